@@ -89,6 +89,8 @@ const (
 	MoveFlag         = "move"
 	DeleteFlag       = "delete"
 	DeleteForceFlag  = "D"
+	DepthParam       = "depth"
+	DeepenParam      = "deepen"
 )
 
 var mergeAbortDetails = `Abort the current conflict resolution process, and try to reconstruct the pre-merge state.
@@ -148,6 +150,8 @@ func CreateCheckoutArgParser() *argparser.ArgParser {
 func CreateFetchArgParser() *argparser.ArgParser {
 	ap := argparser.NewArgParser()
 	ap.SupportsFlag(ForceFlag, "f", "Update refs to remote branches with the current state of the remote, overwriting any conflicting history.")
+	ap.SupportsInt(DepthParam, "", "depth", "Fetch only the last depth commits of the history of each branch, making the repository a shallow clone.")
+	ap.SupportsInt(DeepenParam, "", "depth", "Fetch depth more commits of history past the shallow commits of a shallow clone.")
 	return ap
 }
 
@@ -160,6 +164,15 @@ func CreateRevertArgParser() *argparser.ArgParser {
 	return ap
 }
 
+func CreateCherryPickArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParser()
+	ap.SupportsString(AuthorParam, "", "author", "Specify an explicit author using the standard A U Thor <author@example.com> format.")
+	ap.ArgListHelp = append(ap.ArgListHelp, [2]string{"revision",
+		"The commit revision whose changes are applied to the current branch."})
+
+	return ap
+}
+
 func CreatePullArgParser() *argparser.ArgParser {
 	ap := argparser.NewArgParser()
 	ap.SupportsFlag(SquashParam, "", "Merges changes to the working set without updating the commit history")
@@ -169,6 +182,16 @@ func CreatePullArgParser() *argparser.ArgParser {
 	return ap
 }
 
+// Creates the argparser shared by dolt tag and DOLT_TAG.
+func CreateTagArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParser()
+	ap.ArgListHelp = append(ap.ArgListHelp, [2]string{"ref", "A commit ref that the tag should point at."})
+	ap.SupportsString(CommitMessageArg, "m", "msg", "Use the given {{.LessThan}}msg{{.GreaterThan}} as the tag message.")
+	ap.SupportsFlag(DeleteFlag, "d", "Delete a tag.")
+
+	return ap
+}
+
 func CreateBranchArgParser() *argparser.ArgParser {
 	ap := argparser.NewArgParser()
 	ap.SupportsFlag(ForceFlag, "f", "Ignores any foreign key warnings and proceeds with the commit.")
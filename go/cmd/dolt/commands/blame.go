@@ -267,6 +267,22 @@ func blameInputsFromCommits(ctx context.Context, dEnv *env.DoltEnv, tableName st
 	numCommits := len(commits)
 	blameInputs := make([]blameInput, numCommits)
 	for i, c := range commits {
+		numParents, err := c.NumParents()
+		if err != nil {
+			return nil, err
+		}
+
+		// the initial commit, and the shallow commits of a shallow clone, have no parents. Rows of the table in the
+		// shallow commits are blamed on them, as the history before them is not available.
+		if numParents == 0 {
+			input, err := parentlessBlameInput(ctx, c, tableName)
+			if err != nil {
+				return nil, err
+			}
+			blameInputs[i] = input
+			continue
+		}
+
 		// don't precompute inputs for the initial commit; we don't need them
 		if i == numCommits-1 {
 			break
@@ -322,6 +338,35 @@ func blameInputsFromCommits(ctx context.Context, dEnv *env.DoltEnv, tableName st
 	return &blameInputs, nil
 }
 
+// parentlessBlameInput returns the blameInput of a commit without parents, in which every row of the table is new.
+func parentlessBlameInput(ctx context.Context, c *doltdb.Commit, tableName string) (blameInput, error) {
+	h, err := c.HashOf()
+	if err != nil {
+		return blameInput{}, fmt.Errorf("error getting hash of commit: %v", err)
+	}
+
+	tbl, err := maybeTableFromCommit(ctx, c, tableName)
+	if err != nil {
+		return blameInput{}, fmt.Errorf("error getting table from commit %s: %v", h.String(), err)
+	}
+
+	var s schema.Schema
+	if tbl != nil {
+		s, err = tbl.GetSchema(ctx)
+		if err != nil {
+			return blameInput{}, fmt.Errorf("error getting schema from table %s in commit %s: %v", tableName, h.String(), err)
+		}
+	}
+
+	return blameInput{
+		Commit:    c,
+		Hash:      h.String(),
+		Table:     tbl,
+		TableName: tableName,
+		Schema:    s,
+	}, nil
+}
+
 // rowsFromCommit returns the row data of the table with the given name at the given commit
 func rowsFromCommit(ctx context.Context, commit *doltdb.Commit, tableName string) (types.Map, error) {
 	root, err := commit.GetRootValue()
@@ -0,0 +1,163 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"io"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/merge"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor"
+	"github.com/dolthub/dolt/go/libraries/utils/argparser"
+)
+
+var cherryPickDocs = cli.CommandDocumentationContent{
+	ShortDesc: "Apply the changes introduced by an existing commit",
+	LongDesc: `Applies the changes introduced by the given commit to the working set, and then automatically commits the result
+using the message of the original commit. This is done by way of a three-way merge. Given a specific commit (e.g.
+feature~1), the patch from the commit's parent to the commit is applied on top of the current HEAD. This requires a
+clean working set, and merge commits may not be cherry-picked.
+
+If the merge results in conflicts or constraint violations, they are written to the working set in the same way that
+{{.EmphasisLeft}}dolt merge{{.EmphasisRight}} writes them, and no commit is made. They may be inspected using the
+{{.EmphasisLeft}}dolt_conflicts{{.EmphasisRight}} and {{.EmphasisLeft}}dolt_constraint_violations{{.EmphasisRight}} system
+tables. Once they have been resolved, add the affected tables and commit the result. To abandon the cherry-pick, use
+{{.EmphasisLeft}}dolt reset --hard{{.EmphasisRight}}.`,
+	Synopsis: []string{
+		"<revision>",
+	},
+}
+
+type CherryPickCmd struct{}
+
+var _ cli.Command = CherryPickCmd{}
+
+// Name implements the interface cli.Command.
+func (cmd CherryPickCmd) Name() string {
+	return "cherry-pick"
+}
+
+// Description implements the interface cli.Command.
+func (cmd CherryPickCmd) Description() string {
+	return "Apply the changes introduced by an existing commit."
+}
+
+// CreateMarkdown implements the interface cli.Command.
+func (cmd CherryPickCmd) CreateMarkdown(wr io.Writer, commandStr string) error {
+	ap := cli.CreateCherryPickArgParser()
+	return CreateMarkdown(wr, cli.GetCommandDocumentation(commandStr, cherryPickDocs, ap))
+}
+
+func (cmd CherryPickCmd) ArgParser() *argparser.ArgParser {
+	return cli.CreateCherryPickArgParser()
+}
+
+// Exec implements the interface cli.Command.
+func (cmd CherryPickCmd) Exec(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv) int {
+	ap := cli.CreateCherryPickArgParser()
+	help, usage := cli.HelpAndUsagePrinters(cli.GetCommandDocumentation(commandStr, cherryPickDocs, ap))
+	apr := cli.ParseArgsOrDie(ap, args, help)
+
+	// This command creates a commit, so we need user identity
+	if !cli.CheckUserNameAndEmail(dEnv) {
+		return 1
+	}
+
+	if apr.NArg() != 1 {
+		usage()
+		return 1
+	}
+	headRoot, err := dEnv.HeadRoot(ctx)
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	}
+	workingRoot, err := dEnv.WorkingRoot(ctx)
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	}
+	headHash, err := headRoot.HashOf()
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	}
+	workingHash, err := workingRoot.HashOf()
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	}
+	if !headHash.Equal(workingHash) {
+		cli.PrintErrln("You must commit any changes before using cherry-pick.")
+		return 1
+	}
+
+	commitSpec, err := doltdb.NewCommitSpec(apr.Arg(0))
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	}
+	commit, err := dEnv.DoltDB.Resolve(ctx, commitSpec, dEnv.RepoState.CWBHeadRef())
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	}
+
+	opts := editor.Options{Deaf: dEnv.DbEaFactory()}
+	workingRoot, commitMessage, tblToStats, err := merge.CherryPick(ctx, dEnv.DoltDB, workingRoot, commit, opts)
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	}
+
+	workingHash, err = workingRoot.HashOf()
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	}
+	if headHash.Equal(workingHash) {
+		cli.Println("No changes were made.")
+		return 0
+	}
+
+	err = dEnv.UpdateWorkingRoot(ctx, workingRoot)
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	}
+
+	if merge.HasConflictsOrViolations(tblToStats) {
+		hasConflicts, hasConstraintViolations := printConflictsAndViolations(tblToStats)
+		if hasConflicts && hasConstraintViolations {
+			cli.Println("error: could not apply commit; fix conflicts and constraint violations and then commit the result.")
+		} else if hasConflicts {
+			cli.Println("error: could not apply commit; fix conflicts and then commit the result.")
+		} else {
+			cli.Println("error: could not apply commit; fix constraint violations and then commit the result.\n" +
+				"Constraint violations for the working set may be viewed using the 'dolt_constraint_violations' system table.\n" +
+				"They may be queried and removed per-table using the 'dolt_constraint_violations_TABLENAME' system table.")
+		}
+		return 1
+	}
+
+	res := AddCmd{}.Exec(ctx, "add", []string{"-A"}, dEnv)
+	if res != 0 {
+		return res
+	}
+
+	// Pass in the final parameters for the author string.
+	commitParams := []string{"-m", commitMessage}
+	authorStr, ok := apr.GetValue(cli.AuthorParam)
+	if ok {
+		commitParams = append(commitParams, "--author", authorStr)
+	}
+
+	return CommitCmd{}.Exec(ctx, "commit", commitParams, dEnv)
+}
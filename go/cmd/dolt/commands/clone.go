@@ -19,6 +19,7 @@ import (
 	"io"
 	"os"
 	"path"
+	"strings"
 
 	"github.com/dolthub/dolt/go/cmd/dolt/cli"
 	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
@@ -31,12 +32,15 @@ import (
 	"github.com/dolthub/dolt/go/libraries/events"
 	"github.com/dolthub/dolt/go/libraries/utils/argparser"
 	"github.com/dolthub/dolt/go/libraries/utils/earl"
+	"github.com/dolthub/dolt/go/libraries/utils/funcitr"
 	"github.com/dolthub/dolt/go/store/types"
 )
 
 const (
-	remoteParam = "remote"
-	branchParam = "branch"
+	remoteParam       = "remote"
+	branchParam       = "branch"
+	singleBranchParam = "single-branch"
+	tablesParam       = "tables"
 )
 
 var cloneDocs = cli.CommandDocumentationContent{
@@ -46,9 +50,13 @@ var cloneDocs = cli.CommandDocumentationContent{
 After the clone, a plain {{.EmphasisLeft}}dolt fetch{{.EmphasisRight}} without arguments will update all the remote-tracking branches, and a {{.EmphasisLeft}}dolt pull{{.EmphasisRight}} without arguments will in addition merge the remote branch into the current branch.
 
 This default configuration is achieved by creating references to the remote branch heads under {{.LessThan}}refs/remotes/origin{{.GreaterThan}}  and by creating a remote named 'origin'.
+
+With {{.EmphasisLeft}}--single-branch{{.EmphasisRight}}, only the branch given by {{.EmphasisLeft}}--branch{{.EmphasisRight}}, or the remote's default branch, is cloned. With {{.EmphasisLeft}}--depth{{.EmphasisRight}}, which implies {{.EmphasisLeft}}--single-branch{{.EmphasisRight}}, only the last {{.LessThan}}depth{{.GreaterThan}} commits of its history are cloned. The result is a shallow clone: the commits at the edge of its history are treated as having no parents, and more of the history can be fetched later with {{.EmphasisLeft}}dolt fetch --deepen{{.EmphasisRight}}.
+
+With {{.EmphasisLeft}}--tables{{.EmphasisRight}}, the result is a partial clone: the commits and schemas of the whole history are cloned, but only the row data of the tables listed, along with that of the dolt system tables. The row data of the other tables is fetched from the remote when it is read, and later fetches and pulls only retrieve the row data of the tables listed.
 `,
 	Synopsis: []string{
-		"[-remote {{.LessThan}}remote{{.GreaterThan}}] [-branch {{.LessThan}}branch{{.GreaterThan}}]  [--aws-region {{.LessThan}}region{{.GreaterThan}}] [--aws-creds-type {{.LessThan}}creds-type{{.GreaterThan}}] [--aws-creds-file {{.LessThan}}file{{.GreaterThan}}] [--aws-creds-profile {{.LessThan}}profile{{.GreaterThan}}] {{.LessThan}}remote-url{{.GreaterThan}} {{.LessThan}}new-dir{{.GreaterThan}}",
+		"[-remote {{.LessThan}}remote{{.GreaterThan}}] [-branch {{.LessThan}}branch{{.GreaterThan}}] [--single-branch] [--depth {{.LessThan}}depth{{.GreaterThan}}] [--tables {{.LessThan}}table{{.GreaterThan}}[,{{.LessThan}}table{{.GreaterThan}}...]] [--aws-region {{.LessThan}}region{{.GreaterThan}}] [--aws-creds-type {{.LessThan}}creds-type{{.GreaterThan}}] [--aws-creds-file {{.LessThan}}file{{.GreaterThan}}] [--aws-creds-profile {{.LessThan}}profile{{.GreaterThan}}] [--s3-endpoint {{.LessThan}}url{{.GreaterThan}}] {{.LessThan}}remote-url{{.GreaterThan}} {{.LessThan}}new-dir{{.GreaterThan}}",
 	},
 }
 
@@ -80,10 +88,14 @@ func (cmd CloneCmd) ArgParser() *argparser.ArgParser {
 	ap := argparser.NewArgParser()
 	ap.SupportsString(remoteParam, "", "name", "Name of the remote to be added. Default will be 'origin'.")
 	ap.SupportsString(branchParam, "b", "branch", "The branch to be cloned.  If not specified all branches will be cloned.")
+	ap.SupportsFlag(singleBranchParam, "", "Clone only the branch given by --branch, or the remote's default branch.")
+	ap.SupportsInt(cli.DepthParam, "", "depth", "Clone only the last depth commits of the history of the branch. Implies --single-branch.")
+	ap.SupportsString(tablesParam, "", "tables", "Clone only the row data of this comma separated list of tables. The row data of other tables is fetched from the remote when it is read.")
 	ap.SupportsString(dbfactory.AWSRegionParam, "", "region", "")
 	ap.SupportsValidatedString(dbfactory.AWSCredsTypeParam, "", "creds-type", "", argparser.ValidatorFromStrList(dbfactory.AWSCredsTypeParam, credTypes))
 	ap.SupportsString(dbfactory.AWSCredsFileParam, "", "file", "AWS credentials file.")
 	ap.SupportsString(dbfactory.AWSCredsProfile, "", "profile", "AWS profile to use.")
+	ap.SupportsString(dbfactory.S3EndpointParam, "", "url", "Endpoint of the S3 compatible object store of s3 urls.")
 	return ap
 }
 
@@ -109,6 +121,25 @@ func (cmd CloneCmd) Exec(ctx context.Context, commandStr string, args []string,
 func clone(ctx context.Context, apr *argparser.ArgParseResults, dEnv *env.DoltEnv) errhand.VerboseError {
 	remoteName := apr.GetValueOrDefault(remoteParam, "origin")
 	branch := apr.GetValueOrDefault(branchParam, "")
+	singleBranch := apr.Contains(singleBranchParam)
+	depth := apr.GetIntOrDefault(cli.DepthParam, 0)
+	if apr.Contains(cli.DepthParam) && depth <= 0 {
+		return errhand.BuildDError("error: --%s must be a positive number of commits", cli.DepthParam).Build()
+	}
+
+	var tables []string
+	if val, ok := apr.GetValue(tablesParam); ok {
+		for _, t := range funcitr.MapStrings(strings.Split(val, ","), strings.TrimSpace) {
+			if t != "" {
+				tables = append(tables, t)
+			}
+		}
+
+		if len(tables) == 0 {
+			return errhand.BuildDError("error: --%s must list at least one table", tablesParam).Build()
+		}
+	}
+
 	dir, urlStr, verr := parseArgs(apr)
 	if verr != nil {
 		return verr
@@ -139,7 +170,7 @@ func clone(ctx context.Context, apr *argparser.ArgParseResults, dEnv *env.DoltEn
 		return errhand.VerboseErrorFromError(err)
 	}
 
-	err = actions.CloneRemote(ctx, srcDB, remoteName, branch, dEnv)
+	err = actions.CloneRemote(ctx, srcDB, remoteName, branch, singleBranch, depth, tables, dEnv)
 	if err != nil {
 		// If we're cloning into a directory that already exists do not erase it. Otherwise
 		// make best effort to delete the directory we created.
@@ -16,10 +16,12 @@ package cnfcmds
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"strings"
 
 	eventsapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/eventsapi/v1alpha1"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
 	"github.com/dolthub/dolt/go/libraries/doltcore/row"
 	"github.com/dolthub/dolt/go/store/types"
 
@@ -29,6 +31,8 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/env"
 	"github.com/dolthub/dolt/go/libraries/doltcore/env/actions"
 	"github.com/dolthub/dolt/go/libraries/doltcore/merge"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlfmt"
 	"github.com/dolthub/dolt/go/libraries/utils/argparser"
 )
 
@@ -40,16 +44,20 @@ When a merge operation finds conflicting changes, the rows with the conflicts ar
 In its first form {{.EmphasisLeft}}dolt conflicts resolve <table> <key>...{{.EmphasisRight}}, resolve runs in manual merge mode resolving the conflicts whose keys are provided.
 
 In its second form {{.EmphasisLeft}}dolt conflicts resolve --ours|--theirs <table>...{{.EmphasisRight}}, resolve runs in auto resolve mode. Where conflicts are resolved using a rule to determine which version of a row should be used.
+
+In its third form {{.EmphasisLeft}}dolt conflicts resolve --sql <assignments> <table> [<condition>]{{.EmphasisRight}}, the conflicts are resolved to new values by updating the {{.EmphasisLeft}}our_{{.EmphasisRight}} columns of the {{.EmphasisLeft}}dolt_conflicts_<table>{{.EmphasisRight}} system table with the SQL assignments given, e.g. {{.EmphasisLeft}}our_count = their_count + our_count - base_count{{.EmphasisRight}}. The rows of the table are written with the updated values and their conflicts are resolved. If a condition is given, only the conflicts matching it are resolved. Setting all of the {{.EmphasisLeft}}our_{{.EmphasisRight}} columns of a conflict to NULL resolves it by deleting the row.
 `,
 	Synopsis: []string{
 		`{{.LessThan}}table{{.GreaterThan}} [{{.LessThan}}key_definition{{.GreaterThan}}] {{.LessThan}}key{{.GreaterThan}}...`,
 		`--ours|--theirs {{.LessThan}}table{{.GreaterThan}}...`,
+		`--sql {{.LessThan}}assignments{{.GreaterThan}} {{.LessThan}}table{{.GreaterThan}} [{{.LessThan}}condition{{.GreaterThan}}]`,
 	},
 }
 
 const (
 	oursFlag   = "ours"
 	theirsFlag = "theirs"
+	sqlParam   = "sql"
 )
 
 var autoResolvers = map[string]merge.AutoResolver{
@@ -95,6 +103,7 @@ func (cmd ResolveCmd) ArgParser() *argparser.ArgParser {
 	ap.ArgListHelp = append(ap.ArgListHelp, [2]string{"key", "key(s) of rows within a table whose conflicts have been resolved"})
 	ap.SupportsFlag("ours", "", "For all conflicts, take the version from our branch and resolve the conflict")
 	ap.SupportsFlag("theirs", "", "For all conflicts, take the version from their branch and resolve the conflict")
+	ap.SupportsString(sqlParam, "", "assignments", "Resolve the conflicts of a table by setting the our_ columns of its dolt_conflicts_<table> table with the given SQL assignments")
 
 	return ap
 }
@@ -105,6 +114,13 @@ func (cmd ResolveCmd) Exec(ctx context.Context, commandStr string, args []string
 	help, usage := cli.HelpAndUsagePrinters(cli.GetCommandDocumentation(commandStr, resDocumentation, ap))
 	apr := cli.ParseArgsOrDie(ap, args, help)
 
+	if apr.Contains(sqlParam) {
+		if apr.ContainsAny(autoResolverParams...) {
+			return commands.HandleVErrAndExitCode(errhand.BuildDError("--%s cannot be used with an auto resolve mode", sqlParam).SetPrintUsage().Build(), usage)
+		}
+		return sqlResolve(ctx, apr, dEnv, usage)
+	}
+
 	var verr errhand.VerboseError
 	if apr.ContainsAny(autoResolverParams...) {
 		verr = autoResolve(ctx, apr, dEnv)
@@ -143,6 +159,27 @@ func autoResolve(ctx context.Context, apr *argparser.ArgParseResults, dEnv *env.
 	return saveDocsOnResolve(ctx, dEnv)
 }
 
+func sqlResolve(ctx context.Context, apr *argparser.ArgParseResults, dEnv *env.DoltEnv, usage cli.UsagePrinter) int {
+	if apr.NArg() == 0 {
+		return commands.HandleVErrAndExitCode(errhand.BuildDError("specify a table to resolve conflicts").SetPrintUsage().Build(), usage)
+	}
+
+	assignments := apr.MustGetValue(sqlParam)
+	query := fmt.Sprintf("UPDATE %s SET %s", sqlfmt.QuoteIdentifier(doltdb.DoltConfTablePrefix+apr.Arg(0)), assignments)
+	if apr.NArg() > 1 {
+		query = fmt.Sprintf("%s WHERE %s", query, strings.Join(apr.Args[1:], " "))
+	}
+
+	// like manual resolution, resolving some of the conflicts leaves the others in the working set
+	query = fmt.Sprintf("SET @@%s = 1; %s;", dsess.ForceTransactionCommit, query)
+
+	if res := (commands.SqlCmd{}).Exec(ctx, "", []string{"--" + commands.QueryFlag, query}, dEnv); res != 0 {
+		return res
+	}
+
+	return commands.HandleVErrAndExitCode(saveDocsOnResolve(ctx, dEnv), usage)
+}
+
 func manualResolve(ctx context.Context, apr *argparser.ArgParseResults, dEnv *env.DoltEnv) errhand.VerboseError {
 	args := apr.Args
 
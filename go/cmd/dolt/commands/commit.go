@@ -139,6 +139,10 @@ func (cmd CommitCmd) Exec(ctx context.Context, commandStr string, args []string,
 		return HandleVErrAndExitCode(errhand.BuildDError("Couldn't get working set").AddCause(err).Build(), usage)
 	}
 
+	if ws.RebaseActive() {
+		return handleCommitErr(ctx, dEnv, doltdb.ErrRebaseActive, usage)
+	}
+
 	var mergeParentCommits []*doltdb.Commit
 	if ws.MergeActive() {
 		mergeParentCommits = []*doltdb.Commit{ws.MergeState().Commit()}
@@ -473,12 +477,30 @@ func getAddedNotStaged(notStagedTbls []diff.TableDelta, notStagedDocs *diff.DocD
 func PrintStatus(ctx context.Context, dEnv *env.DoltEnv, stagedTbls, notStagedTbls []diff.TableDelta, workingTblsInConflict, workingTblsWithViolations []string, stagedDocs, notStagedDocs *diff.DocDiffs) error {
 	cli.Printf(branchHeader, dEnv.RepoStateReader().CWBHeadRef().GetPath())
 
-	mergeActive, err := dEnv.IsMergeActive(ctx)
+	ws, err := dEnv.WorkingSet(ctx)
 	if err != nil {
 		return err
 	}
 
-	if mergeActive {
+	mergeActive := ws.MergeActive()
+	if ws.RebaseActive() {
+		rebaseHeader, err := rebaseStatusHeader(ws, dEnv.RepoStateReader().CWBHeadRef().GetPath())
+		if err != nil {
+			return err
+		}
+		cli.Print(rebaseHeader)
+
+		if len(workingTblsInConflict) > 0 && len(workingTblsWithViolations) > 0 {
+			cli.Println(fmt.Sprintf(unrebasedTablesHeader, "conflicts and constraint violations"))
+		} else if len(workingTblsInConflict) > 0 {
+			cli.Println(fmt.Sprintf(unrebasedTablesHeader, "conflicts"))
+		} else if len(workingTblsWithViolations) > 0 {
+			cli.Println(fmt.Sprintf(unrebasedTablesHeader, "constraint violations"))
+		} else {
+			cli.Println(allRebasedHeader)
+			cli.Println()
+		}
+	} else if mergeActive {
 		if len(workingTblsInConflict) > 0 && len(workingTblsWithViolations) > 0 {
 			cli.Println(fmt.Sprintf(unmergedTablesHeader, "conflicts and constraint violations"))
 		} else if len(workingTblsInConflict) > 0 {
@@ -500,6 +522,15 @@ func PrintStatus(ctx context.Context, dEnv *env.DoltEnv, stagedTbls, notStagedTb
 	return nil
 }
 
+func rebaseStatusHeader(ws *doltdb.WorkingSet, branchName string) (string, error) {
+	ontoHash, err := ws.MergeState().RebaseState().Onto().HashOf()
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(rebasingHeader, branchName, ontoHash.String()), nil
+}
+
 const (
 	branchHeader     = "On branch %s\n"
 	stagedHeader     = `Changes to be committed:`
@@ -514,6 +545,16 @@ const (
   (use "dolt commit" to conclude merge)
 `
 
+	rebasingHeader = "You are currently rebasing branch '%s' on '%s'.\n"
+
+	unrebasedTablesHeader = `You have unmerged tables.
+  (fix %s, add the affected tables and run "dolt rebase --continue")
+  (use "dolt rebase --abort" to check out the original branch)
+`
+
+	allRebasedHeader = `All conflicts and constraint violations fixed.
+  (use "dolt add" to stage the changes and run "dolt rebase --continue")`
+
 	mergedTableHeader = `Unmerged paths:`
 	mergedTableHelp   = `  (use "dolt add <file>..." to mark resolution)`
 
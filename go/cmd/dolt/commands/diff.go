@@ -39,7 +39,6 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/rowconv"
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle"
-	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlfmt"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/pipeline"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/untyped"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/untyped/fwt"
@@ -63,6 +62,7 @@ const (
 
 	TabularDiffOutput diffOutput = 1
 	SQLDiffOutput     diffOutput = 2
+	JSONDiffOutput    diffOutput = 3
 
 	DataFlag    = "data"
 	SchemaFlag  = "schema"
@@ -93,6 +93,8 @@ Show changes between the working and staged tables, changes between the working
 {{.EmphasisLeft}}dolt diff [--options] <commit> <commit> [<tables>...]{{.EmphasisRight}}
    This is to view the changes between two arbitrary {{.EmphasisLeft}}commit{{.EmphasisRight}}.
 
+The {{.EmphasisLeft}}--result-format json{{.EmphasisRight}} option writes the diff as a single JSON document with an entry for each changed table containing its schema changes and a {{.EmphasisLeft}}data_diff{{.EmphasisRight}} array of row changes, each with a {{.EmphasisLeft}}before{{.EmphasisRight}} and an {{.EmphasisLeft}}after{{.EmphasisRight}} object keyed by column name. Changes to docs are not included in JSON output.
+
 The diffs displayed can be limited to show the first N by providing the parameter {{.EmphasisLeft}}--limit N{{.EmphasisRight}} where {{.EmphasisLeft}}N{{.EmphasisRight}} is the number of diffs to display.
 
 In order to filter which diffs are displayed {{.EmphasisLeft}}--where key=value{{.EmphasisRight}} can be used.  The key in this case would be either {{.EmphasisLeft}}to_COLUMN_NAME{{.EmphasisRight}} or {{.EmphasisLeft}}from_COLUMN_NAME{{.EmphasisRight}}. where {{.EmphasisLeft}}from_COLUMN_NAME=value{{.EmphasisRight}} would filter based on the original value and {{.EmphasisLeft}}to_COLUMN_NAME{{.EmphasisRight}} would select based on its updated value.
@@ -141,7 +143,7 @@ func (cmd DiffCmd) ArgParser() *argparser.ArgParser {
 	ap.SupportsFlag(DataFlag, "d", "Show only the data changes, do not show the schema changes (Both shown by default).")
 	ap.SupportsFlag(SchemaFlag, "s", "Show only the schema changes, do not show the data changes (Both shown by default).")
 	ap.SupportsFlag(SummaryFlag, "", "Show summary of data changes")
-	ap.SupportsString(FormatFlag, "r", "result output format", "How to format diff output. Valid values are tabular & sql. Defaults to tabular. ")
+	ap.SupportsString(FormatFlag, "r", "result output format", "How to format diff output. Valid values are tabular, sql & json. Defaults to tabular. ")
 	ap.SupportsString(whereParam, "", "column", "filters columns based on values in the diff.  See {{.EmphasisLeft}}dolt diff --help{{.EmphasisRight}} for details.")
 	ap.SupportsInt(limitParam, "", "record_count", "limits to the first N diffs.")
 	ap.SupportsFlag(CachedFlag, "c", "Show only the unstaged data changes.")
@@ -166,10 +168,12 @@ func (cmd DiffCmd) Exec(ctx context.Context, commandStr string, args []string, d
 		return HandleVErrAndExitCode(verr, usage)
 	}
 
-	err = diffDoltDocs(ctx, dEnv, fromRoot, toRoot, dArgs)
+	if dArgs.diffOutput != JSONDiffOutput {
+		err = diffDoltDocs(ctx, dEnv, fromRoot, toRoot, dArgs)
 
-	if err != nil {
-		verr = errhand.BuildDError("error diffing dolt docs").AddCause(err).Build()
+		if err != nil {
+			verr = errhand.BuildDError("error diffing dolt docs").AddCause(err).Build()
+		}
 	}
 
 	return HandleVErrAndExitCode(verr, usage)
@@ -213,6 +217,8 @@ func parseDiffArgs(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgPar
 		dArgs.diffOutput = TabularDiffOutput
 	case "sql":
 		dArgs.diffOutput = SQLDiffOutput
+	case "json":
+		dArgs.diffOutput = JSONDiffOutput
 	case "":
 		dArgs.diffOutput = TabularDiffOutput
 	default:
@@ -388,6 +394,15 @@ func diffUserTables(ctx context.Context, fromRoot, toRoot *doltdb.RootValue, dAr
 	sort.Slice(tableDeltas, func(i, j int) bool {
 		return strings.Compare(tableDeltas[i].ToName, tableDeltas[j].ToName) < 0
 	})
+
+	var jsonWr *diff.JSONDiffWriter
+	if dArgs.diffOutput == JSONDiffOutput {
+		jsonWr, err = diff.NewJSONDiffWriter(iohelp.NopWrCloser(cli.CliOut))
+		if err != nil {
+			return errhand.BuildDError("error: unable to write diff").AddCause(err).Build()
+		}
+	}
+
 	for _, td := range tableDeltas {
 		if !dArgs.tableSet.Contains(td.FromName) && !dArgs.tableSet.Contains(td.ToName) {
 			continue
@@ -415,6 +430,14 @@ func diffUserTables(ctx context.Context, fromRoot, toRoot *doltdb.RootValue, dAr
 			continue
 		}
 
+		if jsonWr != nil {
+			verr = jsonDiffTable(ctx, jsonWr, td, dArgs, toRoot.VRW())
+			if verr != nil {
+				return verr
+			}
+			continue
+		}
+
 		fromSch, toSch, err := td.GetSchemas(ctx)
 		if err != nil {
 			return errhand.BuildDError("cannot retrieve schema for table %s", td.ToName).AddCause(err).Build()
@@ -426,7 +449,7 @@ func diffUserTables(ctx context.Context, fromRoot, toRoot *doltdb.RootValue, dAr
 		}
 
 		if dArgs.diffParts&SchemaOnlyDiff != 0 {
-			verr = diffSchemas(ctx, toRoot, td, dArgs)
+			verr = diffSchemas(ctx, td, dArgs)
 		}
 
 		if dArgs.diffParts&DataOnlyDiff != 0 {
@@ -435,7 +458,7 @@ func diffUserTables(ctx context.Context, fromRoot, toRoot *doltdb.RootValue, dAr
 			} else if td.IsAdd() {
 				fromSch = toSch
 			}
-			verr = diffRows(ctx, td, dArgs, toRoot.VRW())
+			verr = diffRows(ctx, td, dArgs, toRoot.VRW(), nil)
 		}
 
 		if verr != nil {
@@ -443,15 +466,93 @@ func diffUserTables(ctx context.Context, fromRoot, toRoot *doltdb.RootValue, dAr
 		}
 	}
 
+	if jsonWr != nil {
+		if err = jsonWr.Close(); err != nil {
+			return errhand.BuildDError("error: unable to write diff").AddCause(err).Build()
+		}
+	}
+
 	return nil
 }
 
-func diffSchemas(ctx context.Context, toRoot *doltdb.RootValue, td diff.TableDelta, dArgs *diffArgs) errhand.VerboseError {
-	toSchemas, err := toRoot.GetAllSchemas(ctx)
-	if err != nil {
-		return errhand.BuildDError("could not read schemas from toRoot").AddCause(err).Build()
+// jsonSchemaChange is the JSON representation of a sqle.SchemaChange
+type jsonSchemaChange struct {
+	ObjectType     string   `json:"object_type"`
+	ObjectName     string   `json:"object_name"`
+	DiffType       string   `json:"diff_type"`
+	FromDefinition *string  `json:"from_definition"`
+	ToDefinition   *string  `json:"to_definition"`
+	Statements     []string `json:"statements"`
+}
+
+// jsonDiffTable writes the diff of a single table as an entry of the JSON document written by |jsonWr|. Like SQL
+// output, the data diff of a dropped table is not written.
+func jsonDiffTable(ctx context.Context, jsonWr *diff.JSONDiffWriter, td diff.TableDelta, dArgs *diffArgs, vrw types.ValueReadWriter) errhand.VerboseError {
+	if err := jsonWr.BeginTable(td); err != nil {
+		return errhand.BuildDError("error: unable to write diff").AddCause(err).Build()
+	}
+
+	if dArgs.diffParts&Summary != 0 {
+		if verr := jsonDiffSummary(ctx, jsonWr, td); verr != nil {
+			return verr
+		}
+	}
+
+	if dArgs.diffParts&SchemaOnlyDiff != 0 {
+		changes, err := sqle.GetSchemaChanges(ctx, td)
+		if err != nil {
+			return errhand.BuildDError("cannot diff schema for table %s", td.CurName()).AddCause(err).Build()
+		}
+
+		jsonChanges := make([]jsonSchemaChange, len(changes))
+		for i, change := range changes {
+			jsonChanges[i] = jsonSchemaChange{
+				ObjectType:     change.ObjectType,
+				ObjectName:     change.ObjectName,
+				DiffType:       schemaChangeTypeName(change.DiffType),
+				FromDefinition: stringPtrOrNil(change.FromDefinition),
+				ToDefinition:   stringPtrOrNil(change.ToDefinition),
+				Statements:     change.Statements,
+			}
+		}
+
+		if err = jsonWr.WriteTableField("schema_diff", jsonChanges); err != nil {
+			return errhand.BuildDError("error: unable to write diff").AddCause(err).Build()
+		}
+	}
+
+	if dArgs.diffParts&DataOnlyDiff != 0 && !td.IsDrop() {
+		if verr := diffRows(ctx, td, dArgs, vrw, jsonWr); verr != nil {
+			return verr
+		}
 	}
 
+	if err := jsonWr.EndTable(); err != nil {
+		return errhand.BuildDError("error: unable to write diff").AddCause(err).Build()
+	}
+
+	return nil
+}
+
+func schemaChangeTypeName(diffType diff.SchemaChangeType) string {
+	switch diffType {
+	case diff.SchDiffAdded:
+		return "added"
+	case diff.SchDiffRemoved:
+		return "removed"
+	default:
+		return "modified"
+	}
+}
+
+func stringPtrOrNil(s string) *string {
+	if len(s) == 0 {
+		return nil
+	}
+	return &s
+}
+
+func diffSchemas(ctx context.Context, td diff.TableDelta, dArgs *diffArgs) errhand.VerboseError {
 	if dArgs.diffOutput == TabularDiffOutput {
 		if td.IsDrop() || td.IsAdd() {
 			panic("cannot perform tabular schema diff for added/dropped tables")
@@ -460,7 +561,7 @@ func diffSchemas(ctx context.Context, toRoot *doltdb.RootValue, td diff.TableDel
 		return printShowCreateTableDiff(ctx, td)
 	}
 
-	return sqlSchemaDiff(ctx, td, toSchemas)
+	return sqlSchemaDiff(ctx, td)
 }
 
 func printShowCreateTableDiff(ctx context.Context, td diff.TableDelta) errhand.VerboseError {
@@ -484,89 +585,18 @@ func printShowCreateTableDiff(ctx context.Context, td diff.TableDelta) errhand.V
 	return nil
 }
 
-// TODO: this doesn't handle check constraints or triggers
-func sqlSchemaDiff(ctx context.Context, td diff.TableDelta, toSchemas map[string]schema.Schema) errhand.VerboseError {
-	fromSch, toSch, err := td.GetSchemas(ctx)
+func sqlSchemaDiff(ctx context.Context, td diff.TableDelta) errhand.VerboseError {
+	changes, err := sqle.GetSchemaChanges(ctx, td)
 	if err != nil {
-		return errhand.BuildDError("cannot retrieve schema for table %s", td.ToName).AddCause(err).Build()
+		return errhand.BuildDError("cannot diff schema for table %s", td.CurName()).AddCause(err).Build()
 	}
 
-	if td.IsDrop() {
-		cli.Println(sqlfmt.DropTableStmt(td.FromName))
-	} else if td.IsAdd() {
-		sqlDb := sqle.NewSingleTableDatabase(td.ToName, toSch, td.ToFks, td.ToFksParentSch)
-		sqlCtx, engine, _ := sqle.PrepareCreateTableStmt(ctx, sqlDb)
-		stmt, err := sqle.GetCreateTableStmt(sqlCtx, engine, td.ToName)
-		if err != nil {
-			return errhand.VerboseErrorFromError(err)
-		}
-		cli.Println(stmt)
-	} else {
-		if td.FromName != td.ToName {
-			cli.Println(sqlfmt.RenameTableStmt(td.FromName, td.ToName))
-		}
-
-		eq := schema.SchemasAreEqual(fromSch, toSch)
-		if eq && !td.HasFKChanges() {
-			return nil
-		}
-
-		colDiffs, unionTags := diff.DiffSchColumns(fromSch, toSch)
-		for _, tag := range unionTags {
-			cd := colDiffs[tag]
-			switch cd.DiffType {
-			case diff.SchDiffNone:
-			case diff.SchDiffAdded:
-				cli.Println(sqlfmt.AlterTableAddColStmt(td.ToName, sqlfmt.FmtCol(0, 0, 0, *cd.New)))
-			case diff.SchDiffRemoved:
-				cli.Println(sqlfmt.AlterTableDropColStmt(td.ToName, cd.Old.Name))
-			case diff.SchDiffModified:
-				// Ignore any primary key set changes here
-				if cd.Old.IsPartOfPK != cd.New.IsPartOfPK {
-					continue
-				}
-				if cd.Old.Name != cd.New.Name {
-					cli.Println(sqlfmt.AlterTableRenameColStmt(td.ToName, cd.Old.Name, cd.New.Name))
-				}
-			}
-		}
-
-		// Print changes between a primary key set change. It contains an ALTER TABLE DROP and an ALTER TABLE ADD
-		if !schema.ColCollsAreEqual(fromSch.GetPKCols(), toSch.GetPKCols()) {
-			cli.Println(sqlfmt.AlterTableDropPks(td.ToName))
-			if toSch.GetPKCols().Size() > 0 {
-				cli.Println(sqlfmt.AlterTableAddPrimaryKeys(td.ToName, toSch.GetPKCols()))
-			}
-		}
-
-		for _, idxDiff := range diff.DiffSchIndexes(fromSch, toSch) {
-			switch idxDiff.DiffType {
-			case diff.SchDiffNone:
-			case diff.SchDiffAdded:
-				cli.Println(sqlfmt.AlterTableAddIndexStmt(td.ToName, idxDiff.To))
-			case diff.SchDiffRemoved:
-				cli.Println(sqlfmt.AlterTableDropIndexStmt(td.FromName, idxDiff.From))
-			case diff.SchDiffModified:
-				cli.Println(sqlfmt.AlterTableDropIndexStmt(td.FromName, idxDiff.From))
-				cli.Println(sqlfmt.AlterTableAddIndexStmt(td.ToName, idxDiff.To))
-			}
-		}
-
-		for _, fkDiff := range diff.DiffForeignKeys(td.FromFks, td.ToFks) {
-			switch fkDiff.DiffType {
-			case diff.SchDiffNone:
-			case diff.SchDiffAdded:
-				parentSch := toSchemas[fkDiff.To.ReferencedTableName]
-				cli.Println(sqlfmt.AlterTableAddForeignKeyStmt(fkDiff.To, toSch, parentSch))
-			case diff.SchDiffRemoved:
-				cli.Println(sqlfmt.AlterTableDropForeignKeyStmt(fkDiff.From))
-			case diff.SchDiffModified:
-				cli.Println(sqlfmt.AlterTableDropForeignKeyStmt(fkDiff.From))
-				parentSch := toSchemas[fkDiff.To.ReferencedTableName]
-				cli.Println(sqlfmt.AlterTableAddForeignKeyStmt(fkDiff.To, toSch, parentSch))
-			}
+	for _, change := range changes {
+		for _, stmt := range change.Statements {
+			cli.Println(stmt)
 		}
 	}
+
 	return nil
 }
 
@@ -600,7 +630,8 @@ func fromNamer(name string) string {
 	return diff.From + "_" + name
 }
 
-func diffRows(ctx context.Context, td diff.TableDelta, dArgs *diffArgs, vrw types.ValueReadWriter) errhand.VerboseError {
+// diffRows writes the data diff of a table. When writing JSON output, rows are written to the current table of |jsonWr|.
+func diffRows(ctx context.Context, td diff.TableDelta, dArgs *diffArgs, vrw types.ValueReadWriter, jsonWr *diff.JSONDiffWriter) errhand.VerboseError {
 	fromSch, toSch, err := td.GetSchemas(ctx)
 	if err != nil {
 		return errhand.BuildDError("cannot retrieve schema for table %s", td.ToName).AddCause(err).Build()
@@ -633,7 +664,13 @@ func diffRows(ctx context.Context, td diff.TableDelta, dArgs *diffArgs, vrw type
 
 	rd := diff.NewRowDiffer(ctx, fromSch, toSch, 1024)
 	if _, ok := rd.(*diff.EmptyRowDiffer); ok {
-		cli.Println("warning: skipping data diff due to primary key set change")
+		const warning = "warning: skipping data diff due to primary key set change"
+		if jsonWr != nil {
+			// keep stdout valid JSON
+			cli.PrintErrln(warning)
+		} else {
+			cli.Println(warning)
+		}
 		return nil
 	}
 	rd.Start(ctx, fromRows, toRows)
@@ -661,9 +698,12 @@ func diffRows(ctx context.Context, td diff.TableDelta, dArgs *diffArgs, vrw type
 	}
 
 	var sink DiffSink
-	if dArgs.diffOutput == TabularDiffOutput {
+	switch dArgs.diffOutput {
+	case TabularDiffOutput:
 		sink, err = diff.NewColorDiffSink(iohelp.NopWrCloser(cli.CliOut), unionSch, numHeaderRows)
-	} else {
+	case JSONDiffOutput:
+		sink, err = jsonWr.BeginRowDiffs(joiner)
+	default:
 		sink, err = diff.NewSQLDiffSink(iohelp.NopWrCloser(cli.CliOut), unionSch, td.CurName())
 	}
 
@@ -684,7 +724,7 @@ func diffRows(ctx context.Context, td diff.TableDelta, dArgs *diffArgs, vrw type
 		return verr
 	}
 
-	if dArgs.diffOutput != SQLDiffOutput {
+	if dArgs.diffOutput == TabularDiffOutput {
 		if schemasEqual {
 			schRow, err := untyped.NewRowFromTaggedStrings(toRows.Format(), unionSch, newColNames)
 
@@ -745,9 +785,12 @@ func buildPipeline(dArgs *diffArgs, joiner *rowconv.Joiner, ds *diff.DiffSplitte
 		transforms.AppendTransforms(pipeline.NewNamedTransform("select", selTrans.LimitAndFilter))
 	}
 
-	transforms.AppendTransforms(
-		pipeline.NewNamedTransform("split_diffs", ds.SplitDiffIntoOldAndNew),
-	)
+	// JSON output writes each joined row as a single object with its old and new values
+	if dArgs.diffOutput != JSONDiffOutput {
+		transforms.AppendTransforms(
+			pipeline.NewNamedTransform("split_diffs", ds.SplitDiffIntoOldAndNew),
+		)
+	}
 
 	if dArgs.diffOutput == TabularDiffOutput {
 		nullPrinter := nullprinter.NewNullPrinter(untypedUnionSch)
@@ -943,6 +986,66 @@ func printTableDiffSummary(td diff.TableDelta) {
 }
 
 func diffSummary(ctx context.Context, td diff.TableDelta, colLen int) errhand.VerboseError {
+	acc, verr := accumulateDiffSummary(ctx, td, true)
+	if verr != nil {
+		return verr
+	}
+
+	keyless, err := td.IsKeyless(ctx)
+	if err != nil {
+		return nil
+	}
+
+	if (acc.Adds + acc.Removes + acc.Changes) == 0 {
+		cli.Println("No data changes. See schema changes by using -s or --schema.")
+		return nil
+	}
+
+	if keyless {
+		printKeylessSummary(acc)
+	} else {
+		printSummary(acc, colLen)
+	}
+
+	return nil
+}
+
+// jsonDiffSummary writes the summary of the data changes of a table as the "summary" field of its JSON diff entry.
+// Modification counts are omitted for keyless tables, whose changes are all adds and deletes.
+func jsonDiffSummary(ctx context.Context, jsonWr *diff.JSONDiffWriter, td diff.TableDelta) errhand.VerboseError {
+	acc, verr := accumulateDiffSummary(ctx, td, false)
+	if verr != nil {
+		return verr
+	}
+
+	keyless, err := td.IsKeyless(ctx)
+	if err != nil {
+		return errhand.BuildDError("").AddCause(err).Build()
+	}
+
+	summary := map[string]uint64{
+		"rows_added":   acc.Adds,
+		"rows_deleted": acc.Removes,
+	}
+
+	if !keyless {
+		summary["rows_unmodified"] = acc.OldSize - acc.Changes - acc.Removes
+		summary["rows_modified"] = acc.Changes
+		summary["cells_modified"] = acc.CellChanges
+		summary["old_row_count"] = acc.OldSize
+		summary["new_row_count"] = acc.NewSize
+	}
+
+	if err = jsonWr.WriteTableField("summary", summary); err != nil {
+		return errhand.BuildDError("error: unable to write diff").AddCause(err).Build()
+	}
+
+	return nil
+}
+
+// accumulateDiffSummary computes the summary of the data changes of a table, printing its progress if |printProgress|
+// is true.
+func accumulateDiffSummary(ctx context.Context, td diff.TableDelta, printProgress bool) (diff.DiffSummaryProgress, errhand.VerboseError) {
 	// todo: use errgroup.Group
 	ae := atomicerr.New()
 	ch := make(chan diff.DiffSummaryProgress)
@@ -968,7 +1071,7 @@ func diffSummary(ctx context.Context, td diff.TableDelta, colLen int) errhand.Ve
 		acc.NewSize += p.NewSize
 		acc.OldSize += p.OldSize
 
-		if count%10000 == 0 {
+		if printProgress && count%10000 == 0 {
 			statusStr := fmt.Sprintf("prev size: %d, new size: %d, adds: %d, deletes: %d, modifications: %d", acc.OldSize, acc.NewSize, acc.Adds, acc.Removes, acc.Changes)
 			pos = cli.DeleteAndPrint(pos, statusStr)
 		}
@@ -976,29 +1079,15 @@ func diffSummary(ctx context.Context, td diff.TableDelta, colLen int) errhand.Ve
 		count++
 	}
 
-	pos = cli.DeleteAndPrint(pos, "")
-
-	if err := ae.Get(); err != nil {
-		return errhand.BuildDError("").AddCause(err).Build()
+	if printProgress {
+		cli.DeleteAndPrint(pos, "")
 	}
 
-	keyless, err := td.IsKeyless(ctx)
-	if err != nil {
-		return nil
-	}
-
-	if (acc.Adds + acc.Removes + acc.Changes) == 0 {
-		cli.Println("No data changes. See schema changes by using -s or --schema.")
-		return nil
-	}
-
-	if keyless {
-		printKeylessSummary(acc)
-	} else {
-		printSummary(acc, colLen)
+	if err := ae.Get(); err != nil {
+		return diff.DiffSummaryProgress{}, errhand.BuildDError("").AddCause(err).Build()
 	}
 
-	return nil
+	return acc, nil
 }
 
 func printSummary(acc diff.DiffSummaryProgress, colLen int) {
@@ -33,6 +33,7 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
 	dsqle "github.com/dolthub/dolt/go/libraries/doltcore/sqle"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/privileges"
 	"github.com/dolthub/dolt/go/libraries/utils/config"
 	"github.com/dolthub/dolt/go/libraries/utils/tracing"
 )
@@ -54,6 +55,7 @@ func NewSqlEngine(
 	initialDb string,
 	isReadOnly bool,
 	tempUsers []gms.TemporaryUser,
+	privilegeFilePath string,
 	autocommit bool) (*SqlEngine, error) {
 
 	parallelism := runtime.GOMAXPROCS(0)
@@ -74,7 +76,34 @@ func NewSqlEngine(
 
 	pro := dsqle.NewDoltDatabaseProvider(mrEnv.Config(), mrEnv.FileSystem(), all...)
 
-	engine := gms.New(analyzer.NewBuilder(pro).WithParallelism(parallelism).Build(), &gms.Config{IsReadOnly: isReadOnly, TemporaryUsers: tempUsers}).WithBackgroundThreads(bThreads)
+	// accounts and branch rules are only persisted when a privilege file is given
+	var privStore *privileges.Store
+	if len(privilegeFilePath) > 0 {
+		tempUserNames := make([]string, len(tempUsers))
+		for i, tempUser := range tempUsers {
+			tempUserNames[i] = tempUser.Username
+		}
+
+		privStore = privileges.NewStore(mrEnv.FileSystem(), privilegeFilePath, tempUserNames...)
+		err = privStore.Load(sql.NewContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+
+		pro = pro.WithBranchAccessController(privStore)
+	}
+
+	builder := analyzer.NewBuilder(pro).WithParallelism(parallelism)
+	if privStore != nil {
+		builder = builder.AddPostValidationRule(privileges.PersistRuleName, privStore.PersistRule())
+	}
+
+	a := builder.Build()
+	if privStore != nil {
+		a.Catalog.GrantTables = privStore.GrantTables()
+	}
+
+	engine := gms.New(a, &gms.Config{IsReadOnly: isReadOnly, TemporaryUsers: tempUsers}).WithBackgroundThreads(bThreads)
 
 	if dbg, ok := os.LookupEnv("DOLT_SQL_DEBUG_LOG"); ok && strings.ToLower(dbg) == "true" {
 		engine.Analyzer.Debug = true
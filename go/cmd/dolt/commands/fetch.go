@@ -35,10 +35,12 @@ var fetchDocs = cli.CommandDocumentationContent{
 By default dolt will attempt to fetch from a remote named {{.EmphasisLeft}}origin{{.EmphasisRight}}.  The {{.LessThan}}remote{{.GreaterThan}} parameter allows you to specify the name of a different remote you wish to pull from by the remote's name.
 
 When no refspec(s) are specified on the command line, the fetch_specs for the default remote are used.
+
+With {{.EmphasisLeft}}--depth{{.EmphasisRight}}, only the last {{.LessThan}}depth{{.GreaterThan}} commits of the history of each branch are fetched, and the repository becomes a shallow clone. With {{.EmphasisLeft}}--deepen{{.EmphasisRight}}, {{.LessThan}}depth{{.GreaterThan}} more commits of history are fetched past the edge of the history of a shallow clone.
 `,
 
 	Synopsis: []string{
-		"[{{.LessThan}}remote{{.GreaterThan}}] [{{.LessThan}}refspec{{.GreaterThan}} ...]",
+		"[--depth {{.LessThan}}depth{{.GreaterThan}}] [--deepen {{.LessThan}}depth{{.GreaterThan}}] [{{.LessThan}}remote{{.GreaterThan}}] [{{.LessThan}}refspec{{.GreaterThan}} ...]",
 	},
 }
 
@@ -81,7 +83,16 @@ func (cmd FetchCmd) Exec(ctx context.Context, commandStr string, args []string,
 	}
 	updateMode := ref.UpdateMode{Force: apr.Contains(cli.ForceFlag)}
 
-	err = actions.FetchRefSpecs(ctx, dEnv.DbData(), refSpecs, r, updateMode, runProgFuncs, stopProgFuncs)
+	depth, deepen, verr := parseFetchDepth(apr)
+	if verr != nil {
+		return HandleVErrAndExitCode(verr, usage)
+	}
+
+	err = actions.FetchRefSpecs(ctx, dEnv.DbData(), refSpecs, r, updateMode, depth, runProgFuncs, stopProgFuncs)
+	if err == nil && deepen > 0 {
+		err = actions.DeepenShallowClone(ctx, dEnv.DbData(), r, deepen, runProgFuncs, stopProgFuncs)
+	}
+
 	switch err {
 	case doltdb.ErrUpToDate:
 		return HandleVErrAndExitCode(nil, usage)
@@ -94,3 +105,18 @@ func (cmd FetchCmd) Exec(ctx context.Context, commandStr string, args []string,
 	}
 	return HandleVErrAndExitCode(nil, usage)
 }
+
+// parseFetchDepth returns the --depth and --deepen arguments of a fetch, which must be positive if given.
+func parseFetchDepth(apr *argparser.ArgParseResults) (depth int, deepen int, verr errhand.VerboseError) {
+	for _, param := range []string{cli.DepthParam, cli.DeepenParam} {
+		if n, ok := apr.GetInt(param); ok && n <= 0 {
+			return 0, 0, errhand.BuildDError("error: --%s must be a positive number of commits", param).Build()
+		}
+	}
+
+	if apr.Contains(cli.DepthParam) && apr.Contains(cli.DeepenParam) {
+		return 0, 0, errhand.BuildDError("error: --%s and --%s cannot be used together", cli.DepthParam, cli.DeepenParam).Build()
+	}
+
+	return apr.GetIntOrDefault(cli.DepthParam, 0), apr.GetIntOrDefault(cli.DeepenParam, 0), nil
+}
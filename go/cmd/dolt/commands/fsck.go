@@ -0,0 +1,183 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
+	"github.com/dolthub/dolt/go/libraries/doltcore/dbfactory"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/utils/argparser"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/nbs"
+)
+
+const fsckVerboseFlag = "verbose"
+
+var fsckDocs = cli.CommandDocumentationContent{
+	ShortDesc: "Verifies the integrity of the repository.",
+	LongDesc: `Checks the repository for damage, such as after a disk failure.
+
+Every table file referenced by the manifests of the repository is checked: its index and footer are validated, and the contents of each of its chunks are hashed and compared to the chunk's address. The chunk journal, if there is one, is checked in the same way.
+
+Every ref of the database is then walked, checking that each chunk reachable from it is present and intact, and decoding each commit and root value along with the schemas and row data of their tables. Missing, corrupt and undecodable chunks are reported along with the refs that depend on them.
+
+Chunks which are not reachable from any ref are reported as orphaned. Orphaned chunks are not damage, and are removed by {{.EmphasisLeft}}dolt gc{{.EmphasisRight}}. They are only listed individually with {{.EmphasisLeft}}--verbose{{.EmphasisRight}}.
+
+Exits with a non-zero status if any damage is found.`,
+	Synopsis: []string{
+		"[--verbose]",
+	},
+}
+
+type FsckCmd struct{}
+
+// Name is returns the name of the Dolt cli command. This is what is used on the command line to invoke the command
+func (cmd FsckCmd) Name() string {
+	return "fsck"
+}
+
+// Description returns a description of the command
+func (cmd FsckCmd) Description() string {
+	return fsckDocs.ShortDesc
+}
+
+// RequiresRepo should return false if this interface is implemented, and the command does not have the requirement
+// that it be run from within a data repository directory. fsck checks the table files of the repository itself, so
+// that it can report damage which stops the database from being loaded.
+func (cmd FsckCmd) RequiresRepo() bool {
+	return false
+}
+
+// CreateMarkdown creates a markdown file containing the helptext for the command at the given path
+func (cmd FsckCmd) CreateMarkdown(wr io.Writer, commandStr string) error {
+	ap := cmd.ArgParser()
+	return CreateMarkdown(wr, cli.GetCommandDocumentation(commandStr, fsckDocs, ap))
+}
+
+func (cmd FsckCmd) ArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParser()
+	ap.SupportsFlag(fsckVerboseFlag, "v", "List every orphaned chunk.")
+	return ap
+}
+
+// Exec executes the command
+func (cmd FsckCmd) Exec(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv) int {
+	ap := cmd.ArgParser()
+	help, usage := cli.HelpAndUsagePrinters(cli.GetCommandDocumentation(commandStr, fsckDocs, ap))
+	apr := cli.ParseArgsOrDie(ap, args, help)
+
+	if apr.NArg() > 0 {
+		return HandleVErrAndExitCode(errhand.BuildDError("").SetPrintUsage().Build(), usage)
+	}
+
+	if !dEnv.HasDoltDir() {
+		cli.PrintErrln(color.RedString("The current directory is not a valid dolt repository."))
+		return 2
+	}
+
+	damaged := false
+	stored := hash.NewHashSet()
+
+	keys, err := dbfactory.LoadEncryptionKeyring()
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.BuildDError("error: failed to read the encryption key file").AddCause(err).Build(), usage)
+	}
+
+	dir := filepath.Join(dEnv.GetDoltDir(), dbfactory.DataDir)
+	for _, storeDir := range []string{dir, filepath.Join(dir, "oldgen")} {
+		check, err := nbs.CheckLocalStore(ctx, storeDir, keys)
+		if err != nil {
+			cli.PrintErrln(color.RedString("error: failed to read the manifest of %s: %v", storeDir, err))
+			damaged = true
+			continue
+		} else if check == nil {
+			continue
+		}
+
+		cli.Printf("checked %d table files in %s\n", len(check.Tables), storeDir)
+		for _, tf := range check.Tables {
+			printTableFileCheck(tf)
+		}
+
+		damaged = damaged || check.Damaged()
+		for h := range check.Chunks {
+			stored.Insert(h)
+		}
+	}
+
+	if dEnv.DBLoadError != nil {
+		cli.PrintErrln(color.RedString("error: failed to load the database: %v", dEnv.DBLoadError))
+		return 1
+	}
+
+	report, err := dEnv.DoltDB.Fsck(ctx, stored)
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.BuildDError("error: failed to check the database").AddCause(err).Build(), usage)
+	}
+
+	cli.Printf("checked %d refs and %d reachable chunks\n", report.Refs, report.Reachable)
+	for _, warning := range report.Warnings {
+		cli.PrintErrln(color.YellowString("warning: %s", warning))
+	}
+	printDamagedChunks("missing chunk", report.Missing)
+	printDamagedChunks("corrupt chunk", report.Corrupt)
+	printDamagedChunks("invalid value in chunk", report.Invalid)
+
+	if len(report.Orphaned) > 0 {
+		cli.Printf("%d orphaned chunks, which can be removed with dolt gc\n", len(report.Orphaned))
+		if report.Damaged() {
+			cli.Println("chunks which are only referenced by missing or corrupt chunks are counted as orphaned")
+		}
+		if apr.Contains(fsckVerboseFlag) {
+			for _, h := range report.Orphaned {
+				cli.Printf("orphaned chunk %s\n", h.String())
+			}
+		}
+	}
+
+	if damaged || report.Damaged() {
+		cli.PrintErrln(color.RedString("damage found"))
+		return 1
+	}
+
+	cli.Println(color.GreenString("no damage found"))
+	return 0
+}
+
+func printTableFileCheck(tf nbs.TableFileCheck) {
+	if tf.Err != nil {
+		cli.PrintErrln(color.RedString("damaged table file %s: %v", tf.Path, tf.Err))
+	}
+
+	for _, cc := range tf.Corrupt {
+		cli.PrintErrln(color.RedString("corrupt chunk %s in table file %s: %v", cc.Hash.String(), tf.Path, cc.Err))
+	}
+}
+
+func printDamagedChunks(desc string, damaged []doltdb.DamagedChunk) {
+	for _, dc := range damaged {
+		cli.PrintErrln(color.RedString("%s %s: %v", desc, dc.Hash.String(), dc.Err))
+		cli.PrintErrln(color.RedString("\tneeded by %s", strings.Join(dc.Refs, ", ")))
+	}
+}
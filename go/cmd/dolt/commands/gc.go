@@ -125,7 +125,7 @@ func (cmd GarbageCollectionCmd) Exec(ctx context.Context, commandStr string, arg
 			return HandleVErrAndExitCode(verr, usage)
 		}
 
-		err = dEnv.DoltDB.GC(ctx, keepers...)
+		_, err = dEnv.DoltDB.GC(ctx, keepers...)
 		if err != nil {
 			if errors.Is(err, chunks.ErrNothingToCollect) {
 				cli.PrintErrln(color.YellowString("Nothing to collect."))
@@ -50,6 +50,8 @@ type logNode struct {
 	commitMeta   *doltdb.CommitMeta
 	commitHash   hash.Hash
 	parentHashes []hash.Hash
+	// grafted is set for the shallow commits of a shallow clone, whose parents are not available
+	grafted bool
 }
 
 var logDocs = cli.CommandDocumentationContent{
@@ -175,6 +177,7 @@ func logCommits(ctx context.Context, dEnv *env.DoltEnv, cs *doltdb.CommitSpec, o
 		return 1
 	}
 
+	shallow := dEnv.DoltDB.ShallowCommits()
 	var commitsInfo []logNode
 	for _, comm := range commits {
 		meta, mErr := comm.GetCommitMeta()
@@ -195,7 +198,7 @@ func logCommits(ctx context.Context, dEnv *env.DoltEnv, cs *doltdb.CommitSpec, o
 			return 1
 		}
 
-		commitsInfo = append(commitsInfo, logNode{meta, cmHash, pHashes})
+		commitsInfo = append(commitsInfo, logNode{meta, cmHash, pHashes, shallow.Has(cmHash)})
 	}
 
 	logToStdOut(opts, commitsInfo)
@@ -293,7 +296,7 @@ func logTableCommits(ctx context.Context, dEnv *env.DoltEnv, opts logOpts, cs *d
 				return err
 			}
 
-			commitsInfo = append(commitsInfo, logNode{meta, prevHash, ph})
+			commitsInfo = append(commitsInfo, logNode{meta, prevHash, ph, dEnv.DoltDB.ShallowCommits().Has(prevHash)})
 
 			numLines--
 		}
@@ -327,6 +330,10 @@ func logToStdOut(opts logOpts, commits []logNode) {
 				}
 			}
 
+			if comm.grafted {
+				chStr += " (grafted)"
+			}
+
 			pager.Writer.Write([]byte(fmt.Sprintf("\033[1;33mcommit %s \033[0m", chStr)))
 
 			if len(comm.parentHashes) > 1 {
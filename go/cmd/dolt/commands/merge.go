@@ -41,6 +41,8 @@ var mergeDocs = cli.CommandDocumentationContent{
 The second syntax ({{.LessThan}}dolt merge --abort{{.GreaterThan}}) can only be run after the merge has resulted in conflicts. dolt merge {{.EmphasisLeft}}--abort{{.EmphasisRight}} will abort the merge process and try to reconstruct the pre-merge state. However, if there were uncommitted changes when the merge started (and especially if those changes were further modified after the merge was started), dolt merge {{.EmphasisLeft}}--abort{{.EmphasisRight}} will in some cases be unable to reconstruct the original (pre-merge) changes. Therefore: 
 
 {{.LessThan}}Warning{{.GreaterThan}}: Running dolt merge with non-trivial uncommitted changes is discouraged: while possible, it may leave you in a state that is hard to back out of in the case of a conflict.
+
+A row changed on both sides of the merge is a conflict if any column was changed to different values on each side. Columns can instead be merged with a strategy configured in the {{.EmphasisLeft}}dolt_merge_strategies{{.EmphasisRight}} table, which has a row for each column of each table with a strategy. Strategies are read from the current branch and are one of {{.EmphasisLeft}}ours{{.EmphasisRight}}, {{.EmphasisLeft}}theirs{{.EmphasisRight}}, {{.EmphasisLeft}}max{{.EmphasisRight}}, {{.EmphasisLeft}}min{{.EmphasisRight}}, {{.EmphasisLeft}}sum-of-deltas{{.EmphasisRight}}, which applies the changes made on both sides to the ancestor's value, and {{.EmphasisLeft}}latest-timestamp{{.EmphasisRight}}, which takes the value from the side with the later value in the row's {{.EmphasisLeft}}timestamp_column{{.EmphasisRight}}, or in the column itself. A row is still a conflict if a strategy does not apply to its values, such as when either is NULL. Strategies are not applied to keyless tables.
 `,
 
 	Synopsis: []string{
@@ -106,6 +108,17 @@ func (cmd MergeCmd) Exec(ctx context.Context, commandStr string, args []string,
 			return 1
 		}
 
+		ws, err := dEnv.WorkingSet(ctx)
+		if err != nil {
+			cli.PrintErrln("fatal:", err.Error())
+			return 1
+		}
+
+		if ws.RebaseActive() {
+			cli.PrintErrln("fatal: A rebase is in progress. Use 'dolt rebase --abort' to abort it")
+			return 1
+		}
+
 		verr = abortMerge(ctx, dEnv)
 	} else {
 		if apr.NArg() != 1 {
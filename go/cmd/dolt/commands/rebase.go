@@ -0,0 +1,340 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env/actions"
+	"github.com/dolthub/dolt/go/libraries/doltcore/merge"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor"
+	"github.com/dolthub/dolt/go/libraries/utils/argparser"
+)
+
+var rebaseDocs = cli.CommandDocumentationContent{
+	ShortDesc: "Reapply commits on top of another base commit",
+	LongDesc: `Replays the commits made on the current branch since it diverged from {{.LessThan}}upstream{{.GreaterThan}} on top of {{.LessThan}}upstream{{.GreaterThan}}, resulting in a linear history. The commits to replay are those reachable from HEAD that are not reachable from {{.LessThan}}upstream{{.GreaterThan}}, which is found using the merge base of the two. Merge commits are not replayed. This requires a clean working set.
+
+Each commit is replayed by way of a three-way merge, in the same way as {{.EmphasisLeft}}dolt cherry-pick{{.EmphasisRight}}, keeping the author and message of the original commit. Commits that no longer introduce any changes are dropped. Once all commits have been replayed, the current branch is updated to point at the last of them.
+
+If replaying a commit results in conflicts or constraint violations, the rebase stops, and they are written to the working set in the same way that {{.EmphasisLeft}}dolt merge{{.EmphasisRight}} writes them. Once they have been resolved, add the affected tables and run {{.EmphasisLeft}}dolt rebase --continue{{.EmphasisRight}} to commit the result and replay the remaining commits. To abandon the rebase and return the branch to its original state, run {{.EmphasisLeft}}dolt rebase --abort{{.EmphasisRight}}.
+
+The commits replayed and the way they are replayed may be controlled by a plan file given with {{.EmphasisLeft}}--plan{{.EmphasisRight}}. Each line of a plan file consists of an action followed by a commit, and commits are replayed in the order they are listed. Commits that are not listed are not replayed. Blank lines and lines beginning with # are ignored. The supported actions are:
+
+pick (or p): replay the commit.
+squash (or s): replay the commit and fold it into the previous commit, combining their messages.
+drop (or d): do not replay the commit.
+
+For example:
+
+	pick 2ei5ac7gkvf2ebh4cdsh23tefn2ai4k9 insert rows
+	squash 3fmhh9lbmdp4h5i3sfb0obm3bbq4gsk0 fix typo
+	drop ns7kljfbkdl53ej5gbu7vhhq7ufvq4ud debugging`,
+	Synopsis: []string{
+		"[--plan {{.LessThan}}file{{.GreaterThan}}] {{.LessThan}}upstream{{.GreaterThan}}",
+		"--continue",
+		"--abort",
+	},
+}
+
+const (
+	rebasePlanParam     = "plan"
+	rebaseContinueParam = "continue"
+)
+
+type RebaseCmd struct{}
+
+var _ cli.Command = RebaseCmd{}
+
+// Name implements the interface cli.Command.
+func (cmd RebaseCmd) Name() string {
+	return "rebase"
+}
+
+// Description implements the interface cli.Command.
+func (cmd RebaseCmd) Description() string {
+	return "Reapply commits on top of another base commit."
+}
+
+// CreateMarkdown implements the interface cli.Command.
+func (cmd RebaseCmd) CreateMarkdown(wr io.Writer, commandStr string) error {
+	ap := cmd.ArgParser()
+	return CreateMarkdown(wr, cli.GetCommandDocumentation(commandStr, rebaseDocs, ap))
+}
+
+func (cmd RebaseCmd) ArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParser()
+	ap.ArgListHelp = append(ap.ArgListHelp, [2]string{"upstream", "The branch or commit to replay the commits of the current branch on top of."})
+	ap.SupportsString(rebasePlanParam, "", "file", "Replay commits according to the plan in {{.LessThan}}file{{.GreaterThan}} rather than picking every commit.")
+	ap.SupportsFlag(rebaseContinueParam, "", "Commit the resolved changes of the commit the rebase stopped on, and replay the remaining commits.")
+	ap.SupportsFlag(cli.AbortParam, "", "Abort the rebase, returning the current branch and working set to their state before the rebase started.")
+	return ap
+}
+
+// Exec implements the interface cli.Command.
+func (cmd RebaseCmd) Exec(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv) int {
+	ap := cmd.ArgParser()
+	help, usage := cli.HelpAndUsagePrinters(cli.GetCommandDocumentation(commandStr, rebaseDocs, ap))
+	apr := cli.ParseArgsOrDie(ap, args, help)
+
+	if apr.ContainsAll(rebaseContinueParam, cli.AbortParam) {
+		cli.PrintErrf("error: Flags '--%s' and '--%s' cannot be used together.\n", rebaseContinueParam, cli.AbortParam)
+		return 1
+	}
+
+	var verr errhand.VerboseError
+	switch {
+	case apr.Contains(cli.AbortParam):
+		verr = abortRebase(ctx, dEnv)
+	case apr.Contains(rebaseContinueParam):
+		verr = continueRebase(ctx, dEnv)
+	default:
+		if apr.NArg() != 1 {
+			usage()
+			return 1
+		}
+		verr = startRebase(ctx, dEnv, apr)
+	}
+
+	return HandleVErrAndExitCode(verr, usage)
+}
+
+func startRebase(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgParseResults) errhand.VerboseError {
+	ws, err := dEnv.WorkingSet(ctx)
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+	if ws.RebaseActive() {
+		return errhand.BuildDError("error: a rebase is already in progress.").
+			AddDetails("hint: use 'dolt rebase --continue' or 'dolt rebase --abort' to finish it.").Build()
+	} else if ws.MergeActive() {
+		return errhand.BuildDError("error: cannot rebase while a merge is in progress").Build()
+	}
+
+	roots, err := dEnv.Roots(ctx)
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+	if same, err := doltdb.RootsEqual(roots.Head, roots.Working, roots.Staged); err != nil {
+		return errhand.VerboseErrorFromError(err)
+	} else if !same {
+		return errhand.BuildDError("error: cannot rebase: you have uncommitted changes.").
+			AddDetails("hint: commit or stash them first.").Build()
+	}
+
+	headRef := dEnv.RepoStateReader().CWBHeadRef()
+	head, err := dEnv.DoltDB.ResolveCommitRef(ctx, headRef)
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+
+	upstreamSpec, err := doltdb.NewCommitSpec(apr.Arg(0))
+	if err != nil {
+		return errhand.BuildDError("error: invalid upstream '%s'", apr.Arg(0)).AddCause(err).Build()
+	}
+	upstream, err := dEnv.DoltDB.Resolve(ctx, upstreamSpec, headRef)
+	if err != nil {
+		return errhand.BuildDError("error: invalid upstream '%s'", apr.Arg(0)).AddCause(err).Build()
+	}
+
+	var steps []merge.RebaseStep
+	if planFile, ok := apr.GetValue(rebasePlanParam); ok {
+		plan, err := dEnv.FS.ReadFile(planFile)
+		if err != nil {
+			return errhand.BuildDError("error: could not read plan file '%s'", planFile).AddCause(err).Build()
+		}
+		steps, err = merge.ParseRebasePlan(ctx, dEnv.DoltDB, headRef, string(plan))
+		if err != nil {
+			return errhand.VerboseErrorFromError(err)
+		}
+	} else {
+		baseHash, err := merge.MergeBase(ctx, head, upstream)
+		if err != nil {
+			return errhand.VerboseErrorFromError(err)
+		}
+		upstreamHash, err := upstream.HashOf()
+		if err != nil {
+			return errhand.VerboseErrorFromError(err)
+		}
+		if baseHash == upstreamHash {
+			cli.Printf("Current branch %s is up to date.\n", headRef.GetPath())
+			return nil
+		}
+
+		commits, err := merge.RebaseCommits(ctx, dEnv.DoltDB, head, upstream)
+		if err != nil {
+			return errhand.VerboseErrorFromError(err)
+		}
+		steps = merge.NewRebasePlan(commits)
+	}
+
+	err = merge.ValidateRebasePlan(steps)
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+
+	opts := editor.Options{Deaf: dEnv.DbEaFactory()}
+	res, err := merge.ReplayRebasePlan(ctx, dEnv.DoltDB, upstream, upstream, steps, opts)
+	if err != nil {
+		return errhand.BuildDError("error: rebase failed").AddCause(err).Build()
+	}
+
+	rebaseState := doltdb.NewRebaseState(upstream, head, "", "")
+	return finishRebase(ctx, dEnv, ws, res, rebaseState, roots.Working)
+}
+
+func continueRebase(ctx context.Context, dEnv *env.DoltEnv) errhand.VerboseError {
+	ws, err := dEnv.WorkingSet(ctx)
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+	if !ws.RebaseActive() {
+		return errhand.BuildDError("fatal: There is no rebase in progress").Build()
+	}
+
+	roots, err := dEnv.Roots(ctx)
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+	if verr := checkNoUnresolvedChanges(ctx, roots.Working); verr != nil {
+		return verr
+	}
+	if same, err := doltdb.RootsEqual(roots.Working, roots.Staged); err != nil {
+		return errhand.VerboseErrorFromError(err)
+	} else if !same {
+		return errhand.BuildDError("error: you have unstaged changes.").
+			AddDetails("hint: add them using 'dolt add <table>' and then run 'dolt rebase --continue'.").Build()
+	}
+
+	headRef := dEnv.RepoStateReader().CWBHeadRef()
+	head, err := dEnv.DoltDB.ResolveCommitRef(ctx, headRef)
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+
+	mergeState := ws.MergeState()
+	rebaseState := mergeState.RebaseState()
+	step := merge.RebaseStep{Action: merge.RebaseAction(rebaseState.Action()), Commit: mergeState.Commit()}
+
+	head, err = merge.CommitRebaseStep(ctx, dEnv.DoltDB, rebaseState.Onto(), head, step, roots.Staged)
+	if err != nil {
+		return errhand.BuildDError("error: failed to commit rebased changes").AddCause(err).Build()
+	}
+
+	remaining, err := merge.ParseRebasePlan(ctx, dEnv.DoltDB, headRef, rebaseState.Todo())
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+
+	opts := editor.Options{Deaf: dEnv.DbEaFactory()}
+	res, err := merge.ReplayRebasePlan(ctx, dEnv.DoltDB, rebaseState.Onto(), head, remaining, opts)
+	if err != nil {
+		return errhand.BuildDError("error: rebase failed").AddCause(err).Build()
+	}
+
+	return finishRebase(ctx, dEnv, ws, res, rebaseState, mergeState.PreMergeWorkingRoot())
+}
+
+func abortRebase(ctx context.Context, dEnv *env.DoltEnv) errhand.VerboseError {
+	ws, err := dEnv.WorkingSet(ctx)
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+	if !ws.RebaseActive() {
+		return errhand.BuildDError("fatal: There is no rebase in progress").Build()
+	}
+
+	err = dEnv.DoltDB.SetHeadToCommit(ctx, dEnv.RepoStateReader().CWBHeadRef(), ws.MergeState().RebaseState().OrigHead())
+	if err != nil {
+		return errhand.BuildDError("fatal: failed to reset branch").AddCause(err).Build()
+	}
+
+	err = dEnv.UpdateWorkingSet(ctx, ws.AbortMerge())
+	if err != nil {
+		return errhand.BuildDError("fatal: failed to revert changes").AddCause(err).Build()
+	}
+
+	err = actions.SaveTrackedDocsFromWorking(ctx, dEnv)
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+
+	return nil
+}
+
+// finishRebase updates the current branch and working set with the result of replaying a rebase plan. If the rebase
+// stopped on conflicts, the working set records the progress of the rebase so that it may be continued or aborted.
+func finishRebase(ctx context.Context, dEnv *env.DoltEnv, ws *doltdb.WorkingSet, res *merge.RebaseResult, rebaseState *doltdb.RebaseState, preRebaseWorking *doltdb.RootValue) errhand.VerboseError {
+	headRef := dEnv.RepoStateReader().CWBHeadRef()
+	err := dEnv.DoltDB.SetHeadToCommit(ctx, headRef, res.Head)
+	if err != nil {
+		return errhand.BuildDError("error: failed to update branch %s", headRef.GetPath()).AddCause(err).Build()
+	}
+
+	headRoot, err := res.Head.GetRootValue()
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+
+	if !res.Stopped {
+		err = dEnv.UpdateWorkingSet(ctx, ws.WithWorkingRoot(headRoot).WithStagedRoot(headRoot).ClearMerge())
+		if err != nil {
+			return errhand.VerboseErrorFromError(err)
+		}
+		err = actions.SaveTrackedDocsFromWorking(ctx, dEnv)
+		if err != nil {
+			return errhand.VerboseErrorFromError(err)
+		}
+
+		cli.Printf("Successfully rebased and updated %s.\n", headRef.String())
+		return nil
+	}
+
+	todo, err := merge.FormatRebasePlan(res.Remaining)
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+
+	rebaseState = doltdb.NewRebaseState(rebaseState.Onto(), rebaseState.OrigHead(), string(res.Step.Action), todo)
+	ws = ws.WithWorkingRoot(res.WorkingRoot).WithStagedRoot(headRoot).StartRebase(res.Step.Commit, preRebaseWorking, rebaseState)
+	err = dEnv.UpdateWorkingSet(ctx, ws)
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+	err = actions.SaveTrackedDocsFromWorking(ctx, dEnv)
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+
+	h, err := res.Step.Commit.HashOf()
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+	meta, err := res.Step.Commit.GetCommitMeta()
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+
+	printConflictsAndViolations(res.TblToStats)
+	return errhand.BuildDError("error: could not apply %s... %s", h.String(), strings.SplitN(meta.Description, "\n", 2)[0]).
+		AddDetails("hint: Resolve all conflicts and constraint violations, then add the affected tables using 'dolt add <table>'\n" +
+			"hint: and run 'dolt rebase --continue'. To abort the rebase, run 'dolt rebase --abort'.").Build()
+}
@@ -0,0 +1,158 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/fatih/color"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/libraries/utils/argparser"
+)
+
+var reflogDocs = cli.CommandDocumentationContent{
+	ShortDesc: "Show the history of updates to branches, tags and working sets",
+	LongDesc: `Shows the entries of the reflog, which records every update made to the branches, tags and working sets of the local database, most recent first. A command which updates a working set many times in quick succession is recorded at most once a second. Each entry shows the hash the ref pointed to after the update, and the command that made it, shortened if it is long. The most recent entry for a ref is referred to as {{.EmphasisLeft}}ref@{0}{{.EmphasisRight}}, the one before it as {{.EmphasisLeft}}ref@{1}{{.EmphasisRight}}, and so on.
+
+The reflog can be used to find commits that are no longer reachable from any branch, e.g. after a {{.EmphasisLeft}}dolt reset --hard{{.EmphasisRight}} or after deleting a branch. Values referenced by reflog entries are kept by {{.EmphasisLeft}}dolt gc{{.EmphasisRight}} until the entries expire, after the number of days given by the {{.EmphasisLeft}}reflog.expiredays{{.EmphasisRight}} config setting, 90 by default.
+
+The reflog is local to a database, and is not pushed or cloned. It may also be queried using the {{.EmphasisLeft}}dolt_reflog{{.EmphasisRight}} system table, which includes the hash each ref pointed to before each update, and the user and time of the update.
+
+When no ref is given, shows the reflog of the current branch. A ref may be the name of a branch or a tag, including one that has been deleted, or the full path of any ref, e.g. {{.EmphasisLeft}}workingSets/heads/main{{.EmphasisRight}}.`,
+
+	Synopsis: []string{
+		"[{{.LessThan}}ref{{.GreaterThan}}]",
+		"--all",
+	},
+}
+
+const reflogAllFlag = "all"
+
+type ReflogCmd struct{}
+
+// Name is returns the name of the Dolt cli command. This is what is used on the command line to invoke the command
+func (cmd ReflogCmd) Name() string {
+	return "reflog"
+}
+
+// Description returns a description of the command
+func (cmd ReflogCmd) Description() string {
+	return "Show the history of updates to branches, tags and working sets."
+}
+
+// CreateMarkdown creates a markdown file containing the helptext for the command at the given path
+func (cmd ReflogCmd) CreateMarkdown(wr io.Writer, commandStr string) error {
+	ap := cmd.ArgParser()
+	return CreateMarkdown(wr, cli.GetCommandDocumentation(commandStr, reflogDocs, ap))
+}
+
+func (cmd ReflogCmd) ArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParser()
+	ap.ArgListHelp = append(ap.ArgListHelp, [2]string{"ref", "The branch, tag or ref whose reflog is shown. Defaults to the current branch."})
+	ap.SupportsFlag(reflogAllFlag, "", "Show the reflog of every ref.")
+	return ap
+}
+
+// Exec executes the command
+func (cmd ReflogCmd) Exec(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv) int {
+	ap := cmd.ArgParser()
+	help, usage := cli.HelpAndUsagePrinters(cli.GetCommandDocumentation(commandStr, reflogDocs, ap))
+	apr := cli.ParseArgsOrDie(ap, args, help)
+
+	if apr.NArg() > 1 || (apr.NArg() > 0 && apr.Contains(reflogAllFlag)) {
+		return HandleVErrAndExitCode(errhand.BuildDError("").SetPrintUsage().Build(), usage)
+	}
+
+	rl := dEnv.DoltDB.Reflog()
+	if rl == nil {
+		return HandleVErrAndExitCode(errhand.BuildDError("fatal: this database does not keep a reflog").Build(), usage)
+	}
+
+	var entries []doltdb.ReflogEntry
+	var err error
+	if apr.Contains(reflogAllFlag) {
+		entries, err = rl.Entries()
+	} else {
+		entries, err = reflogEntriesForArg(ctx, dEnv, rl, apr)
+	}
+
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		printReflogEntry(entries[i])
+	}
+
+	return 0
+}
+
+// reflogEntriesForArg returns the reflog entries for the ref named in the arguments given, or for the current branch if
+// none is named. Branch and tag names are matched against the reflog rather than the database, so that the reflog of a
+// deleted branch or tag can be shown.
+func reflogEntriesForArg(ctx context.Context, dEnv *env.DoltEnv, rl *doltdb.Reflog, apr *argparser.ArgParseResults) ([]doltdb.ReflogEntry, error) {
+	if apr.NArg() == 0 {
+		return rl.EntriesForRef(dEnv.RepoStateReader().CWBHeadRef().String())
+	}
+
+	name := apr.Arg(0)
+	branchRef, tagRef := ref.NewBranchRef(name), ref.NewTagRef(name)
+	for _, refPath := range []string{name, branchRef.String(), tagRef.String()} {
+		entries, err := rl.EntriesForRef(refPath)
+		if err != nil {
+			return nil, err
+		}
+		if len(entries) > 0 {
+			return entries, nil
+		}
+	}
+
+	for _, dref := range []ref.DoltRef{branchRef, tagRef} {
+		if has, err := dEnv.DoltDB.HasRef(ctx, dref); err != nil {
+			return nil, err
+		} else if has {
+			return nil, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown ref '%s'", name)
+}
+
+func printReflogEntry(e doltdb.ReflogEntry) {
+	h, suffix := e.NewHash, ""
+	if h.IsEmpty() {
+		h, suffix = e.OldHash, " (deleted)"
+	}
+
+	cli.Println(fmt.Sprintf("%s %s@{%d}: %s%s", color.YellowString(h.String()), reflogRefName(e.Ref), e.Index, e.Command, suffix))
+}
+
+// reflogRefName returns the name of the ref with the full path given, as displayed in the reflog.
+func reflogRefName(refPath string) string {
+	if ref.IsRef(refPath) {
+		if dref, err := ref.Parse(refPath); err == nil && dref.GetType() == ref.BranchRefType {
+			return dref.GetPath()
+		}
+	}
+
+	return refPath
+}
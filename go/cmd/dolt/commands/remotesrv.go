@@ -0,0 +1,195 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/remotesrv"
+	"github.com/dolthub/dolt/go/libraries/utils/argparser"
+)
+
+const (
+	remoteSrvConfigFlag     = "config"
+	remoteSrvDirFlag        = "dir"
+	remoteSrvGRPCPortFlag   = "grpc-port"
+	remoteSrvHTTPPortFlag   = "http-port"
+	remoteSrvHTTPHostFlag   = "http-host"
+	remoteSrvAuthFileFlag   = "auth-file"
+	remoteSrvReadOnlyFlag   = "read-only"
+	remoteSrvAutoCreateFlag = "auto-create-repos"
+	remoteSrvTLSCertFlag    = "tls-cert"
+	remoteSrvTLSKeyFlag     = "tls-key"
+	remoteSrvLogLevelFlag   = "loglevel"
+)
+
+var remoteSrvDocs = cli.CommandDocumentationContent{
+	ShortDesc: "Start a remote server which dolt repositories can clone, fetch from and push to.",
+	LongDesc: `Serves the repositories of a data directory as dolt remotes. Repositories are stored as {{.EmphasisLeft}}<org>/<repo>{{.EmphasisRight}} directories of the data directory, and are used as the remote url {{.EmphasisLeft}}http://<host>:<grpc-port>/<org>/<repo>{{.EmphasisRight}}.
+
+The server is made up of a grpc server implementing the remote api, and an http server serving the table files of the repositories. The http server also serves a repository api at {{.EmphasisLeft}}/api/v1alpha1/repos{{.EmphasisRight}}: a GET lists the repositories, a GET of {{.EmphasisLeft}}/api/v1alpha1/repos/<org>/<repo>{{.EmphasisRight}} describes a repository, and a PUT of it creates the repository.
+
+Parameters can be specified using a yaml configuration file passed via {{.EmphasisLeft}}--config <file>{{.EmphasisRight}}. Command line parameters override the values of the config file. This is an example yaml configuration file showing all supported items and their default values:
+
+` + indentLines(remotesrv.DefaultConfig().String()) + `
+When {{.EmphasisLeft}}auth_file{{.EmphasisRight}} is set, requests are authorized against the users of the credentials file, and the permissions they have on each repository. When {{.EmphasisLeft}}tls.cert{{.EmphasisRight}} and {{.EmphasisLeft}}tls.key{{.EmphasisRight}} are set, both servers use TLS. {{.EmphasisLeft}}quotas{{.EmphasisRight}} limits the total size of the table files of repositories, in bytes. Prometheus metrics are served at {{.EmphasisLeft}}/metrics{{.EmphasisRight}} when {{.EmphasisLeft}}metrics.port{{.EmphasisRight}} is positive.
+
+The server stops on SIGINT or SIGTERM, waiting up to {{.EmphasisLeft}}shutdown_timeout_millis{{.EmphasisRight}} for requests in flight to finish.`,
+	Synopsis: []string{
+		"[--config {{.LessThan}}file{{.GreaterThan}}] [--dir {{.LessThan}}directory{{.GreaterThan}}] [--grpc-port {{.LessThan}}port{{.GreaterThan}}] [--http-port {{.LessThan}}port{{.GreaterThan}}] [--http-host {{.LessThan}}host{{.GreaterThan}}] [--auth-file {{.LessThan}}file{{.GreaterThan}}] [--read-only] [--auto-create-repos]",
+	},
+}
+
+func indentLines(s string) string {
+	sb := strings.Builder{}
+	for _, line := range strings.Split(strings.TrimRight(s, "\n"), "\n") {
+		sb.WriteRune('\t')
+		sb.WriteString(line)
+		sb.WriteRune('\n')
+	}
+	return sb.String()
+}
+
+type RemoteSrvCmd struct{}
+
+// Name is returns the name of the Dolt cli command. This is what is used on the command line to invoke the command
+func (cmd RemoteSrvCmd) Name() string {
+	return "remotesrv"
+}
+
+// Description returns a description of the command
+func (cmd RemoteSrvCmd) Description() string {
+	return remoteSrvDocs.ShortDesc
+}
+
+// RequiresRepo should return false if this interface is implemented, and the command does not have the requirement
+// that it be run from within a data repository directory
+func (cmd RemoteSrvCmd) RequiresRepo() bool {
+	return false
+}
+
+// CreateMarkdown creates a markdown file containing the helptext for the command at the given path
+func (cmd RemoteSrvCmd) CreateMarkdown(wr io.Writer, commandStr string) error {
+	ap := cmd.ArgParser()
+	return CreateMarkdown(wr, cli.GetCommandDocumentation(commandStr, remoteSrvDocs, ap))
+}
+
+func (cmd RemoteSrvCmd) ArgParser() *argparser.ArgParser {
+	cfg := remotesrv.DefaultConfig()
+
+	ap := argparser.NewArgParser()
+	ap.SupportsString(remoteSrvConfigFlag, "", "file", "The yaml config file of the server.")
+	ap.SupportsString(remoteSrvDirFlag, "", "directory", "The directory the repositories are stored in (default the current directory).")
+	ap.SupportsInt(remoteSrvGRPCPortFlag, "", "port", fmt.Sprintf("The port the grpc server listens on (default `%d`).", cfg.GRPC.Port))
+	ap.SupportsInt(remoteSrvHTTPPortFlag, "", "port", fmt.Sprintf("The port the http server listens on (default `%d`).", cfg.HTTP.Port))
+	ap.SupportsString(remoteSrvHTTPHostFlag, "", "host", "The host of the http server in the table file urls handed out to clients (default `localhost`).")
+	ap.SupportsString(remoteSrvAuthFileFlag, "", "file", "The credentials file requests are authorized against. When not provided, all requests are allowed.")
+	ap.SupportsFlag(remoteSrvReadOnlyFlag, "r", "Rejects every request which would modify a repository.")
+	ap.SupportsFlag(remoteSrvAutoCreateFlag, "", "Creates repositories which do not exist when they are first accessed by a caller which may write to them, such as by a push.")
+	ap.SupportsString(remoteSrvTLSCertFlag, "", "file", "The certificate used for TLS by both the grpc and http servers.")
+	ap.SupportsString(remoteSrvTLSKeyFlag, "", "file", "The key of the TLS certificate.")
+	ap.SupportsString(remoteSrvLogLevelFlag, "l", "Log level", fmt.Sprintf("The level of logging. Options are: `trace`, `debug`, `info`, `warning`, `error`, `fatal` (default `%s`).", cfg.LogLevel))
+	return ap
+}
+
+// Exec executes the command
+func (cmd RemoteSrvCmd) Exec(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv) int {
+	ap := cmd.ArgParser()
+	help, usage := cli.HelpAndUsagePrinters(cli.GetCommandDocumentation(commandStr, remoteSrvDocs, ap))
+	apr := cli.ParseArgsOrDie(ap, args, help)
+
+	if apr.NArg() > 0 {
+		return HandleVErrAndExitCode(errhand.BuildDError("").SetPrintUsage().Build(), usage)
+	}
+
+	cfg, verr := getRemoteSrvConfig(dEnv, apr)
+	if verr != nil {
+		return HandleVErrAndExitCode(verr, usage)
+	}
+
+	server, err := remotesrv.NewServer(cfg)
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.BuildDError("error: failed to start server").AddCause(err).Build(), nil)
+	}
+
+	server.Start()
+	<-ctx.Done()
+
+	err = server.Stop()
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.BuildDError("error: failed to stop server").AddCause(err).Build(), nil)
+	}
+
+	return 0
+}
+
+func getRemoteSrvConfig(dEnv *env.DoltEnv, apr *argparser.ArgParseResults) (remotesrv.Config, errhand.VerboseError) {
+	cfg := remotesrv.DefaultConfig()
+	if cfgFile, ok := apr.GetValue(remoteSrvConfigFlag); ok {
+		data, err := dEnv.FS.ReadFile(cfgFile)
+		if err != nil {
+			return cfg, errhand.BuildDError("error: failed to read config file '%s'", cfgFile).AddCause(err).Build()
+		}
+
+		cfg, err = remotesrv.ReadConfig(data)
+		if err != nil {
+			return cfg, errhand.BuildDError("error: failed to parse config file '%s'", cfgFile).AddCause(err).Build()
+		}
+	}
+
+	if dir, ok := apr.GetValue(remoteSrvDirFlag); ok {
+		cfg.DataDir = dir
+	}
+	if port, ok := apr.GetInt(remoteSrvGRPCPortFlag); ok {
+		cfg.GRPC.Port = port
+	}
+	if port, ok := apr.GetInt(remoteSrvHTTPPortFlag); ok {
+		cfg.HTTP.Port = port
+	}
+	if host, ok := apr.GetValue(remoteSrvHTTPHostFlag); ok {
+		cfg.HTTP.AdvertiseHost = fmt.Sprintf("%s:%d", host, cfg.HTTP.Port)
+	}
+	if authFile, ok := apr.GetValue(remoteSrvAuthFileFlag); ok {
+		cfg.AuthFile = authFile
+	}
+	if apr.Contains(remoteSrvReadOnlyFlag) {
+		cfg.ReadOnly = true
+	}
+	if apr.Contains(remoteSrvAutoCreateFlag) {
+		cfg.AutoCreateRepos = true
+	}
+	if cert, ok := apr.GetValue(remoteSrvTLSCertFlag); ok {
+		cfg.TLS.Cert = cert
+	}
+	if key, ok := apr.GetValue(remoteSrvTLSKeyFlag); ok {
+		cfg.TLS.Key = key
+	}
+	if logLevel, ok := apr.GetValue(remoteSrvLogLevelFlag); ok {
+		cfg.LogLevel = logLevel
+	}
+
+	err := cfg.Validate()
+	if err != nil {
+		return cfg, errhand.BuildDError("error: invalid configuration").AddCause(err).Build()
+	}
+
+	return cfg, nil
+}
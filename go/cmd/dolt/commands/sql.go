@@ -374,7 +374,7 @@ func execShell(
 	format engine.PrintResultFormat,
 	initialDb string,
 ) errhand.VerboseError {
-	se, err := engine.NewSqlEngine(ctx, mrEnv, format, initialDb, false, nil, true)
+	se, err := engine.NewSqlEngine(ctx, mrEnv, format, initialDb, false, nil, "", true)
 	if err != nil {
 		return errhand.VerboseErrorFromError(err)
 	}
@@ -395,7 +395,7 @@ func execBatch(
 	format engine.PrintResultFormat,
 	initialDb string,
 ) errhand.VerboseError {
-	se, err := engine.NewSqlEngine(ctx, mrEnv, format, initialDb, false, nil, false)
+	se, err := engine.NewSqlEngine(ctx, mrEnv, format, initialDb, false, nil, "", false)
 	if err != nil {
 		return errhand.VerboseErrorFromError(err)
 	}
@@ -430,7 +430,7 @@ func execMultiStatements(
 	format engine.PrintResultFormat,
 	initialDb string,
 ) errhand.VerboseError {
-	se, err := engine.NewSqlEngine(ctx, mrEnv, format, initialDb, false, nil, true)
+	se, err := engine.NewSqlEngine(ctx, mrEnv, format, initialDb, false, nil, "", true)
 	if err != nil {
 		return errhand.VerboseErrorFromError(err)
 	}
@@ -452,7 +452,7 @@ func execQuery(
 	format engine.PrintResultFormat,
 	initialDb string,
 ) errhand.VerboseError {
-	se, err := engine.NewSqlEngine(ctx, mrEnv, format, initialDb, false, nil, true)
+	se, err := engine.NewSqlEngine(ctx, mrEnv, format, initialDb, false, nil, "", true)
 	if err != nil {
 		return errhand.VerboseErrorFromError(err)
 	}
@@ -26,6 +26,7 @@ import (
 	"github.com/dolthub/go-mysql-server/server"
 	"github.com/dolthub/go-mysql-server/sql"
 	"github.com/dolthub/vitess/go/mysql"
+	"github.com/opentracing/opentracing-go"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
 
@@ -33,6 +34,8 @@ import (
 	"github.com/dolthub/dolt/go/cmd/dolt/commands/engine"
 	"github.com/dolthub/dolt/go/libraries/doltcore/env"
 	_ "github.com/dolthub/dolt/go/libraries/doltcore/sqle/dfunctions"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/privileges"
 )
 
 // Serve starts a MySQL-compatible server. Returns any errors that were encountered.
@@ -171,7 +174,17 @@ func Serve(
 			Password: serverConfig.Password(),
 		})
 	}
-	sqlEngine, err := engine.NewSqlEngine(ctx, mrEnv, engine.FormatTabular, "", isReadOnly, tempUsers, serverConfig.AutoCommit())
+
+	// the default privilege file is in the data dir, which is the working dir of the multi repo env's filesystem
+	privilegeFilePath := privileges.DefaultPrivilegeFilePath
+	if len(serverConfig.PrivilegeFilePath()) > 0 {
+		privilegeFilePath, err = dEnv.FS.Abs(serverConfig.PrivilegeFilePath())
+		if err != nil {
+			return err, nil
+		}
+	}
+
+	sqlEngine, err := engine.NewSqlEngine(ctx, mrEnv, engine.FormatTabular, "", isReadOnly, tempUsers, privilegeFilePath, serverConfig.AutoCommit())
 	if err != nil {
 		return err, nil
 	}
@@ -181,7 +194,7 @@ func Serve(
 	listener := newMetricsListener(labels)
 	defer listener.Close()
 
-	mySQLServer, startError = server.NewServer(
+	mySQLServer, startError = newServer(
 		serverConf,
 		sqlEngine.GetUnderlyingEngine(),
 		newSessionBuilder(sqlEngine),
@@ -234,6 +247,75 @@ func portInUse(hostPort string) bool {
 	return false
 }
 
+// newServer creates a server like server.NewServer, whose handler also closes the session of each connection which
+// closes.
+func newServer(cfg server.Config, e *gms.Engine, sb server.SessionBuilder, listener server.ServerEventListener) (*server.Server, error) {
+	tracer := cfg.Tracer
+	if tracer == nil {
+		tracer = opentracing.NoopTracer{}
+	}
+
+	if cfg.ConnReadTimeout < 0 {
+		cfg.ConnReadTimeout = 0
+	}
+	if cfg.ConnWriteTimeout < 0 {
+		cfg.ConnWriteTimeout = 0
+	}
+	if cfg.MaxConnections < 0 {
+		cfg.MaxConnections = 0
+	}
+
+	sm := server.NewSessionManager(sb, tracer, e.Analyzer.Catalog.HasDB, e.MemoryManager, e.ProcessList, cfg.Address)
+	handler := &sessionClosingHandler{
+		Handler: server.NewHandler(e, sm, cfg.ConnReadTimeout, cfg.DisableClientMultiStatements, listener),
+		sm:      sm,
+	}
+
+	l, err := net.Listen(cfg.Protocol, cfg.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	vtListnr, err := mysql.NewListenerWithConfig(mysql.ListenerConfig{
+		Listener:           l,
+		AuthServer:         e.Analyzer.Catalog.GrantTables,
+		Handler:            handler,
+		ConnReadTimeout:    cfg.ConnReadTimeout,
+		ConnWriteTimeout:   cfg.ConnWriteTimeout,
+		MaxConns:           cfg.MaxConnections,
+		ConnReadBufferSize: mysql.DefaultConnBufferSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Version != "" {
+		vtListnr.ServerVersion = cfg.Version
+	}
+	vtListnr.TLSConfig = cfg.TLSConfig
+	vtListnr.RequireSecureTransport = cfg.RequireSecureTransport
+
+	return &server.Server{Listener: vtListnr}, nil
+}
+
+// sessionClosingHandler is a server.Handler which closes the session of a connection when the connection closes, so
+// that the transactions open in the session no longer keep their roots from garbage collection.
+type sessionClosingHandler struct {
+	*server.Handler
+	sm *server.SessionManager
+}
+
+func (h *sessionClosingHandler) ConnectionClosed(c *mysql.Conn) {
+	ctx, err := h.sm.NewContextWithQuery(c, "")
+	if err == nil {
+		if sess, ok := ctx.Session.(*dsess.DoltSession); ok {
+			sess.Close()
+		}
+	}
+
+	h.Handler.ConnectionClosed(c)
+}
+
 func newSessionBuilder(se *engine.SqlEngine) server.SessionBuilder {
 	return func(ctx context.Context, conn *mysql.Conn, host string) (sql.Session, error) {
 		mysqlSess, err := server.DefaultSessionBuilder(ctx, conn, host)
@@ -20,6 +20,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/gocraft/dbr/v2"
@@ -229,6 +230,39 @@ func TestServerSelect(t *testing.T) {
 	}
 }
 
+func TestServerClosedConnectionEndsTransactions(t *testing.T) {
+	dEnv := dtestutils.CreateEnvWithSeedData(t)
+	serverConfig := DefaultServerConfig().withLogLevel(LogLevel_Fatal).withPort(15303)
+
+	sc := NewServerController()
+	defer sc.StopServer()
+	go func() {
+		_, _ = Serve(context.Background(), "", serverConfig, sc, dEnv)
+	}()
+	err := sc.WaitForStart()
+	require.NoError(t, err)
+
+	const dbName = "dolt"
+	db, err := dbr.Open("mysql", ConnectionString(serverConfig)+dbName, nil)
+	require.NoError(t, err)
+	before := len(dsess.OpenTransactionRoots(dEnv.DoltDB))
+	conn, err := db.Conn(context.Background())
+	require.NoError(t, err)
+
+	_, err = conn.ExecContext(context.Background(), "start transaction")
+	require.NoError(t, err)
+	_, err = conn.ExecContext(context.Background(), "update people set age = 33 where name = 'Bill Billerson'")
+	require.NoError(t, err)
+	assert.Greater(t, len(dsess.OpenTransactionRoots(dEnv.DoltDB)), before)
+
+	// the connection closes with its transaction still open
+	require.NoError(t, conn.Close())
+	require.NoError(t, db.Close())
+	assert.Eventually(t, func() bool {
+		return len(dsess.OpenTransactionRoots(dEnv.DoltDB)) == before
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
 // If a port is already in use, throw error "Port XXXX already in use."
 func TestServerFailsIfPortInUse(t *testing.T) {
 	serverController := NewServerController()
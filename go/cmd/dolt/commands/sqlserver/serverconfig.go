@@ -102,6 +102,9 @@ type ServerConfig interface {
 	DatabaseNamesAndPaths() []env.EnvNameAndPath
 	// DataDir is the path to a directory to use as the data dir, both to create new databases and locate existing ones.
 	DataDir() string
+	// PrivilegeFilePath returns the path of the file that accounts, their privileges and branch rules are persisted to.
+	// "" if the default path in the data dir should be used.
+	PrivilegeFilePath() string
 	// MaxConnections returns the maximum number of simultaneous connections the server will allow.  The default is 1
 	MaxConnections() uint64
 	// QueryParallelism returns the parallelism that should be used by the go-mysql-server analyzer
@@ -134,6 +137,7 @@ type commandLineServerConfig struct {
 	logLevel               LogLevel
 	dbNamesAndPaths        []env.EnvNameAndPath
 	dataDir                string
+	privilegeFilePath      string
 	autoCommit             bool
 	maxConnections         uint64
 	queryParallelism       int
@@ -244,6 +248,11 @@ func (cfg *commandLineServerConfig) DataDir() string {
 	return cfg.dataDir
 }
 
+// PrivilegeFilePath returns the path of the file that accounts, their privileges and branch rules are persisted to.
+func (cfg *commandLineServerConfig) PrivilegeFilePath() string {
+	return cfg.privilegeFilePath
+}
+
 // withHost updates the host and returns the called `*commandLineServerConfig`, which is useful for chaining calls.
 func (cfg *commandLineServerConfig) withHost(host string) *commandLineServerConfig {
 	cfg.host = host
@@ -309,6 +318,13 @@ func (cfg *commandLineServerConfig) withDataDir(dataDir string) *commandLineServ
 	return cfg
 }
 
+// withPrivilegeFilePath updates the path of the privilege file and returns the called `*commandLineServerConfig`, which
+// is useful for chaining calls.
+func (cfg *commandLineServerConfig) withPrivilegeFilePath(privilegeFilePath string) *commandLineServerConfig {
+	cfg.privilegeFilePath = privilegeFilePath
+	return cfg
+}
+
 func (cfg *commandLineServerConfig) withPersistenceBehavior(persistenceBehavior string) *commandLineServerConfig {
 	cfg.persistenceBehavior = persistenceBehavior
 	return cfg
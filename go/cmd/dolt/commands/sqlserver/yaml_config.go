@@ -116,6 +116,7 @@ type YAMLConfig struct {
 	DatabaseConfig    []DatabaseYAMLConfig  `yaml:"databases"`
 	PerformanceConfig PerformanceYAMLConfig `yaml:"performance"`
 	DataDirStr        *string               `yaml:"data_dir"`
+	PrivilegeFile     *string               `yaml:"privilege_file"`
 	MetricsConfig     MetricsYAMLConfig     `yaml:"metrics"`
 }
 
@@ -359,3 +360,11 @@ func (cfg YAMLConfig) DataDir() string {
 	}
 	return ""
 }
+
+// PrivilegeFilePath returns the path of the file that accounts, their privileges and branch rules are persisted to.
+func (cfg YAMLConfig) PrivilegeFilePath() string {
+	if cfg.PrivilegeFile != nil {
+		return *cfg.PrivilegeFile
+	}
+	return ""
+}
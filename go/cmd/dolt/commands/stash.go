@@ -0,0 +1,394 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env/actions"
+	"github.com/dolthub/dolt/go/libraries/doltcore/merge"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor"
+	"github.com/dolthub/dolt/go/libraries/utils/argparser"
+	"github.com/dolthub/dolt/go/libraries/utils/set"
+)
+
+var stashDocs = cli.CommandDocumentationContent{
+	ShortDesc: "Stash the changes in a dirty working set away",
+	LongDesc: `Use {{.EmphasisLeft}}dolt stash{{.EmphasisRight}} when you want to record the current state of the working set and staged tables, but want to go back to a clean working set. The command saves your local modifications away and reverts the working set to match the HEAD commit. Tables that have never been staged are left in the working set, unless {{.EmphasisLeft}}--include-untracked{{.EmphasisRight}} is given.
+
+Stashes are stored in the database and are preserved by {{.EmphasisLeft}}dolt gc{{.EmphasisRight}}. The latest stash is referred to as {{.EmphasisLeft}}stash@{0}{{.EmphasisRight}}, the one before it as {{.EmphasisLeft}}stash@{1}{{.EmphasisRight}}, and so on. Stashes may also be queried using the {{.EmphasisLeft}}dolt_stashes{{.EmphasisRight}} system table.
+
+{{.EmphasisLeft}}push{{.EmphasisRight}}
+Save your local modifications to a new stash and revert the working set to HEAD. This is the default when no subcommand is given.
+
+{{.EmphasisLeft}}list{{.EmphasisRight}}
+List the stashes that you currently have, most recent first.
+
+{{.EmphasisLeft}}pop{{.EmphasisRight}}
+Remove a single stash from the stash list and apply it on top of the current working set by way of a three-way merge, using the commit the stash was created on as the common ancestor. If the merge results in conflicts, they are written to the working set in the same way that {{.EmphasisLeft}}dolt merge{{.EmphasisRight}} writes them, and the stash is not removed from the stash list.
+
+{{.EmphasisLeft}}apply{{.EmphasisRight}}
+Like {{.EmphasisLeft}}pop{{.EmphasisRight}}, but do not remove the stash from the stash list.
+
+{{.EmphasisLeft}}drop{{.EmphasisRight}}
+Remove a single stash from the stash list.
+
+{{.EmphasisLeft}}clear{{.EmphasisRight}}
+Remove all the stashes.`,
+
+	Synopsis: []string{
+		"[push] [-u | --include-untracked] [-m {{.LessThan}}message{{.GreaterThan}}]",
+		"list",
+		"pop [{{.LessThan}}stash{{.GreaterThan}}]",
+		"apply [{{.LessThan}}stash{{.GreaterThan}}]",
+		"drop [{{.LessThan}}stash{{.GreaterThan}}]",
+		"clear",
+	},
+}
+
+const (
+	stashPushId  = "push"
+	stashListId  = "list"
+	stashPopId   = "pop"
+	stashApplyId = "apply"
+	stashDropId  = "drop"
+	stashClearId = "clear"
+
+	includeUntrackedFlag = "include-untracked"
+)
+
+type StashCmd struct{}
+
+// Name is returns the name of the Dolt cli command. This is what is used on the command line to invoke the command
+func (cmd StashCmd) Name() string {
+	return "stash"
+}
+
+// Description returns a description of the command
+func (cmd StashCmd) Description() string {
+	return "Stash the changes in a dirty working set away."
+}
+
+// CreateMarkdown creates a markdown file containing the helptext for the command at the given path
+func (cmd StashCmd) CreateMarkdown(wr io.Writer, commandStr string) error {
+	ap := cmd.ArgParser()
+	return CreateMarkdown(wr, cli.GetCommandDocumentation(commandStr, stashDocs, ap))
+}
+
+func (cmd StashCmd) ArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParser()
+	ap.ArgListHelp = append(ap.ArgListHelp, [2]string{"stash", "A stash in the form stash@{n}. Defaults to the most recent stash, stash@{0}."})
+	ap.SupportsString(cli.CommitMessageArg, "m", "msg", "Use the given {{.LessThan}}msg{{.GreaterThan}} to describe the stash.")
+	ap.SupportsFlag(includeUntrackedFlag, "u", "Also stash tables that have never been staged, removing them from the working set.")
+	return ap
+}
+
+// Exec executes the command
+func (cmd StashCmd) Exec(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv) int {
+	ap := cmd.ArgParser()
+	help, usage := cli.HelpAndUsagePrinters(cli.GetCommandDocumentation(commandStr, stashDocs, ap))
+	apr := cli.ParseArgsOrDie(ap, args, help)
+
+	var verr errhand.VerboseError
+
+	switch {
+	case apr.NArg() == 0 || apr.Arg(0) == stashPushId:
+		verr = stashChanges(ctx, dEnv, apr)
+	case apr.Arg(0) == stashListId:
+		verr = listStashes(ctx, dEnv)
+	case apr.Arg(0) == stashPopId:
+		verr = applyStash(ctx, dEnv, apr, true)
+	case apr.Arg(0) == stashApplyId:
+		verr = applyStash(ctx, dEnv, apr, false)
+	case apr.Arg(0) == stashDropId:
+		verr = dropStash(ctx, dEnv, apr)
+	case apr.Arg(0) == stashClearId:
+		verr = clearStashes(ctx, dEnv)
+	default:
+		verr = errhand.BuildDError("").SetPrintUsage().Build()
+	}
+
+	return HandleVErrAndExitCode(verr, usage)
+}
+
+func stashChanges(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgParseResults) errhand.VerboseError {
+	if apr.NArg() > 1 {
+		return errhand.BuildDError("").SetPrintUsage().Build()
+	}
+
+	ws, err := dEnv.WorkingSet(ctx)
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+	if ws.MergeActive() {
+		return errhand.BuildDError("error: cannot stash changes while a merge is in progress").Build()
+	}
+
+	roots, err := dEnv.Roots(ctx)
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+	if verr := checkNoUnresolvedChanges(ctx, roots.Working); verr != nil {
+		return verr
+	}
+
+	stashedWorking := roots.Working
+	newRoots := roots
+	if apr.Contains(includeUntrackedFlag) {
+		newRoots.Working = roots.Head
+		newRoots.Staged = roots.Head
+	} else {
+		// tables that have never been staged are not stashed, and are left in the working set
+		_, newRoots, err = actions.ResetHardTables(ctx, dEnv.DbData(), "", roots)
+		if err != nil {
+			return errhand.VerboseErrorFromError(err)
+		}
+		untracked, err := untrackedTables(ctx, roots)
+		if err != nil {
+			return errhand.VerboseErrorFromError(err)
+		}
+		stashedWorking, err = stashedWorking.RemoveTables(ctx, false, untracked...)
+		if err != nil {
+			return errhand.VerboseErrorFromError(err)
+		}
+	}
+
+	if same, err := doltdb.RootsEqual(roots.Head, stashedWorking, roots.Staged); err != nil {
+		return errhand.VerboseErrorFromError(err)
+	} else if same {
+		cli.Println("No local changes to save")
+		return nil
+	}
+
+	headCommit, err := dEnv.DoltDB.ResolveCommitRef(ctx, dEnv.RepoStateReader().CWBHeadRef())
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+	h, err := headCommit.HashOf()
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+	headMeta, err := headCommit.GetCommitMeta()
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+
+	branchName := dEnv.RepoStateReader().CWBHeadRef().GetPath()
+	desc := fmt.Sprintf("WIP on %s: %s %s", branchName, h.String(), headMeta.Description)
+	if msg, ok := apr.GetValue(cli.CommitMessageArg); ok {
+		desc = fmt.Sprintf("On %s: %s", branchName, msg)
+	}
+
+	name, email, err := env.GetNameAndEmail(dEnv.Config)
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+	meta, err := doltdb.NewCommitMeta(name, email, desc)
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+
+	_, err = dEnv.DoltDB.NewStash(ctx, headCommit, stashedWorking, roots.Staged, meta)
+	if err != nil {
+		return errhand.BuildDError("error: failed to save stash").AddCause(err).Build()
+	}
+
+	err = dEnv.UpdateWorkingSet(ctx, ws.WithWorkingRoot(newRoots.Working).WithStagedRoot(newRoots.Staged))
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+	err = actions.SaveTrackedDocsFromWorking(ctx, dEnv)
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+
+	cli.Println("Saved working set and staged state", desc)
+	return nil
+}
+
+func listStashes(ctx context.Context, dEnv *env.DoltEnv) errhand.VerboseError {
+	stashes, err := dEnv.DoltDB.GetStashes(ctx)
+	if err != nil {
+		return errhand.BuildDError("error: failed to read stashes").AddCause(err).Build()
+	}
+
+	for _, stash := range stashes {
+		meta, err := stash.GetCommitMeta()
+		if err != nil {
+			return errhand.VerboseErrorFromError(err)
+		}
+		cli.Printf("%s: %s\n", stash.Name(), meta.Description)
+	}
+
+	return nil
+}
+
+func applyStash(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgParseResults, drop bool) errhand.VerboseError {
+	stash, verr := resolveStashArg(ctx, dEnv, apr)
+	if verr != nil {
+		return verr
+	}
+
+	ws, err := dEnv.WorkingSet(ctx)
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+	if ws.MergeActive() {
+		return errhand.BuildDError("error: cannot apply a stash while a merge is in progress").Build()
+	}
+
+	roots, err := dEnv.Roots(ctx)
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+	if verr := checkNoUnresolvedChanges(ctx, roots.Working); verr != nil {
+		return verr
+	}
+
+	opts := editor.Options{Deaf: dEnv.DbEaFactory()}
+	roots, tblToStats, err := merge.ApplyStash(ctx, roots, stash, opts)
+	if err != nil {
+		return errhand.BuildDError("error: failed to apply %s", stash.Name()).AddCause(err).Build()
+	}
+
+	err = dEnv.UpdateWorkingSet(ctx, ws.WithWorkingRoot(roots.Working).WithStagedRoot(roots.Staged))
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+	err = actions.SaveTrackedDocsFromWorking(ctx, dEnv)
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+
+	if merge.HasConflictsOrViolations(tblToStats) {
+		printConflictsAndViolations(tblToStats)
+		return errhand.BuildDError("error: applying %s resulted in conflicts or constraint violations; fix them in the working set.", stash.Name()).
+			AddDetails("The stash entry is kept in case you need it again.").Build()
+	}
+
+	if drop {
+		return dropAndPrint(ctx, dEnv, stash)
+	}
+
+	return nil
+}
+
+func dropStash(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgParseResults) errhand.VerboseError {
+	stash, verr := resolveStashArg(ctx, dEnv, apr)
+	if verr != nil {
+		return verr
+	}
+
+	return dropAndPrint(ctx, dEnv, stash)
+}
+
+func clearStashes(ctx context.Context, dEnv *env.DoltEnv) errhand.VerboseError {
+	stashes, err := dEnv.DoltDB.GetStashes(ctx)
+	if err != nil {
+		return errhand.BuildDError("error: failed to read stashes").AddCause(err).Build()
+	}
+
+	for _, stash := range stashes {
+		err = dEnv.DoltDB.DropStash(ctx, stash)
+		if err != nil {
+			return errhand.BuildDError("error: failed to drop %s", stash.Name()).AddCause(err).Build()
+		}
+	}
+
+	return nil
+}
+
+func dropAndPrint(ctx context.Context, dEnv *env.DoltEnv, stash *doltdb.Stash) errhand.VerboseError {
+	h, err := stash.HashOf()
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+
+	err = dEnv.DoltDB.DropStash(ctx, stash)
+	if err != nil {
+		return errhand.BuildDError("error: failed to drop %s", stash.Name()).AddCause(err).Build()
+	}
+
+	cli.Printf("Dropped %s (%s)\n", stash.Name(), h.String())
+	return nil
+}
+
+// resolveStashArg returns the stash named by the optional argument following the subcommand, defaulting to the most
+// recent stash.
+func resolveStashArg(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgParseResults) (*doltdb.Stash, errhand.VerboseError) {
+	if apr.NArg() > 2 {
+		return nil, errhand.BuildDError("").SetPrintUsage().Build()
+	}
+
+	idx := 0
+	if apr.NArg() == 2 {
+		var err error
+		idx, err = doltdb.ParseStashIndex(apr.Arg(1))
+		if err != nil {
+			return nil, errhand.VerboseErrorFromError(err)
+		}
+	}
+
+	stash, err := dEnv.DoltDB.GetStash(ctx, idx)
+	if err != nil {
+		return nil, errhand.VerboseErrorFromError(err)
+	}
+
+	return stash, nil
+}
+
+func checkNoUnresolvedChanges(ctx context.Context, working *doltdb.RootValue) errhand.VerboseError {
+	if hasCnf, err := working.HasConflicts(ctx); err != nil {
+		return errhand.BuildDError("error: failed to get conflicts").AddCause(err).Build()
+	} else if hasCnf {
+		return errhand.BuildDError("error: you have unresolved conflicts in the working set.").Build()
+	}
+
+	if hasCV, err := working.HasConstraintViolations(ctx); err != nil {
+		return errhand.BuildDError("error: failed to get constraint violations").AddCause(err).Build()
+	} else if hasCV {
+		return errhand.BuildDError("error: you have unresolved constraint violations in the working set.").Build()
+	}
+
+	return nil
+}
+
+// untrackedTables returns the names of the tables in the working root that have never been staged.
+func untrackedTables(ctx context.Context, roots doltdb.Roots) ([]string, error) {
+	workingTbls, err := roots.Working.GetTableNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stagedTbls, err := roots.Staged.GetTableNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	staged := set.NewStrSet(stagedTbls)
+	var untracked []string
+	for _, tblName := range workingTbls {
+		if !staged.Contains(tblName) && tblName != doltdb.DocTableName {
+			untracked = append(untracked, tblName)
+		}
+	}
+
+	return untracked, nil
+}
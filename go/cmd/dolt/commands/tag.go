@@ -68,12 +68,8 @@ func (cmd TagCmd) CreateMarkdown(wr io.Writer, commandStr string) error {
 }
 
 func (cmd TagCmd) ArgParser() *argparser.ArgParser {
-	ap := argparser.NewArgParser()
-	// todo: docs
-	ap.ArgListHelp = append(ap.ArgListHelp, [2]string{"ref", "A commit ref that the tag should point at."})
-	ap.SupportsString(tagMessageArg, "m", "msg", "Use the given {{.LessThan}}msg{{.GreaterThan}} as the tag message.")
+	ap := cli.CreateTagArgParser()
 	ap.SupportsFlag(verboseFlag, "v", "list tags along with their metadata.")
-	ap.SupportsFlag(deleteFlag, "d", "Delete a tag.")
 	return ap
 }
 
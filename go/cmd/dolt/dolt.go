@@ -72,6 +72,10 @@ var doltCommand = cli.NewSubCommandHandler("dolt", "it's git for data", []cli.Co
 	commands.MergeCmd{},
 	cnfcmds.Commands,
 	commands.RevertCmd{},
+	commands.CherryPickCmd{},
+	commands.StashCmd{},
+	commands.RebaseCmd{},
+	commands.ReflogCmd{},
 	commands.CloneCmd{},
 	commands.FetchCmd{},
 	commands.PullCmd{},
@@ -79,6 +83,7 @@ var doltCommand = cli.NewSubCommandHandler("dolt", "it's git for data", []cli.Co
 	commands.ConfigCmd{},
 	commands.RemoteCmd{},
 	commands.BackupCmd{},
+	commands.RemoteSrvCmd{},
 	commands.LoginCmd{},
 	credcmds.Commands,
 	commands.LsCmd{},
@@ -92,6 +97,7 @@ var doltCommand = cli.NewSubCommandHandler("dolt", "it's git for data", []cli.Co
 	indexcmds.Commands,
 	commands.ReadTablesCmd{},
 	commands.GarbageCollectionCmd{},
+	commands.FsckCmd{},
 	commands.FilterBranchCmd{},
 	commands.MergeBaseCmd{},
 	commands.RootsCmd{},
@@ -336,6 +342,7 @@ func runMain() int {
 	start := time.Now()
 	var wg sync.WaitGroup
 	ctx, stop := context.WithCancel(ctx)
+	ctx = doltdb.WithReflogInfo(ctx, reflogCommand(args), reflogUser(dEnv))
 	res := doltCommand.Exec(ctx, "dolt", args, dEnv)
 	stop()
 	wg.Wait()
@@ -367,6 +374,9 @@ func commandNeedsMigrationCheck(args []string) bool {
 		commands.ResetCmd{},
 		commands.CommitCmd{},
 		commands.RevertCmd{},
+		commands.CherryPickCmd{},
+		commands.StashCmd{},
+		commands.RebaseCmd{},
 		commands.SqlCmd{},
 		sqlserver.SqlServerCmd{},
 		sqlserver.SqlClientCmd{},
@@ -420,3 +430,27 @@ func processEventsDir(args []string, dEnv *env.DoltEnv) error {
 
 	return nil
 }
+
+// reflogCommand returns the command line of this process as it is recorded in the reflog.
+func reflogCommand(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if len(arg) == 0 || strings.ContainsAny(arg, " \t\n\"'") {
+			arg = strconv.Quote(arg)
+		}
+		quoted[i] = arg
+	}
+
+	return strings.Join(append([]string{"dolt"}, quoted...), " ")
+}
+
+// reflogUser returns the user that ref updates made by this process are attributed to in the reflog.
+func reflogUser(dEnv *env.DoltEnv) string {
+	name := dEnv.Config.GetStringOrDefault(env.UserNameKey, "")
+	email := dEnv.Config.GetStringOrDefault(env.UserEmailKey, "")
+	if len(email) == 0 {
+		return name
+	}
+
+	return name + " <" + email + ">"
+}
@@ -117,6 +117,9 @@ func (dc DoltCreds) Sign(data []byte) []byte {
 	return ed25519.Sign(dc.PrivKey, data)
 }
 
+// DefaultJWTAudience is the audience of the tokens dolt signs with its credentials to authenticate with remote servers.
+const DefaultJWTAudience = "dolthub-remote-api.liquidata.co"
+
 func (dc DoltCreds) toBearerToken() (string, error) {
 	b32KIDStr := dc.KeyIDBase32Str()
 	key := jose.SigningKey{Algorithm: jose.EdDSA, Key: ed25519.PrivateKey(dc.PrivKey)}
@@ -133,7 +136,7 @@ func (dc DoltCreds) toBearerToken() (string, error) {
 	// Shouldn't be hard coded
 	jwtBuilder := jwt.Signed(signer)
 	jwtBuilder = jwtBuilder.Claims(jwt.Claims{
-		Audience: []string{"dolthub-remote-api.liquidata.co"},
+		Audience: []string{DefaultJWTAudience},
 		Issuer:   "dolt-client.liquidata.co",
 		Subject:  "doltClientCredentials/" + b32KIDStr,
 		Expiry:   jwt.NewNumericDate(datetime.Now().Add(30 * time.Second)),
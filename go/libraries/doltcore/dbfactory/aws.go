@@ -136,8 +136,14 @@ func (fact AWSFactory) newChunkStore(ctx context.Context, nbf *types.NomsBinForm
 		return nil, err
 	}
 
+	keys, err := LoadEncryptionKeyring()
+
+	if err != nil {
+		return nil, err
+	}
+
 	sess := session.Must(session.NewSessionWithOptions(opts))
-	return nbs.NewAWSStore(ctx, nbf.VersionString(), parts[0], dbName, parts[1], s3.New(sess), dynamodb.New(sess), defaultMemTableSize)
+	return nbs.NewAWSStoreWithKeyring(ctx, nbf.VersionString(), parts[0], dbName, parts[1], s3.New(sess), dynamodb.New(sess), defaultMemTableSize, keys)
 }
 
 func validatePath(path string) (string, error) {
@@ -33,6 +33,21 @@ const (
 
 	// DataDir is the directory internal to the DoltDir which holds the noms files.
 	DataDir = "noms"
+
+	// ChunkJournalEnvKey is the environment variable which, when set, makes local databases append their commits to a
+	// chunk journal rather than writing a table file for each commit. Databases which already have a chunk journal use
+	// it whether or not the variable is set.
+	ChunkJournalEnvKey = "DOLT_ENABLE_CHUNK_JOURNAL"
+
+	// TableFileCompressionEnvKey is the environment variable which sets the compression of the table files written by
+	// local databases, one of "snappy", "zstd" and "zstd-dict". Table files are compressed with snappy by default.
+	TableFileCompressionEnvKey = "DOLT_TABLE_FILE_COMPRESSION"
+
+	// EncryptionKeyFileEnvKey is the environment variable which names the encryption key file of databases encrypted at
+	// rest. When it is set, the table files and manifests written to file, aws and gs databases are encrypted with the
+	// active key of the file, and encrypted table files and manifests are read with any of its keys. Rotating the keys
+	// of local databases is done by making a new key active, and garbage collecting them.
+	EncryptionKeyFileEnvKey = "DOLT_ENCRYPTION_KEY_FILE"
 )
 
 // DoltDataDir is the directory where noms files will be stored
@@ -58,7 +73,28 @@ func (fact FileFactory) CreateDB(ctx context.Context, nbf *types.NomsBinFormat,
 		return nil, err
 	}
 
-	newGenSt, err := nbs.NewLocalStore(ctx, nbf.VersionString(), path, defaultMemTableSize)
+	compression := nbs.SnappyCompression
+	if v, ok := os.LookupEnv(TableFileCompressionEnvKey); ok && v != "" {
+		compression, err = nbs.ParseTableFileCompression(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	keys, err := LoadEncryptionKeyring()
+	if err != nil {
+		return nil, err
+	}
+
+	var newGenSt *nbs.NomsBlockStore
+	if v, ok := os.LookupEnv(ChunkJournalEnvKey); ok && v != "" {
+		if keys != nil {
+			return nil, nbs.ErrChunkJournalEncryption
+		}
+		newGenSt, err = nbs.NewLocalJournalingStore(ctx, nbf.VersionString(), path, defaultMemTableSize)
+	} else {
+		newGenSt, err = nbs.NewLocalStoreWithKeyring(ctx, nbf.VersionString(), path, defaultMemTableSize, keys)
+	}
 
 	if err != nil {
 		return nil, err
@@ -77,18 +113,36 @@ func (fact FileFactory) CreateDB(ctx context.Context, nbf *types.NomsBinFormat,
 		}
 	}
 
-	oldGenSt, err := nbs.NewLocalStore(ctx, nbf.VersionString(), oldgenPath, defaultMemTableSize)
+	oldGenSt, err := nbs.NewLocalStoreWithKeyring(ctx, nbf.VersionString(), oldgenPath, defaultMemTableSize, keys)
 
 	if err != nil {
 		return nil, err
 	}
 
+	for _, gen := range []*nbs.NomsBlockStore{newGenSt, oldGenSt} {
+		err = gen.SetTableFileCompression(compression)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	st := nbs.NewGenerationalCS(oldGenSt, newGenSt)
 	// metrics?
 
 	return datas.NewDatabase(st), nil
 }
 
+// LoadEncryptionKeyring returns the keyring of the encryption key file named by EncryptionKeyFileEnvKey, or nil when
+// it is not set.
+func LoadEncryptionKeyring() (*nbs.Keyring, error) {
+	path, ok := os.LookupEnv(EncryptionKeyFileEnvKey)
+	if !ok || path == "" {
+		return nil, nil
+	}
+
+	return nbs.ReadKeyringFile(path)
+}
+
 func validateDir(path string) error {
 	info, err := os.Stat(path)
 
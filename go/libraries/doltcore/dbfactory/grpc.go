@@ -19,8 +19,12 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"os"
+	"strconv"
+	"strings"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
 	remotesapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/remotesapi/v1alpha1"
 	"github.com/dolthub/dolt/go/libraries/doltcore/grpcendpoint"
@@ -33,6 +37,18 @@ import (
 
 var GRPCDialProviderParam = "__DOLT__grpc_dial_provider"
 
+// RemoteTokenEnvKey is the environment variable which, when set, holds a bearer token sent to the remote server named
+// by RemoteTokenHostEnvKey in place of the token signed with the user's dolt credentials.
+const RemoteTokenEnvKey = "DOLT_REMOTE_TOKEN"
+
+// RemoteTokenHostEnvKey is the environment variable holding the host, and the port if the url of the remote has one,
+// of the remote server the token of RemoteTokenEnvKey is sent to. The token is not sent to any other server.
+const RemoteTokenHostEnvKey = "DOLT_REMOTE_TOKEN_HOST"
+
+// RemoteTokenInsecureEnvKey is the environment variable which, when set to true, allows the token of
+// RemoteTokenEnvKey to be sent to a remote server without TLS.
+const RemoteTokenInsecureEnvKey = "DOLT_REMOTE_TOKEN_INSECURE"
+
 // GRPCDialProvider is an interface for getting a *grpc.ClientConn.
 type GRPCDialProvider interface {
 	GetGRPCDialParams(grpcendpoint.Config) (string, []grpc.DialOption, error)
@@ -77,11 +93,19 @@ func (fact DoltRemoteFactory) CreateDB(ctx context.Context, nbf *types.NomsBinFo
 var NoCachingParameter = "__dolt__NO_CACHING"
 
 func (fact DoltRemoteFactory) newChunkStore(ctx context.Context, nbf *types.NomsBinFormat, urlObj *url.URL, params map[string]interface{}, dp GRPCDialProvider) (chunks.ChunkStore, error) {
-	endpoint, opts, err := dp.GetGRPCDialParams(grpcendpoint.Config{
+	config := grpcendpoint.Config{
 		Endpoint:     urlObj.Host,
 		Insecure:     fact.insecure,
 		WithEnvCreds: true,
-	})
+	}
+	tokenCreds, err := remoteTokenCreds(urlObj.Host, fact.insecure)
+	if err != nil {
+		return nil, err
+	} else if tokenCreds != nil {
+		config.Creds = tokenCreds
+	}
+
+	endpoint, opts, err := dp.GetGRPCDialParams(config)
 	if err != nil {
 		return nil, err
 	}
@@ -110,3 +134,48 @@ func (fact DoltRemoteFactory) newChunkStore(ctx context.Context, nbf *types.Noms
 
 	return cs, err
 }
+
+// remoteTokenCreds returns the credentials sending the token of RemoteTokenEnvKey to the remote server |host|, or nil
+// if no token is set, or it is set for another host. |insecure| is whether the connection to |host| does not use TLS,
+// which the token is only sent over when RemoteTokenInsecureEnvKey allows it.
+func remoteTokenCreds(host string, insecure bool) (credentials.PerRPCCredentials, error) {
+	token := os.Getenv(RemoteTokenEnvKey)
+	if token == "" {
+		return nil, nil
+	}
+
+	tokenHost := os.Getenv(RemoteTokenHostEnvKey)
+	if tokenHost == "" {
+		return nil, fmt.Errorf("%s is set, but %s is not. set it to the host the token is sent to", RemoteTokenEnvKey, RemoteTokenHostEnvKey)
+	}
+
+	if !strings.EqualFold(tokenHost, host) {
+		return nil, nil
+	}
+
+	allowInsecure, _ := strconv.ParseBool(os.Getenv(RemoteTokenInsecureEnvKey))
+	if insecure && !allowInsecure {
+		return nil, fmt.Errorf("%s is only sent over TLS, and the remote %s does not use it. set %s=true to send it anyway", RemoteTokenEnvKey, host, RemoteTokenInsecureEnvKey)
+	}
+
+	return bearerTokenCreds{token: token, insecure: allowInsecure}, nil
+}
+
+// bearerTokenCreds are grpc credentials sending a static bearer token with each request. They require TLS unless
+// |insecure| is set.
+type bearerTokenCreds struct {
+	token    string
+	insecure bool
+}
+
+var _ credentials.PerRPCCredentials = bearerTokenCreds{}
+
+func (t bearerTokenCreds) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{
+		"authorization": "Bearer " + t.token,
+	}, nil
+}
+
+func (t bearerTokenCreds) RequireTransportSecurity() bool {
+	return !t.insecure
+}
@@ -0,0 +1,58 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbfactory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteTokenCreds(t *testing.T) {
+	t.Setenv(RemoteTokenEnvKey, "")
+	c, err := remoteTokenCreds("remotes.example.com", false)
+	require.NoError(t, err)
+	assert.Nil(t, c)
+
+	// a token is only sent to the host it is set for
+	t.Setenv(RemoteTokenEnvKey, "s3cr3t")
+	_, err = remoteTokenCreds("remotes.example.com", false)
+	assert.Error(t, err)
+
+	t.Setenv(RemoteTokenHostEnvKey, "remotes.example.com")
+	c, err = remoteTokenCreds("other.example.com", false)
+	require.NoError(t, err)
+	assert.Nil(t, c)
+
+	c, err = remoteTokenCreds("Remotes.Example.com", false)
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	assert.True(t, c.RequireTransportSecurity())
+	md, err := c.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer s3cr3t", md["authorization"])
+
+	// and only over TLS, unless sending it without is allowed
+	_, err = remoteTokenCreds("remotes.example.com", true)
+	assert.Error(t, err)
+
+	t.Setenv(RemoteTokenInsecureEnvKey, "true")
+	c, err = remoteTokenCreds("remotes.example.com", true)
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	assert.False(t, c.RequireTransportSecurity())
+}
@@ -41,7 +41,13 @@ func (fact GSFactory) CreateDB(ctx context.Context, nbf *types.NomsBinFormat, ur
 	}
 
 	bs := blobstore.NewGCSBlobstore(gcs, urlObj.Host, urlObj.Path)
-	gcsStore, err := nbs.NewBSStore(ctx, nbf.VersionString(), bs, defaultMemTableSize)
+	keys, err := LoadEncryptionKeyring()
+
+	if err != nil {
+		return nil, err
+	}
+
+	gcsStore, err := nbs.NewBSStoreWithKeyring(ctx, nbf.VersionString(), bs, defaultMemTableSize, keys)
 
 	if err != nil {
 		return nil, err
@@ -66,7 +72,13 @@ func (fact LocalBSFactory) CreateDB(ctx context.Context, nbf *types.NomsBinForma
 	}
 
 	bs := blobstore.NewLocalBlobstore(absPath)
-	bsStore, err := nbs.NewBSStore(ctx, nbf.VersionString(), bs, defaultMemTableSize)
+	keys, err := LoadEncryptionKeyring()
+
+	if err != nil {
+		return nil, err
+	}
+
+	bsStore, err := nbs.NewBSStoreWithKeyring(ctx, nbf.VersionString(), bs, defaultMemTableSize, keys)
 
 	if err != nil {
 		return nil, err
@@ -0,0 +1,85 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbfactory
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/dolthub/dolt/go/store/blobstore"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/nbs"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+const (
+	// S3EndpointParam is a creation parameter that can be used to set the endpoint of an S3 compatible object store,
+	// such as MinIO. Objects are addressed with path style urls when it is set.
+	S3EndpointParam = "s3-endpoint"
+
+	defaultS3Region = "us-east-1"
+)
+
+// S3Factory is a DBFactory implementation for creating databases backed by an S3 compatible object store. Unlike
+// AWSFactory, the manifest is stored in the bucket alongside the table files, so no DynamoDB table is needed.
+type S3Factory struct {
+}
+
+// CreateDB creates an S3 backed database
+func (fact S3Factory) CreateDB(ctx context.Context, nbf *types.NomsBinFormat, urlObj *url.URL, params map[string]interface{}) (datas.Database, error) {
+	var db datas.Database
+	if urlObj.Host == "" {
+		return nil, errors.New("s3 url has an invalid format, expected s3://bucket/path")
+	}
+
+	opts, err := awsConfigFromParams(params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if val, ok := params[S3EndpointParam]; ok {
+		opts.Config.MergeIn(aws.NewConfig().WithEndpoint(val.(string)).WithS3ForcePathStyle(true))
+
+		// S3 compatible object stores are commonly run without a region, which the sdk requires
+		if opts.Config.Region == nil && os.Getenv("AWS_REGION") == "" {
+			opts.Config.MergeIn(aws.NewConfig().WithRegion(defaultS3Region))
+		}
+	}
+
+	keys, err := LoadEncryptionKeyring()
+
+	if err != nil {
+		return nil, err
+	}
+
+	sess := session.Must(session.NewSessionWithOptions(opts))
+	bs := blobstore.NewS3Blobstore(s3.New(sess), urlObj.Host, urlObj.Path)
+	s3Store, err := nbs.NewBSStoreWithKeyring(ctx, nbf.VersionString(), bs, defaultMemTableSize, keys)
+
+	if err != nil {
+		return nil, err
+	}
+
+	db = datas.NewDatabase(s3Store)
+
+	return db, err
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
 	"github.com/dolthub/dolt/go/libraries/doltcore/row"
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/utils/set"
 	"github.com/dolthub/dolt/go/store/diff"
 	"github.com/dolthub/dolt/go/store/types"
 )
@@ -133,6 +134,94 @@ func summaryWithReporter(ctx context.Context, ch chan DiffSummaryProgress, from,
 	return nil
 }
 
+// ColumnsChanged returns the tags of the columns of |sch| whose values differ in any row between |from| and |to|,
+// computed from the same map diff as Summary. Rather than counting the fields of each modified row which changed, the
+// tags of those fields are collected, along with the tags of the non-NULL fields of every row added or removed. The
+// diff stops as soon as every column of |sch| is known to have changed.
+func ColumnsChanged(ctx context.Context, sch schema.Schema, from, to types.Map) (changed *set.Uint64Set, err error) {
+	changed = set.NewUint64Set(nil)
+	cols := sch.GetAllCols()
+	if cols.Size() == 0 {
+		return changed, nil
+	}
+
+	ad := NewAsyncDiffer(1024)
+	ad.Start(ctx, from, to)
+	defer func() {
+		if cerr := ad.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	keyless := schema.IsKeyless(sch)
+	addTags := func(tvs ...row.TaggedValues) {
+		for _, tv := range tvs {
+			for tag := range tv {
+				if _, ok := cols.GetByTag(tag); ok {
+					changed.Add(tag)
+				}
+			}
+		}
+	}
+
+	var more bool
+	var diffs []*diff.Difference
+	for {
+		diffs, more, err = ad.GetDiffsWithoutTimeout(1024)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, df := range diffs {
+			var oldVals, newVals row.TaggedValues
+			if df.OldValue != nil {
+				oldVals, err = row.ParseTaggedValues(df.OldValue.(types.Tuple))
+				if err != nil {
+					return nil, err
+				}
+			}
+			if df.NewValue != nil {
+				newVals, err = row.ParseTaggedValues(df.NewValue.(types.Tuple))
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			switch {
+			case df.ChangeType == types.DiffChangeModified && !keyless:
+				for tag, v := range oldVals {
+					if nv, ok := newVals[tag]; !ok || !v.Equals(nv) {
+						addTags(row.TaggedValues{tag: v})
+					}
+				}
+				for tag, v := range newVals {
+					if _, ok := oldVals[tag]; !ok {
+						addTags(row.TaggedValues{tag: v})
+					}
+				}
+			default:
+				// rows of keyless tables are only modified by a change to their cardinality, which adds or removes
+				// copies of the whole row
+				keyVals, err := row.ParseTaggedValues(df.KeyValue.(types.Tuple))
+				if err != nil {
+					return nil, err
+				}
+				addTags(keyVals, oldVals, newVals)
+			}
+
+			if changed.Size() == cols.Size() {
+				return changed, nil
+			}
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	return changed, nil
+}
+
 func reportPkChanges(ctx context.Context, change *diff.Difference, ch chan<- DiffSummaryProgress) error {
 	var summary DiffSummaryProgress
 	switch change.ChangeType {
@@ -0,0 +1,122 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/row"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+func TestColumnsChanged(t *testing.T) {
+	const (
+		pkTag uint64 = iota
+		c1Tag
+		c2Tag
+		c3Tag
+	)
+
+	sch := schema.MustSchemaFromCols(schema.NewColCollection(
+		schema.NewColumn("pk", pkTag, types.IntKind, true),
+		schema.NewColumn("c1", c1Tag, types.IntKind, false),
+		schema.NewColumn("c2", c2Tag, types.IntKind, false),
+		schema.NewColumn("c3", c3Tag, types.IntKind, false),
+	))
+
+	ctx := context.Background()
+	vrw := types.NewMemoryValueStore()
+	mapOf := func(rows ...row.TaggedValues) types.Map {
+		m, err := types.NewMap(ctx, vrw)
+		require.NoError(t, err)
+		me := m.Edit()
+		for _, tv := range rows {
+			r, err := row.New(vrw.Format(), sch, tv)
+			require.NoError(t, err)
+			me = me.Set(r.NomsMapKey(sch), r.NomsMapValue(sch))
+		}
+		m, err = me.Map(ctx)
+		require.NoError(t, err)
+		return m
+	}
+
+	base := mapOf(
+		row.TaggedValues{pkTag: types.Int(1), c1Tag: types.Int(1), c2Tag: types.Int(1)},
+		row.TaggedValues{pkTag: types.Int(2), c1Tag: types.Int(2), c2Tag: types.Int(2)},
+	)
+
+	tests := []struct {
+		name     string
+		to       types.Map
+		expected []uint64
+	}{
+		{
+			name:     "no changes",
+			to:       base,
+			expected: nil,
+		},
+		{
+			name: "modified field",
+			to: mapOf(
+				row.TaggedValues{pkTag: types.Int(1), c1Tag: types.Int(1), c2Tag: types.Int(10)},
+				row.TaggedValues{pkTag: types.Int(2), c1Tag: types.Int(2), c2Tag: types.Int(2)},
+			),
+			expected: []uint64{c2Tag},
+		},
+		{
+			name: "field set to and from NULL",
+			to: mapOf(
+				row.TaggedValues{pkTag: types.Int(1), c2Tag: types.Int(1), c3Tag: types.Int(1)},
+				row.TaggedValues{pkTag: types.Int(2), c1Tag: types.Int(2), c2Tag: types.Int(2)},
+			),
+			expected: []uint64{c1Tag, c3Tag},
+		},
+		{
+			name: "row added",
+			to: mapOf(
+				row.TaggedValues{pkTag: types.Int(1), c1Tag: types.Int(1), c2Tag: types.Int(1)},
+				row.TaggedValues{pkTag: types.Int(2), c1Tag: types.Int(2), c2Tag: types.Int(2)},
+				row.TaggedValues{pkTag: types.Int(3), c1Tag: types.Int(3)},
+			),
+			expected: []uint64{pkTag, c1Tag},
+		},
+		{
+			name:     "row removed",
+			to:       mapOf(row.TaggedValues{pkTag: types.Int(1), c1Tag: types.Int(1), c2Tag: types.Int(1)}),
+			expected: []uint64{pkTag, c1Tag, c2Tag},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			changed, err := ColumnsChanged(ctx, sch, base, test.to)
+			require.NoError(t, err)
+
+			actual := changed.AsSlice()
+			sort.Slice(actual, func(i, j int) bool { return actual[i] < actual[j] })
+			if test.expected == nil {
+				assert.Empty(t, actual)
+			} else {
+				assert.Equal(t, test.expected, actual)
+			}
+		})
+	}
+}
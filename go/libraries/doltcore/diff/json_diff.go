@@ -0,0 +1,362 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/row"
+	"github.com/dolthub/dolt/go/libraries/doltcore/rowconv"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema/typeinfo"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/pipeline"
+	"github.com/dolthub/dolt/go/libraries/utils/iohelp"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+const (
+	jsonDiffHeader = `{"tables":[`
+	jsonDiffFooter = `]}`
+
+	jsonDiffTypeAdded    = "added"
+	jsonDiffTypeRemoved  = "removed"
+	jsonDiffTypeModified = "modified"
+)
+
+var JSONDiffWriteBufSize = 256 * 1024
+
+// JSONDiffWriter writes the differences between the tables of two roots as a single JSON document of the form
+//
+//	{"tables":[{"name":...,"from_name":...,"to_name":...,"diff_type":...,<fields>...,"data_diff":[<rows>...]},...]}
+//
+// Each table is written as it is diffed and each row diff is written as it is read, so the size of the diff being
+// written is not bounded by memory.
+type JSONDiffWriter struct {
+	closer        io.Closer
+	bWr           *bufio.Writer
+	tablesWritten int
+	inTable       bool
+}
+
+// NewJSONDiffWriter creates a JSONDiffWriter which writes to |wr|, and writes the start of the document.
+func NewJSONDiffWriter(wr io.WriteCloser) (*JSONDiffWriter, error) {
+	bWr := bufio.NewWriterSize(wr, JSONDiffWriteBufSize)
+	err := iohelp.WriteAll(bWr, []byte(jsonDiffHeader))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &JSONDiffWriter{closer: wr, bWr: bWr}, nil
+}
+
+// BeginTable starts the object for the table in |td|, writing its names and whether it was added, removed or modified.
+func (jdw *JSONDiffWriter) BeginTable(td TableDelta) error {
+	if jdw.inTable {
+		return errors.New("cannot begin a table before the previous table has ended")
+	}
+
+	if jdw.tablesWritten != 0 {
+		if err := jdw.bWr.WriteByte(','); err != nil {
+			return err
+		}
+	}
+
+	diffType := jsonDiffTypeModified
+	if td.IsAdd() {
+		diffType = jsonDiffTypeAdded
+	} else if td.IsDrop() {
+		diffType = jsonDiffTypeRemoved
+	}
+
+	if err := jdw.writeField(`{"name":`, td.CurName()); err != nil {
+		return err
+	}
+
+	if err := jdw.WriteTableField("from_name", stringOrNil(td.FromName)); err != nil {
+		return err
+	}
+
+	if err := jdw.WriteTableField("to_name", stringOrNil(td.ToName)); err != nil {
+		return err
+	}
+
+	if err := jdw.WriteTableField("diff_type", diffType); err != nil {
+		return err
+	}
+
+	jdw.inTable = true
+	jdw.tablesWritten++
+
+	return nil
+}
+
+// WriteTableField writes a field with the name |key| to the object of the current table. |val| is marshalled using
+// encoding/json.
+func (jdw *JSONDiffWriter) WriteTableField(key string, val interface{}) error {
+	keyData, err := json.Marshal(key)
+
+	if err != nil {
+		return err
+	}
+
+	return jdw.writeField(","+string(keyData)+":", val)
+}
+
+func (jdw *JSONDiffWriter) writeField(prefix string, val interface{}) error {
+	data, err := json.Marshal(val)
+
+	if err != nil {
+		return err
+	}
+
+	if err = iohelp.WriteAll(jdw.bWr, []byte(prefix)); err != nil {
+		return err
+	}
+
+	return iohelp.WriteAll(jdw.bWr, data)
+}
+
+// BeginRowDiffs starts the "data_diff" array of the current table, and returns a JSONDiffSink which writes the row
+// diffs of the table to it. The sink must be closed before the table is ended.
+func (jdw *JSONDiffWriter) BeginRowDiffs(joiner *rowconv.Joiner) (*JSONDiffSink, error) {
+	err := iohelp.WriteAll(jdw.bWr, []byte(`,"data_diff":[`))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &JSONDiffSink{bWr: jdw.bWr, joiner: joiner}, nil
+}
+
+// EndTable ends the object of the current table.
+func (jdw *JSONDiffWriter) EndTable() error {
+	if !jdw.inTable {
+		return errors.New("no table has been started")
+	}
+
+	jdw.inTable = false
+
+	return jdw.bWr.WriteByte('}')
+}
+
+// Close ends the document, flushes all writes and closes the underlying writer.
+func (jdw *JSONDiffWriter) Close() error {
+	if jdw.closer == nil {
+		return errors.New("already closed")
+	}
+
+	if jdw.inTable {
+		if err := jdw.EndTable(); err != nil {
+			return err
+		}
+	}
+
+	err := iohelp.WriteAll(jdw.bWr, []byte(jsonDiffFooter))
+
+	if err != nil {
+		return err
+	}
+
+	errFl := jdw.bWr.Flush()
+	errCl := jdw.closer.Close()
+	jdw.closer = nil
+
+	if errCl != nil {
+		return errCl
+	}
+
+	return errFl
+}
+
+// JSONDiffSink is a diff pipeline sink which writes joined diff rows, before they are split into their old and new
+// values, as JSON objects of the form
+//
+//	{"diff_type":"added|removed|modified","before":{<col>:<val>,...},"after":{<col>:<val>,...}}
+//
+// where "before" is keyed by the column names of the old schema and "after" by those of the new schema. "before" is
+// null for added rows and "after" is null for removed rows.
+type JSONDiffSink struct {
+	bWr         *bufio.Writer
+	joiner      *rowconv.Joiner
+	rowsWritten int
+}
+
+// GetSchema gets the schema of the joined rows the JSONDiffSink consumes.
+func (jds *JSONDiffSink) GetSchema() schema.Schema {
+	return jds.joiner.GetSchema()
+}
+
+// ProcRowWithProps satisfies pipeline.SinkFunc; it writes a joined diff row as a JSON object.
+func (jds *JSONDiffSink) ProcRowWithProps(r row.Row, _ pipeline.ReadableMap) error {
+	rows, err := jds.joiner.Split(r)
+
+	if err != nil {
+		return err
+	}
+
+	oldRow, newRow := rows[From], rows[To]
+
+	var diffType string
+	switch {
+	case oldRow != nil && newRow != nil:
+		diffType = jsonDiffTypeModified
+	case oldRow != nil:
+		diffType = jsonDiffTypeRemoved
+	case newRow != nil:
+		diffType = jsonDiffTypeAdded
+	default:
+		return nil
+	}
+
+	if jds.rowsWritten != 0 {
+		if err = jds.bWr.WriteByte(','); err != nil {
+			return err
+		}
+	}
+
+	diffTypeData, err := json.Marshal(diffType)
+
+	if err != nil {
+		return err
+	}
+
+	err = iohelp.WriteAll(jds.bWr, []byte(`{"diff_type":`), diffTypeData, []byte(`,"before":`))
+
+	if err != nil {
+		return err
+	}
+
+	if err = writeJSONRow(jds.bWr, jds.joiner.SchemaForName(From), oldRow); err != nil {
+		return err
+	}
+
+	if err = iohelp.WriteAll(jds.bWr, []byte(`,"after":`)); err != nil {
+		return err
+	}
+
+	if err = writeJSONRow(jds.bWr, jds.joiner.SchemaForName(To), newRow); err != nil {
+		return err
+	}
+
+	if err = jds.bWr.WriteByte('}'); err != nil {
+		return err
+	}
+
+	jds.rowsWritten++
+
+	return nil
+}
+
+// Close ends the "data_diff" array. It does not close the JSONDiffWriter the sink was created by.
+func (jds *JSONDiffSink) Close() error {
+	if jds.bWr == nil {
+		return errors.New("already closed")
+	}
+
+	err := jds.bWr.WriteByte(']')
+	jds.bWr = nil
+
+	return err
+}
+
+// writeJSONRow writes |r| as a JSON object whose keys are the column names of |sch|, in schema order. A nil row is
+// written as null.
+func writeJSONRow(wr io.Writer, sch schema.Schema, r row.Row) error {
+	if r == nil {
+		return iohelp.WriteAll(wr, []byte("null"))
+	}
+
+	if err := iohelp.WriteAll(wr, []byte("{")); err != nil {
+		return err
+	}
+
+	i := 0
+	err := sch.GetAllCols().Iter(func(tag uint64, col schema.Column) (stop bool, err error) {
+		val, err := jsonValue(col, r)
+
+		if err != nil {
+			return true, err
+		}
+
+		keyData, err := json.Marshal(col.Name)
+
+		if err != nil {
+			return true, err
+		}
+
+		valData, err := json.Marshal(val)
+
+		if err != nil {
+			return true, err
+		}
+
+		sep := []byte(",")
+		if i == 0 {
+			sep = nil
+		}
+		i++
+
+		return false, iohelp.WriteAll(wr, sep, keyData, []byte(":"), valData)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return iohelp.WriteAll(wr, []byte("}"))
+}
+
+// jsonValue returns the value of |col| in |r| as a value which encoding/json marshals as a JSON number, boolean or
+// string, or nil if it is NULL.
+func jsonValue(col schema.Column, r row.Row) (interface{}, error) {
+	val, ok := r.GetColVal(col.Tag)
+	if !ok || types.IsNull(val) {
+		return nil, nil
+	}
+
+	switch col.TypeInfo.GetTypeIdentifier() {
+	case typeinfo.BitTypeIdentifier,
+		typeinfo.BoolTypeIdentifier,
+		typeinfo.VarStringTypeIdentifier,
+		typeinfo.UintTypeIdentifier,
+		typeinfo.IntTypeIdentifier,
+		typeinfo.FloatTypeIdentifier:
+		// use primitive type
+		return val, nil
+	}
+
+	str, err := col.TypeInfo.FormatValue(val)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if str == nil {
+		return nil, nil
+	}
+
+	return *str, nil
+}
+
+func stringOrNil(s string) interface{} {
+	if len(s) == 0 {
+		return nil
+	}
+	return s
+}
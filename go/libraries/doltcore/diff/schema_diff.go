@@ -16,6 +16,7 @@ package diff
 
 import (
 	"reflect"
+	"strings"
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
@@ -204,3 +205,58 @@ func DiffForeignKeys(fromFks, toFKs []doltdb.ForeignKey) (diffs []ForeignKeyDiff
 	}
 	return diffs
 }
+
+type CheckDifference struct {
+	DiffType SchemaChangeType
+	From     schema.Check
+	To       schema.Check
+}
+
+// DiffChecks matches the check constraints of two schemas by name.
+// It returns matched and unmatched checks as a slice of CheckDifferences.
+func DiffChecks(fromSch, toSch schema.Schema) (diffs []CheckDifference) {
+	fromChecks := make(map[string]schema.Check)
+	for _, from := range fromSch.Checks().AllChecks() {
+		fromChecks[strings.ToLower(from.Name())] = from
+	}
+
+	toChecks := make(map[string]schema.Check)
+	for _, to := range toSch.Checks().AllChecks() {
+		toChecks[strings.ToLower(to.Name())] = to
+	}
+
+	for _, from := range fromSch.Checks().AllChecks() {
+		to, ok := toChecks[strings.ToLower(from.Name())]
+		if !ok {
+			diffs = append(diffs, CheckDifference{
+				DiffType: SchDiffRemoved,
+				From:     from,
+			})
+			continue
+		}
+
+		d := CheckDifference{
+			DiffType: SchDiffModified,
+			From:     from,
+			To:       to,
+		}
+
+		if from.Name() == to.Name() && from.Expression() == to.Expression() && from.Enforced() == to.Enforced() {
+			d.DiffType = SchDiffNone
+		}
+		diffs = append(diffs, d)
+	}
+
+	for _, to := range toSch.Checks().AllChecks() {
+		if _, ok := fromChecks[strings.ToLower(to.Name())]; ok {
+			continue
+		}
+
+		diffs = append(diffs, CheckDifference{
+			DiffType: SchDiffAdded,
+			To:       to,
+		})
+	}
+
+	return diffs
+}
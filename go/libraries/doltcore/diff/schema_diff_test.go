@@ -66,3 +66,35 @@ func TestDiffSchemas(t *testing.T) {
 		t.Error(diffs, "!=", expected)
 	}
 }
+
+func TestDiffChecks(t *testing.T) {
+	cols := schema.NewColCollection(
+		schema.NewColumn("pk", 0, types.IntKind, true, schema.NotNullConstraint{}),
+		schema.NewColumn("c1", 1, types.IntKind, false))
+
+	oldSch, err := schema.SchemaFromCols(cols)
+	require.NoError(t, err)
+	unchanged, err := oldSch.Checks().AddCheck("unchanged", "(c1 > 0)", true)
+	require.NoError(t, err)
+	dropped, err := oldSch.Checks().AddCheck("dropped", "(c1 < 100)", true)
+	require.NoError(t, err)
+	oldModified, err := oldSch.Checks().AddCheck("modified", "(c1 <> 5)", true)
+	require.NoError(t, err)
+
+	newSch, err := schema.SchemaFromCols(cols)
+	require.NoError(t, err)
+	_, err = newSch.Checks().AddCheck("unchanged", "(c1 > 0)", true)
+	require.NoError(t, err)
+	newModified, err := newSch.Checks().AddCheck("modified", "(c1 <> 5)", false)
+	require.NoError(t, err)
+	added, err := newSch.Checks().AddCheck("added", "(pk > 0)", true)
+	require.NoError(t, err)
+
+	diffs := DiffChecks(oldSch, newSch)
+	require.Equal(t, []CheckDifference{
+		{SchDiffNone, unchanged, unchanged},
+		{SchDiffRemoved, dropped, nil},
+		{SchDiffModified, oldModified, newModified},
+		{SchDiffAdded, nil, added},
+	}, diffs)
+}
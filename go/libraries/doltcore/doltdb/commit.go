@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
 	"github.com/dolthub/dolt/go/store/datas"
@@ -50,7 +51,19 @@ func NewCommit(vrw types.ValueReadWriter, commitSt types.Struct) *Commit {
 	return &Commit{vrw, commitSt, parents}
 }
 
+// readParents returns the parents of |commitSt|. The shallow commits of |vrw| have no parents: their parents are
+// grafted away.
 func readParents(vrw types.ValueReadWriter, commitSt types.Struct) ([]types.Ref, error) {
+	if shallow := datas.ShallowCommits(vrw); len(shallow) > 0 {
+		h, err := commitSt.Hash(vrw.Format())
+		if err != nil {
+			return nil, err
+		}
+		if shallow.Has(h) {
+			return nil, nil
+		}
+	}
+
 	if l, found, err := commitSt.MaybeGet(parentsListField); err != nil {
 		return nil, err
 	} else if found && l != nil {
@@ -176,6 +189,10 @@ func maxChunkHeight(v types.Value, nbf *types.NomsBinFormat) (max uint64, err er
 }
 
 func (c *Commit) getParent(ctx context.Context, idx int) (*types.Struct, error) {
+	if idx < 0 || idx >= len(c.parents) {
+		return nil, fmt.Errorf("commit has no parent %d", idx)
+	}
+
 	parentRef := c.parents[idx]
 	targVal, err := parentRef.TargetValue(ctx, c.vrw)
 	if err != nil {
@@ -246,6 +263,9 @@ func getCommitAncestorRef(ctx context.Context, ref1, ref2 types.Ref, vrw1, vrw2
 	}
 
 	if !ok {
+		if len(datas.ShallowCommits(vrw1)) > 0 || len(datas.ShallowCommits(vrw2)) > 0 {
+			return types.Ref{}, fmt.Errorf("%w: %s", ErrNoCommonAncestor, ErrShallowHistory.Error())
+		}
 		return types.Ref{}, ErrNoCommonAncestor
 	}
 
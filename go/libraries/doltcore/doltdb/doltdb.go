@@ -59,14 +59,18 @@ var ErrCannotDeleteLastBranch = errors.New("cannot delete the last branch")
 // Additionally the noms codebase uses panics in a way that is non idiomatic and We've opted to recover and return
 // errors in many cases.
 type DoltDB struct {
-	db datas.Database
+	db          datas.Database
+	reflog      *Reflog
+	shallow     *shallowFile
+	partialFile *partialFile
+	partial     *PartialClone
 }
 
 // DoltDBFromCS creates a DoltDB from a noms chunks.ChunkStore
 func DoltDBFromCS(cs chunks.ChunkStore) *DoltDB {
 	db := datas.NewDatabase(cs)
 
-	return &DoltDB{db}
+	return &DoltDB{db: db}
 }
 
 // LoadDoltDB will acquire a reference to the underlying noms db.  If the Location is InMemDoltDB then a reference
@@ -77,6 +81,9 @@ func LoadDoltDB(ctx context.Context, nbf *types.NomsBinFormat, urlStr string, fs
 }
 
 func LoadDoltDBWithParams(ctx context.Context, nbf *types.NomsBinFormat, urlStr string, fs filesys.Filesys, params map[string]interface{}) (*DoltDB, error) {
+	var reflog *Reflog
+	var shallow *shallowFile
+	var partialF *partialFile
 	if urlStr == LocalDirDoltDB {
 		exists, isDir := fs.Exists(dbfactory.DoltDataDir)
 
@@ -92,6 +99,21 @@ func LoadDoltDBWithParams(ctx context.Context, nbf *types.NomsBinFormat, urlStr
 		}
 
 		urlStr = fmt.Sprintf("file://%s", filepath.ToSlash(absPath))
+
+		reflog, err = newLocalReflog(fs)
+		if err != nil {
+			return nil, err
+		}
+
+		shallow, err = newLocalShallowFile(fs)
+		if err != nil {
+			return nil, err
+		}
+
+		partialF, err = newLocalPartialFile(fs)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	db, err := dbfactory.CreateDB(ctx, nbf, urlStr, params)
@@ -100,7 +122,30 @@ func LoadDoltDBWithParams(ctx context.Context, nbf *types.NomsBinFormat, urlStr
 		return nil, err
 	}
 
-	return &DoltDB{db}, nil
+	if shallow != nil {
+		commits, err := shallow.read()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(commits) > 0 {
+			err = datas.SetShallowCommits(ctx, db, commits)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var partial *PartialClone
+	if partialF != nil {
+		// the database only fetches the row data it does not hold once it is given the remote to fetch it from
+		partial, err = partialF.read()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &DoltDB{db: db, reflog: reflog, shallow: shallow, partialFile: partialF, partial: partial}, nil
 }
 
 // NomsRoot returns the hash of the noms dataset map
@@ -207,9 +252,13 @@ func (ddb *DoltDB) WriteEmptyRepoWithCommitTimeAndDefaultBranch(
 		return errors.New("commit without head")
 	}
 
-	_, err = ddb.db.SetHead(ctx, ds, headRef)
+	newDs, err := ddb.db.SetHead(ctx, ds, headRef)
+	if err != nil {
+		return err
+	}
 
-	return err
+	ddb.logRefUpdate(ctx, ds, newDs)
+	return nil
 }
 
 func getCommitStForRefStr(ctx context.Context, db datas.Database, ref string) (types.Struct, error) {
@@ -526,9 +575,13 @@ func (ddb *DoltDB) FastForward(ctx context.Context, branch ref.DoltRef, commit *
 		return err
 	}
 
-	_, err = ddb.db.FastForward(ctx, ds, rf)
+	newDs, err := ddb.db.FastForward(ctx, ds, rf)
+	if err != nil {
+		return err
+	}
 
-	return err
+	ddb.logRefUpdate(ctx, ds, newDs)
+	return nil
 }
 
 // CanFastForward returns whether the given branch can be fast-forwarded to the commit given.
@@ -565,8 +618,13 @@ func (ddb *DoltDB) SetHead(ctx context.Context, ref ref.DoltRef, stRef types.Ref
 		return err
 	}
 
-	_, err = ddb.db.SetHead(ctx, ds, stRef)
-	return err
+	newDs, err := ddb.db.SetHead(ctx, ds, stRef)
+	if err != nil {
+		return err
+	}
+
+	ddb.logRefUpdate(ctx, ds, newDs)
+	return nil
 }
 
 // CommitWithParentSpecs commits the value hash given to the branch given, using the list of parent hashes given. Returns an
@@ -648,13 +706,15 @@ func (ddb *DoltDB) CommitWithParentCommits(ctx context.Context, valHash hash.Has
 		return nil, err
 	}
 
-	ds, err = ddb.db.Commit(ctx, ds, val, commitOpts)
+	newDs, err := ddb.db.Commit(ctx, ds, val, commitOpts)
 
 	if err != nil {
 		return nil, err
 	}
 
-	commitSt, ok := ds.MaybeHead()
+	ddb.logRefUpdate(ctx, ds, newDs)
+
+	commitSt, ok := newDs.MaybeHead()
 	if !ok {
 		return nil, errors.New("Commit has no head but commit succeeded. This is a bug.")
 	}
@@ -882,11 +942,13 @@ func (ddb *DoltDB) NewBranchAtCommit(ctx context.Context, branchRef ref.DoltRef,
 		return err
 	}
 
-	_, err = ddb.db.SetHead(ctx, ds, rf)
+	newDs, err := ddb.db.SetHead(ctx, ds, rf)
 	if err != nil {
 		return err
 	}
 
+	ddb.logRefUpdate(ctx, ds, newDs)
+
 	// Update the corresponding working set at the same time, either by updating it or creating a new one
 	// TODO: find all the places HEAD can change, update working set too. This is only necessary when we don't already
 	//  update the working set when the head changes.
@@ -972,8 +1034,13 @@ func (ddb *DoltDB) deleteRef(ctx context.Context, dref ref.DoltRef) error {
 		}
 	}
 
-	_, err = ddb.db.Delete(ctx, ds)
-	return err
+	newDs, err := ddb.db.Delete(ctx, ds)
+	if err != nil {
+		return err
+	}
+
+	ddb.logRefUpdate(ctx, ds, newDs)
+	return nil
 }
 
 // NewTagAtCommit create a new tag at the commit given.
@@ -1011,9 +1078,13 @@ func (ddb *DoltDB) NewTagAtCommit(ctx context.Context, tagRef ref.DoltRef, c *Co
 
 	tag := datas.TagOptions{Meta: st}
 
-	ds, err = ddb.db.Tag(ctx, ds, r, tag)
+	newDs, err := ddb.db.Tag(ctx, ds, r, tag)
+	if err != nil {
+		return err
+	}
 
-	return err
+	ddb.logRefUpdate(ctx, ds, newDs)
+	return nil
 }
 
 // UpdateWorkingSet updates the working set with the ref given to the root value given
@@ -1042,14 +1113,18 @@ func (ddb *DoltDB) UpdateWorkingSet(
 		return err
 	}
 
-	_, err = ddb.db.UpdateWorkingSet(ctx, ds, datas.WorkingSetSpec{
+	newDs, err := ddb.db.UpdateWorkingSet(ctx, ds, datas.WorkingSetSpec{
 		Meta:        datas.WorkingSetMeta{Meta: metaSt},
 		WorkingRoot: workingRootRef,
 		StagedRoot:  stagedRef,
 		MergeState:  mergeStateRef,
 	}, prevHash)
+	if err != nil {
+		return err
+	}
 
-	return err
+	ddb.logRefUpdate(ctx, ds, newDs)
+	return nil
 }
 
 // CommitWithWorkingSet combines the functionality of CommitWithParents with UpdateWorking set, and takes a combination
@@ -1084,7 +1159,7 @@ func (ddb *DoltDB) CommitWithWorkingSet(
 		return nil, err
 	}
 
-	commitDataset, _, err := ddb.db.CommitWithWorkingSet(ctx, headDs, wsDs, commit.Roots.Staged.valueSt, datas.WorkingSetSpec{
+	commitDataset, newWsDs, err := ddb.db.CommitWithWorkingSet(ctx, headDs, wsDs, commit.Roots.Staged.valueSt, datas.WorkingSetSpec{
 		Meta:        datas.WorkingSetMeta{Meta: metaSt},
 		WorkingRoot: workingRootRef,
 		StagedRoot:  stagedRef,
@@ -1095,6 +1170,9 @@ func (ddb *DoltDB) CommitWithWorkingSet(
 		return nil, err
 	}
 
+	ddb.logRefUpdate(ctx, headDs, commitDataset)
+	ddb.logRefUpdate(ctx, wsDs, newWsDs)
+
 	commitSt, ok := commitDataset.MaybeHead()
 	if !ok {
 		return nil, errors.New("Commit has no head but commit succeeded. This is a bug.")
@@ -1110,8 +1188,13 @@ func (ddb *DoltDB) DeleteWorkingSet(ctx context.Context, workingSetRef ref.Worki
 		return err
 	}
 
-	_, err = ddb.db.Delete(ctx, ds)
-	return err
+	newDs, err := ddb.db.Delete(ctx, ds)
+	if err != nil {
+		return err
+	}
+
+	ddb.logRefUpdate(ctx, ds, newDs)
+	return nil
 }
 
 func (ddb *DoltDB) DeleteTag(ctx context.Context, tag ref.DoltRef) error {
@@ -1157,16 +1240,54 @@ func (ddb *DoltDB) Rebase(ctx context.Context) error {
 	return ddb.db.Rebase(ctx)
 }
 
+// GCStats describes a garbage collection of a DoltDB.
+type GCStats struct {
+	types.GCStats
+	// BytesBefore is the size of the table files of the database before the collection.
+	BytesBefore uint64
+	// BytesAfter is the size of the table files of the database after the collection.
+	BytesAfter uint64
+	// Duration is how long the collection took.
+	Duration time.Duration
+}
+
+// BytesReclaimed returns the number of bytes of table files removed by the collection.
+func (s GCStats) BytesReclaimed() uint64 {
+	if s.BytesAfter > s.BytesBefore {
+		return 0
+	}
+	return s.BytesBefore - s.BytesAfter
+}
+
 // GC performs garbage collection on this ddb. Values passed in |uncommitedVals| will be temporarily saved during gc.
-func (ddb *DoltDB) GC(ctx context.Context, uncommitedVals ...hash.Hash) error {
+// Nothing may write to the database while GC runs.
+func (ddb *DoltDB) GC(ctx context.Context, uncommitedVals ...hash.Hash) (GCStats, error) {
+	return ddb.gc(ctx, false, uncommitedVals)
+}
+
+// OnlineGC is like GC, but may run while the database is being written to, such as by the sessions of a sql-server.
+func (ddb *DoltDB) OnlineGC(ctx context.Context, uncommitedVals ...hash.Hash) (GCStats, error) {
+	return ddb.gc(ctx, true, uncommitedVals)
+}
+
+func (ddb *DoltDB) gc(ctx context.Context, online bool, uncommitedVals []hash.Hash) (GCStats, error) {
+	start := time.Now()
+
 	collector, ok := ddb.db.(datas.GarbageCollector)
 	if !ok {
-		return fmt.Errorf("this database does not support garbage collection")
+		return GCStats{}, fmt.Errorf("this database does not support garbage collection")
 	}
 
-	err := ddb.pruneUnreferencedDatasets(ctx)
+	var stats GCStats
+	var err error
+	stats.BytesBefore, err = ddb.storeSize(ctx)
 	if err != nil {
-		return err
+		return GCStats{}, err
+	}
+
+	err = ddb.pruneUnreferencedDatasets(ctx)
+	if err != nil {
+		return GCStats{}, err
 	}
 
 	datasets, err := ddb.db.Datasets(ctx)
@@ -1198,10 +1319,84 @@ func (ddb *DoltDB) GC(ctx context.Context, uncommitedVals ...hash.Hash) error {
 	})
 
 	if err != nil {
-		return err
+		return GCStats{}, err
 	}
 
-	return collector.GC(ctx, oldGen, newGen)
+	now := time.Now()
+	reflogRoots, err := ddb.reflogRoots(ctx, now)
+	if err != nil {
+		return GCStats{}, err
+	}
+	newGen.InsertAll(reflogRoots)
+
+	if online {
+		stats.GCStats, err = collector.OnlineGC(ctx, oldGen, newGen)
+	} else {
+		stats.GCStats, err = collector.GC(ctx, oldGen, newGen)
+	}
+	if err != nil {
+		return GCStats{}, err
+	}
+
+	if ddb.reflog != nil {
+		_, err = ddb.reflog.Expire(now)
+		if err != nil {
+			return GCStats{}, err
+		}
+	}
+
+	stats.BytesAfter, err = ddb.storeSize(ctx)
+	if err != nil {
+		return GCStats{}, err
+	}
+
+	stats.Duration = time.Since(start)
+	return stats, nil
+}
+
+// storeSize returns the size of the table files of the database, or 0 if its chunk store can't tell.
+func (ddb *DoltDB) storeSize(ctx context.Context) (uint64, error) {
+	sizer, ok := datas.ChunkStoreFromDatabase(ddb.db).(interface {
+		Size(ctx context.Context) (uint64, error)
+	})
+	if !ok {
+		return 0, nil
+	}
+	return sizer.Size(ctx)
+}
+
+// reflogRoots returns the values referenced by reflog entries which have not yet expired as of |now|, which garbage
+// collection keeps alive.
+func (ddb *DoltDB) reflogRoots(ctx context.Context, now time.Time) (hash.HashSet, error) {
+	if ddb.reflog == nil {
+		return hash.NewHashSet(), nil
+	}
+
+	entries, err := ddb.reflog.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	roots := make([]datas.ReflogRoot, 0, 2*len(entries))
+	for _, e := range entries {
+		roots = append(roots, datas.ReflogRoot{Hash: e.OldHash, Timestamp: e.Timestamp})
+		roots = append(roots, datas.ReflogRoot{Hash: e.NewHash, Timestamp: e.Timestamp})
+	}
+
+	keep := datas.ReflogRoots(roots, ddb.reflog.MaxAge(), now)
+
+	// values that are already gone, e.g. because they were collected before the reflog was written, can't be kept
+	for h := range keep.Copy() {
+		val, err := ddb.db.ReadValue(ctx, h)
+		if err != nil {
+			return nil, err
+		}
+		if val == nil {
+			keep.Remove(h)
+		}
+	}
+
+	return keep, nil
 }
 
 func (ddb *DoltDB) pruneUnreferencedDatasets(ctx context.Context) error {
@@ -1276,15 +1471,20 @@ func (ddb *DoltDB) PushChunksForRefHash(ctx context.Context, tempDir string, src
 // PullChunks initiates a pull into a database from the source database given, at the commit given. Progress is
 // communicated over the provided channel.
 func (ddb *DoltDB) PullChunks(ctx context.Context, tempDir string, srcDB *DoltDB, stRef types.Ref, progChan chan datas.PullProgress, pullerEventCh chan datas.PullerEvent) error {
+	return ddb.PullChunksWithDepth(ctx, tempDir, srcDB, stRef, 0, progChan, pullerEventCh)
+}
+
+// pullChunks pulls the chunks of |stRef| from |srcDB|, without following refs to the chunks |excluded|.
+func (ddb *DoltDB) pullChunks(ctx context.Context, tempDir string, srcDB *DoltDB, stRef types.Ref, excluded hash.HashSet, progChan chan datas.PullProgress, pullerEventCh chan datas.PullerEvent) error {
 	if datas.CanUsePuller(srcDB.db) && datas.CanUsePuller(ddb.db) {
-		puller, err := datas.NewPuller(ctx, tempDir, defaultChunksPerTF, srcDB.db, ddb.db, stRef.TargetHash(), pullerEventCh)
+		puller, err := datas.NewShallowPuller(ctx, tempDir, defaultChunksPerTF, srcDB.db, ddb.db, stRef.TargetHash(), excluded, pullerEventCh)
 		if err != nil {
 			return err
 		}
 
 		return puller.Pull(ctx)
 	} else {
-		return datas.PullWithoutBatching(ctx, srcDB.db, ddb.db, stRef, progChan)
+		return datas.PullShallowWithoutBatching(ctx, srcDB.db, ddb.db, stRef, excluded, progChan)
 	}
 }
 
@@ -178,6 +178,26 @@ func VrwFromTable(t Table) types.ValueReadWriter {
 	return t.(nomsTable).vrw
 }
 
+// RowDataRefs returns the refs of |t| to its row data and to the index set holding the row data of its secondary
+// indexes.
+func RowDataRefs(t Table) ([]types.Ref, error) {
+	nt := t.(nomsTable)
+
+	var refs []types.Ref
+	for _, key := range []string{tableRowsKey, indexesKey} {
+		v, ok, err := nt.tableStruct.MaybeGet(key)
+		if err != nil {
+			return nil, err
+		}
+
+		if r, isRef := v.(types.Ref); ok && isRef {
+			refs = append(refs, r)
+		}
+	}
+
+	return refs, nil
+}
+
 // valueReadWriter returns the valueReadWriter for this table.
 func (t nomsTable) valueReadWriter() types.ValueReadWriter {
 	return t.vrw
@@ -49,6 +49,7 @@ var ErrIsBehind = errors.New("cannot reverse from b to a. b is a is behind a alr
 var ErrUnresolvedConflicts = errors.New("merge has unresolved conflicts. please use the dolt_conflicts table to resolve")
 var ErrUnresolvedConstraintViolations = errors.New("merge has unresolved constraint violations. please use the dolt_constraint_violations table to resolve")
 var ErrMergeActive = errors.New("merging is not possible because you have not committed an active merge")
+var ErrRebaseActive = errors.New("a rebase is in progress. use 'dolt rebase --continue' or 'dolt rebase --abort' to finish it")
 
 type ErrClientOutOfDate struct {
 	RepoVer   FeatureVersion
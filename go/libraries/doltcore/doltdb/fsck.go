@@ -0,0 +1,526 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doltdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dolthub/dolt/go/store/chunks"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+// fsckBatchSize is the number of chunks requested from the chunk store at once while walking the database.
+const fsckBatchSize = 16 * 1024
+
+// ErrMissingChunk is the error of a chunk which is referenced, but is not in the chunk store.
+var ErrMissingChunk = errors.New("chunk is missing")
+
+// ErrCorruptChunk is the error of a chunk whose contents do not match its address, or which holds no valid value.
+var ErrCorruptChunk = errors.New("chunk is corrupt")
+
+const (
+	// FsckDatabaseRoot is the name given to the root of the database, which holds its refs, when reporting the refs
+	// which depend on a damaged chunk.
+	FsckDatabaseRoot = "<database root>"
+
+	// FsckReflog is the name given to the values kept alive by the reflog when reporting the refs which depend on a
+	// damaged chunk.
+	FsckReflog = "<reflog>"
+)
+
+// DamagedChunk is a chunk which is missing, corrupt or holds a value which cannot be decoded.
+type DamagedChunk struct {
+	Hash hash.Hash
+	Err  error
+	// Refs holds the refs, and other datasets, from which the chunk is reachable.
+	Refs []string
+}
+
+// FsckReport is the result of checking the integrity of a DoltDB.
+type FsckReport struct {
+	// Refs is the number of refs, and other datasets, which were checked.
+	Refs int
+	// Reachable is the number of chunks reachable from the root of the database and from the reflog.
+	Reachable int
+	// Missing holds the chunks which are referenced, but not in the chunk store.
+	Missing []DamagedChunk
+	// Corrupt holds the chunks whose contents do not match their address, or cannot be read.
+	Corrupt []DamagedChunk
+	// Invalid holds the chunks of commits and root values which cannot be decoded.
+	Invalid []DamagedChunk
+	// Orphaned holds the chunks of the chunk store which are not reachable from the root of the database or from the
+	// reflog. These are not damage, and are removed by garbage collection.
+	Orphaned []hash.Hash
+	// Warnings holds the problems which kept parts of the database from being checked, but which are not damage to the
+	// database itself.
+	Warnings []string
+}
+
+// Damaged returns whether any missing, corrupt or invalid chunks were found.
+func (r *FsckReport) Damaged() bool {
+	return len(r.Missing) > 0 || len(r.Corrupt) > 0 || len(r.Invalid) > 0
+}
+
+// Fsck checks the integrity of the database. It walks every chunk reachable from the root of the database and from the
+// reflog, checking that it is in the chunk store and that its contents match its address, and decodes every commit and
+// root value, along with the schemas and row data of their tables. Damaged chunks are reported with the refs which depend on them.
+// |stored| holds the addresses of the chunks of the chunk store, if they are known, and is used to find orphaned
+// chunks. An error is only returned when the check itself cannot be run.
+func (ddb *DoltDB) Fsck(ctx context.Context, stored hash.HashSet) (*FsckReport, error) {
+	cs := datas.ChunkStoreFromDatabase(ddb.db)
+
+	root, err := cs.Root(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &FsckReport{}
+	if root.IsEmpty() {
+		return report, nil
+	}
+
+	// the values kept by the reflog cannot be listed if one of them cannot be read, and only the root of the database
+	// is walked then
+	reflog, err := ddb.reflogRoots(ctx, time.Now())
+	if err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("the values kept by the reflog were not checked: %v", err))
+		reflog = hash.NewHashSet()
+	}
+
+	roots := reflog.Copy()
+	roots.Insert(root)
+
+	w := newChunkWalk(cs, ddb.Format(), true)
+	if ddb.IsShallow() || datas.IsPartial(ddb.db) {
+		// the ancestors of the shallow commits, and the row data a partial clone fetches from its remote, are not
+		// missing
+		w.absentFilter = func(ctx context.Context, absent hash.HashSet) (hash.HashSet, error) {
+			return datas.DanglingRefs(ctx, ddb.db, absent)
+		}
+	}
+	if err = w.walk(ctx, roots); err != nil {
+		return nil, err
+	}
+	report.Reachable = len(w.reachable)
+
+	damaged := make(map[hash.Hash]error)
+	for h, err := range w.damaged {
+		damaged[h] = err
+	}
+
+	for _, h := range sortedHashes(w.structs) {
+		if _, ok := damaged[h]; ok {
+			continue
+		}
+		if err := ddb.checkStruct(ctx, h, w.absent); err != nil {
+			damaged[h] = err
+		}
+	}
+
+	for h := range stored {
+		if !w.reachable.Has(h) {
+			report.Orphaned = append(report.Orphaned, h)
+		}
+	}
+	sort.Slice(report.Orphaned, func(i, j int) bool {
+		return report.Orphaned[i].Less(report.Orphaned[j])
+	})
+
+	refs := make(map[hash.Hash][]string, len(damaged))
+	datasets, err := ddb.datasetHeads(ctx)
+	if err != nil {
+		// the refs themselves cannot be read, so everything damaged is only attributed to the root of the database
+		for h := range damaged {
+			refs[h] = []string{FsckDatabaseRoot}
+		}
+	} else {
+		report.Refs = len(datasets)
+		if len(reflog) > 0 {
+			datasets[FsckReflog] = reflog
+		}
+		if len(damaged) > 0 {
+			refs, err = attributeDamage(ctx, cs, ddb.Format(), datasets, damaged)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, h := range sortedHashesOf(damaged) {
+		err := damaged[h]
+		dc := DamagedChunk{Hash: h, Err: err, Refs: refs[h]}
+		if len(dc.Refs) == 0 {
+			dc.Refs = []string{FsckDatabaseRoot}
+		}
+
+		switch {
+		case errors.Is(err, ErrMissingChunk):
+			report.Missing = append(report.Missing, dc)
+		case errors.Is(err, ErrCorruptChunk):
+			report.Corrupt = append(report.Corrupt, dc)
+		default:
+			report.Invalid = append(report.Invalid, dc)
+		}
+	}
+
+	return report, nil
+}
+
+// datasetHeads returns the hash of the head of each dataset of the database, by dataset name.
+func (ddb *DoltDB) datasetHeads(ctx context.Context) (map[string]hash.HashSet, error) {
+	datasets, err := ddb.db.Datasets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	heads := make(map[string]hash.HashSet)
+	err = datasets.IterAll(ctx, func(key, value types.Value) error {
+		r, ok := value.(types.Ref)
+		if !ok {
+			return fmt.Errorf("dataset %s does not hold a ref", key)
+		}
+		heads[string(key.(types.String))] = hash.NewHashSet(r.TargetHash())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return heads, nil
+}
+
+// checkStruct decodes the commit or root value in the chunk |h|, along with the tables of its root value. The row data
+// of tables which is in |absent| is not decoded.
+func (ddb *DoltDB) checkStruct(ctx context.Context, h hash.Hash, absent hash.HashSet) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to decode value: %v", r)
+		}
+	}()
+
+	val, err := ddb.db.ReadValue(ctx, h)
+	if err != nil {
+		return err
+	}
+
+	st, ok := val.(types.Struct)
+	if !ok {
+		return nil
+	}
+
+	switch st.Name() {
+	case CommitStructName:
+		cm := NewCommit(ddb.db, st)
+		if _, err = cm.GetCommitMeta(); err != nil {
+			return fmt.Errorf("invalid commit metadata: %w", err)
+		}
+
+		root, err := cm.GetRootValue()
+		if err != nil {
+			return fmt.Errorf("invalid commit root value: %w", err)
+		}
+
+		return checkRootValue(ctx, root, absent)
+	case ddbRootStructName:
+		root, err := newRootValue(ddb.db, st)
+		if err != nil {
+			return fmt.Errorf("invalid root value: %w", err)
+		}
+
+		return checkRootValue(ctx, root, absent)
+	}
+
+	return nil
+}
+
+// checkRootValue decodes the tables of |root|: their schemas, row data and index sets, along with the foreign keys and
+// super schemas of the root value. The chunks of row data and indexes are checked by walking them. The row data and
+// indexes of tables whose row data is in |absent| are not decoded.
+func checkRootValue(ctx context.Context, root *RootValue, absent hash.HashSet) error {
+	if _, err := root.GetForeignKeyCollection(ctx); err != nil {
+		return fmt.Errorf("invalid foreign keys: %w", err)
+	}
+
+	if _, err := root.GetSuperSchemaMap(ctx); err != nil {
+		return fmt.Errorf("invalid super schemas: %w", err)
+	}
+
+	names, err := root.GetTableNames(ctx)
+	if err != nil {
+		return fmt.Errorf("invalid table map: %w", err)
+	}
+
+	for _, name := range names {
+		tbl, ok, err := root.GetTable(ctx, name)
+		if err != nil {
+			return fmt.Errorf("invalid table %s: %w", name, err)
+		} else if !ok {
+			return fmt.Errorf("table %s is missing from the table map", name)
+		}
+
+		if _, err = tbl.GetSchema(ctx); err != nil {
+			return fmt.Errorf("invalid schema of table %s: %w", name, err)
+		}
+
+		if len(absent) > 0 {
+			hashes, err := tbl.rowDataHashes()
+			if err != nil {
+				return fmt.Errorf("invalid table %s: %w", name, err)
+			}
+
+			held := true
+			for h := range hashes {
+				if absent.Has(h) {
+					held = false
+					break
+				}
+			}
+			if !held {
+				continue
+			}
+		}
+
+		if _, err = tbl.GetNomsRowData(ctx); err != nil {
+			return fmt.Errorf("invalid row data of table %s: %w", name, err)
+		}
+
+		if _, err = tbl.GetIndexData(ctx); err != nil {
+			return fmt.Errorf("invalid indexes of table %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// attributeDamage returns the datasets from which each of the |damaged| chunks is reachable. Each dataset is walked on
+// its own, so this is only done once damage has been found.
+func attributeDamage(ctx context.Context, cs chunks.ChunkStore, nbf *types.NomsBinFormat, datasets map[string]hash.HashSet, damaged map[hash.Hash]error) (map[hash.Hash][]string, error) {
+	names := make([]string, 0, len(datasets))
+	for name := range datasets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	refs := make(map[hash.Hash][]string)
+	for _, name := range names {
+		w := newChunkWalk(cs, nbf, false)
+		if err := w.walk(ctx, datasets[name]); err != nil {
+			return nil, err
+		}
+
+		for h := range damaged {
+			if w.reachable.Has(h) {
+				refs[h] = append(refs[h], name)
+			}
+		}
+	}
+
+	return refs, nil
+}
+
+// chunkWalk walks the chunks reachable from a set of roots, recording the chunks which are missing or corrupt.
+type chunkWalk struct {
+	cs  chunks.ChunkStore
+	nbf *types.NomsBinFormat
+
+	mu        sync.Mutex
+	reachable hash.HashSet
+	damaged   map[hash.Hash]error
+
+	// structs holds the chunks holding structs, which may be commits or root values, when |findStructs| is set.
+	findStructs bool
+	structs     hash.HashSet
+
+	// absentFilter, if set, is given the chunks which are not in the chunk store, and returns those which are missing.
+	// The others are recorded in |absent| and are not walked, such as the commits grafted away by the shallow commits
+	// of the database.
+	absentFilter func(ctx context.Context, absent hash.HashSet) (hash.HashSet, error)
+	absent       hash.HashSet
+}
+
+func newChunkWalk(cs chunks.ChunkStore, nbf *types.NomsBinFormat, findStructs bool) *chunkWalk {
+	return &chunkWalk{
+		cs:          cs,
+		nbf:         nbf,
+		reachable:   hash.NewHashSet(),
+		damaged:     make(map[hash.Hash]error),
+		findStructs: findStructs,
+		structs:     hash.NewHashSet(),
+		absent:      hash.NewHashSet(),
+	}
+}
+
+func (w *chunkWalk) walk(ctx context.Context, roots hash.HashSet) error {
+	next := roots
+	for len(next) > 0 {
+		for h := range next {
+			w.reachable.Insert(h)
+		}
+
+		refs := hash.NewHashSet()
+		for _, batch := range batchHashes(next, fsckBatchSize) {
+			if err := w.visit(ctx, batch, refs); err != nil {
+				return err
+			}
+		}
+
+		next = hash.NewHashSet()
+		for h := range refs {
+			if !w.reachable.Has(h) {
+				next.Insert(h)
+			}
+		}
+	}
+
+	return nil
+}
+
+// visit reads the chunks |batch|, and adds the chunks they reference to |refs|.
+func (w *chunkWalk) visit(ctx context.Context, batch hash.HashSet, refs hash.HashSet) error {
+	absent, err := w.cs.HasMany(ctx, batch)
+	if err != nil {
+		return err
+	}
+
+	missing := absent
+	if len(absent) > 0 && w.absentFilter != nil {
+		missing, err = w.absentFilter(ctx, absent)
+		if err != nil {
+			return err
+		}
+	}
+
+	present := hash.NewHashSet()
+	for h := range batch {
+		if !absent.Has(h) {
+			present.Insert(h)
+		} else if missing.Has(h) {
+			w.damaged[h] = ErrMissingChunk
+		} else {
+			w.reachable.Remove(h)
+			w.absent.Insert(h)
+		}
+	}
+
+	found := hash.NewHashSet()
+	err = w.cs.GetMany(ctx, present, func(ctx context.Context, c *chunks.Chunk) {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		found.Insert(c.Hash())
+		w.visitChunk(*c, refs)
+	})
+
+	if err != nil {
+		// a chunk of the batch could not be read, so they are read one by one to find which
+		for h := range present {
+			if found.Has(h) {
+				continue
+			}
+
+			c, err := w.cs.Get(ctx, h)
+			if err != nil {
+				w.damaged[h] = fmt.Errorf("%w: %v", ErrCorruptChunk, err)
+				found.Insert(h)
+				continue
+			}
+
+			if !c.IsEmpty() {
+				found.Insert(h)
+				w.visitChunk(c, refs)
+			}
+		}
+	}
+
+	for h := range present {
+		if !found.Has(h) {
+			w.damaged[h] = ErrMissingChunk
+		}
+	}
+
+	return nil
+}
+
+// callers must acquire lock |w.mu| if chunks are visited concurrently
+func (w *chunkWalk) visitChunk(c chunks.Chunk, refs hash.HashSet) {
+	if hash.Of(c.Data()) != c.Hash() {
+		w.damaged[c.Hash()] = fmt.Errorf("%w: contents do not match its address", ErrCorruptChunk)
+		return
+	}
+
+	if w.findStructs && len(c.Data()) > 0 && types.NomsKind(c.Data()[0]) == types.StructKind {
+		w.structs.Insert(c.Hash())
+	}
+
+	if err := walkChunkRefs(c, w.nbf, refs); err != nil {
+		w.damaged[c.Hash()] = fmt.Errorf("%w: %v", ErrCorruptChunk, err)
+	}
+}
+
+func walkChunkRefs(c chunks.Chunk, nbf *types.NomsBinFormat, refs hash.HashSet) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to decode chunk: %v", r)
+		}
+	}()
+
+	return types.WalkRefs(c, nbf, func(r types.Ref) error {
+		refs.Insert(r.TargetHash())
+		return nil
+	})
+}
+
+func batchHashes(hs hash.HashSet, size int) []hash.HashSet {
+	var batches []hash.HashSet
+	batch := hash.NewHashSet()
+	for h := range hs {
+		batch.Insert(h)
+		if len(batch) == size {
+			batches = append(batches, batch)
+			batch = hash.NewHashSet()
+		}
+	}
+
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+
+	return batches
+}
+
+func sortedHashes(hs hash.HashSet) []hash.Hash {
+	sorted := make([]hash.Hash, 0, len(hs))
+	for h := range hs {
+		sorted = append(sorted, h)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Less(sorted[j])
+	})
+	return sorted
+}
+
+func sortedHashesOf(m map[hash.Hash]error) []hash.Hash {
+	hs := hash.NewHashSet()
+	for h := range m {
+		hs.Insert(h)
+	}
+	return sortedHashes(hs)
+}
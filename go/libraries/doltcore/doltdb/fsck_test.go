@@ -0,0 +1,179 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doltdb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+	"github.com/dolthub/dolt/go/store/chunks"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+// damagedChunkStore is a chunk store which is missing the chunks |missing|, and returns garbage for the chunks
+// |corrupt|.
+type damagedChunkStore struct {
+	chunks.ChunkStore
+	missing hash.HashSet
+	corrupt hash.HashSet
+}
+
+func (cs damagedChunkStore) damage(c chunks.Chunk) chunks.Chunk {
+	if cs.missing.Has(c.Hash()) {
+		return chunks.EmptyChunk
+	} else if cs.corrupt.Has(c.Hash()) {
+		return chunks.NewChunkWithHash(c.Hash(), []byte("garbage"))
+	}
+	return c
+}
+
+func (cs damagedChunkStore) Get(ctx context.Context, h hash.Hash) (chunks.Chunk, error) {
+	c, err := cs.ChunkStore.Get(ctx, h)
+	if err != nil {
+		return chunks.EmptyChunk, err
+	}
+	return cs.damage(c), nil
+}
+
+func (cs damagedChunkStore) GetMany(ctx context.Context, hashes hash.HashSet, found func(context.Context, *chunks.Chunk)) error {
+	return cs.ChunkStore.GetMany(ctx, hashes, func(ctx context.Context, c *chunks.Chunk) {
+		if d := cs.damage(*c); !d.IsEmpty() {
+			found(ctx, &d)
+		}
+	})
+}
+
+func (cs damagedChunkStore) Has(ctx context.Context, h hash.Hash) (bool, error) {
+	if cs.missing.Has(h) {
+		return false, nil
+	}
+	return cs.ChunkStore.Has(ctx, h)
+}
+
+func (cs damagedChunkStore) HasMany(ctx context.Context, hashes hash.HashSet) (hash.HashSet, error) {
+	absent, err := cs.ChunkStore.HasMany(ctx, hashes)
+	if err != nil {
+		return nil, err
+	}
+	for h := range hashes {
+		if cs.missing.Has(h) {
+			absent.Insert(h)
+		}
+	}
+	return absent, nil
+}
+
+// createFsckTestDB creates a database with a commit of a table on master, and returns the storage of the database
+// and the hash of the chunk of the table.
+func createFsckTestDB(t *testing.T) (*chunks.MemoryStorage, hash.Hash) {
+	ctx := context.Background()
+	storage := &chunks.MemoryStorage{}
+	ddb := DoltDBFromCS(storage.NewViewWithDefaultFormat())
+	require.NoError(t, ddb.WriteEmptyRepo(ctx, "master", "Bill Billerson", "bigbillieb@fake.horse"))
+
+	cs, err := NewCommitSpec("master")
+	require.NoError(t, err)
+	commit, err := ddb.Resolve(ctx, cs, nil)
+	require.NoError(t, err)
+	root, err := commit.GetRootValue()
+	require.NoError(t, err)
+
+	sch := createTestSchema(t)
+	rowData, _ := createTestRowData(t, ddb.db, sch)
+	tbl, err := CreateTestTable(ddb.db, sch, rowData)
+	require.NoError(t, err)
+	root, err = root.PutTable(ctx, "test", tbl)
+	require.NoError(t, err)
+
+	valHash, err := ddb.WriteRootValue(ctx, root)
+	require.NoError(t, err)
+	meta, err := NewCommitMeta("Bill Billerson", "bigbillieb@fake.horse", "Sample data")
+	require.NoError(t, err)
+	_, err = ddb.Commit(ctx, valHash, ref.NewBranchRef("master"), meta)
+	require.NoError(t, err)
+
+	tblHash, ok, err := root.GetTableHash(ctx, "test")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	return storage, tblHash
+}
+
+func TestFsck(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("intact", func(t *testing.T) {
+		storage, _ := createFsckTestDB(t)
+		orphan := hash.Of([]byte("orphan"))
+
+		report, err := DoltDBFromCS(storage.NewViewWithDefaultFormat()).Fsck(ctx, hash.NewHashSet(orphan))
+		require.NoError(t, err)
+		assert.False(t, report.Damaged())
+		assert.Equal(t, 2, report.Refs)
+		assert.True(t, report.Reachable > 0)
+		assert.Equal(t, []hash.Hash{orphan}, report.Orphaned)
+	})
+
+	t.Run("unreadable reflog", func(t *testing.T) {
+		storage, _ := createFsckTestDB(t)
+		ddb := DoltDBFromCS(storage.NewViewWithDefaultFormat())
+
+		// the reflog is a directory, so its entries cannot be read
+		fs := filesys.NewInMemFS([]string{"/repo/.dolt/reflog"}, nil, "/repo")
+		ddb.reflog = NewReflog(fs, "/repo/.dolt/reflog")
+
+		report, err := ddb.Fsck(ctx, nil)
+		require.NoError(t, err)
+		assert.False(t, report.Damaged())
+		require.Len(t, report.Warnings, 1)
+		assert.Contains(t, report.Warnings[0], "reflog")
+	})
+
+	t.Run("missing chunk", func(t *testing.T) {
+		storage, tblHash := createFsckTestDB(t)
+		cs := damagedChunkStore{storage.NewViewWithDefaultFormat(), hash.NewHashSet(tblHash), hash.NewHashSet()}
+
+		report, err := DoltDBFromCS(cs).Fsck(ctx, nil)
+		require.NoError(t, err)
+		assert.True(t, report.Damaged())
+		require.Len(t, report.Missing, 1)
+		assert.Equal(t, tblHash, report.Missing[0].Hash)
+		assert.ErrorIs(t, report.Missing[0].Err, ErrMissingChunk)
+		assert.Equal(t, []string{"refs/heads/master"}, report.Missing[0].Refs)
+		assert.Empty(t, report.Corrupt)
+
+		// the commit whose table is missing cannot be decoded either
+		assert.NotEmpty(t, report.Invalid)
+	})
+
+	t.Run("corrupt chunk", func(t *testing.T) {
+		storage, tblHash := createFsckTestDB(t)
+		cs := damagedChunkStore{storage.NewViewWithDefaultFormat(), hash.NewHashSet(), hash.NewHashSet(tblHash)}
+
+		report, err := DoltDBFromCS(cs).Fsck(ctx, nil)
+		require.NoError(t, err)
+		assert.True(t, report.Damaged())
+		require.Len(t, report.Corrupt, 1)
+		assert.Equal(t, tblHash, report.Corrupt[0].Hash)
+		assert.ErrorIs(t, report.Corrupt[0].Err, ErrCorruptChunk)
+		assert.Equal(t, []string{"refs/heads/master"}, report.Corrupt[0].Refs)
+		assert.Empty(t, report.Missing)
+	})
+}
@@ -131,7 +131,7 @@ func testGarbageCollection(t *testing.T, test gcTest) {
 	require.NoError(t, err)
 	// save working root during GC
 
-	err = dEnv.DoltDB.GC(ctx, h)
+	_, err = dEnv.DoltDB.GC(ctx, h)
 	require.NoError(t, err)
 	test.postGCFunc(ctx, t, dEnv.DoltDB, res)
 
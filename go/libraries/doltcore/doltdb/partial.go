@@ -0,0 +1,252 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doltdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/dbfactory"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+	"github.com/dolthub/dolt/go/store/chunks"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+// PartialFile is the name of the file describing a partial clone, within the dolt directory.
+const PartialFile = "partial"
+
+// ErrPartialClone is returned when the row data of a table a partial clone does not hold is needed, and cannot be
+// fetched from the remote it was cloned from.
+var ErrPartialClone = errors.New("the table data needed is not held by this partial clone")
+
+// PartialClone describes a database cloned with the row data of only some of its tables. The commits, root values and
+// schemas of its whole history are held, along with the row data of |Tables|. The row data of the other tables is
+// fetched from |Remote| when it is read. System tables are always held.
+type PartialClone struct {
+	Remote string   `json:"remote"`
+	Tables []string `json:"tables"`
+}
+
+// HoldsTable returns whether the partial clone holds the row data of the table |name|.
+func (pc PartialClone) HoldsTable(name string) bool {
+	if HasDoltPrefix(name) {
+		return true
+	}
+
+	for _, t := range pc.Tables {
+		if strings.EqualFold(t, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// partialFile stores the PartialClone of a local database as JSON.
+type partialFile struct {
+	fs   filesys.Filesys
+	path string
+}
+
+func newLocalPartialFile(fs filesys.Filesys) (*partialFile, error) {
+	path, err := fs.Abs(filepath.Join(dbfactory.DoltDir, PartialFile))
+	if err != nil {
+		return nil, err
+	}
+
+	return &partialFile{fs: fs, path: path}, nil
+}
+
+func (pf *partialFile) read() (*PartialClone, error) {
+	if exists, _ := pf.fs.Exists(pf.path); !exists {
+		return nil, nil
+	}
+
+	data, err := pf.fs.ReadFile(pf.path)
+	if err != nil {
+		return nil, err
+	}
+
+	var pc PartialClone
+	err = json.Unmarshal(data, &pc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid partial clone file %s: %w", pf.path, err)
+	}
+
+	return &pc, nil
+}
+
+func (pf *partialFile) write(pc PartialClone) error {
+	data, err := json.MarshalIndent(pc, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return pf.fs.WriteFile(pf.path, data)
+}
+
+// PartialClone returns the PartialClone describing the database, and whether it is a partial clone.
+func (ddb *DoltDB) PartialClone() (PartialClone, bool) {
+	if ddb.partial == nil {
+		return PartialClone{}, false
+	}
+
+	return *ddb.partial, true
+}
+
+// IsPartial returns whether the database is a partial clone.
+func (ddb *DoltDB) IsPartial() bool {
+	return ddb.partial != nil
+}
+
+// SetPartialClone makes the database a partial clone described by |pc|, which fetches the row data it does not hold
+// from the database |open| returns.
+func (ddb *DoltDB) SetPartialClone(pc PartialClone, open func(ctx context.Context) (*DoltDB, error)) error {
+	if ddb.partialFile != nil {
+		err := ddb.partialFile.write(pc)
+		if err != nil {
+			return err
+		}
+	}
+
+	ddb.partial = &pc
+	return ddb.SetPartialCloneSource(open)
+}
+
+// SetPartialCloneSource sets the database a partial clone fetches the row data it does not hold from, to the database
+// |open| returns. It is opened the first time row data is fetched.
+func (ddb *DoltDB) SetPartialCloneSource(open func(ctx context.Context) (*DoltDB, error)) error {
+	if ddb.partial == nil {
+		return errors.New("the database is not a partial clone")
+	}
+
+	return datas.SetPartialSource(ddb.db, &partialCloneSource{remote: ddb.partial.Remote, open: open})
+}
+
+// partialCloneExcluded returns the row data of the tables the database does not hold in the root values of the
+// |commits| of |srcDB|, which a pull into the database leaves out. Row data which is shared with a table the database
+// does hold is not left out.
+func (ddb *DoltDB) partialCloneExcluded(ctx context.Context, srcDB *DoltDB, commits hash.HashSet) (hash.HashSet, error) {
+	excluded := hash.NewHashSet()
+	held := hash.NewHashSet()
+	for h := range commits {
+		v, err := srcDB.db.ReadValue(ctx, h)
+		if err != nil {
+			return nil, err
+		}
+
+		st, ok := v.(types.Struct)
+		if !ok || st.Name() != CommitStructName {
+			return nil, fmt.Errorf("value is not a commit: %s", h.String())
+		}
+
+		root, err := NewCommit(srcDB.db, st).GetRootValue()
+		if err != nil {
+			return nil, err
+		}
+
+		err = root.IterTables(ctx, func(name string, table *Table, _ schema.Schema) (stop bool, err error) {
+			hashes, err := table.rowDataHashes()
+			if err != nil {
+				return true, err
+			}
+
+			if ddb.partial.HoldsTable(name) {
+				held.InsertAll(hashes)
+			} else {
+				excluded.InsertAll(hashes)
+			}
+
+			return false, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for h := range held {
+		excluded.Remove(h)
+	}
+
+	return excluded, nil
+}
+
+// partialCloneSource is the ChunkFetcher of a partial clone, which fetches the chunks it does not hold from the remote
+// it was cloned from. The remote is opened the first time it is used, and again on the next use if it could not be
+// opened, e.g. because it was unreachable.
+type partialCloneSource struct {
+	remote string
+	open   func(ctx context.Context) (*DoltDB, error)
+
+	mu sync.Mutex
+	cs chunks.ChunkStore
+}
+
+var _ chunks.ChunkFetcher = (*partialCloneSource)(nil)
+
+func (s *partialCloneSource) chunkStore(ctx context.Context) (chunks.ChunkStore, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cs != nil {
+		return s.cs, nil
+	}
+
+	db, err := s.open(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w, and remote '%s' could not be opened to fetch it: %s", ErrPartialClone, s.remote, err.Error())
+	}
+
+	s.cs = datas.ChunkStoreFromDatabase(db.db)
+	return s.cs, nil
+}
+
+// GetMany implements chunks.ChunkFetcher.
+func (s *partialCloneSource) GetMany(ctx context.Context, hashes hash.HashSet, found func(context.Context, *chunks.Chunk)) error {
+	cs, err := s.chunkStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = cs.GetMany(ctx, hashes, found)
+	if err != nil {
+		return fmt.Errorf("%w, and it could not be fetched from remote '%s': %s", ErrPartialClone, s.remote, err.Error())
+	}
+
+	return nil
+}
+
+// HasMany implements chunks.ChunkFetcher.
+func (s *partialCloneSource) HasMany(ctx context.Context, hashes hash.HashSet) (hash.HashSet, error) {
+	cs, err := s.chunkStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	absent, err := cs.HasMany(ctx, hashes)
+	if err != nil {
+		return nil, fmt.Errorf("%w, and remote '%s' could not be checked for it: %s", ErrPartialClone, s.remote, err.Error())
+	}
+
+	return absent, nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doltdb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/store/chunks"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+func TestPartialCloneSourceRetriesOpen(t *testing.T) {
+	ctx := context.Background()
+	remote := (&chunks.MemoryStorage{}).NewViewWithDefaultFormat()
+	c := chunks.NewChunk([]byte("remote chunk"))
+	require.NoError(t, remote.Put(ctx, c))
+
+	opens := 0
+	src := &partialCloneSource{
+		remote: "origin",
+		open: func(ctx context.Context) (*DoltDB, error) {
+			opens++
+			if opens == 1 {
+				return nil, errors.New("remote unreachable")
+			}
+			return DoltDBFromCS(remote), nil
+		},
+	}
+
+	_, err := src.HasMany(ctx, hash.NewHashSet(c.Hash()))
+	require.ErrorIs(t, err, ErrPartialClone)
+
+	// the remote is opened again once it is reachable, and then kept open
+	absent, err := src.HasMany(ctx, hash.NewHashSet(c.Hash()))
+	require.NoError(t, err)
+	assert.Empty(t, absent)
+
+	var fetched []hash.Hash
+	err = src.GetMany(ctx, hash.NewHashSet(c.Hash()), func(_ context.Context, c *chunks.Chunk) {
+		fetched = append(fetched, c.Hash())
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []hash.Hash{c.Hash()}, fetched)
+	assert.Equal(t, 2, opens)
+}
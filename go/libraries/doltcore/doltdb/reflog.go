@@ -0,0 +1,457 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doltdb
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dolthub/fslock"
+	"github.com/sirupsen/logrus"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/dbfactory"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+// ReflogFile is the name of the file holding the reflog of a local database, within the dolt directory.
+const ReflogFile = "reflog"
+
+// DefaultReflogMaxAge is the age after which reflog entries expire, unless configured otherwise.
+const DefaultReflogMaxAge = 90 * 24 * time.Hour
+
+// MaxReflogCommandLen is the length in bytes to which the commands recorded in the reflog are truncated.
+const MaxReflogCommandLen = 256
+
+// WorkingSetReflogInterval is the least time between two entries recorded for a working set by the same command and
+// user. Updates to a working set made within it are not recorded, so that a command updating the working set many
+// times does not flood the reflog. Each entry still holds the hash the working set pointed to before its update.
+const WorkingSetReflogInterval = time.Second
+
+// reflogLockTimeout is how long to wait for another process to unlock the reflog.
+const reflogLockTimeout = 10 * time.Second
+
+// ReflogEntry records a single update to a branch, tag or working set ref.
+type ReflogEntry struct {
+	// Ref is the full path of the ref that was updated, e.g. refs/heads/main.
+	Ref string
+	// OldHash is the hash the ref pointed to before the update. It is empty if the ref was created.
+	OldHash hash.Hash
+	// NewHash is the hash the ref pointed to after the update. It is empty if the ref was deleted.
+	NewHash hash.Hash
+	// Command is the command or query that made the update, truncated to MaxReflogCommandLen bytes.
+	Command string
+	// Timestamp is the time of the update.
+	Timestamp time.Time
+	// User is the user that made the update.
+	User string
+	// Index is the position of the entry among the entries for its ref, where 0 is the most recent. It is set when
+	// entries are read from the reflog.
+	Index int
+}
+
+type reflogEntryJSON struct {
+	Ref       string `json:"ref"`
+	OldHash   string `json:"old"`
+	NewHash   string `json:"new"`
+	Command   string `json:"command"`
+	Timestamp int64  `json:"timestamp"`
+	User      string `json:"user"`
+}
+
+// Reflog is an append-only log of the updates made to the refs of a local database. The reflog is not part of the
+// database itself, so it is neither pushed nor cloned, and its entries expire when garbage collection runs. The
+// reflog file is locked while it is read or written, as it may be shared by several processes.
+type Reflog struct {
+	fs     filesys.Filesys
+	path   string
+	maxAge time.Duration
+	mu     *sync.Mutex
+	// lastWorkingSet holds the last entry appended by this process for each working set ref.
+	lastWorkingSet map[string]ReflogEntry
+}
+
+// NewReflog returns the reflog stored in the file at |path|, which is created when the first entry is appended.
+func NewReflog(fs filesys.Filesys, path string) *Reflog {
+	return &Reflog{fs: fs, path: path, maxAge: DefaultReflogMaxAge, mu: &sync.Mutex{}, lastWorkingSet: make(map[string]ReflogEntry)}
+}
+
+func newLocalReflog(fs filesys.Filesys) (*Reflog, error) {
+	path, err := fs.Abs(filepath.Join(dbfactory.DoltDir, ReflogFile))
+	if err != nil {
+		return nil, err
+	}
+
+	return NewReflog(fs, path), nil
+}
+
+// MaxAge returns the age after which entries expire.
+func (rl *Reflog) MaxAge() time.Duration {
+	return rl.maxAge
+}
+
+// SetMaxAge sets the age after which entries expire.
+func (rl *Reflog) SetMaxAge(maxAge time.Duration) {
+	rl.maxAge = maxAge
+}
+
+// Append appends the entry given to the reflog. An entry for a working set is not appended if it was made by the same
+// command and user within WorkingSetReflogInterval of the last entry appended for the working set by this process.
+func (rl *Reflog) Append(entry ReflogEntry) error {
+	data, err := json.Marshal(toReflogEntryJSON(entry))
+	if err != nil {
+		return err
+	}
+
+	unlock, err := rl.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if ref.IsWorkingSet(entry.Ref) {
+		last, ok := rl.lastWorkingSet[entry.Ref]
+		if ok && last.Command == entry.Command && last.User == entry.User && entry.Timestamp.Sub(last.Timestamp) < WorkingSetReflogInterval {
+			return nil
+		}
+	}
+
+	wr, err := rl.fs.OpenForWriteAppend(rl.path, 0644)
+	if err != nil {
+		return err
+	}
+
+	_, err = wr.Write(append(data, '\n'))
+	if err != nil {
+		_ = wr.Close()
+		return err
+	}
+
+	err = wr.Close()
+	if err != nil {
+		return err
+	}
+
+	if ref.IsWorkingSet(entry.Ref) {
+		rl.lastWorkingSet[entry.Ref] = entry
+	}
+
+	return nil
+}
+
+// Entries returns all entries of the reflog, oldest first.
+func (rl *Reflog) Entries() ([]ReflogEntry, error) {
+	unlock, err := rl.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return rl.readEntries()
+}
+
+// EntriesForRef returns the entries of the reflog for the ref with the full path given, e.g. refs/heads/main, oldest
+// first.
+func (rl *Reflog) EntriesForRef(refPath string) ([]ReflogEntry, error) {
+	entries, err := rl.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []ReflogEntry
+	for _, e := range entries {
+		if e.Ref == refPath {
+			filtered = append(filtered, e)
+		}
+	}
+
+	return filtered, nil
+}
+
+// Expire removes the entries of the reflog that are older than its max age as of |now|, and returns the remaining
+// entries, oldest first.
+func (rl *Reflog) Expire(now time.Time) ([]ReflogEntry, error) {
+	unlock, err := rl.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	entries, err := rl.readEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := now.Add(-rl.maxAge)
+	var kept []ReflogEntry
+	for _, e := range entries {
+		if !e.Timestamp.Before(cutoff) {
+			kept = append(kept, e)
+		}
+	}
+
+	if len(kept) == len(entries) {
+		return entries, nil
+	}
+
+	var buf bytes.Buffer
+	for _, e := range kept {
+		data, err := json.Marshal(toReflogEntryJSON(e))
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+
+	err = rl.fs.WriteFile(rl.path, buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	return kept, nil
+}
+
+// lock locks the reflog against the other users of this process and, unless the reflog is in memory, against other
+// processes. It returns a func which unlocks it.
+func (rl *Reflog) lock() (func(), error) {
+	rl.mu.Lock()
+	if _, ok := rl.fs.(*filesys.InMemFS); ok {
+		return rl.mu.Unlock, nil
+	}
+
+	lck := fslock.New(rl.path + ".lock")
+	err := lck.LockWithTimeout(reflogLockTimeout)
+	if err != nil {
+		rl.mu.Unlock()
+		return nil, fmt.Errorf("failed to lock the reflog: %w", err)
+	}
+
+	return func() {
+		_ = lck.Unlock()
+		rl.mu.Unlock()
+	}, nil
+}
+
+func (rl *Reflog) readEntries() ([]ReflogEntry, error) {
+	if exists, _ := rl.fs.Exists(rl.path); !exists {
+		return nil, nil
+	}
+
+	data, err := rl.fs.ReadFile(rl.path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []ReflogEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), len(data)+1)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var ej reflogEntryJSON
+		err = json.Unmarshal(line, &ej)
+		if err != nil {
+			// a partially written entry, e.g. from a process that was killed mid-write, is skipped
+			continue
+		}
+
+		entries = append(entries, fromReflogEntryJSON(ej))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	remaining := make(map[string]int)
+	for _, e := range entries {
+		remaining[e.Ref]++
+	}
+	for i := range entries {
+		remaining[entries[i].Ref]--
+		entries[i].Index = remaining[entries[i].Ref]
+	}
+
+	return entries, nil
+}
+
+func toReflogEntryJSON(e ReflogEntry) reflogEntryJSON {
+	ej := reflogEntryJSON{
+		Ref:       e.Ref,
+		Command:   e.Command,
+		Timestamp: e.Timestamp.UnixNano() / int64(time.Millisecond),
+		User:      e.User,
+	}
+
+	if !e.OldHash.IsEmpty() {
+		ej.OldHash = e.OldHash.String()
+	}
+	if !e.NewHash.IsEmpty() {
+		ej.NewHash = e.NewHash.String()
+	}
+
+	return ej
+}
+
+func fromReflogEntryJSON(ej reflogEntryJSON) ReflogEntry {
+	e := ReflogEntry{
+		Ref:       ej.Ref,
+		Command:   ej.Command,
+		Timestamp: time.Unix(0, ej.Timestamp*int64(time.Millisecond)),
+		User:      ej.User,
+	}
+
+	if h, ok := hash.MaybeParse(ej.OldHash); ok {
+		e.OldHash = h
+	}
+	if h, ok := hash.MaybeParse(ej.NewHash); ok {
+		e.NewHash = h
+	}
+
+	return e
+}
+
+type reflogInfoKey struct{}
+
+type reflogInfo struct {
+	command string
+	user    string
+}
+
+// WithReflogInfo returns a context that attributes the ref updates made with it to the command and user given in the
+// reflog. Ref updates made in the course of a SQL query are attributed to the query instead.
+func WithReflogInfo(ctx context.Context, command, user string) context.Context {
+	return context.WithValue(ctx, reflogInfoKey{}, reflogInfo{command: command, user: user})
+}
+
+// ContextReflogInfo returns the command and user that ref updates made with |ctx| are attributed to, for contexts
+// that carry them in a form this package does not know about, such as the context of a SQL query. Either may be empty
+// if the context does not carry it. It is set by the SQL engine.
+var ContextReflogInfo = func(ctx context.Context) (command, user string) {
+	return "", ""
+}
+
+func reflogInfoFromContext(ctx context.Context) reflogInfo {
+	info, _ := ctx.Value(reflogInfoKey{}).(reflogInfo)
+
+	command, user := ContextReflogInfo(ctx)
+	if len(command) > 0 {
+		info.command = command
+	}
+	if len(info.user) == 0 {
+		info.user = user
+	}
+
+	return info
+}
+
+// Reflog returns the reflog of this database, or nil if it does not keep one.
+func (ddb *DoltDB) Reflog() *Reflog {
+	return ddb.reflog
+}
+
+// logRefUpdate appends an entry recording the update of the dataset |before| to |after| to the reflog of this
+// database. Only updates to branches, tags and working sets are recorded. The update has already been committed, so a
+// failure to record it is logged as a warning rather than returned.
+func (ddb *DoltDB) logRefUpdate(ctx context.Context, before, after datas.Dataset) {
+	if ddb.reflog == nil || !isReflogged(before.ID()) {
+		return
+	}
+
+	err := ddb.appendReflogEntry(ctx, before, after)
+	if err != nil {
+		logrus.Warnf("failed to record the update of %s in the reflog: %v", before.ID(), err)
+	}
+}
+
+func (ddb *DoltDB) appendReflogEntry(ctx context.Context, before, after datas.Dataset) error {
+	oldHash, err := datasetHeadHash(before)
+	if err != nil {
+		return err
+	}
+
+	newHash, err := datasetHeadHash(after)
+	if err != nil {
+		return err
+	}
+
+	if oldHash == newHash {
+		return nil
+	}
+
+	info := reflogInfoFromContext(ctx)
+	return ddb.reflog.Append(ReflogEntry{
+		Ref:       before.ID(),
+		OldHash:   oldHash,
+		NewHash:   newHash,
+		Command:   truncateReflogCommand(info.command),
+		Timestamp: time.Now(),
+		User:      info.user,
+	})
+}
+
+// truncateReflogCommand truncates |command| to at most MaxReflogCommandLen bytes, so that a long query, along with any
+// data it holds, is not copied into the reflog in full.
+func truncateReflogCommand(command string) string {
+	if len(command) <= MaxReflogCommandLen {
+		return command
+	}
+
+	const ellipsis = "..."
+	end := MaxReflogCommandLen - len(ellipsis)
+	for end > 0 && !utf8.RuneStart(command[end]) {
+		end--
+	}
+
+	return command[:end] + ellipsis
+}
+
+func isReflogged(dsID string) bool {
+	if ref.IsWorkingSet(dsID) {
+		return true
+	}
+
+	if !ref.IsRef(dsID) {
+		return false
+	}
+
+	dref, err := ref.Parse(dsID)
+	if err != nil {
+		return false
+	}
+
+	return dref.GetType() == ref.BranchRefType || dref.GetType() == ref.TagRefType
+}
+
+func datasetHeadHash(ds datas.Dataset) (hash.Hash, error) {
+	r, ok, err := ds.MaybeHeadRef()
+	if err != nil || !ok {
+		return hash.Hash{}, err
+	}
+
+	return r.TargetHash(), nil
+}
@@ -0,0 +1,231 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doltdb
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+	"github.com/dolthub/dolt/go/store/chunks"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+func TestReflog(t *testing.T) {
+	fs := filesys.NewInMemFS([]string{"/repo/.dolt"}, nil, "/repo")
+	rl := NewReflog(fs, "/repo/.dolt/reflog")
+
+	entries, err := rl.Entries()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	h1 := hash.Of([]byte("one"))
+	h2 := hash.Of([]byte("two"))
+	h3 := hash.Of([]byte("three"))
+	now := time.Now()
+	old := now.Add(-2 * DefaultReflogMaxAge)
+
+	toAppend := []ReflogEntry{
+		{Ref: "refs/heads/main", NewHash: h1, Command: "dolt init", Timestamp: old, User: "a"},
+		{Ref: "refs/heads/main", OldHash: h1, NewHash: h2, Command: "dolt commit", Timestamp: now, User: "a"},
+		{Ref: "refs/heads/other", NewHash: h3, Command: "dolt branch other", Timestamp: now, User: "b"},
+		{Ref: "refs/heads/main", OldHash: h2, NewHash: h1, Command: "dolt reset --hard HEAD~1", Timestamp: now, User: "a"},
+	}
+	for _, e := range toAppend {
+		require.NoError(t, rl.Append(e))
+	}
+
+	entries, err = rl.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	assert.Equal(t, []int{2, 1, 0, 0}, []int{entries[0].Index, entries[1].Index, entries[2].Index, entries[3].Index})
+	assert.Equal(t, h1, entries[1].OldHash)
+	assert.Equal(t, h2, entries[1].NewHash)
+	assert.True(t, entries[0].OldHash.IsEmpty())
+	assert.Equal(t, "dolt commit", entries[1].Command)
+	assert.Equal(t, now.UnixNano()/int64(time.Millisecond), entries[1].Timestamp.UnixNano()/int64(time.Millisecond))
+
+	entries, err = rl.EntriesForRef("refs/heads/other")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, h3, entries[0].NewHash)
+
+	entries, err = rl.Expire(now)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+
+	entries, err = rl.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "dolt commit", entries[0].Command)
+	assert.Equal(t, 1, entries[0].Index)
+}
+
+func TestReflogWorkingSetInterval(t *testing.T) {
+	fs := filesys.NewInMemFS([]string{"/repo/.dolt"}, nil, "/repo")
+	rl := NewReflog(fs, "/repo/.dolt/reflog")
+
+	h1 := hash.Of([]byte("one"))
+	h2 := hash.Of([]byte("two"))
+	h3 := hash.Of([]byte("three"))
+	now := time.Now()
+
+	toAppend := []ReflogEntry{
+		{Ref: "workingSets/heads/main", NewHash: h1, Command: "dolt merge other", Timestamp: now, User: "a"},
+		// updates made by the same command and user within the interval are not recorded
+		{Ref: "workingSets/heads/main", OldHash: h1, NewHash: h2, Command: "dolt merge other", Timestamp: now.Add(WorkingSetReflogInterval / 2), User: "a"},
+		{Ref: "workingSets/heads/main", OldHash: h2, NewHash: h3, Command: "dolt merge other", Timestamp: now.Add(WorkingSetReflogInterval), User: "a"},
+		// updates to branches and tags are not limited
+		{Ref: "refs/heads/main", NewHash: h1, Command: "dolt merge other", Timestamp: now, User: "a"},
+		{Ref: "refs/heads/main", OldHash: h1, NewHash: h2, Command: "dolt merge other", Timestamp: now, User: "a"},
+		// and updates by other commands or users are always recorded
+		{Ref: "workingSets/heads/main", OldHash: h3, NewHash: h1, Command: "dolt reset --hard", Timestamp: now.Add(WorkingSetReflogInterval), User: "a"},
+		{Ref: "workingSets/heads/main", OldHash: h1, NewHash: h2, Command: "dolt reset --hard", Timestamp: now.Add(WorkingSetReflogInterval), User: "b"},
+	}
+	for _, e := range toAppend {
+		require.NoError(t, rl.Append(e))
+	}
+
+	entries, err := rl.EntriesForRef("workingSets/heads/main")
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	assert.Equal(t, h1, entries[0].NewHash)
+	assert.Equal(t, h2, entries[1].OldHash)
+	assert.Equal(t, h3, entries[1].NewHash)
+	assert.Equal(t, "a", entries[2].User)
+	assert.Equal(t, "b", entries[3].User)
+
+	entries, err = rl.EntriesForRef("refs/heads/main")
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestReflogInfoFromContext(t *testing.T) {
+	ctx := WithReflogInfo(context.Background(), "dolt sql", "a")
+	info := reflogInfoFromContext(ctx)
+	assert.Equal(t, reflogInfo{command: "dolt sql", user: "a"}, info)
+
+	defer func(f func(context.Context) (string, string)) {
+		ContextReflogInfo = f
+	}(ContextReflogInfo)
+	ContextReflogInfo = func(context.Context) (string, string) {
+		return "CALL DOLT_COMMIT('-m', 'x')", "root@localhost"
+	}
+
+	// the command comes from the context, but the user of the process takes precedence
+	info = reflogInfoFromContext(ctx)
+	assert.Equal(t, reflogInfo{command: "CALL DOLT_COMMIT('-m', 'x')", user: "a"}, info)
+
+	info = reflogInfoFromContext(context.Background())
+	assert.Equal(t, reflogInfo{command: "CALL DOLT_COMMIT('-m', 'x')", user: "root@localhost"}, info)
+}
+
+func TestLogRefUpdateFailure(t *testing.T) {
+	ctx := context.Background()
+	ddb := DoltDBFromCS((&chunks.MemoryStorage{}).NewViewWithDefaultFormat())
+
+	// the reflog is a directory, so entries cannot be appended to it
+	fs := filesys.NewInMemFS([]string{"/repo/.dolt/reflog"}, nil, "/repo")
+	ddb.reflog = NewReflog(fs, "/repo/.dolt/reflog")
+
+	require.NoError(t, ddb.WriteEmptyRepo(ctx, "main", "Bill Billerson", "bigbillieb@fake.horse"))
+
+	cs, err := NewCommitSpec("main")
+	require.NoError(t, err)
+	commit, err := ddb.Resolve(ctx, cs, nil)
+	require.NoError(t, err)
+
+	// the ref is updated even though its update cannot be recorded
+	require.NoError(t, ddb.NewBranchAtCommit(ctx, ref.NewBranchRef("other"), commit))
+	has, err := ddb.HasRef(ctx, ref.NewBranchRef("other"))
+	require.NoError(t, err)
+	assert.True(t, has)
+}
+
+func TestReflogLocking(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ReflogFile)
+
+	// two reflogs for the same file stand in for two processes, which only the lock file keeps apart
+	rl1 := NewReflog(filesys.LocalFS, path)
+	rl2 := NewReflog(filesys.LocalFS, path)
+
+	unlock, err := rl1.lock()
+	require.NoError(t, err)
+
+	appended := make(chan error)
+	go func() {
+		appended <- rl2.Append(ReflogEntry{Ref: "refs/heads/main", NewHash: hash.Of([]byte("one")), Timestamp: time.Now()})
+	}()
+
+	select {
+	case <-appended:
+		t.Fatal("appended to a locked reflog")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	unlock()
+	require.NoError(t, <-appended)
+
+	entries, err := rl1.Entries()
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestLogRefUpdate(t *testing.T) {
+	ctx := WithReflogInfo(context.Background(), "dolt branch "+strings.Repeat("x", MaxReflogCommandLen), "a")
+	ddb := DoltDBFromCS((&chunks.MemoryStorage{}).NewViewWithDefaultFormat())
+	fs := filesys.NewInMemFS([]string{"/repo/.dolt"}, nil, "/repo")
+	ddb.reflog = NewReflog(fs, "/repo/.dolt/reflog")
+
+	require.NoError(t, ddb.WriteEmptyRepo(ctx, "main", "Bill Billerson", "bigbillieb@fake.horse"))
+	cs, err := NewCommitSpec("main")
+	require.NoError(t, err)
+	commit, err := ddb.Resolve(ctx, cs, nil)
+	require.NoError(t, err)
+	require.NoError(t, ddb.NewBranchAtCommit(ctx, ref.NewBranchRef("other"), commit))
+
+	entries, err := ddb.Reflog().Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "refs/heads/main", entries[0].Ref)
+	assert.Equal(t, "refs/heads/other", entries[1].Ref)
+	assert.Equal(t, "workingSets/heads/other", entries[2].Ref)
+
+	assert.Len(t, entries[1].Command, MaxReflogCommandLen)
+	assert.True(t, strings.HasPrefix(entries[1].Command, "dolt branch xxx"))
+	assert.True(t, strings.HasSuffix(entries[1].Command, "..."))
+}
+
+func TestTruncateReflogCommand(t *testing.T) {
+	assert.Equal(t, "dolt commit -m msg", truncateReflogCommand("dolt commit -m msg"))
+
+	exact := strings.Repeat("a", MaxReflogCommandLen)
+	assert.Equal(t, exact, truncateReflogCommand(exact))
+
+	// multi-byte characters are not split
+	long := strings.Repeat("é", MaxReflogCommandLen)
+	truncated := truncateReflogCommand(long)
+	assert.LessOrEqual(t, len(truncated), MaxReflogCommandLen)
+	assert.True(t, utf8.ValidString(truncated))
+	assert.True(t, strings.HasSuffix(truncated, "é..."))
+}
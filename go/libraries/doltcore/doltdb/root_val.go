@@ -763,6 +763,26 @@ func (root *RootValue) HashOf() (hash.Hash, error) {
 	return root.valueSt.Hash(root.vrw.Format())
 }
 
+// RootsEqual returns whether all the roots given have the same hash.
+func RootsEqual(root *RootValue, others ...*RootValue) (bool, error) {
+	h, err := root.HashOf()
+	if err != nil {
+		return false, err
+	}
+
+	for _, other := range others {
+		oh, err := other.HashOf()
+		if err != nil {
+			return false, err
+		}
+		if h != oh {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
 // UpdateSuperSchemasFromOther updates SuperSchemas of tblNames using SuperSchemas from other.
 func (root *RootValue) UpdateSuperSchemasFromOther(ctx context.Context, tblNames []string, other *RootValue) (*RootValue, error) {
 	newRoot := root
@@ -0,0 +1,259 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doltdb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/dbfactory"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+// ShallowFile is the name of the file holding the shallow commits of a local database, within the dolt directory.
+const ShallowFile = "shallow"
+
+// ErrShallowHistory is returned when an operation needs history beyond the shallow commits of a database.
+var ErrShallowHistory = errors.New("the history needed is not available in this shallow clone; fetch more of it with dolt fetch --deepen")
+
+// shallowFile stores the shallow commits of a local database, one hash per line. The commits are not part of the
+// database itself, so they are neither pushed nor cloned.
+type shallowFile struct {
+	fs   filesys.Filesys
+	path string
+}
+
+func newLocalShallowFile(fs filesys.Filesys) (*shallowFile, error) {
+	path, err := fs.Abs(filepath.Join(dbfactory.DoltDir, ShallowFile))
+	if err != nil {
+		return nil, err
+	}
+
+	return &shallowFile{fs: fs, path: path}, nil
+}
+
+func (sf *shallowFile) read() (hash.HashSet, error) {
+	if exists, _ := sf.fs.Exists(sf.path); !exists {
+		return nil, nil
+	}
+
+	data, err := sf.fs.ReadFile(sf.path)
+	if err != nil {
+		return nil, err
+	}
+
+	commits := hash.NewHashSet()
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		h, ok := hash.MaybeParse(line)
+		if !ok {
+			return nil, fmt.Errorf("invalid commit hash in %s: %s", sf.path, line)
+		}
+		commits.Insert(h)
+	}
+
+	return commits, nil
+}
+
+func (sf *shallowFile) write(commits hash.HashSet) error {
+	if len(commits) == 0 {
+		if exists, _ := sf.fs.Exists(sf.path); !exists {
+			return nil
+		}
+		return sf.fs.DeleteFile(sf.path)
+	}
+
+	lines := make([]string, 0, len(commits))
+	for h := range commits {
+		lines = append(lines, h.String())
+	}
+	sort.Strings(lines)
+
+	var buf bytes.Buffer
+	for _, l := range lines {
+		buf.WriteString(l)
+		buf.WriteByte('\n')
+	}
+
+	return sf.fs.WriteFile(sf.path, buf.Bytes())
+}
+
+// IsShallow returns whether the database holds only part of the history of its commits, such as a database cloned
+// with a limited depth.
+func (ddb *DoltDB) IsShallow() bool {
+	return len(ddb.ShallowCommits()) > 0
+}
+
+// ShallowCommits returns the shallow commits of the database: the commits at the edge of the history it holds, whose
+// parents it does not hold. Shallow commits are treated as having no parents. The returned set must not be modified.
+func (ddb *DoltDB) ShallowCommits() hash.HashSet {
+	return datas.ShallowCommits(ddb.db)
+}
+
+func (ddb *DoltDB) setShallowCommits(ctx context.Context, commits hash.HashSet) error {
+	if ddb.shallow != nil {
+		err := ddb.shallow.write(commits)
+		if err != nil {
+			return err
+		}
+	}
+
+	return datas.SetShallowCommits(ctx, ddb.db, commits)
+}
+
+// PullChunksWithDepth pulls the chunks of |stRef| from |srcDB|, as PullChunks does, but pulls only the last |depth|
+// commits of its history when |depth| is positive. The commits at the edge of the history pulled become shallow commits
+// of the database. If the database is already shallow, only the history it is missing up to the commits it already
+// holds is pulled, however deep. If the database is a partial clone, the row data of the tables it does not hold is
+// not pulled.
+func (ddb *DoltDB) PullChunksWithDepth(ctx context.Context, tempDir string, srcDB *DoltDB, stRef types.Ref, depth int, progChan chan datas.PullProgress, pullerEventCh chan datas.PullerEvent) error {
+	if depth <= 0 && !ddb.IsShallow() && !srcDB.IsShallow() && !ddb.IsPartial() {
+		return ddb.pullChunks(ctx, tempDir, srcDB, stRef, nil, progChan, pullerEventCh)
+	}
+
+	heads, err := commitsOf(ctx, srcDB, stRef)
+	if err != nil {
+		return err
+	}
+
+	plan, err := datas.PlanShallowPull(ctx, srcDB.db, ddb.db, heads, depth)
+	if err != nil {
+		return err
+	}
+
+	return ddb.pullShallow(ctx, tempDir, srcDB, []types.Ref{stRef}, plan, progChan, pullerEventCh)
+}
+
+// DeepenShallow pulls |depth| more commits of history from |srcDB| past the shallow commits of the database.
+func (ddb *DoltDB) DeepenShallow(ctx context.Context, tempDir string, srcDB *DoltDB, depth int, progChan chan datas.PullProgress, pullerEventCh chan datas.PullerEvent) error {
+	heads := hash.NewHashSet()
+	for h := range ddb.ShallowCommits() {
+		parents, err := datas.CommitParents(ctx, ddb.db, h)
+		if err != nil {
+			return err
+		}
+		for _, p := range parents {
+			heads.Insert(p)
+		}
+	}
+
+	if len(heads) == 0 {
+		return nil
+	}
+
+	plan, err := datas.PlanShallowPull(ctx, srcDB.db, ddb.db, heads, depth)
+	if err != nil {
+		return err
+	}
+
+	var roots []types.Ref
+	for h := range plan.Commits {
+		if !heads.Has(h) {
+			continue
+		}
+
+		v, err := srcDB.db.ReadValue(ctx, h)
+		if err != nil {
+			return err
+		} else if v == nil {
+			return fmt.Errorf("commit %s not found in the remote database", h.String())
+		}
+
+		r, err := types.NewRef(v, srcDB.Format())
+		if err != nil {
+			return err
+		}
+		roots = append(roots, r)
+	}
+
+	return ddb.pullShallow(ctx, tempDir, srcDB, roots, plan, progChan, pullerEventCh)
+}
+
+// pullShallow pulls the chunks of |roots| from |srcDB| without the history |plan| leaves out, or the row data a partial
+// clone does not hold, and then updates the shallow commits of the database.
+func (ddb *DoltDB) pullShallow(ctx context.Context, tempDir string, srcDB *DoltDB, roots []types.Ref, plan datas.ShallowPull, progChan chan datas.PullProgress, pullerEventCh chan datas.PullerEvent) error {
+	excluded, err := datas.ExcludedFromShallowPull(ctx, srcDB.db, ddb.db, plan)
+	if err != nil {
+		return err
+	}
+
+	if ddb.IsPartial() {
+		rowData, err := ddb.partialCloneExcluded(ctx, srcDB, plan.Commits)
+		if err != nil {
+			return err
+		}
+		excluded.InsertAll(rowData)
+	}
+
+	for _, r := range roots {
+		err = ddb.pullChunks(ctx, tempDir, srcDB, r, excluded, progChan, pullerEventCh)
+		if err != nil && !errors.Is(err, datas.ErrDBUpToDate) {
+			return err
+		}
+	}
+
+	shallow, err := datas.ShallowCommitsAfterPull(ctx, ddb.db, plan)
+	if err != nil {
+		return err
+	}
+
+	return ddb.setShallowCommits(ctx, shallow)
+}
+
+// commitsOf returns the commit |stRef| refers to, or the commits referenced by the value |stRef| refers to, such as
+// the commit of a tag.
+func commitsOf(ctx context.Context, srcDB *DoltDB, stRef types.Ref) (hash.HashSet, error) {
+	v, err := stRef.TargetValue(ctx, srcDB.db)
+	if err != nil {
+		return nil, err
+	} else if v == nil {
+		return nil, fmt.Errorf("%s not found in the remote database", stRef.TargetHash().String())
+	}
+
+	if ok, err := datas.IsCommit(v); err != nil {
+		return nil, err
+	} else if ok {
+		return hash.NewHashSet(stRef.TargetHash()), nil
+	}
+
+	commits := hash.NewHashSet()
+	err = v.WalkRefs(srcDB.Format(), func(r types.Ref) error {
+		t, err := types.TypeOf(r)
+		if err != nil {
+			return err
+		}
+		if datas.IsRefOfCommitType(srcDB.Format(), t) {
+			commits.Insert(r.TargetHash())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return commits, nil
+}
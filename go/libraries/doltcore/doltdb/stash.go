@@ -0,0 +1,237 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doltdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+var ErrStashNotFound = errors.New("stash not found")
+var ErrNoStashes = errors.New("no stash entries found")
+var ErrInvalidStashName = errors.New("not a valid stash name")
+
+var stashNameRegex = regexp.MustCompile(`\A(?:stash@\{([0-9]+)\}|([0-9]+))\z`)
+
+var stashRefFilter = map[ref.RefType]struct{}{ref.StashRefType: {}}
+
+// Stash is a set of working and staged changes that have been shelved. A stash is stored as a commit under a
+// ref.StashRef. The root value of that commit is the stashed working root, and its parents are the HEAD commit the
+// stash was created on, followed by a commit whose root value is the stashed staged root.
+type Stash struct {
+	// Ref is the ref the stash is stored under
+	Ref ref.StashRef
+	// Index is the position of the stash in the stash list, with 0 being the most recent stash
+	Index int
+
+	commit     *Commit
+	headCommit *Commit
+	stagedRoot *RootValue
+}
+
+// Name returns the name of the stash as it is displayed to users, e.g. stash@{0}
+func (s *Stash) Name() string {
+	return fmt.Sprintf("stash@{%d}", s.Index)
+}
+
+// HashOf returns the hash of the commit holding this stash.
+func (s *Stash) HashOf() (hash.Hash, error) {
+	return s.commit.HashOf()
+}
+
+// GetCommitMeta returns the metadata recorded when the stash was created.
+func (s *Stash) GetCommitMeta() (*CommitMeta, error) {
+	return s.commit.GetCommitMeta()
+}
+
+// HeadCommit returns the commit that was HEAD when the stash was created.
+func (s *Stash) HeadCommit() *Commit {
+	return s.headCommit
+}
+
+// WorkingRoot returns the stashed working root.
+func (s *Stash) WorkingRoot() (*RootValue, error) {
+	return s.commit.GetRootValue()
+}
+
+// StagedRoot returns the stashed staged root.
+func (s *Stash) StagedRoot() *RootValue {
+	return s.stagedRoot
+}
+
+// ParseStashIndex parses a user supplied stash name, either in the form stash@{n} or just n, returning the index of
+// the stash in the stash list.
+func ParseStashIndex(name string) (int, error) {
+	matches := stashNameRegex.FindStringSubmatch(name)
+	if matches == nil {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidStashName, name)
+	}
+
+	if len(matches[1]) > 0 {
+		return strconv.Atoi(matches[1])
+	}
+
+	return strconv.Atoi(matches[2])
+}
+
+// NewStash stores the working and staged roots given as a new stash on top of the stash list. |headCommit| is the
+// commit the changes were made on top of, and is used as the base when the stash is later applied.
+func (ddb *DoltDB) NewStash(ctx context.Context, headCommit *Commit, workingRoot, stagedRoot *RootValue, meta *CommitMeta) (*Stash, error) {
+	stagedHash, err := ddb.WriteRootValue(ctx, stagedRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	stagedCommit, err := ddb.CommitDanglingWithParentCommits(ctx, stagedHash, []*Commit{headCommit}, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	workingHash, err := ddb.WriteRootValue(ctx, workingRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	stashCommit, err := ddb.CommitDanglingWithParentCommits(ctx, workingHash, []*Commit{headCommit, stagedCommit}, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	stashRefs, err := ddb.getStashRefs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	next := 0
+	if len(stashRefs) > 0 {
+		next = stashRefs[0].id + 1
+	}
+
+	stashRef := ref.NewStashRef(strconv.Itoa(next))
+	err = ddb.SetHeadToCommit(ctx, stashRef, stashCommit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Stash{Ref: stashRef, Index: 0, commit: stashCommit, headCommit: headCommit, stagedRoot: stagedRoot}, nil
+}
+
+// GetStashes returns all the stashes in this database, ordered from most recent to least recent.
+func (ddb *DoltDB) GetStashes(ctx context.Context) ([]*Stash, error) {
+	stashRefs, err := ddb.getStashRefs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stashes := make([]*Stash, len(stashRefs))
+	for i, sr := range stashRefs {
+		stashes[i], err = ddb.resolveStash(ctx, sr.ref, i)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return stashes, nil
+}
+
+// GetStash returns the stash at the index given in the stash list, where 0 is the most recent stash.
+func (ddb *DoltDB) GetStash(ctx context.Context, idx int) (*Stash, error) {
+	stashRefs, err := ddb.getStashRefs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(stashRefs) == 0 {
+		return nil, ErrNoStashes
+	} else if idx < 0 || idx >= len(stashRefs) {
+		return nil, fmt.Errorf("%w: stash@{%d}", ErrStashNotFound, idx)
+	}
+
+	return ddb.resolveStash(ctx, stashRefs[idx].ref, idx)
+}
+
+// DropStash removes the stash given from the stash list.
+func (ddb *DoltDB) DropStash(ctx context.Context, stash *Stash) error {
+	err := ddb.deleteRef(ctx, stash.Ref)
+
+	if err == ErrBranchNotFound {
+		return ErrStashNotFound
+	}
+
+	return err
+}
+
+func (ddb *DoltDB) resolveStash(ctx context.Context, stashRef ref.StashRef, idx int) (*Stash, error) {
+	commit, err := ddb.ResolveCommitRef(ctx, stashRef)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(commit.ParentRefs()) != 2 {
+		return nil, fmt.Errorf("stash %s is not a valid stash commit", stashRef.String())
+	}
+
+	headCommit, err := ddb.ResolveParent(ctx, commit, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	stagedCommit, err := ddb.ResolveParent(ctx, commit, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	stagedRoot, err := stagedCommit.GetRootValue()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Stash{Ref: stashRef, Index: idx, commit: commit, headCommit: headCommit, stagedRoot: stagedRoot}, nil
+}
+
+type stashRefWithId struct {
+	ref ref.StashRef
+	id  int
+}
+
+// getStashRefs returns the stash refs in this database, ordered from most recent to least recent.
+func (ddb *DoltDB) getStashRefs(ctx context.Context) ([]stashRefWithId, error) {
+	refs, err := ddb.GetRefsOfType(ctx, stashRefFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	stashRefs := make([]stashRefWithId, len(refs))
+	for i, r := range refs {
+		id, err := strconv.Atoi(r.GetPath())
+		if err != nil {
+			return nil, fmt.Errorf("invalid stash ref %s: %w", r.String(), err)
+		}
+		stashRefs[i] = stashRefWithId{ref: r.(ref.StashRef), id: id}
+	}
+
+	sort.Slice(stashRefs, func(i, j int) bool {
+		return stashRefs[i].id > stashRefs[j].id
+	})
+
+	return stashRefs, nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doltdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStashIndex(t *testing.T) {
+	tests := []struct {
+		name      string
+		expected  int
+		expectErr bool
+	}{
+		{"stash@{0}", 0, false},
+		{"stash@{12}", 12, false},
+		{"0", 0, false},
+		{"3", 3, false},
+		{"", 0, true},
+		{"stash", 0, true},
+		{"stash@{}", 0, true},
+		{"stash@{0", 0, true},
+		{"stash@0}", 0, true},
+		{"0}", 0, true},
+		{"{0}", 0, true},
+		{"stash@{-1}", 0, true},
+		{"stash@{0}}", 0, true},
+		{" stash@{0}", 0, true},
+		{"1a", 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			idx, err := ParseStashIndex(test.name)
+			if test.expectErr {
+				assert.ErrorIs(t, err, ErrInvalidStashName)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, idx)
+		})
+	}
+}
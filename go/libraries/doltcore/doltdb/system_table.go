@@ -121,6 +121,7 @@ var writeableSystemTables = []string{
 	DoltQueryCatalogTableName,
 	SchemasTableName,
 	ProceduresTableName,
+	MergeStrategiesTableName,
 }
 
 var persistedSystemTables = []string{
@@ -128,6 +129,7 @@ var persistedSystemTables = []string{
 	DoltQueryCatalogTableName,
 	SchemasTableName,
 	ProceduresTableName,
+	MergeStrategiesTableName,
 }
 
 var generatedSystemTables = []string{
@@ -139,6 +141,13 @@ var generatedSystemTables = []string{
 	CommitAncestorsTableName,
 	StatusTableName,
 	RemotesTableName,
+	StashesTableName,
+	ReflogTableName,
+	SchemaDiffTableName,
+	BranchControlTableName,
+	TagsTableName,
+	RemoteBranchesTableName,
+	ColumnDiffTableName,
 }
 
 var generatedSystemTablePrefixes = []string{
@@ -147,6 +156,7 @@ var generatedSystemTablePrefixes = []string{
 	DoltHistoryTablePrefix,
 	DoltConfTablePrefix,
 	DoltConstViolTablePrefix,
+	DoltBlameTablePrefix,
 }
 
 const (
@@ -205,6 +215,8 @@ const (
 	DoltConfTablePrefix = "dolt_conflicts_"
 	// DoltConstViolTablePrefix is the prefix assigned to all the generated constraint violation tables
 	DoltConstViolTablePrefix = "dolt_constraint_violations_"
+	// DoltBlameTablePrefix is the prefix assigned to all the generated blame tables
+	DoltBlameTablePrefix = "dolt_blame_"
 )
 
 const (
@@ -231,6 +243,27 @@ const (
 
 	// StatusTableName is the status system table name.
 	StatusTableName = "dolt_status"
+
+	// StashesTableName is the stashes system table name.
+	StashesTableName = "dolt_stashes"
+
+	// ReflogTableName is the reflog system table name.
+	ReflogTableName = "dolt_reflog"
+
+	// SchemaDiffTableName is the schema diff system table name.
+	SchemaDiffTableName = "dolt_schema_diff"
+
+	// BranchControlTableName is the system table name of the rules for which branches accounts may write to.
+	BranchControlTableName = "dolt_branch_control"
+
+	// TagsTableName is the tags system table name.
+	TagsTableName = "dolt_tags"
+
+	// RemoteBranchesTableName is the remote-tracking branches system table name.
+	RemoteBranchesTableName = "dolt_remote_branches"
+
+	// ColumnDiffTableName is the column diff system table name.
+	ColumnDiffTableName = "dolt_column_diff"
 )
 
 const (
@@ -245,3 +278,18 @@ const (
 	// ProceduresTableModifiedAtCol is the time that the stored procedure was last modified, in UTC.
 	ProceduresTableModifiedAtCol = "modified_at"
 )
+
+const (
+	// MergeStrategiesTableName is the name of the table which configures how columns are merged when a row is
+	// modified on both sides of a merge.
+	MergeStrategiesTableName = "dolt_merge_strategies"
+	// MergeStrategiesTableNameCol is the name of the table a merge strategy applies to.
+	MergeStrategiesTableNameCol = "table_name"
+	// MergeStrategiesColumnNameCol is the name of the column a merge strategy applies to.
+	MergeStrategiesColumnNameCol = "column_name"
+	// MergeStrategiesStrategyCol is the strategy used to merge the column.
+	MergeStrategiesStrategyCol = "strategy"
+	// MergeStrategiesTimestampColumnCol is the name of the column whose values decide which side of the merge wins
+	// for the latest-timestamp strategy.
+	MergeStrategiesTimestampColumnCol = "timestamp_column"
+)
@@ -225,6 +225,22 @@ func (t *Table) HashOf() (hash.Hash, error) {
 	return t.table.HashOf()
 }
 
+// rowDataHashes returns the addresses of the row data of the table, and of the index set holding the row data of its
+// secondary indexes.
+func (t *Table) rowDataHashes() (hash.HashSet, error) {
+	refs, err := durable.RowDataRefs(t.table)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := hash.NewHashSet()
+	for _, r := range refs {
+		hashes.Insert(r.TargetHash())
+	}
+
+	return hashes, nil
+}
+
 // UpdateNomsRows replaces the current row data and returns and updated Table.
 // Calls to UpdateNomsRows will not be written to the database.  The root must
 // be updated with the updated table, and the root must be committed or written.
@@ -121,10 +121,10 @@ func (tm *TagMeta) toNomsStruct(nbf *types.NomsBinFormat) (types.Struct, error)
 	return types.NewStruct(nbf, tagMetaStName, metadata)
 }
 
-// Time returns the time at which the tag occurred
+// Time returns the time at which the tag occurred, which is the time given when it was created, if any
 func (tm *TagMeta) Time() time.Time {
-	seconds := int64(tm.Timestamp) / secToMilli
-	nanos := (int64(tm.Timestamp) % secToMilli) * milliToNano
+	seconds := tm.UserTimestamp / secToMilli
+	nanos := (tm.UserTimestamp % secToMilli) * milliToNano
 	return time.Unix(seconds, nanos)
 }
 
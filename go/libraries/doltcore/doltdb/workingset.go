@@ -28,6 +28,40 @@ import (
 type MergeState struct {
 	commit          *Commit
 	preMergeWorking *RootValue
+	rebase          *RebaseState
+}
+
+// RebaseState records the progress of a rebase that has stopped partway through, so that it can be resumed or
+// aborted. A rebase in progress is stored as part of the merge state of the working set, where the merge commit is the
+// commit being replayed.
+type RebaseState struct {
+	onto     *Commit
+	origHead *Commit
+	action   string
+	todo     string
+}
+
+// NewRebaseState returns a new RebaseState. |onto| is the commit the branch is being rebased onto, |origHead| is the
+// head of the branch before the rebase started, |action| is the rebase action being applied for the commit being
+// replayed, and |todo| is the remaining rebase plan.
+func NewRebaseState(onto, origHead *Commit, action, todo string) *RebaseState {
+	return &RebaseState{onto: onto, origHead: origHead, action: action, todo: todo}
+}
+
+func (rs RebaseState) Onto() *Commit {
+	return rs.onto
+}
+
+func (rs RebaseState) OrigHead() *Commit {
+	return rs.origHead
+}
+
+func (rs RebaseState) Action() string {
+	return rs.action
+}
+
+func (rs RebaseState) Todo() string {
+	return rs.todo
 }
 
 // WorkingSetMeta contains all the metadata that is associated with a working set
@@ -90,9 +124,45 @@ func newMergeState(ctx context.Context, vrw types.ValueReadWriter, mergeState ty
 		return nil, err
 	}
 
+	rebase, err := newRebaseState(vrw, mergeState)
+	if err != nil {
+		return nil, err
+	}
+
 	return &MergeState{
 		commit:          commit,
 		preMergeWorking: workingRoot,
+		rebase:          rebase,
+	}, nil
+}
+
+// newRebaseState returns the RebaseState stored in the merge state given, or nil if the merge state is not for a rebase
+func newRebaseState(vrw types.ValueReadWriter, mergeState types.Struct) (*RebaseState, error) {
+	ontoSt, ok, err := mergeState.MaybeGet(datas.MergeStateRebaseOntoField)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	var vals []types.Value
+	for _, field := range []string{datas.MergeStateRebaseOrigHeadField, datas.MergeStateRebaseActionField, datas.MergeStateRebaseTodoField} {
+		val, ok, err := mergeState.MaybeGet(field)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("corrupted MergeState struct")
+		}
+		vals = append(vals, val)
+	}
+
+	return &RebaseState{
+		onto:     NewCommit(vrw, ontoSt.(types.Struct)),
+		origHead: NewCommit(vrw, vals[0].(types.Struct)),
+		action:   string(vals[1].(types.String)),
+		todo:     string(vals[2].(types.String)),
 	}, nil
 }
 
@@ -104,6 +174,11 @@ func (m MergeState) PreMergeWorkingRoot() *RootValue {
 	return m.preMergeWorking
 }
 
+// RebaseState returns the state of the rebase in progress, or nil if this merge state is not for a rebase
+func (m MergeState) RebaseState() *RebaseState {
+	return m.rebase
+}
+
 type WorkingSet struct {
 	Name        string
 	meta        WorkingSetMeta
@@ -146,6 +221,18 @@ func (ws WorkingSet) StartMerge(commit *Commit) *WorkingSet {
 	return &ws
 }
 
+// StartRebase returns a copy of this working set with a merge state recording that the rebase given stopped while
+// replaying |commit|. |preRebaseWorking| is the working root before the rebase started.
+func (ws WorkingSet) StartRebase(commit *Commit, preRebaseWorking *RootValue, rebase *RebaseState) *WorkingSet {
+	ws.mergeState = &MergeState{
+		commit:          commit,
+		preMergeWorking: preRebaseWorking,
+		rebase:          rebase,
+	}
+
+	return &ws
+}
+
 func (ws WorkingSet) AbortMerge() *WorkingSet {
 	ws.workingRoot = ws.mergeState.PreMergeWorkingRoot()
 	ws.stagedRoot = ws.workingRoot
@@ -174,6 +261,11 @@ func (ws *WorkingSet) MergeActive() bool {
 	return ws.mergeState != nil
 }
 
+// RebaseActive returns whether a rebase is in progress, in which case MergeActive also returns true
+func (ws *WorkingSet) RebaseActive() bool {
+	return ws.mergeState != nil && ws.mergeState.rebase != nil
+}
+
 func (ws WorkingSet) Meta() WorkingSetMeta {
 	return ws.meta
 }
@@ -362,7 +454,12 @@ func (ws *WorkingSet) writeValues(ctx context.Context, db *DoltDB) (
 			return types.Ref{}, types.Ref{}, nil, err
 		}
 
-		mergeStateRefSt, err := datas.NewMergeState(ctx, preMergeWorking, ws.mergeState.commit.commitSt)
+		var mergeStateRefSt types.Struct
+		if rs := ws.mergeState.rebase; rs != nil {
+			mergeStateRefSt, err = datas.NewRebaseMergeState(ctx, preMergeWorking, ws.mergeState.commit.commitSt, rs.onto.commitSt, rs.origHead.commitSt, rs.action, rs.todo)
+		} else {
+			mergeStateRefSt, err = datas.NewMergeState(ctx, preMergeWorking, ws.mergeState.commit.commitSt)
+		}
 		if err != nil {
 			return types.Ref{}, types.Ref{}, nil, err
 		}
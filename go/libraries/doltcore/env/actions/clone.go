@@ -123,20 +123,29 @@ func cloneProg(eventCh <-chan datas.TableFileEvent) {
 	cli.Println()
 }
 
-func CloneRemote(ctx context.Context, srcDB *doltdb.DoltDB, remoteName, branch string, dEnv *env.DoltEnv) error {
-	eventCh := make(chan datas.TableFileEvent, 128)
-
-	wg := &sync.WaitGroup{}
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		cloneProg(eventCh)
-	}()
-
-	err := Clone(ctx, srcDB, dEnv.DoltDB, eventCh)
-	close(eventCh)
-
-	wg.Wait()
+// CloneRemote clones |srcDB| into |dEnv|, checking out |branch|, or the default branch of |srcDB| if |branch| is empty.
+// If |singleBranch| is set, or |depth| is positive, only |branch| is cloned, and only the last |depth| commits of its
+// history are cloned if |depth| is positive. If |tables| are given, |dEnv| becomes a partial clone holding the row
+// data of only those tables, which fetches the row data of the others from the remote |remoteName| when it is read.
+func CloneRemote(ctx context.Context, srcDB *doltdb.DoltDB, remoteName, branch string, singleBranch bool, depth int, tables []string, dEnv *env.DoltEnv) error {
+	var err error
+	if singleBranch || depth > 0 || len(tables) > 0 {
+		branch, err = cloneBranches(ctx, srcDB, remoteName, branch, singleBranch || depth > 0, depth, tables, dEnv)
+	} else {
+		eventCh := make(chan datas.TableFileEvent, 128)
+
+		wg := &sync.WaitGroup{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cloneProg(eventCh)
+		}()
+
+		err = Clone(ctx, srcDB, dEnv.DoltDB, eventCh)
+		close(eventCh)
+
+		wg.Wait()
+	}
 
 	if err != nil {
 		if err == datas.ErrNoData {
@@ -235,6 +244,140 @@ func CloneRemote(ctx context.Context, srcDB *doltdb.DoltDB, remoteName, branch s
 	return nil
 }
 
+// cloneBranches pulls the branch |branch| of |srcDB|, or its default branch if |branch| is empty, into |dEnv|, along
+// with the other branches and tags of |srcDB| unless |singleBranch| is set. Only the last |depth| commits of their
+// history are pulled if |depth| is positive, and only the row data of |tables| if any are given. It returns the name of
+// the branch to check out.
+func cloneBranches(ctx context.Context, srcDB *doltdb.DoltDB, remoteName, branch string, singleBranch bool, depth int, tables []string, dEnv *env.DoltEnv) (string, error) {
+	branches, err := srcDB.GetBranches(ctx)
+	if err != nil {
+		return "", err
+	} else if len(branches) == 0 {
+		return "", datas.ErrNoData
+	}
+
+	if branch == "" {
+		branch = env.GetDefaultBranch(dEnv, branches)
+	}
+
+	cs, err := doltdb.NewCommitSpec(branch)
+	if err != nil {
+		return "", err
+	}
+
+	cm, err := srcDB.Resolve(ctx, cs, nil)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s; %s", ErrFailedToGetBranch, branch, err.Error())
+	}
+
+	if len(tables) > 0 {
+		err = validateCloneTables(ctx, cm, branch, tables)
+		if err != nil {
+			return "", err
+		}
+
+		pc := doltdb.PartialClone{Remote: remoteName, Tables: tables}
+		err = dEnv.DoltDB.SetPartialClone(pc, func(context.Context) (*doltdb.DoltDB, error) {
+			return srcDB, nil
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	tempTablesDir := dEnv.TempTableFilesDir()
+	err = dEnv.FS.MkDirs(tempTablesDir)
+	if err != nil {
+		return "", err
+	}
+
+	cli.Println("Retrieving remote information.")
+
+	toClone := []ref.DoltRef{ref.NewBranchRef(branch)}
+	if !singleBranch {
+		toClone = branches
+	}
+
+	for _, br := range toClone {
+		cs, err := doltdb.NewCommitSpec(br.GetPath())
+		if err != nil {
+			return "", err
+		}
+
+		cm, err := srcDB.Resolve(ctx, cs, nil)
+		if err != nil {
+			return "", fmt.Errorf("%w: %s; %s", ErrFailedToGetBranch, br.GetPath(), err.Error())
+		}
+
+		stRef, err := cm.GetStRef()
+		if err != nil {
+			return "", err
+		}
+
+		err = dEnv.DoltDB.PullChunksWithDepth(ctx, tempTablesDir, srcDB, stRef, depth, nil, nil)
+		if err != nil && err != datas.ErrDBUpToDate {
+			return "", err
+		}
+
+		cs, err = doltdb.NewCommitSpec(stRef.TargetHash().String())
+		if err != nil {
+			return "", err
+		}
+
+		cm, err = dEnv.DoltDB.Resolve(ctx, cs, nil)
+		if err != nil {
+			return "", err
+		}
+
+		err = dEnv.DoltDB.SetHeadToCommit(ctx, ref.NewBranchRef(br.GetPath()), cm)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if singleBranch {
+		return branch, nil
+	}
+
+	err = IterResolvedTags(ctx, srcDB, func(tag *doltdb.Tag) (stop bool, err error) {
+		stRef, err := tag.GetStRef()
+		if err != nil {
+			return true, err
+		}
+
+		err = dEnv.DoltDB.PullChunksWithDepth(ctx, tempTablesDir, srcDB, stRef, depth, nil, nil)
+		if err != nil && err != datas.ErrDBUpToDate {
+			return true, err
+		}
+
+		return false, dEnv.DoltDB.SetHead(ctx, tag.GetDoltRef(), stRef)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return branch, nil
+}
+
+// validateCloneTables returns an error if any of |tables| is not a table of |cm|, the head of the branch |branch|.
+func validateCloneTables(ctx context.Context, cm *doltdb.Commit, branch string, tables []string) error {
+	root, err := cm.GetRootValue()
+	if err != nil {
+		return fmt.Errorf("%w: %s; %s", ErrFailedToGetRootValue, branch, err.Error())
+	}
+
+	for _, name := range tables {
+		_, _, ok, err := root.GetTableInsensitive(ctx, name)
+		if err != nil {
+			return err
+		} else if !ok {
+			return fmt.Errorf("table '%s' not found on branch '%s'", name, branch)
+		}
+	}
+
+	return nil
+}
+
 // Inits an empty, newly cloned repo. This would be unnecessary if we properly initialized the storage for a repository
 // when we created it on dolthub. If we do that, this code can be removed.
 func InitEmptyClonedRepo(ctx context.Context, dEnv *env.DoltEnv) error {
@@ -338,6 +338,12 @@ func FetchFollowTags(ctx context.Context, tempTableDir string, srcDB, destDB *do
 }
 
 func FetchRemoteBranch(ctx context.Context, tempTablesDir string, rem env.Remote, srcDB, destDB *doltdb.DoltDB, srcRef, destRef ref.DoltRef, progStarter ProgStarter, progStopper ProgStopper) (*doltdb.Commit, error) {
+	return fetchRemoteBranch(ctx, tempTablesDir, rem, srcDB, destDB, srcRef, 0, progStarter, progStopper)
+}
+
+// fetchRemoteBranch fetches the branch |srcRef| of |srcDB|, along with the last |depth| commits of its history if
+// |depth| is positive, or all of the history |destDB| is missing otherwise.
+func fetchRemoteBranch(ctx context.Context, tempTablesDir string, rem env.Remote, srcDB, destDB *doltdb.DoltDB, srcRef ref.DoltRef, depth int, progStarter ProgStarter, progStopper ProgStopper) (*doltdb.Commit, error) {
 	evt := events.GetEventFromContext(ctx)
 
 	u, err := earl.Parse(rem.Url)
@@ -355,9 +361,14 @@ func FetchRemoteBranch(ctx context.Context, tempTablesDir string, rem env.Remote
 		return nil, fmt.Errorf("unable to find '%s' on '%s'; %w", srcRef.GetPath(), rem.Name, err)
 	}
 
+	stRef, err := srcDBCommit.GetStRef()
+	if err != nil {
+		return nil, err
+	}
+
 	newCtx, cancelFunc := context.WithCancel(ctx)
 	wg, progChan, pullerEventCh := progStarter(newCtx)
-	err = FetchCommit(ctx, tempTablesDir, srcDB, destDB, srcDBCommit, progChan, pullerEventCh)
+	err = destDB.PullChunksWithDepth(ctx, tempTablesDir, srcDB, stRef, depth, progChan, pullerEventCh)
 	progStopper(cancelFunc, wg, progChan, pullerEventCh)
 	if err == nil {
 		cli.Println()
@@ -372,8 +383,10 @@ func FetchRemoteBranch(ctx context.Context, tempTablesDir string, rem env.Remote
 	return srcDBCommit, nil
 }
 
-// FetchRefSpecs is the common SQL and CLI entrypoint for fetching branches, tags, and heads from a remote.
-func FetchRefSpecs(ctx context.Context, dbData env.DbData, refSpecs []ref.RemoteRefSpec, remote env.Remote, mode ref.UpdateMode, progStarter ProgStarter, progStopper ProgStopper) error {
+// FetchRefSpecs is the common SQL and CLI entrypoint for fetching branches, tags, and heads from a remote. If |depth|
+// is positive, only the last |depth| commits of the history of each branch are fetched, and the database becomes a
+// shallow clone.
+func FetchRefSpecs(ctx context.Context, dbData env.DbData, refSpecs []ref.RemoteRefSpec, remote env.Remote, mode ref.UpdateMode, depth int, progStarter ProgStarter, progStopper ProgStopper) error {
 	srcDB, err := remote.GetRemoteDBWithoutCaching(ctx, dbData.Ddb.ValueReadWriter().Format())
 	if err != nil {
 		return err
@@ -392,7 +405,7 @@ func FetchRefSpecs(ctx context.Context, dbData env.DbData, refSpecs []ref.Remote
 
 			if remoteTrackRef != nil {
 				rsSeen = true
-				srcDBCommit, err := FetchRemoteBranch(ctx, dbData.Rsw.TempTableFilesDir(), remote, srcDB, dbData.Ddb, branchRef, remoteTrackRef, progStarter, progStopper)
+				srcDBCommit, err := fetchRemoteBranch(ctx, dbData.Rsw.TempTableFilesDir(), remote, srcDB, dbData.Ddb, branchRef, depth, progStarter, progStopper)
 				if err != nil {
 					return err
 				}
@@ -440,6 +453,29 @@ func FetchRefSpecs(ctx context.Context, dbData env.DbData, refSpecs []ref.Remote
 	return nil
 }
 
+// DeepenShallowClone fetches |depth| more commits of history from |remote| past the shallow commits of a shallow
+// clone.
+func DeepenShallowClone(ctx context.Context, dbData env.DbData, remote env.Remote, depth int, progStarter ProgStarter, progStopper ProgStopper) error {
+	if !dbData.Ddb.IsShallow() {
+		return nil
+	}
+
+	srcDB, err := remote.GetRemoteDBWithoutCaching(ctx, dbData.Ddb.ValueReadWriter().Format())
+	if err != nil {
+		return err
+	}
+
+	newCtx, cancelFunc := context.WithCancel(ctx)
+	wg, progChan, pullerEventCh := progStarter(newCtx)
+	err = dbData.Ddb.DeepenShallow(ctx, dbData.Rsw.TempTableFilesDir(), srcDB, depth, progChan, pullerEventCh)
+	progStopper(cancelFunc, wg, progChan, pullerEventCh)
+	if err == nil {
+		cli.Println()
+	}
+
+	return err
+}
+
 // SyncRoots copies the entire chunkstore from srcDb to destDb and rewrites the remote manifest. Used to
 // streamline database backup and restores.
 // TODO: this should read/write a backup lock file specific to the client who created the backup
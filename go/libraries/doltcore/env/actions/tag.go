@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
 	"github.com/dolthub/dolt/go/libraries/doltcore/env"
@@ -28,6 +29,8 @@ type TagProps struct {
 	TaggerName  string
 	TaggerEmail string
 	Description string
+	// Date is the time the tag is recorded as created at. The current time is used when it is zero.
+	Date time.Time
 }
 
 func CreateTag(ctx context.Context, dEnv *env.DoltEnv, tagName, startPoint string, props TagProps) error {
@@ -64,6 +67,9 @@ func CreateTagOnDB(ctx context.Context, ddb *doltdb.DoltDB, tagName, startPoint
 	}
 
 	meta := doltdb.NewTagMeta(props.TaggerName, props.TaggerEmail, props.Description)
+	if !props.Date.IsZero() {
+		meta = doltdb.NewTagMetaWithUserTS(props.TaggerName, props.TaggerEmail, props.Description, props.Date)
+	}
 
 	return ddb.NewTagAtCommit(ctx, tagRef, cm, meta)
 }
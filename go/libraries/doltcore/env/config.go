@@ -49,6 +49,10 @@ const (
 	MetricsHost     = "metrics.host"
 	MetricsPort     = "metrics.port"
 	MetricsInsecure = "metrics.insecure"
+
+	// ReflogExpireDays is the number of days after which reflog entries expire and stop protecting the values they
+	// reference from garbage collection.
+	ReflogExpireDays = "reflog.expiredays"
 )
 
 var LocalConfigWhitelist = set.NewStrSet([]string{UserNameKey, UserEmailKey})
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 	"unicode"
@@ -135,6 +136,14 @@ func Load(ctx context.Context, hdp HomeDirProvider, fs filesys.Filesys, urlStr,
 		dEnv.RepoState.Backups = backups
 	}
 
+	if ddb != nil && ddb.Reflog() != nil && config != nil {
+		ddb.Reflog().SetMaxAge(reflogMaxAge(config))
+	}
+
+	if ddb != nil && ddb.IsPartial() && dEnv.RepoState != nil {
+		dEnv.DBLoadError = dEnv.setPartialCloneSource()
+	}
+
 	if dbLoadErr == nil && dEnv.HasDoltDir() {
 		if !dEnv.HasDoltTempTableDir() {
 			err := dEnv.FS.MkDirs(dEnv.TempTableFilesDir())
@@ -179,6 +188,20 @@ func Load(ctx context.Context, hdp HomeDirProvider, fs filesys.Filesys, urlStr,
 	return dEnv
 }
 
+// setPartialCloneSource makes the DoltDB of a partial clone fetch the row data it does not hold from the remote it was
+// cloned from.
+func (dEnv *DoltEnv) setPartialCloneSource() error {
+	pc, _ := dEnv.DoltDB.PartialClone()
+	return dEnv.DoltDB.SetPartialCloneSource(func(ctx context.Context) (*doltdb.DoltDB, error) {
+		r, ok := dEnv.RepoState.Remotes[pc.Remote]
+		if !ok {
+			return nil, fmt.Errorf("%w: '%s'", ErrUnknownRemote, pc.Remote)
+		}
+
+		return r.GetRemoteDB(ctx, dEnv.DoltDB.Format())
+	})
+}
+
 func GetDefaultInitBranch(cfg config.ReadableConfig) string {
 	return GetStringOrDefault(cfg, InitBranchName, DefaultInitBranch)
 }
@@ -370,6 +393,16 @@ func (dEnv *DoltEnv) InitRepoWithNoData(ctx context.Context, nbf *types.NomsBinF
 	return err
 }
 
+// reflogMaxAge returns the age after which reflog entries expire, as configured in the config given.
+func reflogMaxAge(config *DoltCliConfig) time.Duration {
+	days, err := strconv.Atoi(config.GetStringOrDefault(ReflogExpireDays, ""))
+	if err != nil || days < 0 {
+		return doltdb.DefaultReflogMaxAge
+	}
+
+	return time.Duration(days) * 24 * time.Hour
+}
+
 func (dEnv *DoltEnv) createDirectories(dir string) (string, error) {
 	absPath, err := dEnv.FS.Abs(dir)
 
@@ -1262,6 +1295,20 @@ func GetGCKeepers(ctx context.Context, env *DoltEnv) ([]hash.Hash, error) {
 		}
 
 		keepers = append(keepers, ch, pmwh)
+
+		if rs := ws.MergeState().RebaseState(); rs != nil {
+			oh, err := rs.Onto().HashOf()
+			if err != nil {
+				return nil, err
+			}
+
+			ohh, err := rs.OrigHead().HashOf()
+			if err != nil {
+				return nil, err
+			}
+
+			keepers = append(keepers, oh, ohh)
+		}
 	}
 
 	return keepers, nil
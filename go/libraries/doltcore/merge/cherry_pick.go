@@ -0,0 +1,82 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merge
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor"
+)
+
+var ErrCherryPickMergeCommit = errors.New("cherry-picking a merge commit is not supported")
+
+// CherryPick is a convenience function for a three-way merge that applies the changes introduced by a single commit
+// onto the given root. It is the inverse of Revert, applying a three-way merge with the following characteristics
+// (assuming the commit is HEAD~1):
+//
+// Base:   HEAD~2
+// Ours:   root
+// Theirs: HEAD~1
+//
+// Unlike Revert, any conflicts or constraint violations generated by the merge are written to the returned root, in
+// the same way that a merge would write them, so that they may be inspected and resolved. The commit message of the
+// given commit is returned along with the merge stats for each table.
+func CherryPick(ctx context.Context, ddb *doltdb.DoltDB, root *doltdb.RootValue, commit *doltdb.Commit, opts editor.Options) (*doltdb.RootValue, string, map[string]*MergeStats, error) {
+	if len(commit.ParentRefs()) > 1 {
+		return nil, "", nil, ErrCherryPickMergeCommit
+	}
+
+	theirRoot, err := commit.GetRootValue()
+	if err != nil {
+		return nil, "", nil, err
+	}
+	meta, err := commit.GetCommitMeta()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	var baseRoot *doltdb.RootValue
+	if len(commit.ParentRefs()) > 0 {
+		parentCM, err := ddb.ResolveParent(ctx, commit, 0)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		baseRoot, err = parentCM.GetRootValue()
+		if err != nil {
+			return nil, "", nil, err
+		}
+	} else {
+		baseRoot, err = doltdb.EmptyRootValue(ctx, ddb.ValueReadWriter())
+		if err != nil {
+			return nil, "", nil, err
+		}
+	}
+
+	root, tblToStats, err := MergeRoots(ctx, root, theirRoot, baseRoot, opts)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return root, meta.Description, tblToStats, nil
+}
+
+// HasConflictsOrViolations returns whether any of the tables in the given merge stats were left with conflicts or
+// constraint violations.
+func HasConflictsOrViolations(tblToStats map[string]*MergeStats) bool {
+	conflicts, constraintViolations := conflictsAndViolations(tblToStats)
+	return len(conflicts) > 0 || len(constraintViolations) > 0
+}
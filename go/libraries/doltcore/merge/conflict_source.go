@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"io"
+	"strings"
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/conflict"
 
@@ -184,6 +185,41 @@ func (cr *ConflictReader) GetKeyForConflict(ctx context.Context, r row.Row) (typ
 	return nil, errors.New("could not determine key")
 }
 
+// GetResolvedRowForConflict returns the pk of a conflict row, and the value of our side of the conflict as a row of the
+// table with schema |sch|. The value is NULL if our side of the conflict was deleted.
+func (cr *ConflictReader) GetResolvedRowForConflict(ctx context.Context, r row.Row, sch schema.Schema) (key, val types.Value, err error) {
+	rows, err := cr.joiner.Split(r)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	oursRow, ok := rows[oursStr]
+
+	if !ok {
+		return nil, types.NullValue, nil
+	}
+
+	key, err = oursRow.NomsMapKey(sch).Value(ctx)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	val, err = oursRow.NomsMapValue(sch).Value(ctx)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return key, val, nil
+}
+
+// IsOursColumn returns whether |colName| is a column of the conflict rows which holds a value of our side of the conflict.
+func IsOursColumn(colName string) bool {
+	return strings.HasPrefix(colName, oursStr+"_")
+}
+
 // Close should release resources being held
 func (cr *ConflictReader) Close() error {
 	return nil
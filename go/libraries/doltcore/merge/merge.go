@@ -193,7 +193,12 @@ func (merger *Merger) MergeTable(ctx context.Context, tblName string, opts edito
 		return nil, nil, err
 	}
 
-	resultTbl, cons, stats, err := mergeTableData(ctx, merger.vrw, tblName, postMergeSchema, rows, mergeRows, ancRows, updatedTblEditor)
+	strategies, err := loadColumnMergeStrategies(ctx, merger.root, tblName, postMergeSchema)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resultTbl, cons, stats, err := mergeTableData(ctx, merger.vrw, tblName, postMergeSchema, rows, mergeRows, ancRows, updatedTblEditor, strategies)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -300,12 +305,19 @@ type rowMerger func(ctx context.Context, nbf *types.NomsBinFormat, sch schema.Sc
 
 type applicator func(ctx context.Context, sch schema.Schema, tableEditor editor.TableEditor, rowData types.Map, stats *MergeStats, change types.ValueChanged) error
 
-func mergeTableData(ctx context.Context, vrw types.ValueReadWriter, tblName string, sch schema.Schema, rows, mergeRows, ancRows types.Map, tblEdit editor.TableEditor) (*doltdb.Table, types.Map, *MergeStats, error) {
+// mergeTableData merges the row data of a table. Rows of tables with primary keys which were changed on both sides of
+// the merge are merged column by column, using |strategies| to merge columns changed on both sides.
+func mergeTableData(ctx context.Context, vrw types.ValueReadWriter, tblName string, sch schema.Schema, rows, mergeRows, ancRows types.Map, tblEdit editor.TableEditor, strategies columnMergeStrategies) (*doltdb.Table, types.Map, *MergeStats, error) {
 	var rowMerge rowMerger
 	var applyChange applicator
 	if schema.IsKeyless(sch) {
 		rowMerge = keylessRowMerge
 		applyChange = applyKeylessChange
+	} else if len(strategies) != 0 {
+		rowMerge = func(ctx context.Context, nbf *types.NomsBinFormat, sch schema.Schema, r, mergeRow, baseRow types.Value) (types.Value, bool, error) {
+			return pkRowMergeWithStrategies(ctx, nbf, sch, strategies, r, mergeRow, baseRow)
+		}
+		applyChange = applyPkChange
 	} else {
 		rowMerge = pkRowMerge
 		applyChange = applyPkChange
@@ -692,6 +704,13 @@ func convertValueChanged(vc types.ValueChanged) (types.ValueChanged, uint64, err
 }
 
 func pkRowMerge(ctx context.Context, nbf *types.NomsBinFormat, sch schema.Schema, r, mergeRow, baseRow types.Value) (types.Value, bool, error) {
+	return pkRowMergeWithStrategies(ctx, nbf, sch, nil, r, mergeRow, baseRow)
+}
+
+// pkRowMergeWithStrategies merges a row changed on both sides of a merge column by column. A column changed to
+// different values on both sides is merged with its strategy in |strategies|, and is a conflict if it has none or its
+// strategy does not apply to the values.
+func pkRowMergeWithStrategies(ctx context.Context, nbf *types.NomsBinFormat, sch schema.Schema, strategies columnMergeStrategies, r, mergeRow, baseRow types.Value) (types.Value, bool, error) {
 	var baseVals row.TaggedValues
 	if baseRow == nil {
 		if r.Equals(mergeRow) {
@@ -723,23 +742,27 @@ func pkRowMerge(ctx context.Context, nbf *types.NomsBinFormat, sch schema.Schema
 		return nil, false, err
 	}
 
-	processTagFunc := func(tag uint64) (resultVal types.Value, isConflict bool) {
+	processTagFunc := func(tag uint64) (resultVal types.Value, isConflict bool, err error) {
 		baseVal, _ := baseVals.Get(tag)
 		val, _ := rowVals.Get(tag)
 		mergeVal, _ := mergeVals.Get(tag)
 
 		if valutil.NilSafeEqCheck(val, mergeVal) {
-			return val, false
+			return val, false, nil
 		} else {
 			modified := !valutil.NilSafeEqCheck(val, baseVal)
 			mergeModified := !valutil.NilSafeEqCheck(mergeVal, baseVal)
 			switch {
 			case modified && mergeModified:
-				return nil, true
+				if strategy, ok := strategies[tag]; ok {
+					resolved, ok, err := strategy.resolve(ctx, nbf, baseVal, val, mergeVal, rowVals, mergeVals)
+					return resolved, !ok, err
+				}
+				return nil, true, nil
 			case modified:
-				return val, false
+				return val, false, nil
 			default:
-				return mergeVal, false
+				return mergeVal, false, nil
 			}
 		}
 
@@ -750,10 +773,10 @@ func pkRowMerge(ctx context.Context, nbf *types.NomsBinFormat, sch schema.Schema
 	var isConflict bool
 	err = sch.GetNonPKCols().Iter(func(tag uint64, _ schema.Column) (stop bool, err error) {
 		var val types.Value
-		val, isConflict = processTagFunc(tag)
+		val, isConflict, err = processTagFunc(tag)
 		resultVals[tag] = val
 
-		return isConflict, nil
+		return isConflict, err
 	})
 
 	if err != nil {
@@ -0,0 +1,358 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merge
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/shopspring/decimal"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/row"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema/typeinfo"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+// MergeStrategy is the name of a strategy for merging the values of a column in a row that was changed on both sides
+// of a merge.
+type MergeStrategy string
+
+const (
+	// MergeStrategyOurs keeps the value from our side of the merge.
+	MergeStrategyOurs MergeStrategy = "ours"
+	// MergeStrategyTheirs takes the value from their side of the merge.
+	MergeStrategyTheirs MergeStrategy = "theirs"
+	// MergeStrategyMax takes the greater of the two values.
+	MergeStrategyMax MergeStrategy = "max"
+	// MergeStrategyMin takes the lesser of the two values.
+	MergeStrategyMin MergeStrategy = "min"
+	// MergeStrategySumOfDeltas applies the changes made on both sides to the ancestor's value, which is useful for
+	// counters and quantities.
+	MergeStrategySumOfDeltas MergeStrategy = "sum-of-deltas"
+	// MergeStrategyLatestTimestamp takes the value from the side whose row has the later value in the strategy's
+	// timestamp column, or whose value is later if no timestamp column is configured.
+	MergeStrategyLatestTimestamp MergeStrategy = "latest-timestamp"
+)
+
+// MergeStrategies are the names of all supported merge strategies.
+var MergeStrategies = []string{
+	string(MergeStrategyOurs),
+	string(MergeStrategyTheirs),
+	string(MergeStrategyMax),
+	string(MergeStrategyMin),
+	string(MergeStrategySumOfDeltas),
+	string(MergeStrategyLatestTimestamp),
+}
+
+// MergeStrategiesTableSchema returns the fixed dolt schema of the dolt_merge_strategies table.
+func MergeStrategiesTableSchema() schema.Schema {
+	strategyType, err := typeinfo.FromSqlType(sql.MustCreateEnumType(MergeStrategies, sql.Collation_Default))
+	if err != nil {
+		panic(err) // should never happen
+	}
+
+	strategyCol, err := schema.NewColumnWithTypeInfo(doltdb.MergeStrategiesStrategyCol, schema.DoltMergeStrategiesStrategyTag, strategyType, false, "", false, "", schema.NotNullConstraint{})
+	if err != nil {
+		panic(err) // should never happen
+	}
+
+	colColl := schema.NewColCollection(
+		schema.NewColumn(doltdb.MergeStrategiesTableNameCol, schema.DoltMergeStrategiesTableNameTag, types.StringKind, true, schema.NotNullConstraint{}),
+		schema.NewColumn(doltdb.MergeStrategiesColumnNameCol, schema.DoltMergeStrategiesColumnNameTag, types.StringKind, true, schema.NotNullConstraint{}),
+		strategyCol,
+		schema.NewColumn(doltdb.MergeStrategiesTimestampColumnCol, schema.DoltMergeStrategiesTimestampColumnTag, types.StringKind, false),
+	)
+
+	return schema.MustSchemaFromCols(colColl)
+}
+
+// columnMergeStrategy is a merge strategy configured for a single column.
+type columnMergeStrategy struct {
+	strategy MergeStrategy
+	// typ is the type of the column, which the values a strategy computes must fit. Computed values are not checked
+	// when it is nil.
+	typ typeinfo.TypeInfo
+	// tsTag is the tag of the timestamp column of a latest-timestamp strategy, if one was configured
+	tsTag    uint64
+	hasTsTag bool
+}
+
+// columnMergeStrategies maps the tags of a table's columns to the strategies used to merge them.
+type columnMergeStrategies map[uint64]columnMergeStrategy
+
+// loadColumnMergeStrategies reads the strategies configured for |tblName| from the dolt_merge_strategies table of
+// |root|, keyed by the tags of the columns of |sch| they apply to. Strategies for columns which are not in |sch|, and
+// for primary key columns, are ignored.
+func loadColumnMergeStrategies(ctx context.Context, root *doltdb.RootValue, tblName string, sch schema.Schema) (columnMergeStrategies, error) {
+	tbl, ok, err := root.GetTable(ctx, doltdb.MergeStrategiesTableName)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	stratSch, err := tbl.GetSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stratCols := stratSch.GetAllCols()
+	tblNameCol, ok1 := stratCols.GetByName(doltdb.MergeStrategiesTableNameCol)
+	colNameCol, ok2 := stratCols.GetByName(doltdb.MergeStrategiesColumnNameCol)
+	strategyCol, ok3 := stratCols.GetByName(doltdb.MergeStrategiesStrategyCol)
+	tsColNameCol, ok4 := stratCols.GetByName(doltdb.MergeStrategiesTimestampColumnCol)
+	if !ok1 || !ok2 || !ok3 || !ok4 {
+		return nil, fmt.Errorf("`%s` schema in unexpected format", doltdb.MergeStrategiesTableName)
+	}
+
+	rowData, err := tbl.GetNomsRowData(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	strategies := make(columnMergeStrategies)
+	err = rowData.IterAll(ctx, func(key, value types.Value) error {
+		r, err := row.FromNoms(stratSch, key.(types.Tuple), value.(types.Tuple))
+		if err != nil {
+			return err
+		}
+
+		vals := make([]string, 4)
+		for i, col := range []schema.Column{tblNameCol, colNameCol, strategyCol, tsColNameCol} {
+			val, ok := r.GetColVal(col.Tag)
+			if !ok || types.IsNull(val) {
+				continue
+			}
+
+			str, err := col.TypeInfo.FormatValue(val)
+			if err != nil {
+				return err
+			}
+			if str != nil {
+				vals[i] = *str
+			}
+		}
+
+		if !strings.EqualFold(vals[0], tblName) {
+			return nil
+		}
+
+		col, ok := sch.GetNonPKCols().GetByNameCaseInsensitive(vals[1])
+		if !ok {
+			return nil
+		}
+
+		cms := columnMergeStrategy{strategy: MergeStrategy(strings.ToLower(vals[2])), typ: col.TypeInfo}
+		if !isMergeStrategy(cms.strategy) {
+			return fmt.Errorf("unknown merge strategy '%s' for column %s.%s", vals[2], tblName, col.Name)
+		}
+
+		if len(vals[3]) != 0 {
+			if cms.strategy != MergeStrategyLatestTimestamp {
+				return fmt.Errorf("%s for column %s.%s can only be set for the %s strategy", doltdb.MergeStrategiesTimestampColumnCol, tblName, col.Name, MergeStrategyLatestTimestamp)
+			}
+
+			tsCol, ok := sch.GetAllCols().GetByNameCaseInsensitive(vals[3])
+			if !ok {
+				return fmt.Errorf("timestamp column %s of the merge strategy for column %s.%s does not exist", vals[3], tblName, col.Name)
+			}
+
+			cms.tsTag, cms.hasTsTag = tsCol.Tag, true
+		}
+
+		strategies[col.Tag] = cms
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return strategies, nil
+}
+
+func isMergeStrategy(strategy MergeStrategy) bool {
+	for _, s := range MergeStrategies {
+		if string(strategy) == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resolve merges the values of a column which was changed on both sides of a merge to different values. |rowVals|
+// and |mergeVals| are the values of the whole row on each side. It returns false if the strategy cannot be applied
+// to the values, in which case the row is a conflict.
+func (cms columnMergeStrategy) resolve(ctx context.Context, nbf *types.NomsBinFormat, baseVal, val, mergeVal types.Value, rowVals, mergeVals row.TaggedValues) (types.Value, bool, error) {
+	switch cms.strategy {
+	case MergeStrategyOurs:
+		return val, true, nil
+	case MergeStrategyTheirs:
+		return mergeVal, true, nil
+	case MergeStrategyMax, MergeStrategyMin:
+		less, ok, err := compareValues(nbf, val, mergeVal)
+		if err != nil || !ok {
+			return nil, false, err
+		}
+
+		if less == (cms.strategy == MergeStrategyMax) {
+			return mergeVal, true, nil
+		}
+		return val, true, nil
+	case MergeStrategySumOfDeltas:
+		return sumOfDeltas(ctx, cms.typ, baseVal, val, mergeVal)
+	case MergeStrategyLatestTimestamp:
+		ts, mergeTs := val, mergeVal
+		if cms.hasTsTag {
+			ts, _ = rowVals.Get(cms.tsTag)
+			mergeTs, _ = mergeVals.Get(cms.tsTag)
+		}
+
+		less, ok, err := compareValues(nbf, ts, mergeTs)
+		if err != nil || !ok {
+			return nil, false, err
+		}
+
+		if less {
+			return mergeVal, true, nil
+		} else if ts.Equals(mergeTs) {
+			// neither side is later
+			return nil, false, nil
+		}
+		return val, true, nil
+	}
+
+	return nil, false, fmt.Errorf("unknown merge strategy '%s'", cms.strategy)
+}
+
+// compareValues returns whether |val| is less than |mergeVal|. It returns false for its second result if the values
+// cannot be compared because either of them is NULL or they are of different kinds.
+func compareValues(nbf *types.NomsBinFormat, val, mergeVal types.Value) (less bool, ok bool, err error) {
+	if types.IsNull(val) || types.IsNull(mergeVal) || val.Kind() != mergeVal.Kind() {
+		return false, false, nil
+	}
+
+	less, err = val.Less(nbf, mergeVal)
+	if err != nil {
+		return false, false, err
+	}
+
+	return less, true, nil
+}
+
+// sumOfDeltas returns |val| + |mergeVal| - |baseVal|, the ancestor's value with the changes made on both sides of the
+// merge applied to it. A NULL |baseVal| is treated as zero, so rows added on both sides are summed. It returns false
+// if either side's value is NULL, the values are not numbers of the same kind, or the result overflows, either its
+// kind or the column type |typ|, such as a TINYINT or the precision of a DECIMAL.
+func sumOfDeltas(ctx context.Context, typ typeinfo.TypeInfo, baseVal, val, mergeVal types.Value) (types.Value, bool, error) {
+	sum, ok := sumOfNumberDeltas(baseVal, val, mergeVal)
+	if !ok || typ == nil {
+		return sum, ok, nil
+	}
+
+	var goVal interface{}
+	switch v := sum.(type) {
+	case types.Int:
+		goVal = int64(v)
+	case types.Uint:
+		goVal = uint64(v)
+	case types.Float:
+		goVal = float64(v)
+	case types.Decimal:
+		goVal = decimal.Decimal(v)
+	}
+
+	// values which do not fit the column type fail to convert, rather than being truncated
+	fitted, err := typ.ConvertValueToNomsValue(ctx, nil, goVal)
+	if err != nil {
+		return nil, false, nil
+	}
+
+	return fitted, true, nil
+}
+
+// sumOfNumberDeltas returns |val| + |mergeVal| - |baseVal| for the kind of the values, or false if the sum cannot be
+// computed, or overflows the kind.
+func sumOfNumberDeltas(baseVal, val, mergeVal types.Value) (types.Value, bool) {
+	if types.IsNull(val) || types.IsNull(mergeVal) || val.Kind() != mergeVal.Kind() {
+		return nil, false
+	}
+
+	hasBase := !types.IsNull(baseVal)
+	if hasBase && baseVal.Kind() != val.Kind() {
+		return nil, false
+	}
+
+	switch v := val.(type) {
+	case types.Int:
+		var base int64
+		if hasBase {
+			base = int64(baseVal.(types.Int))
+		}
+
+		merge := int64(mergeVal.(types.Int))
+		delta := merge - base
+		if (base > 0 && delta > merge) || (base < 0 && delta < merge) {
+			return nil, false
+		}
+
+		sum := int64(v) + delta
+		if (delta > 0 && sum < int64(v)) || (delta < 0 && sum > int64(v)) {
+			return nil, false
+		}
+		return types.Int(sum), true
+	case types.Uint:
+		var base uint64
+		if hasBase {
+			base = uint64(baseVal.(types.Uint))
+		}
+
+		merge := uint64(mergeVal.(types.Uint))
+		if merge >= base {
+			delta := merge - base
+			if uint64(v) > math.MaxUint64-delta {
+				return nil, false
+			}
+			return types.Uint(uint64(v) + delta), true
+		}
+
+		delta := base - merge
+		if uint64(v) < delta {
+			return nil, false
+		}
+		return types.Uint(uint64(v) - delta), true
+	case types.Float:
+		var base float64
+		if hasBase {
+			base = float64(baseVal.(types.Float))
+		}
+
+		return types.Float(float64(v) + float64(mergeVal.(types.Float)) - base), true
+	case types.Decimal:
+		base := decimal.Zero
+		if hasBase {
+			base = decimal.Decimal(baseVal.(types.Decimal))
+		}
+
+		sum := decimal.Decimal(v).Add(decimal.Decimal(mergeVal.(types.Decimal))).Sub(base)
+		return types.Decimal(sum), true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,203 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merge
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema/typeinfo"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+func TestSumOfDeltas(t *testing.T) {
+	decimalType, err := typeinfo.FromSqlType(sql.MustCreateDecimalType(5, 2))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name                string
+		typ                 typeinfo.TypeInfo
+		base, val, mergeVal types.Value
+		expected            types.Value
+		expectOk            bool
+	}{
+		{"ints", nil, types.Int(10), types.Int(13), types.Int(6), types.Int(9), true},
+		{"ints without base", nil, types.NullValue, types.Int(3), types.Int(4), types.Int(7), true},
+		{"negative ints", nil, types.Int(0), types.Int(-5), types.Int(-5), types.Int(-10), true},
+		{"int overflow", nil, types.Int(0), types.Int(math.MaxInt64), types.Int(1), nil, false},
+		{"uints", nil, types.Uint(10), types.Uint(13), types.Uint(6), types.Uint(9), true},
+		{"uint underflow", nil, types.Uint(10), types.Uint(2), types.Uint(1), nil, false},
+		{"uint overflow", nil, types.Uint(0), types.Uint(math.MaxUint64), types.Uint(1), nil, false},
+		{"floats", nil, types.Float(1), types.Float(1.5), types.Float(3), types.Float(3.5), true},
+		{
+			"decimals",
+			nil,
+			types.Decimal(decimal.RequireFromString("1.10")),
+			types.Decimal(decimal.RequireFromString("2.20")),
+			types.Decimal(decimal.RequireFromString("0.10")),
+			types.Decimal(decimal.RequireFromString("1.20")),
+			true,
+		},
+		{"null value", nil, types.Int(1), types.NullValue, types.Int(2), nil, false},
+		{"mismatched kinds", nil, types.Int(1), types.Int(2), types.Uint(3), nil, false},
+		{"strings", nil, types.String("a"), types.String("b"), types.String("c"), nil, false},
+		{"tinyint", typeinfo.Int8Type, types.Int(100), types.Int(110), types.Int(115), types.Int(125), true},
+		{"tinyint overflow", typeinfo.Int8Type, types.Int(100), types.Int(120), types.Int(110), nil, false},
+		{"tinyint underflow", typeinfo.Int8Type, types.Int(-100), types.Int(-120), types.Int(-110), nil, false},
+		{
+			"decimal within precision",
+			decimalType,
+			types.Decimal(decimal.RequireFromString("500.00")),
+			types.Decimal(decimal.RequireFromString("700.25")),
+			types.Decimal(decimal.RequireFromString("799.50")),
+			types.Decimal(decimal.RequireFromString("999.75")),
+			true,
+		},
+		{
+			"decimal exceeding precision",
+			decimalType,
+			types.Decimal(decimal.RequireFromString("500.00")),
+			types.Decimal(decimal.RequireFromString("700.00")),
+			types.Decimal(decimal.RequireFromString("800.00")),
+			nil,
+			false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, ok, err := sumOfDeltas(context.Background(), test.typ, test.base, test.val, test.mergeVal)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectOk, ok)
+			if !ok {
+				return
+			}
+
+			if d, isDecimal := test.expected.(types.Decimal); isDecimal {
+				assert.True(t, decimal.Decimal(d).Equal(decimal.Decimal(actual.(types.Decimal))))
+			} else {
+				assert.Equal(t, test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRowMergeWithStrategies(t *testing.T) {
+	earlier := types.Timestamp(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	later := types.Timestamp(time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		test       RowMergeTest
+		strategies columnMergeStrategies
+	}{
+		{
+			createRowMergeStruct(
+				"no strategy",
+				[]types.Value{types.Int(2)},
+				[]types.Value{types.Int(3)},
+				[]types.Value{types.Int(1)},
+				nil,
+				true,
+			),
+			columnMergeStrategies{},
+		},
+		{
+			createRowMergeStruct(
+				"ours and theirs",
+				[]types.Value{types.String("ours"), types.String("ours")},
+				[]types.Value{types.String("theirs"), types.String("theirs")},
+				[]types.Value{types.String("base"), types.String("base")},
+				[]types.Value{types.String("ours"), types.String("theirs")},
+				false,
+			),
+			columnMergeStrategies{1: {strategy: MergeStrategyOurs}, 2: {strategy: MergeStrategyTheirs}},
+		},
+		{
+			createRowMergeStruct(
+				"max and min",
+				[]types.Value{types.Int(5), types.Int(5)},
+				[]types.Value{types.Int(7), types.Int(7)},
+				[]types.Value{types.Int(1), types.Int(1)},
+				[]types.Value{types.Int(7), types.Int(5)},
+				false,
+			),
+			columnMergeStrategies{1: {strategy: MergeStrategyMax}, 2: {strategy: MergeStrategyMin}},
+		},
+		{
+			createRowMergeStruct(
+				"sum of deltas with unmodified column",
+				[]types.Value{types.Int(13), types.String("a")},
+				[]types.Value{types.Int(6), types.String("a")},
+				[]types.Value{types.Int(10), types.String("a")},
+				[]types.Value{types.Int(9), types.String("a")},
+				false,
+			),
+			columnMergeStrategies{1: {strategy: MergeStrategySumOfDeltas}},
+		},
+		{
+			createRowMergeStruct(
+				"strategy does not apply",
+				[]types.Value{types.Int(13), types.NullValue},
+				[]types.Value{types.NullValue, types.Int(2)},
+				[]types.Value{types.Int(10), types.Int(1)},
+				nil,
+				true,
+			),
+			columnMergeStrategies{1: {strategy: MergeStrategySumOfDeltas}, 2: {strategy: MergeStrategyMax}},
+		},
+		{
+			createRowMergeStruct(
+				"latest timestamp column",
+				[]types.Value{types.String("ours"), later},
+				[]types.Value{types.String("theirs"), earlier},
+				[]types.Value{types.String("base"), types.NullValue},
+				[]types.Value{types.String("ours"), later},
+				false,
+			),
+			columnMergeStrategies{
+				1: {strategy: MergeStrategyLatestTimestamp, tsTag: 2, hasTsTag: true},
+				2: {strategy: MergeStrategyLatestTimestamp},
+			},
+		},
+		{
+			createRowMergeStruct(
+				"latest timestamp tie",
+				[]types.Value{types.String("ours"), earlier},
+				[]types.Value{types.String("theirs"), earlier},
+				[]types.Value{types.String("base"), types.NullValue},
+				nil,
+				true,
+			),
+			columnMergeStrategies{1: {strategy: MergeStrategyLatestTimestamp, tsTag: 2, hasTsTag: true}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.test.name, func(t *testing.T) {
+			rmt := test.test
+			actualResult, isConflict, err := pkRowMergeWithStrategies(context.Background(), types.Format_Default, rmt.sch, test.strategies, rmt.row, rmt.mergeRow, rmt.ancRow)
+			require.NoError(t, err)
+			assert.Equal(t, rmt.expectConflict, isConflict)
+			assert.Equal(t, rmt.expectedResult, actualResult)
+		})
+	}
+}
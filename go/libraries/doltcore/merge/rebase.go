@@ -0,0 +1,306 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env/actions/commitwalk"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor"
+)
+
+var ErrInvalidRebasePlan = errors.New("invalid rebase plan")
+
+// RebaseAction is the action taken for a single commit in a rebase plan.
+type RebaseAction string
+
+const (
+	// RebasePick replays the changes of a commit as a new commit with the same message.
+	RebasePick RebaseAction = "pick"
+	// RebaseSquash replays the changes of a commit and folds them into the previous commit, combining their messages.
+	RebaseSquash RebaseAction = "squash"
+	// RebaseDrop skips a commit, discarding its changes.
+	RebaseDrop RebaseAction = "drop"
+)
+
+var rebaseActionNames = map[string]RebaseAction{
+	"p":      RebasePick,
+	"pick":   RebasePick,
+	"s":      RebaseSquash,
+	"squash": RebaseSquash,
+	"d":      RebaseDrop,
+	"drop":   RebaseDrop,
+}
+
+// RebaseStep is a single step of a rebase plan.
+type RebaseStep struct {
+	Action RebaseAction
+	Commit *doltdb.Commit
+}
+
+// RebaseResult is the outcome of replaying a rebase plan.
+type RebaseResult struct {
+	// Head is the last commit created by the rebase. The commits created by a rebase are dangling, and it is up to the
+	// caller to update the branch being rebased to point at Head.
+	Head *doltdb.Commit
+	// Stopped is true if the rebase stopped because a step could not be applied without conflicts or constraint
+	// violations.
+	Stopped bool
+	// Step is the step that could not be applied, if Stopped is true.
+	Step RebaseStep
+	// Remaining are the steps following Step that have not been applied, if Stopped is true.
+	Remaining []RebaseStep
+	// WorkingRoot is the result of applying Step on top of Head, including its conflicts and constraint violations, if
+	// Stopped is true.
+	WorkingRoot *doltdb.RootValue
+	// TblToStats are the merge stats from applying Step, if Stopped is true.
+	TblToStats map[string]*MergeStats
+}
+
+// RebaseCommits returns the commits that are replayed when rebasing |head| onto |upstream|, oldest first. These are
+// the commits reachable from |head| that are not reachable from |upstream|, i.e. the commits made since the merge base
+// of the two. Merge commits are not included.
+func RebaseCommits(ctx context.Context, ddb *doltdb.DoltDB, head, upstream *doltdb.Commit) ([]*doltdb.Commit, error) {
+	headHash, err := head.HashOf()
+	if err != nil {
+		return nil, err
+	}
+
+	upstreamHash, err := upstream.HashOf()
+	if err != nil {
+		return nil, err
+	}
+
+	commits, err := commitwalk.GetDotDotRevisions(ctx, ddb, headHash, ddb, upstreamHash, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	var toReplay []*doltdb.Commit
+	for i := len(commits) - 1; i >= 0; i-- {
+		if len(commits[i].ParentRefs()) > 1 {
+			continue
+		}
+		toReplay = append(toReplay, commits[i])
+	}
+
+	return toReplay, nil
+}
+
+// NewRebasePlan returns a rebase plan that picks each of the commits given, in order.
+func NewRebasePlan(commits []*doltdb.Commit) []RebaseStep {
+	steps := make([]RebaseStep, len(commits))
+	for i, cm := range commits {
+		steps[i] = RebaseStep{Action: RebasePick, Commit: cm}
+	}
+
+	return steps
+}
+
+// ParseRebasePlan parses the text of a rebase plan. Each line of a plan consists of an action (pick, squash or drop,
+// or their abbreviations p, s and d), followed by a commit spec for the commit the action applies to. Anything after
+// the commit spec is ignored, as are blank lines and lines beginning with #. For example:
+//
+//	pick 2ei5ac7gkvf2ebh4cdsh23tefn2ai4k9 insert rows
+//	squash 3fmhh9lbmdp4h5i3sfb0obm3bbq4gsk0 fix typo
+//	drop main~1
+//
+// Commit specs are resolved relative to |cwb|.
+func ParseRebasePlan(ctx context.Context, ddb *doltdb.DoltDB, cwb ref.DoltRef, plan string) ([]RebaseStep, error) {
+	var steps []RebaseStep
+	for i, line := range strings.Split(plan, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		action, ok := rebaseActionNames[strings.ToLower(fields[0])]
+		if !ok {
+			return nil, fmt.Errorf("%w: line %d: unknown action '%s'", ErrInvalidRebasePlan, i+1, fields[0])
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("%w: line %d: missing commit for action '%s'", ErrInvalidRebasePlan, i+1, fields[0])
+		}
+
+		cs, err := doltdb.NewCommitSpec(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %s", ErrInvalidRebasePlan, i+1, err.Error())
+		}
+
+		cm, err := ddb.Resolve(ctx, cs, cwb)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: could not resolve '%s': %s", ErrInvalidRebasePlan, i+1, fields[1], err.Error())
+		}
+
+		steps = append(steps, RebaseStep{Action: action, Commit: cm})
+	}
+
+	return steps, nil
+}
+
+// FormatRebasePlan returns the text of the rebase plan given, in the format read by ParseRebasePlan.
+func FormatRebasePlan(steps []RebaseStep) (string, error) {
+	sb := strings.Builder{}
+	for _, step := range steps {
+		h, err := step.Commit.HashOf()
+		if err != nil {
+			return "", err
+		}
+
+		meta, err := step.Commit.GetCommitMeta()
+		if err != nil {
+			return "", err
+		}
+
+		summary := strings.SplitN(meta.Description, "\n", 2)[0]
+		sb.WriteString(fmt.Sprintf("%s %s %s\n", step.Action, h.String(), summary))
+	}
+
+	return sb.String(), nil
+}
+
+// ValidateRebasePlan returns an error if the rebase plan given cannot be applied from its beginning.
+func ValidateRebasePlan(steps []RebaseStep) error {
+	sawPick := false
+	for _, step := range steps {
+		if step.Action == RebaseDrop {
+			continue
+		}
+
+		if len(step.Commit.ParentRefs()) > 1 {
+			h, err := step.Commit.HashOf()
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("%w: commit %s is a merge commit, which cannot be rebased", ErrInvalidRebasePlan, h.String())
+		}
+
+		if step.Action == RebaseSquash && !sawPick {
+			return fmt.Errorf("%w: cannot squash without a previous commit", ErrInvalidRebasePlan)
+		}
+		sawPick = true
+	}
+
+	return nil
+}
+
+// ReplayRebasePlan applies each step of the rebase plan given on top of |head|, which is |onto| or a commit created
+// by a previous step of the same rebase. The changes of each commit are applied by way of a three-way merge, in the
+// same manner as CherryPick. If applying a step results in conflicts or constraint violations, replaying stops and the
+// returned result describes the step that could not be applied, so that the user may resolve the conflicts, commit the
+// step with CommitRebaseStep, and replay the remaining steps.
+func ReplayRebasePlan(ctx context.Context, ddb *doltdb.DoltDB, onto, head *doltdb.Commit, steps []RebaseStep, opts editor.Options) (*RebaseResult, error) {
+	for i, step := range steps {
+		if step.Action == RebaseDrop {
+			continue
+		}
+
+		headRoot, err := head.GetRootValue()
+		if err != nil {
+			return nil, err
+		}
+
+		root, _, tblToStats, err := CherryPick(ctx, ddb, headRoot, step.Commit, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		if HasConflictsOrViolations(tblToStats) {
+			return &RebaseResult{
+				Head:        head,
+				Stopped:     true,
+				Step:        step,
+				Remaining:   steps[i+1:],
+				WorkingRoot: root,
+				TblToStats:  tblToStats,
+			}, nil
+		}
+
+		head, err = CommitRebaseStep(ctx, ddb, onto, head, step, root)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &RebaseResult{Head: head}, nil
+}
+
+// CommitRebaseStep creates the commit for a rebase step whose changes have been applied on top of |head|, resulting
+// in |root|, and returns the new head of the rebase. A picked commit is recreated with its original author and message
+// on top of |head|, unless it no longer introduces any changes, in which case it is dropped. A squashed commit replaces
+// |head|, combining the messages of the two. Squashing onto |onto| is treated as a pick, as |onto| is not part of the
+// rebase.
+func CommitRebaseStep(ctx context.Context, ddb *doltdb.DoltDB, onto, head *doltdb.Commit, step RebaseStep, root *doltdb.RootValue) (*doltdb.Commit, error) {
+	meta, err := step.Commit.GetCommitMeta()
+	if err != nil {
+		return nil, err
+	}
+
+	ontoHash, err := onto.HashOf()
+	if err != nil {
+		return nil, err
+	}
+
+	headHash, err := head.HashOf()
+	if err != nil {
+		return nil, err
+	}
+
+	parents := []*doltdb.Commit{head}
+	if step.Action == RebaseSquash && headHash != ontoHash {
+		headMeta, err := head.GetCommitMeta()
+		if err != nil {
+			return nil, err
+		}
+
+		parents, err = ddb.ResolveAllParents(ctx, head)
+		if err != nil {
+			return nil, err
+		}
+
+		squashedMeta := *headMeta
+		squashedMeta.Description = headMeta.Description + "\n\n" + meta.Description
+		meta = &squashedMeta
+	} else {
+		headRoot, err := head.GetRootValue()
+		if err != nil {
+			return nil, err
+		}
+
+		if same, err := doltdb.RootsEqual(headRoot, root); err != nil {
+			return nil, err
+		} else if same {
+			return head, nil
+		}
+	}
+
+	meta, err = doltdb.NewCommitMeta(meta.Name, meta.Email, meta.Description)
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := ddb.WriteRootValue(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+
+	return ddb.CommitDanglingWithParentCommits(ctx, h, parents, meta)
+}
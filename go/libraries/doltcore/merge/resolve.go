@@ -135,46 +135,7 @@ func resolvePkTable(ctx context.Context, tbl *doltdb.Table, tblName string, opts
 			return false, err
 		}
 
-		if types.IsNull(updated) {
-			originalRow, err := row.FromNoms(tblSch, key.(types.Tuple), cnf.Base.(types.Tuple))
-			if err != nil {
-				return false, err
-			}
-
-			err = tableEditor.DeleteRow(ctx, originalRow)
-			if err != nil {
-				return false, err
-			}
-		} else {
-			updatedRow, err := row.FromNoms(tblSch, key.(types.Tuple), updated.(types.Tuple))
-			if err != nil {
-				return false, err
-			}
-
-			if isValid, err := row.IsValid(updatedRow, tblSch); err != nil {
-				return false, err
-			} else if !isValid {
-				return false, table.NewBadRow(updatedRow, "error resolving conflicts", fmt.Sprintf("row with primary key %v in table %s does not match constraints or types of the table's schema.", key, tblName))
-			}
-
-			if types.IsNull(cnf.Value) {
-				err = tableEditor.InsertRow(ctx, updatedRow, nil)
-				if err != nil {
-					return false, err
-				}
-			} else {
-				originalRow, err := row.FromNoms(tblSch, key.(types.Tuple), cnf.Value.(types.Tuple))
-				if err != nil {
-					return false, err
-				}
-				err = tableEditor.UpdateRow(ctx, originalRow, updatedRow, nil)
-				if err != nil {
-					return false, err
-				}
-			}
-		}
-
-		return false, nil
+		return false, resolvePkRow(ctx, tableEditor, tblSch, tblName, key, cnf, updated)
 	})
 	if err != nil {
 		return nil, err
@@ -183,6 +144,113 @@ func resolvePkTable(ctx context.Context, tbl *doltdb.Table, tblName string, opts
 	return tableEditor.Table(ctx)
 }
 
+// resolvePkRow writes the resolved value |updated| of the conflicting row with primary key |key| to |tableEditor|. The
+// table is expected to contain our side of the conflict. A NULL |updated| deletes the row.
+func resolvePkRow(ctx context.Context, tableEditor editor.TableEditor, tblSch schema.Schema, tblName string, key types.Value, cnf conflict.Conflict, updated types.Value) error {
+	if types.IsNull(updated) {
+		if types.IsNull(cnf.Value) {
+			// already deleted on our side
+			return nil
+		}
+
+		originalRow, err := row.FromNoms(tblSch, key.(types.Tuple), cnf.Value.(types.Tuple))
+		if err != nil {
+			return err
+		}
+
+		return tableEditor.DeleteRow(ctx, originalRow)
+	}
+
+	updatedRow, err := row.FromNoms(tblSch, key.(types.Tuple), updated.(types.Tuple))
+	if err != nil {
+		return err
+	}
+
+	if isValid, err := row.IsValid(updatedRow, tblSch); err != nil {
+		return err
+	} else if !isValid {
+		return table.NewBadRow(updatedRow, "error resolving conflicts", fmt.Sprintf("row with primary key %v in table %s does not match constraints or types of the table's schema.", key, tblName))
+	}
+
+	if types.IsNull(cnf.Value) {
+		return tableEditor.InsertRow(ctx, updatedRow, nil)
+	}
+
+	originalRow, err := row.FromNoms(tblSch, key.(types.Tuple), cnf.Value.(types.Tuple))
+	if err != nil {
+		return err
+	}
+
+	return tableEditor.UpdateRow(ctx, originalRow, updatedRow, nil)
+}
+
+// ResolvedRow is the value a conflicting row is resolved to. A NULL Value resolves the conflict by deleting the row.
+type ResolvedRow struct {
+	Key   types.Value
+	Value types.Value
+}
+
+// ResolvePkConflicts writes the resolved values of conflicting rows to a table with a primary key and removes their
+// conflicts, leaving the table's other conflicts in place. It is an error for a resolved row to not be in conflict.
+func ResolvePkConflicts(ctx context.Context, tbl *doltdb.Table, tblName string, opts editor.Options, resolved []ResolvedRow) (*doltdb.Table, error) {
+	if len(resolved) == 0 {
+		return tbl, nil
+	}
+
+	tblSch, err := tbl.GetSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if schema.IsKeyless(tblSch) {
+		return nil, fmt.Errorf("conflicts of keyless table %s cannot be resolved to new values", tblName)
+	}
+
+	_, conflicts, err := tbl.GetConflicts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tableEditor, err := editor.NewTableEditor(ctx, tbl, tblSch, tblName, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]types.Value, len(resolved))
+	for i, res := range resolved {
+		cnfVal, ok, err := conflicts.MaybeGet(ctx, res.Key)
+		if err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, fmt.Errorf("row with primary key %v in table %s is not in conflict", res.Key, tblName)
+		}
+
+		cnf, err := conflict.ConflictFromTuple(cnfVal.(types.Tuple))
+		if err != nil {
+			return nil, err
+		}
+
+		err = resolvePkRow(ctx, tableEditor, tblSch, tblName, res.Key, cnf, res.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		keys[i] = res.Key
+	}
+
+	tbl, err = tableEditor.Table(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, _, tbl, err = tbl.ResolveConflicts(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return tbl, nil
+}
+
 func resolveKeylessTable(ctx context.Context, tbl *doltdb.Table, auto AutoResolver) (*doltdb.Table, error) {
 	_, conflicts, err := tbl.GetConflicts(ctx)
 	if err != nil {
@@ -0,0 +1,58 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merge
+
+import (
+	"context"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor"
+)
+
+// ApplyStash reapplies the changes shelved in the given stash to the given roots by way of a three-way merge, using
+// the HEAD commit the stash was created on as the common ancestor. The stashed working root is merged into the current
+// working root, and the stashed staged root is merged into the current staged root.
+//
+// Any conflicts or constraint violations generated by merging the working roots are written to the returned working
+// root, and the returned merge stats can be used to detect them. If merging the staged roots cannot be done cleanly,
+// the current staged root is left unchanged, so that all of the stashed changes are present in the working root only.
+func ApplyStash(ctx context.Context, roots doltdb.Roots, stash *doltdb.Stash, opts editor.Options) (doltdb.Roots, map[string]*MergeStats, error) {
+	ancRoot, err := stash.HeadCommit().GetRootValue()
+	if err != nil {
+		return doltdb.Roots{}, nil, err
+	}
+
+	stashedWorking, err := stash.WorkingRoot()
+	if err != nil {
+		return doltdb.Roots{}, nil, err
+	}
+
+	mergedWorking, tblToStats, err := MergeRoots(ctx, roots.Working, stashedWorking, ancRoot, opts)
+	if err != nil {
+		return doltdb.Roots{}, nil, err
+	}
+
+	mergedStaged, stagedStats, err := MergeRoots(ctx, roots.Staged, stash.StagedRoot(), ancRoot, opts)
+	if err != nil {
+		return doltdb.Roots{}, nil, err
+	}
+	if HasConflictsOrViolations(stagedStats) {
+		mergedStaged = roots.Staged
+	}
+
+	roots.Working = mergedWorking
+	roots.Staged = mergedStaged
+	return roots, tblToStats, nil
+}
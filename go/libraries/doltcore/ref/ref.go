@@ -51,6 +51,9 @@ const (
 
 	// WorkspaceRefType is a reference to a workspace
 	WorkspaceRefType RefType = "workspaces"
+
+	// StashRefType is a reference to a stash of working set changes
+	StashRefType RefType = "stashes"
 )
 
 // HeadRefTypes are the ref types that point to a HEAD and contain a Commit struct. These are the types that are
@@ -161,5 +164,11 @@ func Parse(str string) (DoltRef, error) {
 		}
 	}
 
+	// Stash refs point to commits, but are local to a repository and aren't HEADs of anything, so they are parsed
+	// separately from HeadRefTypes.
+	if prefix := PrefixForType(StashRefType); strings.HasPrefix(str, prefix) {
+		return NewStashRef(str[len(prefix):]), nil
+	}
+
 	return nil, ErrUnknownRefType
 }
@@ -0,0 +1,219 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesrv
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+// ReposAPIPath is the path of the repository api of the http server. GET ReposAPIPath lists the repositories the
+// request may read. GET ReposAPIPath/<org>/<repo> describes a repository, and PUT ReposAPIPath/<org>/<repo> creates
+// one.
+const ReposAPIPath = "/api/v1alpha1/repos"
+
+// RepoInfo describes a repository in the responses of the repository api.
+type RepoInfo struct {
+	Org  string `json:"org"`
+	Name string `json:"name"`
+	// Size is the total size of the table files of the repository, in bytes.
+	Size *uint64 `json:"size,omitempty"`
+	// Root is the root hash of the repository.
+	Root string `json:"root,omitempty"`
+	// Quota is the limit of Size, or zero if it is not limited.
+	Quota *uint64 `json:"quota,omitempty"`
+}
+
+// ListReposResponse is the response to a request listing repositories.
+type ListReposResponse struct {
+	Repos []RepoInfo `json:"repos"`
+}
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// reposAPIHandler is the http handler of the repository api.
+type reposAPIHandler struct {
+	csCache  *DBCache
+	auth     Authorizer
+	readOnly bool
+	quotas   QuotaConfig
+	lgr      *logrus.Entry
+}
+
+func (h *reposAPIHandler) ServeHTTP(respWr http.ResponseWriter, req *http.Request) {
+	logger := getReqLogger(h.lgr, "HTTP_"+req.Method, req.URL.Path)
+	defer func() { logger.Trace("finished") }()
+
+	path := strings.Trim(strings.TrimPrefix(req.URL.Path, ReposAPIPath), "/")
+	if path == "" {
+		if req.Method != http.MethodGet {
+			writeAPIError(respWr, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+
+		h.listRepos(logger, respWr, req)
+		return
+	}
+
+	tokens := strings.Split(path, "/")
+	if len(tokens) != 2 {
+		writeAPIError(respWr, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+
+	org, repo := tokens[0], tokens[1]
+	switch req.Method {
+	case http.MethodGet:
+		h.getRepo(logger, respWr, req, org, repo)
+	case http.MethodPut, http.MethodPost:
+		h.createRepo(logger, respWr, req, org, repo)
+	default:
+		writeAPIError(respWr, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+	}
+}
+
+// authorize writes an error response and returns false unless the request has |perm| on |org|/|repo|.
+func (h *reposAPIHandler) authorize(logger *logrus.Entry, respWr http.ResponseWriter, req *http.Request, org, repo string, perm Permission) bool {
+	if perm == PermissionWrite && h.readOnly {
+		writeAPIError(respWr, http.StatusForbidden, ErrReadOnly)
+		return false
+	}
+
+	if h.auth == nil {
+		return true
+	}
+
+	err := h.auth.Authorize(req.Context(), httpBearerToken(req), org, repo, perm)
+	if errors.Is(err, ErrPermissionDenied) {
+		logger.Info(err.Error())
+		writeAPIError(respWr, http.StatusForbidden, err)
+		return false
+	} else if errors.Is(err, ErrUnauthenticated) {
+		logger.Info(err.Error())
+		writeAPIError(respWr, http.StatusUnauthorized, err)
+		return false
+	} else if err != nil {
+		logger.WithError(err).Error("error occurred authorizing request")
+		writeAPIError(respWr, http.StatusInternalServerError, errors.New("failed to authorize request"))
+		return false
+	}
+
+	return true
+}
+
+func (h *reposAPIHandler) listRepos(logger *logrus.Entry, respWr http.ResponseWriter, req *http.Request) {
+	repos, err := h.csCache.List()
+	if err != nil {
+		logger.WithError(err).Error("failed to list repositories")
+		writeAPIError(respWr, http.StatusInternalServerError, errors.New("failed to list repositories"))
+		return
+	}
+
+	resp := ListReposResponse{Repos: []RepoInfo{}}
+	for _, r := range repos {
+		tokens := strings.Split(r, "/")
+		org, repo := tokens[0], tokens[1]
+
+		if h.auth != nil && h.auth.Authorize(req.Context(), httpBearerToken(req), org, repo, PermissionRead) != nil {
+			continue
+		}
+
+		resp.Repos = append(resp.Repos, RepoInfo{Org: org, Name: repo})
+	}
+
+	writeAPIResponse(logger, respWr, http.StatusOK, resp)
+}
+
+func (h *reposAPIHandler) getRepo(logger *logrus.Entry, respWr http.ResponseWriter, req *http.Request, org, repo string) {
+	if !h.authorize(logger, respWr, req, org, repo, PermissionRead) {
+		return
+	}
+
+	cs, err := h.csCache.Get(org, repo, types.Format_Default.VersionString(), false)
+	if errors.Is(err, ErrRepoNotFound) {
+		writeAPIError(respWr, http.StatusNotFound, err)
+		return
+	} else if errors.Is(err, ErrInvalidRepoName) {
+		writeAPIError(respWr, http.StatusBadRequest, err)
+		return
+	} else if err != nil {
+		logger.WithError(err).Error("failed to get chunkstore")
+		writeAPIError(respWr, http.StatusInternalServerError, errors.New("failed to get repository"))
+		return
+	}
+
+	size, err := h.csCache.Size(req.Context(), org, repo, cs)
+	if err != nil {
+		logger.WithError(err).Error("failed to get repository size")
+		writeAPIError(respWr, http.StatusInternalServerError, errors.New("failed to get repository"))
+		return
+	}
+
+	root, err := cs.Root(req.Context())
+	if err != nil {
+		logger.WithError(err).Error("failed to get repository root")
+		writeAPIError(respWr, http.StatusInternalServerError, errors.New("failed to get repository"))
+		return
+	}
+
+	quota := h.quotas.Limit(org, repo)
+	writeAPIResponse(logger, respWr, http.StatusOK, RepoInfo{Org: org, Name: repo, Size: &size, Root: root.String(), Quota: &quota})
+}
+
+func (h *reposAPIHandler) createRepo(logger *logrus.Entry, respWr http.ResponseWriter, req *http.Request, org, repo string) {
+	if !h.authorize(logger, respWr, req, org, repo, PermissionWrite) {
+		return
+	}
+
+	_, err := h.csCache.Create(org, repo, types.Format_Default.VersionString())
+	if errors.Is(err, ErrRepoExists) {
+		writeAPIError(respWr, http.StatusConflict, err)
+		return
+	} else if errors.Is(err, ErrInvalidRepoName) {
+		writeAPIError(respWr, http.StatusBadRequest, err)
+		return
+	} else if err != nil {
+		logger.WithError(err).Error("failed to create repository")
+		writeAPIError(respWr, http.StatusInternalServerError, errors.New("failed to create repository"))
+		return
+	}
+
+	logger.Infof("created repository %s/%s", org, repo)
+	writeAPIResponse(logger, respWr, http.StatusCreated, RepoInfo{Org: org, Name: repo})
+}
+
+func writeAPIResponse(logger *logrus.Entry, respWr http.ResponseWriter, statusCode int, resp interface{}) {
+	respWr.Header().Set("Content-Type", "application/json")
+	respWr.WriteHeader(statusCode)
+
+	err := json.NewEncoder(respWr).Encode(resp)
+	if err != nil {
+		logger.WithError(err).Error("failed to write response")
+	}
+}
+
+func writeAPIError(respWr http.ResponseWriter, statusCode int, err error) {
+	respWr.Header().Set("Content-Type", "application/json")
+	respWr.WriteHeader(statusCode)
+	_ = json.NewEncoder(respWr).Encode(apiError{Error: err.Error()})
+}
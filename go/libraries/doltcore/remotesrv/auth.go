@@ -0,0 +1,380 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesrv
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ed25519"
+	"google.golang.org/grpc/metadata"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/creds"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+)
+
+// Permission is the access a request has to a repository.
+type Permission int
+
+const (
+	PermissionNone Permission = iota
+	PermissionRead
+	PermissionWrite
+)
+
+func (p Permission) String() string {
+	switch p {
+	case PermissionRead:
+		return "read"
+	case PermissionWrite:
+		return "write"
+	default:
+		return "none"
+	}
+}
+
+func parsePermission(str string) (Permission, error) {
+	switch strings.ToLower(strings.TrimSpace(str)) {
+	case "none":
+		return PermissionNone, nil
+	case "read":
+		return PermissionRead, nil
+	case "write":
+		return PermissionWrite, nil
+	default:
+		return PermissionNone, fmt.Errorf("invalid permission '%s'. should be one of none, read or write", str)
+	}
+}
+
+var ErrUnauthenticated = errors.New("missing or invalid credentials")
+var ErrPermissionDenied = errors.New("permission denied")
+
+// Authorizer authenticates the bearer tokens sent with requests, and authorizes them to access repositories.
+type Authorizer interface {
+	// Authorize returns nil if |token| grants |perm| on the repository |org|/|repo|, ErrUnauthenticated if |token| is
+	// not valid, and ErrPermissionDenied if it is valid but does not grant |perm|. |token| is empty when the request
+	// did not carry one.
+	Authorize(ctx context.Context, token, org, repo string, perm Permission) error
+}
+
+// jwtLeeway is the clock skew allowed when validating the expiry of the tokens signed with dolt credentials.
+const jwtLeeway = 30 * time.Second
+
+// repoPermissions maps repositories to the permission granted on them. Keys are either "<org>/<repo>", "<org>/*" or
+// "*", and the most specific key matching a repository applies.
+type repoPermissions map[string]Permission
+
+func (rp repoPermissions) get(org, repo string) Permission {
+	if p, ok := rp[org+"/"+repo]; ok {
+		return p
+	}
+	if p, ok := rp[org+"/*"]; ok {
+		return p
+	}
+	return rp["*"]
+}
+
+func parseRepoPermissions(m map[string]string) (repoPermissions, error) {
+	rp := make(repoPermissions, len(m))
+	for k, v := range m {
+		if k != "*" && len(strings.Split(k, "/")) != 2 {
+			return nil, fmt.Errorf("invalid repository '%s'. should be <org>/<repo>, <org>/* or *", k)
+		}
+
+		p, err := parsePermission(v)
+		if err != nil {
+			return nil, err
+		}
+		rp[k] = p
+	}
+
+	return rp, nil
+}
+
+// credentialsFile is the JSON format of the file a FileAuthorizer is loaded from.
+type credentialsFile struct {
+	Users     []credentialsFileUser `json:"users"`
+	Anonymous map[string]string     `json:"anonymous"`
+}
+
+// credentialsFileUser is a user of a credentials file. A user authenticates either with a static |Token|, or with a
+// token signed by the dolt credentials whose public key is |PublicKey|, as printed by `dolt creds new`, or is read
+// from the .jwk file |JWKFile|.
+type credentialsFileUser struct {
+	Name      string            `json:"name"`
+	Token     string            `json:"token"`
+	PublicKey string            `json:"public_key"`
+	JWKFile   string            `json:"jwk_file"`
+	Repos     map[string]string `json:"repos"`
+}
+
+type authUser struct {
+	name  string
+	token []byte
+	repos repoPermissions
+}
+
+// FileAuthorizer is an Authorizer which validates tokens against the users of a local credentials file.
+type FileAuthorizer struct {
+	tokenUsers []*authUser
+	keyUsers   map[string]*authUser
+	pubKeys    map[string]ed25519.PublicKey
+	anonymous  repoPermissions
+	audience   string
+}
+
+var _ Authorizer = (*FileAuthorizer)(nil)
+
+// NewFileAuthorizer loads a FileAuthorizer from the credentials file at |path|. The .jwk files it references are
+// relative to the directory of the credentials file. Tokens signed with dolt credentials are only accepted when they
+// were issued for |audience|.
+func NewFileAuthorizer(fs filesys.Filesys, path, audience string) (*FileAuthorizer, error) {
+	if audience == "" {
+		return nil, errors.New("a jwt audience is required to authorize tokens signed with dolt credentials")
+	}
+
+	data, err := fs.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cf credentialsFile
+	err = json.Unmarshal(data, &cf)
+	if err != nil {
+		return nil, fmt.Errorf("invalid credentials file %s: %w", path, err)
+	}
+
+	auth := &FileAuthorizer{
+		keyUsers: make(map[string]*authUser),
+		pubKeys:  make(map[string]ed25519.PublicKey),
+		audience: audience,
+	}
+
+	auth.anonymous, err = parseRepoPermissions(cf.Anonymous)
+	if err != nil {
+		return nil, fmt.Errorf("invalid credentials file %s: anonymous: %w", path, err)
+	}
+
+	for i, u := range cf.Users {
+		name := u.Name
+		if name == "" {
+			name = "#" + strconv.Itoa(i)
+		}
+
+		repos, err := parseRepoPermissions(u.Repos)
+		if err != nil {
+			return nil, fmt.Errorf("invalid credentials file %s: user %s: %w", path, name, err)
+		}
+		au := &authUser{name: name, repos: repos}
+
+		var pub []byte
+		switch {
+		case u.Token != "" && (u.PublicKey != "" || u.JWKFile != ""), u.PublicKey != "" && u.JWKFile != "":
+			return nil, fmt.Errorf("invalid credentials file %s: user %s: only one of token, public_key and jwk_file may be set", path, name)
+		case u.Token != "":
+			au.token = []byte(u.Token)
+			auth.tokenUsers = append(auth.tokenUsers, au)
+			continue
+		case u.PublicKey != "":
+			pub, err = creds.B32CredsEncoding.DecodeString(u.PublicKey)
+			if err != nil {
+				return nil, fmt.Errorf("invalid credentials file %s: user %s: %w", path, name, creds.ErrBadB32CredsEncoding)
+			}
+		case u.JWKFile != "":
+			jwkPath := u.JWKFile
+			if !filepath.IsAbs(jwkPath) {
+				jwkPath = filepath.Join(filepath.Dir(path), jwkPath)
+			}
+
+			dc, err := creds.JWKCredsReadFromFile(fs, jwkPath)
+			if err != nil {
+				return nil, fmt.Errorf("invalid credentials file %s: user %s: failed to read %s: %w", path, name, jwkPath, err)
+			}
+			pub = dc.PubKey
+		default:
+			return nil, fmt.Errorf("invalid credentials file %s: user %s: one of token, public_key and jwk_file must be set", path, name)
+		}
+
+		if len(pub) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid credentials file %s: user %s: invalid public key", path, name)
+		}
+
+		kid := creds.PubKeyToKIDStr(pub)
+		auth.keyUsers[kid] = au
+		auth.pubKeys[kid] = ed25519.PublicKey(pub)
+	}
+
+	return auth, nil
+}
+
+// Authorize implements Authorizer.
+func (auth *FileAuthorizer) Authorize(ctx context.Context, token, org, repo string, perm Permission) error {
+	if perm <= auth.anonymous.get(org, repo) {
+		return nil
+	}
+
+	if token == "" {
+		return ErrUnauthenticated
+	}
+
+	u, err := auth.authenticate(token)
+	if err != nil {
+		return err
+	}
+
+	if perm > u.repos.get(org, repo) {
+		return fmt.Errorf("%w: user %s does not have %s access to %s/%s", ErrPermissionDenied, u.name, perm, org, repo)
+	}
+
+	return nil
+}
+
+func (auth *FileAuthorizer) authenticate(token string) (*authUser, error) {
+	if tok, err := jwt.ParseSigned(token); err == nil {
+		if len(tok.Headers) == 0 {
+			return nil, ErrUnauthenticated
+		}
+
+		kid := tok.Headers[0].KeyID
+		u, ok := auth.keyUsers[kid]
+		if !ok {
+			return nil, ErrUnauthenticated
+		}
+
+		var claims jwt.Claims
+		err = tok.Claims(auth.pubKeys[kid], &claims)
+		if err != nil {
+			return nil, ErrUnauthenticated
+		}
+
+		err = claims.ValidateWithLeeway(jwt.Expected{Audience: jwt.Audience{auth.audience}, Time: time.Now()}, jwtLeeway)
+		if err != nil {
+			return nil, ErrUnauthenticated
+		}
+
+		return u, nil
+	}
+
+	for _, u := range auth.tokenUsers {
+		if subtle.ConstantTimeCompare(u.token, []byte(token)) == 1 {
+			return u, nil
+		}
+	}
+
+	return nil, ErrUnauthenticated
+}
+
+const bearerPrefix = "bearer "
+
+func parseBearerToken(authorization string) string {
+	if len(authorization) < len(bearerPrefix) || !strings.EqualFold(authorization[:len(bearerPrefix)], bearerPrefix) {
+		return ""
+	}
+
+	return strings.TrimSpace(authorization[len(bearerPrefix):])
+}
+
+// grpcBearerToken returns the bearer token in the authorization metadata of a grpc request, or "" if it has none.
+func grpcBearerToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	for _, v := range md.Get("authorization") {
+		if token := parseBearerToken(v); token != "" {
+			return token
+		}
+	}
+
+	return ""
+}
+
+// httpBearerToken returns the bearer token in the Authorization header of an http request, or "" if it has none.
+func httpBearerToken(req *http.Request) string {
+	return parseBearerToken(req.Header.Get("Authorization"))
+}
+
+const (
+	signedUrlTTL          = time.Hour
+	signedUrlRefreshAfter = 30 * time.Minute
+
+	expiresParam   = "Expires"
+	signatureParam = "Signature"
+)
+
+// urlSigner signs the urls of the table files handed out by the grpc server, so that the http server can authorize
+// the requests for them without the client sending its credentials along.
+type urlSigner struct {
+	key []byte
+}
+
+func newUrlSigner() (*urlSigner, error) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &urlSigner{key: key}, nil
+}
+
+func (s *urlSigner) signature(path string, perm Permission, expires int64) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(fmt.Sprintf("%s\n%s\n%d", perm, path, expires)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// sign returns |rawUrl| with a signature granting |perm| on it until |signedUrlTTL| after |now|.
+func (s *urlSigner) sign(rawUrl string, perm Permission, now time.Time) (string, error) {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return "", err
+	}
+
+	expires := now.Add(signedUrlTTL).Unix()
+	q := u.Query()
+	q.Set(expiresParam, strconv.FormatInt(expires, 10))
+	q.Set(signatureParam, s.signature(u.Path, perm, expires))
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+// verify returns whether |u| carries an unexpired signature granting |perm| on it.
+func (s *urlSigner) verify(u *url.URL, perm Permission, now time.Time) bool {
+	q := u.Query()
+	expires, err := strconv.ParseInt(q.Get(expiresParam), 10, 64)
+	if err != nil || now.Unix() > expires {
+		return false
+	}
+
+	expected := s.signature(u.Path, perm, expires)
+	return hmac.Equal([]byte(expected), []byte(q.Get(signatureParam)))
+}
@@ -0,0 +1,151 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesrv
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ed25519"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/creds"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+	"github.com/dolthub/dolt/go/libraries/utils/osutil"
+)
+
+func TestFileAuthorizer(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(osutil.FileSystemRoot, "auth")
+
+	keyCreds, err := creds.GenerateCredentials()
+	require.NoError(t, err)
+	jwkCreds, err := creds.GenerateCredentials()
+	require.NoError(t, err)
+	otherCreds, err := creds.GenerateCredentials()
+	require.NoError(t, err)
+
+	var jwk bytes.Buffer
+	require.NoError(t, creds.JWKCredsWrite(&jwk, jwkCreds))
+
+	credsFile := fmt.Sprintf(`{
+  "anonymous": {"public/*": "read"},
+  "users": [
+    {"name": "ci", "token": "s3cr3t", "repos": {"org/repo": "write", "org/*": "read"}},
+    {"name": "key", "public_key": "%s", "repos": {"*": "write", "org/private": "none"}},
+    {"name": "jwk", "jwk_file": "keys/jwk.jwk", "repos": {"org/repo": "read"}}
+  ]
+}`, keyCreds.PubKeyBase32Str())
+
+	fs := filesys.NewInMemFS([]string{dir}, map[string][]byte{
+		filepath.Join(dir, "creds.json"):      []byte(credsFile),
+		filepath.Join(dir, "keys", "jwk.jwk"): jwk.Bytes(),
+	}, dir)
+
+	_, err = NewFileAuthorizer(fs, filepath.Join(dir, "creds.json"), "")
+	require.Error(t, err)
+
+	auth, err := NewFileAuthorizer(fs, filepath.Join(dir, "creds.json"), creds.DefaultJWTAudience)
+	require.NoError(t, err)
+
+	bearer := func(dc creds.DoltCreds) string {
+		md, err := dc.GetRequestMetadata(ctx)
+		require.NoError(t, err)
+		return parseBearerToken(md["authorization"])
+	}
+
+	// bearerFor signs a token with |dc| like dolt does, but issued for |audience|
+	bearerFor := func(dc creds.DoltCreds, audience ...string) string {
+		key := jose.SigningKey{Algorithm: jose.EdDSA, Key: ed25519.PrivateKey(dc.PrivKey)}
+		signer, err := jose.NewSigner(key, &jose.SignerOptions{ExtraHeaders: map[jose.HeaderKey]interface{}{
+			creds.JWTKIDHeader: dc.KeyIDBase32Str(),
+		}})
+		require.NoError(t, err)
+
+		token, err := jwt.Signed(signer).Claims(jwt.Claims{
+			Audience: audience,
+			Expiry:   jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		}).CompactSerialize()
+		require.NoError(t, err)
+		return token
+	}
+
+	tests := []struct {
+		name     string
+		token    string
+		org      string
+		repo     string
+		perm     Permission
+		expected error
+	}{
+		{"anonymous read of public repo", "", "public", "repo", PermissionRead, nil},
+		{"anonymous write of public repo", "", "public", "repo", PermissionWrite, ErrUnauthenticated},
+		{"anonymous read of other repo", "", "org", "repo", PermissionRead, ErrUnauthenticated},
+		{"invalid token", "wrong", "org", "repo", PermissionRead, ErrUnauthenticated},
+		{"token write", "s3cr3t", "org", "repo", PermissionWrite, nil},
+		{"token read of org", "s3cr3t", "org", "other", PermissionRead, nil},
+		{"token write of org", "s3cr3t", "org", "other", PermissionWrite, ErrPermissionDenied},
+		{"token read of other org", "s3cr3t", "other", "repo", PermissionRead, ErrPermissionDenied},
+		{"public key write", bearer(keyCreds), "other", "repo", PermissionWrite, nil},
+		{"public key denied repo", bearer(keyCreds), "org", "private", PermissionRead, ErrPermissionDenied},
+		{"jwk read", bearer(jwkCreds), "org", "repo", PermissionRead, nil},
+		{"jwk write", bearer(jwkCreds), "org", "repo", PermissionWrite, ErrPermissionDenied},
+		{"unknown key", bearer(otherCreds), "org", "repo", PermissionRead, ErrUnauthenticated},
+		{"public key for audience", bearerFor(keyCreds, creds.DefaultJWTAudience), "other", "repo", PermissionWrite, nil},
+		{"public key for other audience", bearerFor(keyCreds, "other-remote.example.com"), "other", "repo", PermissionWrite, ErrUnauthenticated},
+		{"public key without audience", bearerFor(keyCreds), "other", "repo", PermissionWrite, ErrUnauthenticated},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := auth.Authorize(ctx, test.token, test.org, test.repo, test.perm)
+			if test.expected == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, test.expected)
+			}
+		})
+	}
+}
+
+func TestUrlSigner(t *testing.T) {
+	signer, err := newUrlSigner()
+	require.NoError(t, err)
+
+	now := time.Now()
+	signed, err := signer.sign("http://localhost:80/org/repo/abc", PermissionRead, now)
+	require.NoError(t, err)
+
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+	assert.True(t, signer.verify(u, PermissionRead, now))
+	assert.False(t, signer.verify(u, PermissionWrite, now))
+	assert.False(t, signer.verify(u, PermissionRead, now.Add(signedUrlTTL+time.Minute)))
+
+	other, err := newUrlSigner()
+	require.NoError(t, err)
+	assert.False(t, other.verify(u, PermissionRead, now))
+
+	u.Path = "/org/other/abc"
+	assert.False(t, signer.verify(u, PermissionRead, now))
+}
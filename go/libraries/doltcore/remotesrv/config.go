@@ -0,0 +1,221 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesrv
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/creds"
+)
+
+const (
+	defaultGRPCPort              = 50051
+	defaultHTTPPort              = 80
+	defaultMaxMessageSize        = 128 * 1024 * 1024
+	defaultShutdownTimeoutMillis = 30 * 1000
+	defaultLogLevel              = "info"
+	defaultLogFormat             = "text"
+	defaultMetricsHost           = "localhost"
+)
+
+// Config is the configuration of a Server, which is read from a yaml file.
+type Config struct {
+	// DataDir is the directory the repositories are stored in, as <org>/<repo> directories of table files.
+	DataDir string `yaml:"data_dir"`
+	// ReadOnly rejects every request which would modify a repository.
+	ReadOnly bool `yaml:"read_only"`
+	// AutoCreateRepos creates repositories which do not exist when they are first accessed by a caller which may
+	// write to them, such as by a push. Otherwise, repositories must be created with the repository api.
+	AutoCreateRepos bool `yaml:"auto_create_repos"`
+	// AuthFile is the credentials file requests are authorized against. When it is empty, every request is allowed.
+	AuthFile string `yaml:"auth_file"`
+	// JWTAudience is the audience the tokens signed with dolt credentials must be issued for. It defaults to the
+	// audience of the tokens dolt signs.
+	JWTAudience string `yaml:"jwt_audience"`
+	// LogLevel is one of trace, debug, info, warning, error and fatal.
+	LogLevel string `yaml:"log_level"`
+	// LogFormat is either text or json.
+	LogFormat string `yaml:"log_format"`
+	// ShutdownTimeoutMillis is how long the server waits for requests in flight to finish when it is stopped.
+	ShutdownTimeoutMillis uint64 `yaml:"shutdown_timeout_millis"`
+
+	GRPC    GRPCConfig    `yaml:"grpc"`
+	HTTP    HTTPConfig    `yaml:"http"`
+	TLS     TLSConfig     `yaml:"tls"`
+	Quotas  QuotaConfig   `yaml:"quotas"`
+	Metrics MetricsConfig `yaml:"metrics"`
+}
+
+// GRPCConfig configures the grpc server implementing the remote chunk store api.
+type GRPCConfig struct {
+	Host           string `yaml:"host"`
+	Port           int    `yaml:"port"`
+	MaxMessageSize int    `yaml:"max_message_size"`
+}
+
+// HTTPConfig configures the http server which serves the table files of the repositories.
+type HTTPConfig struct {
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+	// AdvertiseHost is the host, and optionally port, of the http server in the urls of the table files handed out
+	// by the grpc server. It defaults to localhost and the http port.
+	AdvertiseHost string `yaml:"advertise_host"`
+	// MaxUploadSize is the largest table file which may be uploaded, in bytes. Zero does not limit uploads.
+	MaxUploadSize uint64 `yaml:"max_upload_size"`
+}
+
+// TLSConfig configures TLS for both the grpc and http servers. TLS is used when both Cert and Key are set.
+type TLSConfig struct {
+	Cert string `yaml:"cert"`
+	Key  string `yaml:"key"`
+}
+
+// QuotaConfig limits the total size of the table files of repositories, in bytes. Zero does not limit them.
+type QuotaConfig struct {
+	// MaxRepoSize is the limit of repositories without a limit of their own.
+	MaxRepoSize uint64 `yaml:"max_repo_size"`
+	// Repos are the limits of individual repositories, keyed by <org>/<repo>.
+	Repos map[string]uint64 `yaml:"repos"`
+}
+
+// MetricsConfig configures the http server exposing prometheus metrics at /metrics. It is only started when Port is
+// positive.
+type MetricsConfig struct {
+	Host   string            `yaml:"host"`
+	Port   int               `yaml:"port"`
+	Labels map[string]string `yaml:"labels"`
+}
+
+// DefaultConfig returns the configuration of a server without a config file.
+func DefaultConfig() Config {
+	return Config{
+		DataDir:               ".",
+		JWTAudience:           creds.DefaultJWTAudience,
+		LogLevel:              defaultLogLevel,
+		LogFormat:             defaultLogFormat,
+		ShutdownTimeoutMillis: defaultShutdownTimeoutMillis,
+		GRPC: GRPCConfig{
+			Port:           defaultGRPCPort,
+			MaxMessageSize: defaultMaxMessageSize,
+		},
+		HTTP: HTTPConfig{
+			Port: defaultHTTPPort,
+		},
+		Metrics: MetricsConfig{
+			Host: defaultMetricsHost,
+			Port: -1,
+		},
+	}
+}
+
+// ReadConfig reads a Config from the yaml |data|. Fields missing from |data| have their default values.
+func ReadConfig(data []byte) (Config, error) {
+	cfg := DefaultConfig()
+	err := yaml.UnmarshalStrict(data, &cfg)
+	if err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+// String returns the yaml representation of the config.
+func (cfg Config) String() string {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return "Failed to marshal as yaml: " + err.Error()
+	}
+
+	return string(data)
+}
+
+// Validate returns an error if the config is not valid.
+func (cfg Config) Validate() error {
+	if cfg.GRPC.Port < 0 || cfg.GRPC.Port > 65535 {
+		return fmt.Errorf("invalid grpc port %d", cfg.GRPC.Port)
+	}
+	if cfg.HTTP.Port < 0 || cfg.HTTP.Port > 65535 {
+		return fmt.Errorf("invalid http port %d", cfg.HTTP.Port)
+	}
+	if cfg.Metrics.Port > 65535 {
+		return fmt.Errorf("invalid metrics port %d", cfg.Metrics.Port)
+	}
+	if cfg.AuthFile != "" && cfg.JWTAudience == "" {
+		return errors.New("authorizing requests requires a jwt audience")
+	}
+	if cfg.GRPC.MaxMessageSize <= 0 {
+		return fmt.Errorf("invalid grpc max message size %d", cfg.GRPC.MaxMessageSize)
+	}
+	if (cfg.TLS.Cert == "") != (cfg.TLS.Key == "") {
+		return errors.New("tls requires both a cert and a key")
+	}
+	if _, err := logrus.ParseLevel(cfg.LogLevel); err != nil {
+		return fmt.Errorf("invalid log level '%s'", cfg.LogLevel)
+	}
+	if cfg.LogFormat != "text" && cfg.LogFormat != "json" {
+		return fmt.Errorf("invalid log format '%s'. should be text or json", cfg.LogFormat)
+	}
+	for repo := range cfg.Quotas.Repos {
+		tokens := strings.Split(repo, "/")
+		if len(tokens) != 2 || validateRepoName(tokens[0]) != nil || validateRepoName(tokens[1]) != nil {
+			return fmt.Errorf("invalid repository '%s' in quotas. should be <org>/<repo>", repo)
+		}
+	}
+
+	return nil
+}
+
+// TLSEnabled returns whether the servers use TLS.
+func (cfg Config) TLSEnabled() bool {
+	return cfg.TLS.Cert != "" && cfg.TLS.Key != ""
+}
+
+// HTTPScheme returns the scheme of the urls of the http server.
+func (cfg Config) HTTPScheme() string {
+	if cfg.TLSEnabled() {
+		return "https"
+	}
+
+	return "http"
+}
+
+// HTTPAdvertiseHost returns the host of the http server in the urls handed out by the grpc server.
+func (cfg Config) HTTPAdvertiseHost() string {
+	if cfg.HTTP.AdvertiseHost != "" {
+		return cfg.HTTP.AdvertiseHost
+	}
+
+	return fmt.Sprintf("localhost:%d", cfg.HTTP.Port)
+}
+
+// ShutdownTimeout returns how long the server waits for requests in flight to finish when it is stopped.
+func (cfg Config) ShutdownTimeout() time.Duration {
+	return time.Duration(cfg.ShutdownTimeoutMillis) * time.Millisecond
+}
+
+// Limit returns the quota of the repository |org|/|repo|, or zero if it is not limited.
+func (qc QuotaConfig) Limit(org, repo string) uint64 {
+	if limit, ok := qc.Repos[org+"/"+repo]; ok {
+		return limit
+	}
+
+	return qc.MaxRepoSize
+}
@@ -0,0 +1,109 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesrv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadConfig(t *testing.T) {
+	cfg, err := ReadConfig([]byte(`
+data_dir: /var/lib/remotesrv
+read_only: true
+auto_create_repos: true
+log_format: json
+shutdown_timeout_millis: 5000
+http:
+  port: 8080
+  advertise_host: remotes.example.com
+  max_upload_size: 1048576
+tls:
+  cert: cert.pem
+  key: key.pem
+quotas:
+  max_repo_size: 100
+  repos:
+    org/big: 1000
+metrics:
+  port: 9090
+  labels:
+    instance: test
+`))
+	require.NoError(t, err)
+	require.NoError(t, cfg.Validate())
+
+	assert.Equal(t, "/var/lib/remotesrv", cfg.DataDir)
+	assert.True(t, cfg.ReadOnly)
+	assert.True(t, cfg.AutoCreateRepos)
+	assert.Equal(t, "info", cfg.LogLevel)
+	assert.Equal(t, "json", cfg.LogFormat)
+	assert.Equal(t, 5*time.Second, cfg.ShutdownTimeout())
+	assert.Equal(t, 50051, cfg.GRPC.Port)
+	assert.Equal(t, 8080, cfg.HTTP.Port)
+	assert.Equal(t, "remotes.example.com", cfg.HTTPAdvertiseHost())
+	assert.Equal(t, uint64(1048576), cfg.HTTP.MaxUploadSize)
+	assert.True(t, cfg.TLSEnabled())
+	assert.Equal(t, "https", cfg.HTTPScheme())
+	assert.Equal(t, uint64(100), cfg.Quotas.Limit("org", "small"))
+	assert.Equal(t, uint64(1000), cfg.Quotas.Limit("org", "big"))
+	assert.Equal(t, 9090, cfg.Metrics.Port)
+	assert.Equal(t, map[string]string{"instance": "test"}, cfg.Metrics.Labels)
+
+	cfg, err = ReadConfig([]byte("data_dir: .\nunknown_field: 1\n"))
+	assert.Error(t, err)
+
+	cfg, err = ReadConfig(nil)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultConfig(), cfg)
+	assert.Equal(t, "localhost:80", cfg.HTTPAdvertiseHost())
+	assert.Equal(t, "http", cfg.HTTPScheme())
+	assert.Equal(t, uint64(0), cfg.Quotas.Limit("org", "repo"))
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cfg *Config)
+		valid  bool
+	}{
+		{"default", func(cfg *Config) {}, true},
+		{"negative grpc port", func(cfg *Config) { cfg.GRPC.Port = -1 }, false},
+		{"http port too large", func(cfg *Config) { cfg.HTTP.Port = 65536 }, false},
+		{"zero max message size", func(cfg *Config) { cfg.GRPC.MaxMessageSize = 0 }, false},
+		{"cert without key", func(cfg *Config) { cfg.TLS.Cert = "cert.pem" }, false},
+		{"invalid log level", func(cfg *Config) { cfg.LogLevel = "loud" }, false},
+		{"invalid log format", func(cfg *Config) { cfg.LogFormat = "xml" }, false},
+		{"auth file without jwt audience", func(cfg *Config) { cfg.AuthFile, cfg.JWTAudience = "creds.json", "" }, false},
+		{"quota for org/repo", func(cfg *Config) { cfg.Quotas.Repos = map[string]uint64{"org/repo": 1} }, true},
+		{"quota for org", func(cfg *Config) { cfg.Quotas.Repos = map[string]uint64{"org": 1} }, false},
+		{"quota for nested repo", func(cfg *Config) { cfg.Quotas.Repos = map[string]uint64{"org/repo/x": 1} }, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			test.modify(&cfg)
+			if test.valid {
+				assert.NoError(t, cfg.Validate())
+			} else {
+				assert.Error(t, cfg.Validate())
+			}
+		})
+	}
+}
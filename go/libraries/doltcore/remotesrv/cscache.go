@@ -0,0 +1,215 @@
+// Copyright 2019 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesrv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+	"github.com/dolthub/dolt/go/store/nbs"
+)
+
+const (
+	defaultMemTableSize = 128 * 1024 * 1024
+)
+
+var ErrRepoNotFound = errors.New("repository not found")
+var ErrRepoExists = errors.New("repository already exists")
+var ErrInvalidRepoName = errors.New("invalid repository name")
+
+// DBCache holds the stores of the repositories of a server. Each repository is a directory of table files, at
+// <org>/<repo> within the working directory of the cache's filesystem.
+type DBCache struct {
+	mu  *sync.Mutex
+	dbs map[string]*nbs.NomsBlockStore
+
+	fs filesys.Filesys
+}
+
+func NewLocalCSCache(filesys filesys.Filesys) *DBCache {
+	return &DBCache{
+		&sync.Mutex{},
+		make(map[string]*nbs.NomsBlockStore),
+		filesys,
+	}
+}
+
+func validateRepoName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, "/\\") {
+		return fmt.Errorf("%w: '%s'", ErrInvalidRepoName, name)
+	}
+
+	return nil
+}
+
+// Path returns the absolute path of the directory of the repository |org|/|repo|.
+func (cache *DBCache) Path(org, repo string) (string, error) {
+	if err := validateRepoName(org); err != nil {
+		return "", err
+	}
+	if err := validateRepoName(repo); err != nil {
+		return "", err
+	}
+
+	return cache.fs.Abs(filepath.Join(org, repo))
+}
+
+// Get returns the store of the repository |org|/|repo|. If the repository does not exist, it is created when |create|
+// is true, and ErrRepoNotFound is returned otherwise.
+func (cache *DBCache) Get(org, repo, nbfVerStr string, create bool) (*nbs.NomsBlockStore, error) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	id := filepath.Join(org, repo)
+
+	if cs, ok := cache.dbs[id]; ok {
+		return cs, nil
+	}
+
+	return cache.open(org, repo, nbfVerStr, create, false)
+}
+
+// Create creates the repository |org|/|repo| and returns its store. ErrRepoExists is returned if it already exists.
+func (cache *DBCache) Create(org, repo, nbfVerStr string) (*nbs.NomsBlockStore, error) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	return cache.open(org, repo, nbfVerStr, true, true)
+}
+
+func (cache *DBCache) open(org, repo, nbfVerStr string, create, mustCreate bool) (*nbs.NomsBlockStore, error) {
+	path, err := cache.Path(org, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	id := filepath.Join(org, repo)
+	exists, isDir := cache.fs.Exists(id)
+	if exists && !isDir {
+		return nil, fmt.Errorf("%w: '%s' is not a directory", ErrInvalidRepoName, id)
+	} else if exists && mustCreate {
+		return nil, fmt.Errorf("%w: %s/%s", ErrRepoExists, org, repo)
+	} else if !exists && !create {
+		return nil, fmt.Errorf("%w: %s/%s", ErrRepoNotFound, org, repo)
+	}
+
+	err = cache.fs.MkDirs(id)
+	if err != nil {
+		return nil, err
+	}
+
+	newCS, err := nbs.NewLocalStore(context.TODO(), nbfVerStr, path, defaultMemTableSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// clients read chunks from byte ranges of table files, and can't read those of versioned table files
+	err = newCS.ConvertToLegacyTableFiles(context.TODO())
+	if err != nil {
+		newCS.Close()
+		return nil, err
+	}
+
+	cache.dbs[id] = newCS
+
+	return newCS, nil
+}
+
+// List returns the repositories of the cache, as <org>/<repo>, in order.
+func (cache *DBCache) List() ([]string, error) {
+	var orgs []string
+	err := cache.fs.Iter(".", false, func(path string, size int64, isDir bool) (stop bool) {
+		if isDir {
+			orgs = append(orgs, filepath.Base(path))
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var repos []string
+	for _, org := range orgs {
+		if validateRepoName(org) != nil {
+			continue
+		}
+
+		err = cache.fs.Iter(org, false, func(path string, size int64, isDir bool) (stop bool) {
+			if isDir && validateRepoName(filepath.Base(path)) == nil {
+				repos = append(repos, org+"/"+filepath.Base(path))
+			}
+			return false
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(repos)
+	return repos, nil
+}
+
+// Size returns the total size of the table files of |cs|, the store of the repository |org|/|repo|.
+func (cache *DBCache) Size(ctx context.Context, org, repo string, cs *nbs.NomsBlockStore) (uint64, error) {
+	path, err := cache.Path(org, repo)
+	if err != nil {
+		return 0, err
+	}
+
+	_, tfs, _, err := cs.Sources(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var size uint64
+	for _, tf := range tfs {
+		info, err := os.Stat(filepath.Join(path, tf.FileID()))
+		if err != nil {
+			return 0, err
+		}
+
+		size += uint64(info.Size())
+	}
+
+	return size, nil
+}
+
+// Close closes the stores of the cache.
+func (cache *DBCache) Close() error {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	var firstErr error
+	for id, cs := range cache.dbs {
+		if cs == nil {
+			continue
+		}
+
+		err := cs.Close()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(cache.dbs, id)
+	}
+
+	return firstErr
+}
@@ -0,0 +1,670 @@
+// Copyright 2019 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesrv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	remotesapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/remotesapi/v1alpha1"
+	"github.com/dolthub/dolt/go/libraries/doltcore/remotestorage"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/nbs"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+type RemoteChunkStore struct {
+	HttpHost   string
+	httpScheme string
+	csCache    *DBCache
+	bucket     string
+	auth       Authorizer
+	signer     *urlSigner
+	readOnly   bool
+	autoCreate bool
+	quotas     QuotaConfig
+	uploads    *expectedUploads
+	lgr        *logrus.Entry
+	remotesapi.UnimplementedChunkStoreServiceServer
+}
+
+// newRemoteChunkStore creates a RemoteChunkStore serving the repositories of |csCache|, whose table files are served by
+// the http server at |httpHost|. When |auth| is not nil, requests must be authorized by it, and the urls handed out
+// for table files are signed by |signer|.
+func newRemoteChunkStore(cfg Config, lgr *logrus.Entry, csCache *DBCache, auth Authorizer, signer *urlSigner, uploads *expectedUploads) *RemoteChunkStore {
+	return &RemoteChunkStore{
+		HttpHost:   cfg.HTTPAdvertiseHost(),
+		httpScheme: cfg.HTTPScheme(),
+		csCache:    csCache,
+		bucket:     "",
+		auth:       auth,
+		signer:     signer,
+		readOnly:   cfg.ReadOnly,
+		autoCreate: cfg.AutoCreateRepos,
+		quotas:     cfg.Quotas,
+		uploads:    uploads,
+		lgr:        lgr,
+	}
+}
+
+// authorize returns a grpc status error unless the request of |ctx| has |perm| on the repository |repoId|.
+func (rs *RemoteChunkStore) authorize(ctx context.Context, logger *logrus.Entry, repoId *remotesapi.RepoId, perm Permission) error {
+	if repoId == nil {
+		return status.Error(codes.InvalidArgument, "missing repo id")
+	}
+
+	if perm == PermissionWrite && rs.readOnly {
+		logger.Debug("rejected write to read only server")
+		return status.Error(codes.PermissionDenied, ErrReadOnly.Error())
+	}
+
+	if rs.auth == nil {
+		return nil
+	}
+
+	err := rs.auth.Authorize(ctx, grpcBearerToken(ctx), repoId.Org, repoId.RepoName, perm)
+	if errors.Is(err, ErrPermissionDenied) {
+		logger.Info(err.Error())
+		return status.Error(codes.PermissionDenied, err.Error())
+	} else if errors.Is(err, ErrUnauthenticated) {
+		logger.Info(err.Error())
+		return status.Error(codes.Unauthenticated, err.Error())
+	} else if err != nil {
+		logger.WithError(err).Errorf("error occurred authorizing %s access to %s/%s", perm, repoId.Org, repoId.RepoName)
+		return status.Error(codes.Internal, "Failed to authorize request")
+	}
+
+	return nil
+}
+
+func (rs *RemoteChunkStore) HasChunks(ctx context.Context, req *remotesapi.HasChunksRequest) (*remotesapi.HasChunksResponse, error) {
+	logger := getReqLogger(rs.lgr, "GRPC", "HasChunks")
+	defer func() { logger.Trace("finished") }()
+
+	if err := rs.authorize(ctx, logger, req.RepoId, PermissionRead); err != nil {
+		return nil, err
+	}
+
+	cs, err := rs.getStore(logger, req.RepoId)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Debugf("found repo %s/%s", req.RepoId.Org, req.RepoId.RepoName)
+
+	hashes, hashToIndex := remotestorage.ParseByteSlices(req.Hashes)
+
+	absent, err := cs.HasMany(ctx, hashes)
+
+	if err != nil {
+		return nil, status.Error(codes.Internal, "HasMany failure:"+err.Error())
+	}
+
+	indices := make([]int32, len(absent))
+
+	n := 0
+	for h := range absent {
+		indices[n] = int32(hashToIndex[h])
+		n++
+	}
+
+	//logger(fmt.Sprintf("missing chunks: %v", indices))
+
+	resp := &remotesapi.HasChunksResponse{
+		Absent: indices,
+	}
+
+	return resp, nil
+}
+
+func (rs *RemoteChunkStore) GetDownloadLocations(ctx context.Context, req *remotesapi.GetDownloadLocsRequest) (*remotesapi.GetDownloadLocsResponse, error) {
+	logger := getReqLogger(rs.lgr, "GRPC", "GetDownloadLocations")
+	defer func() { logger.Trace("finished") }()
+
+	if err := rs.authorize(ctx, logger, req.RepoId, PermissionRead); err != nil {
+		return nil, err
+	}
+
+	cs, err := rs.getStore(logger, req.RepoId)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Debugf("found repo %s/%s", req.RepoId.Org, req.RepoId.RepoName)
+
+	org := req.RepoId.Org
+	repoName := req.RepoId.RepoName
+	hashes, _ := remotestorage.ParseByteSlices(req.ChunkHashes)
+	locations, err := cs.GetChunkLocations(hashes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var locs []*remotesapi.DownloadLoc
+	for loc, hashToRange := range locations {
+		var ranges []*remotesapi.RangeChunk
+		for h, r := range hashToRange {
+			hCpy := h
+			ranges = append(ranges, &remotesapi.RangeChunk{Hash: hCpy[:], Offset: r.Offset, Length: r.Length})
+		}
+
+		url, err := rs.getDownloadUrl(logger, org, repoName, loc.String())
+		if err != nil {
+			logger.WithError(err).Error("Failed to sign request")
+			return nil, err
+		}
+
+		logger.Tracef("The URL is %s", url)
+
+		getRange := &remotesapi.HttpGetRange{Url: url, Ranges: ranges}
+		refreshAfter, refreshReq := rs.getRefreshInfo(req.RepoId, loc.String())
+		locs = append(locs, &remotesapi.DownloadLoc{
+			Location:       &remotesapi.DownloadLoc_HttpGetRange{HttpGetRange: getRange},
+			RefreshAfter:   refreshAfter,
+			RefreshRequest: refreshReq,
+		})
+	}
+
+	return &remotesapi.GetDownloadLocsResponse{Locs: locs}, nil
+}
+
+func (rs *RemoteChunkStore) StreamDownloadLocations(stream remotesapi.ChunkStoreService_StreamDownloadLocationsServer) error {
+	logger := getReqLogger(rs.lgr, "GRPC", "StreamDownloadLocations")
+	defer func() { logger.Trace("finished") }()
+
+	var repoID *remotesapi.RepoId
+	var cs *nbs.NomsBlockStore
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		if !proto.Equal(req.RepoId, repoID) {
+			if err := rs.authorize(stream.Context(), logger, req.RepoId, PermissionRead); err != nil {
+				return err
+			}
+
+			repoID = req.RepoId
+			cs, err = rs.getStore(logger, repoID)
+			if err != nil {
+				return err
+			}
+			logger.Debugf("found repo %s/%s", repoID.Org, repoID.RepoName)
+		}
+
+		org := req.RepoId.Org
+		repoName := req.RepoId.RepoName
+		hashes, _ := remotestorage.ParseByteSlices(req.ChunkHashes)
+		locations, err := cs.GetChunkLocations(hashes)
+		if err != nil {
+			return err
+		}
+
+		var locs []*remotesapi.DownloadLoc
+		for loc, hashToRange := range locations {
+			var ranges []*remotesapi.RangeChunk
+			for h, r := range hashToRange {
+				hCpy := h
+				ranges = append(ranges, &remotesapi.RangeChunk{Hash: hCpy[:], Offset: r.Offset, Length: r.Length})
+			}
+
+			url, err := rs.getDownloadUrl(logger, org, repoName, loc.String())
+			if err != nil {
+				logger.WithError(err).Error("Failed to sign request")
+				return err
+			}
+
+			logger.Tracef("The URL is %s", url)
+
+			getRange := &remotesapi.HttpGetRange{Url: url, Ranges: ranges}
+			refreshAfter, refreshReq := rs.getRefreshInfo(req.RepoId, loc.String())
+			locs = append(locs, &remotesapi.DownloadLoc{
+				Location:       &remotesapi.DownloadLoc_HttpGetRange{HttpGetRange: getRange},
+				RefreshAfter:   refreshAfter,
+				RefreshRequest: refreshReq,
+			})
+		}
+
+		if err := stream.Send(&remotesapi.GetDownloadLocsResponse{Locs: locs}); err != nil {
+			return err
+		}
+	}
+}
+
+func (rs *RemoteChunkStore) getDownloadUrl(logger *logrus.Entry, org, repoName, fileId string) (string, error) {
+	return rs.signUrl(fmt.Sprintf("%s://%s/%s/%s/%s", rs.httpScheme, rs.HttpHost, org, repoName, fileId), PermissionRead)
+}
+
+// signUrl signs |url| for |perm| when the requests to the http server must be authorized.
+func (rs *RemoteChunkStore) signUrl(url string, perm Permission) (string, error) {
+	if rs.signer == nil {
+		return url, nil
+	}
+
+	return rs.signer.sign(url, perm, time.Now())
+}
+
+// getRefreshInfo returns when the signed download url of |fileId| should be refreshed, and the request refreshing it,
+// or nils if download urls are not signed.
+func (rs *RemoteChunkStore) getRefreshInfo(repoId *remotesapi.RepoId, fileId string) (*timestamppb.Timestamp, *remotesapi.RefreshTableFileUrlRequest) {
+	if rs.signer == nil {
+		return nil, nil
+	}
+
+	return timestamppb.New(time.Now().Add(signedUrlRefreshAfter)), &remotesapi.RefreshTableFileUrlRequest{RepoId: repoId, FileId: fileId}
+}
+
+func (rs *RemoteChunkStore) RefreshTableFileUrl(ctx context.Context, req *remotesapi.RefreshTableFileUrlRequest) (*remotesapi.RefreshTableFileUrlResponse, error) {
+	logger := getReqLogger(rs.lgr, "GRPC", "RefreshTableFileUrl")
+	defer func() { logger.Trace("finished") }()
+
+	if err := rs.authorize(ctx, logger, req.RepoId, PermissionRead); err != nil {
+		return nil, err
+	}
+
+	if _, ok := hash.MaybeParse(req.FileId); !ok {
+		return nil, status.Error(codes.InvalidArgument, req.FileId+" is not a valid table file id")
+	}
+
+	url, err := rs.getDownloadUrl(logger, req.RepoId.Org, req.RepoId.RepoName, req.FileId)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get download url for "+req.FileId)
+	}
+
+	refreshAfter, _ := rs.getRefreshInfo(req.RepoId, req.FileId)
+	return &remotesapi.RefreshTableFileUrlResponse{Url: url, RefreshAfter: refreshAfter}, nil
+}
+
+func parseTableFileDetails(req *remotesapi.GetUploadLocsRequest) []*remotesapi.TableFileDetails {
+	tfd := req.GetTableFileDetails()
+
+	if len(tfd) == 0 {
+		_, hashToIdx := remotestorage.ParseByteSlices(req.TableFileHashes)
+
+		tfd = make([]*remotesapi.TableFileDetails, len(hashToIdx))
+		for h, i := range hashToIdx {
+			tfd[i] = &remotesapi.TableFileDetails{
+				Id:            h[:],
+				ContentLength: 0,
+				ContentHash:   nil,
+			}
+		}
+	}
+
+	return tfd
+}
+
+func (rs *RemoteChunkStore) GetUploadLocations(ctx context.Context, req *remotesapi.GetUploadLocsRequest) (*remotesapi.GetUploadLocsResponse, error) {
+	logger := getReqLogger(rs.lgr, "GRPC", "GetUploadLocations")
+	defer func() { logger.Trace("finished") }()
+
+	if err := rs.authorize(ctx, logger, req.RepoId, PermissionWrite); err != nil {
+		return nil, err
+	}
+
+	cs, err := rs.getOrCreateStore(logger, req.RepoId, types.Format_Default.VersionString())
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Debugf("found repo %s/%s", req.RepoId.Org, req.RepoId.RepoName)
+
+	org := req.RepoId.Org
+	repoName := req.RepoId.RepoName
+	tfds := parseTableFileDetails(req)
+
+	var added uint64
+	for _, tfd := range tfds {
+		added += tfd.ContentLength
+	}
+
+	err = rs.checkQuota(ctx, logger, req.RepoId, cs, added)
+	if err != nil {
+		return nil, err
+	}
+
+	var locs []*remotesapi.UploadLoc
+	for _, tfd := range tfds {
+		h := hash.New(tfd.Id)
+		url, err := rs.getUploadUrl(logger, org, repoName, tfd)
+
+		if err != nil {
+			return nil, status.Error(codes.Internal, "Failed to get upload Url.")
+		}
+
+		loc := &remotesapi.UploadLoc_HttpPost{HttpPost: &remotesapi.HttpPostTableFile{Url: url}}
+		locs = append(locs, &remotesapi.UploadLoc{TableFileHash: h[:], Location: loc})
+
+		logger.Debugf("sending upload location for chunk %s: %s", h.String(), url)
+	}
+
+	return &remotesapi.GetUploadLocsResponse{Locs: locs}, nil
+}
+
+func (rs *RemoteChunkStore) getUploadUrl(logger *logrus.Entry, org, repoName string, tfd *remotesapi.TableFileDetails) (string, error) {
+	fileID := hash.New(tfd.Id).String()
+	rs.uploads.add(org, repoName, tfd)
+	return rs.signUrl(fmt.Sprintf("%s://%s/%s/%s/%s", rs.httpScheme, rs.HttpHost, org, repoName, fileID), PermissionWrite)
+}
+
+func (rs *RemoteChunkStore) Rebase(ctx context.Context, req *remotesapi.RebaseRequest) (*remotesapi.RebaseResponse, error) {
+	logger := getReqLogger(rs.lgr, "GRPC", "Rebase")
+	defer func() { logger.Trace("finished") }()
+
+	if err := rs.authorize(ctx, logger, req.RepoId, PermissionRead); err != nil {
+		return nil, err
+	}
+
+	cs, err := rs.getStore(logger, req.RepoId)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Debugf("found %s/%s", req.RepoId.Org, req.RepoId.RepoName)
+
+	err = cs.Rebase(ctx)
+
+	if err != nil {
+		logger.Errorf("error occurred during processing of Rebace rpc of %s/%s details: %v", req.RepoId.Org, req.RepoId.RepoName, err)
+		return nil, status.Error(codes.Internal, "Failed to rebase")
+	}
+
+	return &remotesapi.RebaseResponse{}, nil
+}
+
+func (rs *RemoteChunkStore) Root(ctx context.Context, req *remotesapi.RootRequest) (*remotesapi.RootResponse, error) {
+	logger := getReqLogger(rs.lgr, "GRPC", "Root")
+	defer func() { logger.Trace("finished") }()
+
+	if err := rs.authorize(ctx, logger, req.RepoId, PermissionRead); err != nil {
+		return nil, err
+	}
+
+	cs, err := rs.getStore(logger, req.RepoId)
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := cs.Root(ctx)
+
+	if err != nil {
+		logger.Errorf("error occurred during processing of Root rpc of %s/%s details: %v", req.RepoId.Org, req.RepoId.RepoName, err)
+		return nil, status.Error(codes.Internal, "Failed to get root")
+	}
+
+	return &remotesapi.RootResponse{RootHash: h[:]}, nil
+}
+
+func (rs *RemoteChunkStore) Commit(ctx context.Context, req *remotesapi.CommitRequest) (*remotesapi.CommitResponse, error) {
+	logger := getReqLogger(rs.lgr, "GRPC", "Commit")
+	defer func() { logger.Trace("finished") }()
+
+	if err := rs.authorize(ctx, logger, req.RepoId, PermissionWrite); err != nil {
+		return nil, err
+	}
+
+	cs, err := rs.getOrCreateStore(logger, req.RepoId, types.Format_Default.VersionString())
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Debugf("found %s/%s", req.RepoId.Org, req.RepoId.RepoName)
+
+	//should validate
+	updates := make(map[hash.Hash]uint32)
+	for _, cti := range req.ChunkTableInfo {
+		updates[hash.New(cti.Hash)] = cti.ChunkCount
+	}
+
+	_, err = cs.UpdateManifest(ctx, updates)
+
+	if err != nil {
+		logger.Errorf("error occurred updating the manifest: %s", err.Error())
+		return nil, status.Error(codes.Internal, "manifest update error")
+	}
+
+	currHash := hash.New(req.Current)
+	lastHash := hash.New(req.Last)
+
+	var ok bool
+	ok, err = cs.Commit(ctx, currHash, lastHash)
+
+	if err != nil {
+		logger.Errorf("error occurred during processing of Commit of %s/%s last %s curr: %s details: %v", req.RepoId.Org, req.RepoId.RepoName, lastHash.String(), currHash.String(), err)
+		return nil, status.Error(codes.Internal, "Failed to rebase")
+	}
+
+	logger.Debugf("committed %s/%s moved from %s -> %s", req.RepoId.Org, req.RepoId.RepoName, currHash.String(), lastHash.String())
+	return &remotesapi.CommitResponse{Success: ok}, nil
+}
+
+func (rs *RemoteChunkStore) GetRepoMetadata(ctx context.Context, req *remotesapi.GetRepoMetadataRequest) (*remotesapi.GetRepoMetadataResponse, error) {
+	logger := getReqLogger(rs.lgr, "GRPC", "GetRepoMetadata")
+	defer func() { logger.Trace("finished") }()
+
+	if err := rs.authorize(ctx, logger, req.RepoId, PermissionRead); err != nil {
+		return nil, err
+	}
+
+	if req.ClientRepoFormat == nil {
+		return nil, status.Error(codes.InvalidArgument, "missing client repo format")
+	}
+
+	// a repository which does not exist is only created for a caller which may write to it, such as a push
+	cs, err := rs.openStore(logger, req.RepoId, req.ClientRepoFormat.NbfVersion, false)
+	if status.Code(err) == codes.NotFound && rs.autoCreate && !rs.readOnly {
+		if rs.authorize(ctx, logger, req.RepoId, PermissionWrite) == nil {
+			cs, err = rs.getOrCreateStore(logger, req.RepoId, req.ClientRepoFormat.NbfVersion)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := rs.csCache.Size(ctx, req.RepoId.Org, req.RepoId.RepoName, cs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &remotesapi.GetRepoMetadataResponse{
+		NbfVersion:  cs.Version(),
+		NbsVersion:  req.ClientRepoFormat.NbsVersion,
+		StorageSize: size,
+	}, nil
+}
+
+func (rs *RemoteChunkStore) ListTableFiles(ctx context.Context, req *remotesapi.ListTableFilesRequest) (*remotesapi.ListTableFilesResponse, error) {
+	logger := getReqLogger(rs.lgr, "GRPC", "ListTableFiles")
+	defer func() { logger.Trace("finished") }()
+
+	if err := rs.authorize(ctx, logger, req.RepoId, PermissionRead); err != nil {
+		return nil, err
+	}
+
+	cs, err := rs.getStore(logger, req.RepoId)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Debugf("found repo %s/%s", req.RepoId.Org, req.RepoId.RepoName)
+
+	root, tables, appendixTables, err := cs.Sources(ctx)
+
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get sources")
+	}
+
+	tableFileInfo, err := getTableFileInfo(rs, logger, tables, req)
+	if err != nil {
+		return nil, err
+	}
+
+	appendixTableFileInfo, err := getTableFileInfo(rs, logger, appendixTables, req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &remotesapi.ListTableFilesResponse{
+		RootHash:              root[:],
+		TableFileInfo:         tableFileInfo,
+		AppendixTableFileInfo: appendixTableFileInfo,
+	}
+
+	return resp, nil
+}
+
+func getTableFileInfo(rs *RemoteChunkStore, logger *logrus.Entry, tableList []nbs.TableFile, req *remotesapi.ListTableFilesRequest) ([]*remotesapi.TableFileInfo, error) {
+	appendixTableFileInfo := make([]*remotesapi.TableFileInfo, 0)
+	for _, t := range tableList {
+		url, err := rs.getDownloadUrl(logger, req.RepoId.Org, req.RepoId.RepoName, t.FileID())
+		if err != nil {
+			return nil, status.Error(codes.Internal, "failed to get download url for "+t.FileID())
+		}
+
+		refreshAfter, refreshReq := rs.getRefreshInfo(req.RepoId, t.FileID())
+		appendixTableFileInfo = append(appendixTableFileInfo, &remotesapi.TableFileInfo{
+			FileId:         t.FileID(),
+			NumChunks:      uint32(t.NumChunks()),
+			Url:            url,
+			RefreshAfter:   refreshAfter,
+			RefreshRequest: refreshReq,
+		})
+	}
+	return appendixTableFileInfo, nil
+}
+
+// AddTableFiles updates the remote manifest with new table files without modifying the root hash.
+func (rs *RemoteChunkStore) AddTableFiles(ctx context.Context, req *remotesapi.AddTableFilesRequest) (*remotesapi.AddTableFilesResponse, error) {
+	logger := getReqLogger(rs.lgr, "GRPC", "Commit")
+	defer func() { logger.Trace("finished") }()
+
+	if err := rs.authorize(ctx, logger, req.RepoId, PermissionWrite); err != nil {
+		return nil, err
+	}
+
+	cs, err := rs.getOrCreateStore(logger, req.RepoId, types.Format_Default.VersionString())
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Debugf("found %s/%s", req.RepoId.Org, req.RepoId.RepoName)
+
+	// should validate
+	updates := make(map[hash.Hash]uint32)
+	for _, cti := range req.ChunkTableInfo {
+		updates[hash.New(cti.Hash)] = cti.ChunkCount
+	}
+
+	_, err = cs.UpdateManifest(ctx, updates)
+
+	if err != nil {
+		logger.Errorf("error occurred updating the manifest: %s", err.Error())
+		return nil, status.Error(codes.Internal, "manifest update error")
+	}
+
+	// table files are uploaded as they are, so versioned table files are converted before their chunks are served
+	err = cs.ConvertToLegacyTableFiles(ctx)
+
+	if err != nil {
+		logger.Errorf("error occurred converting table files: %s", err.Error())
+		return nil, status.Error(codes.Internal, "table file conversion error")
+	}
+
+	return &remotesapi.AddTableFilesResponse{Success: true}, nil
+}
+
+// getStore returns the store of the repository |repoId|, or a NotFound error if it does not exist. The returned error
+// is a grpc status error.
+func (rs *RemoteChunkStore) getStore(logger *logrus.Entry, repoId *remotesapi.RepoId) (*nbs.NomsBlockStore, error) {
+	return rs.openStore(logger, repoId, types.Format_Default.VersionString(), false)
+}
+
+// getOrCreateStore is like getStore, but creates the repository if it does not exist and the server creates
+// repositories on demand. It must only be called for requests authorized to write to the repository.
+func (rs *RemoteChunkStore) getOrCreateStore(logger *logrus.Entry, repoId *remotesapi.RepoId, nbfVerStr string) (*nbs.NomsBlockStore, error) {
+	return rs.openStore(logger, repoId, nbfVerStr, rs.autoCreate && !rs.readOnly)
+}
+
+func (rs *RemoteChunkStore) openStore(logger *logrus.Entry, repoId *remotesapi.RepoId, nbfVerStr string, create bool) (*nbs.NomsBlockStore, error) {
+	org := repoId.Org
+	repoName := repoId.RepoName
+
+	cs, err := rs.csCache.Get(org, repoName, nbfVerStr, create)
+	if errors.Is(err, ErrRepoNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	} else if errors.Is(err, ErrInvalidRepoName) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	} else if err != nil {
+		logger.WithError(err).Errorf("Failed to retrieve chunkstore for %s/%s", org, repoName)
+		return nil, status.Error(codes.Internal, "Could not get chunkstore")
+	}
+
+	return cs, nil
+}
+
+// checkQuota returns a grpc status error if adding |added| bytes of table files to the repository |repoId|, whose
+// store is |cs|, would take it over its quota.
+func (rs *RemoteChunkStore) checkQuota(ctx context.Context, logger *logrus.Entry, repoId *remotesapi.RepoId, cs *nbs.NomsBlockStore, added uint64) error {
+	limit := rs.quotas.Limit(repoId.Org, repoId.RepoName)
+	if limit == 0 {
+		return nil
+	}
+
+	size, err := rs.csCache.Size(ctx, repoId.Org, repoId.RepoName, cs)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get repository size")
+		return status.Error(codes.Internal, "Failed to get repository size")
+	}
+
+	if size+added > limit {
+		logger.Infof("rejected %d bytes of table files for %s/%s of size %d with quota %d", added, repoId.Org, repoId.RepoName, size, limit)
+		return status.Error(codes.ResourceExhausted, fmt.Sprintf("%s: %s/%s is limited to %d bytes", ErrQuotaExceeded.Error(), repoId.Org, repoId.RepoName, limit))
+	}
+
+	return nil
+}
+
+var requestId int32
+
+func incReqId() int32 {
+	return atomic.AddInt32(&requestId, 1)
+}
+
+// getReqLogger returns a logger for a request to the |method| server for |callName|, whose entries are tagged with an
+// id unique to the request.
+func getReqLogger(lgr *logrus.Entry, method, callName string) *logrus.Entry {
+	lgr = lgr.WithFields(logrus.Fields{
+		"request_num": incReqId(),
+		"method":      method,
+		"call":        callName,
+	})
+	lgr.Trace("new request")
+	return lgr
+}
@@ -0,0 +1,410 @@
+// Copyright 2019 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesrv
+
+import (
+	"bytes"
+	"crypto/md5"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	remotesapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/remotesapi/v1alpha1"
+	"github.com/dolthub/dolt/go/libraries/utils/iohelp"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+// expectedUploads are the table files the grpc server has handed out upload locations for, which the http server
+// accepts uploads of.
+type expectedUploads struct {
+	mu    sync.Mutex
+	files map[string]*remotesapi.TableFileDetails
+}
+
+func newExpectedUploads() *expectedUploads {
+	return &expectedUploads{files: make(map[string]*remotesapi.TableFileDetails)}
+}
+
+func (eu *expectedUploads) add(org, repo string, tfd *remotesapi.TableFileDetails) {
+	eu.mu.Lock()
+	defer eu.mu.Unlock()
+	eu.files[path.Join(org, repo, hash.New(tfd.Id).String())] = tfd
+}
+
+func (eu *expectedUploads) get(org, repo, fileId string) (*remotesapi.TableFileDetails, bool) {
+	eu.mu.Lock()
+	defer eu.mu.Unlock()
+	tfd, ok := eu.files[path.Join(org, repo, fileId)]
+	return tfd, ok
+}
+
+func (eu *expectedUploads) remove(org, repo, fileId string) {
+	eu.mu.Lock()
+	defer eu.mu.Unlock()
+	delete(eu.files, path.Join(org, repo, fileId))
+}
+
+// fileHandler is the http handler serving the table files of the repositories of a DBCache, at /<org>/<repo>/<file>.
+// Table files are read with GET requests, and uploaded with POST or PUT requests to the locations handed out by the
+// grpc server.
+type fileHandler struct {
+	csCache       *DBCache
+	uploads       *expectedUploads
+	readOnly      bool
+	quotas        QuotaConfig
+	maxUploadSize uint64
+	lgr           *logrus.Entry
+}
+
+func (fh *fileHandler) ServeHTTP(respWr http.ResponseWriter, req *http.Request) {
+	logger := getReqLogger(fh.lgr, "HTTP_"+req.Method, req.URL.Path)
+	defer func() { logger.Trace("finished") }()
+
+	tokens := strings.Split(strings.TrimLeft(req.URL.Path, "/"), "/")
+
+	if len(tokens) != 3 {
+		logger.Debugf("response to: %v method: %v http response code: %v", req.URL.Path, req.Method, http.StatusNotFound)
+		respWr.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	org := tokens[0]
+	repo := tokens[1]
+	hashStr := tokens[2]
+
+	if _, ok := hash.MaybeParse(hashStr); !ok {
+		logger.Debug(hashStr + " is not a valid hash")
+		respWr.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	repoPath, err := fh.csCache.Path(org, repo)
+	if err != nil {
+		logger.Debug(err.Error())
+		respWr.WriteHeader(http.StatusNotFound)
+		return
+	}
+	filePath := filepath.Join(repoPath, hashStr)
+
+	statusCode := http.StatusMethodNotAllowed
+	switch req.Method {
+	case http.MethodGet:
+		rangeStr := req.Header.Get("Range")
+
+		if rangeStr == "" {
+			statusCode = readFile(logger, filePath, respWr)
+		} else {
+			statusCode = readChunk(logger, filePath, rangeStr, respWr)
+		}
+
+	case http.MethodPost, http.MethodPut:
+		statusCode = fh.writeTableFile(logger, org, repo, hashStr, filePath, req)
+	}
+
+	if statusCode != -1 {
+		respWr.WriteHeader(statusCode)
+	}
+}
+
+// authHandler authorizes the requests for table files before passing them to |next|. A request is authorized either
+// by the signature of a url handed out by the grpc server, or by its bearer token.
+type authHandler struct {
+	auth   Authorizer
+	signer *urlSigner
+	next   http.Handler
+	lgr    *logrus.Entry
+}
+
+func (h authHandler) ServeHTTP(respWr http.ResponseWriter, req *http.Request) {
+	path := strings.TrimLeft(req.URL.Path, "/")
+	tokens := strings.Split(path, "/")
+
+	if len(tokens) != 3 {
+		respWr.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	perm := PermissionWrite
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		perm = PermissionRead
+	}
+
+	if !h.signer.verify(req.URL, perm, time.Now()) {
+		err := h.auth.Authorize(req.Context(), httpBearerToken(req), tokens[0], tokens[1], perm)
+		if err != nil {
+			writeAuthError(h.lgr.WithField("path", req.URL.Path), respWr, err)
+			return
+		}
+	}
+
+	h.next.ServeHTTP(respWr, req)
+}
+
+// writeAuthError writes the http status of the authorization error |err| to |respWr|.
+func writeAuthError(logger *logrus.Entry, respWr http.ResponseWriter, err error) {
+	if errors.Is(err, ErrPermissionDenied) {
+		logger.Info(err.Error())
+		respWr.WriteHeader(http.StatusForbidden)
+	} else if errors.Is(err, ErrUnauthenticated) {
+		logger.Info(err.Error())
+		respWr.WriteHeader(http.StatusUnauthorized)
+	} else {
+		logger.WithError(err).Error("error occurred authorizing request")
+		respWr.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (fh *fileHandler) writeTableFile(logger *logrus.Entry, org, repo, fileId, path string, request *http.Request) int {
+	if fh.readOnly {
+		logger.Debug("rejected write to read only server")
+		return http.StatusForbidden
+	}
+
+	tfd, ok := fh.uploads.get(org, repo, fileId)
+
+	if !ok {
+		return http.StatusBadRequest
+	}
+
+	logger.Trace(fileId + " is valid")
+	body := io.Reader(request.Body)
+	if fh.maxUploadSize > 0 {
+		body = io.LimitReader(body, int64(fh.maxUploadSize)+1)
+	}
+	data, err := io.ReadAll(body)
+
+	if err != nil {
+		logger.WithError(err).Error("failed to read body")
+		return http.StatusInternalServerError
+	}
+
+	if fh.maxUploadSize > 0 && uint64(len(data)) > fh.maxUploadSize {
+		logger.Infof("rejected upload of %s larger than %d bytes", fileId, fh.maxUploadSize)
+		return http.StatusRequestEntityTooLarge
+	}
+
+	if tfd.ContentLength != 0 && tfd.ContentLength != uint64(len(data)) {
+		return http.StatusBadRequest
+	}
+
+	if len(tfd.ContentHash) > 0 {
+		actualMD5Bytes := md5.Sum(data)
+		if !bytes.Equal(tfd.ContentHash, actualMD5Bytes[:]) {
+			return http.StatusBadRequest
+		}
+	}
+
+	if limit := fh.quotas.Limit(org, repo); limit > 0 {
+		cs, err := fh.csCache.Get(org, repo, types.Format_Default.VersionString(), false)
+		if err != nil {
+			logger.WithError(err).Error("failed to get chunkstore")
+			return http.StatusInternalServerError
+		}
+
+		size, err := fh.csCache.Size(request.Context(), org, repo, cs)
+		if err != nil {
+			logger.WithError(err).Error("failed to get repository size")
+			return http.StatusInternalServerError
+		}
+
+		if size+uint64(len(data)) > limit {
+			logger.Infof("rejected upload of %d bytes to %s/%s of size %d with quota %d", len(data), org, repo, size, limit)
+			return http.StatusRequestEntityTooLarge
+		}
+	}
+
+	err = writeLocal(logger, path, data)
+
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+
+	fh.uploads.remove(org, repo, fileId)
+	return http.StatusOK
+}
+
+func writeLocal(logger *logrus.Entry, path string, data []byte) error {
+	err := os.WriteFile(path, data, os.ModePerm)
+
+	if err != nil {
+		logger.WithError(err).Errorf("failed to write file %s", path)
+		return err
+	}
+
+	logger.Debug("Successfully wrote object to storage")
+
+	return nil
+}
+
+func offsetAndLenFromRange(rngStr string) (int64, int64, error) {
+	if rngStr == "" {
+		return -1, -1, nil
+	}
+
+	if !strings.HasPrefix(rngStr, "bytes=") {
+		return -1, -1, errors.New("range string does not start with 'bytes=")
+	}
+
+	tokens := strings.Split(rngStr[6:], "-")
+
+	if len(tokens) != 2 {
+		return -1, -1, errors.New("invalid range format. should be bytes=#-#")
+	}
+
+	start, err := strconv.ParseUint(strings.TrimSpace(tokens[0]), 10, 64)
+
+	if err != nil {
+		return -1, -1, errors.New("invalid offset is not a number. should be bytes=#-#")
+	}
+
+	end, err := strconv.ParseUint(strings.TrimSpace(tokens[1]), 10, 64)
+
+	if err != nil {
+		return -1, -1, errors.New("invalid length is not a number. should be bytes=#-#")
+	}
+
+	return int64(start), int64(end-start) + 1, nil
+}
+
+func readFile(logger *logrus.Entry, path string, writer io.Writer) int {
+	info, err := os.Stat(path)
+
+	if err != nil {
+		logger.Debug("file not found. path: " + path)
+		return http.StatusNotFound
+	}
+
+	f, err := os.Open(path)
+
+	if err != nil {
+		logger.WithError(err).Error("failed to open file. file: " + path)
+		return http.StatusInternalServerError
+	}
+
+	defer func() {
+		err := f.Close()
+
+		if err != nil {
+			logger.Errorf("Close failed. file: %s, err: %v", path, err)
+		} else {
+			logger.Trace("Close Successful")
+		}
+	}()
+
+	n, err := io.Copy(writer, f)
+
+	if err != nil {
+		logger.WithError(err).Error("failed to write data to response")
+		return -1
+	}
+
+	if n != info.Size() {
+		logger.Errorf("failed to write entire file to response. Copied %d of %d err: %v", n, info.Size(), err)
+		return -1
+	}
+
+	return -1
+}
+
+func readChunk(logger *logrus.Entry, path, rngStr string, writer io.Writer) int {
+	offset, length, err := offsetAndLenFromRange(rngStr)
+
+	if err != nil {
+		logger.Debug(rngStr + " is not a valid range")
+		return http.StatusBadRequest
+	}
+
+	data, retVal := readLocalRange(logger, path, int64(offset), int64(length))
+
+	if retVal != -1 {
+		return retVal
+	}
+
+	logger.Tracef("writing %d bytes", len(data))
+	err = iohelp.WriteAll(writer, data)
+
+	if err != nil {
+		logger.WithError(err).Error("failed to write data to response")
+		return -1
+	}
+
+	logger.Trace("Successfully wrote data")
+	return -1
+}
+
+func readLocalRange(logger *logrus.Entry, path string, offset, length int64) ([]byte, int) {
+	logger.Tracef("Attempting to read bytes %d to %d from %s", offset, offset+length, path)
+	info, err := os.Stat(path)
+
+	if err != nil {
+		logger.Debugf("file %s not found", path)
+		return nil, http.StatusNotFound
+	}
+
+	logger.Tracef("Verified file %s exists", path)
+
+	if info.Size() < int64(offset+length) {
+		logger.Debugf("Attempted to read bytes %d to %d, but the file is only %d bytes in size", offset, offset+length, info.Size())
+		return nil, http.StatusBadRequest
+	}
+
+	logger.Trace("Verified the file is large enough to contain the range")
+	f, err := os.Open(path)
+
+	if err != nil {
+		logger.Errorf("Failed to open %s: %v", path, err)
+		return nil, http.StatusInternalServerError
+	}
+
+	defer func() {
+		err := f.Close()
+
+		if err != nil {
+			logger.Errorf("Close failed. file: %s, err: %v", path, err)
+		} else {
+			logger.Trace("Close Successful")
+		}
+	}()
+
+	logger.Trace("Successfully opened file")
+	pos, err := f.Seek(int64(offset), 0)
+
+	if err != nil {
+		logger.Errorf("Failed to seek to %d: %v", offset, err)
+		return nil, http.StatusInternalServerError
+	}
+
+	logger.Tracef("Seek succeeded.  Current position is %d", pos)
+	diff := offset - pos
+	data, err := iohelp.ReadNBytes(f, int(diff+int64(length)))
+
+	if err != nil {
+		logger.Errorf("Failed to read %d bytes: %v", diff+length, err)
+		return nil, http.StatusInternalServerError
+	}
+
+	logger.Tracef("Successfully read %d bytes", len(data))
+	return data[diff:], -1
+}
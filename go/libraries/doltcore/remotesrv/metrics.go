@@ -0,0 +1,144 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesrv
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// serverMetrics are the prometheus metrics of a Server, which are registered with a registry of their own.
+type serverMetrics struct {
+	registry *prometheus.Registry
+
+	grpcRequests *prometheus.CounterVec
+	grpcDuration *prometheus.HistogramVec
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+	bytesRead    prometheus.Counter
+	bytesWritten prometheus.Counter
+}
+
+func newServerMetrics(labels map[string]string) *serverMetrics {
+	m := &serverMetrics{
+		registry: prometheus.NewRegistry(),
+		grpcRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "remotesrv_grpc_requests",
+			Help:        "Count of the grpc requests handled, by method and status code",
+			ConstLabels: labels,
+		}, []string{"method", "code"}),
+		grpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:        "remotesrv_grpc_request_duration_seconds",
+			Help:        "Duration of the grpc requests handled, by method",
+			ConstLabels: labels,
+			Buckets:     prometheus.DefBuckets,
+		}, []string{"method"}),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "remotesrv_http_requests",
+			Help:        "Count of the http requests handled, by method and status code",
+			ConstLabels: labels,
+		}, []string{"method", "code"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:        "remotesrv_http_request_duration_seconds",
+			Help:        "Duration of the http requests handled, by method",
+			ConstLabels: labels,
+			Buckets:     prometheus.DefBuckets,
+		}, []string{"method"}),
+		bytesRead: prometheus.NewCounter(prometheus.CounterOpts{
+			Name:        "remotesrv_http_bytes_downloaded",
+			Help:        "Count of the bytes of table files served by the http server",
+			ConstLabels: labels,
+		}),
+		bytesWritten: prometheus.NewCounter(prometheus.CounterOpts{
+			Name:        "remotesrv_http_bytes_uploaded",
+			Help:        "Count of the bytes of table files uploaded to the http server",
+			ConstLabels: labels,
+		}),
+	}
+
+	m.registry.MustRegister(m.grpcRequests, m.grpcDuration, m.httpRequests, m.httpDuration, m.bytesRead, m.bytesWritten)
+	m.registry.MustRegister(prometheus.NewGoCollector(), prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}))
+
+	return m
+}
+
+// handler returns the http handler exposing the metrics.
+func (m *serverMetrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+func (m *serverMetrics) observeGRPC(method string, start time.Time, err error) {
+	m.grpcRequests.WithLabelValues(method, status.Code(err).String()).Inc()
+	m.grpcDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+func (m *serverMetrics) unaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.observeGRPC(info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+func (m *serverMetrics) streamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		m.observeGRPC(info.FullMethod, start, err)
+		return err
+	}
+}
+
+// httpHandler wraps |next| in a handler recording the metrics of the requests it serves.
+func (m *serverMetrics) httpHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(respWr http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: respWr, status: http.StatusOK}
+		next.ServeHTTP(rec, req)
+
+		m.httpRequests.WithLabelValues(req.Method, strconv.Itoa(rec.status)).Inc()
+		m.httpDuration.WithLabelValues(req.Method).Observe(time.Since(start).Seconds())
+		m.bytesRead.Add(float64(rec.written))
+		if req.ContentLength > 0 && rec.status < 300 && (req.Method == http.MethodPost || req.Method == http.MethodPut) {
+			m.bytesWritten.Add(float64(req.ContentLength))
+		}
+	})
+}
+
+// statusRecorder is an http.ResponseWriter recording the status code and the size of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status  int
+	written uint64
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(data []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(data)
+	r.written += uint64(n)
+	return n, err
+}
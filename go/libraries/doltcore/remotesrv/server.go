@@ -0,0 +1,301 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesrv
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	remotesapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/remotesapi/v1alpha1"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+)
+
+var ErrReadOnly = errors.New("the server is read only")
+var ErrQuotaExceeded = errors.New("repository quota exceeded")
+
+// Server is a remote which serves the repositories of a data directory. It is made up of a grpc server implementing
+// the remote chunk store api, an http server serving the table files of the repositories along with the repository
+// api, and optionally an http server exposing prometheus metrics.
+type Server struct {
+	cfg     Config
+	lgr     *logrus.Entry
+	csCache *DBCache
+
+	grpcServer    *grpc.Server
+	httpServer    *http.Server
+	metricsServer *http.Server
+
+	grpcListener    net.Listener
+	httpListener    net.Listener
+	metricsListener net.Listener
+
+	wg sync.WaitGroup
+}
+
+// NewServer creates a Server for |cfg| and starts listening on its ports. Requests are not served until Start is
+// called.
+func NewServer(cfg Config) (*Server, error) {
+	err := cfg.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	lgr, err := newLogger(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.MkdirAll(cfg.DataDir, os.ModePerm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create data dir '%s': %w", cfg.DataDir, err)
+	}
+
+	fs, err := filesys.LocalFilesysWithWorkingDir(cfg.DataDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open data dir '%s': %w", cfg.DataDir, err)
+	}
+
+	var auth Authorizer
+	var signer *urlSigner
+	if cfg.AuthFile != "" {
+		fileAuth, err := NewFileAuthorizer(filesys.LocalFS, cfg.AuthFile, cfg.JWTAudience)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load credentials file '%s': %w", cfg.AuthFile, err)
+		}
+
+		auth = fileAuth
+		signer, err = newUrlSigner()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create url signer: %w", err)
+		}
+	}
+
+	var tlsConfig *tls.Config
+	if cfg.TLSEnabled() {
+		cert, err := tls.LoadX509KeyPair(cfg.TLS.Cert, cfg.TLS.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tls cert and key: %w", err)
+		}
+
+		tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	}
+
+	s := &Server{cfg: cfg, lgr: lgr, csCache: NewLocalCSCache(fs)}
+
+	s.grpcListener, err = net.Listen("tcp", fmt.Sprintf("%s:%d", cfg.GRPC.Host, cfg.GRPC.Port))
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for grpc: %w", err)
+	}
+
+	s.httpListener, err = net.Listen("tcp", fmt.Sprintf("%s:%d", cfg.HTTP.Host, cfg.HTTP.Port))
+	if err != nil {
+		s.closeListeners()
+		return nil, fmt.Errorf("failed to listen for http: %w", err)
+	}
+
+	if cfg.Metrics.Port > 0 {
+		s.metricsListener, err = net.Listen("tcp", fmt.Sprintf("%s:%d", cfg.Metrics.Host, cfg.Metrics.Port))
+		if err != nil {
+			s.closeListeners()
+			return nil, fmt.Errorf("failed to listen for metrics: %w", err)
+		}
+	}
+
+	if tlsConfig != nil {
+		s.httpListener = tls.NewListener(s.httpListener, tlsConfig)
+	}
+
+	// the port of the http server is only known once it is listening when it is 0
+	if cfg.HTTP.AdvertiseHost == "" {
+		cfg.HTTP.AdvertiseHost = fmt.Sprintf("localhost:%d", s.httpListener.Addr().(*net.TCPAddr).Port)
+		s.cfg = cfg
+	}
+
+	metrics := newServerMetrics(cfg.Metrics.Labels)
+	uploads := newExpectedUploads()
+
+	grpcOpts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(cfg.GRPC.MaxMessageSize),
+		grpc.ChainUnaryInterceptor(metrics.unaryInterceptor()),
+		grpc.ChainStreamInterceptor(metrics.streamInterceptor()),
+	}
+	if tlsConfig != nil {
+		grpcOpts = append(grpcOpts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	s.grpcServer = grpc.NewServer(grpcOpts...)
+	chnkSt := newRemoteChunkStore(cfg, lgr, s.csCache, auth, signer, uploads)
+	remotesapi.RegisterChunkStoreServiceServer(s.grpcServer, chnkSt)
+
+	var files http.Handler = &fileHandler{
+		csCache:       s.csCache,
+		uploads:       uploads,
+		readOnly:      cfg.ReadOnly,
+		quotas:        cfg.Quotas,
+		maxUploadSize: cfg.HTTP.MaxUploadSize,
+		lgr:           lgr,
+	}
+	if auth != nil {
+		files = authHandler{auth: auth, signer: signer, next: files, lgr: lgr}
+	}
+
+	api := &reposAPIHandler{csCache: s.csCache, auth: auth, readOnly: cfg.ReadOnly, quotas: cfg.Quotas, lgr: lgr}
+
+	mux := http.NewServeMux()
+	mux.Handle(ReposAPIPath, api)
+	mux.Handle(ReposAPIPath+"/", api)
+	mux.Handle("/", files)
+
+	s.httpServer = &http.Server{
+		Handler:           metrics.httpHandler(mux),
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+
+	if s.metricsListener != nil {
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle("/metrics", metrics.handler())
+		s.metricsServer = &http.Server{Handler: metricsMux}
+	}
+
+	return s, nil
+}
+
+func newLogger(cfg Config) (*logrus.Entry, error) {
+	level, err := logrus.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(os.Stderr)
+	logger.SetLevel(level)
+	if cfg.LogFormat == "json" {
+		logger.SetFormatter(&logrus.JSONFormatter{})
+	} else {
+		logger.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	}
+
+	return logrus.NewEntry(logger), nil
+}
+
+func (s *Server) closeListeners() {
+	for _, l := range []net.Listener{s.grpcListener, s.httpListener, s.metricsListener} {
+		if l != nil {
+			_ = l.Close()
+		}
+	}
+}
+
+// Start serves requests in the background until Stop is called.
+func (s *Server) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.lgr.Infof("starting grpc server on %s", s.grpcListener.Addr())
+		err := s.grpcServer.Serve(s.grpcListener)
+		if err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			s.lgr.WithError(err).Error("grpc server exited")
+		}
+	}()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.lgr.Infof("starting http server on %s, advertised as %s://%s", s.httpListener.Addr(), s.cfg.HTTPScheme(), s.cfg.HTTPAdvertiseHost())
+		err := s.httpServer.Serve(s.httpListener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.lgr.WithError(err).Error("http server exited")
+		}
+	}()
+
+	if s.metricsServer != nil {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.lgr.Infof("starting metrics server on %s", s.metricsListener.Addr())
+			err := s.metricsServer.Serve(s.metricsListener)
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				s.lgr.WithError(err).Error("metrics server exited")
+			}
+		}()
+	}
+
+	if s.cfg.ReadOnly {
+		s.lgr.Info("serving repositories read only")
+	}
+}
+
+// Stop stops the server, waiting up to the shutdown timeout of the config for requests in flight to finish before
+// closing their connections, and closes the repositories.
+func (s *Server) Stop() error {
+	s.lgr.Info("stopping server")
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.ShutdownTimeout())
+	defer cancel()
+
+	grpcStopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+
+	httpErr := s.httpServer.Shutdown(ctx)
+	if httpErr != nil {
+		s.lgr.WithError(httpErr).Warn("http server did not shut down gracefully")
+		_ = s.httpServer.Close()
+	}
+
+	if s.metricsServer != nil {
+		_ = s.metricsServer.Shutdown(ctx)
+	}
+
+	select {
+	case <-grpcStopped:
+	case <-ctx.Done():
+		s.lgr.Warn("grpc server did not shut down gracefully")
+		s.grpcServer.Stop()
+		<-grpcStopped
+	}
+
+	s.wg.Wait()
+
+	err := s.csCache.Close()
+	if err != nil {
+		return err
+	}
+
+	s.lgr.Info("server stopped")
+	return nil
+}
+
+// GRPCAddr returns the address the grpc server is listening on.
+func (s *Server) GRPCAddr() net.Addr {
+	return s.grpcListener.Addr()
+}
+
+// HTTPAddr returns the address the http server is listening on.
+func (s *Server) HTTPAddr() net.Addr {
+	return s.httpListener.Addr()
+}
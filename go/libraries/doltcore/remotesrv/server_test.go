@@ -0,0 +1,395 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotesrv
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	remotesapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/remotesapi/v1alpha1"
+	"github.com/dolthub/dolt/go/libraries/doltcore/remotestorage"
+	"github.com/dolthub/dolt/go/store/chunks"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/nbs"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+func newTestServer(t *testing.T, modify func(cfg *Config)) *Server {
+	cfg := DefaultConfig()
+	cfg.DataDir = t.TempDir()
+	cfg.LogLevel = "error"
+	cfg.GRPC.Host = "localhost"
+	cfg.GRPC.Port = 0
+	cfg.HTTP.Host = "localhost"
+	cfg.HTTP.Port = 0
+	cfg.ShutdownTimeoutMillis = 1000
+	if modify != nil {
+		modify(&cfg)
+	}
+
+	s, err := NewServer(cfg)
+	require.NoError(t, err)
+	s.Start()
+	t.Cleanup(func() {
+		require.NoError(t, s.Stop())
+	})
+
+	return s
+}
+
+func dialTestServer(t *testing.T, s *Server, opts ...grpc.DialOption) remotesapi.ChunkStoreServiceClient {
+	if len(opts) == 0 {
+		opts = append(opts, grpc.WithInsecure())
+	}
+
+	conn, err := grpc.Dial(s.GRPCAddr().String(), opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	return remotesapi.NewChunkStoreServiceClient(conn)
+}
+
+func apiRequest(t *testing.T, client *http.Client, method, url string, resp interface{}) int {
+	req, err := http.NewRequest(method, url, nil)
+	require.NoError(t, err)
+
+	httpResp, err := client.Do(req)
+	require.NoError(t, err)
+	defer httpResp.Body.Close()
+
+	if resp != nil && httpResp.StatusCode < 300 {
+		require.NoError(t, json.NewDecoder(httpResp.Body).Decode(resp))
+	}
+
+	return httpResp.StatusCode
+}
+
+func getRepoMetadata(ctx context.Context, client remotesapi.ChunkStoreServiceClient, org, repo string) (*remotesapi.GetRepoMetadataResponse, error) {
+	return client.GetRepoMetadata(ctx, &remotesapi.GetRepoMetadataRequest{
+		RepoId: &remotesapi.RepoId{Org: org, RepoName: repo},
+		ClientRepoFormat: &remotesapi.ClientRepoFormat{
+			NbfVersion: types.Format_Default.VersionString(),
+			NbsVersion: nbs.StorageVersion,
+		},
+	})
+}
+
+func getUploadLocations(ctx context.Context, client remotesapi.ChunkStoreServiceClient, org, repo string, size uint64) (*remotesapi.GetUploadLocsResponse, error) {
+	h := hash.Of([]byte("table file"))
+	return client.GetUploadLocations(ctx, &remotesapi.GetUploadLocsRequest{
+		RepoId:           &remotesapi.RepoId{Org: org, RepoName: repo},
+		TableFileHashes:  [][]byte{h[:]},
+		TableFileDetails: []*remotesapi.TableFileDetails{{Id: h[:], ContentLength: size}},
+	})
+}
+
+func TestServerReposAPI(t *testing.T) {
+	s := newTestServer(t, func(cfg *Config) {
+		cfg.AutoCreateRepos = false
+		cfg.Quotas.Repos = map[string]uint64{"org/repo": 1024}
+	})
+	client := http.DefaultClient
+	reposUrl := fmt.Sprintf("http://%s%s", s.HTTPAddr(), ReposAPIPath)
+
+	var list ListReposResponse
+	require.Equal(t, http.StatusOK, apiRequest(t, client, http.MethodGet, reposUrl, &list))
+	assert.Empty(t, list.Repos)
+
+	var info RepoInfo
+	require.Equal(t, http.StatusCreated, apiRequest(t, client, http.MethodPut, reposUrl+"/org/repo", &info))
+	assert.Equal(t, RepoInfo{Org: "org", Name: "repo"}, info)
+	assert.Equal(t, http.StatusConflict, apiRequest(t, client, http.MethodPut, reposUrl+"/org/repo", nil))
+	assert.Equal(t, http.StatusNotFound, apiRequest(t, client, http.MethodPut, reposUrl+"/org/repo/nested", nil))
+	require.Equal(t, http.StatusCreated, apiRequest(t, client, http.MethodPut, reposUrl+"/another/repo", nil))
+
+	require.Equal(t, http.StatusOK, apiRequest(t, client, http.MethodGet, reposUrl, &list))
+	assert.Equal(t, []RepoInfo{{Org: "another", Name: "repo"}, {Org: "org", Name: "repo"}}, list.Repos)
+
+	info = RepoInfo{}
+	require.Equal(t, http.StatusOK, apiRequest(t, client, http.MethodGet, reposUrl+"/org/repo", &info))
+	require.NotNil(t, info.Size)
+	require.NotNil(t, info.Quota)
+	assert.Equal(t, uint64(0), *info.Size)
+	assert.Equal(t, uint64(1024), *info.Quota)
+	assert.Equal(t, hash.Hash{}.String(), info.Root)
+	assert.Equal(t, http.StatusNotFound, apiRequest(t, client, http.MethodGet, reposUrl+"/org/missing", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, apiRequest(t, client, http.MethodDelete, reposUrl+"/org/repo", nil))
+
+	// repositories are not created on first access without auto_create_repos
+	ctx := context.Background()
+	grpcClient := dialTestServer(t, s)
+	_, err := getRepoMetadata(ctx, grpcClient, "org", "missing")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = getRepoMetadata(ctx, grpcClient, "org", "repo")
+	assert.NoError(t, err)
+}
+
+func TestServerAutoCreateRepos(t *testing.T) {
+	authFile := filepath.Join(t.TempDir(), "creds.json")
+	err := os.WriteFile(authFile, []byte(`{
+  "anonymous": {"*": "read"},
+  "users": [{"name": "ci", "token": "s3cr3t", "repos": {"*": "write"}}]
+}`), 0600)
+	require.NoError(t, err)
+
+	s := newTestServer(t, func(cfg *Config) {
+		cfg.AutoCreateRepos = true
+		cfg.AuthFile = authFile
+	})
+	ctx := context.Background()
+	client := dialTestServer(t, s)
+	repoId := &remotesapi.RepoId{Org: "org", RepoName: "repo"}
+	reposUrl := fmt.Sprintf("http://%s%s", s.HTTPAddr(), ReposAPIPath)
+
+	// reads of a repository which does not exist do not create it
+	_, err = getRepoMetadata(ctx, client, "org", "repo")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = client.HasChunks(ctx, &remotesapi.HasChunksRequest{RepoId: repoId})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = client.Root(ctx, &remotesapi.RootRequest{RepoId: repoId})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, http.StatusNotFound, apiRequest(t, http.DefaultClient, http.MethodGet, reposUrl+"/org/repo", nil))
+
+	// a caller which may write to it creates it, such as a push
+	writeCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer s3cr3t")
+	_, err = getRepoMetadata(writeCtx, client, "org", "repo")
+	require.NoError(t, err)
+	_, err = client.Root(ctx, &remotesapi.RootRequest{RepoId: repoId})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, apiRequest(t, http.DefaultClient, http.MethodGet, reposUrl+"/org/repo", nil))
+}
+
+func TestServerReadOnly(t *testing.T) {
+	dir := t.TempDir()
+	err := os.MkdirAll(filepath.Join(dir, "org", "repo"), os.ModePerm)
+	require.NoError(t, err)
+
+	s := newTestServer(t, func(cfg *Config) {
+		cfg.DataDir = dir
+		cfg.ReadOnly = true
+	})
+	ctx := context.Background()
+	client := dialTestServer(t, s)
+
+	// repositories are neither created on first access nor by the api
+	_, err = getRepoMetadata(ctx, client, "org", "missing")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	reposUrl := fmt.Sprintf("http://%s%s", s.HTTPAddr(), ReposAPIPath)
+	assert.Equal(t, http.StatusForbidden, apiRequest(t, http.DefaultClient, http.MethodPut, reposUrl+"/org/other", nil))
+
+	_, err = getRepoMetadata(ctx, client, "org", "repo")
+	assert.NoError(t, err)
+	_, err = getUploadLocations(ctx, client, "org", "repo", 10)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	h := hash.Of([]byte("table file"))
+	resp, err := http.Post(fmt.Sprintf("http://%s/org/repo/%s", s.HTTPAddr(), h.String()), "application/octet-stream", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestServerQuota(t *testing.T) {
+	s := newTestServer(t, func(cfg *Config) {
+		cfg.AutoCreateRepos = true
+		cfg.Quotas.MaxRepoSize = 1024
+		cfg.Quotas.Repos = map[string]uint64{"org/big": 4096}
+	})
+
+	ctx := context.Background()
+	client := dialTestServer(t, s)
+	for _, repo := range []string{"small", "big"} {
+		_, err := getRepoMetadata(ctx, client, "org", repo)
+		require.NoError(t, err)
+	}
+
+	_, err := getUploadLocations(ctx, client, "org", "small", 1000)
+	assert.NoError(t, err)
+	_, err = getUploadLocations(ctx, client, "org", "small", 2000)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	_, err = getUploadLocations(ctx, client, "org", "big", 2000)
+	assert.NoError(t, err)
+}
+
+func TestServerTLS(t *testing.T) {
+	certFile, keyFile := writeTestCert(t)
+	s := newTestServer(t, func(cfg *Config) {
+		cfg.AutoCreateRepos = true
+		cfg.TLS.Cert = certFile
+		cfg.TLS.Key = keyFile
+	})
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: true}
+	httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	reposUrl := fmt.Sprintf("https://%s%s", s.HTTPAddr(), ReposAPIPath)
+	assert.Equal(t, http.StatusOK, apiRequest(t, httpClient, http.MethodGet, reposUrl, nil))
+
+	ctx := context.Background()
+	client := dialTestServer(t, s, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	_, err := getRepoMetadata(ctx, client, "org", "repo")
+	require.NoError(t, err)
+
+	resp, err := getUploadLocations(ctx, client, "org", "repo", 10)
+	require.NoError(t, err)
+	require.Len(t, resp.Locs, 1)
+	assert.Regexp(t, "^https://localhost:", resp.Locs[0].GetHttpPost().Url)
+}
+
+func writeTestCert(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+
+	return certFile, keyFile
+}
+
+// writeVersionedStore writes a store of table files compressed with zstd dictionaries to |dir|, and returns the data of
+// its chunks by hash.
+func writeVersionedStore(t *testing.T, dir string) map[hash.Hash][]byte {
+	ctx := context.Background()
+	cs, err := nbs.NewLocalStore(ctx, types.Format_Default.VersionString(), dir, 1<<20)
+	require.NoError(t, err)
+	defer cs.Close()
+	require.NoError(t, cs.SetTableFileCompression(nbs.ZstdDictCompression))
+
+	data := make(map[hash.Hash][]byte)
+	var root hash.Hash
+	for i := 0; i < 128; i++ {
+		c := chunks.NewChunk([]byte(fmt.Sprintf(`{"id": %d, "name": "customer %d", "email": "customer.%d@example.com", "plan": "enterprise"}`, i, i, i)))
+		require.NoError(t, cs.Put(ctx, c))
+		data[c.Hash()] = c.Data()
+		root = c.Hash()
+	}
+
+	ok, err := cs.Commit(ctx, root, hash.Hash{})
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	return data
+}
+
+func assertFetchesChunks(t *testing.T, s *Server, client remotesapi.ChunkStoreServiceClient, repoPath string, data map[hash.Hash][]byte) {
+	ctx := context.Background()
+	dcs, err := remotestorage.NewDoltChunkStoreFromPath(ctx, types.Format_Default, repoPath, s.GRPCAddr().String(), client)
+	require.NoError(t, err)
+
+	hashes := make(hash.HashSet)
+	for h := range data {
+		hashes.Insert(h)
+	}
+
+	found := make(map[hash.Hash][]byte)
+	var mu sync.Mutex
+	err = dcs.GetMany(ctx, hashes, func(ctx context.Context, c *chunks.Chunk) {
+		mu.Lock()
+		defer mu.Unlock()
+		found[c.Hash()] = c.Data()
+	})
+	require.NoError(t, err)
+	assert.Equal(t, data, found)
+}
+
+func TestServerServesVersionedTableFiles(t *testing.T) {
+	dataDir := t.TempDir()
+	served := filepath.Join(dataDir, "org", "served")
+	require.NoError(t, os.MkdirAll(served, os.ModePerm))
+	servedData := writeVersionedStore(t, served)
+
+	s := newTestServer(t, func(cfg *Config) {
+		cfg.DataDir = dataDir
+		cfg.AutoCreateRepos = true
+	})
+	client := dialTestServer(t, s)
+
+	t.Run("existing table files", func(t *testing.T) {
+		assertFetchesChunks(t, s, client, "org/served", servedData)
+	})
+
+	t.Run("pushed table files", func(t *testing.T) {
+		ctx := context.Background()
+		srcDir := t.TempDir()
+		pushedData := writeVersionedStore(t, srcDir)
+
+		src, err := nbs.NewLocalStore(ctx, types.Format_Default.VersionString(), srcDir, 1<<20)
+		require.NoError(t, err)
+		defer src.Close()
+
+		dcs, err := remotestorage.NewDoltChunkStoreFromPath(ctx, types.Format_Default, "org/pushed", s.GRPCAddr().String(), client)
+		require.NoError(t, err)
+
+		// table files are pushed as they are
+		_, tfs, _, err := src.Sources(ctx)
+		require.NoError(t, err)
+		require.NotEmpty(t, tfs)
+		fileIdToNumChunks := make(map[string]int)
+		for _, tf := range tfs {
+			info, err := os.Stat(filepath.Join(srcDir, tf.FileID()))
+			require.NoError(t, err)
+			rd, err := tf.Open(ctx)
+			require.NoError(t, err)
+			err = dcs.WriteTableFile(ctx, tf.FileID(), tf.NumChunks(), rd, uint64(info.Size()), nil)
+			require.NoError(t, err)
+			require.NoError(t, rd.Close())
+			fileIdToNumChunks[tf.FileID()] = tf.NumChunks()
+		}
+		require.NoError(t, dcs.AddTableFilesToManifest(ctx, fileIdToNumChunks))
+
+		assertFetchesChunks(t, s, client, "org/pushed", pushedData)
+	})
+}
@@ -41,6 +41,17 @@ func newIdentityConverter(mapping *FieldMapping) *RowConverter {
 
 // NewRowConverter creates a row converter from a given FieldMapping.
 func NewRowConverter(ctx context.Context, vrw types.ValueReadWriter, mapping *FieldMapping) (*RowConverter, error) {
+	return newRowConverter(ctx, vrw, mapping, false)
+}
+
+// NewLossyRowConverter creates a row converter from a given FieldMapping which never fails to convert a value. Values
+// which can't be converted to the type of their destination column, including all values of a column whose type
+// has no conversion to the destination type, are converted to NULL.
+func NewLossyRowConverter(ctx context.Context, vrw types.ValueReadWriter, mapping *FieldMapping) (*RowConverter, error) {
+	return newRowConverter(ctx, vrw, mapping, true)
+}
+
+func newRowConverter(ctx context.Context, vrw types.ValueReadWriter, mapping *FieldMapping, lossy bool) (*RowConverter, error) {
 	if nec, err := IsNecessary(mapping.SrcSch, mapping.DestSch, mapping.SrcToDest); err != nil {
 		return nil, err
 	} else if !nec {
@@ -57,11 +68,21 @@ func NewRowConverter(ctx context.Context, vrw types.ValueReadWriter, mapping *Fi
 		}
 
 		tc, _, err := typeinfo.GetTypeConverter(ctx, srcCol.TypeInfo, destCol.TypeInfo)
-		if err != nil {
+		if err != nil && !lossy {
 			return nil, err
+		} else if err != nil {
+			convFuncs[srcTag] = func(types.Value) (types.Value, error) {
+				return types.NullValue, nil
+			}
+			continue
 		}
+
 		convFuncs[srcTag] = func(v types.Value) (types.Value, error) {
-			return tc(ctx, vrw, v)
+			outVal, err := tc(ctx, vrw, v)
+			if err != nil && lossy {
+				return types.NullValue, nil
+			}
+			return outVal, err
 		}
 	}
 
@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/row"
@@ -100,3 +101,44 @@ func TestUnneccessaryConversion(t *testing.T) {
 		t.Fatal("expected identity converter")
 	}
 }
+
+func TestLossyRowConverter(t *testing.T) {
+	strSch := schema.MustSchemaFromCols(schema.NewColCollection(
+		schema.NewColumn("pk", 0, types.StringKind, true),
+		schema.NewColumn("val", 1, types.StringKind, false),
+	))
+	intSch := schema.MustSchemaFromCols(schema.NewColCollection(
+		schema.NewColumn("pk", 0, types.StringKind, true),
+		schema.NewColumn("val", 2, types.IntKind, false),
+	))
+
+	mapping, err := NewFieldMapping(strSch, intSch, map[uint64]uint64{0: 0, 1: 2})
+	require.NoError(t, err)
+
+	vrw := types.NewMemoryValueStore()
+	rConv, err := NewLossyRowConverter(context.Background(), vrw, mapping)
+	require.NoError(t, err)
+
+	tests := []struct {
+		in       types.Value
+		expected types.Value
+	}{
+		{types.String("12"), types.Int(12)},
+		{types.String("twelve"), nil},
+	}
+
+	for _, test := range tests {
+		inRow, err := row.New(vrw.Format(), strSch, row.TaggedValues{0: types.String("a"), 1: test.in})
+		require.NoError(t, err)
+
+		outRow, err := rConv.Convert(inRow)
+		require.NoError(t, err)
+
+		v, ok := outRow.GetColVal(2)
+		if test.expected == nil {
+			assert.False(t, ok && !types.IsNull(v))
+		} else {
+			assert.Equal(t, test.expected, v)
+		}
+	}
+}
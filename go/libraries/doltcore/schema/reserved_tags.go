@@ -93,6 +93,14 @@ const (
 	DoltProceduresModifiedAtTag
 )
 
+// Tags for the dolt_merge_strategies table
+const (
+	DoltMergeStrategiesTableNameTag = iota + SystemTableReservedMin + uint64(7000)
+	DoltMergeStrategiesColumnNameTag
+	DoltMergeStrategiesStrategyTag
+	DoltMergeStrategiesTimestampColumnTag
+)
+
 const (
 	DoltConstraintViolationsTypeTag = 0
 	DoltConstraintViolationsInfoTag = math.MaxUint64
@@ -251,6 +251,73 @@ func (ss *SuperSchema) NameMapForSchema(sch Schema) (map[string]string, error) {
 	return inNameToOutName, nil
 }
 
+// LineageMapping creates a tag mapping from the columns of sch, a schema from the history of the SuperSchema, to the
+// columns of current, the schema the table has now. Columns are mapped by tag where possible. A column whose tag is
+// not in current, such as one whose type was changed, is mapped to a column of current which is not otherwise mapped,
+// preferring one with the same name, then one which has had any of the column's names. Columns of sch which can't be
+// mapped, such as those which have since been dropped, are not included in the mapping.
+func (ss *SuperSchema) LineageMapping(sch, current Schema) map[uint64]uint64 {
+	mapping := make(map[uint64]uint64)
+	mapped := set.NewUint64Set(nil)
+	var unmapped []Column
+	_ = sch.GetAllCols().Iter(func(tag uint64, col Column) (stop bool, err error) {
+		if _, ok := current.GetAllCols().GetByTag(tag); ok {
+			mapping[tag] = tag
+			mapped.Add(tag)
+		} else {
+			unmapped = append(unmapped, col)
+		}
+		return false, nil
+	})
+
+	if len(unmapped) == 0 {
+		return mapping
+	}
+
+	matchers := []func(col, curr Column) bool{
+		func(col, curr Column) bool {
+			return col.Name == curr.Name
+		},
+		func(col, curr Column) bool {
+			names := set.NewStrSet(ss.AllColumnNames(curr.Tag))
+			if names.Contains(col.Name) {
+				return true
+			}
+			for _, nm := range ss.AllColumnNames(col.Tag) {
+				if names.Contains(nm) {
+					return true
+				}
+			}
+			return false
+		},
+	}
+
+	for _, matches := range matchers {
+		remaining := unmapped[:0:0]
+		for _, col := range unmapped {
+			match, found := Column{}, false
+			_ = current.GetAllCols().Iter(func(tag uint64, curr Column) (stop bool, err error) {
+				if mapped.Contains(tag) {
+					return false, nil
+				}
+
+				match, found = curr, matches(col, curr)
+				return found, nil
+			})
+
+			if found {
+				mapping[col.Tag] = match.Tag
+				mapped.Add(match.Tag)
+			} else {
+				remaining = append(remaining, col)
+			}
+		}
+		unmapped = remaining
+	}
+
+	return mapping
+}
+
 // RebaseTag changes the tag of a column from oldTag to newTag.
 func (ss *SuperSchema) RebaseTag(tagMapping map[uint64]uint64) (*SuperSchema, error) {
 	tn := make(map[uint64][]string)
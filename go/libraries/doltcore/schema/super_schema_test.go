@@ -220,6 +220,35 @@ func testSuperSchemaUnion(t *testing.T) {
 	assert.Equal(t, expectedGeneratedSchema, gs)
 }
 
+func TestLineageMapping(t *testing.T) {
+	original := mustSchema([]Column{
+		strCol("a", 1, true),
+		strCol("b", 2, false),
+		strCol("c", 3, false),
+		strCol("d", 4, false),
+	})
+	// b renamed to bb, d dropped
+	renamed := mustSchema([]Column{
+		strCol("a", 1, true),
+		strCol("bb", 2, false),
+		strCol("c", 3, false),
+	})
+	// bb and c given new types, and so new tags, and a column added which has never been named c
+	current := mustSchema([]Column{
+		strCol("a", 1, true),
+		strCol("bb", 12, false),
+		strCol("e", 15, false),
+		strCol("c", 13, false),
+	})
+
+	ss, err := NewSuperSchema(original, renamed, current)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[uint64]uint64{1: 1, 2: 12, 3: 13}, ss.LineageMapping(original, current))
+	assert.Equal(t, map[uint64]uint64{1: 1, 2: 12, 3: 13}, ss.LineageMapping(renamed, current))
+	assert.Equal(t, map[uint64]uint64{1: 1, 12: 12, 13: 13, 15: 15}, ss.LineageMapping(current, current))
+}
+
 func superSchemaDeepEqual(t *testing.T, ss1, ss2 *SuperSchema) {
 	assert.Equal(t, ss1.tagNames, ss2.tagNames)
 	assert.Equal(t, *ss1.allCols, *ss2.allCols)
@@ -34,6 +34,7 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dtables"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/globalstate"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/privileges"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/writer"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor"
@@ -301,7 +302,12 @@ func (db Database) GetTableInsensitiveWithRoot(ctx *sql.Context, root *doltdb.Ro
 		return dt, true, nil
 	case strings.HasPrefix(lwrName, doltdb.DoltCommitDiffTablePrefix):
 		suffix := tblName[len(doltdb.DoltCommitDiffTablePrefix):]
-		dt, err := dtables.NewCommitDiffTable(ctx, suffix, db.ddb, root)
+		head, err := sess.GetHeadCommit(ctx, db.name)
+		if err != nil {
+			return nil, false, err
+		}
+		roots, _ := sess.GetRoots(ctx, db.name)
+		dt, err := dtables.NewCommitDiffTable(ctx, suffix, db.ddb, root, roots.Staged, head)
 		if err != nil {
 			return nil, false, err
 		}
@@ -317,9 +323,20 @@ func (db Database) GetTableInsensitiveWithRoot(ctx *sql.Context, root *doltdb.Ro
 			return nil, false, err
 		}
 		return dt, true, nil
+	case strings.HasPrefix(lwrName, doltdb.DoltBlameTablePrefix):
+		suffix := tblName[len(doltdb.DoltBlameTablePrefix):]
+		head, err := sess.GetHeadCommit(ctx, db.name)
+		if err != nil {
+			return nil, false, err
+		}
+		dt, err := dtables.NewBlameTable(ctx, suffix, db.ddb, head)
+		if err != nil {
+			return nil, false, err
+		}
+		return dt, true, nil
 	case strings.HasPrefix(lwrName, doltdb.DoltConfTablePrefix):
 		suffix := tblName[len(doltdb.DoltConfTablePrefix):]
-		dt, err := dtables.NewConflictsTable(ctx, suffix, root, dtables.RootSetter(db))
+		dt, err := dtables.NewConflictsTable(ctx, suffix, root, dtables.RootSetter(db), db.editOpts)
 		if err != nil {
 			return nil, false, err
 		}
@@ -343,30 +360,66 @@ func (db Database) GetTableInsensitiveWithRoot(ctx *sql.Context, root *doltdb.Ro
 			return nil, false, err
 		}
 		dt, found = dtables.NewLogTable(ctx, db.ddb, head), true
+	case doltdb.ColumnDiffTableName:
+		head, err := sess.GetHeadCommit(ctx, db.name)
+		if err != nil {
+			return nil, false, err
+		}
+		dt, found = dtables.NewColumnDiffTable(ctx, db.ddb, head), true
 	case doltdb.TableOfTablesInConflictName:
 		dt, found = dtables.NewTableOfTablesInConflict(ctx, db.ddb, root), true
 	case doltdb.TableOfTablesWithViolationsName:
 		dt, found = dtables.NewTableOfTablesConstraintViolations(ctx, root), true
 	case doltdb.BranchesTableName:
-		dt, found = dtables.NewBranchesTable(ctx, db.ddb), true
+		dt, found = dtables.NewBranchesTable(ctx, db.name, db.ddb), true
 	case doltdb.RemotesTableName:
 		dt, found = dtables.NewRemotesTable(ctx, db.ddb), true
+	case doltdb.RemoteBranchesTableName:
+		dt, found = dtables.NewRemoteBranchesTable(ctx, db.ddb), true
+	case doltdb.TagsTableName:
+		dt, found = dtables.NewTagsTable(ctx, db.name, db.ddb), true
 	case doltdb.CommitsTableName:
 		dt, found = dtables.NewCommitsTable(ctx, db.ddb), true
 	case doltdb.CommitAncestorsTableName:
 		dt, found = dtables.NewCommitAncestorsTable(ctx, db.ddb), true
 	case doltdb.StatusTableName:
 		dt, found = dtables.NewStatusTable(ctx, db.name, db.ddb, dsess.NewSessionStateAdapter(sess.Session, db.name, map[string]env.Remote{}, map[string]env.BranchConfig{}), db.drw), true
+	case doltdb.StashesTableName:
+		dt, found = dtables.NewStashesTable(ctx, db.ddb), true
+	case doltdb.ReflogTableName:
+		dt, found = dtables.NewReflogTable(ctx, db.ddb), true
+	case doltdb.BranchControlTableName:
+		store, _ := sess.BranchAccessController().(*privileges.Store)
+		dt, found = dtables.NewBranchControlTable(ctx, store), true
+	case doltdb.SchemaDiffTableName:
+		head, err := sess.GetHeadCommit(ctx, db.name)
+		if err != nil {
+			return nil, false, err
+		}
+		roots, _ := sess.GetRoots(ctx, db.name)
+		dt, found = NewSchemaDiffTable(ctx, db.ddb, root, roots.Staged, head), true
 	}
 	if found {
 		return dt, found, nil
 	}
 
-	return db.getTable(ctx, root, tblName, false)
+	tbl, found, err := db.getTable(ctx, root, tblName, false)
+	if err == nil && !found && lwrName == doltdb.MergeStrategiesTableName {
+		return newEmptyMergeStrategiesTable(db), true, nil
+	}
+
+	return tbl, found, err
 }
 
 // GetTableInsensitiveAsOf implements sql.VersionedDatabase
 func (db Database) GetTableInsensitiveAsOf(ctx *sql.Context, tableName string, asOf interface{}) (sql.Table, bool, error) {
+	lwrName := strings.ToLower(tableName)
+	if strings.HasPrefix(lwrName, doltdb.DoltDiffTablePrefix) ||
+		strings.HasPrefix(lwrName, doltdb.DoltHistoryTablePrefix) ||
+		strings.HasPrefix(lwrName, doltdb.DoltBlameTablePrefix) {
+		return db.getHistorySystemTableAsOf(ctx, tableName, asOf)
+	}
+
 	root, err := db.rootAsOf(ctx, asOf)
 
 	if err != nil {
@@ -395,20 +448,67 @@ func (db Database) GetTableInsensitiveAsOf(ctx *sql.Context, tableName string, a
 	}
 }
 
+// getHistorySystemTableAsOf returns the dolt_diff_, dolt_history_ or dolt_blame_ system table with the name given as of
+// the commit given by the expression |asOf|. The table shows the history of the commits reachable from that commit,
+// projected onto the schema the table had at that commit.
+func (db Database) getHistorySystemTableAsOf(ctx *sql.Context, tableName string, asOf interface{}) (sql.Table, bool, error) {
+	cm, err := db.commitAsOf(ctx, asOf)
+	if err != nil {
+		return nil, false, err
+	} else if cm == nil {
+		return nil, false, nil
+	}
+
+	root, err := cm.GetRootValue()
+	if err != nil {
+		return nil, false, err
+	}
+
+	lwrName := strings.ToLower(tableName)
+
+	var dt sql.Table
+	switch {
+	case strings.HasPrefix(lwrName, doltdb.DoltDiffTablePrefix):
+		dt, err = dtables.NewDiffTable(ctx, tableName[len(doltdb.DoltDiffTablePrefix):], db.ddb, root, cm)
+	case strings.HasPrefix(lwrName, doltdb.DoltHistoryTablePrefix):
+		dt, err = dtables.NewHistoryTable(ctx, tableName[len(doltdb.DoltHistoryTablePrefix):], db.ddb, root, cm)
+	default:
+		dt, err = dtables.NewBlameTable(ctx, tableName[len(doltdb.DoltBlameTablePrefix):], db.ddb, cm)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return dt, true, nil
+}
+
 // rootAsOf returns the root of the DB as of the expression given, which may be nil in the case that it refers to an
 // expression before the first commit.
 func (db Database) rootAsOf(ctx *sql.Context, asOf interface{}) (*doltdb.RootValue, error) {
+	cm, err := db.commitAsOf(ctx, asOf)
+	if err != nil || cm == nil {
+		return nil, err
+	}
+
+	return cm.GetRootValue()
+}
+
+// commitAsOf returns the commit of the DB as of the expression given, which may be nil in the case that it refers to
+// an expression before the first commit.
+func (db Database) commitAsOf(ctx *sql.Context, asOf interface{}) (*doltdb.Commit, error) {
 	switch x := asOf.(type) {
 	case string:
-		return db.getRootForCommitRef(ctx, x)
+		return db.getCommitForCommitRef(ctx, x)
 	case time.Time:
-		return db.getRootForTime(ctx, x)
+		return db.getCommitForTime(ctx, x)
 	default:
 		panic(fmt.Sprintf("unsupported AS OF type %T", asOf))
 	}
 }
 
-func (db Database) getRootForTime(ctx *sql.Context, asOf time.Time) (*doltdb.RootValue, error) {
+// getCommitForTime returns the latest commit reachable from HEAD which was made at or before |asOf|, or nil if there is
+// no such commit.
+func (db Database) getCommitForTime(ctx *sql.Context, asOf time.Time) (*doltdb.Commit, error) {
 	cs, err := doltdb.NewCommitSpec("HEAD")
 	if err != nil {
 		return nil, err
@@ -429,6 +529,12 @@ func (db Database) getRootForTime(ctx *sql.Context, asOf time.Time) (*doltdb.Roo
 		return nil, err
 	}
 
+	// Every commit reachable from HEAD is considered, not only those on its first-parent chain. A commit brought in by a
+	// merge may have been made after any commit on the branch it was merged into, so the latest commit made at or
+	// before the time given can't be found by stopping at the first match. Ties go to the commit which comes first in
+	// topological order.
+	var latest *doltdb.Commit
+	var latestTime time.Time
 	for {
 		_, curr, err := cmItr.Next(ctx)
 		if err == io.EOF {
@@ -442,31 +548,26 @@ func (db Database) getRootForTime(ctx *sql.Context, asOf time.Time) (*doltdb.Roo
 			return nil, err
 		}
 
-		if meta.Time().Equal(asOf) || meta.Time().Before(asOf) {
-			return curr.GetRootValue()
+		cmTime := meta.Time()
+		if cmTime.After(asOf) {
+			continue
+		}
+
+		if latest == nil || cmTime.After(latestTime) {
+			latest, latestTime = curr, cmTime
 		}
 	}
 
-	return nil, nil
+	return latest, nil
 }
 
-func (db Database) getRootForCommitRef(ctx *sql.Context, commitRef string) (*doltdb.RootValue, error) {
+func (db Database) getCommitForCommitRef(ctx *sql.Context, commitRef string) (*doltdb.Commit, error) {
 	cs, err := doltdb.NewCommitSpec(commitRef)
 	if err != nil {
 		return nil, err
 	}
 
-	cm, err := db.ddb.Resolve(ctx, cs, db.rsr.CWBHeadRef())
-	if err != nil {
-		return nil, err
-	}
-
-	root, err := cm.GetRootValue()
-	if err != nil {
-		return nil, err
-	}
-
-	return root, nil
+	return db.ddb.Resolve(ctx, cs, db.rsr.CWBHeadRef())
 }
 
 // GetTableNamesAsOf implements sql.VersionedDatabase
@@ -48,12 +48,15 @@ type DoltDatabaseProvider struct {
 	cfg         config.ReadableConfig
 
 	dbFactoryUrl string
+
+	branchAccess dsess.BranchAccessController
 }
 
 var _ sql.DatabaseProvider = DoltDatabaseProvider{}
 var _ sql.FunctionProvider = DoltDatabaseProvider{}
 var _ sql.MutableDatabaseProvider = DoltDatabaseProvider{}
 var _ dsess.RevisionDatabaseProvider = DoltDatabaseProvider{}
+var _ dsess.BranchAccessProvider = DoltDatabaseProvider{}
 
 const createDbWC = 1105 // 1105 represents an unknown error.
 
@@ -98,6 +101,18 @@ func (p DoltDatabaseProvider) WithDbFactoryUrl(url string) DoltDatabaseProvider
 	return p
 }
 
+// WithBranchAccessController returns a copy of this provider which restricts the branches clients may write to with
+// the BranchAccessController given.
+func (p DoltDatabaseProvider) WithBranchAccessController(bac dsess.BranchAccessController) DoltDatabaseProvider {
+	p.branchAccess = bac
+	return p
+}
+
+// BranchAccessController implements dsess.BranchAccessProvider
+func (p DoltDatabaseProvider) BranchAccessController() dsess.BranchAccessController {
+	return p.branchAccess
+}
+
 func (p DoltDatabaseProvider) Database(name string) (db sql.Database, err error) {
 	name = strings.ToLower(name)
 	var ok bool
@@ -0,0 +1,183 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dfunctions
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/merge"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema/typeinfo"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+)
+
+const DoltCherryPickFuncName = "dolt_cherry_pick"
+
+// DoltCherryPickFunc runs a `dolt cherry-pick` in the SQL context, applying the changes introduced by a commit to the
+// working set and committing the result.
+type DoltCherryPickFunc struct {
+	expression.NaryExpression
+}
+
+var _ sql.Expression = (*DoltCherryPickFunc)(nil)
+
+// NewDoltCherryPickFunc creates a new DoltCherryPickFunc expression whose children represents the args passed in
+// DOLT_CHERRY_PICK.
+func NewDoltCherryPickFunc(args ...sql.Expression) (sql.Expression, error) {
+	return &DoltCherryPickFunc{expression.NaryExpression{ChildExpressions: args}}, nil
+}
+
+// Eval implements the Expression interface. Mirroring DOLT_MERGE, it returns 1 when the commit was applied cleanly,
+// and 0 when the changes were written to the working set with conflicts or constraint violations.
+func (d DoltCherryPickFunc) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
+	dbName := ctx.GetCurrentDatabase()
+	if len(dbName) == 0 {
+		return noConflicts, fmt.Errorf("Empty database name.")
+	}
+
+	dSess := dsess.DSessFromSess(ctx.Session)
+	ddb, ok := dSess.GetDoltDB(ctx, dbName)
+	if !ok {
+		return noConflicts, fmt.Errorf("dolt database could not be found")
+	}
+
+	args, err := getDoltArgs(ctx, row, d.ChildExpressions)
+	if err != nil {
+		return noConflicts, err
+	}
+
+	apr, err := cli.CreateCherryPickArgParser().Parse(args)
+	if err != nil {
+		return noConflicts, err
+	}
+	if apr.NArg() != 1 {
+		return noConflicts, fmt.Errorf("%s takes exactly one revision", strings.ToUpper(DoltCherryPickFuncName))
+	}
+
+	workingSet, err := dSess.WorkingSet(ctx, dbName)
+	if err != nil {
+		return noConflicts, err
+	}
+	if workingSet.MergeActive() {
+		return noConflicts, doltdb.ErrMergeActive
+	}
+
+	roots, ok := dSess.GetRoots(ctx, dbName)
+	if !ok {
+		return noConflicts, sql.ErrDatabaseNotFound.New(dbName)
+	}
+	headHash, err := roots.Head.HashOf()
+	if err != nil {
+		return noConflicts, err
+	}
+	workingHash, err := roots.Working.HashOf()
+	if err != nil {
+		return noConflicts, err
+	}
+	if !headHash.Equal(workingHash) {
+		return noConflicts, fmt.Errorf("you must commit any changes before using cherry-pick")
+	}
+
+	headRef, err := dSess.CWBHeadRef(ctx, dbName)
+	if err != nil {
+		return noConflicts, err
+	}
+	commitSpec, err := doltdb.NewCommitSpec(apr.Arg(0))
+	if err != nil {
+		return noConflicts, err
+	}
+	commit, err := ddb.Resolve(ctx, commitSpec, headRef)
+	if err != nil {
+		return noConflicts, err
+	}
+
+	dbState, ok, err := dSess.LookupDbState(ctx, dbName)
+	if err != nil {
+		return noConflicts, err
+	} else if !ok {
+		return noConflicts, sql.ErrDatabaseNotFound.New(dbName)
+	}
+
+	workingRoot, commitMessage, tblToStats, err := merge.CherryPick(ctx, ddb, roots.Working, commit, dbState.EditOpts())
+	if err != nil {
+		return noConflicts, err
+	}
+
+	workingHash, err = workingRoot.HashOf()
+	if err != nil {
+		return noConflicts, err
+	}
+	if headHash.Equal(workingHash) {
+		return noConflicts, nil
+	}
+
+	if merge.HasConflictsOrViolations(tblToStats) {
+		// conflicts are recoverable in-session, so we write the working set back to the session and return a warning
+		err = dSess.SetWorkingSet(ctx, dbName, workingSet.WithWorkingRoot(workingRoot), nil)
+		if err != nil {
+			return hasConflicts, err
+		}
+		ctx.Warn(DoltMergeWarningCode, "cherry-pick resulted in conflicts or constraint violations; fix them and then commit the result")
+		return hasConflicts, nil
+	}
+
+	err = dSess.SetRoot(ctx, dbName, workingRoot)
+	if err != nil {
+		return noConflicts, err
+	}
+
+	stringType := typeinfo.StringDefaultType.ToSqlType()
+	expressions := []sql.Expression{expression.NewLiteral("-a", stringType), expression.NewLiteral("-m", stringType), expression.NewLiteral(commitMessage, stringType)}
+	if author, hasAuthor := apr.GetValue(cli.AuthorParam); hasAuthor {
+		expressions = append(expressions, expression.NewLiteral("--author", stringType), expression.NewLiteral(author, stringType))
+	}
+
+	commitFunc, err := NewDoltCommitFunc(expressions...)
+	if err != nil {
+		return noConflicts, err
+	}
+	_, err = commitFunc.Eval(ctx, row)
+	if err != nil {
+		return noConflicts, err
+	}
+
+	return noConflicts, nil
+}
+
+// String implements the Stringer interface.
+func (d DoltCherryPickFunc) String() string {
+	childrenStrings := make([]string, len(d.Children()))
+
+	for i, child := range d.Children() {
+		childrenStrings[i] = child.String()
+	}
+
+	return fmt.Sprintf("DOLT_CHERRY_PICK(%s)", strings.Join(childrenStrings, ","))
+}
+
+// Type implements the Expression interface.
+func (d DoltCherryPickFunc) Type() sql.Type {
+	return sql.Boolean
+}
+
+// WithChildren implements the Expression interface.
+func (d DoltCherryPickFunc) WithChildren(children ...sql.Expression) (sql.Expression, error) {
+	return NewDoltCherryPickFunc(children...)
+}
@@ -88,7 +88,7 @@ func (d DoltBranchFunc) Eval(ctx *sql.Context, row sql.Row) (interface{}, error)
 
 	switch {
 	case apr.Contains(cli.CopyFlag):
-		err = makeACopyOfBranch(ctx, dbData, apr)
+		err = makeACopyOfBranch(ctx, dbName, dbData, apr)
 		if err != nil {
 			return 1, err
 		}
@@ -109,7 +109,7 @@ func (d DoltBranchFunc) Eval(ctx *sql.Context, row sql.Row) (interface{}, error)
 			return 1, EmptyBranchNameErr
 		}
 
-		err = createNewBranch(ctx, dbData, branchName)
+		err = createNewBranch(ctx, dbName, dbData, branchName)
 		if err != nil {
 			return 1, err
 		}
@@ -118,7 +118,12 @@ func (d DoltBranchFunc) Eval(ctx *sql.Context, row sql.Row) (interface{}, error)
 	return 0, nil
 }
 
-func createNewBranch(ctx *sql.Context, dbData env.DbData, branchName string) error {
+func createNewBranch(ctx *sql.Context, dbName string, dbData env.DbData, branchName string) error {
+	err := dsess.DSessFromSess(ctx.Session).CheckBranchWrite(ctx, dbName, branchName)
+	if err != nil {
+		return err
+	}
+
 	// Check if the branch already exists.
 	isBranch, err := actions.IsBranch(ctx, dbData.Ddb, branchName)
 	if err != nil {
@@ -131,7 +136,7 @@ func createNewBranch(ctx *sql.Context, dbData env.DbData, branchName string) err
 	return actions.CreateBranchWithStartPt(ctx, dbData, branchName, startPt, false)
 }
 
-func makeACopyOfBranch(ctx *sql.Context, dbData env.DbData, apr *argparser.ArgParseResults) error {
+func makeACopyOfBranch(ctx *sql.Context, dbName string, dbData env.DbData, apr *argparser.ArgParseResults) error {
 	if apr.NArg() != 2 {
 		return InvalidArgErr
 	}
@@ -146,6 +151,11 @@ func makeACopyOfBranch(ctx *sql.Context, dbData env.DbData, apr *argparser.ArgPa
 		return EmptyBranchNameErr
 	}
 
+	err := dsess.DSessFromSess(ctx.Session).CheckBranchWrite(ctx, dbName, destBr)
+	if err != nil {
+		return err
+	}
+
 	force := apr.Contains(cli.ForceFlag)
 	return copyABranch(ctx, dbData, srcBr, destBr, force)
 }
@@ -135,7 +135,12 @@ func checkoutNewBranch(ctx *sql.Context, dbName string, dbData env.DbData, roots
 		startPt = "head"
 	}
 
-	err := actions.CreateBranchWithStartPt(ctx, dbData, branchName, startPt, false)
+	err := dsess.DSessFromSess(ctx.Session).CheckBranchWrite(ctx, dbName, branchName)
+	if err != nil {
+		return err
+	}
+
+	err = actions.CreateBranchWithStartPt(ctx, dbData, branchName, startPt, false)
 	if err != nil {
 		return err
 	}
@@ -93,7 +93,18 @@ func (d DoltFetchFunc) Eval(ctx *sql.Context, row sql.Row) (interface{}, error)
 
 	updateMode := ref.UpdateMode{Force: apr.Contains(cli.ForceFlag)}
 
-	err = actions.FetchRefSpecs(ctx, dbData, refSpecs, remote, updateMode, runProgFuncs, stopProgFuncs)
+	depth := apr.GetIntOrDefault(cli.DepthParam, 0)
+	deepen := apr.GetIntOrDefault(cli.DeepenParam, 0)
+	if (apr.Contains(cli.DepthParam) && depth <= 0) || (apr.Contains(cli.DeepenParam) && deepen <= 0) {
+		return cmdFailure, fmt.Errorf("--%s and --%s must be a positive number of commits", cli.DepthParam, cli.DeepenParam)
+	} else if depth > 0 && deepen > 0 {
+		return cmdFailure, fmt.Errorf("--%s and --%s cannot be used together", cli.DepthParam, cli.DeepenParam)
+	}
+
+	err = actions.FetchRefSpecs(ctx, dbData, refSpecs, remote, updateMode, depth, runProgFuncs, stopProgFuncs)
+	if err == nil && deepen > 0 {
+		err = actions.DeepenShallowClone(ctx, dbData, remote, deepen, runProgFuncs, stopProgFuncs)
+	}
 	if err != nil {
 		return cmdFailure, fmt.Errorf("fetch failed: %w", err)
 	}
@@ -0,0 +1,123 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dfunctions
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/store/chunks"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+const DoltGCFuncName = "dolt_gc"
+
+// DoltGCFunc garbage collects the current database. The collection runs while other sessions keep reading and
+// writing the database; the chunks they write during the collection are kept, as are the uncommitted changes of
+// their open transactions, and they are only paused briefly while the collected table files are swapped in. It
+// returns a JSON document describing the collection.
+type DoltGCFunc struct {
+}
+
+// NewDoltGCFunc creates a new DoltGCFunc expression.
+func NewDoltGCFunc() sql.Expression {
+	return &DoltGCFunc{}
+}
+
+// Eval implements the Expression interface.
+func (d *DoltGCFunc) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
+	dbName := ctx.GetCurrentDatabase()
+
+	if len(dbName) == 0 {
+		return nil, fmt.Errorf("empty database name")
+	}
+
+	dSess := dsess.DSessFromSess(ctx.Session)
+	ddb, ok := dSess.GetDoltDB(ctx, dbName)
+	if !ok {
+		return nil, sql.ErrDatabaseNotFound.New(dbName)
+	}
+
+	roots, ok := dSess.GetRoots(ctx, dbName)
+	if !ok {
+		return nil, sql.ErrDatabaseNotFound.New(dbName)
+	}
+
+	// the working and staged roots of this session, and of the transactions open in other sessions, may not have been
+	// written to the database yet
+	toKeep := append(dsess.OpenTransactionRoots(ddb), roots.Working, roots.Staged)
+	var keepers []hash.Hash
+	for _, root := range toKeep {
+		h, err := ddb.WriteRootValue(ctx, root)
+		if err != nil {
+			return nil, err
+		}
+		keepers = append(keepers, h)
+	}
+
+	stats, err := ddb.OnlineGC(ctx, keepers...)
+	if errors.Is(err, chunks.ErrNothingToCollect) {
+		stats = doltdb.GCStats{}
+	} else if err != nil {
+		return nil, fmt.Errorf("gc failed: %w", err)
+	}
+
+	return sql.JSONDocument{Val: map[string]interface{}{
+		"bytes_before":       stats.BytesBefore,
+		"bytes_after":        stats.BytesAfter,
+		"bytes_reclaimed":    stats.BytesReclaimed(),
+		"chunks_kept":        stats.ChunksKept,
+		"concurrent_chunks":  stats.ConcurrentChunks,
+		"safepoint_pause_ms": stats.SafepointPause.Milliseconds(),
+		"duration_ms":        stats.Duration.Milliseconds(),
+	}}, nil
+}
+
+// String implements the Stringer interface.
+func (d *DoltGCFunc) String() string {
+	return "DOLT_GC()"
+}
+
+// IsNullable implements the Expression interface.
+func (d *DoltGCFunc) IsNullable() bool {
+	return false
+}
+
+// Resolved implements the Expression interface.
+func (*DoltGCFunc) Resolved() bool {
+	return true
+}
+
+// Type implements the Expression interface.
+func (d *DoltGCFunc) Type() sql.Type {
+	return sql.JSON
+}
+
+// Children implements the Expression interface.
+func (*DoltGCFunc) Children() []sql.Expression {
+	return nil
+}
+
+// WithChildren implements the Expression interface.
+func (d *DoltGCFunc) WithChildren(children ...sql.Expression) (sql.Expression, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(d, len(children), 0)
+	}
+	return NewDoltGCFunc(), nil
+}
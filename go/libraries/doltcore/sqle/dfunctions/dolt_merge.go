@@ -83,6 +83,9 @@ func (d DoltMergeFunc) Eval(ctx *sql.Context, row sql.Row) (interface{}, error)
 		if !ws.MergeActive() {
 			return noConflicts, fmt.Errorf("fatal: There is no merge to abort")
 		}
+		if ws.RebaseActive() {
+			return noConflicts, doltdb.ErrRebaseActive
+		}
 
 		ws, err = abortMerge(ctx, ws, roots)
 		if err != nil {
@@ -147,6 +150,11 @@ func mergeIntoWorkingSet(ctx *sql.Context, sess *dsess.DoltSession, roots doltdb
 		return ws, noConflicts, fmt.Errorf("failed to get dbData")
 	}
 
+	err = sess.CheckBranchWrite(ctx, dbName, dbData.Rsr.CWBHeadRef().GetPath())
+	if err != nil {
+		return ws, noConflicts, err
+	}
+
 	canFF, err := spec.HeadC.CanFastForwardTo(ctx, spec.MergeC)
 	if err != nil {
 		switch err {
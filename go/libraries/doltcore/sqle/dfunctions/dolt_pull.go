@@ -106,6 +106,12 @@ func (d DoltPullFunc) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
 		return noConflicts, err
 	}
 
+	// check before fetching, pulling into a branch that can't be written to is pointless
+	err = sess.CheckBranchWrite(ctx, dbName, dbData.Rsr.CWBHeadRef().GetPath())
+	if err != nil {
+		return noConflicts, err
+	}
+
 	var conflicts interface{}
 	for _, refSpec := range pullSpec.RefSpecs {
 		remoteTrackRef := refSpec.DestRef(pullSpec.Branch)
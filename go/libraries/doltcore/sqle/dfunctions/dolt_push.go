@@ -25,6 +25,7 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
 	"github.com/dolthub/dolt/go/libraries/doltcore/env"
 	"github.com/dolthub/dolt/go/libraries/doltcore/env/actions"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
 	"github.com/dolthub/dolt/go/store/datas"
 )
@@ -87,6 +88,16 @@ func (d DoltPushFunc) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
 	if err != nil {
 		return cmdFailure, err
 	}
+
+	// a push publishes a local branch, and sets its upstream when asked to, so the rules for the local branch apply,
+	// whatever the branch is named on the remote. Tags are not covered by branch rules.
+	if opts.SrcRef.GetType() == ref.BranchRefType {
+		err = sess.CheckBranchWrite(ctx, dbName, opts.SrcRef.GetPath())
+		if err != nil {
+			return cmdFailure, err
+		}
+	}
+
 	err = actions.DoPush(ctx, dbData.Rsr, dbData.Rsw, dbData.Ddb, dbData.Rsw.TempTableFilesDir(), opts, runProgFuncs, stopProgFuncs)
 	if err != nil {
 		switch err {
@@ -78,6 +78,11 @@ func (d DoltResetFunc) Eval(ctx *sql.Context, row sql.Row) (interface{}, error)
 			arg = apr.Arg(0)
 		}
 
+		err = dSess.CheckBranchWrite(ctx, dbName, dbData.Rsr.CWBHeadRef().GetPath())
+		if err != nil {
+			return 1, err
+		}
+
 		var newHead *doltdb.Commit
 		newHead, roots, err = actions.ResetHardTables(ctx, dbData, arg, roots)
 		if err != nil {
@@ -0,0 +1,136 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dfunctions
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env/actions"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+)
+
+const DoltTagFuncName = "dolt_tag"
+
+type DoltTagFunc struct {
+	expression.NaryExpression
+}
+
+// NewDoltTagFunc creates a DOLT_TAG() function, which creates and deletes tags with the same arguments as dolt tag.
+func NewDoltTagFunc(args ...sql.Expression) (sql.Expression, error) {
+	return &DoltTagFunc{expression.NaryExpression{ChildExpressions: args}}, nil
+}
+
+func (d DoltTagFunc) String() string {
+	childrenStrings := make([]string, len(d.Children()))
+
+	for i, child := range d.Children() {
+		childrenStrings[i] = child.String()
+	}
+
+	return fmt.Sprintf("DOLT_TAG(%s)", strings.Join(childrenStrings, ","))
+}
+
+func (d DoltTagFunc) Type() sql.Type {
+	return sql.Int8
+}
+
+func (d DoltTagFunc) WithChildren(children ...sql.Expression) (sql.Expression, error) {
+	return NewDoltTagFunc(children...)
+}
+
+func (d DoltTagFunc) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
+	dbName := ctx.GetCurrentDatabase()
+
+	if len(dbName) == 0 {
+		return 1, fmt.Errorf("Empty database name.")
+	}
+
+	ap := cli.CreateTagArgParser()
+
+	args, err := getDoltArgs(ctx, row, d.Children())
+	if err != nil {
+		return 1, err
+	}
+
+	apr, err := ap.Parse(args)
+	if err != nil {
+		return 1, err
+	}
+
+	dSess := dsess.DSessFromSess(ctx.Session)
+	dbData, ok := dSess.GetDbData(ctx, dbName)
+	if !ok {
+		return 1, fmt.Errorf("Could not load database %s", dbName)
+	}
+
+	if apr.NArg() == 0 {
+		return 1, InvalidArgErr
+	}
+
+	// delete tags
+	if apr.Contains(cli.DeleteFlag) {
+		if apr.Contains(cli.CommitMessageArg) {
+			return 1, errors.New("delete and tag message options are incompatible")
+		}
+
+		err = actions.DeleteTagsOnDB(ctx, dbData.Ddb, apr.Args...)
+		if err == doltdb.ErrTagNotFound {
+			return 1, fmt.Errorf("error: %w", err)
+		} else if err != nil {
+			return 1, err
+		}
+
+		return 0, nil
+	}
+
+	// create a tag
+	if apr.NArg() > 2 {
+		return 1, errors.New("create tag takes at most two args")
+	}
+
+	tagName := apr.Arg(0)
+	startPoint := "head"
+	if apr.NArg() > 1 {
+		startPoint = apr.Arg(1)
+	}
+
+	msg, _ := apr.GetValue(cli.CommitMessageArg)
+	props := actions.TagProps{
+		TaggerName:  dSess.Username(),
+		TaggerEmail: dSess.Email(),
+		Description: msg,
+	}
+
+	headRef, err := dSess.CWBHeadRef(ctx, dbName)
+	if err != nil {
+		return 1, err
+	}
+
+	err = actions.CreateTagOnDB(ctx, dbData.Ddb, tagName, startPoint, props, headRef)
+	if err == actions.ErrAlreadyExists {
+		return 1, fmt.Errorf("fatal: tag '%s' already exists", tagName)
+	} else if err != nil {
+		return 1, err
+	}
+
+	return 0, nil
+}
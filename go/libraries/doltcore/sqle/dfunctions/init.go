@@ -33,10 +33,13 @@ var DoltFunctions = []sql.Function{
 	sql.FunctionN{Name: ConstraintsVerifyFuncName, Fn: NewConstraintsVerifyFunc},
 	sql.FunctionN{Name: ConstraintsVerifyAllFuncName, Fn: NewConstraintsVerifyAllFunc},
 	sql.FunctionN{Name: RevertFuncName, Fn: NewRevertFunc},
+	sql.FunctionN{Name: DoltCherryPickFuncName, Fn: NewDoltCherryPickFunc},
 	sql.FunctionN{Name: DoltPullFuncName, Fn: NewPullFunc},
 	sql.FunctionN{Name: DoltFetchFuncName, Fn: NewFetchFunc},
 	sql.FunctionN{Name: DoltPushFuncName, Fn: NewPushFunc},
 	sql.FunctionN{Name: DoltBranchFuncName, Fn: NewDoltBranchFunc},
+	sql.FunctionN{Name: DoltTagFuncName, Fn: NewDoltTagFunc},
+	sql.Function0{Name: DoltGCFuncName, Fn: NewDoltGCFunc},
 }
 
 // These are the DoltFunctions that get exposed to Dolthub Api.
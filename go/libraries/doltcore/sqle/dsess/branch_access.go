@@ -0,0 +1,53 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dsess
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// BranchAccessController decides which branches the clients of sessions may write to.
+type BranchAccessController interface {
+	// CheckBranchWrite returns an error if the client of the session of |ctx| may not write to |branch| of the
+	// database named.
+	CheckBranchWrite(ctx *sql.Context, dbName, branch string) error
+}
+
+// BranchAccessProvider is implemented by RevisionDatabaseProviders which restrict the branches clients may write to.
+type BranchAccessProvider interface {
+	// BranchAccessController returns the BranchAccessController of the provider, or nil if writes to branches are not
+	// restricted.
+	BranchAccessController() BranchAccessController
+}
+
+// BranchAccessController returns the BranchAccessController of the session's database provider, or nil if writes to
+// branches are not restricted.
+func (sess *Session) BranchAccessController() BranchAccessController {
+	if bap, ok := sess.provider.(BranchAccessProvider); ok {
+		return bap.BranchAccessController()
+	}
+	return nil
+}
+
+// CheckBranchWrite returns an error if the client of the session may not write to |branch| of the database named.
+// Everything that updates a branch, like committing a transaction or creating, moving or deleting a branch, must check
+// that the write is allowed first.
+func (sess *Session) CheckBranchWrite(ctx *sql.Context, dbName, branch string) error {
+	bac := sess.BranchAccessController()
+	if bac == nil {
+		return nil
+	}
+	return bac.CheckBranchWrite(ctx, dbName, branch)
+}
@@ -0,0 +1,94 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dsess
+
+import (
+	"sync"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+)
+
+// openTransactions holds the working and staged roots of the transactions open in every session, which may reference
+// values that no ref of their database does until the transactions commit. Garbage collection keeps those values.
+var openTransactions = &openTransactionSet{roots: make(map[*DatabaseSessionState]openTransactionRoots)}
+
+type openTransactionSet struct {
+	mu    sync.Mutex
+	roots map[*DatabaseSessionState]openTransactionRoots
+}
+
+// openTransactionRoots are copies of the roots of a transaction, as a RootValue is updated in place when it is written.
+type openTransactionRoots struct {
+	ddb     *doltdb.DoltDB
+	working doltdb.RootValue
+	staged  doltdb.RootValue
+}
+
+// track records the current roots of |state|, replacing the ones recorded before.
+func (ots *openTransactionSet) track(state *DatabaseSessionState) {
+	if state.WorkingSet == nil || state.WorkingSet.WorkingRoot() == nil || state.WorkingSet.StagedRoot() == nil {
+		return
+	}
+
+	roots := openTransactionRoots{
+		ddb:     state.dbData.Ddb,
+		working: *state.WorkingSet.WorkingRoot(),
+		staged:  *state.WorkingSet.StagedRoot(),
+	}
+
+	ots.mu.Lock()
+	defer ots.mu.Unlock()
+	ots.roots[state] = roots
+}
+
+// untrack forgets the roots of |state|, once its transaction has ended.
+func (ots *openTransactionSet) untrack(state *DatabaseSessionState) {
+	ots.mu.Lock()
+	defer ots.mu.Unlock()
+	delete(ots.roots, state)
+}
+
+// OpenTransactionRoots returns the working and staged roots of the transactions open in every session on |ddb|. The
+// roots returned are copies, which the caller may write.
+func OpenTransactionRoots(ddb *doltdb.DoltDB) []*doltdb.RootValue {
+	openTransactions.mu.Lock()
+	defer openTransactions.mu.Unlock()
+
+	var roots []*doltdb.RootValue
+	for _, r := range openTransactions.roots {
+		if r.ddb == ddb {
+			working, staged := r.working, r.staged
+			roots = append(roots, &working, &staged)
+		}
+	}
+
+	return roots
+}
+
+// endTransaction forgets the roots of the transaction for the database named, which has been committed or
+// rolled back.
+func (sess *Session) endTransaction(dbName string) {
+	if state, ok := sess.dbStates[dbName]; ok {
+		openTransactions.untrack(state)
+	}
+}
+
+// Close forgets the roots of the transactions open in the session. It is called when the connection of the session
+// closes, as those transactions will never be committed or rolled back.
+func (sess *Session) Close() {
+	for _, state := range sess.dbStates {
+		openTransactions.untrack(state)
+	}
+}
@@ -15,6 +15,7 @@
 package dsess
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -45,6 +46,23 @@ func init() {
 	if ok {
 		transactionMergeStomp = true
 	}
+
+	doltdb.ContextReflogInfo = queryReflogInfo
+}
+
+// queryReflogInfo attributes the ref updates made in the course of a SQL query to the query and the client that sent
+// it.
+func queryReflogInfo(ctx context.Context) (command, user string) {
+	sqlCtx, ok := ctx.(*sql.Context)
+	if !ok || sqlCtx.Session == nil {
+		return "", ""
+	}
+
+	if client := sqlCtx.Client(); len(client.User) > 0 {
+		user = fmt.Sprintf("%s@%s", client.User, client.Address)
+	}
+
+	return strings.TrimSpace(sqlCtx.Query()), user
 }
 
 const TransactionMergeStompEnvKey = "DOLT_TRANSACTION_MERGE_STOMP"
@@ -234,6 +252,8 @@ func (sess *Session) newWorkingSetForHead(ctx *sql.Context, wsRef ref.WorkingSet
 // CommitTransaction commits the in-progress transaction for the database named. Depending on session settings, this
 // may write only a new working set, or may additionally create a new dolt commit for the current HEAD.
 func (sess *Session) CommitTransaction(ctx *sql.Context, dbName string, tx sql.Transaction) error {
+	defer sess.endTransaction(dbName)
+
 	if sess.BatchMode() == Batched {
 		err := sess.Flush(ctx, dbName)
 		if err != nil {
@@ -347,6 +367,19 @@ func (sess *Session) doCommit(ctx *sql.Context, dbName string, tx sql.Transactio
 		return nil, fmt.Errorf("expected a DoltTransaction")
 	}
 
+	// the working set is nil for detached heads, which cannot be committed to
+	if dbState.WorkingSet != nil {
+		headRef, err := dbState.WorkingSet.Ref().ToHeadRef()
+		if err != nil {
+			return nil, err
+		}
+
+		err = sess.CheckBranchWrite(ctx, dbName, headRef.GetPath())
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	mergedWorkingSet, newCommit, err := commitFunc(ctx, dtx, dbState.WorkingSet)
 	if err != nil {
 		return nil, err
@@ -386,6 +419,10 @@ func (sess *Session) NewPendingCommit(ctx *sql.Context, dbName string, roots dol
 		return nil, err
 	}
 
+	if sessionState.WorkingSet.RebaseActive() {
+		return nil, doltdb.ErrRebaseActive
+	}
+
 	var mergeParentCommits []*doltdb.Commit
 	if sessionState.WorkingSet.MergeActive() {
 		mergeParentCommits = []*doltdb.Commit{sessionState.WorkingSet.MergeState().Commit()}
@@ -401,6 +438,8 @@ func (sess *Session) NewPendingCommit(ctx *sql.Context, dbName string, roots dol
 
 // RollbackTransaction rolls the given transaction back
 func (sess *Session) RollbackTransaction(ctx *sql.Context, dbName string, tx sql.Transaction) error {
+	defer sess.endTransaction(dbName)
+
 	if !TransactionsDisabled(ctx) || dbName == "" {
 		return nil
 	}
@@ -577,6 +616,7 @@ func (sess *Session) setRoot(ctx *sql.Context, dbName string, newRoot *doltdb.Ro
 	}
 
 	sessionState.WorkingSet = sessionState.WorkingSet.WithWorkingRoot(newRoot)
+	openTransactions.track(sessionState)
 
 	err = sessionState.WriteSession.SetRoot(ctx, newRoot)
 	if err != nil {
@@ -0,0 +1,365 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/diff"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/row"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+const blameDiffBufferSize = 1024
+
+var _ sql.Table = (*BlameTable)(nil)
+
+// BlameTable is a sql.Table implementation of a system table which attributes each row of a table to the commit which
+// last modified it, in the same way as dolt blame. Rows are blamed as of a commit, which is the session's head commit
+// unless the table is queried with AS OF, so changes in the working set are not reflected.
+type BlameTable struct {
+	tblName string
+	ddb     *doltdb.DoltDB
+	commit  *doltdb.Commit
+	tbl     *doltdb.Table
+	sch     schema.Schema
+	sqlSch  sql.Schema
+}
+
+// NewBlameTable creates a BlameTable for the table with the name given at |commit|
+func NewBlameTable(ctx *sql.Context, tblName string, ddb *doltdb.DoltDB, commit *doltdb.Commit) (sql.Table, error) {
+	root, err := commit.GetRootValue()
+	if err != nil {
+		return nil, err
+	}
+
+	tbl, resolvedName, ok, err := root.GetTableInsensitive(ctx, tblName)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, sql.ErrTableNotFound.New(doltdb.DoltBlameTablePrefix + tblName)
+	}
+	tblName = resolvedName
+
+	sch, err := tbl.GetSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if schema.IsKeyless(sch) {
+		return nil, fmt.Errorf("%s%s: blame is not supported for keyless tables", doltdb.DoltBlameTablePrefix, tblName)
+	}
+
+	name := doltdb.DoltBlameTablePrefix + tblName
+	pkSch, err := sqlutil.FromDoltSchema(name, sch)
+	if err != nil {
+		return nil, err
+	}
+
+	var sqlSch sql.Schema
+	for _, col := range pkSch.Schema {
+		if col.PrimaryKey {
+			sqlSch = append(sqlSch, col)
+		}
+	}
+
+	sqlSch = append(sqlSch,
+		&sql.Column{Name: CommitHashCol, Type: sql.Text, Source: name, PrimaryKey: false, Nullable: false},
+		&sql.Column{Name: CommitterCol, Type: sql.Text, Source: name, PrimaryKey: false, Nullable: false},
+		&sql.Column{Name: "email", Type: sql.Text, Source: name, PrimaryKey: false, Nullable: false},
+		&sql.Column{Name: CommitDateCol, Type: sql.Datetime, Source: name, PrimaryKey: false, Nullable: false},
+		&sql.Column{Name: "message", Type: sql.Text, Source: name, PrimaryKey: false, Nullable: false},
+	)
+
+	return &BlameTable{
+		tblName: tblName,
+		ddb:     ddb,
+		commit:  commit,
+		tbl:     tbl,
+		sch:     sch,
+		sqlSch:  sqlSch,
+	}, nil
+}
+
+// Name is a sql.Table interface function which returns the name of the table
+func (bt *BlameTable) Name() string {
+	return doltdb.DoltBlameTablePrefix + bt.tblName
+}
+
+// String is a sql.Table interface function which returns the name of the table
+func (bt *BlameTable) String() string {
+	return doltdb.DoltBlameTablePrefix + bt.tblName
+}
+
+// Schema is a sql.Table interface function that gets the sql.Schema of the blame system table, which is made up of
+// the primary key columns of the table followed by the columns describing the commit which last modified the row.
+func (bt *BlameTable) Schema() sql.Schema {
+	return bt.sqlSch
+}
+
+// Partitions is a sql.Table interface function that returns a partition of the data.  Currently the data is unpartitioned.
+func (bt *BlameTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return index.SinglePartitionIterFromNomsMap(nil), nil
+}
+
+// PartitionRows is a sql.Table interface function that gets a row iterator for a partition
+func (bt *BlameTable) PartitionRows(ctx *sql.Context, _ sql.Partition) (sql.RowIter, error) {
+	rows, err := bt.tbl.GetNomsRowData(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	blame, err := blameRows(ctx, bt.ddb, bt.commit, bt.tblName, bt.sch, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	itr, err := rows.Iterator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &blameItr{itr: itr, sch: bt.sch, blame: blame}, nil
+}
+
+// blameCommit is the commit a row is blamed on
+type blameCommit struct {
+	hash string
+	meta *doltdb.CommitMeta
+}
+
+func newBlameCommit(cm *doltdb.Commit) (*blameCommit, error) {
+	h, err := cm.HashOf()
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := cm.GetCommitMeta()
+	if err != nil {
+		return nil, err
+	}
+
+	return &blameCommit{hash: h.String(), meta: meta}, nil
+}
+
+// blameRows attributes each row of |rows|, the row data of the table |tblName| at |commit|, to the commit which last
+// modified it. History is walked by following first parents, comparing the row data of each commit with that of its
+// parent, and stops as soon as every row has been attributed. As with dolt blame, every row is considered modified by
+// a commit which changes the schema of the table, and the rows of a commit without parents, such as the shallow
+// commits of a shallow clone, are attributed to it.
+func blameRows(ctx context.Context, ddb *doltdb.DoltDB, commit *doltdb.Commit, tblName string, sch schema.Schema, rows types.Map) (map[hash.Hash]*blameCommit, error) {
+	nbf := rows.Format()
+	unblamed := make(map[hash.Hash]struct{}, rows.Len())
+	err := rows.IterAll(ctx, func(key, _ types.Value) error {
+		h, err := key.Hash(nbf)
+		if err != nil {
+			return err
+		}
+
+		unblamed[h] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	blame := make(map[hash.Hash]*blameCommit, len(unblamed))
+	blameAll := func(cm *doltdb.Commit) error {
+		bc, err := newBlameCommit(cm)
+		if err != nil {
+			return err
+		}
+
+		for h := range unblamed {
+			blame[h] = bc
+		}
+
+		return nil
+	}
+
+	child, childSch, childRows := commit, sch, rows
+	for len(unblamed) > 0 {
+		numParents, err := child.NumParents()
+		if err != nil {
+			return nil, err
+		}
+
+		if numParents == 0 {
+			return blame, blameAll(child)
+		}
+
+		parent, err := ddb.ResolveParent(ctx, child, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		parentRoot, err := parent.GetRootValue()
+		if err != nil {
+			return nil, err
+		}
+
+		parentTbl, ok, err := parentRoot.GetTable(ctx, tblName)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return blame, blameAll(child)
+		}
+
+		parentSch, err := parentTbl.GetSchema(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !schema.SchemasAreEqual(parentSch, childSch) {
+			return blame, blameAll(child)
+		}
+
+		parentRows, err := parentTbl.GetNomsRowData(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if !parentRows.Equals(childRows) {
+			err = blameChangedRows(ctx, child, parentRows, childRows, unblamed, blame)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		child, childSch, childRows = parent, parentSch, parentRows
+	}
+
+	return blame, nil
+}
+
+// blameChangedRows attributes the rows in |unblamed| which differ between |parentRows| and |childRows| to |child|,
+// removing them from |unblamed|.
+func blameChangedRows(ctx context.Context, child *doltdb.Commit, parentRows, childRows types.Map, unblamed map[hash.Hash]struct{}, blame map[hash.Hash]*blameCommit) (err error) {
+	nbf := childRows.Format()
+	ad := diff.NewAsyncDiffer(blameDiffBufferSize)
+	ad.Start(ctx, parentRows, childRows)
+	defer func() {
+		closeErr := ad.Close()
+		if err == nil {
+			err = closeErr
+		}
+	}()
+
+	var bc *blameCommit
+	for len(unblamed) > 0 {
+		diffs, more, err := ad.GetDiffsWithoutTimeout(blameDiffBufferSize)
+		if err != nil {
+			return err
+		}
+
+		for _, d := range diffs {
+			h, err := d.KeyValue.Hash(nbf)
+			if err != nil {
+				return err
+			}
+
+			if _, ok := unblamed[h]; !ok {
+				continue
+			}
+
+			if bc == nil {
+				bc, err = newBlameCommit(child)
+				if err != nil {
+					return err
+				}
+			}
+
+			blame[h] = bc
+			delete(unblamed, h)
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	return nil
+}
+
+// blameItr is a sql.RowIter which iterates over the rows of a table in primary key order, returning the primary key
+// of each row along with the commit it is blamed on.
+type blameItr struct {
+	itr   types.MapIterator
+	sch   schema.Schema
+	blame map[hash.Hash]*blameCommit
+}
+
+// Next retrieves the next row. It will return io.EOF if it's the last row.
+func (itr *blameItr) Next(ctx *sql.Context) (sql.Row, error) {
+	key, _, err := itr.itr.Next(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, io.EOF
+	}
+
+	h, err := key.Hash(key.(types.Tuple).Format())
+	if err != nil {
+		return nil, err
+	}
+
+	bc, ok := itr.blame[h]
+	if !ok {
+		return nil, fmt.Errorf("couldn't find blame for row with key %s", h.String())
+	}
+
+	tvs, err := row.ParseTaggedValues(key.(types.Tuple))
+	if err != nil {
+		return nil, err
+	}
+
+	// primary key columns are returned in the order of the schema, rather than that of the key
+	r := make(sql.Row, 0, itr.sch.GetPKCols().Size()+5)
+	err = itr.sch.GetAllCols().Iter(func(tag uint64, col schema.Column) (stop bool, err error) {
+		if !col.IsPartOfPK {
+			return false, nil
+		}
+
+		var v interface{}
+		if nomsVal, ok := tvs.Get(tag); ok {
+			v, err = col.TypeInfo.ConvertNomsValueToValue(nomsVal)
+		}
+
+		r = append(r, v)
+		return err != nil, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	meta := bc.meta
+	return append(r, bc.hash, meta.Name, meta.Email, meta.Time(), meta.Description), nil
+}
+
+// Close closes the iterator.
+func (itr *blameItr) Close(*sql.Context) error {
+	return nil
+}
@@ -0,0 +1,208 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"errors"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/privileges"
+)
+
+// ErrBranchControlUnavailable is returned when branch rules are changed without a privilege file to persist them to.
+var ErrBranchControlUnavailable = errors.New("branch rules can only be changed on a sql-server with a privilege file")
+
+var _ sql.Table = (*BranchControlTable)(nil)
+var _ sql.UpdatableTable = (*BranchControlTable)(nil)
+var _ sql.DeletableTable = (*BranchControlTable)(nil)
+var _ sql.InsertableTable = (*BranchControlTable)(nil)
+var _ sql.ReplaceableTable = (*BranchControlTable)(nil)
+
+// BranchControlTable is a sql.Table implementation that implements a system table which shows and edits the rules for
+// which branches accounts may write to. The rules are the same for every database, and name the databases they apply
+// to.
+type BranchControlTable struct {
+	store *privileges.Store
+}
+
+// NewBranchControlTable creates a BranchControlTable. |store| may be nil, in which case the table has no rows and
+// cannot be written to.
+func NewBranchControlTable(_ *sql.Context, store *privileges.Store) sql.Table {
+	return &BranchControlTable{store}
+}
+
+// Name is a sql.Table interface function which returns the name of the table which is defined by the constant
+// BranchControlTableName
+func (bt *BranchControlTable) Name() string {
+	return doltdb.BranchControlTableName
+}
+
+// String is a sql.Table interface function which returns the name of the table which is defined by the constant
+// BranchControlTableName
+func (bt *BranchControlTable) String() string {
+	return doltdb.BranchControlTableName
+}
+
+// Schema is a sql.Table interface function that gets the sql.Schema of the branch control system table
+func (bt *BranchControlTable) Schema() sql.Schema {
+	return []*sql.Column{
+		{Name: "database", Type: sql.Text, Source: doltdb.BranchControlTableName, PrimaryKey: true, Nullable: false},
+		{Name: "branch", Type: sql.Text, Source: doltdb.BranchControlTableName, PrimaryKey: true, Nullable: false},
+		{Name: "user", Type: sql.Text, Source: doltdb.BranchControlTableName, PrimaryKey: true, Nullable: false},
+		{Name: "host", Type: sql.Text, Source: doltdb.BranchControlTableName, PrimaryKey: true, Nullable: false},
+	}
+}
+
+// Partitions is a sql.Table interface function that returns a partition of the data.  Currently the data is unpartitioned.
+func (bt *BranchControlTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return index.SinglePartitionIterFromNomsMap(nil), nil
+}
+
+// PartitionRows is a sql.Table interface function that gets a row iterator for a partition
+func (bt *BranchControlTable) PartitionRows(*sql.Context, sql.Partition) (sql.RowIter, error) {
+	if bt.store == nil {
+		return sql.RowsToRowIter(), nil
+	}
+
+	rules := bt.store.BranchRules()
+	rows := make([]sql.Row, len(rules))
+	for i, rule := range rules {
+		rows[i] = sql.NewRow(rule.Database, rule.Branch, rule.User, rule.Host)
+	}
+
+	return sql.RowsToRowIter(rows...), nil
+}
+
+// Replacer returns a RowReplacer for this table. The RowReplacer will have Insert and optionally Delete called once
+// for each row, followed by a call to Close() when all rows have been processed.
+func (bt *BranchControlTable) Replacer(*sql.Context) sql.RowReplacer {
+	return branchControlWriter{bt}
+}
+
+// Updater returns a RowUpdater for this table. The RowUpdater will have Update called once for each row to be
+// updated, followed by a call to Close() when all rows have been processed.
+func (bt *BranchControlTable) Updater(*sql.Context) sql.RowUpdater {
+	return branchControlWriter{bt}
+}
+
+// Inserter returns an Inserter for this table. The Inserter will get one call to Insert() for each row to be
+// inserted, and will end with a call to Close() to finalize the insert operation.
+func (bt *BranchControlTable) Inserter(*sql.Context) sql.RowInserter {
+	return branchControlWriter{bt}
+}
+
+// Deleter returns a RowDeleter for this table. The RowDeleter will get one call to Delete for each row to be deleted,
+// and will end with a call to Close() to finalize the delete operation.
+func (bt *BranchControlTable) Deleter(*sql.Context) sql.RowDeleter {
+	return branchControlWriter{bt}
+}
+
+var _ sql.RowReplacer = branchControlWriter{nil}
+var _ sql.RowUpdater = branchControlWriter{nil}
+var _ sql.RowInserter = branchControlWriter{nil}
+var _ sql.RowDeleter = branchControlWriter{nil}
+
+type branchControlWriter struct {
+	bt *BranchControlTable
+}
+
+func branchRuleFromRow(r sql.Row) (privileges.BranchRule, error) {
+	vals := make([]string, 4)
+	for i := range vals {
+		if r[i] == nil {
+			continue
+		}
+
+		str, ok := r[i].(string)
+		if !ok {
+			return privileges.BranchRule{}, errors.New("invalid value type for branch rule")
+		}
+		vals[i] = str
+	}
+
+	if len(vals[0]) == 0 || len(vals[1]) == 0 || len(vals[2]) == 0 {
+		return privileges.BranchRule{}, errors.New("the database, branch and user of a branch rule cannot be empty")
+	}
+
+	return privileges.BranchRule{Database: vals[0], Branch: vals[1], User: vals[2], Host: vals[3]}, nil
+}
+
+// Insert inserts the row given, returning an error if it cannot. Insert will be called once for each row to process
+// for the insert operation, which may involve many rows. After all rows in an operation have been processed, Close
+// is called.
+func (bWr branchControlWriter) Insert(ctx *sql.Context, r sql.Row) error {
+	if bWr.bt.store == nil {
+		return ErrBranchControlUnavailable
+	}
+
+	rule, err := branchRuleFromRow(r)
+	if err != nil {
+		return err
+	}
+
+	return bWr.bt.store.AddBranchRule(ctx, rule)
+}
+
+// Update the given row. Provides both the old and new rows.
+func (bWr branchControlWriter) Update(ctx *sql.Context, old sql.Row, new sql.Row) error {
+	err := bWr.Delete(ctx, old)
+	if err != nil {
+		return err
+	}
+
+	return bWr.Insert(ctx, new)
+}
+
+// Delete deletes the given row. Returns ErrDeleteRowNotFound if the row was not found. Delete will be called once for
+// each row to process for the delete operation, which may involve many rows. After all rows have been processed,
+// Close is called.
+func (bWr branchControlWriter) Delete(ctx *sql.Context, r sql.Row) error {
+	if bWr.bt.store == nil {
+		return ErrBranchControlUnavailable
+	}
+
+	rule, err := branchRuleFromRow(r)
+	if err != nil {
+		return err
+	}
+
+	err = bWr.bt.store.RemoveBranchRule(ctx, rule)
+	if privileges.ErrBranchRuleNotFound.Is(err) {
+		return sql.ErrDeleteRowNotFound.New()
+	}
+
+	return err
+}
+
+// StatementBegin implements the interface sql.TableEditor. Currently a no-op.
+func (bWr branchControlWriter) StatementBegin(ctx *sql.Context) {}
+
+// DiscardChanges implements the interface sql.TableEditor. Currently a no-op.
+func (bWr branchControlWriter) DiscardChanges(ctx *sql.Context, errorEncountered error) error {
+	return nil
+}
+
+// StatementComplete implements the interface sql.TableEditor. Currently a no-op.
+func (bWr branchControlWriter) StatementComplete(ctx *sql.Context) error {
+	return nil
+}
+
+// Close finalizes the write operation. Rules are persisted as they are written, so this is a no-op.
+func (bWr branchControlWriter) Close(*sql.Context) error {
+	return nil
+}
@@ -22,6 +22,7 @@ import (
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
 	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
 )
 
@@ -33,12 +34,13 @@ var _ sql.ReplaceableTable = (*BranchesTable)(nil)
 
 // BranchesTable is a sql.Table implementation that implements a system table which shows the dolt branches
 type BranchesTable struct {
-	ddb *doltdb.DoltDB
+	dbName string
+	ddb    *doltdb.DoltDB
 }
 
 // NewBranchesTable creates a BranchesTable
-func NewBranchesTable(_ *sql.Context, ddb *doltdb.DoltDB) sql.Table {
-	return &BranchesTable{ddb}
+func NewBranchesTable(_ *sql.Context, dbName string, ddb *doltdb.DoltDB) sql.Table {
+	return &BranchesTable{dbName: dbName, ddb: ddb}
 }
 
 // Name is a sql.Table interface function which returns the name of the table which is defined by the constant
@@ -90,6 +92,11 @@ func NewBranchItr(sqlCtx *sql.Context, ddb *doltdb.DoltDB) (*BranchItr, error) {
 		return nil, err
 	}
 
+	return newBranchItrForRefs(sqlCtx, ddb, branches)
+}
+
+// newBranchItrForRefs creates a BranchItr over the commits at the head of each of |branches|.
+func newBranchItrForRefs(sqlCtx *sql.Context, ddb *doltdb.DoltDB, branches []ref.DoltRef) (*BranchItr, error) {
 	branchNames := make([]string, len(branches))
 	commits := make([]*doltdb.Commit, len(branches))
 	for i, branch := range branches {
@@ -206,6 +213,11 @@ func (bWr branchWriter) Insert(ctx *sql.Context, r sql.Row) error {
 		return err
 	}
 
+	err = dsess.DSessFromSess(ctx.Session).CheckBranchWrite(ctx, bWr.bt.dbName, branchName)
+	if err != nil {
+		return err
+	}
+
 	ddb := bWr.bt.ddb
 	cm, err := ddb.Resolve(ctx, cs, nil)
 
@@ -240,6 +252,11 @@ func (bWr branchWriter) Delete(ctx *sql.Context, r sql.Row) error {
 		return err
 	}
 
+	err = dsess.DSessFromSess(ctx.Session).CheckBranchWrite(ctx, bWr.bt.dbName, branchName)
+	if err != nil {
+		return err
+	}
+
 	brRef := ref.NewBranchRef(branchName)
 	exists, err := bWr.bt.ddb.HasRef(ctx, brRef)
 
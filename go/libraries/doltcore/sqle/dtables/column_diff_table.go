@@ -0,0 +1,317 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"sort"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/diff"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
+	"github.com/dolthub/dolt/go/libraries/utils/set"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+const (
+	// TableNameCol is the name of the column containing the name of a table in the result set
+	TableNameCol = "table_name"
+
+	// ColumnNameCol is the name of the column containing the name of a column in the result set
+	ColumnNameCol = "column_name"
+)
+
+var _ sql.Table = (*ColumnDiffTable)(nil)
+var _ sql.FilteredTable = (*ColumnDiffTable)(nil)
+
+// ColumnDiffTable is a sql.Table implementation of a system table which shows the columns of each table changed by
+// every commit reachable from the head, compared with the commit's first parent. A column is added or removed when
+// it is added to or dropped from the schema of its table, which includes when the table is created or dropped, and is
+// modified when its definition or its value in any row changes. Filters on the commit columns and on table_name are
+// handled by the table, so that only matching commits and tables are diffed.
+type ColumnDiffTable struct {
+	ddb           *doltdb.DoltDB
+	head          *doltdb.Commit
+	commitFilters []sql.Expression
+	tableFilters  []sql.Expression
+	cmItr         doltdb.CommitItr
+	tableCheck    func(*sql.Context, string) (bool, error)
+}
+
+// NewColumnDiffTable creates a ColumnDiffTable
+func NewColumnDiffTable(_ *sql.Context, ddb *doltdb.DoltDB, head *doltdb.Commit) sql.Table {
+	return &ColumnDiffTable{ddb: ddb, head: head, cmItr: doltdb.CommitItrForRoots(ddb, head)}
+}
+
+// Name is a sql.Table interface function which returns the name of the table which is defined by the constant
+// ColumnDiffTableName
+func (cdt *ColumnDiffTable) Name() string {
+	return doltdb.ColumnDiffTableName
+}
+
+// String is a sql.Table interface function which returns the name of the table which is defined by the constant
+// ColumnDiffTableName
+func (cdt *ColumnDiffTable) String() string {
+	return doltdb.ColumnDiffTableName
+}
+
+// Schema is a sql.Table interface function that gets the sql.Schema of the column diff system table.
+func (cdt *ColumnDiffTable) Schema() sql.Schema {
+	return []*sql.Column{
+		{Name: CommitHashCol, Type: sql.Text, Source: doltdb.ColumnDiffTableName, PrimaryKey: true},
+		{Name: TableNameCol, Type: sql.Text, Source: doltdb.ColumnDiffTableName, PrimaryKey: true},
+		{Name: ColumnNameCol, Type: sql.Text, Source: doltdb.ColumnDiffTableName, PrimaryKey: true},
+		{Name: CommitterCol, Type: sql.Text, Source: doltdb.ColumnDiffTableName, PrimaryKey: false},
+		{Name: "email", Type: sql.Text, Source: doltdb.ColumnDiffTableName, PrimaryKey: false},
+		{Name: CommitDateCol, Type: sql.Datetime, Source: doltdb.ColumnDiffTableName, PrimaryKey: false},
+		{Name: "message", Type: sql.Text, Source: doltdb.ColumnDiffTableName, PrimaryKey: false},
+		{Name: diffTypeColName, Type: sql.Text, Source: doltdb.ColumnDiffTableName, PrimaryKey: false},
+	}
+}
+
+var tableFilterCols = set.NewStrSet([]string{TableNameCol})
+
+// HandledFilters returns the list of filters that will be handled by the table itself
+func (cdt *ColumnDiffTable) HandledFilters(filters []sql.Expression) []sql.Expression {
+	cdt.splitFilters(filters)
+	return append(append([]sql.Expression{}, cdt.commitFilters...), cdt.tableFilters...)
+}
+
+func (cdt *ColumnDiffTable) splitFilters(filters []sql.Expression) {
+	var rest []sql.Expression
+	cdt.commitFilters, rest = splitCommitFilters(filters)
+	cdt.tableFilters, _ = splitFilters(rest, getColumnFilterCheck(tableFilterCols))
+}
+
+// Filters returns the list of filters that are applied to this table.
+func (cdt *ColumnDiffTable) Filters() []sql.Expression {
+	return append(append([]sql.Expression{}, cdt.commitFilters...), cdt.tableFilters...)
+}
+
+// WithFilters returns a new sql.Table instance with the filters applied
+func (cdt *ColumnDiffTable) WithFilters(ctx *sql.Context, filters []sql.Expression) sql.Table {
+	if cdt.commitFilters == nil && cdt.tableFilters == nil {
+		cdt.splitFilters(filters)
+	}
+
+	if len(cdt.commitFilters) > 0 {
+		commitCheck, err := getCommitFilterFunc(ctx, cdt.commitFilters)
+
+		if err != nil {
+			return sqlutil.NewStaticErrorTable(cdt, err)
+		}
+
+		cdt.cmItr = doltdb.NewFilteringCommitItr(cdt.cmItr, commitCheck)
+	}
+
+	if len(cdt.tableFilters) > 0 {
+		cdt.tableCheck = getTableNameFilterFunc(cdt.tableFilters)
+	}
+
+	return cdt
+}
+
+// getTableNameFilterFunc returns a function which checks whether a table name matches all of the filters given, each
+// of which must only reference the table_name column.
+func getTableNameFilterFunc(filters []sql.Expression) func(*sql.Context, string) (bool, error) {
+	transformed := make([]sql.Expression, len(filters))
+	for i := range filters {
+		transformed[i], _ = expression.TransformUp(filters[i], func(e sql.Expression) (sql.Expression, error) {
+			if gf, ok := e.(*expression.GetField); ok {
+				return gf.WithIndex(0), nil
+			}
+			return e, nil
+		})
+	}
+
+	return func(ctx *sql.Context, tblName string) (bool, error) {
+		r := sql.Row{tblName}
+		for _, filter := range transformed {
+			res, err := filter.Eval(ctx, r)
+			if err != nil {
+				return false, err
+			}
+
+			if b, ok := res.(bool); !ok || !b {
+				return false, nil
+			}
+		}
+
+		return true, nil
+	}
+}
+
+// Partitions returns a PartitionIter which will be used in getting partitions each of which is used to create RowIter.
+func (cdt *ColumnDiffTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return &commitPartitioner{cdt.cmItr}, nil
+}
+
+// PartitionRows takes a partition and returns a row iterator for that partition
+func (cdt *ColumnDiffTable) PartitionRows(ctx *sql.Context, part sql.Partition) (sql.RowIter, error) {
+	cp := part.(*commitPartition)
+
+	rows, err := columnDiffRowsForCommit(ctx, cdt.ddb, cp.h, cp.cm, cdt.tableCheck)
+	if err != nil {
+		return nil, err
+	}
+
+	return sql.RowsToRowIter(rows...), nil
+}
+
+// columnDiffRowsForCommit returns a row for each column changed by |cm| in each table which passes |tableCheck|, if it
+// is not nil.
+func columnDiffRowsForCommit(ctx *sql.Context, ddb *doltdb.DoltDB, h hash.Hash, cm *doltdb.Commit, tableCheck func(*sql.Context, string) (bool, error)) ([]sql.Row, error) {
+	toRoot, err := cm.GetRootValue()
+	if err != nil {
+		return nil, err
+	}
+
+	var fromRoot *doltdb.RootValue
+	numParents, err := cm.NumParents()
+	if err != nil {
+		return nil, err
+	}
+
+	if numParents == 0 {
+		fromRoot, err = doltdb.EmptyRootValue(ctx, ddb.ValueReadWriter())
+	} else {
+		var parent *doltdb.Commit
+		parent, err = ddb.ResolveParent(ctx, cm, 0)
+		if err != nil {
+			return nil, err
+		}
+		fromRoot, err = parent.GetRootValue()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	deltas, err := diff.GetTableDeltas(ctx, fromRoot, toRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(deltas, func(i, j int) bool {
+		return deltas[i].CurName() < deltas[j].CurName()
+	})
+
+	meta, err := cm.GetCommitMeta()
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []sql.Row
+	for _, td := range deltas {
+		tblName := td.CurName()
+		if tableCheck != nil {
+			ok, err := tableCheck(ctx, tblName)
+			if err != nil {
+				return nil, err
+			}
+
+			if !ok {
+				continue
+			}
+		}
+
+		changes, err := columnChangesForTableDelta(ctx, td)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, change := range changes {
+			rows = append(rows, sql.NewRow(h.String(), tblName, change.name, meta.Name, meta.Email, meta.Time(), meta.Description, change.diffType))
+		}
+	}
+
+	return rows, nil
+}
+
+// columnChange is the change to a single column of a table
+type columnChange struct {
+	name     string
+	diffType string
+}
+
+// columnChangesForTableDelta returns the changes to the columns of the table of |td|, in the order of the columns of
+// the table's new schema, followed by any which were removed.
+func columnChangesForTableDelta(ctx *sql.Context, td diff.TableDelta) ([]columnChange, error) {
+	if td.IsAdd() {
+		return columnChangesOfType(td.ToSch, diffTypeAdded), nil
+	} else if td.IsDrop() {
+		return columnChangesOfType(td.FromSch, diffTypeRemoved), nil
+	}
+
+	changed, err := td.HasHashChanged()
+	if err != nil || !changed {
+		return nil, err
+	}
+
+	// when rows can't be compared across the change to the schema, every column common to both schemas is modified
+	dataChanged := func(uint64) bool { return true }
+	if schema.ArePrimaryKeySetsDiffable(td.FromSch, td.ToSch) {
+		fromRows, toRows, err := td.GetMaps(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		changedTags, err := diff.ColumnsChanged(ctx, td.ToSch, fromRows, toRows)
+		if err != nil {
+			return nil, err
+		}
+
+		dataChanged = changedTags.Contains
+	}
+
+	var changes []columnChange
+	err = td.ToSch.GetAllCols().Iter(func(tag uint64, col schema.Column) (stop bool, err error) {
+		fromCol, ok := td.FromSch.GetAllCols().GetByTag(tag)
+		if !ok {
+			changes = append(changes, columnChange{col.Name, diffTypeAdded})
+		} else if !fromCol.Equals(col) || dataChanged(tag) {
+			changes = append(changes, columnChange{col.Name, diffTypeModified})
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = td.FromSch.GetAllCols().Iter(func(tag uint64, col schema.Column) (stop bool, err error) {
+		if _, ok := td.ToSch.GetAllCols().GetByTag(tag); !ok {
+			changes = append(changes, columnChange{col.Name, diffTypeRemoved})
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+func columnChangesOfType(sch schema.Schema, diffType string) []columnChange {
+	var changes []columnChange
+	_ = sch.GetAllCols().Iter(func(tag uint64, col schema.Column) (stop bool, err error) {
+		changes = append(changes, columnChange{col.Name, diffType})
+		return false, nil
+	})
+
+	return changes
+}
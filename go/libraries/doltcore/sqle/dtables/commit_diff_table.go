@@ -42,16 +42,22 @@ var _ sql.FilteredTable = (*CommitDiffTable)(nil)
 type CommitDiffTable struct {
 	name              string
 	ddb               *doltdb.DoltDB
-	ss                *schema.SuperSchema
+	proj              *schemaProjection
 	joiner            *rowconv.Joiner
 	sqlSch            sql.PrimaryKeySchema
 	workingRoot       *doltdb.RootValue
+	stagedRoot        *doltdb.RootValue
+	head              *doltdb.Commit
 	fromCommitFilter  *expression.Equals
 	toCommitFilter    *expression.Equals
+	rowFilters        []sql.Expression
 	requiredFilterErr error
 }
 
-func NewCommitDiffTable(ctx *sql.Context, tblName string, ddb *doltdb.DoltDB, root *doltdb.RootValue) (sql.Table, error) {
+// NewCommitDiffTable returns the dolt_commit_diff_ table for the table named. The from_commit and to_commit it is
+// filtered on may be any commit spec, such as a branch, tag or commit hash, or HEAD~n relative to |head|, as well as
+// WORKING or STAGED for the |root| and |staged| root values given.
+func NewCommitDiffTable(ctx *sql.Context, tblName string, ddb *doltdb.DoltDB, root, staged *doltdb.RootValue, head *doltdb.Commit) (sql.Table, error) {
 	tblName, ok, err := root.ResolveTableName(ctx, tblName)
 	if err != nil {
 		return nil, err
@@ -69,17 +75,17 @@ func NewCommitDiffTable(ctx *sql.Context, tblName string, ddb *doltdb.DoltDB, ro
 	_ = ss.AddColumn(schema.NewColumn("commit", schema.DiffCommitTag, types.StringKind, false))
 	_ = ss.AddColumn(schema.NewColumn("commit_date", schema.DiffCommitDateTag, types.TimestampKind, false))
 
-	sch, err := ss.GenerateSchema()
+	proj, err := newSuperSchemaProjection(ss)
 	if err != nil {
 		return nil, err
 	}
 
-	if sch.GetAllCols().Size() <= 1 {
+	if proj.sch.GetAllCols().Size() <= 1 {
 		return nil, sql.ErrTableNotFound.New(diffTblName)
 	}
 
 	j, err := rowconv.NewJoiner(
-		[]rowconv.NamedSchema{{Name: diff.To, Sch: sch}, {Name: diff.From, Sch: sch}},
+		[]rowconv.NamedSchema{{Name: diff.To, Sch: proj.sch}, {Name: diff.From, Sch: proj.sch}},
 		map[string]rowconv.ColNamingFunc{
 			diff.To:   toNamer,
 			diff.From: fromNamer,
@@ -104,7 +110,9 @@ func NewCommitDiffTable(ctx *sql.Context, tblName string, ddb *doltdb.DoltDB, ro
 		name:        tblName,
 		ddb:         ddb,
 		workingRoot: root,
-		ss:          ss,
+		stagedRoot:  staged,
+		head:        head,
+		proj:        proj,
 		joiner:      j,
 		sqlSch:      sqlSch,
 	}, nil
@@ -237,6 +245,10 @@ func (dt *CommitDiffTable) Partitions(ctx *sql.Context) (sql.PartitionIter, erro
 
 	fromTable, _, err := fromRoot.GetTable(ctx, dt.name)
 
+	if err != nil {
+		return nil, err
+	}
+
 	dp := diffPartition{
 		to:       toTable,
 		from:     fromTable,
@@ -278,45 +290,75 @@ func (dt *CommitDiffTable) rootValForFilter(ctx *sql.Context, eqFilter *expressi
 		return nil, "", nil, fmt.Errorf("received '%v' when expecting commit hash string", val)
 	}
 
-	var root *doltdb.RootValue
-	var commitTime *types.Timestamp
-	if strings.ToLower(hashStr) == "working" {
-		root = dt.workingRoot
-	} else {
-		cs, err := doltdb.NewCommitSpec(hashStr)
+	root, cm, err := RootForRevision(ctx, dt.ddb, hashStr, dt.workingRoot, dt.stagedRoot, dt.head)
 
-		if err != nil {
-			return nil, "", nil, err
-		}
+	if err != nil {
+		return nil, "", nil, err
+	}
 
-		cm, err := dt.ddb.Resolve(ctx, cs, nil)
+	var commitTime *types.Timestamp
+	if cm != nil {
+		meta, err := cm.GetCommitMeta()
 
 		if err != nil {
 			return nil, "", nil, err
 		}
 
-		root, err = cm.GetRootValue()
+		t := meta.Time()
+		commitTime = (*types.Timestamp)(&t)
+	}
 
-		if err != nil {
-			return nil, "", nil, err
-		}
+	return root, hashStr, commitTime, nil
+}
 
-		meta, err := cm.GetCommitMeta()
+// RootForRevision returns the root value of the revision given, which is either WORKING or STAGED for the |working|
+// and |staged| root values given, or a commit spec such as a branch, tag or commit hash. Commit specs relative to HEAD
+// are resolved relative to |head|, the head commit of the session. The commit that the revision resolves to is also
+// returned, or nil for WORKING and STAGED.
+func RootForRevision(ctx *sql.Context, ddb *doltdb.DoltDB, revision string, working, staged *doltdb.RootValue, head *doltdb.Commit) (*doltdb.RootValue, *doltdb.Commit, error) {
+	switch strings.ToLower(revision) {
+	case "working":
+		return working, nil, nil
+	case "staged":
+		return staged, nil, nil
+	}
+
+	name, as, err := doltdb.SplitAncestorSpec(revision)
+	if err != nil {
+		return nil, nil, err
+	}
 
+	var cm *doltdb.Commit
+	if strings.ToUpper(name) == "HEAD" {
+		if head == nil {
+			return nil, nil, fmt.Errorf("cannot resolve '%s' without a head commit", revision)
+		}
+		cm, err = head.GetAncestor(ctx, as)
+	} else {
+		var cs *doltdb.CommitSpec
+		cs, err = doltdb.NewCommitSpec(revision)
 		if err != nil {
-			return nil, "", nil, err
+			return nil, nil, err
 		}
+		cm, err = ddb.Resolve(ctx, cs, nil)
+	}
 
-		t := meta.Time()
-		commitTime = (*types.Timestamp)(&t)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return root, hashStr, commitTime, nil
+	root, err := cm.GetRootValue()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return root, cm, nil
 }
 
 // HandledFilters returns the list of filters that will be handled by the table itself
 func (dt *CommitDiffTable) HandledFilters(filters []sql.Expression) []sql.Expression {
 	var commitFilters []sql.Expression
+	dt.rowFilters = nil
 	for _, filter := range filters {
 		isCommitFilter := false
 
@@ -345,6 +387,10 @@ func (dt *CommitDiffTable) HandledFilters(filters []sql.Expression) []sql.Expres
 
 		if isCommitFilter {
 			commitFilters = append(commitFilters, filter)
+		} else {
+			// other filters may limit the range of keys that need to be diffed, but still need to be applied to the
+			// rows of the diff
+			dt.rowFilters = append(dt.rowFilters, filter)
 		}
 	}
 
@@ -367,5 +413,10 @@ func (dt *CommitDiffTable) WithFilters(ctx *sql.Context, filters []sql.Expressio
 
 func (dt *CommitDiffTable) PartitionRows(ctx *sql.Context, part sql.Partition) (sql.RowIter, error) {
 	dp := part.(diffPartition)
-	return dp.getRowIter(ctx, dt.ddb, dt.ss, dt.joiner)
+	itr, err := dp.getRowIter(ctx, dt.ddb, dt.proj, dt.joiner, dt.rowFilters)
+	if err != nil {
+		return nil, err
+	}
+
+	return itr, nil
 }
@@ -16,6 +16,7 @@ package dtables
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/dolthub/go-mysql-server/sql"
 
@@ -23,10 +24,13 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/merge"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor"
 	"github.com/dolthub/dolt/go/store/types"
 )
 
 var _ sql.Table = ConflictsTable{}
+var _ sql.DeletableTable = ConflictsTable{}
+var _ sql.UpdatableTable = ConflictsTable{}
 
 // ConflictsTable is a sql.Table implementation that provides access to the conflicts that exist for a user table
 type ConflictsTable struct {
@@ -36,6 +40,7 @@ type ConflictsTable struct {
 	tbl     *doltdb.Table
 	rd      *merge.ConflictReader
 	rs      RootSetter
+	opts    editor.Options
 }
 
 type RootSetter interface {
@@ -43,7 +48,7 @@ type RootSetter interface {
 }
 
 // NewConflictsTable returns a new ConflictsTableTable instance
-func NewConflictsTable(ctx *sql.Context, tblName string, root *doltdb.RootValue, rs RootSetter) (sql.Table, error) {
+func NewConflictsTable(ctx *sql.Context, tblName string, root *doltdb.RootValue, rs RootSetter, opts editor.Options) (sql.Table, error) {
 	tbl, tblName, ok, err := root.GetTableInsensitive(ctx, tblName)
 	if err != nil {
 		return nil, err
@@ -68,6 +73,7 @@ func NewConflictsTable(ctx *sql.Context, tblName string, root *doltdb.RootValue,
 		tbl:     tbl,
 		rd:      rd,
 		rs:      rs,
+		opts:    opts,
 	}, nil
 }
 
@@ -102,6 +108,13 @@ func (ct ConflictsTable) Deleter(*sql.Context) sql.RowDeleter {
 	return &conflictDeleter{ct: ct, rs: ct.rs}
 }
 
+// Updater returns a RowUpdater for this table. Only the our_ columns of the table can be updated. Updating a row
+// resolves its conflict by writing the new value of our side of the conflict to the table, and deletes the row from
+// the table if all of its our_ columns are set to NULL.
+func (ct ConflictsTable) Updater(*sql.Context) sql.RowUpdater {
+	return &conflictUpdater{ct: ct, rs: ct.rs}
+}
+
 type conflictRowIter struct {
 	rd *merge.ConflictReader
 }
@@ -186,3 +199,97 @@ func (cd *conflictDeleter) Close(ctx *sql.Context) error {
 
 	return cd.rs.SetRoot(ctx, updatedRoot)
 }
+
+var _ sql.RowUpdater = &conflictUpdater{}
+
+type conflictUpdater struct {
+	ct       ConflictsTable
+	rs       RootSetter
+	resolved []merge.ResolvedRow
+}
+
+// Update resolves the conflict of |old| to the values of the our_ columns of |new|.
+func (cu *conflictUpdater) Update(ctx *sql.Context, old sql.Row, new sql.Row) error {
+	for i, col := range cu.ct.sqlSch.Schema {
+		if merge.IsOursColumn(col.Name) {
+			continue
+		}
+
+		cmp, err := col.Type.Compare(old[i], new[i])
+		if err != nil {
+			return err
+		}
+		if cmp != 0 {
+			return fmt.Errorf("column %s of %s cannot be updated, only the values of our side of a conflict can be changed", col.Name, cu.ct.Name())
+		}
+	}
+
+	cnfSch := cu.ct.rd.GetSchema()
+	vrw := cu.ct.tbl.ValueReadWriter()
+	oldRow, err := sqlutil.SqlRowToDoltRow(ctx, vrw, old, cnfSch)
+	if err != nil {
+		return err
+	}
+
+	newRow, err := sqlutil.SqlRowToDoltRow(ctx, vrw, new, cnfSch)
+	if err != nil {
+		return err
+	}
+
+	key, err := cu.ct.rd.GetKeyForConflict(ctx, oldRow)
+	if err != nil {
+		return err
+	}
+
+	tblSch, err := cu.ct.tbl.GetSchema(ctx)
+	if err != nil {
+		return err
+	}
+
+	newKey, val, err := cu.ct.rd.GetResolvedRowForConflict(ctx, newRow, tblSch)
+	if err != nil {
+		return err
+	}
+
+	if newKey != nil && !newKey.Equals(key) {
+		return fmt.Errorf("the primary key of a row in conflict cannot be changed when updating %s", cu.ct.Name())
+	}
+
+	cu.resolved = append(cu.resolved, merge.ResolvedRow{Key: key, Value: val})
+	return nil
+}
+
+// StatementBegin implements the interface sql.TableEditor. Currently a no-op.
+func (cu *conflictUpdater) StatementBegin(ctx *sql.Context) {}
+
+// DiscardChanges implements the interface sql.TableEditor. Currently a no-op.
+func (cu *conflictUpdater) DiscardChanges(ctx *sql.Context, errorEncountered error) error {
+	return nil
+}
+
+// StatementComplete implements the interface sql.TableEditor. Currently a no-op.
+func (cu *conflictUpdater) StatementComplete(ctx *sql.Context) error {
+	return nil
+}
+
+// Close finalizes the update operation, writing the resolved rows to the table and removing their conflicts in a
+// single update of the working root.
+func (cu *conflictUpdater) Close(ctx *sql.Context) error {
+	if len(cu.resolved) == 0 {
+		return nil
+	}
+
+	updatedTbl, err := merge.ResolvePkConflicts(ctx, cu.ct.tbl, cu.ct.tblName, cu.ct.opts, cu.resolved)
+
+	if err != nil {
+		return err
+	}
+
+	updatedRoot, err := cu.ct.root.PutTable(ctx, cu.ct.tblName, updatedTbl)
+
+	if err != nil {
+		return err
+	}
+
+	return cu.rs.SetRoot(ctx, updatedRoot)
+}
@@ -0,0 +1,189 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"context"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/setalgebra"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/typed/noms"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+// diffKeyRange is the range of keys that a diff of table data is limited to. Diff tables filtered on the primary key
+// of the table being diffed only need to diff the portion of the table's row data that can match the filters, rather
+// than the entire table.
+type diffKeyRange struct {
+	// start is the key to start diffing at, or nil to start at the beginning of the table
+	start types.Value
+	// check returns whether a key is before the end of the range
+	check noms.InRangeCheck
+}
+
+// keyRangeForFilters returns the range of keys of a table with the schema |sch| that rows of a diff of the table
+// matching all of the filters given can have, or nil if the filters don't limit the keys. Only filters on the column
+// the first primary key column is projected onto by |proj| are considered, on either its to_ or its from_ column. Both
+// limit the keys that can match, as for every row in a diff, to_ and from_ primary key columns are either equal or
+// NULL. The filters are not fully handled by the range, and must still be applied to the rows of the diff.
+func keyRangeForFilters(nbf *types.NomsBinFormat, proj *schemaProjection, sch schema.Schema, filters []sql.Expression) (*diffKeyRange, error) {
+	if len(filters) == 0 {
+		return nil, nil
+	}
+
+	projCol, ok, err := proj.projectedKeyColumn(sch)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	pkCol := sch.GetPKCols().GetByIndex(0)
+	toCol, fromCol := pkCol, pkCol
+	toCol.Name, fromCol.Name = toNamer(projCol.Name), fromNamer(projCol.Name)
+
+	var keySet setalgebra.Set = setalgebra.UniversalSet{}
+	for _, filter := range filters {
+		for _, col := range []schema.Column{toCol, fromCol} {
+			setForFilter, err := getSetForKeyColumn(nbf, col, filter)
+			if err != nil {
+				// a filter that can't be converted to a set of keys doesn't limit the range
+				continue
+			}
+
+			keySet, err = keySet.Intersect(setForFilter)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	in, ok, err := boundingInterval(nbf, keySet)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	return keyRangeForInterval(nbf, types.Uint(pkCol.Tag), in)
+}
+
+// boundingInterval returns the smallest interval containing all the values of the set given, and false if the set is
+// not bounded by any interval smaller than the universal set.
+func boundingInterval(nbf *types.NomsBinFormat, set setalgebra.Set) (*setalgebra.Interval, bool, error) {
+	switch typedSet := set.(type) {
+	case setalgebra.EmptySet:
+		return nil, true, nil
+	case setalgebra.Interval:
+		return &typedSet, true, nil
+	case setalgebra.FiniteSet:
+		return boundingIntervalOfPoints(nbf, typedSet, nil)
+	case setalgebra.CompositeSet:
+		return boundingIntervalOfPoints(nbf, typedSet.Set, typedSet.Intervals)
+	}
+
+	return nil, false, nil
+}
+
+func boundingIntervalOfPoints(nbf *types.NomsBinFormat, fs setalgebra.FiniteSet, intervals []setalgebra.Interval) (*setalgebra.Interval, bool, error) {
+	var start, end *setalgebra.IntervalEndpoint
+	for _, v := range fs.HashToVal {
+		if start == nil {
+			start, end = &setalgebra.IntervalEndpoint{Val: v, Inclusive: true}, &setalgebra.IntervalEndpoint{Val: v, Inclusive: true}
+			continue
+		}
+
+		if less, err := v.Less(nbf, start.Val); err != nil {
+			return nil, false, err
+		} else if less {
+			start = &setalgebra.IntervalEndpoint{Val: v, Inclusive: true}
+		}
+
+		if less, err := end.Val.Less(nbf, v); err != nil {
+			return nil, false, err
+		} else if less {
+			end = &setalgebra.IntervalEndpoint{Val: v, Inclusive: true}
+		}
+	}
+
+	// intervals are sorted and don't overlap, and none of the points in the finite set lie within them
+	if len(intervals) > 0 {
+		first, last := intervals[0], intervals[len(intervals)-1]
+		if first.Start == nil || last.End == nil {
+			return nil, false, nil
+		}
+
+		if start == nil {
+			start, end = first.Start, last.End
+		} else {
+			if less, err := first.Start.Val.Less(nbf, start.Val); err != nil {
+				return nil, false, err
+			} else if less {
+				start = first.Start
+			}
+
+			if less, err := end.Val.Less(nbf, last.End.Val); err != nil {
+				return nil, false, err
+			} else if less {
+				end = last.End
+			}
+		}
+	}
+
+	if start == nil {
+		return nil, true, nil
+	}
+
+	in := setalgebra.NewInterval(nbf, start, end)
+	return &in, true, nil
+}
+
+// keyRangeForInterval returns the range of keys whose first element is tagged |tag| and whose first value lies within
+// the interval given. A nil interval is empty.
+func keyRangeForInterval(nbf *types.NomsBinFormat, tag types.Uint, in *setalgebra.Interval) (*diffKeyRange, error) {
+	if in == nil {
+		return &diffKeyRange{check: noms.InRangeCheckNever{}}, nil
+	}
+
+	kr := &diffKeyRange{check: noms.InRangeCheckAlways{}}
+	if in.Start != nil {
+		var err error
+		if in.Start.Inclusive {
+			kr.start, err = types.NewTuple(nbf, tag, in.Start.Val)
+		} else {
+			kr.start, err = types.NewTuple(nbf, tag, in.Start.Val, types.Uint(uint64(0xffffffffffffffff)))
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if in.End != nil {
+		if in.End.Inclusive {
+			kr.check = checkLessThanOrEquals{in.End.Val}
+		} else {
+			kr.check = checkLessThan{in.End.Val}
+		}
+	}
+
+	return kr, nil
+}
+
+// inRange returns a types.ValueInRange for the end of this range.
+func (kr *diffKeyRange) inRange(ctx context.Context) types.ValueInRange {
+	return func(v types.Value) (bool, error) {
+		valid, _, err := kr.check.Check(ctx, v.(types.Tuple))
+		return valid, err
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+func TestKeyRangeForFilters(t *testing.T) {
+	toPK := expression.NewGetField(0, sql.Int64, toNamer(pk0Name), true)
+	fromPK := expression.NewGetField(2, sql.Int64, fromNamer(pk0Name), true)
+	toC1 := expression.NewGetField(1, sql.Int64, toNamer(c1Name), true)
+	lit := func(v int64) sql.Expression {
+		return expression.NewLiteral(v, sql.Int64)
+	}
+
+	tests := []struct {
+		name     string
+		filters  []sql.Expression
+		expected []int64
+		noRange  bool
+	}{
+		{
+			name:    "no filters",
+			filters: nil,
+			noRange: true,
+		},
+		{
+			name:    "non key filter",
+			filters: []sql.Expression{expression.NewEquals(toC1, lit(5))},
+			noRange: true,
+		},
+		{
+			name:     "to equals",
+			filters:  []sql.Expression{expression.NewEquals(toPK, lit(5))},
+			expected: []int64{5},
+		},
+		{
+			name:     "from greater than",
+			filters:  []sql.Expression{expression.NewGreaterThan(fromPK, lit(15))},
+			expected: int64Range(16, 20, 1),
+		},
+		{
+			name: "to and from bounds",
+			filters: []sql.Expression{
+				expression.NewGreaterThanOrEqual(toPK, lit(3)),
+				expression.NewLessThan(fromPK, lit(7)),
+				expression.NewEquals(toC1, lit(5)),
+			},
+			expected: int64Range(3, 7, 1),
+		},
+		{
+			name: "in tuple",
+			filters: []sql.Expression{expression.NewInTuple(
+				toPK,
+				expression.NewTuple(lit(12), lit(4), lit(8)))},
+			expected: int64Range(4, 13, 1),
+		},
+		{
+			name: "or of interval and point",
+			filters: []sql.Expression{expression.NewOr(
+				expression.NewLessThanOrEqual(toPK, lit(2)),
+				expression.NewEquals(fromPK, lit(9)))},
+			noRange: true,
+		},
+		{
+			name: "disjoint",
+			filters: []sql.Expression{
+				expression.NewEquals(toPK, lit(3)),
+				expression.NewEquals(fromPK, lit(4)),
+			},
+			expected: nil,
+		},
+	}
+
+	ctx := context.Background()
+	nbf := types.Format_Default
+	ss, err := schema.NewSuperSchema(oneIntPKSch)
+	require.NoError(t, err)
+	proj, err := newSuperSchemaProjection(ss)
+	require.NoError(t, err)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kr, err := keyRangeForFilters(nbf, proj, oneIntPKSch, test.filters)
+			require.NoError(t, err)
+
+			if test.noRange {
+				assert.Nil(t, kr)
+				return
+			}
+
+			require.NotNil(t, kr)
+			inRange := kr.inRange(ctx)
+
+			var actual []int64
+			for i := int64(0); i < 20; i++ {
+				key, err := types.NewTuple(nbf, types.Uint(pk0Tag), types.Int(i))
+				require.NoError(t, err)
+
+				if kr.start != nil {
+					if less, err := key.Less(nbf, kr.start); err != nil {
+						require.NoError(t, err)
+					} else if less {
+						continue
+					}
+				}
+
+				ok, err := inRange(key)
+				require.NoError(t, err)
+				if ok {
+					actual = append(actual, i)
+				}
+			}
+
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
@@ -15,7 +15,6 @@
 package dtables
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -40,10 +39,11 @@ const (
 	toCommitDate   = "to_commit_date"
 	fromCommitDate = "from_commit_date"
 
-	diffTypeColName  = "diff_type"
-	diffTypeAdded    = "added"
-	diffTypeModified = "modified"
-	diffTypeRemoved  = "removed"
+	diffTypeColName      = "diff_type"
+	diffTypeAdded        = "added"
+	diffTypeModified     = "modified"
+	diffTypeRemoved      = "removed"
+	diffTypeSchemaChange = "schema_change"
 )
 
 func toNamer(name string) string {
@@ -63,7 +63,7 @@ type DiffTable struct {
 	workingRoot *doltdb.RootValue
 	head        *doltdb.Commit
 
-	ss               *schema.SuperSchema
+	proj             *schemaProjection
 	joiner           *rowconv.Joiner
 	sqlSch           sql.PrimaryKeySchema
 	partitionFilters []sql.Expression
@@ -74,8 +74,11 @@ var PrimaryKeyChangeWarning = "cannot render full diff between commits %s and %s
 
 const PrimaryKeyChanceWarningCode int = 1105 // Since this our own custom warning we'll use 1105, the code for an unknown error
 
+// NewDiffTable creates a DiffTable for the table with the name given. Rows from every commit are projected onto the
+// schema of the table in |root|, following columns which have been renamed or had their types changed, and each
+// commit which changed the schema of the table has a row of its own with a diff_type of schema_change.
 func NewDiffTable(ctx *sql.Context, tblName string, ddb *doltdb.DoltDB, root *doltdb.RootValue, head *doltdb.Commit) (sql.Table, error) {
-	tblName, ok, err := root.ResolveTableName(ctx, tblName)
+	tbl, tblName, ok, err := root.GetTableInsensitive(ctx, tblName)
 	if err != nil {
 		return nil, err
 	}
@@ -89,20 +92,20 @@ func NewDiffTable(ctx *sql.Context, tblName string, ddb *doltdb.DoltDB, root *do
 		return nil, err
 	}
 
-	_ = ss.AddColumn(schema.NewColumn("commit", schema.DiffCommitTag, types.StringKind, false))
-	_ = ss.AddColumn(schema.NewColumn("commit_date", schema.DiffCommitDateTag, types.TimestampKind, false))
-
-	sch, err := ss.GenerateSchema()
+	currSch, err := tbl.GetSchema(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	if sch.GetAllCols().Size() <= 1 {
-		return nil, sql.ErrTableNotFound.New(diffTblName)
+	proj, err := newCurrentSchemaProjection(ss, currSch,
+		schema.NewColumn("commit", schema.DiffCommitTag, types.StringKind, false),
+		schema.NewColumn("commit_date", schema.DiffCommitDateTag, types.TimestampKind, false))
+	if err != nil {
+		return nil, err
 	}
 
 	j, err := rowconv.NewJoiner(
-		[]rowconv.NamedSchema{{Name: diff.To, Sch: sch}, {Name: diff.From, Sch: sch}},
+		[]rowconv.NamedSchema{{Name: diff.To, Sch: proj.sch}, {Name: diff.From, Sch: proj.sch}},
 		map[string]rowconv.ColNamingFunc{
 			diff.To:   toNamer,
 			diff.From: fromNamer,
@@ -128,7 +131,7 @@ func NewDiffTable(ctx *sql.Context, tblName string, ddb *doltdb.DoltDB, root *do
 		ddb:              ddb,
 		workingRoot:      root,
 		head:             head,
-		ss:               ss,
+		proj:             proj,
 		joiner:           j,
 		sqlSch:           sqlSch,
 		partitionFilters: nil,
@@ -188,7 +191,18 @@ func (dt *DiffTable) WithFilters(ctx *sql.Context, filters []sql.Expression) sql
 
 func (dt *DiffTable) PartitionRows(ctx *sql.Context, part sql.Partition) (sql.RowIter, error) {
 	dp := part.(diffPartition)
-	return dp.getRowIter(ctx, dt.ddb, dt.ss, dt.joiner)
+	itr, err := dp.getRowIter(ctx, dt.ddb, dt.proj, dt.joiner, dt.rowFilters)
+	if err != nil {
+		return nil, err
+	}
+
+	itr.schemaChange, err = dp.isSchemaChange(ctx)
+	if err != nil {
+		_ = itr.Close(ctx)
+		return nil, err
+	}
+
+	return itr, nil
 }
 
 func tableData(ctx *sql.Context, tbl *doltdb.Table, ddb *doltdb.DoltDB) (types.Map, schema.Schema, error) {
@@ -227,8 +241,11 @@ type diffRowItr struct {
 	diffSrc        *diff.RowDiffSource
 	joiner         *rowconv.Joiner
 	sch            schema.Schema
+	nbf            *types.NomsBinFormat
 	fromCommitInfo commitInfo
 	toCommitInfo   commitInfo
+	// schemaChange is true when a schema_change row is to be returned ahead of the rows of the diff
+	schemaChange bool
 }
 
 type commitInfo struct {
@@ -240,20 +257,75 @@ type commitInfo struct {
 
 // Next returns the next row
 func (itr *diffRowItr) Next(*sql.Context) (sql.Row, error) {
-	r, _, err := itr.diffSrc.NextDiff()
+	if itr.schemaChange {
+		itr.schemaChange = false
+		return itr.schemaChangeRow()
+	}
+
+	var r row.Row
+	var hasTo, hasFrom bool
+	for {
+		var err error
+		r, _, err = itr.diffSrc.NextDiff()
+
+		if err != nil {
+			return nil, err
+		}
+
+		toAndFromRows, err := itr.joiner.Split(r)
+		if err != nil {
+			return nil, err
+		}
+		var to, from row.Row
+		to, hasTo = toAndFromRows[diff.To]
+		from, hasFrom = toAndFromRows[diff.From]
+
+		// rows whose changes don't survive projection onto the current schema, such as those only changed by giving
+		// a column a new type, aren't shown as modified
+		projSch := itr.joiner.SchemaForName(diff.To)
+		if !hasTo || !hasFrom || schema.IsKeyless(projSch) || !row.AreEqual(to, from, projSch) {
+			break
+		}
+	}
+
+	sqlRow, err := itr.sqlRowWithCommitInfo(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if hasTo && hasFrom {
+		sqlRow = append(sqlRow, diffTypeModified)
+	} else if hasTo && !hasFrom {
+		sqlRow = append(sqlRow, diffTypeAdded)
+	} else {
+		sqlRow = append(sqlRow, diffTypeRemoved)
+	}
+
+	return sqlRow, nil
+}
+
+// schemaChangeRow returns the row marking a change to the schema of the table between the commits being diffed. All
+// of its to_ and from_ columns are NULL, other than those of the commits.
+func (itr *diffRowItr) schemaChangeRow() (sql.Row, error) {
+	r, err := row.New(itr.nbf, itr.sch, row.TaggedValues{})
 
 	if err != nil {
 		return nil, err
 	}
 
-	toAndFromRows, err := itr.joiner.Split(r)
+	sqlRow, err := itr.sqlRowWithCommitInfo(r)
+
 	if err != nil {
 		return nil, err
 	}
-	_, hasTo := toAndFromRows[diff.To]
-	_, hasFrom := toAndFromRows[diff.From]
 
-	r, err = r.SetColVal(itr.toCommitInfo.nameTag, types.String(itr.toCommitInfo.name), itr.sch)
+	return append(sqlRow, diffTypeSchemaChange), nil
+}
+
+// sqlRowWithCommitInfo returns the sql row for the joined row given, with the to_ and from_ commit columns set.
+func (itr *diffRowItr) sqlRowWithCommitInfo(r row.Row) (sql.Row, error) {
+	r, err := r.SetColVal(itr.toCommitInfo.nameTag, types.String(itr.toCommitInfo.name), itr.sch)
 	if err != nil {
 		return nil, err
 	}
@@ -280,21 +352,7 @@ func (itr *diffRowItr) Next(*sql.Context) (sql.Row, error) {
 		}
 	}
 
-	sqlRow, err := sqlutil.DoltRowToSqlRow(r, itr.sch)
-
-	if err != nil {
-		return nil, err
-	}
-
-	if hasTo && hasFrom {
-		sqlRow = append(sqlRow, diffTypeModified)
-	} else if hasTo && !hasFrom {
-		sqlRow = append(sqlRow, diffTypeAdded)
-	} else {
-		sqlRow = append(sqlRow, diffTypeRemoved)
-	}
-
-	return sqlRow, nil
+	return sqlutil.DoltRowToSqlRow(r, itr.sch)
 }
 
 // Close closes the iterator
@@ -332,7 +390,9 @@ func (dp diffPartition) Key() []byte {
 	return []byte(dp.toName + dp.fromName)
 }
 
-func (dp diffPartition) getRowIter(ctx *sql.Context, ddb *doltdb.DoltDB, ss *schema.SuperSchema, joiner *rowconv.Joiner) (sql.RowIter, error) {
+// getRowIter returns an iterator over the rows of the diff of this partition. If |keyFilters| limit the primary keys of
+// rows that can match them, only the range of row data that can match is diffed.
+func (dp diffPartition) getRowIter(ctx *sql.Context, ddb *doltdb.DoltDB, proj *schemaProjection, joiner *rowconv.Joiner, keyFilters []sql.Expression) (*diffRowItr, error) {
 	fromData, fromSch, err := tableData(ctx, dp.from, ddb)
 
 	if err != nil {
@@ -345,13 +405,13 @@ func (dp diffPartition) getRowIter(ctx *sql.Context, ddb *doltdb.DoltDB, ss *sch
 		return nil, err
 	}
 
-	fromConv, err := rowConvForSchema(ctx, ddb.ValueReadWriter(), ss, fromSch)
+	fromConv, err := proj.rowConvForSchema(ctx, ddb.ValueReadWriter(), fromSch)
 
 	if err != nil {
 		return nil, err
 	}
 
-	toConv, err := rowConvForSchema(ctx, ddb.ValueReadWriter(), ss, toSch)
+	toConv, err := proj.rowConvForSchema(ctx, ddb.ValueReadWriter(), toSch)
 
 	if err != nil {
 		return nil, err
@@ -366,8 +426,26 @@ func (dp diffPartition) getRowIter(ctx *sql.Context, ddb *doltdb.DoltDB, ss *sch
 	fromCmInfo := commitInfo{types.String(dp.fromName), dp.fromDate, fromCol.Tag, fromDateCol.Tag}
 	toCmInfo := commitInfo{types.String(dp.toName), dp.toDate, toCol.Tag, toDateCol.Tag}
 
+	keySch := toSch
+	if dp.to == nil {
+		keySch = fromSch
+	} else if dp.from != nil && !keyPrefixesMatch(fromSch, toSch) {
+		// the keys of the two sides of the diff can't be limited to the same range
+		keyFilters = nil
+	}
+
+	kr, err := keyRangeForFilters(ddb.Format(), proj, keySch, keyFilters)
+
+	if err != nil {
+		return nil, err
+	}
+
 	rd := diff.NewRowDiffer(ctx, fromSch, toSch, 1024)
-	rd.Start(ctx, fromData, toData)
+	if ad, ok := rd.(*diff.AsyncDiffer); ok && kr != nil {
+		ad.StartWithRange(ctx, fromData, toData, kr.start, kr.inRange(ctx))
+	} else {
+		rd.Start(ctx, fromData, toData)
+	}
 
 	src := diff.NewRowDiffSource(rd, joiner)
 	src.AddInputRowConversion(fromConv, toConv)
@@ -377,6 +455,7 @@ func (dp diffPartition) getRowIter(ctx *sql.Context, ddb *doltdb.DoltDB, ss *sch
 		diffSrc:        src,
 		joiner:         joiner,
 		sch:            joiner.GetSchema(),
+		nbf:            ddb.Format(),
 		fromCommitInfo: fromCmInfo,
 		toCommitInfo:   toCmInfo,
 	}, nil
@@ -403,6 +482,34 @@ func (dp *diffPartition) isDiffablePartition(ctx *sql.Context) (bool, error) {
 	return schema.ArePrimaryKeySetsDiffable(fromSch, toSch), nil
 }
 
+// isSchemaChange checks if the schema of the table changed between the commits of this partition.
+func (dp *diffPartition) isSchemaChange(ctx *sql.Context) (bool, error) {
+	if dp.from == nil || dp.to == nil {
+		return false, nil
+	}
+
+	fromSch, err := dp.from.GetSchema(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	toSch, err := dp.to.GetSchema(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return !schema.SchemasAreEqual(fromSch, toSch), nil
+}
+
+// keyPrefixesMatch returns whether the keys of tables with the schemas given begin with the same column.
+func keyPrefixesMatch(sch1, sch2 schema.Schema) bool {
+	if schema.IsKeyless(sch1) || schema.IsKeyless(sch2) {
+		return false
+	}
+
+	return sch1.GetPKCols().GetByIndex(0).Tag == sch2.GetPKCols().GetByIndex(0).Tag
+}
+
 type partitionSelectFunc func(*sql.Context, diffPartition) (bool, error)
 
 func selectFuncForFilters(nbf *types.NomsBinFormat, filters []sql.Expression) (partitionSelectFunc, error) {
@@ -587,30 +694,3 @@ func (dp *diffPartitions) Next(ctx *sql.Context) (sql.Partition, error) {
 func (dp *diffPartitions) Close(*sql.Context) error {
 	return nil
 }
-
-// creates a RowConverter for transforming rows with the the given schema to this super schema.
-func rowConvForSchema(ctx context.Context, vrw types.ValueReadWriter, ss *schema.SuperSchema, sch schema.Schema) (*rowconv.RowConverter, error) {
-	if schema.SchemasAreEqual(sch, schema.EmptySchema) {
-		return rowconv.IdentityConverter, nil
-	}
-
-	inNameToOutName, err := ss.NameMapForSchema(sch)
-
-	if err != nil {
-		return nil, err
-	}
-
-	ssch, err := ss.GenerateSchema()
-
-	if err != nil {
-		return nil, err
-	}
-
-	fm, err := rowconv.NameMapping(sch, ssch, inNameToOutName)
-
-	if err != nil {
-		return nil, err
-	}
-
-	return rowconv.NewRowConverter(ctx, vrw, fm)
-}
@@ -52,7 +52,7 @@ var _ sql.FilteredTable = (*HistoryTable)(nil)
 type HistoryTable struct {
 	name                  string
 	ddb                   *doltdb.DoltDB
-	ss                    *schema.SuperSchema
+	proj                  *schemaProjection
 	sqlSch                sql.PrimaryKeySchema
 	commitFilters         []sql.Expression
 	rowFilters            []sql.Expression
@@ -60,9 +60,10 @@ type HistoryTable struct {
 	readerCreateFuncCache *ThreadSafeCRFuncCache
 }
 
-// NewHistoryTable creates a history table
+// NewHistoryTable creates a history table for the table with the name given. Rows from every commit are projected onto
+// the schema of the table in |root|, following columns which have been renamed or had their types changed.
 func NewHistoryTable(ctx *sql.Context, tblName string, ddb *doltdb.DoltDB, root *doltdb.RootValue, head *doltdb.Commit) (sql.Table, error) {
-	tblName, ok, err := root.ResolveTableName(ctx, tblName)
+	tbl, tblName, ok, err := root.GetTableInsensitive(ctx, tblName)
 	if err != nil {
 		return nil, err
 	}
@@ -75,21 +76,21 @@ func NewHistoryTable(ctx *sql.Context, tblName string, ddb *doltdb.DoltDB, root
 		return nil, err
 	}
 
-	_ = ss.AddColumn(schema.NewColumn(CommitHashCol, schema.HistoryCommitHashTag, types.StringKind, false))
-	_ = ss.AddColumn(schema.NewColumn(CommitterCol, schema.HistoryCommitterTag, types.StringKind, false))
-	_ = ss.AddColumn(schema.NewColumn(CommitDateCol, schema.HistoryCommitDateTag, types.TimestampKind, false))
-
-	sch, err := ss.GenerateSchema()
+	currSch, err := tbl.GetSchema(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	if sch.GetAllCols().Size() <= 3 {
-		return nil, sql.ErrTableNotFound.New(doltdb.DoltHistoryTablePrefix + tblName)
+	proj, err := newCurrentSchemaProjection(ss, currSch,
+		schema.NewColumn(CommitHashCol, schema.HistoryCommitHashTag, types.StringKind, false),
+		schema.NewColumn(CommitterCol, schema.HistoryCommitterTag, types.StringKind, false),
+		schema.NewColumn(CommitDateCol, schema.HistoryCommitDateTag, types.TimestampKind, false))
+	if err != nil {
+		return nil, err
 	}
 
 	tableName := doltdb.DoltHistoryTablePrefix + tblName
-	sqlSch, err := sqlutil.FromDoltSchema(tableName, sch)
+	sqlSch, err := sqlutil.FromDoltSchema(tableName, proj.sch)
 	if err != nil {
 		return nil, err
 	}
@@ -98,7 +99,7 @@ func NewHistoryTable(ctx *sql.Context, tblName string, ddb *doltdb.DoltDB, root
 	return &HistoryTable{
 		name:                  tblName,
 		ddb:                   ddb,
-		ss:                    ss,
+		proj:                  proj,
 		sqlSch:                sqlSch,
 		cmItr:                 cmItr,
 		readerCreateFuncCache: NewThreadSafeCRFuncCache(),
@@ -245,7 +246,8 @@ func (ht *HistoryTable) String() string {
 	return doltdb.DoltHistoryTablePrefix + ht.name
 }
 
-// Schema returns the schema for the history table, which will be the super set of the schemas from the history
+// Schema returns the schema for the history table, which is the current schema of the table followed by the columns
+// of the commit
 func (ht *HistoryTable) Schema() sql.Schema {
 	return ht.sqlSch.Schema
 }
@@ -259,7 +261,7 @@ func (ht *HistoryTable) Partitions(ctx *sql.Context) (sql.PartitionIter, error)
 func (ht *HistoryTable) PartitionRows(ctx *sql.Context, part sql.Partition) (sql.RowIter, error) {
 	cp := part.(*commitPartition)
 
-	return newRowItrForTableAtCommit(ctx, cp.h, cp.cm, ht.name, ht.ss, ht.rowFilters, ht.readerCreateFuncCache)
+	return newRowItrForTableAtCommit(ctx, cp.h, cp.cm, ht.name, ht.proj, ht.rowFilters, ht.readerCreateFuncCache)
 }
 
 // commitPartition is a single commit
@@ -295,11 +297,11 @@ func (cp commitPartitioner) Close(*sql.Context) error {
 }
 
 type rowItrForTableAtCommit struct {
-	rd             table.TableReadCloser
-	sch            schema.Schema
-	toSuperSchConv *rowconv.RowConverter
-	extraVals      map[uint64]types.Value
-	empty          bool
+	rd            table.TableReadCloser
+	sch           schema.Schema
+	toProjSchConv *rowconv.RowConverter
+	extraVals     map[uint64]types.Value
+	empty         bool
 }
 
 func newRowItrForTableAtCommit(
@@ -307,7 +309,7 @@ func newRowItrForTableAtCommit(
 	h hash.Hash,
 	cm *doltdb.Commit,
 	tblName string,
-	ss *schema.SuperSchema,
+	proj *schemaProjection,
 	filters []sql.Expression,
 	readerCreateFuncCache *ThreadSafeCRFuncCache) (*rowItrForTableAtCommit, error) {
 	root, err := cm.GetRootValue()
@@ -342,36 +344,43 @@ func newRowItrForTableAtCommit(
 		return nil, err
 	}
 
-	toSuperSchConv, err := rowConvForSchema(ctx, tbl.ValueReadWriter(), ss, tblSch)
+	toProjSchConv, err := proj.rowConvForSchema(ctx, tbl.ValueReadWriter(), tblSch)
 
 	if err != nil {
 		return nil, err
 	}
 
-	createReaderFunc, err := readerCreateFuncCache.GetOrCreate(schHash, tbl.Format(), tblSch, filters)
+	// filters are on the columns of the projected schema, and can only limit the rows read when the key column they
+	// apply to is the same at this commit
+	projKeyCol, ok, err := proj.projectedKeyColumn(tblSch)
 
 	if err != nil {
 		return nil, err
 	}
 
-	rd, err := createReaderFunc(ctx, m)
+	if !ok || projKeyCol.Name != tblSch.GetPKCols().GetByIndex(0).Name {
+		filters = nil
+	}
+
+	createReaderFunc, err := readerCreateFuncCache.GetOrCreate(schHash, tbl.Format(), tblSch, filters)
 
 	if err != nil {
 		return nil, err
 	}
 
-	sch, err := ss.GenerateSchema()
+	rd, err := createReaderFunc(ctx, m)
 
 	if err != nil {
 		return nil, err
 	}
 
+	sch := proj.sch
 	hashCol, hashOK := sch.GetAllCols().GetByName(CommitHashCol)
 	dateCol, dateOK := sch.GetAllCols().GetByName(CommitDateCol)
 	committerCol, commiterOK := sch.GetAllCols().GetByName(CommitterCol)
 
 	if !hashOK || !dateOK || !commiterOK {
-		panic("Bug: History table super schema should always have commit_hash")
+		panic("Bug: History table schema should always have commit_hash")
 	}
 
 	meta, err := cm.GetCommitMeta()
@@ -381,9 +390,9 @@ func newRowItrForTableAtCommit(
 	}
 
 	return &rowItrForTableAtCommit{
-		rd:             rd,
-		sch:            sch,
-		toSuperSchConv: toSuperSchConv,
+		rd:            rd,
+		sch:           sch,
+		toProjSchConv: toProjSchConv,
 		extraVals: map[uint64]types.Value{
 			hashCol.Tag:      types.String(h.String()),
 			dateCol.Tag:      types.Timestamp(meta.Time()),
@@ -406,7 +415,7 @@ func (tblItr *rowItrForTableAtCommit) Next(ctx *sql.Context) (sql.Row, error) {
 		return nil, err
 	}
 
-	r, err = tblItr.toSuperSchConv.Convert(r)
+	r, err = tblItr.toProjSchConv.Convert(r)
 
 	if err != nil {
 		return nil, err
@@ -0,0 +1,126 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"io"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+var _ sql.Table = (*ReflogTable)(nil)
+
+// ReflogTable is a sql.Table implementation that implements a system table which shows the updates made to the
+// branches, tags and working sets of the database, most recent first
+type ReflogTable struct {
+	ddb *doltdb.DoltDB
+}
+
+// NewReflogTable creates a ReflogTable
+func NewReflogTable(_ *sql.Context, ddb *doltdb.DoltDB) sql.Table {
+	return &ReflogTable{ddb}
+}
+
+// Name is a sql.Table interface function which returns the name of the table which is defined by the constant
+// ReflogTableName
+func (rt *ReflogTable) Name() string {
+	return doltdb.ReflogTableName
+}
+
+// String is a sql.Table interface function which returns the name of the table which is defined by the constant
+// ReflogTableName
+func (rt *ReflogTable) String() string {
+	return doltdb.ReflogTableName
+}
+
+// Schema is a sql.Table interface function that gets the sql.Schema of the reflog system table
+func (rt *ReflogTable) Schema() sql.Schema {
+	return []*sql.Column{
+		{Name: "ref", Type: sql.Text, Source: doltdb.ReflogTableName, PrimaryKey: true, Nullable: false},
+		{Name: "ref_index", Type: sql.Int64, Source: doltdb.ReflogTableName, PrimaryKey: true, Nullable: false},
+		{Name: "old_hash", Type: sql.Text, Source: doltdb.ReflogTableName, PrimaryKey: false, Nullable: true},
+		{Name: "new_hash", Type: sql.Text, Source: doltdb.ReflogTableName, PrimaryKey: false, Nullable: true},
+		{Name: "command", Type: sql.Text, Source: doltdb.ReflogTableName, PrimaryKey: false, Nullable: true},
+		{Name: "user", Type: sql.Text, Source: doltdb.ReflogTableName, PrimaryKey: false, Nullable: true},
+		{Name: "date", Type: sql.Datetime, Source: doltdb.ReflogTableName, PrimaryKey: false, Nullable: false},
+	}
+}
+
+// Partitions is a sql.Table interface function that returns a partition of the data.  Currently the data is unpartitioned.
+func (rt *ReflogTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return index.SinglePartitionIterFromNomsMap(nil), nil
+}
+
+// PartitionRows is a sql.Table interface function that gets a row iterator for a partition
+func (rt *ReflogTable) PartitionRows(sqlCtx *sql.Context, part sql.Partition) (sql.RowIter, error) {
+	return NewReflogItr(sqlCtx, rt.ddb)
+}
+
+// ReflogItr is a sql.RowItr implementation which iterates over each reflog entry as if it's a row in the table.
+type ReflogItr struct {
+	entries []doltdb.ReflogEntry
+	idx     int
+}
+
+// NewReflogItr creates a ReflogItr from the current environment.
+func NewReflogItr(sqlCtx *sql.Context, ddb *doltdb.DoltDB) (*ReflogItr, error) {
+	if ddb.Reflog() == nil {
+		return &ReflogItr{}, nil
+	}
+
+	entries, err := ddb.Reflog().Entries()
+
+	if err != nil {
+		return nil, err
+	}
+
+	// most recent first
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+
+	return &ReflogItr{entries, 0}, nil
+}
+
+// Next retrieves the next row. It will return io.EOF if it's the last row.
+// After retrieving the last row, Close will be automatically closed.
+func (itr *ReflogItr) Next(*sql.Context) (sql.Row, error) {
+	if itr.idx >= len(itr.entries) {
+		return nil, io.EOF
+	}
+
+	defer func() {
+		itr.idx++
+	}()
+
+	e := itr.entries[itr.idx]
+	return sql.NewRow(e.Ref, int64(e.Index), hashOrNil(e.OldHash), hashOrNil(e.NewHash), e.Command, e.User, e.Timestamp), nil
+}
+
+// Close closes the iterator.
+func (itr *ReflogItr) Close(*sql.Context) error {
+	return nil
+}
+
+func hashOrNil(h hash.Hash) interface{} {
+	if h.IsEmpty() {
+		return nil
+	}
+	return h.String()
+}
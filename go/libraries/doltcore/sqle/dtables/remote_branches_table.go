@@ -0,0 +1,86 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
+)
+
+var _ sql.Table = (*RemoteBranchesTable)(nil)
+
+// RemoteBranchesTable is a sql.Table implementation that implements a read only system table which shows the
+// remote-tracking branches, as last fetched from each remote
+type RemoteBranchesTable struct {
+	ddb *doltdb.DoltDB
+}
+
+// NewRemoteBranchesTable creates a RemoteBranchesTable
+func NewRemoteBranchesTable(_ *sql.Context, ddb *doltdb.DoltDB) sql.Table {
+	return &RemoteBranchesTable{ddb: ddb}
+}
+
+// Name is a sql.Table interface function which returns the name of the table which is defined by the constant
+// RemoteBranchesTableName
+func (rbt *RemoteBranchesTable) Name() string {
+	return doltdb.RemoteBranchesTableName
+}
+
+// String is a sql.Table interface function which returns the name of the table which is defined by the constant
+// RemoteBranchesTableName
+func (rbt *RemoteBranchesTable) String() string {
+	return doltdb.RemoteBranchesTableName
+}
+
+// Schema is a sql.Table interface function that gets the sql.Schema of the remote branches system table. Branches are
+// named by their remote and branch, e.g. remotes/origin/main.
+func (rbt *RemoteBranchesTable) Schema() sql.Schema {
+	return []*sql.Column{
+		{Name: "name", Type: sql.Text, Source: doltdb.RemoteBranchesTableName, PrimaryKey: true, Nullable: false},
+		{Name: "hash", Type: sql.Text, Source: doltdb.RemoteBranchesTableName, PrimaryKey: false, Nullable: false},
+		{Name: "latest_committer", Type: sql.Text, Source: doltdb.RemoteBranchesTableName, PrimaryKey: false, Nullable: true},
+		{Name: "latest_committer_email", Type: sql.Text, Source: doltdb.RemoteBranchesTableName, PrimaryKey: false, Nullable: true},
+		{Name: "latest_commit_date", Type: sql.Datetime, Source: doltdb.RemoteBranchesTableName, PrimaryKey: false, Nullable: true},
+		{Name: "latest_commit_message", Type: sql.Text, Source: doltdb.RemoteBranchesTableName, PrimaryKey: false, Nullable: true},
+	}
+}
+
+// Partitions is a sql.Table interface function that returns a partition of the data.  Currently the data is unpartitioned.
+func (rbt *RemoteBranchesTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return index.SinglePartitionIterFromNomsMap(nil), nil
+}
+
+// PartitionRows is a sql.Table interface function that gets a row iterator for a partition
+func (rbt *RemoteBranchesTable) PartitionRows(sqlCtx *sql.Context, part sql.Partition) (sql.RowIter, error) {
+	remoteRefs, err := rbt.ddb.GetRemoteRefs(sqlCtx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	itr, err := newBranchItrForRefs(sqlCtx, rbt.ddb, remoteRefs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i, name := range itr.branches {
+		itr.branches[i] = "remotes/" + name
+	}
+
+	return itr, nil
+}
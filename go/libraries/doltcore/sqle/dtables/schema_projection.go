@@ -0,0 +1,139 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/rowconv"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+// schemaProjection projects the rows of a table, stored with any of the schemas the table has had over its history,
+// onto the single schema of a system table which shows the rows of the table at many commits.
+type schemaProjection struct {
+	// sch is the schema rows are projected onto
+	sch schema.Schema
+	// tagMapping returns a mapping from the tags of a schema in the table's history to the tags of sch
+	tagMapping func(sch schema.Schema) (map[uint64]uint64, error)
+}
+
+// newSuperSchemaProjection creates a schemaProjection onto the schema generated from |ss|, which has a column for
+// every column the table has ever had. Columns are mapped by tag.
+func newSuperSchemaProjection(ss *schema.SuperSchema) (*schemaProjection, error) {
+	sch, err := ss.GenerateSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemaProjection{
+		sch: sch,
+		tagMapping: func(histSch schema.Schema) (map[uint64]uint64, error) {
+			mapping := make(map[uint64]uint64)
+			err := histSch.GetAllCols().Iter(func(tag uint64, col schema.Column) (stop bool, err error) {
+				if _, ok := ss.GetByTag(tag); !ok {
+					return true, errors.New("failed to map columns")
+				}
+				mapping[tag] = tag
+				return false, nil
+			})
+
+			return mapping, err
+		},
+	}, nil
+}
+
+// newCurrentSchemaProjection creates a schemaProjection onto the columns of |current|, the schema the table has now,
+// followed by |extraCols|. Rows are projected through the lineage of each column, tracked by |ss|, so the values of
+// columns which have since been renamed or had their types changed are shown in the column they became. Values which
+// can't be converted to the current type of their column, and those of columns which have since been dropped, are
+// not shown.
+func newCurrentSchemaProjection(ss *schema.SuperSchema, current schema.Schema, extraCols ...schema.Column) (*schemaProjection, error) {
+	// constraints aren't projected, as rows from the history of the table needn't satisfy them
+	var cols []schema.Column
+	err := current.GetAllCols().Iter(func(tag uint64, col schema.Column) (stop bool, err error) {
+		cols = append(cols, schema.Column{
+			Name:       col.Name,
+			Tag:        col.Tag,
+			Kind:       col.Kind,
+			IsPartOfPK: col.IsPartOfPK,
+			TypeInfo:   col.TypeInfo,
+		})
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sch, err := schema.SchemaFromCols(schema.NewColCollection(append(cols, extraCols...)...))
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemaProjection{
+		sch: sch,
+		tagMapping: func(histSch schema.Schema) (map[uint64]uint64, error) {
+			return ss.LineageMapping(histSch, current), nil
+		},
+	}, nil
+}
+
+// rowConvForSchema creates a RowConverter for transforming rows with the given schema to the projected schema.
+func (sp *schemaProjection) rowConvForSchema(ctx context.Context, vrw types.ValueReadWriter, sch schema.Schema) (*rowconv.RowConverter, error) {
+	if schema.SchemasAreEqual(sch, schema.EmptySchema) {
+		return rowconv.IdentityConverter, nil
+	}
+
+	tagMapping, err := sp.tagMapping(sch)
+	if err != nil {
+		return nil, err
+	}
+
+	fm, err := rowconv.NewFieldMapping(sch, sp.sch, tagMapping)
+	if err != nil {
+		return nil, err
+	}
+
+	return rowconv.NewLossyRowConverter(ctx, vrw, fm)
+}
+
+// projectedKeyColumn returns the column that the first primary key column of |sch| is projected onto, and false if it
+// isn't projected onto a column of the same type. Only when it is can filters on the projected column be used to limit
+// the keys of the table data read.
+func (sp *schemaProjection) projectedKeyColumn(sch schema.Schema) (schema.Column, bool, error) {
+	if schema.IsKeyless(sch) {
+		return schema.Column{}, false, nil
+	}
+
+	tagMapping, err := sp.tagMapping(sch)
+	if err != nil {
+		return schema.Column{}, false, err
+	}
+
+	pkCol := sch.GetPKCols().GetByIndex(0)
+	projTag, ok := tagMapping[pkCol.Tag]
+	if !ok {
+		return schema.Column{}, false, nil
+	}
+
+	projCol, ok := sp.sch.GetAllCols().GetByTag(projTag)
+	if !ok || !projCol.TypeInfo.Equals(pkCol.TypeInfo) {
+		return schema.Column{}, false, nil
+	}
+
+	return projCol, true, nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"io"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
+)
+
+var _ sql.Table = (*StashesTable)(nil)
+
+// StashesTable is a sql.Table implementation that implements a system table which shows the dolt stashes
+type StashesTable struct {
+	ddb *doltdb.DoltDB
+}
+
+// NewStashesTable creates a StashesTable
+func NewStashesTable(_ *sql.Context, ddb *doltdb.DoltDB) sql.Table {
+	return &StashesTable{ddb}
+}
+
+// Name is a sql.Table interface function which returns the name of the table which is defined by the constant
+// StashesTableName
+func (st *StashesTable) Name() string {
+	return doltdb.StashesTableName
+}
+
+// String is a sql.Table interface function which returns the name of the table which is defined by the constant
+// StashesTableName
+func (st *StashesTable) String() string {
+	return doltdb.StashesTableName
+}
+
+// Schema is a sql.Table interface function that gets the sql.Schema of the stashes system table
+func (st *StashesTable) Schema() sql.Schema {
+	return []*sql.Column{
+		{Name: "name", Type: sql.Text, Source: doltdb.StashesTableName, PrimaryKey: true, Nullable: false},
+		{Name: "hash", Type: sql.Text, Source: doltdb.StashesTableName, PrimaryKey: false, Nullable: false},
+		{Name: "head_commit", Type: sql.Text, Source: doltdb.StashesTableName, PrimaryKey: false, Nullable: false},
+		{Name: "committer", Type: sql.Text, Source: doltdb.StashesTableName, PrimaryKey: false, Nullable: true},
+		{Name: "email", Type: sql.Text, Source: doltdb.StashesTableName, PrimaryKey: false, Nullable: true},
+		{Name: "date", Type: sql.Datetime, Source: doltdb.StashesTableName, PrimaryKey: false, Nullable: true},
+		{Name: "message", Type: sql.Text, Source: doltdb.StashesTableName, PrimaryKey: false, Nullable: true},
+	}
+}
+
+// Partitions is a sql.Table interface function that returns a partition of the data.  Currently the data is unpartitioned.
+func (st *StashesTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return index.SinglePartitionIterFromNomsMap(nil), nil
+}
+
+// PartitionRows is a sql.Table interface function that gets a row iterator for a partition
+func (st *StashesTable) PartitionRows(sqlCtx *sql.Context, part sql.Partition) (sql.RowIter, error) {
+	return NewStashItr(sqlCtx, st.ddb)
+}
+
+// StashItr is a sql.RowItr implementation which iterates over each stash as if it's a row in the table.
+type StashItr struct {
+	stashes []*doltdb.Stash
+	idx     int
+}
+
+// NewStashItr creates a StashItr from the current environment.
+func NewStashItr(sqlCtx *sql.Context, ddb *doltdb.DoltDB) (*StashItr, error) {
+	stashes, err := ddb.GetStashes(sqlCtx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &StashItr{stashes, 0}, nil
+}
+
+// Next retrieves the next row. It will return io.EOF if it's the last row.
+// After retrieving the last row, Close will be automatically closed.
+func (itr *StashItr) Next(*sql.Context) (sql.Row, error) {
+	if itr.idx >= len(itr.stashes) {
+		return nil, io.EOF
+	}
+
+	defer func() {
+		itr.idx++
+	}()
+
+	stash := itr.stashes[itr.idx]
+	meta, err := stash.GetCommitMeta()
+
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := stash.HashOf()
+
+	if err != nil {
+		return nil, err
+	}
+
+	headHash, err := stash.HeadCommit().HashOf()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return sql.NewRow(stash.Name(), h.String(), headHash.String(), meta.Name, meta.Email, meta.Time(), meta.Description), nil
+}
+
+// Close closes the iterator.
+func (itr *StashItr) Close(*sql.Context) error {
+	return nil
+}
@@ -0,0 +1,262 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env/actions"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
+)
+
+var _ sql.Table = (*TagsTable)(nil)
+var _ sql.InsertableTable = (*TagsTable)(nil)
+var _ sql.DeletableTable = (*TagsTable)(nil)
+
+// TagsTable is a sql.Table implementation that implements a system table which shows the dolt tags. Tags are created
+// by inserting rows, and deleted by deleting them.
+type TagsTable struct {
+	dbName string
+	ddb    *doltdb.DoltDB
+}
+
+// NewTagsTable creates a TagsTable
+func NewTagsTable(_ *sql.Context, dbName string, ddb *doltdb.DoltDB) sql.Table {
+	return &TagsTable{dbName: dbName, ddb: ddb}
+}
+
+// Name is a sql.Table interface function which returns the name of the table which is defined by the constant
+// TagsTableName
+func (tt *TagsTable) Name() string {
+	return doltdb.TagsTableName
+}
+
+// String is a sql.Table interface function which returns the name of the table which is defined by the constant
+// TagsTableName
+func (tt *TagsTable) String() string {
+	return doltdb.TagsTableName
+}
+
+// Schema is a sql.Table interface function that gets the sql.Schema of the tags system table
+func (tt *TagsTable) Schema() sql.Schema {
+	return []*sql.Column{
+		{Name: "name", Type: sql.Text, Source: doltdb.TagsTableName, PrimaryKey: true, Nullable: false},
+		{Name: "hash", Type: sql.Text, Source: doltdb.TagsTableName, PrimaryKey: false, Nullable: true},
+		{Name: "tagger", Type: sql.Text, Source: doltdb.TagsTableName, PrimaryKey: false, Nullable: true},
+		{Name: "email", Type: sql.Text, Source: doltdb.TagsTableName, PrimaryKey: false, Nullable: true},
+		{Name: "date", Type: sql.Datetime, Source: doltdb.TagsTableName, PrimaryKey: false, Nullable: true},
+		{Name: "message", Type: sql.Text, Source: doltdb.TagsTableName, PrimaryKey: false, Nullable: true},
+	}
+}
+
+// Partitions is a sql.Table interface function that returns a partition of the data.  Currently the data is unpartitioned.
+func (tt *TagsTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return index.SinglePartitionIterFromNomsMap(nil), nil
+}
+
+// PartitionRows is a sql.Table interface function that gets a row iterator for a partition
+func (tt *TagsTable) PartitionRows(sqlCtx *sql.Context, part sql.Partition) (sql.RowIter, error) {
+	return NewTagItr(sqlCtx, tt.ddb)
+}
+
+// TagItr is a sql.RowItr implementation which iterates over each tag as if it's a row in the table.
+type TagItr struct {
+	tags []*doltdb.Tag
+	idx  int
+}
+
+// NewTagItr creates a TagItr from the current environment.
+func NewTagItr(ctx *sql.Context, ddb *doltdb.DoltDB) (*TagItr, error) {
+	var tags []*doltdb.Tag
+	err := actions.IterResolvedTags(ctx, ddb, func(tag *doltdb.Tag) (bool, error) {
+		tags = append(tags, tag)
+		return false, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &TagItr{tags, 0}, nil
+}
+
+// Next retrieves the next row. It will return io.EOF if it's the last row.
+// After retrieving the last row, Close will be automatically closed.
+func (itr *TagItr) Next(*sql.Context) (sql.Row, error) {
+	if itr.idx >= len(itr.tags) {
+		return nil, io.EOF
+	}
+
+	defer func() {
+		itr.idx++
+	}()
+
+	tag := itr.tags[itr.idx]
+	h, err := tag.Commit.HashOf()
+
+	if err != nil {
+		return nil, err
+	}
+
+	meta := tag.Meta
+	return sql.NewRow(tag.Name, h.String(), meta.Name, meta.Email, meta.Time(), meta.Description), nil
+}
+
+// Close closes the iterator.
+func (itr *TagItr) Close(*sql.Context) error {
+	return nil
+}
+
+// Inserter returns an Inserter for this table. The Inserter will get one call to Insert() for each row to be
+// inserted, and will end with a call to Close() to finalize the insert operation.
+func (tt *TagsTable) Inserter(*sql.Context) sql.RowInserter {
+	return tagWriter{tt}
+}
+
+// Deleter returns a RowDeleter for this table. The RowDeleter will get one call to Delete for each row to be deleted,
+// and will end with a call to Close() to finalize the delete operation.
+func (tt *TagsTable) Deleter(*sql.Context) sql.RowDeleter {
+	return tagWriter{tt}
+}
+
+var _ sql.RowInserter = tagWriter{nil}
+var _ sql.RowDeleter = tagWriter{nil}
+
+type tagWriter struct {
+	tt *TagsTable
+}
+
+func tagNameFromRow(r sql.Row) (string, error) {
+	tagName, ok := r[0].(string)
+
+	if !ok {
+		return "", errors.New("invalid value type for name")
+	} else if !ref.IsValidTagName(tagName) {
+		return "", doltdb.ErrInvTagName
+	}
+
+	return tagName, nil
+}
+
+// optionalString returns the string value of a nullable column of |r|, or |def| when it is NULL.
+func optionalString(r sql.Row, i int, col, def string) (string, error) {
+	if r[i] == nil {
+		return def, nil
+	}
+
+	s, ok := r[i].(string)
+	if !ok {
+		return "", errors.New("invalid value type for " + col)
+	}
+
+	return s, nil
+}
+
+// Insert creates the tag described by the row given, returning an error if it cannot. The tag points to the commit
+// given by hash, which may be any commit spec, or to the current HEAD when it is NULL. The tagger and email
+// default to those of the session, and the date to the current time. Insert will be called once for each row to
+// process for the insert operation, which may involve many rows. After all rows in an operation have been processed,
+// Close is called.
+func (tWr tagWriter) Insert(ctx *sql.Context, r sql.Row) error {
+	tagName, err := tagNameFromRow(r)
+	if err != nil {
+		return err
+	}
+
+	dSess := dsess.DSessFromSess(ctx.Session)
+	startPoint, err := optionalString(r, 1, "hash", "head")
+	if err != nil {
+		return err
+	}
+
+	name, err := optionalString(r, 2, "tagger", dSess.Username())
+	if err != nil {
+		return err
+	}
+
+	email, err := optionalString(r, 3, "email", dSess.Email())
+	if err != nil {
+		return err
+	}
+
+	msg, err := optionalString(r, 5, "message", "")
+	if err != nil {
+		return err
+	}
+
+	date := ctx.QueryTime()
+	if r[4] != nil {
+		var ok bool
+		date, ok = r[4].(time.Time)
+		if !ok {
+			return errors.New("invalid value type for date")
+		}
+	}
+
+	headRef, err := dSess.CWBHeadRef(ctx, tWr.tt.dbName)
+	if err != nil {
+		return err
+	}
+
+	props := actions.TagProps{TaggerName: name, TaggerEmail: email, Description: msg, Date: date}
+	err = actions.CreateTagOnDB(ctx, tWr.tt.ddb, tagName, startPoint, props, headRef)
+	if err == actions.ErrAlreadyExists {
+		return sql.ErrPrimaryKeyViolation.New()
+	}
+
+	return err
+}
+
+// Delete deletes the given row. Returns ErrDeleteRowNotFound if the row was not found. Delete will be called once for
+// each row to process for the delete operation, which may involve many rows. After all rows have been processed,
+// Close is called.
+func (tWr tagWriter) Delete(ctx *sql.Context, r sql.Row) error {
+	tagName, err := tagNameFromRow(r)
+	if err != nil {
+		return err
+	}
+
+	err = actions.DeleteTagsOnDB(ctx, tWr.tt.ddb, tagName)
+	if err == doltdb.ErrTagNotFound {
+		return sql.ErrDeleteRowNotFound.New()
+	}
+
+	return err
+}
+
+// StatementBegin implements the interface sql.TableEditor. Currently a no-op.
+func (tWr tagWriter) StatementBegin(ctx *sql.Context) {}
+
+// DiscardChanges implements the interface sql.TableEditor. Currently a no-op.
+func (tWr tagWriter) DiscardChanges(ctx *sql.Context, errorEncountered error) error {
+	return nil
+}
+
+// StatementComplete implements the interface sql.TableEditor. Currently a no-op.
+func (tWr tagWriter) StatementComplete(ctx *sql.Context) error {
+	return nil
+}
+
+// Close finalizes the insert or delete operation, persisting the result.
+func (tWr tagWriter) Close(*sql.Context) error {
+	return nil
+}
@@ -86,5 +86,331 @@ var DoltScripts = []enginetest.ScriptTest{
 				},
 			},
 		},
+	}, {
+		Name: "dolt_cherry_pick applies a single commit from another branch",
+		SetUpScript: []string{
+			"create table t (pk int primary key, v int)",
+			"select DOLT_COMMIT('-a', '-m', 'created table')",
+			"select DOLT_CHECKOUT('-b', 'feature')",
+			"insert into t values (1, 1)",
+			"select DOLT_COMMIT('-a', '-m', 'inserted 1')",
+			"insert into t values (2, 2)",
+			"select DOLT_COMMIT('-a', '-m', 'inserted 2')",
+			"select DOLT_CHECKOUT('main')",
+		},
+		Assertions: []enginetest.ScriptTestAssertion{
+			{
+				Query:    "select DOLT_CHERRY_PICK('feature')",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				Query:    "select * from t order by pk",
+				Expected: []sql.Row{{2, 2}},
+			},
+			{
+				Query:    "select message from dolt_log order by date desc limit 1",
+				Expected: []sql.Row{{"inserted 2"}},
+			},
+		},
+	},
+	{
+		Name: "dolt_cherry_pick writes conflicts to the working set",
+		SetUpScript: []string{
+			"create table t (pk int primary key, v int)",
+			"insert into t values (1, 1)",
+			"select DOLT_COMMIT('-a', '-m', 'created table')",
+			"select DOLT_CHECKOUT('-b', 'feature')",
+			"update t set v = 2 where pk = 1",
+			"select DOLT_COMMIT('-a', '-m', 'updated on feature')",
+			"select DOLT_CHECKOUT('main')",
+			"update t set v = 3 where pk = 1",
+			"select DOLT_COMMIT('-a', '-m', 'updated on main')",
+			"set autocommit = 0",
+		},
+		Assertions: []enginetest.ScriptTestAssertion{
+			{
+				Query:    "select DOLT_CHERRY_PICK('feature')",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:    "select `table`, num_conflicts from dolt_conflicts",
+				Expected: []sql.Row{{"t", uint64(1)}},
+			},
+			{
+				Query:    "select base_v, our_v, their_v from dolt_conflicts_t",
+				Expected: []sql.Row{{1, 3, 2}},
+			},
+		},
+	},
+	{
+		Name: "dolt_cherry_pick requires a clean working set",
+		SetUpScript: []string{
+			"create table t (pk int primary key, v int)",
+			"select DOLT_COMMIT('-a', '-m', 'created table')",
+			"insert into t values (1, 1)",
+		},
+		Assertions: []enginetest.ScriptTestAssertion{
+			{
+				Query:          "select DOLT_CHERRY_PICK('HEAD')",
+				ExpectedErrStr: "you must commit any changes before using cherry-pick",
+			},
+		},
+	}, {
+		Name: "dolt_tags and DOLT_TAG create and delete tags",
+		SetUpScript: []string{
+			"create table t (pk int primary key, v int)",
+			"select DOLT_COMMIT('-a', '-m', 'created table')",
+			"insert into t values (1, 1)",
+			"select DOLT_COMMIT('-a', '-m', 'inserted a row')",
+		},
+		Assertions: []enginetest.ScriptTestAssertion{
+			{
+				Query:    "select DOLT_TAG('v1', 'HEAD~1')",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:    "select DOLT_TAG('-m', 'second release', 'v2')",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:    "insert into dolt_tags (name, hash, message) values ('v3', 'HEAD~1', 'from sql')",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "select name, message from dolt_tags order by name",
+				Expected: []sql.Row{{"v1", ""}, {"v2", "second release"}, {"v3", "from sql"}},
+			},
+			{
+				Query:    "select t.name from dolt_tags t join dolt_log l on t.hash = l.commit_hash where l.message = 'created table' order by t.name",
+				Expected: []sql.Row{{"v1"}, {"v3"}},
+			},
+			{
+				Query:       "insert into dolt_tags (name) values ('v1')",
+				ExpectedErr: sql.ErrPrimaryKeyViolation,
+			},
+			{
+				Query:          "select DOLT_TAG('v1')",
+				ExpectedErrStr: "fatal: tag 'v1' already exists",
+			},
+			{
+				Query:    "select DOLT_TAG('-d', 'v1')",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:    "delete from dolt_tags where name = 'v3'",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "select name from dolt_tags",
+				Expected: []sql.Row{{"v2"}},
+			},
+			{
+				Query:    "select count(*) from dolt_remote_branches",
+				Expected: []sql.Row{{0}},
+			},
+		},
+	},
+	{
+		Name: "dolt_blame_ attributes rows to the commit that last modified them",
+		SetUpScript: []string{
+			"create table t (a int, b int, v int, primary key (b, a))",
+			"insert into t values (1, 1, 1), (2, 2, 2), (3, 3, 3)",
+			"select DOLT_COMMIT('-a', '-m', 'created table', '--author', 'Alice <alice@example.com>')",
+			"update t set v = 20 where a = 2",
+			"insert into t values (4, 4, 4)",
+			"select DOLT_COMMIT('-a', '-m', 'updated a row', '--author', 'Bob <bob@example.com>')",
+			"update t set v = 40 where a = 4",
+			"select DOLT_COMMIT('-a', '-m', 'updated another row')",
+			"update t set v = 30 where a = 3",
+		},
+		Assertions: []enginetest.ScriptTestAssertion{
+			{
+				Query:    "select a, b, committer, email, message from dolt_blame_t order by a",
+				Expected: []sql.Row{{1, 1, "Alice", "alice@example.com", "created table"}, {2, 2, "Bob", "bob@example.com", "updated a row"}, {3, 3, "Alice", "alice@example.com", "created table"}, {4, 4, "billy bob", "bigbillieb@fake.horse", "updated another row"}},
+			},
+			{
+				Query:    "select count(*) from dolt_blame_t b join dolt_log l on b.commit_hash = l.commit_hash and b.commit_date = l.date",
+				Expected: []sql.Row{{4}},
+			},
+			{
+				Query:    "select a, message from dolt_blame_t as of 'HEAD~1' order by a",
+				Expected: []sql.Row{{1, "created table"}, {2, "updated a row"}, {3, "created table"}, {4, "updated a row"}},
+			},
+			{
+				Query:    "select a, message from dolt_blame_t as of 'HEAD~2' order by a",
+				Expected: []sql.Row{{1, "created table"}, {2, "created table"}, {3, "created table"}},
+			},
+			{
+				Query:          "select * from dolt_blame_nosuchtable",
+				ExpectedErrStr: "table not found: dolt_blame_nosuchtable",
+			},
+		},
+	},
+	{
+		Name: "dolt_blame_ attributes every row to a schema change",
+		SetUpScript: []string{
+			"create table t (pk int primary key, v int)",
+			"insert into t values (1, 1), (2, 2)",
+			"select DOLT_COMMIT('-a', '-m', 'created table')",
+			"alter table t add column w int",
+			"select DOLT_COMMIT('-a', '-m', 'added a column')",
+			"update t set w = 2 where pk = 2",
+			"select DOLT_COMMIT('-a', '-m', 'updated a row')",
+		},
+		Assertions: []enginetest.ScriptTestAssertion{
+			{
+				Query:    "select pk, message from dolt_blame_t order by pk",
+				Expected: []sql.Row{{1, "added a column"}, {2, "updated a row"}},
+			},
+		},
+	},
+	{
+		Name: "dolt_column_diff shows the columns changed by each commit",
+		SetUpScript: []string{
+			"create table t (pk int primary key, a int, b int)",
+			"insert into t values (1, 1, 1), (2, 2, 2)",
+			"select DOLT_COMMIT('-a', '-m', 'created t')",
+			"create table u (pk int primary key, v int)",
+			"update t set a = 10 where pk = 1",
+			"select DOLT_COMMIT('-a', '-m', 'created u and updated t')",
+			"alter table t add column c int",
+			"alter table t drop column b",
+			"select DOLT_COMMIT('-a', '-m', 'changed schema of t')",
+			"delete from t where pk = 2",
+			"select DOLT_COMMIT('-a', '-m', 'deleted a row')",
+			"drop table u",
+			"select DOLT_COMMIT('-a', '-m', 'dropped u')",
+		},
+		Assertions: []enginetest.ScriptTestAssertion{
+			{
+				Query: "select l.message, c.table_name, c.column_name, c.diff_type from dolt_column_diff c join dolt_log l on c.commit_hash = l.commit_hash order by l.message, c.table_name, c.column_name",
+				Expected: []sql.Row{
+					{"changed schema of t", "t", "b", "removed"},
+					{"changed schema of t", "t", "c", "added"},
+					{"created t", "t", "a", "added"},
+					{"created t", "t", "b", "added"},
+					{"created t", "t", "pk", "added"},
+					{"created u and updated t", "t", "a", "modified"},
+					{"created u and updated t", "u", "pk", "added"},
+					{"created u and updated t", "u", "v", "added"},
+					{"deleted a row", "t", "a", "modified"},
+					{"deleted a row", "t", "pk", "modified"},
+					{"dropped u", "u", "pk", "removed"},
+					{"dropped u", "u", "v", "removed"},
+				},
+			},
+			{
+				Query:    "select column_name, diff_type from dolt_column_diff where table_name = 'u' order by column_name, diff_type",
+				Expected: []sql.Row{{"pk", "added"}, {"pk", "removed"}, {"v", "added"}, {"v", "removed"}},
+			},
+			{
+				Query:    "select table_name, column_name, message from dolt_column_diff where commit_hash = hashof('HEAD~1') order by column_name",
+				Expected: []sql.Row{{"t", "a", "deleted a row"}, {"t", "pk", "deleted a row"}},
+			},
+			{
+				Query:    "select count(*) from dolt_column_diff where table_name = 't' and committer = 'billy bob' and diff_type = 'added'",
+				Expected: []sql.Row{{4}},
+			},
+		},
+	},
+	{
+		Name: "dolt_history_ and dolt_diff_ follow renamed and retyped columns",
+		SetUpScript: []string{
+			"create table t (pk int primary key, a int, b int)",
+			"insert into t values (1, 10, 100)",
+			"select DOLT_COMMIT('-a', '-m', 'created table')",
+			"alter table t rename column a to c",
+			"update t set c = 11 where pk = 1",
+			"select DOLT_COMMIT('-a', '-m', 'renamed a')",
+			"alter table t modify column b varchar(20)",
+			"insert into t values (2, 20, 'two')",
+			"select DOLT_COMMIT('-a', '-m', 'retyped b')",
+		},
+		Assertions: []enginetest.ScriptTestAssertion{
+			{
+				Query: "select h.pk, h.c, h.b, l.message from dolt_history_t h join dolt_log l on h.commit_hash = l.commit_hash order by h.pk, l.message",
+				Expected: []sql.Row{
+					{1, 10, "100", "created table"},
+					{1, 11, "100", "renamed a"},
+					{1, 11, "100", "retyped b"},
+					{2, 20, "two", "retyped b"},
+				},
+			},
+			{
+				Query:    "select c from dolt_history_t where pk = 1 order by c",
+				Expected: []sql.Row{{10}, {11}, {11}},
+			},
+			{
+				Query: "select l.message, d.diff_type, d.to_pk, d.to_c, d.to_b, d.from_pk, d.from_c, d.from_b from dolt_diff_t d join dolt_log l on d.to_commit = l.commit_hash order by l.message, d.diff_type",
+				Expected: []sql.Row{
+					{"created table", "added", 1, 10, "100", nil, nil, nil},
+					{"renamed a", "modified", 1, 11, "100", 1, 10, "100"},
+					{"renamed a", "schema_change", nil, nil, nil, nil, nil, nil},
+					{"retyped b", "added", 2, 20, "two", nil, nil, nil},
+					{"retyped b", "schema_change", nil, nil, nil, nil, nil, nil},
+				},
+			},
+			{
+				Query:    "select to_c, from_c from dolt_diff_t where to_pk = 1 and diff_type = 'modified'",
+				Expected: []sql.Row{{11, 10}},
+			},
+		},
+	},
+	{
+		Name: "AS OF a timestamp resolves to the latest commit made by then across merges",
+		SetUpScript: []string{
+			"create table t (pk int primary key, v int)",
+			"insert into t values (1, 1)",
+			"select DOLT_COMMIT('-a', '-m', 'created table', '--date', '2022-01-01T12:00:00Z')",
+			"select DOLT_CHECKOUT('-b', 'feature')",
+			"insert into t values (2, 2)",
+			"select DOLT_COMMIT('-a', '-m', 'inserted 2', '--date', '2022-01-01T12:30:00Z')",
+			"select DOLT_CHECKOUT('main')",
+			"insert into t values (3, 3)",
+			"select DOLT_COMMIT('-a', '-m', 'inserted 3', '--date', '2022-01-01T12:10:00Z')",
+			"insert into t values (4, 4)",
+			"select DOLT_COMMIT('-a', '-m', 'inserted 4', '--date', '2022-01-01T12:20:00Z')",
+			"select DOLT_MERGE('feature')",
+			"select DOLT_COMMIT('-a', '-m', 'merged feature', '--date', '2022-01-01T13:00:00Z')",
+			"create view history_view as select pk from dolt_history_t",
+		},
+		Assertions: []enginetest.ScriptTestAssertion{
+			{
+				Query:    "select pk from t as of convert('2022-01-01 12:05:00', datetime) order by pk",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				Query:    "select pk from t as of convert('2022-01-01 12:25:00', datetime) order by pk",
+				Expected: []sql.Row{{1}, {3}, {4}},
+			},
+			{
+				Query:    "select pk from t as of convert('2022-01-01 12:45:00', datetime) order by pk",
+				Expected: []sql.Row{{1}, {2}},
+			},
+			{
+				Query:    "select pk from t as of convert('2022-01-01 13:00:00', datetime) order by pk",
+				Expected: []sql.Row{{1}, {2}, {3}, {4}},
+			},
+			{
+				Query:    "select pk from dolt_history_t as of convert('2022-01-01 12:25:00', datetime) order by pk",
+				Expected: []sql.Row{{1}, {1}, {1}, {3}, {3}, {4}},
+			},
+			{
+				Query:    "select to_pk, diff_type from dolt_diff_t as of convert('2022-01-01 12:45:00', datetime) order by to_pk",
+				Expected: []sql.Row{{1, "added"}, {2, "added"}},
+			},
+			{
+				Query:    "select pk from history_view as of convert('2022-01-01 12:45:00', datetime) order by pk",
+				Expected: []sql.Row{{1}, {1}, {2}},
+			},
+			{
+				Query:    "select pk from history_view order by pk",
+				Expected: []sql.Row{{1}, {1}, {1}, {1}, {1}, {2}, {2}, {3}, {3}, {3}, {4}, {4}},
+			},
+			{
+				Query:       "select pk from dolt_history_t as of convert('2022-01-01 11:00:00', datetime)",
+				ExpectedErr: sql.ErrTableNotFound,
+			},
+		},
 	},
 }
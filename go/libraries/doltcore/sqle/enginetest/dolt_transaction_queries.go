@@ -791,4 +791,45 @@ var DoltTransactionTests = []enginetest.TransactionTest{
 			},
 		},
 	},
+	{
+		Name: "garbage collection keeps the changes of open transactions",
+		SetUpScript: []string{
+			"create table t (x int primary key, y int)",
+			"insert into t values (1, 1)",
+		},
+		Assertions: []enginetest.ScriptTestAssertion{
+			{
+				Query:    "/* client a */ start transaction",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client a */ insert into t values (2, 2), (3, 3)",
+				Expected: []sql.Row{{sql.NewOkResult(2)}},
+			},
+			{
+				Query:    "/* client a */ create table u (x int primary key)",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client b */ select dolt_gc() is not null",
+				Expected: []sql.Row{{true}},
+			},
+			{
+				Query:    "/* client a */ insert into u values (1)",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "/* client a */ commit",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "/* client b */ select * from t order by x",
+				Expected: []sql.Row{{1, 1}, {2, 2}, {3, 3}},
+			},
+			{
+				Query:    "/* client b */ select * from u",
+				Expected: []sql.Row{{1}},
+			},
+		},
+	},
 }
@@ -206,7 +206,7 @@ func TestJSONStructuralSharing(t *testing.T) {
 
 		err = db.Flush(ctx)
 		require.NoError(t, err)
-		err = db.(datas.GarbageCollector).GC(ctx, hash.HashSet{}, hash.HashSet{})
+		_, err = db.(datas.GarbageCollector).GC(ctx, hash.HashSet{}, hash.HashSet{})
 		require.NoError(t, err)
 		after := ts.Len()
 
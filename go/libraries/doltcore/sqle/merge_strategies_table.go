@@ -0,0 +1,138 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/merge"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
+)
+
+// The fixed SQL schema for the `dolt_merge_strategies` table.
+func MergeStrategiesTableSqlSchema() sql.PrimaryKeySchema {
+	sqlSchema, err := sqlutil.FromDoltSchema(doltdb.MergeStrategiesTableName, merge.MergeStrategiesTableSchema())
+	if err != nil {
+		panic(err) // should never happen
+	}
+	return sqlSchema
+}
+
+// GetOrCreateMergeStrategiesTable returns the `dolt_merge_strategies` table from the given db, creating it if it does
+// not already exist.
+func GetOrCreateMergeStrategiesTable(ctx *sql.Context, db Database) (*WritableDoltTable, error) {
+	root, err := db.GetRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tbl, found, err := db.getTable(ctx, root, doltdb.MergeStrategiesTableName, false)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return tbl.(*WritableDoltTable), nil
+	}
+
+	err = db.createDoltTable(ctx, doltdb.MergeStrategiesTableName, root, merge.MergeStrategiesTableSchema())
+	if err != nil {
+		return nil, err
+	}
+
+	root, err = db.GetRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tbl, found, err = db.getTable(ctx, root, doltdb.MergeStrategiesTableName, false)
+	if err != nil {
+		return nil, err
+	}
+	// Verify it was created successfully
+	if !found {
+		return nil, sql.ErrTableNotFound.New(doltdb.MergeStrategiesTableName)
+	}
+	return tbl.(*WritableDoltTable), nil
+}
+
+var _ sql.InsertableTable = (*emptyMergeStrategiesTable)(nil)
+var _ sql.ReplaceableTable = (*emptyMergeStrategiesTable)(nil)
+var _ sql.UpdatableTable = (*emptyMergeStrategiesTable)(nil)
+var _ sql.DeletableTable = (*emptyMergeStrategiesTable)(nil)
+
+// emptyMergeStrategiesTable stands in for the `dolt_merge_strategies` table in databases where it has not been created
+// yet, so that merge strategies can be configured without creating the table first. It has no rows, and creates the
+// table when it is written to.
+type emptyMergeStrategiesTable struct {
+	db Database
+}
+
+func newEmptyMergeStrategiesTable(db Database) sql.Table {
+	return &emptyMergeStrategiesTable{db: db}
+}
+
+// Name implements sql.Table
+func (mt *emptyMergeStrategiesTable) Name() string {
+	return doltdb.MergeStrategiesTableName
+}
+
+// String implements sql.Table
+func (mt *emptyMergeStrategiesTable) String() string {
+	return doltdb.MergeStrategiesTableName
+}
+
+// Schema implements sql.Table
+func (mt *emptyMergeStrategiesTable) Schema() sql.Schema {
+	return MergeStrategiesTableSqlSchema().Schema
+}
+
+// Partitions implements sql.Table
+func (mt *emptyMergeStrategiesTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return sql.PartitionsToPartitionIter(), nil
+}
+
+// PartitionRows implements sql.Table
+func (mt *emptyMergeStrategiesTable) PartitionRows(*sql.Context, sql.Partition) (sql.RowIter, error) {
+	return sql.RowsToRowIter(), nil
+}
+
+// Inserter implements sql.InsertableTable
+func (mt *emptyMergeStrategiesTable) Inserter(ctx *sql.Context) sql.RowInserter {
+	tbl, err := GetOrCreateMergeStrategiesTable(ctx, mt.db)
+	if err != nil {
+		return sqlutil.NewStaticErrorEditor(err)
+	}
+	return tbl.Inserter(ctx)
+}
+
+// Replacer implements sql.ReplaceableTable
+func (mt *emptyMergeStrategiesTable) Replacer(ctx *sql.Context) sql.RowReplacer {
+	tbl, err := GetOrCreateMergeStrategiesTable(ctx, mt.db)
+	if err != nil {
+		return sqlutil.NewStaticErrorEditor(err)
+	}
+	return tbl.Replacer(ctx)
+}
+
+// Updater implements sql.UpdatableTable. As the table has no rows, nothing is ever updated.
+func (mt *emptyMergeStrategiesTable) Updater(ctx *sql.Context) sql.RowUpdater {
+	return sqlutil.NewStaticErrorEditor(sql.ErrTableNotFound.New(doltdb.MergeStrategiesTableName))
+}
+
+// Deleter implements sql.DeletableTable. As the table has no rows, nothing is ever deleted.
+func (mt *emptyMergeStrategiesTable) Deleter(ctx *sql.Context) sql.RowDeleter {
+	return sqlutil.NewStaticErrorEditor(sql.ErrTableNotFound.New(doltdb.MergeStrategiesTableName))
+}
@@ -0,0 +1,210 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privileges
+
+import (
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/grant_tables"
+	"github.com/dolthub/vitess/go/mysql"
+	"gopkg.in/src-d/go-errors.v1"
+)
+
+// wildcard matches any sequence of characters in the patterns of a BranchRule.
+const wildcard = "%"
+
+var ErrBranchWriteDenied = errors.NewKind("user '%s' is not allowed to write to branch '%s' of database '%s'")
+var ErrBranchControlDenied = errors.NewKind("user '%s' is not allowed to change branch rules, which requires the SUPER privilege")
+var ErrBranchRuleExists = errors.NewKind("a branch rule for database '%s', branch '%s' and user '%s'@'%s' already exists")
+var ErrBranchRuleNotFound = errors.NewKind("no branch rule for database '%s', branch '%s' and user '%s'@'%s' exists")
+
+// BranchRule allows an account to write to the branches matching Branch of the databases matching Database. The
+// patterns may contain % wildcards, which match any sequence of characters. Host may be %, which matches any host of
+// the user.
+//
+// Once any branch rule exists, accounts without the SUPER privilege may only write to the branches they are allowed
+// to by a rule. Reading from branches is not restricted by branch rules.
+type BranchRule struct {
+	Database string `json:"database"`
+	Branch   string `json:"branch"`
+	User     string `json:"user"`
+	Host     string `json:"host"`
+}
+
+// matches returns whether the rule allows |user| to write to |branch| of |dbName|.
+func (rule BranchRule) matches(dbName, branch string, user *grant_tables.User) bool {
+	if rule.User != user.User || (rule.Host != wildcard && rule.Host != user.Host) {
+		return false
+	}
+
+	// database names are case-insensitive, branch names are not
+	return matchPattern(strings.ToLower(rule.Database), strings.ToLower(dbName)) && matchPattern(rule.Branch, branch)
+}
+
+// matchPattern returns whether |s| matches |pattern|, in which % matches any sequence of characters.
+func matchPattern(pattern, s string) bool {
+	parts := strings.Split(pattern, wildcard)
+	if len(parts) == 1 {
+		return pattern == s
+	}
+
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+
+	return len(s) >= len(last) && strings.HasSuffix(s, last)
+}
+
+// BranchRules returns the branch rules of the Store.
+func (s *Store) BranchRules() []BranchRule {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]BranchRule{}, s.branchRules...)
+}
+
+// AddBranchRule adds |rule| to the branch rules of the Store and persists it. Only accounts with the SUPER privilege
+// may change the branch rules.
+func (s *Store) AddBranchRule(ctx *sql.Context, rule BranchRule) error {
+	if err := s.checkBranchControl(ctx); err != nil {
+		return err
+	}
+
+	if len(rule.Host) == 0 {
+		rule.Host = wildcard
+	}
+
+	err := func() error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		for _, existing := range s.branchRules {
+			if existing == rule {
+				return ErrBranchRuleExists.New(rule.Database, rule.Branch, rule.User, rule.Host)
+			}
+		}
+
+		s.branchRules = append(s.branchRules, rule)
+		return nil
+	}()
+
+	if err != nil {
+		return err
+	}
+
+	return s.Persist(ctx)
+}
+
+// RemoveBranchRule removes |rule| from the branch rules of the Store and persists the change. Only accounts with the
+// SUPER privilege may change the branch rules.
+func (s *Store) RemoveBranchRule(ctx *sql.Context, rule BranchRule) error {
+	if err := s.checkBranchControl(ctx); err != nil {
+		return err
+	}
+
+	if len(rule.Host) == 0 {
+		rule.Host = wildcard
+	}
+
+	err := func() error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		for i, existing := range s.branchRules {
+			if existing == rule {
+				s.branchRules = append(s.branchRules[:i], s.branchRules[i+1:]...)
+				return nil
+			}
+		}
+
+		return ErrBranchRuleNotFound.New(rule.Database, rule.Branch, rule.User, rule.Host)
+	}()
+
+	if err != nil {
+		return err
+	}
+
+	return s.Persist(ctx)
+}
+
+// CheckBranchWrite returns an error if the client of the session of |ctx| is not allowed to write to |branch| of the
+// database named. Revision qualified database names are checked against the name of their database.
+func (s *Store) CheckBranchWrite(ctx *sql.Context, dbName, branch string) error {
+	if !s.grantTables.Enabled {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.branchRules) == 0 {
+		return nil
+	}
+
+	user, err := s.clientUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	if user.PrivilegeSet.Has(grant_tables.PrivilegeType_Super) {
+		return nil
+	}
+
+	dbName = strings.SplitN(dbName, "/", 2)[0]
+	for _, rule := range s.branchRules {
+		if rule.matches(dbName, branch, user) {
+			return nil
+		}
+	}
+
+	// hosts are left out of the message, as their wildcards are mangled when it is sent to clients
+	return ErrBranchWriteDenied.New(user.User, branch, dbName)
+}
+
+func (s *Store) checkBranchControl(ctx *sql.Context) error {
+	if !s.grantTables.Enabled {
+		return nil
+	}
+
+	user, err := s.clientUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !user.PrivilegeSet.Has(grant_tables.PrivilegeType_Super) {
+		return ErrBranchControlDenied.New(user.User)
+	}
+
+	return nil
+}
+
+func (s *Store) clientUser(ctx *sql.Context) (*grant_tables.User, error) {
+	client := ctx.Session.Client()
+	user := s.grantTables.GetUser(client.User, client.Address, false)
+	if user == nil {
+		return nil, mysql.NewSQLError(mysql.ERAccessDeniedError, mysql.SSAccessDeniedError, "Access denied for user '%v'", client.User)
+	}
+	return user, nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privileges
+
+import (
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/sql/grant_tables"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+)
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		s       string
+		matches bool
+	}{
+		{"main", "main", true},
+		{"main", "main2", false},
+		{"%", "anything", true},
+		{"%", "", true},
+		{"analysts/%", "analysts/q1", true},
+		{"analysts/%", "analysts/", true},
+		{"analysts/%", "analysts", false},
+		{"analysts/%", "other/analysts/q1", false},
+		{"%/release", "v1/release", true},
+		{"%/release", "v1/release2", false},
+		{"a%b%c", "abc", true},
+		{"a%b%c", "a-b-c", true},
+		{"a%b%c", "a-c-b", false},
+		{"ab%ba", "aba", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pattern+" "+test.s, func(t *testing.T) {
+			assert.Equal(t, test.matches, matchPattern(test.pattern, test.s))
+		})
+	}
+}
+
+func TestCheckBranchWrite(t *testing.T) {
+	rootCtx := newTestContext("root", "localhost")
+	analystCtx := newTestContext("analyst", "localhost")
+
+	fs := filesys.NewInMemFS([]string{"/data"}, nil, "/data")
+	store := NewStore(fs, testPrivilegeFile, "root")
+
+	// grant tables are disabled until accounts are added
+	require.NoError(t, store.CheckBranchWrite(analystCtx, "db", "main"))
+
+	store.GrantTables().AddSuperUser("root", "")
+	userData := store.GrantTables().UserTable().Data()
+	require.NoError(t, userData.Put(rootCtx, newTestUser("analyst", "%", grant_tables.PrivilegeType_Select)))
+
+	// without any rules, every branch can be written to
+	require.NoError(t, store.CheckBranchWrite(analystCtx, "db", "main"))
+
+	// only accounts with SUPER can change the rules
+	rule := BranchRule{Database: "db", Branch: "analysts/%", User: "analyst"}
+	assert.True(t, ErrBranchControlDenied.Is(store.AddBranchRule(analystCtx, rule)))
+	require.NoError(t, store.AddBranchRule(rootCtx, rule))
+	assert.True(t, ErrBranchRuleExists.Is(store.AddBranchRule(rootCtx, rule)))
+
+	assert.True(t, ErrBranchWriteDenied.Is(store.CheckBranchWrite(analystCtx, "db", "main")))
+	assert.True(t, ErrBranchWriteDenied.Is(store.CheckBranchWrite(analystCtx, "other_db", "analysts/q1")))
+	assert.NoError(t, store.CheckBranchWrite(analystCtx, "db", "analysts/q1"))
+	assert.NoError(t, store.CheckBranchWrite(analystCtx, "DB", "analysts/q1"))
+	assert.NoError(t, store.CheckBranchWrite(analystCtx, "db/analysts/q1", "analysts/q1"))
+
+	// accounts with SUPER can write to every branch
+	assert.NoError(t, store.CheckBranchWrite(rootCtx, "db", "main"))
+
+	require.NoError(t, store.RemoveBranchRule(rootCtx, rule))
+	assert.True(t, ErrBranchRuleNotFound.Is(store.RemoveBranchRule(rootCtx, rule)))
+	assert.NoError(t, store.CheckBranchWrite(analystCtx, "db", "main"))
+}
@@ -0,0 +1,71 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privileges
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/analyzer"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// PersistRuleName is the name of the analyzer rule returned by Store.PersistRule.
+const PersistRuleName = "persist_privileges"
+
+// PersistRule returns an analyzer rule which persists the accounts of the Store after each statement that changes
+// them, like CREATE USER and GRANT.
+func (s *Store) PersistRule() analyzer.RuleFunc {
+	return func(ctx *sql.Context, a *analyzer.Analyzer, n sql.Node, scope *analyzer.Scope) (sql.Node, error) {
+		switch n.(type) {
+		case *plan.CreateUser, *plan.DropUser, *plan.RenameUser, *plan.CreateRole, *plan.DropRole,
+			*plan.Grant, *plan.GrantRole, *plan.GrantProxy, *plan.Revoke, *plan.RevokeRole, *plan.RevokeAll, *plan.RevokeProxy:
+			return &persistingNode{Node: n, store: s}, nil
+		default:
+			return n, nil
+		}
+	}
+}
+
+// persistingNode persists the accounts of a Store once the statement it wraps has been executed.
+type persistingNode struct {
+	sql.Node
+	store *Store
+}
+
+var _ sql.Node = (*persistingNode)(nil)
+
+// RowIter implements sql.Node
+func (p *persistingNode) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	// statements on the grant tables are executed when their iterator is created
+	iter, err := p.Node.RowIter(ctx, row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = p.store.Persist(ctx); err != nil {
+		return nil, err
+	}
+
+	return iter, nil
+}
+
+// WithChildren implements sql.Node
+func (p *persistingNode) WithChildren(children ...sql.Node) (sql.Node, error) {
+	n, err := p.Node.WithChildren(children...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &persistingNode{Node: n, store: p.store}, nil
+}
@@ -0,0 +1,284 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privileges
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/grant_tables"
+
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+)
+
+// DefaultPrivilegeFilePath is the path of the privilege file relative to the data directory of a sql-server, used
+// when no path is configured.
+var DefaultPrivilegeFilePath = filepath.Join(".doltcfg", "privileges.json")
+
+// privilegeFileMode is the mode of the privilege file, which holds password hashes and so is only readable by its owner.
+const privilegeFileMode = 0600
+
+// privilegeNames are the names used for the global static privileges in the privilege file.
+var privilegeNames = map[grant_tables.PrivilegeType]string{
+	grant_tables.PrivilegeType_Select:            "SELECT",
+	grant_tables.PrivilegeType_Insert:            "INSERT",
+	grant_tables.PrivilegeType_Update:            "UPDATE",
+	grant_tables.PrivilegeType_Delete:            "DELETE",
+	grant_tables.PrivilegeType_Create:            "CREATE",
+	grant_tables.PrivilegeType_Drop:              "DROP",
+	grant_tables.PrivilegeType_Reload:            "RELOAD",
+	grant_tables.PrivilegeType_Shutdown:          "SHUTDOWN",
+	grant_tables.PrivilegeType_Process:           "PROCESS",
+	grant_tables.PrivilegeType_File:              "FILE",
+	grant_tables.PrivilegeType_Grant:             "GRANT OPTION",
+	grant_tables.PrivilegeType_References:        "REFERENCES",
+	grant_tables.PrivilegeType_Index:             "INDEX",
+	grant_tables.PrivilegeType_Alter:             "ALTER",
+	grant_tables.PrivilegeType_ShowDB:            "SHOW DATABASES",
+	grant_tables.PrivilegeType_Super:             "SUPER",
+	grant_tables.PrivilegeType_CreateTempTable:   "CREATE TEMPORARY TABLES",
+	grant_tables.PrivilegeType_LockTables:        "LOCK TABLES",
+	grant_tables.PrivilegeType_Execute:           "EXECUTE",
+	grant_tables.PrivilegeType_ReplicationSlave:  "REPLICATION SLAVE",
+	grant_tables.PrivilegeType_ReplicationClient: "REPLICATION CLIENT",
+	grant_tables.PrivilegeType_CreateView:        "CREATE VIEW",
+	grant_tables.PrivilegeType_ShowView:          "SHOW VIEW",
+	grant_tables.PrivilegeType_CreateRoutine:     "CREATE ROUTINE",
+	grant_tables.PrivilegeType_AlterRoutine:      "ALTER ROUTINE",
+	grant_tables.PrivilegeType_CreateUser:        "CREATE USER",
+	grant_tables.PrivilegeType_Event:             "EVENT",
+	grant_tables.PrivilegeType_Trigger:           "TRIGGER",
+	grant_tables.PrivilegeType_CreateTablespace:  "CREATE TABLESPACE",
+	grant_tables.PrivilegeType_CreateRole:        "CREATE ROLE",
+	grant_tables.PrivilegeType_DropRole:          "DROP ROLE",
+}
+
+// privilegeFile is the format of the privilege file.
+type privilegeFile struct {
+	Users       []userEntry  `json:"users"`
+	BranchRules []BranchRule `json:"branch_rules"`
+}
+
+// userEntry is an account of the grant tables, as stored in the privilege file.
+type userEntry struct {
+	User                string    `json:"user"`
+	Host                string    `json:"host"`
+	Privileges          []string  `json:"privileges"`
+	Plugin              string    `json:"plugin"`
+	Password            string    `json:"password"`
+	PasswordLastChanged time.Time `json:"password_last_changed"`
+	Locked              bool      `json:"locked"`
+	Attributes          *string   `json:"attributes,omitempty"`
+}
+
+// Store holds the accounts and branch rules of a sql-server, and persists them to a privilege file. Accounts are held
+// in the engine's grant tables, which are created by the Store so that they can be loaded before the engine is
+// started. Temporary accounts, like the one configured for the server, are never persisted.
+type Store struct {
+	fs          filesys.ReadWriteFS
+	path        string
+	grantTables *grant_tables.GrantTables
+	temporary   map[grant_tables.UserPrimaryKey]struct{}
+
+	mu          *sync.RWMutex
+	branchRules []BranchRule
+}
+
+// NewStore returns a Store which persists to the file at |path| of |fs|. |temporaryUsers| are the names of accounts
+// which must not be persisted.
+func NewStore(fs filesys.ReadWriteFS, path string, temporaryUsers ...string) *Store {
+	temporary := make(map[grant_tables.UserPrimaryKey]struct{}, len(temporaryUsers))
+	for _, user := range temporaryUsers {
+		// temporary accounts of the engine can connect from any host
+		temporary[grant_tables.UserPrimaryKey{Host: "%", User: user}] = struct{}{}
+	}
+
+	return &Store{
+		fs:          fs,
+		path:        path,
+		grantTables: grant_tables.CreateEmptyGrantTables(),
+		temporary:   temporary,
+		mu:          &sync.RWMutex{},
+	}
+}
+
+// GrantTables returns the grant tables the Store loads accounts into. The engine must use these grant tables for the
+// accounts to be persisted.
+func (s *Store) GrantTables() *grant_tables.GrantTables {
+	return s.grantTables
+}
+
+// Load reads the accounts and branch rules in the privilege file. It is not an error for the file to not exist.
+func (s *Store) Load(ctx *sql.Context) error {
+	if exists, _ := s.fs.Exists(s.path); !exists {
+		return nil
+	}
+
+	var pf privilegeFile
+	if err := filesys.UnmarshalJSONFile(s.fs, s.path, &pf); err != nil {
+		return fmt.Errorf("error reading privilege file %s: %w", s.path, err)
+	}
+
+	userData := s.grantTables.UserTable().Data()
+	for _, entry := range pf.Users {
+		key := grant_tables.UserPrimaryKey{Host: entry.Host, User: entry.User}
+		if _, ok := s.temporary[key]; ok {
+			continue
+		}
+
+		user, err := entry.toUser()
+		if err != nil {
+			return fmt.Errorf("error reading privilege file %s: %w", s.path, err)
+		}
+
+		if err = userData.Put(ctx, user); err != nil {
+			return err
+		}
+	}
+
+	if len(pf.Users) > 0 {
+		s.grantTables.Enabled = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.branchRules = pf.BranchRules
+
+	return nil
+}
+
+// Persist writes the accounts of the grant tables and the branch rules to the privilege file.
+func (s *Store) Persist(ctx *sql.Context) error {
+	iter := s.grantTables.UserTable().Data().ToRowIter(ctx)
+	defer iter.Close(ctx)
+
+	pf := privilegeFile{Users: []userEntry{}}
+	for {
+		row, err := iter.Next(ctx)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		entry, err := (&grant_tables.User{}).NewFromRow(ctx, row)
+		if err != nil {
+			return err
+		}
+
+		user := entry.(*grant_tables.User)
+		if _, ok := s.temporary[grant_tables.UserPrimaryKey{Host: user.Host, User: user.User}]; ok {
+			continue
+		}
+
+		pf.Users = append(pf.Users, userEntryFromUser(user))
+	}
+
+	s.mu.RLock()
+	pf.BranchRules = append([]BranchRule{}, s.branchRules...)
+	s.mu.RUnlock()
+
+	data, err := json.MarshalIndent(pf, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err = s.fs.MkDirs(filepath.Dir(s.path)); err != nil {
+		return err
+	}
+
+	// write to a temporary file first, so that a failed write never leaves a partial privilege file behind. A
+	// temporary file left behind by an earlier write is removed, as opening an existing file does not change its mode.
+	tmpPath := s.path + ".tmp"
+	if exists, _ := s.fs.Exists(tmpPath); exists {
+		if err = s.fs.DeleteFile(tmpPath); err != nil {
+			return err
+		}
+	}
+
+	wr, err := s.fs.OpenForWrite(tmpPath, privilegeFileMode)
+	if err != nil {
+		return err
+	}
+
+	_, err = wr.Write(data)
+	if err == nil {
+		err = wr.Close()
+	} else {
+		wr.Close()
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.fs.MoveFile(tmpPath, s.path)
+}
+
+func userEntryFromUser(user *grant_tables.User) userEntry {
+	names := make([]string, 0, user.PrivilegeSet.Len())
+	// iterate in declaration order so that the file is stable
+	for priv := grant_tables.PrivilegeType_Select; priv <= grant_tables.PrivilegeType_DropRole; priv++ {
+		if user.PrivilegeSet.Has(priv) {
+			names = append(names, privilegeNames[priv])
+		}
+	}
+
+	return userEntry{
+		User:                user.User,
+		Host:                user.Host,
+		Privileges:          names,
+		Plugin:              user.Plugin,
+		Password:            user.Password,
+		PasswordLastChanged: user.PasswordLastChanged,
+		Locked:              user.Locked,
+		Attributes:          user.Attributes,
+	}
+}
+
+func (entry userEntry) toUser() (*grant_tables.User, error) {
+	privSet := grant_tables.NewUserGlobalStaticPrivileges()
+	for _, name := range entry.Privileges {
+		priv, ok := privilegeForName(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown privilege '%s' for user '%s'@'%s'", name, entry.User, entry.Host)
+		}
+		privSet.Add(priv)
+	}
+
+	return &grant_tables.User{
+		User:                entry.User,
+		Host:                entry.Host,
+		PrivilegeSet:        privSet,
+		Plugin:              entry.Plugin,
+		Password:            entry.Password,
+		PasswordLastChanged: entry.PasswordLastChanged,
+		Locked:              entry.Locked,
+		Attributes:          entry.Attributes,
+	}, nil
+}
+
+func privilegeForName(name string) (grant_tables.PrivilegeType, bool) {
+	for priv, privName := range privilegeNames {
+		if privName == name {
+			return priv, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,125 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privileges
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/grant_tables"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+)
+
+const testPrivilegeFile = "/data/.doltcfg/privileges.json"
+
+func newTestUser(name, host string, privs ...grant_tables.PrivilegeType) *grant_tables.User {
+	privSet := grant_tables.NewUserGlobalStaticPrivileges()
+	for _, priv := range privs {
+		privSet.Add(priv)
+	}
+
+	return &grant_tables.User{
+		User:                name,
+		Host:                host,
+		PrivilegeSet:        privSet,
+		Plugin:              "mysql_native_password",
+		PasswordLastChanged: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func newTestContext(user, address string) *sql.Context {
+	sess := sql.NewBaseSessionWithClientServer("", sql.Client{User: user, Address: address}, 1)
+	return sql.NewContext(sql.NewEmptyContext(), sql.WithSession(sess))
+}
+
+func TestPersistAndLoad(t *testing.T) {
+	ctx := newTestContext("root", "localhost")
+	fs := filesys.NewInMemFS([]string{"/data"}, nil, "/data")
+
+	store := NewStore(fs, testPrivilegeFile, "root")
+	store.GrantTables().AddSuperUser("root", "")
+	userData := store.GrantTables().UserTable().Data()
+	require.NoError(t, userData.Put(ctx, newTestUser("analyst", "%", grant_tables.PrivilegeType_Select, grant_tables.PrivilegeType_Insert)))
+	require.NoError(t, store.AddBranchRule(ctx, BranchRule{Database: "db", Branch: "analysts/%", User: "analyst"}))
+
+	exists, isDir := fs.Exists(testPrivilegeFile)
+	require.True(t, exists)
+	require.False(t, isDir)
+
+	loaded := NewStore(fs, testPrivilegeFile, "root")
+	require.NoError(t, loaded.Load(ctx))
+	assert.True(t, loaded.GrantTables().Enabled)
+
+	// temporary users are never persisted
+	assert.Nil(t, loaded.GrantTables().GetUser("root", "localhost", false))
+
+	user := loaded.GrantTables().GetUser("analyst", "%", false)
+	require.NotNil(t, user)
+	assert.True(t, user.PrivilegeSet.Has(grant_tables.PrivilegeType_Select))
+	assert.True(t, user.PrivilegeSet.Has(grant_tables.PrivilegeType_Insert))
+	assert.False(t, user.PrivilegeSet.Has(grant_tables.PrivilegeType_Update))
+	assert.Equal(t, "mysql_native_password", user.Plugin)
+
+	assert.Equal(t, []BranchRule{{Database: "db", Branch: "analysts/%", User: "analyst", Host: "%"}}, loaded.BranchRules())
+}
+
+func TestPersistFileMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not enforced on windows")
+	}
+
+	ctx := newTestContext("root", "localhost")
+	dir := t.TempDir()
+	path := filepath.Join(dir, "privileges.json")
+
+	// a stale temporary file must not lend its mode to the privilege file
+	require.NoError(t, os.WriteFile(path+".tmp", []byte("{}"), 0644))
+
+	store := NewStore(filesys.LocalFS, path)
+	userData := store.GrantTables().UserTable().Data()
+	require.NoError(t, userData.Put(ctx, newTestUser("analyst", "%", grant_tables.PrivilegeType_Select)))
+	require.NoError(t, store.Persist(ctx))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	ctx := newTestContext("root", "localhost")
+	fs := filesys.NewInMemFS([]string{"/data"}, nil, "/data")
+
+	store := NewStore(fs, testPrivilegeFile)
+	require.NoError(t, store.Load(ctx))
+	assert.False(t, store.GrantTables().Enabled)
+	assert.Empty(t, store.BranchRules())
+}
+
+func TestLoadUnknownPrivilege(t *testing.T) {
+	ctx := newTestContext("root", "localhost")
+	fs := filesys.NewInMemFS([]string{"/data"}, map[string][]byte{
+		testPrivilegeFile: []byte(`{"users": [{"user": "analyst", "host": "%", "privileges": ["SELECT", "FLY"]}]}`),
+	}, "/data")
+
+	store := NewStore(fs, testPrivilegeFile)
+	assert.Error(t, store.Load(ctx))
+}
@@ -0,0 +1,355 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/diff"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlfmt"
+)
+
+// The kinds of schema objects that a SchemaChange applies to.
+const (
+	SchemaObjectTable      = "table"
+	SchemaObjectColumn     = "column"
+	SchemaObjectPrimaryKey = "primary key"
+	SchemaObjectIndex      = "index"
+	SchemaObjectForeignKey = "foreign key"
+	SchemaObjectCheck      = "check"
+)
+
+// primaryKeyName is the name of the primary key of a table, as used by MySQL.
+const primaryKeyName = "PRIMARY"
+
+// SchemaChange is a change to a single object of the schema of a table, such as a column or an index, along with the
+// SQL statements that migrate the object from its old definition to its new one.
+type SchemaChange struct {
+	// TableName is the name of the table in the to revision, or in the from revision if the table was dropped.
+	TableName string
+	// ObjectType is the kind of object that changed, one of the SchemaObject constants.
+	ObjectType string
+	// ObjectName is the name of the object that changed, in the to revision if it exists there.
+	ObjectName string
+	// DiffType is how the object changed: it was added, removed or modified.
+	DiffType diff.SchemaChangeType
+	// FromDefinition is the SQL definition of the object in the from revision, or empty if it was added. The definition
+	// of a table is its CREATE TABLE statement.
+	FromDefinition string
+	// ToDefinition is the SQL definition of the object in the to revision, or empty if it was removed.
+	ToDefinition string
+	// Statements are the statements that migrate the object from its definition in the from revision to its definition
+	// in the to revision.
+	Statements []string
+}
+
+// GetSchemaChanges returns the changes to the schema of the table in the TableDelta given, in the order in which their
+// statements should be applied.
+func GetSchemaChanges(ctx context.Context, td diff.TableDelta) ([]SchemaChange, error) {
+	fromSch, toSch, err := td.GetSchemas(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if td.IsDrop() {
+		stmt, err := createTableStmt(ctx, td.FromName, fromSch, td.FromFks, td.FromFksParentSch)
+		if err != nil {
+			return nil, err
+		}
+
+		return []SchemaChange{{
+			TableName:      td.FromName,
+			ObjectType:     SchemaObjectTable,
+			ObjectName:     td.FromName,
+			DiffType:       diff.SchDiffRemoved,
+			FromDefinition: stmt,
+			Statements:     []string{sqlfmt.DropTableStmt(td.FromName)},
+		}}, nil
+	} else if td.IsAdd() {
+		stmt, err := createTableStmt(ctx, td.ToName, toSch, td.ToFks, td.ToFksParentSch)
+		if err != nil {
+			return nil, err
+		}
+
+		return []SchemaChange{{
+			TableName:    td.ToName,
+			ObjectType:   SchemaObjectTable,
+			ObjectName:   td.ToName,
+			DiffType:     diff.SchDiffAdded,
+			ToDefinition: stmt,
+			Statements:   []string{stmt},
+		}}, nil
+	}
+
+	var changes []SchemaChange
+	if td.FromName != td.ToName {
+		fromStmt, err := createTableStmt(ctx, td.FromName, fromSch, td.FromFks, td.FromFksParentSch)
+		if err != nil {
+			return nil, err
+		}
+
+		toStmt, err := createTableStmt(ctx, td.ToName, toSch, td.ToFks, td.ToFksParentSch)
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, SchemaChange{
+			TableName:      td.ToName,
+			ObjectType:     SchemaObjectTable,
+			ObjectName:     td.ToName,
+			DiffType:       diff.SchDiffModified,
+			FromDefinition: fromStmt,
+			ToDefinition:   toStmt,
+			Statements:     []string{sqlfmt.RenameTableStmt(td.FromName, td.ToName)},
+		})
+	}
+
+	changes = append(changes, columnChanges(td.ToName, fromSch, toSch)...)
+	changes = append(changes, primaryKeyChanges(td.ToName, fromSch, toSch)...)
+	changes = append(changes, indexChanges(td.ToName, fromSch, toSch)...)
+	changes = append(changes, foreignKeyChanges(td, fromSch, toSch)...)
+	changes = append(changes, checkChanges(td.ToName, fromSch, toSch)...)
+
+	return changes, nil
+}
+
+func createTableStmt(ctx context.Context, tableName string, sch schema.Schema, fks []doltdb.ForeignKey, parentSchs map[string]schema.Schema) (string, error) {
+	sqlDb := NewSingleTableDatabase(tableName, sch, fks, parentSchs)
+	sqlCtx, engine, _ := PrepareCreateTableStmt(ctx, sqlDb)
+	return GetCreateTableStmt(sqlCtx, engine, tableName)
+}
+
+func columnChanges(tableName string, fromSch, toSch schema.Schema) []SchemaChange {
+	var changes []SchemaChange
+	pkSetChanged := !schema.ColCollsAreEqual(fromSch.GetPKCols(), toSch.GetPKCols())
+	colDiffs, unionTags := diff.DiffSchColumns(fromSch, toSch)
+	for _, tag := range unionTags {
+		cd := colDiffs[tag]
+		switch cd.DiffType {
+		case diff.SchDiffAdded:
+			def := sqlfmt.FmtCol(0, 0, 0, *cd.New)
+			changes = append(changes, SchemaChange{
+				TableName:    tableName,
+				ObjectType:   SchemaObjectColumn,
+				ObjectName:   cd.New.Name,
+				DiffType:     diff.SchDiffAdded,
+				ToDefinition: def,
+				Statements:   []string{sqlfmt.AlterTableAddColStmt(tableName, def)},
+			})
+		case diff.SchDiffRemoved:
+			changes = append(changes, SchemaChange{
+				TableName:      tableName,
+				ObjectType:     SchemaObjectColumn,
+				ObjectName:     cd.Old.Name,
+				DiffType:       diff.SchDiffRemoved,
+				FromDefinition: sqlfmt.FmtCol(0, 0, 0, *cd.Old),
+				Statements:     []string{sqlfmt.AlterTableDropColStmt(tableName, cd.Old.Name)},
+			})
+		case diff.SchDiffModified:
+			// Primary key set changes are migrated by the primary key change
+			if cd.Old.IsPartOfPK != cd.New.IsPartOfPK {
+				continue
+			}
+
+			fromDef, toDef := sqlfmt.FmtCol(0, 0, 0, *cd.Old), sqlfmt.FmtCol(0, 0, 0, *cd.New)
+			if fromDef == toDef {
+				continue
+			}
+
+			var stmts []string
+			if cd.Old.Name != cd.New.Name {
+				stmts = append(stmts, sqlfmt.AlterTableRenameColStmt(tableName, cd.Old.Name, cd.New.Name))
+			}
+
+			renamed := *cd.Old
+			renamed.Name = cd.New.Name
+			if pkSetChanged {
+				// changing the primary key set can change the constraints of other columns as a side effect
+				renamed.Constraints = cd.New.Constraints
+			}
+
+			if sqlfmt.FmtCol(0, 0, 0, renamed) != toDef {
+				stmts = append(stmts, sqlfmt.AlterTableModifyColStmt(tableName, toDef))
+			}
+
+			changes = append(changes, SchemaChange{
+				TableName:      tableName,
+				ObjectType:     SchemaObjectColumn,
+				ObjectName:     cd.New.Name,
+				DiffType:       diff.SchDiffModified,
+				FromDefinition: fromDef,
+				ToDefinition:   toDef,
+				Statements:     stmts,
+			})
+		}
+	}
+
+	return changes
+}
+
+func primaryKeyChanges(tableName string, fromSch, toSch schema.Schema) []SchemaChange {
+	fromPks, toPks := fromSch.GetPKCols(), toSch.GetPKCols()
+	if schema.ColCollsAreEqual(fromPks, toPks) {
+		return nil
+	}
+
+	change := SchemaChange{
+		TableName:  tableName,
+		ObjectType: SchemaObjectPrimaryKey,
+		ObjectName: primaryKeyName,
+		DiffType:   diff.SchDiffModified,
+		Statements: []string{sqlfmt.AlterTableDropPks(tableName)},
+	}
+
+	if fromPks.Size() > 0 {
+		change.FromDefinition = fmtPrimaryKey(fromPks)
+	} else {
+		change.DiffType = diff.SchDiffAdded
+	}
+
+	if toPks.Size() > 0 {
+		change.ToDefinition = fmtPrimaryKey(toPks)
+		change.Statements = append(change.Statements, sqlfmt.AlterTableAddPrimaryKeys(tableName, toPks))
+	} else {
+		change.DiffType = diff.SchDiffRemoved
+	}
+
+	return []SchemaChange{change}
+}
+
+func fmtPrimaryKey(pks *schema.ColCollection) string {
+	var cols []string
+	_ = pks.Iter(func(tag uint64, col schema.Column) (stop bool, err error) {
+		cols = append(cols, sqlfmt.QuoteIdentifier(col.Name))
+		return false, nil
+	})
+
+	return "PRIMARY KEY (" + strings.Join(cols, ",") + ")"
+}
+
+func indexChanges(tableName string, fromSch, toSch schema.Schema) []SchemaChange {
+	var changes []SchemaChange
+	for _, idxDiff := range diff.DiffSchIndexes(fromSch, toSch) {
+		change := SchemaChange{
+			TableName:  tableName,
+			ObjectType: SchemaObjectIndex,
+			DiffType:   idxDiff.DiffType,
+		}
+
+		switch idxDiff.DiffType {
+		case diff.SchDiffNone:
+			continue
+		case diff.SchDiffAdded:
+			change.ObjectName = idxDiff.To.Name()
+			change.ToDefinition = sqlfmt.FmtIndex(idxDiff.To)
+			change.Statements = []string{sqlfmt.AlterTableAddIndexStmt(tableName, idxDiff.To)}
+		case diff.SchDiffRemoved:
+			change.ObjectName = idxDiff.From.Name()
+			change.FromDefinition = sqlfmt.FmtIndex(idxDiff.From)
+			change.Statements = []string{sqlfmt.AlterTableDropIndexStmt(tableName, idxDiff.From)}
+		case diff.SchDiffModified:
+			change.ObjectName = idxDiff.To.Name()
+			change.FromDefinition = sqlfmt.FmtIndex(idxDiff.From)
+			change.ToDefinition = sqlfmt.FmtIndex(idxDiff.To)
+			change.Statements = []string{
+				sqlfmt.AlterTableDropIndexStmt(tableName, idxDiff.From),
+				sqlfmt.AlterTableAddIndexStmt(tableName, idxDiff.To),
+			}
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+func foreignKeyChanges(td diff.TableDelta, fromSch, toSch schema.Schema) []SchemaChange {
+	var changes []SchemaChange
+	for _, fkDiff := range diff.DiffForeignKeys(td.FromFks, td.ToFks) {
+		change := SchemaChange{
+			TableName:  td.ToName,
+			ObjectType: SchemaObjectForeignKey,
+			DiffType:   fkDiff.DiffType,
+		}
+
+		switch fkDiff.DiffType {
+		case diff.SchDiffNone:
+			continue
+		case diff.SchDiffAdded:
+			parentSch := td.ToFksParentSch[fkDiff.To.ReferencedTableName]
+			change.ObjectName = fkDiff.To.Name
+			change.ToDefinition = sqlfmt.FmtForeignKey(fkDiff.To, toSch, parentSch)
+			change.Statements = []string{sqlfmt.AlterTableAddForeignKeyStmt(fkDiff.To, toSch, parentSch)}
+		case diff.SchDiffRemoved:
+			parentSch := td.FromFksParentSch[fkDiff.From.ReferencedTableName]
+			change.ObjectName = fkDiff.From.Name
+			change.FromDefinition = sqlfmt.FmtForeignKey(fkDiff.From, fromSch, parentSch)
+			change.Statements = []string{sqlfmt.AlterTableDropForeignKeyStmt(fkDiff.From)}
+		case diff.SchDiffModified:
+			fromParentSch := td.FromFksParentSch[fkDiff.From.ReferencedTableName]
+			toParentSch := td.ToFksParentSch[fkDiff.To.ReferencedTableName]
+			change.ObjectName = fkDiff.To.Name
+			change.FromDefinition = sqlfmt.FmtForeignKey(fkDiff.From, fromSch, fromParentSch)
+			change.ToDefinition = sqlfmt.FmtForeignKey(fkDiff.To, toSch, toParentSch)
+			change.Statements = []string{
+				sqlfmt.AlterTableDropForeignKeyStmt(fkDiff.From),
+				sqlfmt.AlterTableAddForeignKeyStmt(fkDiff.To, toSch, toParentSch),
+			}
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+func checkChanges(tableName string, fromSch, toSch schema.Schema) []SchemaChange {
+	var changes []SchemaChange
+	for _, chkDiff := range diff.DiffChecks(fromSch, toSch) {
+		change := SchemaChange{
+			TableName:  tableName,
+			ObjectType: SchemaObjectCheck,
+			DiffType:   chkDiff.DiffType,
+		}
+
+		switch chkDiff.DiffType {
+		case diff.SchDiffNone:
+			continue
+		case diff.SchDiffAdded:
+			change.ObjectName = chkDiff.To.Name()
+			change.ToDefinition = sqlfmt.FmtCheck(chkDiff.To)
+			change.Statements = []string{sqlfmt.AlterTableAddCheckStmt(tableName, chkDiff.To)}
+		case diff.SchDiffRemoved:
+			change.ObjectName = chkDiff.From.Name()
+			change.FromDefinition = sqlfmt.FmtCheck(chkDiff.From)
+			change.Statements = []string{sqlfmt.AlterTableDropCheckStmt(tableName, chkDiff.From)}
+		case diff.SchDiffModified:
+			change.ObjectName = chkDiff.To.Name()
+			change.FromDefinition = sqlfmt.FmtCheck(chkDiff.From)
+			change.ToDefinition = sqlfmt.FmtCheck(chkDiff.To)
+			change.Statements = []string{
+				sqlfmt.AlterTableDropCheckStmt(tableName, chkDiff.From),
+				sqlfmt.AlterTableAddCheckStmt(tableName, chkDiff.To),
+			}
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes
+}
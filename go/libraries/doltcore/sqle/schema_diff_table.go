@@ -0,0 +1,249 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/diff"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dtables"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
+)
+
+var ErrSchemaDiffExactlyOneToCommit = errors.New("dolt_schema_diff table must be filtered to a single 'to_commit'")
+var ErrSchemaDiffExactlyOneFromCommit = errors.New("dolt_schema_diff table must be filtered to a single 'from_commit'")
+
+const (
+	schemaDiffFromCommitCol = "from_commit"
+	schemaDiffToCommitCol   = "to_commit"
+)
+
+var _ sql.Table = (*SchemaDiffTable)(nil)
+var _ sql.FilteredTable = (*SchemaDiffTable)(nil)
+
+// SchemaDiffTable is a sql.Table implementation of a system table which shows the changes to the schemas of all tables
+// between two revisions, one row per changed column, primary key, index, foreign key or check constraint, or per
+// added, dropped or renamed table. Each row includes the definitions of the changed object in both revisions and the
+// statements that migrate it from one to the other. Like dolt_commit_diff_ tables, the table must be filtered on a
+// single from_commit and to_commit, each of which may be any commit spec, or WORKING or STAGED.
+type SchemaDiffTable struct {
+	ddb               *doltdb.DoltDB
+	workingRoot       *doltdb.RootValue
+	stagedRoot        *doltdb.RootValue
+	head              *doltdb.Commit
+	fromCommitFilter  *expression.Equals
+	toCommitFilter    *expression.Equals
+	requiredFilterErr error
+}
+
+// NewSchemaDiffTable creates a SchemaDiffTable. Commit specs relative to HEAD are resolved relative to |head|.
+func NewSchemaDiffTable(_ *sql.Context, ddb *doltdb.DoltDB, working, staged *doltdb.RootValue, head *doltdb.Commit) sql.Table {
+	return &SchemaDiffTable{ddb: ddb, workingRoot: working, stagedRoot: staged, head: head}
+}
+
+// Name is a sql.Table interface function which returns the name of the table which is defined by the constant
+// SchemaDiffTableName
+func (st *SchemaDiffTable) Name() string {
+	return doltdb.SchemaDiffTableName
+}
+
+// String is a sql.Table interface function which returns the name of the table which is defined by the constant
+// SchemaDiffTableName
+func (st *SchemaDiffTable) String() string {
+	return doltdb.SchemaDiffTableName
+}
+
+// Schema is a sql.Table interface function that gets the sql.Schema of the schema diff system table
+func (st *SchemaDiffTable) Schema() sql.Schema {
+	return []*sql.Column{
+		{Name: schemaDiffFromCommitCol, Type: sql.Text, Source: doltdb.SchemaDiffTableName, PrimaryKey: false, Nullable: false},
+		{Name: schemaDiffToCommitCol, Type: sql.Text, Source: doltdb.SchemaDiffTableName, PrimaryKey: false, Nullable: false},
+		{Name: "table_name", Type: sql.Text, Source: doltdb.SchemaDiffTableName, PrimaryKey: true, Nullable: false},
+		{Name: "object_type", Type: sql.Text, Source: doltdb.SchemaDiffTableName, PrimaryKey: true, Nullable: false},
+		{Name: "object_name", Type: sql.Text, Source: doltdb.SchemaDiffTableName, PrimaryKey: true, Nullable: false},
+		{Name: "diff_type", Type: sql.Text, Source: doltdb.SchemaDiffTableName, PrimaryKey: false, Nullable: false},
+		{Name: "from_definition", Type: sql.LongText, Source: doltdb.SchemaDiffTableName, PrimaryKey: false, Nullable: true},
+		{Name: "to_definition", Type: sql.LongText, Source: doltdb.SchemaDiffTableName, PrimaryKey: false, Nullable: true},
+		{Name: "statement", Type: sql.LongText, Source: doltdb.SchemaDiffTableName, PrimaryKey: false, Nullable: false},
+	}
+}
+
+// HandledFilters returns the list of filters that will be handled by the table itself
+func (st *SchemaDiffTable) HandledFilters(filters []sql.Expression) []sql.Expression {
+	var commitFilters []sql.Expression
+	for _, filter := range filters {
+		isCommitFilter := false
+
+		if eqFilter, isEquality := filter.(*expression.Equals); isEquality {
+			for _, e := range []sql.Expression{eqFilter.Left(), eqFilter.Right()} {
+				if val, ok := e.(*expression.GetField); ok {
+					switch strings.ToLower(val.Name()) {
+					case schemaDiffToCommitCol:
+						if st.toCommitFilter != nil {
+							st.requiredFilterErr = ErrSchemaDiffExactlyOneToCommit
+						}
+
+						isCommitFilter = true
+						st.toCommitFilter = eqFilter
+					case schemaDiffFromCommitCol:
+						if st.fromCommitFilter != nil {
+							st.requiredFilterErr = ErrSchemaDiffExactlyOneFromCommit
+						}
+
+						isCommitFilter = true
+						st.fromCommitFilter = eqFilter
+					}
+				}
+			}
+		}
+
+		if isCommitFilter {
+			commitFilters = append(commitFilters, filter)
+		}
+	}
+
+	return commitFilters
+}
+
+// Filters returns the list of filters that are applied to this table.
+func (st *SchemaDiffTable) Filters() []sql.Expression {
+	if st.toCommitFilter == nil || st.fromCommitFilter == nil {
+		return nil
+	}
+
+	return []sql.Expression{st.toCommitFilter, st.fromCommitFilter}
+}
+
+// WithFilters returns a new sql.Table instance with the filters applied
+func (st *SchemaDiffTable) WithFilters(ctx *sql.Context, filters []sql.Expression) sql.Table {
+	return st
+}
+
+// Partitions is a sql.Table interface function that returns a partition of the data.  Currently the data is unpartitioned.
+func (st *SchemaDiffTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	if st.requiredFilterErr != nil {
+		return nil, fmt.Errorf("error querying table %s: %w", st.Name(), st.requiredFilterErr)
+	} else if st.toCommitFilter == nil {
+		return nil, fmt.Errorf("error querying table %s: %w", st.Name(), ErrSchemaDiffExactlyOneToCommit)
+	} else if st.fromCommitFilter == nil {
+		return nil, fmt.Errorf("error querying table %s: %w", st.Name(), ErrSchemaDiffExactlyOneFromCommit)
+	}
+
+	return index.SinglePartitionIterFromNomsMap(nil), nil
+}
+
+// PartitionRows is a sql.Table interface function that gets a row iterator for a partition
+func (st *SchemaDiffTable) PartitionRows(ctx *sql.Context, _ sql.Partition) (sql.RowIter, error) {
+	fromRevision, err := revisionForFilter(ctx, st.fromCommitFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	toRevision, err := revisionForFilter(ctx, st.toCommitFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	fromRoot, _, err := dtables.RootForRevision(ctx, st.ddb, fromRevision, st.workingRoot, st.stagedRoot, st.head)
+	if err != nil {
+		return nil, err
+	}
+
+	toRoot, _, err := dtables.RootForRevision(ctx, st.ddb, toRevision, st.workingRoot, st.stagedRoot, st.head)
+	if err != nil {
+		return nil, err
+	}
+
+	deltas, err := diff.GetTableDeltas(ctx, fromRoot, toRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(deltas, func(i, j int) bool {
+		return deltas[i].CurName() < deltas[j].CurName()
+	})
+
+	var rows []sql.Row
+	for _, td := range deltas {
+		if td.CurName() == doltdb.DocTableName {
+			continue
+		}
+
+		changes, err := GetSchemaChanges(ctx, td)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, change := range changes {
+			rows = append(rows, sql.NewRow(
+				fromRevision,
+				toRevision,
+				change.TableName,
+				change.ObjectType,
+				change.ObjectName,
+				schemaDiffTypeName(change.DiffType),
+				stringOrNil(change.FromDefinition),
+				stringOrNil(change.ToDefinition),
+				strings.Join(change.Statements, "\n"),
+			))
+		}
+	}
+
+	return sql.RowsToRowIter(rows...), nil
+}
+
+func revisionForFilter(ctx *sql.Context, eqFilter *expression.Equals) (string, error) {
+	gf, nonGF := eqFilter.Left(), eqFilter.Right()
+	if _, ok := gf.(*expression.GetField); !ok {
+		nonGF = eqFilter.Left()
+	}
+
+	val, err := nonGF.Eval(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+
+	revision, ok := val.(string)
+	if !ok {
+		return "", fmt.Errorf("received '%v' when expecting a commit spec string", val)
+	}
+
+	return revision, nil
+}
+
+func schemaDiffTypeName(diffType diff.SchemaChangeType) string {
+	switch diffType {
+	case diff.SchDiffAdded:
+		return "added"
+	case diff.SchDiffRemoved:
+		return "removed"
+	default:
+		return "modified"
+	}
+}
+
+func stringOrNil(s string) interface{} {
+	if len(s) == 0 {
+		return nil
+	}
+	return s
+}
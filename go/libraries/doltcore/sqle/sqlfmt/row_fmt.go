@@ -33,9 +33,9 @@ import (
 
 const singleQuote = `'`
 
-// Quotes the identifier given with backticks.
+// Quotes the identifier given with backticks, and escapes any contained within the identifier by doubling them.
 func QuoteIdentifier(s string) string {
-	return "`" + s + "`"
+	return "`" + strings.ReplaceAll(s, "`", "``") + "`"
 }
 
 // QuoteComment quotes the given string with apostrophes, and escapes any contained within the string.
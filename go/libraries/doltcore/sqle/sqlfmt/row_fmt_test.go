@@ -51,6 +51,12 @@ type updateTest struct {
 	collDiff       *set.StrSet
 }
 
+func TestQuoteIdentifier(t *testing.T) {
+	assert.Equal(t, "`table_name`", QuoteIdentifier("table_name"))
+	assert.Equal(t, "`table``name`", QuoteIdentifier("table`name"))
+	assert.Equal(t, "`t`` SET c = 1; DROP TABLE t; --`", QuoteIdentifier("t` SET c = 1; DROP TABLE t; --"))
+}
+
 func TestTableDropStmt(t *testing.T) {
 	stmt := DropTableStmt("table_name")
 
@@ -148,6 +148,19 @@ func FmtForeignKey(fk doltdb.ForeignKey, sch, parentSch schema.Schema) string {
 	return sb.String()
 }
 
+func FmtCheck(check schema.Check) string {
+	sb := strings.Builder{}
+	sb.WriteString("CONSTRAINT ")
+	sb.WriteString(QuoteIdentifier(check.Name()))
+	sb.WriteString(" CHECK (")
+	sb.WriteString(check.Expression())
+	sb.WriteRune(')')
+	if !check.Enforced() {
+		sb.WriteString(" NOT ENFORCED")
+	}
+	return sb.String()
+}
+
 func DropTableStmt(tableName string) string {
 	var b strings.Builder
 	b.WriteString("DROP TABLE ")
@@ -301,3 +314,23 @@ func AlterTableDropForeignKeyStmt(fk doltdb.ForeignKey) string {
 	b.WriteRune(';')
 	return b.String()
 }
+
+func AlterTableAddCheckStmt(tableName string, check schema.Check) string {
+	var b strings.Builder
+	b.WriteString("ALTER TABLE ")
+	b.WriteString(QuoteIdentifier(tableName))
+	b.WriteString(" ADD ")
+	b.WriteString(FmtCheck(check))
+	b.WriteRune(';')
+	return b.String()
+}
+
+func AlterTableDropCheckStmt(tableName string, check schema.Check) string {
+	var b strings.Builder
+	b.WriteString("ALTER TABLE ")
+	b.WriteString(QuoteIdentifier(tableName))
+	b.WriteString(" DROP CONSTRAINT ")
+	b.WriteString(QuoteIdentifier(check.Name()))
+	b.WriteRune(';')
+	return b.String()
+}
@@ -425,6 +425,20 @@ func (t *WritableDoltTable) getTableEditor(ctx *sql.Context) (ed writer.TableWri
 	}
 	ait := t.db.gs.GetAutoIncrementTracker(ws.Ref())
 
+	// writes are checked again when the transaction is committed, but failing here spares clients from having to roll
+	// back a transaction they can never commit
+	if !t.temporary {
+		headRef, err := ws.Ref().ToHeadRef()
+		if err != nil {
+			return nil, err
+		}
+
+		err = ds.CheckBranchWrite(ctx, t.db.name, headRef.GetPath())
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	state, _, err := ds.LookupDbState(ctx, t.db.name)
 	if err != nil {
 		return nil, err
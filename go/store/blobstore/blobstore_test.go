@@ -81,11 +81,17 @@ func appendLocalTest(tests []BlobstoreTest) []BlobstoreTest {
 	return append(tests, BlobstoreTest{"local", NewLocalBlobstore(dir), 10, 20})
 }
 
+func appendS3Test(tests []BlobstoreTest) []BlobstoreTest {
+	srv := newFakeS3Server()
+	return append(tests, BlobstoreTest{"s3", NewS3Blobstore(newFakeS3Client(srv), "bucket", uuid.New().String()+"/"), 10, 20})
+}
+
 func newBlobStoreTests() []BlobstoreTest {
 	var tests []BlobstoreTest
 	tests = append(tests, BlobstoreTest{"inmem", NewInMemoryBlobstore(), 10, 20})
 	tests = appendLocalTest(tests)
 	tests = appendGCSTest(tests)
+	tests = appendS3Test(tests)
 
 	return tests
 }
@@ -0,0 +1,192 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+const (
+	ifMatchHeader     = "If-Match"
+	ifNoneMatchHeader = "If-None-Match"
+)
+
+// S3Blobstore provides a Blobstore implementation over any S3 compatible API, such as AWS S3 or MinIO. The version of
+// a blob is its ETag, and CheckAndPut is implemented with conditional writes, so the object store must support the
+// If-Match and If-None-Match headers on PutObject requests.
+type S3Blobstore struct {
+	s3         s3iface.S3API
+	bucketName string
+	prefix     string
+}
+
+// NewS3Blobstore creates a new instance of a S3Blobstore storing blobs under |prefix| in the bucket |bucketName|
+func NewS3Blobstore(s3svc s3iface.S3API, bucketName, prefix string) *S3Blobstore {
+	for len(prefix) > 0 && prefix[0] == '/' {
+		prefix = prefix[1:]
+	}
+
+	return &S3Blobstore{s3svc, bucketName, prefix}
+}
+
+func (bs *S3Blobstore) absKey(key string) string {
+	return path.Join(bs.prefix, key)
+}
+
+func (bs *S3Blobstore) url(absKey string) string {
+	return "s3://" + path.Join(bs.bucketName, absKey)
+}
+
+// Exists returns true if a blob exists for the given key, and false if it does not.
+func (bs *S3Blobstore) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := bs.s3.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bs.bucketName),
+		Key:    aws.String(bs.absKey(key)),
+	})
+
+	if isS3StatusCode(err, http.StatusNotFound) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// Get retrieves an io.reader for the portion of a blob specified by br along with
+// its version
+func (bs *S3Blobstore) Get(ctx context.Context, key string, br BlobRange) (io.ReadCloser, string, error) {
+	absKey := bs.absKey(key)
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(bs.bucketName),
+		Key:    aws.String(absKey),
+	}
+
+	if br.offset < 0 && br.length != 0 {
+		// a range which ends before the end of the blob is resolved against the size of the blob, which must not
+		// change before it is read
+		head, err := bs.s3.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+			Bucket: aws.String(bs.bucketName),
+			Key:    aws.String(absKey),
+		})
+
+		if isS3StatusCode(err, http.StatusNotFound) {
+			return nil, "", NotFound{bs.url(absKey)}
+		} else if err != nil {
+			return nil, "", err
+		}
+
+		br = br.positiveRange(aws.Int64Value(head.ContentLength))
+		input.IfMatch = head.ETag
+	}
+
+	if !br.isAllRange() {
+		input.Range = aws.String(httpRange(br))
+	}
+
+	out, err := bs.s3.GetObjectWithContext(ctx, input)
+
+	if isS3StatusCode(err, http.StatusNotFound) {
+		return nil, "", NotFound{bs.url(absKey)}
+	} else if err != nil {
+		return nil, "", err
+	}
+
+	return out.Body, aws.StringValue(out.ETag), nil
+}
+
+// httpRange returns the value of the Range header reading |br|
+func httpRange(br BlobRange) string {
+	if br.offset < 0 {
+		return fmt.Sprintf("bytes=%d", br.offset)
+	} else if br.length == 0 {
+		return fmt.Sprintf("bytes=%d-", br.offset)
+	}
+
+	return fmt.Sprintf("bytes=%d-%d", br.offset, br.offset+br.length-1)
+}
+
+// Put sets the blob and the version for a key
+func (bs *S3Blobstore) Put(ctx context.Context, key string, reader io.Reader) (string, error) {
+	return bs.put(ctx, key, reader, "", "")
+}
+
+// CheckAndPut will check the current version of a blob against an expectedVersion, and if the
+// versions match it will update the data and version associated with the key
+func (bs *S3Blobstore) CheckAndPut(ctx context.Context, expectedVersion, key string, reader io.Reader) (string, error) {
+	var ver string
+	var err error
+	if expectedVersion != "" {
+		ver, err = bs.put(ctx, key, reader, ifMatchHeader, expectedVersion)
+	} else {
+		ver, err = bs.put(ctx, key, reader, ifNoneMatchHeader, "*")
+	}
+
+	// a conflicting concurrent conditional write fails with 409 Conflict rather than 412 Precondition Failed
+	if isS3StatusCode(err, http.StatusPreconditionFailed) || isS3StatusCode(err, http.StatusConflict) {
+		return "", CheckAndPutError{key, expectedVersion, "unknown (Not supported in S3 implementation)"}
+	}
+
+	return ver, err
+}
+
+// put writes the blob for |key|, with the condition header |condHeader| set to |condValue| when it is not empty. The
+// SDK version in use does not model conditional writes, so the header is added to the request directly.
+func (bs *S3Blobstore) put(ctx context.Context, key string, reader io.Reader, condHeader, condValue string) (string, error) {
+	body, ok := reader.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return "", err
+		}
+
+		body = bytes.NewReader(data)
+	}
+
+	req, out := bs.s3.PutObjectRequest(&s3.PutObjectInput{
+		Bucket: aws.String(bs.bucketName),
+		Key:    aws.String(bs.absKey(key)),
+		Body:   body,
+	})
+	req.SetContext(ctx)
+
+	if condHeader != "" {
+		req.HTTPRequest.Header.Set(condHeader, condValue)
+	}
+
+	err := req.Send()
+
+	if err != nil {
+		return "", err
+	}
+
+	return aws.StringValue(out.ETag), nil
+}
+
+func isS3StatusCode(err error, code int) bool {
+	if reqErr, ok := err.(awserr.RequestFailure); ok {
+		return reqErr.StatusCode() == code
+	}
+
+	return false
+}
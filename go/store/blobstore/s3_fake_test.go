@@ -0,0 +1,170 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blobstore
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// fakeS3Server is an S3 compatible object store serving path style HEAD, GET and PUT object requests, which supports
+// ranged reads and conditional writes.
+type fakeS3Server struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func newFakeS3Server() *httptest.Server {
+	return httptest.NewServer(&fakeS3Server{objects: map[string][]byte{}})
+}
+
+// newFakeS3Client returns a client of the fake S3 server |srv|
+func newFakeS3Client(srv *httptest.Server) *s3.S3 {
+	sess := session.Must(session.NewSession(aws.NewConfig().
+		WithEndpoint(srv.URL).
+		WithRegion("us-east-1").
+		WithS3ForcePathStyle(true).
+		WithCredentials(credentials.NewStaticCredentials("id", "secret", ""))))
+	return s3.New(sess)
+}
+
+func etagOf(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func (s *fakeS3Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := r.URL.Path
+	data, exists := s.objects[key]
+
+	switch r.Method {
+	case http.MethodHead, http.MethodGet:
+		if !exists {
+			writeS3Error(w, r, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+
+		if ifMatch := r.Header.Get(ifMatchHeader); ifMatch != "" && ifMatch != etagOf(data) {
+			writeS3Error(w, r, http.StatusPreconditionFailed, "PreconditionFailed")
+			return
+		}
+
+		status := http.StatusOK
+		body := data
+		if rng := r.Header.Get("Range"); rng != "" {
+			start, end, ok := parseRangeHeader(rng, int64(len(data)))
+			if !ok {
+				writeS3Error(w, r, http.StatusRequestedRangeNotSatisfiable, "InvalidRange")
+				return
+			}
+
+			body = data[start:end]
+			status = http.StatusPartialContent
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end-1, len(data)))
+		}
+
+		w.Header().Set("ETag", etagOf(data))
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(status)
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(body)
+		}
+
+	case http.MethodPut:
+		if ifMatch := r.Header.Get(ifMatchHeader); ifMatch != "" && (!exists || ifMatch != etagOf(data)) {
+			writeS3Error(w, r, http.StatusPreconditionFailed, "PreconditionFailed")
+			return
+		}
+
+		if r.Header.Get(ifNoneMatchHeader) == "*" && exists {
+			writeS3Error(w, r, http.StatusPreconditionFailed, "PreconditionFailed")
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeS3Error(w, r, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+
+		s.objects[key] = body
+		w.Header().Set("ETag", etagOf(body))
+		w.WriteHeader(http.StatusOK)
+
+	default:
+		writeS3Error(w, r, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+// parseRangeHeader returns the start and end of the range of a blob of |size| bytes read by the Range header |rng|
+func parseRangeHeader(rng string, size int64) (int64, int64, bool) {
+	spec := strings.TrimPrefix(rng, "bytes=")
+	parts := strings.SplitN(spec, "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+
+	if parts[0] == "" {
+		n, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return 0, 0, false
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, size, true
+	}
+
+	start, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || start >= size {
+		return 0, 0, false
+	}
+
+	end := size
+	if parts[1] != "" {
+		last, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return 0, 0, false
+		}
+		if last+1 < end {
+			end = last + 1
+		}
+	}
+
+	return start, end, true
+}
+
+func writeS3Error(w http.ResponseWriter, r *http.Request, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		_, _ = fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, code)
+	}
+}
@@ -109,6 +109,23 @@ type ChunkStoreGarbageCollector interface {
 	// chunks sent on |keepChunks| and will have removed all other content
 	// from the ChunkStore.
 	MarkAndSweepChunks(ctx context.Context, last hash.Hash, keepChunks <-chan []hash.Hash, dest ChunkStore) error
+
+	// BeginGC starts tracking the chunks which are written to the chunk
+	// store, or which Has and HasMany report present, so that a collection
+	// which runs while the store is being written to can keep them.
+	// |keeper| is called with the hash of each such chunk. Once |keeper|
+	// returns true, the collection has reached its safepoint, and writers
+	// block until EndGC is called.
+	BeginGC(keeper func(hash.Hash) bool) error
+
+	// EndGC stops tracking chunks for a collection started by BeginGC, and
+	// releases any writers blocked at its safepoint.
+	EndGC()
+
+	// HasManyUntracked is like HasMany, but the chunks it finds are not
+	// tracked for a collection in progress, so it never blocks at the
+	// safepoint of the collection. It is for use by the collection itself.
+	HasManyUntracked(ctx context.Context, hashes hash.HashSet) (absent hash.HashSet, err error)
 }
 
 // GenerationalCS is an interface supporting the getting old gen and new gen chunk stores
@@ -117,6 +134,18 @@ type GenerationalCS interface {
 	OldGen() ChunkStoreGarbageCollector
 }
 
+// ChunkFetcher reads chunks from a store which holds chunks another ChunkStore does not, such as the remote a partial
+// clone of a database was cloned from.
+type ChunkFetcher interface {
+	// GetMany gets the Chunks with |hashes| from the store, calling |found| with each which is found.
+	GetMany(ctx context.Context, hashes hash.HashSet, found func(context.Context, *Chunk)) error
+
+	// HasMany returns a new HashSet containing any members of |hashes| that are absent from the store.
+	HasMany(ctx context.Context, hashes hash.HashSet) (absent hash.HashSet, err error)
+}
+
 var ErrUnsupportedOperation = errors.New("operation not supported")
 
 var ErrGCGenerationExpired = errors.New("garbage collection generation expired")
+
+var ErrGCInProgress = errors.New("a garbage collection is already in progress")
@@ -125,6 +125,9 @@ type MemoryStoreView struct {
 	version  string
 
 	storage *MemoryStorage
+
+	keeperFunc func(hash.Hash) bool
+	gcCond     *sync.Cond
 }
 
 var _ ChunkStore = &MemoryStoreView{}
@@ -156,12 +159,29 @@ func (ms *MemoryStoreView) GetMany(ctx context.Context, hashes hash.HashSet, fou
 }
 
 func (ms *MemoryStoreView) Has(ctx context.Context, h hash.Hash) (bool, error) {
-	ms.mu.RLock()
-	defer ms.mu.RUnlock()
-	if _, ok := ms.pending[h]; ok {
-		return true, nil
+	has, keeper, err := func() (bool, func(hash.Hash) bool, error) {
+		ms.mu.RLock()
+		defer ms.mu.RUnlock()
+		if _, ok := ms.pending[h]; ok {
+			return true, ms.keeperFunc, nil
+		}
+		has, err := ms.storage.Has(ctx, h)
+		return has, ms.keeperFunc, err
+	}()
+
+	if err != nil {
+		return false, err
 	}
-	return ms.storage.Has(ctx, h)
+
+	if has && keeper != nil && keeper(h) {
+		// the collection reached its safepoint, and may not keep |h|
+		ms.mu.Lock()
+		ms.waitForGC()
+		ms.mu.Unlock()
+		return ms.Has(ctx, h)
+	}
+
+	return has, nil
 }
 
 func (ms *MemoryStoreView) HasMany(ctx context.Context, hashes hash.HashSet) (hash.HashSet, error) {
@@ -177,6 +197,26 @@ func (ms *MemoryStoreView) HasMany(ctx context.Context, hashes hash.HashSet) (ha
 	return absent, nil
 }
 
+func (ms *MemoryStoreView) HasManyUntracked(ctx context.Context, hashes hash.HashSet) (hash.HashSet, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	absent := hash.HashSet{}
+	for h := range hashes {
+		if _, ok := ms.pending[h]; ok {
+			continue
+		}
+
+		exists, err := ms.storage.Has(ctx, h)
+		if err != nil {
+			return nil, err
+		} else if !exists {
+			absent.Insert(h)
+		}
+	}
+	return absent, nil
+}
+
 func (ms *MemoryStoreView) Version() string {
 	return ms.version
 }
@@ -184,6 +224,9 @@ func (ms *MemoryStoreView) Version() string {
 func (ms *MemoryStoreView) Put(ctx context.Context, c Chunk) error {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
+	if ms.keeperFunc != nil && ms.keeperFunc(c.Hash()) {
+		ms.waitForGC()
+	}
 	if ms.pending == nil {
 		ms.pending = map[hash.Hash]Chunk{}
 	}
@@ -232,7 +275,7 @@ func (ms *MemoryStoreView) MarkAndSweepChunks(ctx context.Context, last hash.Has
 		panic("unsupported")
 	}
 
-	if last != ms.rootHash {
+	if ms.keeperFunc == nil && last != ms.rootHash {
 		return fmt.Errorf("last does not match ms.Root()")
 	}
 
@@ -257,11 +300,37 @@ LOOP:
 		}
 	}
 
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
 	ms.storage = &MemoryStorage{rootHash: ms.rootHash, data: keepers}
-	ms.pending = map[hash.Hash]Chunk{}
 	return nil
 }
 
+func (ms *MemoryStoreView) BeginGC(keeper func(hash.Hash) bool) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.keeperFunc != nil {
+		return ErrGCInProgress
+	}
+	ms.gcCond = sync.NewCond(&ms.mu)
+	ms.keeperFunc = keeper
+	return nil
+}
+
+func (ms *MemoryStoreView) EndGC() {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.keeperFunc = nil
+	ms.gcCond.Broadcast()
+}
+
+// callers must acquire lock |ms.mu|
+func (ms *MemoryStoreView) waitForGC() {
+	for ms.keeperFunc != nil {
+		ms.gcCond.Wait()
+	}
+}
+
 func (ms *MemoryStoreView) Stats() interface{} {
 	return nil
 }
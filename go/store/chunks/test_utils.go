@@ -95,6 +95,30 @@ func (s *TestStoreView) MarkAndSweepChunks(ctx context.Context, last hash.Hash,
 	return collector.MarkAndSweepChunks(ctx, last, keepChunks, collector)
 }
 
+func (s *TestStoreView) BeginGC(keeper func(hash.Hash) bool) error {
+	collector, ok := s.ChunkStore.(ChunkStoreGarbageCollector)
+	if !ok {
+		return ErrUnsupportedOperation
+	}
+
+	return collector.BeginGC(keeper)
+}
+
+func (s *TestStoreView) HasManyUntracked(ctx context.Context, hashes hash.HashSet) (hash.HashSet, error) {
+	collector, ok := s.ChunkStore.(ChunkStoreGarbageCollector)
+	if !ok {
+		return nil, ErrUnsupportedOperation
+	}
+
+	return collector.HasManyUntracked(ctx, hashes)
+}
+
+func (s *TestStoreView) EndGC() {
+	if collector, ok := s.ChunkStore.(ChunkStoreGarbageCollector); ok {
+		collector.EndGC()
+	}
+}
+
 func (s *TestStoreView) Reads() int {
 	reads := atomic.LoadInt32(&s.reads)
 	return int(reads)
@@ -161,9 +161,15 @@ func FindCommonAncestorUsingParentsList(ctx context.Context, c1, c2 types.Ref, v
 // are dereference through |vr2|.
 //
 // This implementation makes use of the parents_closure field on the commit
-// struct.  If the commit does not have a materialized parents_closure, this
-// implementation delegates to FindCommonAncestorUsingParentsList.
+// struct.  If the commit does not have a materialized parents_closure, or
+// either database is shallow, this implementation delegates to
+// FindCommonAncestorUsingParentsList.
 func FindCommonAncestor(ctx context.Context, c1, c2 types.Ref, vr1, vr2 types.ValueReader) (types.Ref, bool, error) {
+	// the parents closures of a shallow database reference the commits grafted away by its shallow commits
+	if len(ShallowCommits(vr1)) > 0 || len(ShallowCommits(vr2)) > 0 {
+		return FindCommonAncestorUsingParentsList(ctx, c1, c2, vr1, vr2)
+	}
+
 	pi1, err := newParentsClosureIterator(ctx, c1, vr1)
 	if err != nil {
 		return types.Ref{}, false, err
@@ -244,7 +250,9 @@ func FindClosureCommonAncestor(ctx context.Context, cl RefClosure, cm types.Ref,
 	return types.Ref{}, false, nil
 }
 
+// parentsToQueue pushes the parents of |refs| onto |q|. The shallow commits of |vr| are treated as having no parents.
 func parentsToQueue(ctx context.Context, refs types.RefSlice, q *RefByHeightHeap, vr types.ValueReader) error {
+	shallow := ShallowCommits(vr)
 	seen := make(map[hash.Hash]bool)
 	for _, r := range refs {
 		if _, ok := seen[r.TargetHash()]; ok {
@@ -252,6 +260,10 @@ func parentsToQueue(ctx context.Context, refs types.RefSlice, q *RefByHeightHeap
 		}
 		seen[r.TargetHash()] = true
 
+		if shallow.Has(r.TargetHash()) {
+			continue
+		}
+
 		v, err := r.TargetValue(ctx, vr)
 		if err != nil {
 			return err
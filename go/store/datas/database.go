@@ -198,8 +198,13 @@ type GarbageCollector interface {
 	types.ValueReadWriter
 
 	// GC traverses the database starting at the Root and removes
-	// all unreferenced data from persistent storage.
-	GC(ctx context.Context, oldGenRefs, newGenRefs hash.HashSet) error
+	// all unreferenced data from persistent storage. Nothing may
+	// write to the database while it runs.
+	GC(ctx context.Context, oldGenRefs, newGenRefs hash.HashSet) (types.GCStats, error)
+
+	// OnlineGC is like GC, but may run while the database is being
+	// written to.
+	OnlineGC(ctx context.Context, oldGenRefs, newGenRefs hash.HashSet) (types.GCStats, error)
 }
 
 // CanUsePuller returns true if a datas.Puller can be used to pull data from one Database into another.  Not all
@@ -39,6 +39,8 @@ type database struct {
 	*types.ValueStore
 	rt              rootTracker
 	postCommitHooks []CommitHook
+	shallow         *shallowGraft
+	partial         *partialSource
 }
 
 var (
@@ -70,6 +72,8 @@ func newDatabase(cs chunks.ChunkStore) *database {
 	return &database{
 		ValueStore: vs, // ValueStore is responsible for closing |cs|
 		rt:         vs,
+		shallow:    &shallowGraft{},
+		partial:    &partialSource{},
 	}
 }
 
@@ -135,7 +139,12 @@ func (db *database) DatasetsInRoot(ctx context.Context, rootHash hash.Hash) (typ
 		return types.EmptyMap, err
 	}
 
-	return val.(types.Map), nil
+	m, ok := val.(types.Map)
+	if !ok {
+		return types.EmptyMap, fmt.Errorf("root %s of the database is not a map of datasets", rootHash.String())
+	}
+
+	return m, nil
 }
 
 func getParentsClosure(ctx context.Context, vrw types.ValueReadWriter, parentRefsL types.List) (types.Ref, bool, error) {
@@ -1013,10 +1022,15 @@ func (db *database) doDelete(ctx context.Context, datasetIDstr string) error {
 }
 
 // GC traverses the database starting at the Root and removes all unreferenced data from persistent storage.
-func (db *database) GC(ctx context.Context, oldGenRefs, newGenRefs hash.HashSet) error {
+func (db *database) GC(ctx context.Context, oldGenRefs, newGenRefs hash.HashSet) (types.GCStats, error) {
 	return db.ValueStore.GC(ctx, oldGenRefs, newGenRefs)
 }
 
+// OnlineGC is like GC, but may run while the database is being written to.
+func (db *database) OnlineGC(ctx context.Context, oldGenRefs, newGenRefs hash.HashSet) (types.GCStats, error) {
+	return db.ValueStore.OnlineGC(ctx, oldGenRefs, newGenRefs)
+}
+
 func (db *database) tryCommitChunks(ctx context.Context, currentDatasets types.Map, currentRootHash hash.Hash) error {
 	newRoot, err := db.WriteValue(ctx, currentDatasets)
 
@@ -23,16 +23,21 @@ package datas
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/dolthub/dolt/go/store/chunks"
 	"github.com/dolthub/dolt/go/store/d"
 	"github.com/dolthub/dolt/go/store/hash"
 	"github.com/dolthub/dolt/go/store/merge"
+	"github.com/dolthub/dolt/go/store/nbs"
 	"github.com/dolthub/dolt/go/store/types"
+	"github.com/dolthub/dolt/go/store/util/clienttest"
 )
 
 func TestLocalDatabase(t *testing.T) {
@@ -703,3 +708,34 @@ func (suite *DatabaseSuite) TestMetaOption() {
 	c := mustHead(ds)
 	suite.Equal(types.String("arv"), mustGetValue(mustGetValue(c.MaybeGet("meta")).(types.Struct).MaybeGet("author")))
 }
+
+func TestGCRemovesUnreferencedTableFiles(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	st, err := nbs.NewLocalStore(ctx, types.Format_Default.VersionString(), dir, clienttest.DefaultMemTableSize)
+	require.NoError(t, err)
+	db := NewDatabase(st)
+	defer db.Close()
+
+	ds, err := db.GetDataset(ctx, "ds")
+	require.NoError(t, err)
+	_, err = db.CommitValue(ctx, ds, types.String("committed"))
+	require.NoError(t, err)
+
+	// a table file which the manifest does not reference, such as one left behind by a process which crashed
+	unreferenced := filepath.Join(dir, hash.Of([]byte("unreferenced")).String())
+	require.NoError(t, os.WriteFile(unreferenced, []byte("unreferenced"), 0644))
+
+	_, err = db.(GarbageCollector).GC(ctx, hash.HashSet{}, hash.HashSet{})
+	require.NoError(t, err)
+
+	_, err = os.Stat(unreferenced)
+	assert.True(t, os.IsNotExist(err))
+
+	ds, err = db.GetDataset(ctx, "ds")
+	require.NoError(t, err)
+	v, ok, err := ds.MaybeHeadValue()
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, types.String("committed"), v)
+}
@@ -16,8 +16,10 @@ package datas
 
 import (
 	"context"
+	"time"
 
 	"github.com/dolthub/dolt/go/store/chunks"
+	"github.com/dolthub/dolt/go/store/hash"
 	"github.com/dolthub/dolt/go/store/nbs"
 )
 
@@ -30,3 +32,25 @@ func PruneTableFiles(ctx context.Context, db Database) error {
 
 	return tfs.PruneTableFiles(ctx)
 }
+
+// ReflogRoot is a value referenced by an entry in a reflog, along with the time of the entry.
+type ReflogRoot struct {
+	Hash      hash.Hash
+	Timestamp time.Time
+}
+
+// ReflogRoots returns the hashes of the reflog roots given that are younger than |maxAge| as of |now|. Garbage
+// collection treats these as roots alongside the heads of datasets, so that recent ref updates can be undone even
+// after the values they replaced are no longer referenced by any dataset.
+func ReflogRoots(roots []ReflogRoot, maxAge time.Duration, now time.Time) hash.HashSet {
+	keep := hash.NewHashSet()
+	cutoff := now.Add(-maxAge)
+	for _, r := range roots {
+		if r.Hash.IsEmpty() || r.Timestamp.Before(cutoff) {
+			continue
+		}
+		keep.Insert(r.Hash)
+	}
+
+	return keep
+}
@@ -0,0 +1,112 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datas
+
+import (
+	"context"
+	"sync"
+
+	"github.com/dolthub/dolt/go/store/chunks"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+// A partial database holds only some of the chunks reachable from its datasets, such as a database cloned with the
+// row data of only some of its tables. The chunks it does not hold are read from its partial source, typically the
+// database it was cloned from, when they are needed. Values referencing them may be committed, and garbage collection
+// does not walk them.
+
+// partialSource is the ChunkFetcher a partial database reads the chunks it does not hold from.
+type partialSource struct {
+	mu      sync.RWMutex
+	fetcher chunks.ChunkFetcher
+}
+
+// chunkFetcherSetter is a ChunkStore which can read the chunks it does not hold from a ChunkFetcher.
+type chunkFetcherSetter interface {
+	SetChunkFetcher(fetcher chunks.ChunkFetcher)
+}
+
+// SetPartialSource makes |db| a partial database, which reads the chunks it does not hold from |fetcher|. A nil
+// |fetcher| makes |db| read only the chunks it holds again.
+func SetPartialSource(db Database, fetcher chunks.ChunkFetcher) error {
+	sdb, ok := db.(*database)
+	if !ok {
+		return chunks.ErrUnsupportedOperation
+	}
+
+	setter, ok := sdb.chunkStore().(chunkFetcherSetter)
+	if !ok {
+		return chunks.ErrUnsupportedOperation
+	}
+
+	func() {
+		sdb.partial.mu.Lock()
+		defer sdb.partial.mu.Unlock()
+		sdb.partial.fetcher = fetcher
+	}()
+
+	setter.SetChunkFetcher(fetcher)
+	sdb.updateAbsentRefsFilter()
+	return nil
+}
+
+// IsPartial returns whether |vr| is a partial database.
+func IsPartial(vr types.ValueReader) bool {
+	db, ok := vr.(*database)
+	if !ok {
+		return false
+	}
+
+	db.partial.mu.RLock()
+	defer db.partial.mu.RUnlock()
+	return db.partial.fetcher != nil
+}
+
+// DanglingRefs returns the chunks of |absent|, which |db| does not hold, which are dangling: those which are neither
+// grafted away by the shallow commits of |db| nor held by its partial source.
+func DanglingRefs(ctx context.Context, db Database, absent hash.HashSet) (hash.HashSet, error) {
+	sdb, ok := db.(*database)
+	if !ok {
+		return absent, nil
+	}
+
+	return sdb.filterAbsentRefs(ctx, absent)
+}
+
+// filterAbsentRefs is the absent refs filter of the ValueStore of a shallow or partial database.
+func (db *database) filterAbsentRefs(ctx context.Context, absent hash.HashSet) (hash.HashSet, error) {
+	dangling := db.filterGraftedRefs(absent)
+
+	db.partial.mu.RLock()
+	fetcher := db.partial.fetcher
+	db.partial.mu.RUnlock()
+
+	if fetcher == nil || len(dangling) == 0 {
+		return dangling, nil
+	}
+
+	return fetcher.HasMany(ctx, dangling)
+}
+
+// updateAbsentRefsFilter sets the absent refs filter of the ValueStore of |db| if it is shallow or partial, and clears
+// it otherwise.
+func (db *database) updateAbsentRefsFilter() {
+	if len(ShallowCommits(db)) > 0 || IsPartial(db) {
+		db.ValueStore.SetAbsentRefsFilter(db.filterAbsentRefs)
+	} else {
+		db.ValueStore.SetAbsentRefsFilter(nil)
+	}
+}
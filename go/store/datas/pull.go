@@ -260,10 +260,10 @@ func filterAppendicesFromSourceFiles(appendixFiles []nbs.TableFile, sourceFiles
 
 // Pull objects that descend from sourceRef from srcDB to sinkDB.
 func Pull(ctx context.Context, srcDB, sinkDB Database, sourceRef types.Ref, progressCh chan PullProgress) error {
-	return pull(ctx, srcDB, sinkDB, sourceRef.TargetHash(), progressCh, defaultBatchSize)
+	return pull(ctx, srcDB, sinkDB, sourceRef.TargetHash(), nil, progressCh, defaultBatchSize)
 }
 
-func pull(ctx context.Context, srcDB, sinkDB Database, sourceHash hash.Hash, progressCh chan PullProgress, batchSize int) error {
+func pull(ctx context.Context, srcDB, sinkDB Database, sourceHash hash.Hash, excluded hash.HashSet, progressCh chan PullProgress, batchSize int) error {
 	// Sanity Check
 	exists, err := srcDB.chunkStore().Has(ctx, sourceHash)
 
@@ -314,7 +314,7 @@ func pull(ctx context.Context, srcDB, sinkDB Database, sourceHash hash.Hash, pro
 				return err
 			}
 
-			uniqueOrdered, err = putChunks(ctx, sinkDB, batch, neededChunks, nextLevel, uniqueOrdered)
+			uniqueOrdered, err = putChunks(ctx, sinkDB, batch, neededChunks, excluded, nextLevel, uniqueOrdered)
 
 			if err != nil {
 				return err
@@ -363,7 +363,13 @@ func persistChunks(ctx context.Context, cs chunks.ChunkStore) error {
 // optimization problem down to the chunk store which can make smarter decisions.
 func PullWithoutBatching(ctx context.Context, srcDB, sinkDB Database, sourceRef types.Ref, progressCh chan PullProgress) error {
 	// by increasing the batch size to MaxInt32 we effectively remove batching here.
-	return pull(ctx, srcDB, sinkDB, sourceRef.TargetHash(), progressCh, math.MaxInt32)
+	return pull(ctx, srcDB, sinkDB, sourceRef.TargetHash(), nil, progressCh, math.MaxInt32)
+}
+
+// PullShallowWithoutBatching is PullWithoutBatching, but does not follow refs to the chunks |excluded|, so that only
+// part of the history of |sourceRef| is pulled. See ExcludedFromShallowPull.
+func PullShallowWithoutBatching(ctx context.Context, srcDB, sinkDB Database, sourceRef types.Ref, excluded hash.HashSet, progressCh chan PullProgress) error {
+	return pull(ctx, srcDB, sinkDB, sourceRef.TargetHash(), excluded, progressCh, math.MaxInt32)
 }
 
 // concurrently pull all chunks from this batch that the sink is missing out of the source
@@ -390,7 +396,7 @@ func getChunks(ctx context.Context, srcDB Database, batch hash.HashSlice, sample
 
 // put the chunks that were downloaded into the sink IN ORDER and at the same time gather up an ordered, uniquified list
 // of all the children of the chunks and add them to the list of the next level tree chunks.
-func putChunks(ctx context.Context, sinkDB Database, hashes hash.HashSlice, neededChunks map[hash.Hash]*chunks.Chunk, nextLevel hash.HashSet, uniqueOrdered hash.HashSlice) (hash.HashSlice, error) {
+func putChunks(ctx context.Context, sinkDB Database, hashes hash.HashSlice, neededChunks map[hash.Hash]*chunks.Chunk, excluded, nextLevel hash.HashSet, uniqueOrdered hash.HashSlice) (hash.HashSlice, error) {
 	for _, h := range hashes {
 		c := neededChunks[h]
 		err := sinkDB.chunkStore().Put(ctx, *c)
@@ -400,7 +406,7 @@ func putChunks(ctx context.Context, sinkDB Database, hashes hash.HashSlice, need
 		}
 
 		err = types.WalkRefs(*c, sinkDB.Format(), func(r types.Ref) error {
-			if !nextLevel.Has(r.TargetHash()) {
+			if !nextLevel.Has(r.TargetHash()) && !excluded.Has(r.TargetHash()) {
 				uniqueOrdered = append(uniqueOrdered, r.TargetHash())
 				nextLevel.Insert(r.TargetHash())
 			}
@@ -68,6 +68,7 @@ type Puller struct {
 	sinkDBCS      chunks.ChunkStore
 	rootChunkHash hash.Hash
 	downloaded    hash.HashSet
+	excluded      hash.HashSet
 
 	wr            *nbs.CmpChunkTableWriter
 	tablefileSema *semaphore.Weighted
@@ -122,6 +123,12 @@ func NewTFPullerEvent(et PullerEventType, details *TableFileEventDetails) Puller
 // NewPuller creates a new Puller instance to do the syncing.  If a nil puller is returned without error that means
 // that there is nothing to pull and the sinkDB is already up to date.
 func NewPuller(ctx context.Context, tempDir string, chunksPerTF int, srcDB, sinkDB Database, rootChunkHash hash.Hash, eventCh chan PullerEvent) (*Puller, error) {
+	return NewShallowPuller(ctx, tempDir, chunksPerTF, srcDB, sinkDB, rootChunkHash, nil, eventCh)
+}
+
+// NewShallowPuller creates a new Puller instance which does not follow refs to the chunks |excluded|, so that only part
+// of the history of |rootChunkHash| is pulled. See ExcludedFromShallowPull.
+func NewShallowPuller(ctx context.Context, tempDir string, chunksPerTF int, srcDB, sinkDB Database, rootChunkHash hash.Hash, excluded hash.HashSet, eventCh chan PullerEvent) (*Puller, error) {
 	// Sanity Check
 	exists, err := srcDB.chunkStore().Has(ctx, rootChunkHash)
 
@@ -177,6 +184,7 @@ func NewPuller(ctx context.Context, tempDir string, chunksPerTF int, srcDB, sink
 		sinkDBCS:      sinkDBCS,
 		rootChunkHash: rootChunkHash,
 		downloaded:    hash.HashSet{},
+		excluded:      excluded,
 		tablefileSema: semaphore.NewWeighted(outstandingTableFiles),
 		tempDir:       tempDir,
 		wr:            wr,
@@ -408,6 +416,9 @@ func (p *Puller) getCmp(ctx context.Context, twDetails *TreeWalkEventDetails, le
 
 				refs := make(map[hash.Hash]int)
 				if err := types.WalkRefs(chnk, p.fmt, func(r types.Ref) error {
+					if p.excluded.Has(r.TargetHash()) {
+						return nil
+					}
 					refs[r.TargetHash()] = int(r.Height())
 					return nil
 				}); ae.SetIfError(err) {
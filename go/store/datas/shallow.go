@@ -0,0 +1,350 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/dolthub/dolt/go/store/chunks"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+// A shallow database holds only part of the history of its commits, such as a database cloned with a limited depth.
+// The commits at the edge of the history it holds are its shallow commits: it holds the commits themselves, but not
+// their parents. The history walks of this package treat shallow commits as having no parents, and the ancestors of
+// the shallow commits, which the commits and their parents closures still reference, are grafted away: they may be
+// referenced by the values of the database without being held by it.
+
+// shallowGraft is the set of shallow commits of a database, along with the commits grafted away by them.
+type shallowGraft struct {
+	mu      sync.RWMutex
+	commits hash.HashSet
+	grafted hash.HashSet
+}
+
+// ShallowCommits returns the shallow commits of |vr|, or nil if |vr| is not a shallow database. The returned set must
+// not be modified.
+func ShallowCommits(vr types.ValueReader) hash.HashSet {
+	db, ok := vr.(*database)
+	if !ok {
+		return nil
+	}
+
+	db.shallow.mu.RLock()
+	defer db.shallow.mu.RUnlock()
+	return db.shallow.commits
+}
+
+// SetShallowCommits sets the shallow commits of |db|, which must already hold each of |commits|. An empty set makes
+// |db| hold its complete history again.
+func SetShallowCommits(ctx context.Context, db Database, commits hash.HashSet) error {
+	sdb, ok := db.(*database)
+	if !ok {
+		return chunks.ErrUnsupportedOperation
+	}
+
+	if len(commits) == 0 {
+		func() {
+			sdb.shallow.mu.Lock()
+			defer sdb.shallow.mu.Unlock()
+			sdb.shallow.commits = nil
+			sdb.shallow.grafted = nil
+		}()
+
+		sdb.updateAbsentRefsFilter()
+		return nil
+	}
+
+	// the grafted commits are found up front, as the filter runs while the ValueStore cannot be read
+	grafted, err := GraftedCommits(ctx, db, commits)
+	if err != nil {
+		return err
+	}
+
+	sdb.shallow.mu.Lock()
+	sdb.shallow.commits = commits.Copy()
+	sdb.shallow.grafted = grafted
+	sdb.shallow.mu.Unlock()
+
+	sdb.updateAbsentRefsFilter()
+	return nil
+}
+
+// filterGraftedRefs returns the chunks of |absent| which are not grafted away by the shallow commits of |db|.
+func (db *database) filterGraftedRefs(absent hash.HashSet) hash.HashSet {
+	db.shallow.mu.RLock()
+	defer db.shallow.mu.RUnlock()
+
+	if len(db.shallow.grafted) == 0 {
+		return absent
+	}
+
+	dangling := hash.NewHashSet()
+	for h := range absent {
+		if !db.shallow.grafted.Has(h) {
+			dangling.Insert(h)
+		}
+	}
+
+	return dangling
+}
+
+// GraftedCommits returns the ancestors of |commits|, as recorded in their parents and parents closures, which are
+// grafted away when |commits| are the shallow commits of a database. Each commit of |commits| must be readable
+// through |vr|; their ancestors need not be.
+func GraftedCommits(ctx context.Context, vr types.ValueReader, commits hash.HashSet) (hash.HashSet, error) {
+	grafted := hash.NewHashSet()
+	for h := range commits {
+		st, err := readCommit(ctx, vr, h)
+		if err != nil {
+			return nil, err
+		}
+
+		parents, err := commitParents(ctx, st)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range parents {
+			grafted.Insert(p)
+		}
+
+		v, ok, err := st.MaybeGet(ParentsClosureField)
+		if err != nil {
+			return nil, err
+		} else if !ok || types.IsNull(v) {
+			continue
+		}
+
+		r, ok := v.(types.Ref)
+		if !ok {
+			return nil, errors.New("unexpected field value type for parents_closure in commit struct")
+		}
+		mv, err := r.TargetValue(ctx, vr)
+		if err != nil {
+			return nil, err
+		}
+		m, ok := mv.(types.Map)
+		if !ok {
+			return nil, fmt.Errorf("unexpected target value type for parents_closure in commit struct: %v", mv)
+		}
+
+		err = m.IterAll(ctx, func(k, _ types.Value) error {
+			t, ok := k.(types.Tuple)
+			if !ok {
+				return errors.New("key value of parents closure map should have been Tuple")
+			}
+			field, err := t.Get(1)
+			if err != nil {
+				return err
+			}
+			ib, ok := field.(types.InlineBlob)
+			if !ok {
+				return errors.New("second field of tuple key parents closure should have been InlineBlob")
+			}
+			grafted.Insert(hash.New(ib))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return grafted, nil
+}
+
+// ShallowPull describes the commits to pull to give a database the history of some commits up to a limited depth.
+type ShallowPull struct {
+	// Commits are the commits to pull.
+	Commits hash.HashSet
+	// Boundary are the commits of |Commits| whose parents are neither pulled nor already held. They become shallow
+	// commits of the database pulled into.
+	Boundary hash.HashSet
+}
+
+// PlanShallowPull walks the history of |heads| in |srcDB| to find the commits |sinkDB| needs to hold the last |depth|
+// commits of their history. The walk stops at the commits |sinkDB| already holds and at the shallow commits of
+// |srcDB|. A |depth| of zero or less does not limit the walk.
+func PlanShallowPull(ctx context.Context, srcDB, sinkDB Database, heads hash.HashSet, depth int) (ShallowPull, error) {
+	pull := ShallowPull{Commits: hash.NewHashSet(), Boundary: hash.NewHashSet()}
+	srcShallow := ShallowCommits(srcDB)
+
+	level, err := sinkDB.chunkStore().HasMany(ctx, heads)
+	if err != nil {
+		return ShallowPull{}, err
+	}
+
+	for d := 1; len(level) > 0; d++ {
+		parents := make(map[hash.Hash][]hash.Hash, len(level))
+		next := hash.NewHashSet()
+		for h := range level {
+			pull.Commits.Insert(h)
+			if srcShallow.Has(h) {
+				pull.Boundary.Insert(h)
+				continue
+			}
+
+			st, err := readCommit(ctx, srcDB, h)
+			if err != nil {
+				return ShallowPull{}, err
+			}
+			ps, err := commitParents(ctx, st)
+			if err != nil {
+				return ShallowPull{}, err
+			}
+
+			parents[h] = ps
+			for _, p := range ps {
+				next.Insert(p)
+			}
+		}
+
+		absent, err := sinkDB.chunkStore().HasMany(ctx, next)
+		if err != nil {
+			return ShallowPull{}, err
+		}
+
+		if depth > 0 && d >= depth {
+			for h, ps := range parents {
+				for _, p := range ps {
+					if absent.Has(p) && !pull.Commits.Has(p) {
+						pull.Boundary.Insert(h)
+						break
+					}
+				}
+			}
+			break
+		}
+
+		level = hash.NewHashSet()
+		for h := range absent {
+			if !pull.Commits.Has(h) {
+				level.Insert(h)
+			}
+		}
+	}
+
+	return pull, nil
+}
+
+// ExcludedFromShallowPull returns the chunks a shallow pull of |pull| from |srcDB| into |sinkDB| must not follow refs
+// to: the ancestors of the boundary commits of |pull| and of the shallow commits of |sinkDB|, other than the commits
+// being pulled.
+func ExcludedFromShallowPull(ctx context.Context, srcDB, sinkDB Database, pull ShallowPull) (hash.HashSet, error) {
+	excluded, err := GraftedCommits(ctx, srcDB, pull.Boundary)
+	if err != nil {
+		return nil, err
+	}
+
+	sinkShallow := ShallowCommits(sinkDB)
+	if len(sinkShallow) > 0 {
+		grafted, err := GraftedCommits(ctx, sinkDB, sinkShallow)
+		if err != nil {
+			return nil, err
+		}
+		excluded.InsertAll(grafted)
+	}
+
+	for h := range pull.Commits {
+		excluded.Remove(h)
+	}
+
+	return excluded, nil
+}
+
+// ShallowCommitsAfterPull returns the shallow commits of |sinkDB| once |pull| has been pulled into it: its current
+// shallow commits whose parents it still does not hold, along with the boundary commits of |pull|.
+func ShallowCommitsAfterPull(ctx context.Context, sinkDB Database, pull ShallowPull) (hash.HashSet, error) {
+	shallow := pull.Boundary.Copy()
+	for h := range ShallowCommits(sinkDB) {
+		parents, err := CommitParents(ctx, sinkDB, h)
+		if err != nil {
+			return nil, err
+		}
+
+		absent, err := sinkDB.chunkStore().HasMany(ctx, hash.NewHashSet(parents...))
+		if err != nil {
+			return nil, err
+		}
+
+		if len(absent) > 0 {
+			shallow.Insert(h)
+		}
+	}
+
+	return shallow, nil
+}
+
+// CommitParents returns the parents of the commit |h|, as recorded in the commit, even if it is a shallow commit of
+// |vr|.
+func CommitParents(ctx context.Context, vr types.ValueReader, h hash.Hash) ([]hash.Hash, error) {
+	st, err := readCommit(ctx, vr, h)
+	if err != nil {
+		return nil, err
+	}
+
+	return commitParents(ctx, st)
+}
+
+func readCommit(ctx context.Context, vr types.ValueReader, h hash.Hash) (types.Struct, error) {
+	v, err := vr.ReadValue(ctx, h)
+	if err != nil {
+		return types.Struct{}, err
+	}
+	if v == nil {
+		return types.Struct{}, fmt.Errorf("target not found: %v", h)
+	}
+
+	st, ok := v.(types.Struct)
+	if !ok || st.Name() != CommitName {
+		return types.Struct{}, fmt.Errorf("value is not a commit: %v", h)
+	}
+
+	return st, nil
+}
+
+// commitParents returns the parents of the commit |st|, as recorded in the commit, whether or not it is shallow.
+func commitParents(ctx context.Context, st types.Struct) ([]hash.Hash, error) {
+	var parents []hash.Hash
+	addParent := func(v types.Value) error {
+		r, ok := v.(types.Ref)
+		if !ok {
+			return errors.New("parentsRef element was not a Ref")
+		}
+		parents = append(parents, r.TargetHash())
+		return nil
+	}
+
+	if v, ok, err := st.MaybeGet(ParentsListField); err != nil {
+		return nil, err
+	} else if ok && !types.IsNull(v) {
+		err = v.(types.List).IterAll(ctx, func(v types.Value, _ uint64) error {
+			return addParent(v)
+		})
+		return parents, err
+	}
+
+	if v, ok, err := st.MaybeGet(ParentsField); err != nil {
+		return nil, err
+	} else if ok && !types.IsNull(v) {
+		err = v.(types.Set).IterAll(ctx, addParent)
+		return parents, err
+	}
+
+	return nil, nil
+}
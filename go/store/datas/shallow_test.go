@@ -0,0 +1,116 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datas
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+func TestShallowPull(t *testing.T) {
+	ctx := context.Background()
+	srcCS, sinkCS := makeTestStoreViews()
+	src, sink := NewDatabase(srcCS), NewDatabase(sinkCS)
+	defer src.Close()
+	defer sink.Close()
+
+	// a linear history of five commits, the oldest first
+	ds, err := src.GetDataset(ctx, datasetID)
+	require.NoError(t, err)
+	var commits []types.Ref
+	for i := 0; i < 5; i++ {
+		ds, err = src.CommitValue(ctx, ds, buildListOfHeight(2, src))
+		require.NoError(t, err)
+		commits = append(commits, mustHeadRef(ds))
+	}
+	head := commits[4]
+
+	pullShallow := func(roots []types.Ref, depth int) ShallowPull {
+		heads := hash.NewHashSet()
+		for _, r := range roots {
+			heads.Insert(r.TargetHash())
+		}
+
+		plan, err := PlanShallowPull(ctx, src, sink, heads, depth)
+		require.NoError(t, err)
+		excluded, err := ExcludedFromShallowPull(ctx, src, sink, plan)
+		require.NoError(t, err)
+		for _, r := range roots {
+			require.NoError(t, PullShallowWithoutBatching(ctx, src, sink, r, excluded, nil))
+		}
+
+		shallow, err := ShallowCommitsAfterPull(ctx, sink, plan)
+		require.NoError(t, err)
+		require.NoError(t, SetShallowCommits(ctx, sink, shallow))
+		return plan
+	}
+
+	plan := pullShallow([]types.Ref{head}, 2)
+	assert.Equal(t, hash.NewHashSet(commits[3].TargetHash(), commits[4].TargetHash()), plan.Commits)
+	assert.Equal(t, hash.NewHashSet(commits[3].TargetHash()), plan.Boundary)
+	assert.Equal(t, hash.NewHashSet(commits[3].TargetHash()), ShallowCommits(sink))
+
+	for i, c := range commits {
+		v, err := sink.ReadValue(ctx, c.TargetHash())
+		require.NoError(t, err)
+		assert.Equal(t, i >= 3, v != nil, "commit %d", i)
+	}
+
+	// the history of the sink ends at its shallow commit
+	closure, err := NewSetRefClosure(ctx, sink, head)
+	require.NoError(t, err)
+	for i, c := range commits {
+		ok, err := closure.Contains(ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, i >= 3, ok, "commit %d", i)
+	}
+
+	// commits can be made on top of the shallow history, although their parents closures reference the commits it
+	// does not hold
+	sinkDS, err := sink.GetDataset(ctx, datasetID)
+	require.NoError(t, err)
+	sinkDS, err = sink.SetHead(ctx, sinkDS, head)
+	require.NoError(t, err)
+	sinkDS, err = sink.CommitValue(ctx, sinkDS, types.Float(5))
+	require.NoError(t, err)
+
+	ancestor, ok, err := FindCommonAncestor(ctx, mustHeadRef(sinkDS), commits[3], sink, sink)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, commits[3].TargetHash(), ancestor.TargetHash())
+
+	// deepening the history pulls the parents of the shallow commit
+	plan = pullShallow([]types.Ref{commits[2]}, 2)
+	assert.Equal(t, hash.NewHashSet(commits[1].TargetHash(), commits[2].TargetHash()), plan.Commits)
+	assert.Equal(t, hash.NewHashSet(commits[1].TargetHash()), ShallowCommits(sink))
+
+	closure, err = NewSetRefClosure(ctx, sink, mustHeadRef(sinkDS))
+	require.NoError(t, err)
+	for i, c := range commits {
+		ok, err := closure.Contains(ctx, c)
+		require.NoError(t, err)
+		assert.Equal(t, i >= 1, ok, "commit %d", i)
+	}
+
+	// deepening it past the first commit completes it
+	pullShallow([]types.Ref{commits[0]}, 5)
+	assert.Empty(t, ShallowCommits(sink))
+}
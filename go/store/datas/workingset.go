@@ -33,6 +33,11 @@ const (
 	MergeStateName                 = "MergeState"
 	MergeStateCommitField          = "commit"
 	MergeStateWorkingPreMergeField = "workingPreMerge"
+
+	MergeStateRebaseOntoField     = "rebaseOnto"
+	MergeStateRebaseOrigHeadField = "rebaseOrigHead"
+	MergeStateRebaseActionField   = "rebaseAction"
+	MergeStateRebaseTodoField     = "rebaseTodo"
 )
 
 const (
@@ -98,6 +103,21 @@ func NewMergeState(_ context.Context, preMergeWorking types.Ref, commit types.St
 	return mergeStateTemplate.NewStruct(preMergeWorking.Format(), []types.Value{commit, preMergeWorking})
 }
 
+// NewRebaseMergeState creates a merge state for a rebase that has stopped partway through. In addition to the fields
+// of a regular merge state, where |commit| is the commit being replayed, it records the commit being rebased onto, the
+// head of the branch before the rebase started, the action being applied for |commit|, and the remaining rebase plan.
+func NewRebaseMergeState(_ context.Context, preMergeWorking types.Ref, commit, onto, origHead types.Struct, action, todo string) (types.Struct, error) {
+	fields := make(types.StructData)
+	fields[MergeStateCommitField] = commit
+	fields[MergeStateWorkingPreMergeField] = preMergeWorking
+	fields[MergeStateRebaseOntoField] = onto
+	fields[MergeStateRebaseOrigHeadField] = origHead
+	fields[MergeStateRebaseActionField] = types.String(action)
+	fields[MergeStateRebaseTodoField] = types.String(todo)
+
+	return types.NewStruct(preMergeWorking.Format(), MergeStateName, fields)
+}
+
 func NewWorkingSetMeta(format *types.NomsBinFormat, name, email string, timestamp uint64, description string) (types.Struct, error) {
 	fields := make(types.StructData)
 	fields[WorkingSetMetaNameField] = types.String(name)
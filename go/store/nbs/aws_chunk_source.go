@@ -22,6 +22,7 @@
 package nbs
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"sync"
@@ -30,7 +31,7 @@ import (
 
 type indexParserF func([]byte) (tableIndex, error)
 
-func newAWSChunkSource(ctx context.Context, ddb *ddbTableStore, s3 *s3ObjectReader, al awsLimits, name addr, chunkCount uint32, indexCache *indexCache, stats *Stats, parseIndex indexParserF) (cs chunkSource, err error) {
+func newAWSChunkSource(ctx context.Context, ddb *ddbTableStore, s3 *s3ObjectReader, al awsLimits, name addr, chunkCount uint32, indexCache *indexCache, keys *Keyring, stats *Stats, parseIndex indexParserF) (cs chunkSource, err error) {
 	if indexCache != nil {
 		indexCache.lockEntry(name)
 		defer func() {
@@ -57,7 +58,13 @@ func newAWSChunkSource(ctx context.Context, ddb *ddbTableStore, s3 *s3ObjectRead
 			}
 
 			if data != nil {
-				return data, &dynamoTableReaderAt{ddb: ddb, h: name}, nil
+				buff, env, err := readTableTail(keys, chunkCount, readFileTail(bytes.NewReader(data), int64(len(data))))
+
+				if err != nil {
+					return nil, &dynamoTableReaderAt{}, err
+				}
+
+				return buff, env.readerAt(&dynamoTableReaderAt{ddb: ddb, h: name}), nil
 			}
 
 			if _, ok := err.(tableNotInDynamoErr); !ok {
@@ -65,20 +72,27 @@ func newAWSChunkSource(ctx context.Context, ddb *ddbTableStore, s3 *s3ObjectRead
 			}
 		}
 
-		size := indexSize(chunkCount) + footerSize
-		buff := make([]byte, size)
+		buff, env, err := readTableTail(keys, chunkCount, func(size uint64) ([]byte, error) {
+			buff := make([]byte, size)
 
-		n, _, err := s3.ReadFromEnd(ctx, name, buff, stats)
+			n, _, err := s3.ReadFromEnd(ctx, name, buff, stats)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if size != uint64(n) {
+				return nil, errors.New("failed to read all data")
+			}
+
+			return buff, nil
+		})
 
 		if err != nil {
 			return nil, &dynamoTableReaderAt{}, err
 		}
 
-		if size != uint64(n) {
-			return nil, &dynamoTableReaderAt{}, errors.New("failed to read all data")
-		}
-
-		return buff, &s3TableReaderAt{s3: s3, h: name}, nil
+		return buff, env.readerAt(&s3TableReaderAt{s3: s3, h: name}), nil
 	}()
 
 	if err != nil {
@@ -53,6 +53,7 @@ func TestAWSChunkSource(t *testing.T) {
 			h,
 			uint32(len(chunks)),
 			ic,
+			nil,
 			&Stats{},
 			func(bs []byte) (tableIndex, error) {
 				return parseTableIndex(bs)
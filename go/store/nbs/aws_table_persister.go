@@ -62,6 +62,10 @@ type awsTablePersister struct {
 	indexCache *indexCache
 	ns         string
 	parseIndex indexParserF
+
+	// keys seal the table files written by the persister, and open sealed table files. Table files are not sealed
+	// when nil.
+	keys *Keyring
 }
 
 type awsLimits struct {
@@ -90,6 +94,7 @@ func (s3p awsTablePersister) Open(ctx context.Context, name addr, chunkCount uin
 		name,
 		chunkCount,
 		s3p.indexCache,
+		s3p.keys,
 		stats,
 		s3p.parseIndex,
 	)
@@ -118,23 +123,35 @@ func (s3p awsTablePersister) Persist(ctx context.Context, mt *memTable, haver ch
 		return emptyChunkSource{}, nil
 	}
 
-	if s3p.limits.tableFitsInDynamo(name, len(data), chunkCount) {
-		err := s3p.ddb.Write(ctx, name, data)
+	return s3p.persistTable(ctx, name, data, chunkCount)
+}
+
+// persistTable writes the table file |data| named |name|, sealed with the keys of the persister.
+func (s3p awsTablePersister) persistTable(ctx context.Context, name addr, data []byte, chunkCount uint32) (chunkSource, error) {
+	sealed, env, err := sealTable(s3p.keys, data)
+
+	if err != nil {
+		return emptyChunkSource{}, err
+	}
+
+	if s3p.limits.tableFitsInDynamo(name, len(sealed), chunkCount) {
+		err := s3p.ddb.Write(ctx, name, sealed)
 
 		if err != nil {
 			return nil, err
 		}
 
-		return newReaderFromIndexData(s3p.indexCache, data, name, &dynamoTableReaderAt{ddb: s3p.ddb, h: name}, s3BlockSize)
+		tra := env.readerAt(&dynamoTableReaderAt{ddb: s3p.ddb, h: name})
+		return newReaderFromIndexData(s3p.indexCache, data, name, tra, s3BlockSize)
 	}
 
-	err = s3p.multipartUpload(ctx, data, name.String())
+	err = s3p.multipartUpload(ctx, sealed, name.String())
 
 	if err != nil {
 		return emptyChunkSource{}, err
 	}
 
-	tra := &s3TableReaderAt{&s3ObjectReader{s3: s3p.s3, bucket: s3p.bucket, readRl: s3p.rl, ns: s3p.ns}, name}
+	tra := env.readerAt(&s3TableReaderAt{&s3ObjectReader{s3: s3p.s3, bucket: s3p.bucket, readRl: s3p.rl, ns: s3p.ns}, name})
 	return newReaderFromIndexData(s3p.indexCache, data, name, tra, s3BlockSize)
 }
 
@@ -290,6 +307,10 @@ func (s partsByPartNum) Swap(i, j int) {
 }
 
 func (s3p awsTablePersister) ConjoinAll(ctx context.Context, sources chunkSources, stats *Stats) (chunkSource, error) {
+	if s3p.keys != nil {
+		return s3p.rewriteAll(ctx, sources, stats)
+	}
+
 	plan, err := planConjoin(sources, stats)
 
 	if err != nil {
@@ -313,6 +334,50 @@ func (s3p awsTablePersister) ConjoinAll(ctx context.Context, sources chunkSource
 	return newReaderFromIndexData(s3p.indexCache, plan.mergedIndex, name, tra, s3BlockSize)
 }
 
+// rewriteAll writes the chunks of |sources| to a new table file. Sealed table files cannot be concatenated, so the
+// table files of persisters with keys are conjoined by rewriting them.
+func (s3p awsTablePersister) rewriteAll(ctx context.Context, sources chunkSources, stats *Stats) (chunkSource, error) {
+	tw, err := NewCmpChunkTableWriter("")
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, src := range sources {
+		err = copyChunkSource(ctx, src, tw, stats)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if tw.Size() == 0 {
+		return emptyChunkSource{}, nil
+	}
+
+	fileID, err := tw.Finish()
+
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := parseAddr(fileID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	buff := bytes.NewBuffer(make([]byte, 0, tw.ContentLength()))
+	err = tw.Flush(buff)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stats.BytesPerConjoin.Sample(tw.ContentLength())
+	return s3p.persistTable(ctx, name, buff.Bytes(), tw.ChunkCount())
+}
+
 func (s3p awsTablePersister) executeCompactionPlan(ctx context.Context, plan compactionPlan, key string) error {
 	uploadID, err := s3p.startMultipartUpload(ctx, key)
 
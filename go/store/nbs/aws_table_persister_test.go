@@ -342,6 +342,7 @@ func TestAWSTablePersisterConjoinAll(t *testing.T) {
 			ic,
 			"",
 			parseIndexF,
+			nil,
 		}
 	}
 
@@ -28,13 +28,16 @@ const (
 type blobstoreManifest struct {
 	name string
 	bs   blobstore.Blobstore
+
+	// keys seal the manifest when it is written, and open sealed manifests. The manifest is not sealed when nil.
+	keys *Keyring
 }
 
 func (bsm blobstoreManifest) Name() string {
 	return bsm.name
 }
 
-func manifestVersionAndContents(ctx context.Context, bs blobstore.Blobstore) (string, manifestContents, error) {
+func manifestVersionAndContents(ctx context.Context, bs blobstore.Blobstore, keys *Keyring) (string, manifestContents, error) {
 	reader, ver, err := bs.Get(ctx, manifestFile, blobstore.AllRange)
 
 	if err != nil {
@@ -42,7 +45,7 @@ func manifestVersionAndContents(ctx context.Context, bs blobstore.Blobstore) (st
 	}
 
 	defer reader.Close()
-	contents, err := parseManifest(reader)
+	contents, err := parseManifestWithKeys(reader, keys)
 
 	if err != nil {
 		return "", manifestContents{}, err
@@ -58,7 +61,7 @@ func (bsm blobstoreManifest) ParseIfExists(ctx context.Context, stats *Stats, re
 		panic("Read hooks not supported")
 	}
 
-	_, contents, err := manifestVersionAndContents(ctx, bsm.bs)
+	_, contents, err := manifestVersionAndContents(ctx, bsm.bs, bsm.keys)
 
 	if err != nil {
 		if blobstore.IsNotFoundError(err) {
@@ -78,7 +81,7 @@ func (bsm blobstoreManifest) Update(ctx context.Context, lastLock addr, newConte
 		panic("Write hooks not supported")
 	}
 
-	ver, contents, err := manifestVersionAndContents(ctx, bsm.bs)
+	ver, contents, err := manifestVersionAndContents(ctx, bsm.bs, bsm.keys)
 
 	if err != nil && !blobstore.IsNotFoundError(err) {
 		return manifestContents{}, err
@@ -86,7 +89,7 @@ func (bsm blobstoreManifest) Update(ctx context.Context, lastLock addr, newConte
 
 	if contents.lock == lastLock {
 		buffer := bytes.NewBuffer(make([]byte, 64*1024)[:0])
-		err := writeManifest(buffer, newContents)
+		err := writeManifestWithKeys(buffer, newContents, bsm.keys)
 
 		if err != nil {
 			return manifestContents{}, err
@@ -28,6 +28,10 @@ type blobstorePersister struct {
 	bs         blobstore.Blobstore
 	blockSize  uint64
 	indexCache *indexCache
+
+	// keys seal the table files written by the persister, and open sealed table files. Table files are not sealed
+	// when nil.
+	keys *Keyring
 }
 
 // Persist makes the contents of mt durable. Chunks already present in
@@ -43,13 +47,19 @@ func (bsp *blobstorePersister) Persist(ctx context.Context, mt *memTable, haver
 		return emptyChunkSource{}, nil
 	}
 
-	_, err = blobstore.PutBytes(ctx, bsp.bs, name.String(), data)
+	sealed, env, err := sealTable(bsp.keys, data)
 
 	if err != nil {
 		return emptyChunkSource{}, err
 	}
 
-	bsTRA := &bsTableReaderAt{name.String(), bsp.bs}
+	_, err = blobstore.PutBytes(ctx, bsp.bs, name.String(), sealed)
+
+	if err != nil {
+		return emptyChunkSource{}, err
+	}
+
+	bsTRA := env.readerAt(&bsTableReaderAt{name.String(), bsp.bs})
 	return newReaderFromIndexData(bsp.indexCache, data, name, bsTRA, bsp.blockSize)
 }
 
@@ -61,7 +71,7 @@ func (bsp *blobstorePersister) ConjoinAll(ctx context.Context, sources chunkSour
 
 // Open a table named |name|, containing |chunkCount| chunks.
 func (bsp *blobstorePersister) Open(ctx context.Context, name addr, chunkCount uint32, stats *Stats) (chunkSource, error) {
-	return newBSChunkSource(ctx, bsp.bs, name, chunkCount, bsp.blockSize, bsp.indexCache, stats)
+	return newBSChunkSource(ctx, bsp.bs, name, chunkCount, bsp.blockSize, bsp.indexCache, bsp.keys, stats)
 }
 
 type bsTableReaderAt struct {
@@ -97,7 +107,7 @@ func (bsTRA *bsTableReaderAt) ReadAtWithStats(ctx context.Context, p []byte, off
 	return totalRead, nil
 }
 
-func newBSChunkSource(ctx context.Context, bs blobstore.Blobstore, name addr, chunkCount uint32, blockSize uint64, indexCache *indexCache, stats *Stats) (cs chunkSource, err error) {
+func newBSChunkSource(ctx context.Context, bs blobstore.Blobstore, name addr, chunkCount uint32, blockSize uint64, indexCache *indexCache, keys *Keyring, stats *Stats) (cs chunkSource, err error) {
 	if indexCache != nil {
 		indexCache.lockEntry(name)
 		defer func() {
@@ -116,19 +126,27 @@ func newBSChunkSource(ctx context.Context, bs blobstore.Blobstore, name addr, ch
 
 	t1 := time.Now()
 	indexBytes, tra, err := func() ([]byte, tableReaderAt, error) {
-		size := int64(indexSize(chunkCount) + footerSize)
 		key := name.String()
-		buff, _, err := blobstore.GetBytes(ctx, bs, key, blobstore.NewBlobRange(-size, 0))
+		buff, env, err := readTableTail(keys, chunkCount, func(n uint64) ([]byte, error) {
+			size := int64(n)
+			buff, _, err := blobstore.GetBytes(ctx, bs, key, blobstore.NewBlobRange(-size, 0))
+
+			if err != nil {
+				return nil, err
+			}
+
+			if size != int64(len(buff)) {
+				return nil, errors.New("failed to read all data")
+			}
+
+			return buff, nil
+		})
 
 		if err != nil {
 			return nil, nil, err
 		}
 
-		if size != int64(len(buff)) {
-			return nil, nil, errors.New("failed to read all data")
-		}
-
-		return buff, &bsTableReaderAt{key, bs}, nil
+		return buff, env.readerAt(&bsTableReaderAt{key, bs}), nil
 	}()
 
 	if err != nil {
@@ -24,6 +24,7 @@ import (
 
 	"github.com/golang/snappy"
 
+	"github.com/dolthub/dolt/go/store/chunks"
 	nomshash "github.com/dolthub/dolt/go/store/hash"
 )
 
@@ -46,24 +47,47 @@ type CmpChunkTableWriter struct {
 	prefixes              prefixIndexSlice // TODO: This is in danger of exploding memory
 	blockAddr             *addr
 	chunkHashes           nomshash.HashSet
+
+	compression TableFileCompression
+	enc         tableEncoder
+
+	// pending holds the first chunks added to a writer of ZstdDictCompression, until enough of them were added to
+	// train the dictionary of the table file.
+	pending   []chunks.Chunk
+	dictReady bool
 }
 
 // NewCmpChunkTableWriter creates a new CmpChunkTableWriter instance with a default ByteSink
 func NewCmpChunkTableWriter(tempDir string) (*CmpChunkTableWriter, error) {
+	return NewCmpChunkTableWriterWithCompression(tempDir, SnappyCompression)
+}
+
+// NewCmpChunkTableWriterWithCompression creates a new CmpChunkTableWriter instance with a default ByteSink, which
+// writes a table file whose chunks are compressed with |compression|. Chunks compressed otherwise are compressed again.
+func NewCmpChunkTableWriterWithCompression(tempDir string, compression TableFileCompression) (*CmpChunkTableWriter, error) {
 	s, err := NewBufferedFileByteSink(tempDir, defaultTableSinkBlockSize, defaultChBufferSize)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &CmpChunkTableWriter{NewHashingByteSink(s), 0, 0, nil, nil, nomshash.NewHashSet()}, nil
+	return &CmpChunkTableWriter{
+		sink:        NewHashingByteSink(s),
+		chunkHashes: nomshash.NewHashSet(),
+		compression: compression,
+		enc:         newTableEncoder(compression, nil),
+	}, nil
 }
 
 // Size returns the number of compressed chunks that have been added
 func (tw *CmpChunkTableWriter) Size() int {
-	return len(tw.prefixes)
+	if tw.enc.dict != nil {
+		return len(tw.prefixes) - 1
+	}
+	return len(tw.prefixes) + len(tw.pending)
 }
 
+// ChunkCount returns the number of records in the table file, which includes its dictionary if it has one.
 func (tw *CmpChunkTableWriter) ChunkCount() uint32 {
 	return uint32(len(tw.prefixes))
 }
@@ -89,6 +113,33 @@ func (tw *CmpChunkTableWriter) AddCmpChunk(c CompressedChunk) error {
 	}
 
 	tw.chunkHashes.Insert(c.H)
+
+	if tw.compression == ZstdDictCompression && !tw.dictReady {
+		ch, err := c.ToChunk()
+
+		if err != nil {
+			return err
+		}
+
+		tw.pending = append(tw.pending, ch)
+
+		if len(tw.pending) < maxTableDictSamples {
+			return nil
+		}
+
+		return tw.flushPending()
+	}
+
+	if tw.enc.format != tableFormatV1 || c.codec != legacySnappyCodec {
+		rec, uncmpLen, err := tw.enc.appendCompressedRecord(nil, c)
+
+		if err != nil {
+			return err
+		}
+
+		return tw.writeRecord(addr(c.H), rec, uint64(uncmpLen))
+	}
+
 	uncmpLen, err := snappy.DecodedLen(c.CompressedData)
 
 	if err != nil {
@@ -117,12 +168,86 @@ func (tw *CmpChunkTableWriter) AddCmpChunk(c CompressedChunk) error {
 	return nil
 }
 
+// writeRecord writes the tableFormatV2 record |rec| of the chunk |a|.
+func (tw *CmpChunkTableWriter) writeRecord(a addr, rec []byte, uncmpLen uint64) error {
+	_, err := tw.sink.Write(rec)
+
+	if err != nil {
+		return err
+	}
+
+	if a != tableDictAddr {
+		tw.totalCompressedData += uint64(len(rec) - codecSize - checksumSize)
+		tw.totalUncompressedData += uncmpLen
+	}
+
+	// Stored in insertion order
+	tw.prefixes = append(tw.prefixes, prefixIndexRec{
+		a.Prefix(),
+		a[addrPrefixSize:],
+		uint32(len(tw.prefixes)),
+		uint32(len(rec)),
+	})
+
+	return nil
+}
+
+// flushPending trains the dictionary of the table file on the pending chunks, and writes it followed by them.
+func (tw *CmpChunkTableWriter) flushPending() error {
+	idxs := sampleChunks(len(tw.pending))
+	samples := make([][]byte, len(idxs))
+	for i, idx := range idxs {
+		samples[i] = tw.pending[idx].Data()
+	}
+
+	var dict *zstdDict
+	if content := trainTableDict(samples); content != nil {
+		dict = newZstdDict(content)
+	}
+
+	tw.enc = newTableEncoder(ZstdDictCompression, dict)
+	tw.dictReady = true
+
+	if dict != nil {
+		err := tw.writeRecord(tableDictAddr, tw.enc.appendDictionaryRecord(nil), 0)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, ch := range tw.pending {
+		rec, err := tw.enc.appendRecord(nil, ch.Data())
+
+		if err != nil {
+			return err
+		}
+
+		err = tw.writeRecord(addr(ch.Hash()), rec, uint64(len(ch.Data())))
+
+		if err != nil {
+			return err
+		}
+	}
+
+	tw.pending = nil
+	return nil
+}
+
 // Finish will write the index and footer of the table file and return the id of the file.
 func (tw *CmpChunkTableWriter) Finish() (string, error) {
 	if tw.blockAddr != nil {
 		return "", ErrAlreadyFinished
 	}
 
+	if tw.compression == ZstdDictCompression && !tw.dictReady {
+		err := tw.flushPending()
+
+		if err != nil {
+			return "", err
+		}
+	}
+
 	blockHash, err := tw.writeIndex()
 
 	if err != nil {
@@ -236,7 +361,7 @@ func (tw *CmpChunkTableWriter) writeFooter() error {
 	}
 
 	// magic number
-	_, err = tw.sink.Write([]byte(magicNumber))
+	_, err = tw.sink.Write([]byte(tw.enc.format.magicNumber()))
 
 	if err != nil {
 		return err
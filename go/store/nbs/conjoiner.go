@@ -52,7 +52,19 @@ type inlineConjoiner struct {
 }
 
 func (c inlineConjoiner) ConjoinRequired(ts tableSet) bool {
-	return ts.Size() > c.maxTables && len(ts.upstream) >= 2
+	return ts.Size() > c.maxTables && conjoinableCount(ts) >= 2
+}
+
+// conjoinableCount returns the number of upstream tables of |ts| which may be conjoined, which excludes the chunk
+// journal.
+func conjoinableCount(ts tableSet) int {
+	cnt := 0
+	for _, src := range ts.upstream {
+		if h, err := src.hash(); err != nil || h != journalAddr {
+			cnt++
+		}
+	}
+	return cnt
 }
 
 func (c inlineConjoiner) Conjoin(ctx context.Context, upstream manifestContents, mm manifestUpdater, p tablePersister, stats *Stats) (manifestContents, error) {
@@ -162,6 +174,18 @@ func conjoin(ctx context.Context, upstream manifestContents, mm manifestUpdater,
 }
 
 func conjoinTables(ctx context.Context, p tablePersister, upstream []tableSpec, stats *Stats) (conjoined tableSpec, conjoinees, keepers []tableSpec, err error) {
+	// The chunk journal is compacted by its store rather than conjoined, so it is always kept
+	var journal []tableSpec
+	tables := make([]tableSpec, 0, len(upstream))
+	for _, spec := range upstream {
+		if spec.name == journalAddr {
+			journal = append(journal, spec)
+		} else {
+			tables = append(tables, spec)
+		}
+	}
+	upstream = tables
+
 	// Open all the upstream tables concurrently
 	sources := make(chunkSources, len(upstream))
 
@@ -233,7 +257,7 @@ func conjoinTables(ctx context.Context, p tablePersister, upstream []tableSpec,
 		return tableSpec{}, nil, nil, err
 	}
 
-	return tableSpec{h, cnt}, conjoinees, keepers, nil
+	return tableSpec{h, cnt}, conjoinees, append(journal, keepers...), nil
 }
 
 // Current approach is to choose the smallest N tables which, when removed and replaced with the conjoinment, will leave the conjoinment as the smallest table.
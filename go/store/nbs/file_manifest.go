@@ -54,7 +54,7 @@ type manifestChecker func(upstream, contents manifestContents) error
 
 // ParseManifest parses a manifest file from the supplied reader
 func ParseManifest(r io.Reader) (ManifestInfo, error) {
-	return parseManifest(r)
+	return parseManifestWithKeys(r, nil)
 }
 
 func MaybeMigrateFileManifest(ctx context.Context, dir string) (bool, error) {
@@ -66,8 +66,11 @@ func MaybeMigrateFileManifest(ctx context.Context, dir string) (bool, error) {
 		return false, err
 	}
 
-	_, contents, err := parseIfExists(ctx, dir, nil)
-	if err != nil {
+	_, contents, err := parseIfExists(ctx, dir, nil, nil)
+	if errors.Is(err, ErrEncryptionKeysRequired) {
+		// manifests encrypted at rest are only written in the current format
+		return false, nil
+	} else if err != nil {
 		return false, err
 	}
 
@@ -85,7 +88,7 @@ func MaybeMigrateFileManifest(ctx context.Context, dir string) (bool, error) {
 		return nil
 	}
 
-	_, err = updateWithChecker(ctx, dir, check, contents.lock, contents, nil)
+	_, err = updateWithChecker(ctx, dir, nil, check, contents.lock, contents, nil)
 
 	if err != nil {
 		return false, err
@@ -94,30 +97,37 @@ func MaybeMigrateFileManifest(ctx context.Context, dir string) (bool, error) {
 	return true, err
 }
 
-// parse the manifest in its given format
-func getFileManifest(ctx context.Context, dir string) (manifest, error) {
+// parse the manifest in its given format. |keys| decrypt and encrypt the manifest when it is encrypted at rest.
+func getFileManifest(ctx context.Context, dir string, keys *Keyring) (manifest, error) {
 	f, err := openIfExists(filepath.Join(dir, manifestFileName))
 	if err != nil {
 		return nil, err
 	}
 	if f == nil {
-		return fileManifest{dir}, nil
+		return fileManifest{dir, keys}, nil
 	}
 	defer func() {
 		err = f.Close()
 	}()
 
-	fm := fileManifest{dir}
+	fm := fileManifest{dir, keys}
 	ok, _, err := fm.ParseIfExists(ctx, &Stats{}, nil)
 	if ok && err == nil {
 		return fm, nil
 	}
 
+	if errors.Is(err, ErrEncryptionKeysRequired) || errors.Is(err, ErrUnknownEncryptionKey) {
+		return nil, err
+	}
+
 	return nil, ErrUnreadableManifest
 }
 
 type fileManifest struct {
 	dir string
+
+	// keys seal the manifest when it is written, and open sealed manifests. The manifest is not sealed when nil.
+	keys *Keyring
 }
 
 func newLock(dir string) *fslock.Lock {
@@ -174,7 +184,7 @@ func (fm fileManifest) ParseIfExists(
 		stats.ReadManifestLatency.SampleTimeSince(t1)
 	}()
 
-	return parseIfExists(ctx, fm.dir, readHook)
+	return parseIfExists(ctx, fm.dir, fm.keys, readHook)
 }
 
 func (fm fileManifest) Update(ctx context.Context, lastLock addr, newContents manifestContents, stats *Stats, writeHook func() error) (mc manifestContents, err error) {
@@ -188,7 +198,7 @@ func (fm fileManifest) Update(ctx context.Context, lastLock addr, newContents ma
 		return nil
 	}
 
-	return updateWithChecker(ctx, fm.dir, checker, lastLock, newContents, writeHook)
+	return updateWithChecker(ctx, fm.dir, fm.keys, checker, lastLock, newContents, writeHook)
 }
 
 func (fm fileManifest) UpdateGCGen(ctx context.Context, lastLock addr, newContents manifestContents, stats *Stats, writeHook func() error) (mc manifestContents, err error) {
@@ -206,7 +216,7 @@ func (fm fileManifest) UpdateGCGen(ctx context.Context, lastLock addr, newConten
 		return nil
 	}
 
-	return updateWithChecker(ctx, fm.dir, checker, lastLock, newContents, writeHook)
+	return updateWithChecker(ctx, fm.dir, fm.keys, checker, lastLock, newContents, writeHook)
 }
 
 // parseV5Manifest parses the v5 manifest from the Reader given. Assumes the first field (the manifest version and
@@ -333,7 +343,7 @@ func parseV4Manifest(r io.Reader) (manifestContents, error) {
 	}, nil
 }
 
-func parseIfExists(_ context.Context, dir string, readHook func() error) (exists bool, contents manifestContents, err error) {
+func parseIfExists(_ context.Context, dir string, keys *Keyring, readHook func() error) (exists bool, contents manifestContents, err error) {
 	var locked bool
 	locked, err = lockFileExists(dir)
 
@@ -388,7 +398,7 @@ func parseIfExists(_ context.Context, dir string, readHook func() error) (exists
 
 			exists = true
 
-			contents, err = parseManifest(f)
+			contents, err = parseManifestWithKeys(f, keys)
 
 			if err != nil {
 				return false, contents, err
@@ -398,7 +408,7 @@ func parseIfExists(_ context.Context, dir string, readHook func() error) (exists
 	return exists, contents, nil
 }
 
-func updateWithChecker(_ context.Context, dir string, validate manifestChecker, lastLock addr, newContents manifestContents, writeHook func() error) (mc manifestContents, err error) {
+func updateWithChecker(_ context.Context, dir string, keys *Keyring, validate manifestChecker, lastLock addr, newContents manifestContents, writeHook func() error) (mc manifestContents, err error) {
 	var tempManifestPath string
 
 	// Write a temporary manifest file, to be renamed over manifestFileName upon success.
@@ -419,7 +429,7 @@ func updateWithChecker(_ context.Context, dir string, validate manifestChecker,
 			}
 		}()
 
-		ferr = writeManifest(temp, newContents)
+		ferr = writeManifestWithKeys(temp, newContents, keys)
 
 		if ferr != nil {
 			return "", ferr
@@ -472,7 +482,7 @@ func updateWithChecker(_ context.Context, dir string, validate manifestChecker,
 				}
 			}()
 
-			upstream, ferr = parseManifest(f)
+			upstream, ferr = parseManifestWithKeys(f, keys)
 
 			if ferr != nil {
 				return manifestContents{}, ferr
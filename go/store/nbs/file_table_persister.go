@@ -29,7 +29,11 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
 
 	"github.com/dolthub/dolt/go/libraries/utils/file"
 	"github.com/dolthub/dolt/go/store/d"
@@ -40,21 +44,32 @@ const tempTablePrefix = "nbs_table_"
 
 func newFSTablePersister(dir string, fc *fdCache, indexCache *indexCache) tablePersister {
 	d.PanicIfTrue(fc == nil)
-	return &fsTablePersister{dir, fc, indexCache}
+	return &fsTablePersister{dir: dir, fc: fc, indexCache: indexCache}
 }
 
 type fsTablePersister struct {
 	dir        string
 	fc         *fdCache
 	indexCache *indexCache
+
+	// compression is the compression of the chunks of the table files written by the persister.
+	compression TableFileCompression
+
+	// keys seal the table files written by the persister, and open sealed table files. Table files are not sealed
+	// when nil.
+	keys *Keyring
 }
 
 func (ftp *fsTablePersister) Open(ctx context.Context, name addr, chunkCount uint32, stats *Stats) (chunkSource, error) {
+	if ftp.keys != nil {
+		return newKeyedTableReader(ftp.dir, name, chunkCount, ftp.fc, ftp.keys)
+	}
+
 	return newMmapTableReader(ftp.dir, name, chunkCount, ftp.indexCache, ftp.fc)
 }
 
 func (ftp *fsTablePersister) Persist(ctx context.Context, mt *memTable, haver chunkReader, stats *Stats) (chunkSource, error) {
-	name, data, chunkCount, err := mt.write(haver, stats)
+	name, data, chunkCount, err := mt.writeCompressed(haver, ftp.compression, stats)
 
 	if err != nil {
 		return emptyChunkSource{}, err
@@ -84,7 +99,10 @@ func (ftp *fsTablePersister) persistTable(ctx context.Context, name addr, data [
 			}
 		}()
 
-		_, ferr = io.Copy(temp, bytes.NewReader(data))
+		ferr = writeSealed(temp, ftp.keys, func(wr io.Writer) error {
+			_, err := io.Copy(wr, bytes.NewReader(data))
+			return err
+		})
 
 		if ferr != nil {
 			return "", ferr
@@ -132,6 +150,16 @@ func (ftp *fsTablePersister) persistTable(ctx context.Context, name addr, data [
 }
 
 func (ftp *fsTablePersister) ConjoinAll(ctx context.Context, sources chunkSources, stats *Stats) (chunkSource, error) {
+	rewrite, err := ftp.conjoinRequiresRewrite(sources)
+
+	if err != nil {
+		return emptyChunkSource{}, err
+	}
+
+	if rewrite {
+		return ftp.rewriteAll(ctx, sources, ftp.compression, stats)
+	}
+
 	plan, err := planConjoin(sources, stats)
 
 	if err != nil {
@@ -211,6 +239,182 @@ func (ftp *fsTablePersister) ConjoinAll(ctx context.Context, sources chunkSource
 	return ftp.Open(ctx, name, plan.chunkCount, stats)
 }
 
+// conjoinRequiresRewrite returns whether |sources| must be compressed again to be conjoined into a table file of the
+// compression of the persister, rather than concatenated. Table files are rewritten when their format differs from
+// the format of the persister, or when they have dictionaries, and are always rewritten when the persister trains a
+// dictionary for the conjoined file or seals its table files.
+func (ftp *fsTablePersister) conjoinRequiresRewrite(sources chunkSources) (bool, error) {
+	if ftp.compression == ZstdDictCompression || ftp.keys != nil {
+		return true, nil
+	}
+
+	for _, src := range sources {
+		index, err := src.index()
+
+		if err != nil {
+			return false, err
+		}
+
+		if index.Format() != ftp.compression.format() || hasTableDict(index) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// rewriteAll writes the chunks of |sources| to a new table file compressed with |compression|.
+func (ftp *fsTablePersister) rewriteAll(ctx context.Context, sources chunkSources, compression TableFileCompression, stats *Stats) (chunkSource, error) {
+	tw, err := NewCmpChunkTableWriterWithCompression(ftp.dir, compression)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, src := range sources {
+		err = copyChunkSource(ctx, src, tw, stats)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if tw.Size() == 0 {
+		return emptyChunkSource{}, nil
+	}
+
+	fileID, err := tw.Finish()
+
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := parseAddr(fileID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = ftp.fc.ShrinkCache()
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = ftp.flushTableWriter(tw, filepath.Join(ftp.dir, fileID))
+
+	if err != nil {
+		return nil, err
+	}
+
+	stats.BytesPerConjoin.Sample(tw.ContentLength())
+	return ftp.Open(ctx, name, tw.ChunkCount(), stats)
+}
+
+// flushTableWriter writes the table file of the finished |tw| to |path|, sealed with the keys of the persister.
+func (ftp *fsTablePersister) flushTableWriter(tw *CmpChunkTableWriter, path string) error {
+	if ftp.keys == nil {
+		return tw.FlushToFile(path)
+	}
+
+	return writeSealedTableFile(ftp.dir, path, ftp.keys, tw.Flush)
+}
+
+// rekeyTableFile seals the table file |name| with the active key of the persister, unless it is sealed with it
+// already. It returns whether the table file was sealed again. The name and contents of the table file are unchanged.
+func (ftp *fsTablePersister) rekeyTableFile(ctx context.Context, name addr, chunkCount uint32, stats *Stats) (bool, error) {
+	path := filepath.Join(ftp.dir, name.String())
+	active, err := ftp.keys.sealedWithActiveKey(path)
+
+	if err != nil || active {
+		return false, err
+	}
+
+	err = func() (err error) {
+		cs, err := ftp.Open(ctx, name, chunkCount, stats)
+
+		if err != nil {
+			return err
+		}
+
+		defer func() {
+			closeErr := cs.Close()
+
+			if err == nil {
+				err = closeErr
+			}
+		}()
+
+		r, err := cs.reader(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		return writeSealedTableFile(ftp.dir, path, ftp.keys, func(wr io.Writer) error {
+			_, err := io.Copy(wr, r)
+			return err
+		})
+	}()
+
+	if err != nil {
+		return false, err
+	}
+
+	// drop the descriptor of the replaced table file, so that the table file is opened again
+	return true, ftp.fc.ShrinkCache()
+}
+
+// copyChunkSource adds every chunk of |src| to |tw|, skipping chunks already written to it.
+func copyChunkSource(ctx context.Context, src chunkSource, tw *CmpChunkTableWriter, stats *Stats) error {
+	index, err := src.index()
+
+	if err != nil {
+		return err
+	}
+
+	reqs := make([]getRecord, 0, index.ChunkCount())
+	for i := uint32(0); i < index.ChunkCount(); i++ {
+		a := new(addr)
+		index.IndexEntry(i, a)
+
+		if index.Format() == tableFormatV2 && *a == tableDictAddr {
+			continue
+		}
+
+		reqs = append(reqs, getRecord{a: a, prefix: a.Prefix()})
+	}
+	sort.Sort(getRecordByPrefix(reqs))
+
+	var mu sync.Mutex
+	var addErr error
+	eg, egCtx := errgroup.WithContext(ctx)
+	_, err = src.getManyCompressed(egCtx, eg, reqs, func(ctx context.Context, cc CompressedChunk) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if addErr != nil {
+			return
+		}
+
+		if err := tw.AddCmpChunk(cc); err != nil && err != ErrChunkAlreadyWritten {
+			addErr = err
+		}
+	}, stats)
+
+	if err != nil {
+		return err
+	}
+
+	err = eg.Wait()
+
+	if err != nil {
+		return err
+	}
+
+	return addErr
+}
+
 func (ftp *fsTablePersister) PruneTableFiles(ctx context.Context, contents manifestContents) error {
 	ss := contents.getSpecSet()
 
@@ -0,0 +1,274 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nbs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+// ErrChunkHashMismatch is the error of a chunk whose contents do not hash to its address.
+var ErrChunkHashMismatch = errors.New("chunk contents do not match its address")
+
+// CorruptChunk is a chunk of a table file which could not be read, or whose contents do not match its address.
+type CorruptChunk struct {
+	Hash hash.Hash
+	Err  error
+}
+
+// TableFileCheck is the result of verifying a table file referenced by a manifest.
+type TableFileCheck struct {
+	// Path is the path of the table file.
+	Path string
+	// ChunkCount is the number of chunks the manifest records for the table file.
+	ChunkCount uint32
+	// Err is set when the table file is missing, or its index or footer is invalid. Its chunks are not checked then.
+	Err error
+	// Corrupt holds the chunks of the table file which are corrupt.
+	Corrupt []CorruptChunk
+}
+
+// Damaged returns whether the table file is missing, invalid or holds corrupt chunks.
+func (c TableFileCheck) Damaged() bool {
+	return c.Err != nil || len(c.Corrupt) > 0
+}
+
+// StoreCheck is the result of verifying the table files of a local store.
+type StoreCheck struct {
+	// Dir is the directory of the store.
+	Dir string
+	// Root is the root hash recorded by the manifest of the store.
+	Root hash.Hash
+	// Tables holds the result of verifying each table file referenced by the manifest.
+	Tables []TableFileCheck
+	// Chunks holds the address of every chunk of the store which could be located, whether or not it is corrupt.
+	Chunks hash.HashSet
+}
+
+// Damaged returns whether any table file of the store is damaged.
+func (c *StoreCheck) Damaged() bool {
+	for _, t := range c.Tables {
+		if t.Damaged() {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckLocalStore verifies every table file referenced by the manifest of the local store in |dir|. It validates the
+// index and footer of each table file, and reads every chunk to check that its contents hash to its address. The chunk
+// journal is checked in the same way. It returns a nil StoreCheck if there is no store in |dir|, and only returns an
+// error when the manifest itself cannot be read; damage to table files is recorded in the result. |keys| open the
+// table files and manifest of stores encrypted at rest, and may be nil.
+func CheckLocalStore(ctx context.Context, dir string, keys *Keyring) (*StoreCheck, error) {
+	exists, contents, err := fileManifest{dir, keys}.ParseIfExists(ctx, &Stats{}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, nil
+	}
+
+	check := &StoreCheck{
+		Dir:    dir,
+		Root:   contents.root,
+		Chunks: hash.NewHashSet(),
+	}
+
+	for _, spec := range contents.specs {
+		var tc TableFileCheck
+		if spec.name == journalAddr {
+			tc = checkJournal(filepath.Join(dir, spec.name.String()), spec.chunkCount, check.Chunks)
+		} else {
+			tc = checkTableFile(ctx, filepath.Join(dir, spec.name.String()), spec.chunkCount, keys, check.Chunks)
+		}
+		check.Tables = append(check.Tables, tc)
+	}
+
+	return check, nil
+}
+
+func checkTableFile(ctx context.Context, path string, chunkCount uint32, keys *Keyring, found hash.HashSet) TableFileCheck {
+	tc := TableFileCheck{Path: path, ChunkCount: chunkCount}
+
+	f, err := os.Open(path)
+	if err != nil {
+		tc.Err = err
+		return tc
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		tc.Err = err
+		return tc
+	}
+
+	var tra tableReaderAt = fileReaderAt{f}
+	size := info.Size()
+	env, err := openTableEnvelope(f, size, keys)
+	if err != nil {
+		tc.Err = err
+		return tc
+	} else if env != nil {
+		// the index of a sealed table file is read from its plaintext
+		tra = env.readerAt(tra)
+		size = int64(env.size)
+	}
+
+	index, err := ReadTableIndex(io.NewSectionReader(readerAtAdapter{ctx, tra}, 0, size))
+	if err != nil {
+		tc.Err = fmt.Errorf("invalid table file index or footer: %w", err)
+		return tc
+	}
+
+	if index.ChunkCount() != chunkCount {
+		tc.Err = fmt.Errorf("%w: table file has %d chunks, but the manifest records %d", ErrInvalidTableFile, index.ChunkCount(), chunkCount)
+		return tc
+	}
+
+	if index.TableFileSize() != uint64(size) {
+		tc.Err = fmt.Errorf("%w: table file is %d bytes, but its index describes %d bytes", ErrInvalidTableFile, size, index.TableFileSize())
+		return tc
+	}
+
+	tr := newTableReader(index, tra, fileBlockSize)
+
+	var ors offsetRecSlice
+	for i := uint32(0); i < index.ChunkCount(); i++ {
+		a := new(addr)
+		e := index.IndexEntry(i, a)
+		if index.Format() == tableFormatV2 && *a == tableDictAddr {
+			continue
+		}
+		ors = append(ors, offsetRec{a, e.Offset(), e.Length()})
+	}
+	sort.Sort(ors)
+
+	for _, or := range ors {
+		found.Insert(hash.Hash(*or.a))
+		if err := checkTableChunk(ctx, tr, or); err != nil {
+			tc.Corrupt = append(tc.Corrupt, CorruptChunk{Hash: hash.Hash(*or.a), Err: err})
+		}
+	}
+
+	return tc
+}
+
+func checkTableChunk(ctx context.Context, tr tableReader, or offsetRec) error {
+	if or.length < checksumSize {
+		return ErrInvalidTableFile
+	}
+
+	buff := make([]byte, or.length)
+	if _, err := tr.r.ReadAtWithStats(ctx, buff, int64(or.offset), &Stats{}); err != nil {
+		return err
+	}
+
+	cc, err := tr.parseChunk(ctx, *or.a, buff, &Stats{})
+	if err != nil {
+		return err
+	}
+
+	return checkCompressedChunk(cc)
+}
+
+func checkCompressedChunk(cc CompressedChunk) error {
+	chnk, err := cc.ToChunk()
+	if err != nil {
+		return err
+	}
+
+	if hash.Of(chnk.Data()) != cc.H {
+		return ErrChunkHashMismatch
+	}
+
+	return nil
+}
+
+func checkJournal(path string, chunkCount uint32, found hash.HashSet) TableFileCheck {
+	tc := TableFileCheck{Path: path, ChunkCount: chunkCount}
+
+	if _, err := os.Stat(path); err != nil {
+		tc.Err = err
+		return tc
+	}
+
+	wr, err := openJournalWriter(path)
+	if err != nil {
+		tc.Err = err
+		return tc
+	}
+	defer wr.Close()
+
+	for _, a := range wr.chunkAddrs() {
+		found.Insert(hash.Hash(a))
+
+		cc, _, err := wr.getCompressedChunk(a)
+		if err == nil {
+			err = checkCompressedChunk(cc)
+		}
+
+		if err != nil {
+			tc.Corrupt = append(tc.Corrupt, CorruptChunk{Hash: hash.Hash(a), Err: err})
+		}
+	}
+
+	return tc
+}
+
+// openTableEnvelope returns the envelope of the table file |f| of |size| bytes if it is sealed, and nil otherwise.
+func openTableEnvelope(f io.ReaderAt, size int64, keys *Keyring) (*tableEnvelope, error) {
+	if size < sealedTableTrailerSize {
+		return nil, nil
+	}
+
+	trailer, err := readFileTail(f, size)(sealedTableTrailerSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isSealedTable(trailer) {
+		return nil, nil
+	}
+
+	env, err := parseTableEnvelope(keys, trailer)
+	if err != nil {
+		return nil, err
+	}
+
+	if sealedTableSize(env.size) != uint64(size) {
+		return nil, fmt.Errorf("%w: sealed table file is %d bytes, but its trailer describes %d bytes", ErrInvalidTableFile, size, sealedTableSize(env.size))
+	}
+
+	return env, nil
+}
+
+// fileReaderAt reads a table file through an open file.
+type fileReaderAt struct {
+	f *os.File
+}
+
+func (fra fileReaderAt) ReadAtWithStats(ctx context.Context, p []byte, off int64, stats *Stats) (int, error) {
+	return fra.f.ReadAt(p, off)
+}
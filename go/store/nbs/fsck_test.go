@@ -0,0 +1,129 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nbs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/utils/file"
+	"github.com/dolthub/dolt/go/store/chunks"
+	"github.com/dolthub/dolt/go/store/constants"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+// writeFsckTestStore writes |chnks| to a new local store in |dir|, and returns the path of the table file holding them.
+func writeFsckTestStore(t *testing.T, dir string, chnks [][]byte) string {
+	ctx := context.Background()
+	store, err := NewLocalStore(ctx, constants.FormatDefaultString, dir, 1<<20)
+	require.NoError(t, err)
+	defer store.Close()
+
+	for _, c := range chnks {
+		require.NoError(t, store.Put(ctx, chunks.NewChunk(c)))
+	}
+	root, err := store.Root(ctx)
+	require.NoError(t, err)
+	ok, err := store.Commit(ctx, chunks.NewChunk(chnks[0]).Hash(), root)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, tableFiles, _, err := store.Sources(ctx)
+	require.NoError(t, err)
+	require.Len(t, tableFiles, 1)
+	return filepath.Join(dir, tableFiles[0].FileID())
+}
+
+func TestCheckLocalStore(t *testing.T) {
+	ctx := context.Background()
+	chnks := similarChunks(16)
+
+	t.Run("intact", func(t *testing.T) {
+		dir := makeTempDir(t)
+		defer file.RemoveAll(dir)
+		writeFsckTestStore(t, dir, chnks)
+
+		check, err := CheckLocalStore(ctx, dir, nil)
+		require.NoError(t, err)
+		assert.False(t, check.Damaged())
+		require.Len(t, check.Tables, 1)
+		assert.Equal(t, uint32(len(chnks)), check.Tables[0].ChunkCount)
+		assert.Len(t, check.Chunks, len(chnks))
+		for _, c := range chnks {
+			assert.True(t, check.Chunks.Has(hash.Of(c)))
+		}
+	})
+
+	t.Run("no store", func(t *testing.T) {
+		dir := makeTempDir(t)
+		defer file.RemoveAll(dir)
+
+		check, err := CheckLocalStore(ctx, dir, nil)
+		require.NoError(t, err)
+		assert.Nil(t, check)
+	})
+
+	t.Run("corrupt chunk", func(t *testing.T) {
+		dir := makeTempDir(t)
+		defer file.RemoveAll(dir)
+		path := writeFsckTestStore(t, dir, chnks)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		data[0] ^= 0xff
+		require.NoError(t, os.WriteFile(path, data, 0666))
+
+		check, err := CheckLocalStore(ctx, dir, nil)
+		require.NoError(t, err)
+		assert.True(t, check.Damaged())
+		require.Len(t, check.Tables, 1)
+		assert.NoError(t, check.Tables[0].Err)
+		assert.Len(t, check.Tables[0].Corrupt, 1)
+		assert.Len(t, check.Chunks, len(chnks))
+	})
+
+	t.Run("invalid footer", func(t *testing.T) {
+		dir := makeTempDir(t)
+		defer file.RemoveAll(dir)
+		path := writeFsckTestStore(t, dir, chnks)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		data[len(data)-1] ^= 0xff
+		require.NoError(t, os.WriteFile(path, data, 0666))
+
+		check, err := CheckLocalStore(ctx, dir, nil)
+		require.NoError(t, err)
+		assert.True(t, check.Damaged())
+		assert.ErrorIs(t, check.Tables[0].Err, ErrInvalidTableFile)
+	})
+
+	t.Run("missing table file", func(t *testing.T) {
+		dir := makeTempDir(t)
+		defer file.RemoveAll(dir)
+		path := writeFsckTestStore(t, dir, chnks)
+		require.NoError(t, os.Remove(path))
+
+		check, err := CheckLocalStore(ctx, dir, nil)
+		require.NoError(t, err)
+		assert.True(t, check.Damaged())
+		assert.True(t, os.IsNotExist(check.Tables[0].Err))
+	})
+}
@@ -47,8 +47,8 @@ type gcCopier struct {
 	writer *CmpChunkTableWriter
 }
 
-func newGarbageCollectionCopier() (*gcCopier, error) {
-	writer, err := NewCmpChunkTableWriter("")
+func newGarbageCollectionCopier(compression TableFileCompression) (*gcCopier, error) {
+	writer, err := NewCmpChunkTableWriterWithCompression("", compression)
 	if err != nil {
 		return nil, err
 	}
@@ -59,7 +59,8 @@ func (gcc *gcCopier) addChunk(ctx context.Context, c CompressedChunk) error {
 	return gcc.writer.AddCmpChunk(c)
 }
 
-func (gcc *gcCopier) copyTablesToDir(ctx context.Context, destDir string) ([]tableSpec, error) {
+// copyTablesToDir writes the table file of the copied chunks to |destDir|, sealed with |keys| unless they are nil.
+func (gcc *gcCopier) copyTablesToDir(ctx context.Context, destDir string, keys *Keyring) ([]tableSpec, error) {
 	filename, err := gcc.writer.Finish()
 	if err != nil {
 		return nil, err
@@ -76,11 +77,21 @@ func (gcc *gcCopier) copyTablesToDir(ctx context.Context, destDir string) ([]tab
 		return nil, err
 	}
 
+	size := gcc.writer.ContentLength()
+	if keys != nil {
+		size = sealedTableSize(size)
+	}
+
 	if info, err := os.Stat(filepath); err == nil {
 		// file already exists
-		if gcc.writer.ContentLength() != uint64(info.Size()) {
+		if size != uint64(info.Size()) {
 			return nil, fmt.Errorf("'%s' already exists with different contents.", filepath)
 		}
+	} else if keys != nil {
+		err = writeSealedTableFile(destDir, filepath, keys, gcc.writer.Flush)
+		if err != nil {
+			return nil, err
+		}
 	} else {
 		// file does not exist or error determining if it existed.  Try to create it.
 		err = gcc.writer.FlushToFile(filepath)
@@ -16,6 +16,7 @@ package nbs
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"sync"
 
@@ -30,6 +31,9 @@ var _ TableFileStore = (*GenerationalNBS)(nil)
 type GenerationalNBS struct {
 	oldGen *NomsBlockStore
 	newGen *NomsBlockStore
+
+	fetcherMu sync.RWMutex
+	fetcher   chunks.ChunkFetcher
 }
 
 func NewGenerationalCS(oldGen, newGen *NomsBlockStore) *GenerationalNBS {
@@ -51,6 +55,54 @@ func (gcs *GenerationalNBS) OldGen() chunks.ChunkStoreGarbageCollector {
 	return gcs.oldGen
 }
 
+// SetChunkFetcher sets a ChunkFetcher which the chunks the store does not hold are read from, such as the remote a
+// partial clone was cloned from. The chunks fetched are put into the new gen store, and are persisted by its next
+// commit. Has and HasMany only report the chunks the store holds. A nil |fetcher| stops the store fetching chunks.
+func (gcs *GenerationalNBS) SetChunkFetcher(fetcher chunks.ChunkFetcher) {
+	gcs.fetcherMu.Lock()
+	defer gcs.fetcherMu.Unlock()
+	gcs.fetcher = fetcher
+}
+
+// fetchMissing fetches the chunks |missing| with the ChunkFetcher of the store, if it has one, putting each chunk
+// fetched into the new gen store before passing it to |found|. A fetched chunk which was not requested, or whose
+// contents do not match its address, fails the fetch and is not put into the store.
+func (gcs *GenerationalNBS) fetchMissing(ctx context.Context, missing hash.HashSet, found func(context.Context, *chunks.Chunk)) error {
+	gcs.fetcherMu.RLock()
+	fetcher := gcs.fetcher
+	gcs.fetcherMu.RUnlock()
+
+	if fetcher == nil || len(missing) == 0 {
+		return nil
+	}
+
+	mu := &sync.Mutex{}
+	var putErr error
+	err := fetcher.GetMany(ctx, missing, func(ctx context.Context, chunk *chunks.Chunk) {
+		var err error
+		if h := chunk.Hash(); !missing.Has(h) || hash.Of(chunk.Data()) != h {
+			err = fmt.Errorf("%w: fetched chunk %s", ErrChunkHashMismatch, h.String())
+		} else {
+			err = gcs.newGen.Put(ctx, *chunk)
+		}
+
+		if err != nil {
+			mu.Lock()
+			defer mu.Unlock()
+			putErr = err
+			return
+		}
+
+		found(ctx, chunk)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return putErr
+}
+
 // Get the Chunk for the value of the hash in the store. If the hash is absent from the store EmptyChunk is returned.
 func (gcs *GenerationalNBS) Get(ctx context.Context, h hash.Hash) (chunks.Chunk, error) {
 	c, err := gcs.oldGen.Get(ctx, h)
@@ -60,7 +112,21 @@ func (gcs *GenerationalNBS) Get(ctx context.Context, h hash.Hash) (chunks.Chunk,
 	}
 
 	if c.IsEmpty() {
-		return gcs.newGen.Get(ctx, h)
+		c, err = gcs.newGen.Get(ctx, h)
+
+		if err != nil {
+			return chunks.EmptyChunk, err
+		}
+	}
+
+	if c.IsEmpty() {
+		err = gcs.fetchMissing(ctx, hash.NewHashSet(h), func(_ context.Context, chunk *chunks.Chunk) {
+			c = *chunk
+		})
+
+		if err != nil {
+			return chunks.EmptyChunk, err
+		}
 	}
 
 	return c, nil
@@ -89,7 +155,22 @@ func (gcs *GenerationalNBS) GetMany(ctx context.Context, hashes hash.HashSet, fo
 		return nil
 	}
 
-	return gcs.newGen.GetMany(ctx, notInOldGen, found)
+	missing := notInOldGen.Copy()
+	err = gcs.newGen.GetMany(ctx, notInOldGen, func(ctx context.Context, chunk *chunks.Chunk) {
+		func() {
+			mu.Lock()
+			defer mu.Unlock()
+			delete(missing, chunk.Hash())
+		}()
+
+		found(ctx, chunk)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return gcs.fetchMissing(ctx, missing, found)
 }
 
 func (gcs *GenerationalNBS) GetManyCompressed(ctx context.Context, hashes hash.HashSet, found func(context.Context, CompressedChunk)) error {
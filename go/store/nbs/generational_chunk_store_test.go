@@ -171,3 +171,88 @@ func TestGenerationalCS(t *testing.T) {
 	putChunks(t, ctx, chnks, cs, inNew, 15, 16, 17, 18, 19)
 	requireChunks(t, ctx, chnks, cs, inOld, inNew)
 }
+
+func TestGenerationalCSChunkFetcher(t *testing.T) {
+	ctx := context.Background()
+	oldGen, _ := makeTestLocalStore(t, 64)
+	newGen, _ := makeTestLocalStore(t, 64)
+	inOld := make(map[int]bool)
+	inNew := make(map[int]bool)
+	chnks := genChunks(t, 20, 1000)
+
+	putChunks(t, ctx, chnks, oldGen, inOld, 0, 1)
+	putChunks(t, ctx, chnks, newGen, inNew, 2, 3)
+
+	remote := chunks.NewMemoryStoreFactory().CreateStore(ctx, "remote")
+	for _, c := range chnks[:10] {
+		require.NoError(t, remote.Put(ctx, c))
+	}
+
+	cs := NewGenerationalCS(oldGen, newGen)
+	cs.SetChunkFetcher(remote)
+
+	// chunks the store does not hold are fetched, and are then held by the new gen store
+	c, err := cs.Get(ctx, chnks[4].Hash())
+	require.NoError(t, err)
+	require.Equal(t, chnks[4].Data(), c.Data())
+	inNew[4] = true
+
+	expected := hashesForChunks(chnks, map[int]bool{0: true, 2: true, 5: true, 6: true, 15: true})
+	received := foundHashes{}
+	err = cs.GetMany(ctx, expected, received.found)
+	require.NoError(t, err)
+	expected.Remove(chnks[15].Hash())
+	require.Equal(t, expected, hash.HashSet(received))
+	inNew[5], inNew[6] = true, true
+
+	c, err = cs.Get(ctx, chnks[15].Hash())
+	require.NoError(t, err)
+	require.True(t, c.IsEmpty())
+
+	cs.SetChunkFetcher(nil)
+	requireChunks(t, ctx, chnks, cs, inOld, inNew)
+
+	c, err = cs.Get(ctx, chnks[7].Hash())
+	require.NoError(t, err)
+	require.True(t, c.IsEmpty())
+}
+
+// corruptingFetcher is a ChunkFetcher which returns garbage for every chunk it fetches.
+type corruptingFetcher struct {
+	chunks.ChunkFetcher
+}
+
+func (f corruptingFetcher) GetMany(ctx context.Context, hashes hash.HashSet, found func(context.Context, *chunks.Chunk)) error {
+	return f.ChunkFetcher.GetMany(ctx, hashes, func(ctx context.Context, c *chunks.Chunk) {
+		garbage := chunks.NewChunkWithHash(c.Hash(), []byte("garbage"))
+		found(ctx, &garbage)
+	})
+}
+
+func TestGenerationalCSChunkFetcherVerifiesChunks(t *testing.T) {
+	ctx := context.Background()
+	oldGen, _ := makeTestLocalStore(t, 64)
+	newGen, _ := makeTestLocalStore(t, 64)
+	chnks := genChunks(t, 4, 1000)
+
+	remote := chunks.NewMemoryStoreFactory().CreateStore(ctx, "remote")
+	for _, c := range chnks {
+		require.NoError(t, remote.Put(ctx, c))
+	}
+
+	cs := NewGenerationalCS(oldGen, newGen)
+	cs.SetChunkFetcher(corruptingFetcher{remote})
+
+	_, err := cs.Get(ctx, chnks[0].Hash())
+	require.ErrorIs(t, err, ErrChunkHashMismatch)
+
+	err = cs.GetMany(ctx, hashesForChunks(chnks, map[int]bool{1: true, 2: true}), foundHashes{}.found)
+	require.ErrorIs(t, err, ErrChunkHashMismatch)
+
+	// the chunks which failed verification were not stored
+	for _, c := range chnks {
+		ok, err := newGen.Has(ctx, c.Hash())
+		require.NoError(t, err)
+		require.False(t, ok)
+	}
+}
@@ -0,0 +1,520 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nbs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/dolthub/fslock"
+
+	"github.com/dolthub/dolt/go/store/chunks"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+const (
+	journalLockFileName = "JOURNAL_LOCK"
+
+	// defaultJournalCompactionSize is the size, in bytes, past which a chunk journal is compacted into a table file.
+	defaultJournalCompactionSize = 1 << 27 // 128MB
+)
+
+// journalAddr is the name of the chunk journal in manifests, and the name of its file.
+var journalAddr = addr(hash.Parse("vvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvv"))
+
+var ErrJournalLocked = errors.New("the chunk journal is in use by another process")
+var errJournalManifestChanged = errors.New("the manifest of a store with a chunk journal was changed by another process")
+
+// chunkJournal is the tablePersister and manifest of a local store with a write-ahead chunk journal. Instead of
+// writing a table file for every memTable it persists, and rewriting the manifest for every root it commits, it
+// appends the compressed chunks and the root hash to a single journal file, so that a commit costs a single sync.
+//
+// The table files of the store and its manifest file remain the source of truth for everything but the chunks in the
+// journal and the root hash. The journal is listed in the manifest as a table named |journalAddr|, and the manifest
+// file is only rewritten when the set of tables changes. When the store is opened, the journal is replayed, and its
+// latest root hash takes the place of the root hash of the manifest file. Once the journal grows past its compaction
+// size, the store writes its chunks into a table file, replaces the journal with the table file in the manifest, and
+// truncates the journal.
+//
+// Only a single process may write to a journal at a time, which it locks on its first write. Other processes may read
+// the journal meanwhile, and catch up on the records appended to it whenever they read the manifest. Stores of the
+// same process share the chunkJournal of their directory.
+type chunkJournal struct {
+	dir       string
+	wr        *journalWriter
+	src       journalChunkSource
+	lock      *fslock.Lock
+	backing   manifest
+	persister *fsTablePersister
+	maxSize   int64
+
+	mu          sync.Mutex // protects the following state
+	writing     bool
+	exists      bool
+	contents    manifestContents
+	backingLock addr
+	refs        int
+}
+
+var _ tablePersister = &chunkJournal{}
+var _ manifest = &chunkJournal{}
+var _ manifestGCGenUpdater = &chunkJournal{}
+
+var openJournals = struct {
+	mu       sync.Mutex
+	journals map[string]*chunkJournal
+}{journals: map[string]*chunkJournal{}}
+
+// journalExists returns whether the store in |dir| has a chunk journal.
+func journalExists(dir string) (bool, error) {
+	_, err := os.Stat(filepath.Join(dir, journalAddr.String()))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// openChunkJournal returns the chunk journal of the store in |dir|, creating it if it does not exist. The journal
+// must be closed when the store is closed.
+func openChunkJournal(ctx context.Context, dir string, backing manifest, p *fsTablePersister) (*chunkJournal, error) {
+	key, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	openJournals.mu.Lock()
+	defer openJournals.mu.Unlock()
+
+	if j, ok := openJournals.journals[key]; ok {
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		j.refs++
+		return j, nil
+	}
+
+	wr, err := openJournalWriter(filepath.Join(dir, journalAddr.String()))
+	if err != nil {
+		return nil, err
+	}
+
+	j := &chunkJournal{
+		dir:       dir,
+		wr:        wr,
+		src:       journalChunkSource{wr: wr},
+		backing:   backing,
+		persister: p,
+		maxSize:   defaultJournalCompactionSize,
+		refs:      1,
+	}
+
+	if err = j.load(ctx); err != nil {
+		wr.Close()
+		return nil, err
+	}
+
+	openJournals.journals[key] = j
+	return j, nil
+}
+
+// load reads the manifest file of the store and applies the replayed journal to it.
+func (j *chunkJournal) load(ctx context.Context) error {
+	exists, contents, err := j.backing.ParseIfExists(ctx, &Stats{}, nil)
+	if err != nil {
+		return err
+	}
+
+	j.exists, j.backingLock = exists, contents.lock
+	if !exists {
+		if j.wr.chunkCount() > 0 {
+			return fmt.Errorf("%w: chunk journal of %s has no manifest", ErrCorruptManifest, j.dir)
+		}
+		j.contents = contents
+		return nil
+	}
+
+	changed := false
+	if root, ok := j.wr.latestRoot(); ok && root != contents.root {
+		contents.root, changed = root, true
+	}
+
+	// the chunk count of the journal in the manifest is only up to date when the manifest was last written, and the
+	// journal may not be listed at all if the store stopped before listing it, or after compacting it
+	specs := make([]tableSpec, 0, len(contents.specs)+1)
+	if cnt := j.wr.chunkCount(); cnt > 0 {
+		specs = append(specs, tableSpec{name: journalAddr, chunkCount: cnt})
+	}
+	for _, spec := range contents.specs {
+		if spec.name != journalAddr {
+			specs = append(specs, spec)
+		}
+	}
+	if !sameTables(specs, contents.specs) {
+		changed = true
+	}
+	contents.specs = specs
+
+	if changed {
+		contents.lock = generateLockHash(contents.root, contents.specs, contents.appendix)
+	}
+
+	j.contents = contents
+	return nil
+}
+
+// startWriting locks the journal for writing by this process, if it has not yet, and catches up on the records
+// written to it before it was locked.
+//
+// callers must acquire lock |j.mu|
+func (j *chunkJournal) startWriting(ctx context.Context) error {
+	if j.writing {
+		return nil
+	}
+
+	lock := fslock.New(filepath.Join(j.dir, journalLockFileName))
+	if err := lock.TryLock(); errors.Is(err, fslock.ErrLocked) {
+		return ErrJournalLocked
+	} else if err != nil {
+		return err
+	}
+
+	err := j.wr.replay()
+	if err == nil {
+		err = j.wr.truncateTail()
+	}
+	if err == nil {
+		err = j.load(ctx)
+	}
+	if err != nil {
+		lock.Unlock()
+		return err
+	}
+
+	j.lock, j.writing = lock, true
+	return nil
+}
+
+// Name implements manifest.
+func (j *chunkJournal) Name() string {
+	return j.backing.Name()
+}
+
+// ParseIfExists implements manifest. Unless this process writes to the journal, the records appended to it by the
+// process which does are read first.
+func (j *chunkJournal) ParseIfExists(ctx context.Context, stats *Stats, readHook func() error) (bool, manifestContents, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if readHook != nil {
+		if err := readHook(); err != nil {
+			return false, manifestContents{}, err
+		}
+	}
+
+	if !j.writing {
+		if err := j.wr.replay(); err != nil {
+			return false, manifestContents{}, err
+		}
+		if err := j.load(ctx); err != nil {
+			return false, manifestContents{}, err
+		}
+	}
+
+	return j.exists, j.contents, nil
+}
+
+// Update implements manifest. Updates which only change the root hash are appended to the journal, all others are
+// written to the manifest file as well.
+func (j *chunkJournal) Update(ctx context.Context, lastLock addr, next manifestContents, stats *Stats, writeHook func() error) (manifestContents, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.startWriting(ctx); err != nil {
+		return manifestContents{}, err
+	}
+
+	if lastLock != j.contents.lock {
+		return j.contents, nil
+	}
+
+	if writeHook != nil {
+		if err := writeHook(); err != nil {
+			return manifestContents{}, err
+		}
+	}
+
+	if j.exists && j.onlyRootChanged(next) {
+		if err := j.wr.writeRootHash(next.root); err != nil {
+			return manifestContents{}, err
+		}
+	} else {
+		err := j.updateBacking(func() (manifestContents, error) {
+			return j.backing.Update(ctx, j.backingLock, next, stats, nil)
+		}, next)
+
+		if err != nil {
+			return manifestContents{}, err
+		}
+	}
+
+	j.exists, j.contents = true, next
+	return next, nil
+}
+
+// UpdateGCGen implements manifestGCGenUpdater.
+func (j *chunkJournal) UpdateGCGen(ctx context.Context, lastLock addr, next manifestContents, stats *Stats, writeHook func() error) (manifestContents, error) {
+	updater, ok := j.backing.(manifestGCGenUpdater)
+	if !ok {
+		return manifestContents{}, errors.New("manifest does not support updating gc gen")
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.startWriting(ctx); err != nil {
+		return manifestContents{}, err
+	}
+
+	if lastLock != j.contents.lock {
+		return j.contents, nil
+	}
+
+	// the manifest file may hold an older root than the journal, which must be brought up to date first, as the root
+	// cannot change along with the gc generation
+	if j.exists && j.backingLock != j.contents.lock {
+		err := j.updateBacking(func() (manifestContents, error) {
+			return j.backing.Update(ctx, j.backingLock, j.contents, stats, nil)
+		}, j.contents)
+
+		if err != nil {
+			return manifestContents{}, err
+		}
+	}
+
+	err := j.updateBacking(func() (manifestContents, error) {
+		return updater.UpdateGCGen(ctx, j.backingLock, next, stats, writeHook)
+	}, next)
+
+	if err != nil {
+		return manifestContents{}, err
+	}
+
+	j.exists, j.contents = true, next
+	return next, nil
+}
+
+// updateBacking writes |next| to the manifest file using |update|, and then records the root hash of |next| in the
+// journal. When |next| no longer lists the journal, the chunks of the journal were written to a table file, and the
+// journal is truncated.
+//
+// callers must acquire lock |j.mu|
+func (j *chunkJournal) updateBacking(update func() (manifestContents, error), next manifestContents) error {
+	upstream, err := update()
+	if err != nil {
+		return err
+	}
+
+	if upstream.lock != next.lock {
+		return errJournalManifestChanged
+	}
+	j.backingLock = next.lock
+
+	if !hasJournalSpec(next.specs) && j.wr.chunkCount() > 0 {
+		return j.wr.truncate(next.root)
+	}
+
+	return j.wr.writeRootHash(next.root)
+}
+
+// onlyRootChanged returns whether |next| only differs from the current contents by its root hash.
+//
+// callers must acquire lock |j.mu|
+func (j *chunkJournal) onlyRootChanged(next manifestContents) bool {
+	curr := j.contents
+	return curr.nbfVers == next.nbfVers &&
+		curr.gcGen == next.gcGen &&
+		sameTables(curr.specs, next.specs) &&
+		sameTables(curr.appendix, next.appendix)
+}
+
+// sameTables returns whether |a| and |b| list the same tables in the same order. The chunk count of the journal is
+// not compared, as it changes with every chunk written to it.
+func sameTables(a, b []tableSpec) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i].name != b[i].name {
+			return false
+		}
+		if a[i].name != journalAddr && a[i].chunkCount != b[i].chunkCount {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hasJournalSpec(specs []tableSpec) bool {
+	for _, spec := range specs {
+		if spec.name == journalAddr {
+			return true
+		}
+	}
+	return false
+}
+
+// Persist implements tablePersister. The chunks of |mt| which |haver| does not have are appended to the journal, and
+// the journal is returned as their chunkSource.
+func (j *chunkJournal) Persist(ctx context.Context, mt *memTable, haver chunkReader, stats *Stats) (chunkSource, error) {
+	if err := func() error {
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		return j.startWriting(ctx)
+	}(); err != nil {
+		return nil, err
+	}
+
+	if haver != nil {
+		sort.Sort(hasRecordByPrefix(mt.order)) // hasMany() requires addresses to be sorted.
+		if _, err := haver.hasMany(mt.order); err != nil {
+			return nil, err
+		}
+		sort.Sort(hasRecordByOrder(mt.order)) // restore "insertion" order for write
+	}
+
+	ccs := make([]CompressedChunk, 0, len(mt.order))
+	for _, rec := range mt.order {
+		if rec.has {
+			continue
+		}
+		ccs = append(ccs, ChunkToCompressedChunk(chunks.NewChunkWithHash(hash.Hash(*rec.a), mt.chunks[*rec.a])))
+	}
+
+	if err := j.wr.writeChunks(ccs); err != nil {
+		return nil, err
+	}
+
+	if len(ccs) > 0 {
+		stats.ChunksPerPersist.Sample(uint64(len(ccs)))
+	}
+
+	return j.src, nil
+}
+
+// ConjoinAll implements tablePersister. The journal is never conjoined, see conjoinTables.
+func (j *chunkJournal) ConjoinAll(ctx context.Context, sources chunkSources, stats *Stats) (chunkSource, error) {
+	return j.persister.ConjoinAll(ctx, sources, stats)
+}
+
+// Open implements tablePersister.
+func (j *chunkJournal) Open(ctx context.Context, name addr, chunkCount uint32, stats *Stats) (chunkSource, error) {
+	if name == journalAddr {
+		return j.src, nil
+	}
+	return j.persister.Open(ctx, name, chunkCount, stats)
+}
+
+// PruneTableFiles implements tablePersister. The journal file is never pruned.
+func (j *chunkJournal) PruneTableFiles(ctx context.Context, contents manifestContents) error {
+	if !hasJournalSpec(contents.specs) {
+		specs := append([]tableSpec{{name: journalAddr}}, contents.specs...)
+		contents.specs = specs
+	}
+	return j.persister.PruneTableFiles(ctx, contents)
+}
+
+// compactionRequired returns whether the journal has grown past its compaction size.
+func (j *chunkJournal) compactionRequired() bool {
+	return j.wr.size() > j.maxSize
+}
+
+// writeTableFile writes the chunks of the journal to a new table file and returns its spec. It returns false if the
+// journal holds no chunks.
+func (j *chunkJournal) writeTableFile(ctx context.Context) (tableSpec, bool, error) {
+	addrs := j.wr.chunkAddrs()
+	if len(addrs) == 0 {
+		return tableSpec{}, false, nil
+	}
+
+	tw, err := NewCmpChunkTableWriterWithCompression(j.dir, j.persister.compression)
+	if err != nil {
+		return tableSpec{}, false, err
+	}
+
+	for _, a := range addrs {
+		cc, ok, err := j.wr.getCompressedChunk(a)
+		if err != nil {
+			return tableSpec{}, false, err
+		} else if !ok {
+			return tableSpec{}, false, fmt.Errorf("chunk %s missing from the chunk journal", a.String())
+		}
+
+		if err = tw.AddCmpChunk(cc); err != nil {
+			return tableSpec{}, false, err
+		}
+	}
+
+	name, err := tw.Finish()
+	if err != nil {
+		return tableSpec{}, false, err
+	}
+
+	if err = tw.FlushToFile(filepath.Join(j.dir, name)); err != nil {
+		return tableSpec{}, false, err
+	}
+
+	a, err := parseAddr(name)
+	if err != nil {
+		return tableSpec{}, false, err
+	}
+
+	return tableSpec{name: a, chunkCount: tw.ChunkCount()}, true, nil
+}
+
+// Close releases the journal of one of the stores using it, and closes it once it is released by all of them.
+func (j *chunkJournal) Close() error {
+	openJournals.mu.Lock()
+	defer openJournals.mu.Unlock()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.refs--
+	if j.refs > 0 {
+		return nil
+	}
+
+	for key, open := range openJournals.journals {
+		if open == j {
+			delete(openJournals.journals, key)
+		}
+	}
+
+	err := j.wr.Close()
+	if j.writing {
+		if unlockErr := j.lock.Unlock(); err == nil {
+			err = unlockErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,271 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nbs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sort"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/dolthub/dolt/go/store/chunks"
+)
+
+var errJournalNotTableFile = errors.New("the chunk journal cannot be read as a table file")
+
+// journalChunkSource is the chunkSource of the chunks in a chunk journal. There is a single journalChunkSource for a
+// journal, which reflects every chunk written to it, and which is named |journalAddr| in manifests.
+type journalChunkSource struct {
+	wr *journalWriter
+}
+
+var _ chunkSource = journalChunkSource{}
+
+func (s journalChunkSource) has(h addr) (bool, error) {
+	return s.wr.has(h), nil
+}
+
+func (s journalChunkSource) hasMany(addrs []hasRecord) (bool, error) {
+	var remaining bool
+	for i := range addrs {
+		if addrs[i].has {
+			continue
+		}
+
+		if s.wr.has(*addrs[i].a) {
+			addrs[i].has = true
+		} else {
+			remaining = true
+		}
+	}
+	return remaining, nil
+}
+
+func (s journalChunkSource) get(ctx context.Context, h addr, stats *Stats) ([]byte, error) {
+	cc, ok, err := s.wr.getCompressedChunk(h)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	ch, err := cc.ToChunk()
+	if err != nil {
+		return nil, err
+	}
+
+	return ch.Data(), nil
+}
+
+func (s journalChunkSource) getMany(ctx context.Context, eg *errgroup.Group, reqs []getRecord, found func(context.Context, *chunks.Chunk), stats *Stats) (bool, error) {
+	return s.getRequested(reqs, func(cc CompressedChunk) error {
+		ch, err := cc.ToChunk()
+		if err != nil {
+			return err
+		}
+		found(ctx, &ch)
+		return nil
+	})
+}
+
+func (s journalChunkSource) getManyCompressed(ctx context.Context, eg *errgroup.Group, reqs []getRecord, found func(context.Context, CompressedChunk), stats *Stats) (bool, error) {
+	return s.getRequested(reqs, func(cc CompressedChunk) error {
+		found(ctx, cc)
+		return nil
+	})
+}
+
+// getRequested calls |cb| with each chunk of |reqs| which is in the journal and was not found yet, and returns whether
+// any chunks remain to be found.
+func (s journalChunkSource) getRequested(reqs []getRecord, cb func(CompressedChunk) error) (bool, error) {
+	var remaining bool
+	for i := range reqs {
+		if reqs[i].found {
+			continue
+		}
+
+		cc, ok, err := s.wr.getCompressedChunk(*reqs[i].a)
+		if err != nil {
+			return true, err
+		} else if !ok {
+			remaining = true
+			continue
+		}
+
+		reqs[i].found = true
+		if err = cb(cc); err != nil {
+			return true, err
+		}
+	}
+	return remaining, nil
+}
+
+func (s journalChunkSource) extract(ctx context.Context, chunks chan<- extractRecord) error {
+	for _, a := range s.wr.chunkAddrs() {
+		data, err := s.get(ctx, a, &Stats{})
+		if err != nil {
+			return err
+		}
+		chunks <- extractRecord{a: a, data: data}
+	}
+	return nil
+}
+
+func (s journalChunkSource) count() (uint32, error) {
+	return s.wr.chunkCount(), nil
+}
+
+func (s journalChunkSource) uncompressedLen() (uint64, error) {
+	return s.wr.uncompressedLen(), nil
+}
+
+func (s journalChunkSource) hash() (addr, error) {
+	return journalAddr, nil
+}
+
+// calcReads implements chunkSource. Every chunk found in the journal is counted as a read of its own.
+func (s journalChunkSource) calcReads(reqs []getRecord, blockSize uint64) (reads int, remaining bool, err error) {
+	for _, req := range reqs {
+		if req.found {
+			continue
+		}
+		if s.wr.has(*req.a) {
+			reads++
+		} else {
+			remaining = true
+		}
+	}
+	return reads, remaining, nil
+}
+
+// reader implements chunkSource. The journal is not a table file, so it must be compacted into one before its chunks
+// can be read as a table file.
+func (s journalChunkSource) reader(context.Context) (io.Reader, error) {
+	return nil, errJournalNotTableFile
+}
+
+func (s journalChunkSource) index() (tableIndex, error) {
+	return newJournalIndex(s.wr), nil
+}
+
+// Clone implements chunkSource. The journal is owned by its chunkJournal, so there is nothing to clone.
+func (s journalChunkSource) Clone() chunkSource {
+	return s
+}
+
+// Close implements chunkSource. The journal is owned by its chunkJournal, which closes it.
+func (s journalChunkSource) Close() error {
+	return nil
+}
+
+// journalIndex is a tableIndex of a snapshot of the chunks in a chunk journal. Its entries locate the compressed
+// data of chunks within the journal file.
+type journalIndex struct {
+	addrs    []addr // sorted by address
+	entries  []indexResult
+	ordinals []uint32
+	prefixes []uint64
+	size     uint64
+	uncmpLen uint64
+}
+
+var _ tableIndex = journalIndex{}
+
+func newJournalIndex(wr *journalWriter) journalIndex {
+	wr.mu.RLock()
+	defer wr.mu.RUnlock()
+
+	idx := journalIndex{
+		addrs:    append([]addr{}, wr.order...),
+		entries:  make([]indexResult, len(wr.order)),
+		ordinals: make([]uint32, len(wr.order)),
+		prefixes: make([]uint64, len(wr.order)),
+		size:     uint64(wr.off),
+		uncmpLen: wr.uncmpLen,
+	}
+
+	order := make(map[addr]uint32, len(wr.order))
+	for i, a := range wr.order {
+		order[a] = uint32(i)
+	}
+
+	sort.Slice(idx.addrs, func(i, j int) bool {
+		return bytes.Compare(idx.addrs[i][:], idx.addrs[j][:]) < 0
+	})
+	for i, a := range idx.addrs {
+		r := wr.ranges[a]
+		idx.entries[i] = indexResult{o: r.Offset, l: r.Length}
+		idx.prefixes[i] = a.Prefix()
+		idx.ordinals[order[a]] = uint32(i)
+	}
+
+	return idx
+}
+
+func (idx journalIndex) ChunkCount() uint32 {
+	return uint32(len(idx.addrs))
+}
+
+func (idx journalIndex) EntrySuffixMatches(i uint32, h *addr) bool {
+	return bytes.Equal(idx.addrs[i][addrPrefixSize:], h[addrPrefixSize:])
+}
+
+func (idx journalIndex) IndexEntry(i uint32, a *addr) indexEntry {
+	if a != nil {
+		*a = idx.addrs[i]
+	}
+	return idx.entries[i]
+}
+
+func (idx journalIndex) Lookup(h *addr) (indexEntry, bool) {
+	i := sort.Search(len(idx.addrs), func(i int) bool {
+		return bytes.Compare(idx.addrs[i][:], h[:]) >= 0
+	})
+	if i < len(idx.addrs) && idx.addrs[i] == *h {
+		return idx.entries[i], true
+	}
+	return indexResult{}, false
+}
+
+func (idx journalIndex) Ordinals() []uint32 {
+	return idx.ordinals
+}
+
+func (idx journalIndex) Prefixes() []uint64 {
+	return idx.prefixes
+}
+
+func (idx journalIndex) TableFileSize() uint64 {
+	return idx.size
+}
+
+func (idx journalIndex) TotalUncompressedData() uint64 {
+	return idx.uncmpLen
+}
+
+// Format implements tableIndex. The records of the journal hold chunks compressed as in table files of the original
+// format.
+func (idx journalIndex) Format() tableFormat {
+	return tableFormatV1
+}
+
+func (idx journalIndex) Close() error {
+	return nil
+}
+
+func (idx journalIndex) Clone() tableIndex {
+	return idx
+}
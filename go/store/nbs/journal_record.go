@@ -0,0 +1,148 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nbs
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// A chunk journal is a sequence of records, each laid out as
+//
+//   | length (uint32) | kind (uint8) | address (20 bytes) | payload | checksum (uint32) |
+//
+// where |length| is the length of the entire record, and |checksum| is the crc of every byte of the record preceding
+// it. Chunk records hold the address of a chunk and its compressed data, in the same format as in table files. Root
+// hash records hold the root hash of the store and have no payload.
+
+type journalRecordKind uint8
+
+const (
+	unknownJournalRecord journalRecordKind = iota
+	chunkJournalRecord
+	rootHashJournalRecord
+)
+
+const (
+	journalRecLenSize      = uint32Size
+	journalRecKindSize     = 1
+	journalRecAddrOffset   = journalRecLenSize + journalRecKindSize
+	journalRecPayloadStart = journalRecAddrOffset + addrSize
+	journalRecChecksumSize = checksumSize
+
+	// minJournalRecordSize is the size of a record without payload.
+	minJournalRecordSize = journalRecPayloadStart + journalRecChecksumSize
+
+	// maxJournalRecordSize bounds the length of records read from a journal, so that a corrupt length is never
+	// mistaken for a huge record.
+	maxJournalRecordSize = 1 << 30
+)
+
+var errCorruptJournalRecord = errors.New("corrupt chunk journal record")
+
+type journalRecord struct {
+	kind    journalRecordKind
+	address addr
+	payload []byte
+}
+
+// journalRecordSize returns the length of a record with a payload of |payloadLen| bytes.
+func journalRecordSize(payloadLen int) uint32 {
+	return uint32(minJournalRecordSize + payloadLen)
+}
+
+// writeChunkRecord writes a chunk record for |cc| to the beginning of |buf|, which must be large enough to hold it,
+// and returns the number of bytes written.
+func writeChunkRecord(buf []byte, cc CompressedChunk) uint32 {
+	return writeJournalRecord(buf, chunkJournalRecord, addr(cc.H), cc.FullCompressedChunk)
+}
+
+// writeRootHashRecord writes a root hash record for |root| to the beginning of |buf|, which must be large enough to
+// hold it, and returns the number of bytes written.
+func writeRootHashRecord(buf []byte, root addr) uint32 {
+	return writeJournalRecord(buf, rootHashJournalRecord, root, nil)
+}
+
+func writeJournalRecord(buf []byte, kind journalRecordKind, a addr, payload []byte) uint32 {
+	n := journalRecordSize(len(payload))
+	binary.BigEndian.PutUint32(buf, n)
+	buf[journalRecLenSize] = byte(kind)
+	copy(buf[journalRecAddrOffset:], a[:])
+	copy(buf[journalRecPayloadStart:], payload)
+	sumOffset := n - journalRecChecksumSize
+	binary.BigEndian.PutUint32(buf[sumOffset:], crc(buf[:sumOffset]))
+	return n
+}
+
+// readJournalRecordLength returns the length of the record starting with |buf|, which must hold at least the length
+// of the record.
+func readJournalRecordLength(buf []byte) (uint32, error) {
+	n := binary.BigEndian.Uint32(buf)
+	if n < minJournalRecordSize || n > maxJournalRecordSize {
+		return 0, fmt.Errorf("%w: invalid length %d", errCorruptJournalRecord, n)
+	}
+	return n, nil
+}
+
+// readJournalRecord parses the record |buf|, which must hold exactly one record, and validates its checksum. The
+// payload of the result aliases |buf|.
+func readJournalRecord(buf []byte) (journalRecord, error) {
+	if len(buf) < minJournalRecordSize {
+		return journalRecord{}, errCorruptJournalRecord
+	}
+
+	sumOffset := len(buf) - journalRecChecksumSize
+	if binary.BigEndian.Uint32(buf[sumOffset:]) != crc(buf[:sumOffset]) {
+		return journalRecord{}, fmt.Errorf("%w: checksum mismatch", errCorruptJournalRecord)
+	}
+
+	rec := journalRecord{
+		kind:    journalRecordKind(buf[journalRecLenSize]),
+		payload: buf[journalRecPayloadStart:sumOffset],
+	}
+	copy(rec.address[:], buf[journalRecAddrOffset:journalRecPayloadStart])
+
+	switch rec.kind {
+	case chunkJournalRecord:
+		if len(rec.payload) < checksumSize {
+			return journalRecord{}, fmt.Errorf("%w: chunk record without data", errCorruptJournalRecord)
+		}
+	case rootHashJournalRecord:
+		if len(rec.payload) != 0 {
+			return journalRecord{}, fmt.Errorf("%w: root hash record with payload", errCorruptJournalRecord)
+		}
+	default:
+		return journalRecord{}, fmt.Errorf("%w: unknown kind %d", errCorruptJournalRecord, rec.kind)
+	}
+
+	return rec, nil
+}
+
+// findJournalRecord returns the offset of the first valid record within |buf|, which need not start at a record.
+func findJournalRecord(buf []byte) (int, bool) {
+	for i := 0; i+minJournalRecordSize <= len(buf); i++ {
+		n, err := readJournalRecordLength(buf[i:])
+		if err != nil || int(n) > len(buf)-i {
+			continue
+		}
+
+		if _, err = readJournalRecord(buf[i : i+int(n)]); err == nil {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
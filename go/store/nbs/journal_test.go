@@ -0,0 +1,365 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nbs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/store/chunks"
+	"github.com/dolthub/dolt/go/store/types"
+	"github.com/dolthub/dolt/go/store/util/tempfiles"
+)
+
+func makeTestJournalingStore(t *testing.T) (st *NomsBlockStore, nomsDir string) {
+	nomsDir = filepath.Join(tempfiles.MovableTempFileProvider.GetTempDir(), "noms_"+uuid.New().String()[:8])
+	require.NoError(t, os.MkdirAll(nomsDir, os.ModePerm))
+
+	st, err := NewLocalJournalingStore(context.Background(), types.Format_Default.VersionString(), nomsDir, defaultMemTableSize)
+	require.NoError(t, err)
+	return st, nomsDir
+}
+
+// commitChunks puts |n| new chunks, the first of which becomes the new root, and commits them.
+func commitChunks(t *testing.T, st *NomsBlockStore, prefix string, n int) []chunks.Chunk {
+	ctx := context.Background()
+	last, err := st.Root(ctx)
+	require.NoError(t, err)
+
+	chs := make([]chunks.Chunk, n)
+	for i := range chs {
+		chs[i] = chunks.NewChunk([]byte(fmt.Sprintf("%s:%d", prefix, i)))
+		require.NoError(t, st.Put(ctx, chs[i]))
+	}
+
+	ok, err := st.Commit(ctx, chs[0].Hash(), last)
+	require.NoError(t, err)
+	require.True(t, ok)
+	return chs
+}
+
+func requireJournaledChunks(t *testing.T, st *NomsBlockStore, chs []chunks.Chunk) {
+	for _, ch := range chs {
+		actual, err := st.Get(context.Background(), ch.Hash())
+		require.NoError(t, err)
+		require.Equal(t, ch.Data(), actual.Data())
+	}
+}
+
+// tableFileNames returns the names of the table files in |dir|, not counting the chunk journal.
+func tableFileNames(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	var names []string
+	for _, e := range entries {
+		if len(e.Name()) == 32 && e.Name() != journalAddr.String() {
+			names = append(names, e.Name())
+		}
+	}
+	return names
+}
+
+func TestJournalRecordRoundTrip(t *testing.T) {
+	cc := ChunkToCompressedChunk(chunks.NewChunk([]byte("journal record")))
+	buf := make([]byte, journalRecordSize(len(cc.FullCompressedChunk))+minJournalRecordSize)
+
+	n := writeChunkRecord(buf, cc)
+	m := writeRootHashRecord(buf[n:], addr(cc.H))
+	require.Equal(t, len(buf), int(n+m))
+
+	l, err := readJournalRecordLength(buf)
+	require.NoError(t, err)
+	require.Equal(t, n, l)
+
+	rec, err := readJournalRecord(buf[:n])
+	require.NoError(t, err)
+	assert.Equal(t, chunkJournalRecord, rec.kind)
+	assert.Equal(t, addr(cc.H), rec.address)
+	assert.Equal(t, cc.FullCompressedChunk, rec.payload)
+
+	rec, err = readJournalRecord(buf[n:])
+	require.NoError(t, err)
+	assert.Equal(t, rootHashJournalRecord, rec.kind)
+	assert.Equal(t, addr(cc.H), rec.address)
+	assert.Empty(t, rec.payload)
+
+	buf[journalRecPayloadStart] ^= 0xff
+	_, err = readJournalRecord(buf[:n])
+	assert.ErrorIs(t, err, errCorruptJournalRecord)
+}
+
+func TestJournalWriterReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), journalAddr.String())
+	wr, err := openJournalWriter(path)
+	require.NoError(t, err)
+
+	ccs := []CompressedChunk{
+		ChunkToCompressedChunk(chunks.NewChunk([]byte("one"))),
+		ChunkToCompressedChunk(chunks.NewChunk([]byte("two"))),
+	}
+	require.NoError(t, wr.writeChunks(ccs))
+	require.NoError(t, wr.writeChunks(ccs[:1]))
+	require.NoError(t, wr.writeRootHash(ccs[1].H))
+	size := wr.size()
+	require.NoError(t, wr.Close())
+
+	// a record cut short by a crash ends the journal, and is removed before it is written to
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0666)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0, 0, 1, 0, byte(chunkJournalRecord), 1, 2, 3})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	wr, err = openJournalWriter(path)
+	require.NoError(t, err)
+	defer wr.Close()
+
+	assert.Equal(t, size, wr.size())
+	assert.Equal(t, uint32(2), wr.chunkCount())
+	root, ok := wr.latestRoot()
+	require.True(t, ok)
+	assert.Equal(t, ccs[1].H, root)
+
+	for _, cc := range ccs {
+		actual, ok, err := wr.getCompressedChunk(addr(cc.H))
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, cc.FullCompressedChunk, actual.FullCompressedChunk)
+	}
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, size+8, info.Size())
+
+	require.NoError(t, wr.truncateTail())
+	info, err = os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, size, info.Size())
+}
+
+func TestJournalWriterReplayCorruption(t *testing.T) {
+	ccs := []CompressedChunk{
+		ChunkToCompressedChunk(chunks.NewChunk([]byte("one"))),
+		ChunkToCompressedChunk(chunks.NewChunk([]byte("two"))),
+	}
+
+	writeJournal := func(t *testing.T) (string, int64) {
+		path := filepath.Join(t.TempDir(), journalAddr.String())
+		wr, err := openJournalWriter(path)
+		require.NoError(t, err)
+		require.NoError(t, wr.writeChunks(ccs[:1]))
+		first := wr.size()
+		require.NoError(t, wr.writeChunks(ccs[1:]))
+		require.NoError(t, wr.writeRootHash(ccs[1].H))
+		require.NoError(t, wr.Close())
+		return path, first
+	}
+
+	flipByte := func(t *testing.T, path string, off int64) {
+		f, err := os.OpenFile(path, os.O_RDWR, 0666)
+		require.NoError(t, err)
+		defer f.Close()
+		b := make([]byte, 1)
+		_, err = f.ReadAt(b, off)
+		require.NoError(t, err)
+		_, err = f.WriteAt([]byte{b[0] ^ 0xff}, off)
+		require.NoError(t, err)
+	}
+
+	t.Run("bad checksum followed by valid records", func(t *testing.T) {
+		path, _ := writeJournal(t)
+		flipByte(t, path, journalRecPayloadStart)
+
+		_, err := openJournalWriter(path)
+		assert.ErrorIs(t, err, errCorruptJournalRecord)
+	})
+
+	t.Run("bad length followed by valid records", func(t *testing.T) {
+		path, _ := writeJournal(t)
+		flipByte(t, path, 0)
+
+		_, err := openJournalWriter(path)
+		assert.ErrorIs(t, err, errCorruptJournalRecord)
+	})
+
+	t.Run("bad last record", func(t *testing.T) {
+		path, _ := writeJournal(t)
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		flipByte(t, path, info.Size()-1)
+
+		// the root hash record ends the journal, and is dropped as if it was partially written
+		wr, err := openJournalWriter(path)
+		require.NoError(t, err)
+		defer wr.Close()
+		assert.Equal(t, uint32(2), wr.chunkCount())
+		_, ok := wr.latestRoot()
+		assert.False(t, ok)
+	})
+}
+
+func TestJournalReadByOtherProcess(t *testing.T) {
+	ctx := context.Background()
+	st, nomsDir := makeTestJournalingStore(t)
+	defer st.Close()
+	commitChunks(t, st, "before", 2)
+
+	// a journal which is not registered stands in for the journal of another process
+	m, err := getFileManifest(ctx, nomsDir, nil)
+	require.NoError(t, err)
+	readerJournal := &chunkJournal{
+		dir:       nomsDir,
+		backing:   m,
+		persister: newFSTablePersister(nomsDir, globalFDCache, globalIndexCache).(*fsTablePersister),
+		maxSize:   defaultJournalCompactionSize,
+		refs:      1,
+	}
+	readerJournal.wr, err = openJournalWriter(filepath.Join(nomsDir, journalAddr.String()))
+	require.NoError(t, err)
+	readerJournal.src = journalChunkSource{wr: readerJournal.wr}
+	require.NoError(t, readerJournal.load(ctx))
+	defer readerJournal.Close()
+
+	chs := commitChunks(t, st, "after", 3)
+	root, err := st.Root(ctx)
+	require.NoError(t, err)
+
+	// the reader catches up on the journal without locking it
+	exists, contents, err := readerJournal.ParseIfExists(ctx, &Stats{}, nil)
+	require.NoError(t, err)
+	require.True(t, exists)
+	assert.Equal(t, root, contents.root)
+	for _, ch := range chs {
+		assert.True(t, readerJournal.wr.has(addr(ch.Hash())))
+	}
+
+	// but cannot write to it while it is locked
+	_, err = readerJournal.Update(ctx, contents.lock, contents, &Stats{}, nil)
+	assert.ErrorIs(t, err, ErrJournalLocked)
+}
+
+func TestJournalingStoreCommits(t *testing.T) {
+	ctx := context.Background()
+	st, nomsDir := makeTestJournalingStore(t)
+
+	var all []chunks.Chunk
+	for i := 0; i < 10; i++ {
+		all = append(all, commitChunks(t, st, fmt.Sprintf("commit %d", i), 3)...)
+	}
+	requireJournaledChunks(t, st, all)
+
+	// commits are appended to the journal rather than written to table files
+	assert.Empty(t, tableFileNames(t, nomsDir))
+	root, err := st.Root(ctx)
+	require.NoError(t, err)
+	require.NoError(t, st.Close())
+
+	// a store with a journal uses it however it is opened
+	st, err = NewLocalStore(ctx, types.Format_Default.VersionString(), nomsDir, defaultMemTableSize)
+	require.NoError(t, err)
+	defer st.Close()
+
+	_, ok := st.p.(*chunkJournal)
+	require.True(t, ok)
+
+	reopenedRoot, err := st.Root(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, root, reopenedRoot)
+	requireJournaledChunks(t, st, all)
+
+	all = append(all, commitChunks(t, st, "after reopen", 3)...)
+	requireJournaledChunks(t, st, all)
+}
+
+func TestJournalIsSharedWithinProcess(t *testing.T) {
+	ctx := context.Background()
+	st, nomsDir := makeTestJournalingStore(t)
+	defer st.Close()
+
+	// stores of the same process share the journal
+	other, err := NewLocalJournalingStore(ctx, types.Format_Default.VersionString(), nomsDir, defaultMemTableSize)
+	require.NoError(t, err)
+	chs := commitChunks(t, other, "other", 2)
+	require.NoError(t, other.Close())
+
+	require.NoError(t, st.Rebase(ctx))
+	requireJournaledChunks(t, st, chs)
+}
+
+func TestJournalCompaction(t *testing.T) {
+	ctx := context.Background()
+	st, nomsDir := makeTestJournalingStore(t)
+	st.p.(*chunkJournal).maxSize = 1 << 10
+
+	var all []chunks.Chunk
+	for i := 0; i < 20; i++ {
+		all = append(all, commitChunks(t, st, fmt.Sprintf("commit %d", i), 10)...)
+	}
+	requireJournaledChunks(t, st, all)
+
+	j := st.p.(*chunkJournal)
+	assert.NotEmpty(t, tableFileNames(t, nomsDir))
+	assert.LessOrEqual(t, j.wr.size(), j.maxSize+(1<<10))
+
+	root, err := st.Root(ctx)
+	require.NoError(t, err)
+	require.NoError(t, st.Close())
+
+	st, err = NewLocalStore(ctx, types.Format_Default.VersionString(), nomsDir, defaultMemTableSize)
+	require.NoError(t, err)
+	defer st.Close()
+
+	reopenedRoot, err := st.Root(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, root, reopenedRoot)
+	requireJournaledChunks(t, st, all)
+}
+
+func TestJournalingStoreSources(t *testing.T) {
+	ctx := context.Background()
+	st, nomsDir := makeTestJournalingStore(t)
+	defer st.Close()
+
+	chs := commitChunks(t, st, "sources", 5)
+
+	// listing table files compacts the journal, which cannot be read as a table file
+	root, sources, _, err := st.Sources(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, chs[0].Hash(), root)
+	require.Len(t, sources, 1)
+	assert.Equal(t, tableFileNames(t, nomsDir), []string{sources[0].FileID()})
+	assert.Equal(t, 5, sources[0].NumChunks())
+	assert.Equal(t, uint32(0), st.p.(*chunkJournal).wr.chunkCount())
+
+	requireJournaledChunks(t, st, chs)
+
+	// the journal is only compacted when it holds chunks
+	j := st.p.(*chunkJournal)
+	size := j.wr.size()
+	_, again, _, err := st.Sources(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, size, j.wr.size())
+	assert.Equal(t, sources[0].FileID(), again[0].FileID())
+
+	requireJournaledChunks(t, st, commitChunks(t, st, "after sources", 2))
+}
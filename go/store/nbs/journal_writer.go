@@ -0,0 +1,338 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nbs
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/golang/snappy"
+
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+// journalWriter appends records to a chunk journal file and indexes the chunks it holds. The journal is replayed when
+// it is opened, and may be replayed again to read the records appended since by another process. A record which was
+// only partially written ends the journal, as it may still be being written. The writer of the journal removes such a
+// record before it appends to the journal, as it was left behind by a crash. An invalid record which is followed by
+// valid records was not left behind by a crash, and the journal is reported as corrupt rather than truncated.
+type journalWriter struct {
+	mu   sync.RWMutex // protects the following state
+	file *os.File
+	path string
+	off  int64
+
+	// ranges locates the compressed data of each chunk in the journal, and order holds the chunks in the order they
+	// were written.
+	ranges   map[addr]Range
+	order    []addr
+	uncmpLen uint64
+
+	root    hash.Hash
+	hasRoot bool
+}
+
+func openJournalWriter(path string) (*journalWriter, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, err
+	}
+
+	wr := &journalWriter{
+		file:   f,
+		path:   path,
+		ranges: make(map[addr]Range),
+	}
+
+	if err = wr.replay(); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return wr, nil
+}
+
+// replay reads the records of the journal past those read so far, to update its index and latest root hash. If the
+// journal was truncated since it was last read, it is read from the start.
+func (wr *journalWriter) replay() error {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+
+	info, err := wr.file.Stat()
+	if err != nil {
+		return err
+	}
+
+	if info.Size() < wr.off {
+		wr.reset()
+	}
+
+	rd := bufio.NewReaderSize(io.NewSectionReader(wr.file, wr.off, info.Size()-wr.off), 1<<20)
+	lenBuf := make([]byte, journalRecLenSize)
+
+	for {
+		if _, err = io.ReadFull(rd, lenBuf); err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		n, err := readJournalRecordLength(lenBuf)
+		if err != nil {
+			return wr.checkInvalidTail(info.Size())
+		}
+
+		buf := make([]byte, n)
+		copy(buf, lenBuf)
+		if _, err = io.ReadFull(rd, buf[journalRecLenSize:]); err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		rec, err := readJournalRecord(buf)
+		if err != nil {
+			return wr.checkInvalidTail(info.Size())
+		}
+
+		switch rec.kind {
+		case chunkJournalRecord:
+			cc, err := NewCompressedChunk(hash.Hash(rec.address), rec.payload)
+			if err != nil {
+				return err
+			}
+			wr.indexChunk(rec.address, wr.off+journalRecPayloadStart, uint32(len(rec.payload)), cc)
+		case rootHashJournalRecord:
+			wr.root, wr.hasRoot = hash.Hash(rec.address), true
+		}
+
+		wr.off += int64(n)
+	}
+}
+
+// checkInvalidTail is called when the record at |wr.off| is invalid, and returns an error if a valid record follows it
+// within the first |size| bytes of the journal. Otherwise the invalid record is the partially written last record of
+// the journal.
+//
+// callers must acquire lock |wr.mu|
+func (wr *journalWriter) checkInvalidTail(size int64) error {
+	tail := make([]byte, size-wr.off)
+	if _, err := wr.file.ReadAt(tail, wr.off); err != nil && err != io.EOF {
+		return err
+	}
+
+	if off, ok := findJournalRecord(tail[1:]); ok {
+		return fmt.Errorf("%w: invalid record at offset %d of %s is followed by a valid record at offset %d",
+			errCorruptJournalRecord, wr.off, wr.path, wr.off+1+int64(off))
+	}
+
+	return nil
+}
+
+// truncateTail removes anything past the last complete record of the journal, which can only be a record left behind
+// by a crash once the journal is being written by this process.
+func (wr *journalWriter) truncateTail() error {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+	return wr.file.Truncate(wr.off)
+}
+
+// callers must acquire lock |wr.mu|
+func (wr *journalWriter) reset() {
+	wr.off = 0
+	wr.ranges = make(map[addr]Range)
+	wr.order = nil
+	wr.uncmpLen = 0
+	wr.root, wr.hasRoot = hash.Hash{}, false
+}
+
+// callers must acquire lock |wr.mu|
+func (wr *journalWriter) indexChunk(a addr, off int64, length uint32, cc CompressedChunk) {
+	if _, ok := wr.ranges[a]; ok {
+		return
+	}
+	wr.ranges[a] = Range{Offset: uint64(off), Length: length}
+	wr.order = append(wr.order, a)
+
+	// the uncompressed length is only used for statistics, so it is estimated rather than decompressing every chunk
+	if l, err := snappy.DecodedLen(cc.CompressedData); err == nil {
+		wr.uncmpLen += uint64(l)
+	}
+}
+
+// writeChunks appends chunk records for |ccs| to the journal, skipping chunks it already holds. The records are not
+// synced until the next root hash is written.
+func (wr *journalWriter) writeChunks(ccs []CompressedChunk) error {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+
+	var size int
+	for _, cc := range ccs {
+		size += int(journalRecordSize(len(cc.FullCompressedChunk)))
+	}
+
+	buf := make([]byte, size)
+	type written struct {
+		a   addr
+		off int64
+		cc  CompressedChunk
+	}
+	chunks := make([]written, 0, len(ccs))
+	seen := make(map[addr]struct{}, len(ccs))
+
+	var n uint32
+	for _, cc := range ccs {
+		a := addr(cc.H)
+		if _, ok := wr.ranges[a]; ok {
+			continue
+		}
+		if _, ok := seen[a]; ok {
+			continue
+		}
+		seen[a] = struct{}{}
+		chunks = append(chunks, written{a: a, off: wr.off + int64(n) + journalRecPayloadStart, cc: cc})
+		n += writeChunkRecord(buf[n:], cc)
+	}
+
+	if n == 0 {
+		return nil
+	}
+
+	if _, err := wr.file.WriteAt(buf[:n], wr.off); err != nil {
+		return err
+	}
+	wr.off += int64(n)
+
+	for _, w := range chunks {
+		wr.indexChunk(w.a, w.off, uint32(len(w.cc.FullCompressedChunk)), w.cc)
+	}
+
+	return nil
+}
+
+// writeRootHash appends a root hash record for |root| to the journal and syncs it, along with every chunk record
+// written before it.
+func (wr *journalWriter) writeRootHash(root hash.Hash) error {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+
+	buf := make([]byte, minJournalRecordSize)
+	n := writeRootHashRecord(buf, addr(root))
+	if _, err := wr.file.WriteAt(buf[:n], wr.off); err != nil {
+		return err
+	}
+	if err := wr.file.Sync(); err != nil {
+		return err
+	}
+
+	wr.off += int64(n)
+	wr.root, wr.hasRoot = root, true
+	return nil
+}
+
+// truncate removes every record of the journal, after its chunks were written elsewhere, and records |root| as the
+// root hash of the emptied journal.
+func (wr *journalWriter) truncate(root hash.Hash) error {
+	err := func() error {
+		wr.mu.Lock()
+		defer wr.mu.Unlock()
+
+		if err := wr.file.Truncate(0); err != nil {
+			return err
+		}
+
+		wr.reset()
+		return nil
+	}()
+
+	if err != nil {
+		return err
+	}
+
+	return wr.writeRootHash(root)
+}
+
+func (wr *journalWriter) has(a addr) bool {
+	wr.mu.RLock()
+	defer wr.mu.RUnlock()
+	_, ok := wr.ranges[a]
+	return ok
+}
+
+// getCompressedChunk returns the chunk |a| of the journal, if it holds it.
+func (wr *journalWriter) getCompressedChunk(a addr) (CompressedChunk, bool, error) {
+	wr.mu.RLock()
+	r, ok := wr.ranges[a]
+	wr.mu.RUnlock()
+
+	if !ok {
+		return CompressedChunk{}, false, nil
+	}
+
+	buf := make([]byte, r.Length)
+	if _, err := wr.file.ReadAt(buf, int64(r.Offset)); err != nil {
+		return CompressedChunk{}, false, err
+	}
+
+	cc, err := NewCompressedChunk(hash.Hash(a), buf)
+	if err != nil {
+		return CompressedChunk{}, false, err
+	}
+
+	return cc, true, nil
+}
+
+// chunkAddrs returns the addresses of the chunks of the journal, in the order they were written.
+func (wr *journalWriter) chunkAddrs() []addr {
+	wr.mu.RLock()
+	defer wr.mu.RUnlock()
+	return append([]addr{}, wr.order...)
+}
+
+func (wr *journalWriter) chunkCount() uint32 {
+	wr.mu.RLock()
+	defer wr.mu.RUnlock()
+	return uint32(len(wr.order))
+}
+
+func (wr *journalWriter) uncompressedLen() uint64 {
+	wr.mu.RLock()
+	defer wr.mu.RUnlock()
+	return wr.uncmpLen
+}
+
+// size returns the length of the journal, in bytes.
+func (wr *journalWriter) size() int64 {
+	wr.mu.RLock()
+	defer wr.mu.RUnlock()
+	return wr.off
+}
+
+// latestRoot returns the root hash of the last root hash record of the journal, if it has one.
+func (wr *journalWriter) latestRoot() (hash.Hash, bool) {
+	wr.mu.RLock()
+	defer wr.mu.RUnlock()
+	return wr.root, wr.hasRoot
+}
+
+func (wr *journalWriter) Close() error {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+	return wr.file.Close()
+}
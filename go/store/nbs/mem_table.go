@@ -176,13 +176,16 @@ func (mt *memTable) extract(ctx context.Context, chunks chan<- extractRecord) er
 }
 
 func (mt *memTable) write(haver chunkReader, stats *Stats) (name addr, data []byte, count uint32, err error) {
+	return mt.writeCompressed(haver, SnappyCompression, stats)
+}
+
+// writeCompressed writes the chunks of the memTable which |haver| does not have to a table file whose chunks are
+// compressed with |compression|.
+func (mt *memTable) writeCompressed(haver chunkReader, compression TableFileCompression, stats *Stats) (name addr, data []byte, count uint32, err error) {
 	numChunks := uint64(len(mt.order))
 	if numChunks == 0 {
 		return addr{}, nil, 0, fmt.Errorf("mem table cannot write with zero chunks")
 	}
-	maxSize := maxTableSize(uint64(len(mt.order)), mt.totalData)
-	buff := make([]byte, maxSize)
-	tw := newTableWriter(buff, mt.snapper)
 
 	if haver != nil {
 		sort.Sort(hasRecordByPrefix(mt.order)) // hasMany() requires addresses to be sorted.
@@ -195,6 +198,25 @@ func (mt *memTable) write(haver chunkReader, stats *Stats) (name addr, data []by
 		sort.Sort(hasRecordByOrder(mt.order)) // restore "insertion" order for write
 	}
 
+	maxSize := maxTableSize(uint64(len(mt.order)), mt.totalData)
+	var tw *tableWriter
+	if compression == SnappyCompression {
+		tw = newTableWriter(make([]byte, maxSize), mt.snapper)
+	} else {
+		var dict *zstdDict
+		if compression == ZstdDictCompression {
+			dict = mt.trainDict()
+		}
+
+		var dictSize int
+		if dict != nil {
+			dictSize = len(dict.content)
+		}
+
+		buff := make([]byte, maxSize+versionedTableOverhead(numChunks, dictSize))
+		tw = newVersionedTableWriter(buff, newTableEncoder(compression, dict))
+	}
+
 	for _, addr := range mt.order {
 		if !addr.has {
 			h := addr.a
@@ -202,6 +224,10 @@ func (mt *memTable) write(haver chunkReader, stats *Stats) (name addr, data []by
 			count++
 		}
 	}
+	if count > 0 {
+		// the count of a table file includes its dictionary record
+		count = uint32(len(tw.prefixes))
+	}
 	tableSize, name, err := tw.finish()
 
 	if err != nil {
@@ -215,7 +241,29 @@ func (mt *memTable) write(haver chunkReader, stats *Stats) (name addr, data []by
 		stats.ChunksPerPersist.Sample(uint64(count))
 	}
 
-	return name, buff[:tableSize], count, nil
+	return name, tw.buff[:tableSize], count, nil
+}
+
+// trainDict trains a zstd dictionary on a sample of the chunks of the memTable to be written, or returns nil if there
+// are too few of them.
+func (mt *memTable) trainDict() *zstdDict {
+	var novel []addr
+	for _, rec := range mt.order {
+		if !rec.has {
+			novel = append(novel, *rec.a)
+		}
+	}
+
+	idxs := sampleChunks(len(novel))
+	samples := make([][]byte, len(idxs))
+	for i, idx := range idxs {
+		samples[i] = mt.chunks[novel[idx]]
+	}
+
+	if content := trainTableDict(samples); content != nil {
+		return newZstdDict(content)
+	}
+	return nil
 }
 
 func (mt *memTable) Close() error {
@@ -159,6 +159,54 @@ func newMmapTableReader(dir string, h addr, chunkCount uint32, indexCache *index
 	}, nil
 }
 
+// newKeyedTableReader opens the table file |h| in |dir|, which may be sealed with |keys|. The index of the table
+// file is read rather than mapped, as the index of a sealed table file must be decrypted.
+func newKeyedTableReader(dir string, h addr, chunkCount uint32, fc *fdCache, keys *Keyring) (cs chunkSource, err error) {
+	path := filepath.Join(dir, h.String())
+
+	f, err := fc.RefFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		unrefErr := fc.UnrefFile(path)
+
+		if err == nil {
+			err = unrefErr
+		}
+	}()
+
+	fi, err := f.Stat()
+
+	if err != nil {
+		return nil, err
+	}
+
+	buff, env, err := readTableTail(keys, chunkCount, readFileTail(f, fi.Size()))
+
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := parseTableIndex(buff)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if chunkCount != index.chunkCount {
+		return nil, errors.New("unexpected chunk count")
+	}
+
+	return &mmapTableReader{
+		newTableReader(index, env.readerAt(&cacheReaderAt{path, fc}), fileBlockSize),
+		fc,
+		h,
+	}, nil
+}
+
 func (mmtr *mmapTableReader) hash() (addr, error) {
 	return mmtr.h, nil
 }
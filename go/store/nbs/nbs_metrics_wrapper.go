@@ -75,6 +75,18 @@ func (nbsMW *NBSMetricWrapper) MarkAndSweepChunks(ctx context.Context, last hash
 	return nbsMW.nbs.MarkAndSweepChunks(ctx, last, keepChunks, dest)
 }
 
+func (nbsMW *NBSMetricWrapper) BeginGC(keeper func(hash.Hash) bool) error {
+	return nbsMW.nbs.BeginGC(keeper)
+}
+
+func (nbsMW *NBSMetricWrapper) EndGC() {
+	nbsMW.nbs.EndGC()
+}
+
+func (nbsMW *NBSMetricWrapper) HasManyUntracked(ctx context.Context, hashes hash.HashSet) (hash.HashSet, error) {
+	return nbsMW.nbs.HasManyUntracked(ctx, hashes)
+}
+
 // PruneTableFiles deletes old table files that are no longer referenced in the manifest.
 func (nbsMW *NBSMetricWrapper) PruneTableFiles(ctx context.Context) error {
 	return nbsMW.nbs.PruneTableFiles(ctx)
@@ -37,6 +37,7 @@ import (
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/dolthub/dolt/go/libraries/utils/file"
 	"github.com/dolthub/dolt/go/libraries/utils/tracing"
 	"github.com/dolthub/dolt/go/store/blobstore"
 	"github.com/dolthub/dolt/go/store/chunks"
@@ -98,6 +99,10 @@ type NomsBlockStore struct {
 	mtSize   uint64
 	putCount uint64
 
+	// keeperFunc is set while a garbage collection which can run concurrently with writers is in progress, see BeginGC
+	keeperFunc func(hash.Hash) bool
+	gcCond     *sync.Cond
+
 	stats *Stats
 }
 
@@ -138,6 +143,9 @@ func (nbs *NomsBlockStore) GetChunkLocations(hashes hash.HashSet) (map[hash.Hash
 
 					ranges[hash.Hash(tr.h)] = y
 				}
+			case journalChunkSource:
+				// chunks in the chunk journal have no location within a table file
+				continue
 			case *chunkSourceAdapter:
 				y, ok := ranges[hash.Hash(tr.h)]
 
@@ -402,25 +410,34 @@ func NewAWSStoreWithMMapIndex(ctx context.Context, nbfVerStr string, table, ns,
 			}
 			return newMmapTableIndex(ohi, nil)
 		},
+		nil,
 	}
 	mm := makeManifestManager(newDynamoManifest(table, ns, ddb))
 	return newNomsBlockStore(ctx, nbfVerStr, mm, p, inlineConjoiner{defaultMaxTables}, memTableSize)
 }
 
 func NewAWSStore(ctx context.Context, nbfVerStr string, table, ns, bucket string, s3 s3svc, ddb ddbsvc, memTableSize uint64) (*NomsBlockStore, error) {
+	return NewAWSStoreWithKeyring(ctx, nbfVerStr, table, ns, bucket, s3, ddb, memTableSize, nil)
+}
+
+// NewAWSStoreWithKeyring returns an nbs implementation backed by S3 and DynamoDB, whose table files are encrypted at
+// rest with |keys| unless they are nil. The manifest in DynamoDB, which holds only the addresses of the root and the
+// table files, is not encrypted.
+func NewAWSStoreWithKeyring(ctx context.Context, nbfVerStr string, table, ns, bucket string, s3 s3svc, ddb ddbsvc, memTableSize uint64, keys *Keyring) (*NomsBlockStore, error) {
 	cacheOnce.Do(makeGlobalCaches)
 	readRateLimiter := make(chan struct{}, 32)
 	p := &awsTablePersister{
-		s3,
-		bucket,
-		readRateLimiter,
-		&ddbTableStore{ddb, table, readRateLimiter, nil},
-		awsLimits{defaultS3PartSize, minS3PartSize, maxS3PartSize, maxDynamoItemSize, maxDynamoChunks},
-		globalIndexCache,
-		ns,
-		func(bs []byte) (tableIndex, error) {
+		s3:         s3,
+		bucket:     bucket,
+		rl:         readRateLimiter,
+		ddb:        &ddbTableStore{ddb, table, readRateLimiter, nil},
+		limits:     awsLimits{defaultS3PartSize, minS3PartSize, maxS3PartSize, maxDynamoItemSize, maxDynamoChunks},
+		indexCache: keyedIndexCache(keys),
+		ns:         ns,
+		parseIndex: func(bs []byte) (tableIndex, error) {
 			return parseTableIndex(bs)
 		},
+		keys: keys,
 	}
 	mm := makeManifestManager(newDynamoManifest(table, ns, ddb))
 	return newNomsBlockStore(ctx, nbfVerStr, mm, p, inlineConjoiner{defaultMaxTables}, memTableSize)
@@ -436,11 +453,17 @@ func NewGCSStore(ctx context.Context, nbfVerStr string, bucketName, path string,
 
 // NewBSStore returns an nbs implementation backed by a Blobstore
 func NewBSStore(ctx context.Context, nbfVerStr string, bs blobstore.Blobstore, memTableSize uint64) (*NomsBlockStore, error) {
+	return NewBSStoreWithKeyring(ctx, nbfVerStr, bs, memTableSize, nil)
+}
+
+// NewBSStoreWithKeyring returns an nbs implementation backed by a Blobstore, whose table files and manifest are
+// encrypted at rest with |keys| unless they are nil.
+func NewBSStoreWithKeyring(ctx context.Context, nbfVerStr string, bs blobstore.Blobstore, memTableSize uint64, keys *Keyring) (*NomsBlockStore, error) {
 	cacheOnce.Do(makeGlobalCaches)
 
-	mm := makeManifestManager(blobstoreManifest{"manifest", bs})
+	mm := makeManifestManager(blobstoreManifest{"manifest", bs, keys})
 
-	p := &blobstorePersister{bs, s3BlockSize, globalIndexCache}
+	p := &blobstorePersister{bs, s3BlockSize, keyedIndexCache(keys), keys}
 	return newNomsBlockStore(ctx, nbfVerStr, mm, p, inlineConjoiner{defaultMaxTables}, memTableSize)
 }
 
@@ -448,7 +471,25 @@ func NewLocalStore(ctx context.Context, nbfVerStr string, dir string, memTableSi
 	return newLocalStore(ctx, nbfVerStr, dir, memTableSize, defaultMaxTables)
 }
 
+// NewLocalStoreWithKeyring returns a local store whose table files and manifest are encrypted at rest with |keys|.
+// Table files and manifests written before the store was encrypted are read as they are, and are sealed when they
+// are rewritten, or when the store is garbage collected. Stores with a chunk journal cannot be encrypted.
+func NewLocalStoreWithKeyring(ctx context.Context, nbfVerStr string, dir string, memTableSize uint64, keys *Keyring) (*NomsBlockStore, error) {
+	return openLocalStore(ctx, nbfVerStr, dir, memTableSize, defaultMaxTables, false, keys)
+}
+
+// NewLocalJournalingStore returns a local store which appends the chunks and roots it commits to a chunk journal,
+// rather than writing a table file for each commit. See chunkJournal. A local store which already has a chunk journal
+// uses it however it is opened.
+func NewLocalJournalingStore(ctx context.Context, nbfVerStr string, dir string, memTableSize uint64) (*NomsBlockStore, error) {
+	return openLocalStore(ctx, nbfVerStr, dir, memTableSize, defaultMaxTables, true, nil)
+}
+
 func newLocalStore(ctx context.Context, nbfVerStr string, dir string, memTableSize uint64, maxTables int) (*NomsBlockStore, error) {
+	return openLocalStore(ctx, nbfVerStr, dir, memTableSize, maxTables, false, nil)
+}
+
+func openLocalStore(ctx context.Context, nbfVerStr string, dir string, memTableSize uint64, maxTables int, journal bool, keys *Keyring) (*NomsBlockStore, error) {
 	cacheOnce.Do(makeGlobalCaches)
 	err := checkDir(dir)
 
@@ -456,23 +497,55 @@ func newLocalStore(ctx context.Context, nbfVerStr string, dir string, memTableSi
 		return nil, err
 	}
 
-	m, err := getFileManifest(ctx, dir)
+	if !journal {
+		journal, err = journalExists(dir)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if journal && keys != nil {
+		return nil, ErrChunkJournalEncryption
+	}
+
+	m, err := getFileManifest(ctx, dir, keys)
 
 	if err != nil {
 		return nil, err
 	}
 
-	mm := makeManifestManager(m)
-	p := newFSTablePersister(dir, globalFDCache, globalIndexCache)
-	nbs, err := newNomsBlockStore(ctx, nbfVerStr, mm, p, inlineConjoiner{maxTables}, memTableSize)
+	p := newFSTablePersister(dir, globalFDCache, keyedIndexCache(keys))
+	p.(*fsTablePersister).keys = keys
+	if !journal {
+		return newNomsBlockStore(ctx, nbfVerStr, makeManifestManager(m), p, inlineConjoiner{maxTables}, memTableSize)
+	}
+
+	j, err := openChunkJournal(ctx, dir, m, p.(*fsTablePersister))
 
 	if err != nil {
 		return nil, err
 	}
 
+	nbs, err := newNomsBlockStore(ctx, nbfVerStr, makeManifestManager(j), j, inlineConjoiner{maxTables}, memTableSize)
+
+	if err != nil {
+		j.Close()
+		return nil, err
+	}
+
 	return nbs, nil
 }
 
+// keyedIndexCache returns the index cache of the persisters of stores encrypted with |keys|. The index cache does not
+// hold the envelopes of sealed table files, so the persisters of encrypted stores do not use it.
+func keyedIndexCache(keys *Keyring) *indexCache {
+	if keys != nil {
+		return nil
+	}
+	return globalIndexCache
+}
+
 func checkDir(dir string) error {
 	stat, err := os.Stat(dir)
 	if err != nil {
@@ -565,6 +638,9 @@ func (nbs *NomsBlockStore) Put(ctx context.Context, c chunks.Chunk) error {
 func (nbs *NomsBlockStore) addChunk(ctx context.Context, h addr, data []byte) bool {
 	nbs.mu.Lock()
 	defer nbs.mu.Unlock()
+	if nbs.keeperFunc != nil && nbs.keeperFunc(hash.Hash(h)) {
+		nbs.waitForGC()
+	}
 	if nbs.mt == nil {
 		nbs.mt = newMemTable(nbs.mtSize)
 	}
@@ -764,7 +840,7 @@ func (nbs *NomsBlockStore) Has(ctx context.Context, h hash.Hash) (bool, error) {
 	}()
 
 	a := addr(h)
-	has, tables, err := func() (bool, chunkReader, error) {
+	has, tables, keeper, err := func() (bool, chunkReader, func(hash.Hash) bool, error) {
 		nbs.mu.RLock()
 		defer nbs.mu.RUnlock()
 
@@ -772,13 +848,13 @@ func (nbs *NomsBlockStore) Has(ctx context.Context, h hash.Hash) (bool, error) {
 			has, err := nbs.mt.has(a)
 
 			if err != nil {
-				return false, nil, err
+				return false, nil, nil, err
 			}
 
-			return has, nbs.tables, nil
+			return has, nbs.tables, nbs.keeperFunc, nil
 		}
 
-		return false, nbs.tables, nil
+		return false, nbs.tables, nbs.keeperFunc, nil
 	}()
 
 	if err != nil {
@@ -793,18 +869,41 @@ func (nbs *NomsBlockStore) Has(ctx context.Context, h hash.Hash) (bool, error) {
 		}
 	}
 
+	if has && keeper != nil && keeper(h) {
+		// the collection has reached its safepoint, and may not keep |h|, so check again once it is done
+		nbs.mu.Lock()
+		nbs.waitForGC()
+		nbs.mu.Unlock()
+		return nbs.Has(ctx, h)
+	}
+
 	return has, nil
 }
 
 func (nbs *NomsBlockStore) HasMany(ctx context.Context, hashes hash.HashSet) (hash.HashSet, error) {
+	return nbs.hasMany(ctx, hashes, true)
+}
+
+// HasManyUntracked implements chunks.ChunkStoreGarbageCollector.
+func (nbs *NomsBlockStore) HasManyUntracked(ctx context.Context, hashes hash.HashSet) (hash.HashSet, error) {
+	return nbs.hasMany(ctx, hashes, false)
+}
+
+// hasMany returns the chunks of |hashes| which are absent from the store. With |track|, the chunks found are tracked
+// for a collection in progress.
+func (nbs *NomsBlockStore) hasMany(ctx context.Context, hashes hash.HashSet, track bool) (hash.HashSet, error) {
 	t1 := time.Now()
 
 	reqs := toHasRecords(hashes)
 
+	var keeper func(hash.Hash) bool
 	tables, remaining, err := func() (tables chunkReader, remaining bool, err error) {
 		nbs.mu.RLock()
 		defer nbs.mu.RUnlock()
 		tables = nbs.tables
+		if track {
+			keeper = nbs.keeperFunc
+		}
 
 		remaining = true
 		if nbs.mt != nil {
@@ -841,6 +940,20 @@ func (nbs *NomsBlockStore) HasMany(ctx context.Context, hashes hash.HashSet) (ha
 			absent.Insert(hash.New(r.a[:]))
 		}
 	}
+
+	if keeper != nil {
+		for _, r := range reqs {
+			if r.has && keeper(hash.Hash(*r.a)) {
+				// the collection has reached its safepoint, and may not keep the chunks found, so check again once
+				// it is done
+				nbs.mu.Lock()
+				nbs.waitForGC()
+				nbs.mu.Unlock()
+				return nbs.HasMany(ctx, hashes)
+			}
+		}
+	}
+
 	return absent, nil
 }
 
@@ -1101,15 +1214,114 @@ func (nbs *NomsBlockStore) updateManifest(ctx context.Context, current, last has
 	nbs.upstream = newContents
 	nbs.tables = newTables
 
+	if j, ok := nbs.p.(*chunkJournal); ok && j.compactionRequired() {
+		return nbs.compactJournal(ctx, j)
+	}
+
 	return nil
 }
 
+// compactJournal writes the chunks of the chunk journal |j| to a table file, and replaces the journal with the table
+// file in the manifest, which truncates the journal.
+//
+// callers must acquire the update lock of |nbs.mm| and lock |nbs.mu|, and |nbs.tables| must not have novel tables,
+// which could be persisting to the journal.
+func (nbs *NomsBlockStore) compactJournal(ctx context.Context, j *chunkJournal) error {
+	spec, ok, err := j.writeTableFile(ctx)
+	if err != nil || !ok {
+		return err
+	}
+
+	replaced := false
+	specs := make([]tableSpec, len(nbs.upstream.specs))
+	for i, s := range nbs.upstream.specs {
+		if s.name == journalAddr {
+			s, replaced = spec, true
+		}
+		specs[i] = s
+	}
+
+	if !replaced {
+		return errors.New("chunk journal missing from the manifest")
+	}
+
+	newContents := manifestContents{
+		nbfVers:  nbs.upstream.nbfVers,
+		root:     nbs.upstream.root,
+		lock:     generateLockHash(nbs.upstream.root, specs, nbs.upstream.appendix),
+		gcGen:    nbs.upstream.gcGen,
+		specs:    specs,
+		appendix: nbs.upstream.appendix,
+	}
+
+	upstream, err := nbs.mm.Update(ctx, nbs.upstream.lock, newContents, nbs.stats, nil)
+	if err != nil {
+		return err
+	}
+
+	if upstream.lock != newContents.lock {
+		return errors.New("concurrent manifest edit during chunk journal compaction")
+	}
+
+	newTables, err := nbs.tables.Rebase(ctx, specs, nbs.stats)
+	if err != nil {
+		return err
+	}
+
+	nbs.upstream = newContents
+	oldTables := nbs.tables
+	nbs.tables = newTables
+	return oldTables.Close()
+}
+
+// flushJournal commits any pending chunks and compacts the chunk journal of the store, if it has one which holds
+// chunks, so that all of its chunks are in table files.
+func (nbs *NomsBlockStore) flushJournal(ctx context.Context) (err error) {
+	j, ok := nbs.p.(*chunkJournal)
+	if !ok || j.wr.chunkCount() == 0 {
+		return nil
+	}
+
+	nbs.mm.LockForUpdate()
+	defer func() {
+		unlockErr := nbs.mm.UnlockForUpdate()
+
+		if err == nil {
+			err = unlockErr
+		}
+	}()
+
+	nbs.mu.Lock()
+	defer nbs.mu.Unlock()
+
+	for {
+		// flush all tables and update manifest
+		err = nbs.updateManifest(ctx, nbs.upstream.root, nbs.upstream.root)
+
+		if err == nil {
+			break
+		} else if err != errOptimisticLockFailedTables {
+			return err
+		}
+	}
+
+	return nbs.compactJournal(ctx, j)
+}
+
 func (nbs *NomsBlockStore) Version() string {
 	return nbs.upstream.nbfVers
 }
 
 func (nbs *NomsBlockStore) Close() error {
-	return nbs.tables.Close()
+	err := nbs.tables.Close()
+
+	if j, ok := nbs.p.(*chunkJournal); ok {
+		if jErr := j.Close(); err == nil {
+			err = jErr
+		}
+	}
+
+	return err
 }
 
 func (nbs *NomsBlockStore) Stats() interface{} {
@@ -1121,7 +1333,25 @@ func (nbs *NomsBlockStore) StatsSummary() string {
 	defer nbs.mu.Unlock()
 	cnt, _ := nbs.tables.count()
 	physLen, _ := nbs.tables.physicalLen()
-	return fmt.Sprintf("Root: %s; Chunk Count %d; Physical Bytes %s", nbs.upstream.root, cnt, humanize.Bytes(physLen))
+	uncmpLen, _ := nbs.tables.uncompressedLen()
+	summary := fmt.Sprintf("Root: %s; Chunk Count %d; Physical Bytes %s; Uncompressed Bytes %s", nbs.upstream.root, cnt, humanize.Bytes(physLen), humanize.Bytes(uncmpLen))
+	if physLen > 0 {
+		summary += fmt.Sprintf("; Compression Ratio %.2f", float64(uncmpLen)/float64(physLen))
+	}
+	return summary
+}
+
+// SetTableFileCompression sets the compression of the chunks of the table files written by the store. It must be
+// called before the store is used. Existing table files keep their compression until they are rewritten by
+// conjoining or garbage collecting them.
+func (nbs *NomsBlockStore) SetTableFileCompression(compression TableFileCompression) error {
+	p, ok := nbs.localPersister()
+	if !ok {
+		return chunks.ErrUnsupportedOperation
+	}
+
+	p.compression = compression
+	return nil
 }
 
 // tableFile is our implementation of TableFile.
@@ -1148,6 +1378,13 @@ func (tf tableFile) Open(ctx context.Context) (io.ReadCloser, error) {
 // Sources retrieves the current root hash, a list of all table files (which may include appendix tablefiles),
 // and a second list of only the appendix table files
 func (nbs *NomsBlockStore) Sources(ctx context.Context) (hash.Hash, []TableFile, []TableFile, error) {
+	// the chunk journal cannot be read as a table file, so its chunks are compacted into one first
+	err := nbs.flushJournal(ctx)
+
+	if err != nil {
+		return hash.Hash{}, nil, nil, err
+	}
+
 	nbs.mu.Lock()
 	defer nbs.mu.Unlock()
 
@@ -1270,8 +1507,20 @@ func (nbs *NomsBlockStore) chunkSourcesByAddr() (map[addr]chunkSource, error) {
 
 }
 
+// localPersister returns the fsTablePersister of the store, if its table files are local.
+func (nbs *NomsBlockStore) localPersister() (*fsTablePersister, bool) {
+	switch p := nbs.p.(type) {
+	case *fsTablePersister:
+		return p, true
+	case *chunkJournal:
+		return p.persister, true
+	default:
+		return nil, false
+	}
+}
+
 func (nbs *NomsBlockStore) SupportedOperations() TableFileStoreOps {
-	_, ok := nbs.p.(*fsTablePersister)
+	_, ok := nbs.localPersister()
 	return TableFileStoreOps{
 		CanRead:  true,
 		CanWrite: ok,
@@ -1282,7 +1531,7 @@ func (nbs *NomsBlockStore) SupportedOperations() TableFileStoreOps {
 
 // WriteTableFile will read a table file from the provided reader and write it to the TableFileStore
 func (nbs *NomsBlockStore) WriteTableFile(ctx context.Context, fileId string, numChunks int, rd io.Reader, contentLength uint64, contentHash []byte) error {
-	fsPersister, ok := nbs.p.(*fsTablePersister)
+	fsPersister, ok := nbs.localPersister()
 
 	if !ok {
 		return errors.New("Not implemented")
@@ -1303,7 +1552,9 @@ func (nbs *NomsBlockStore) WriteTableFile(ctx context.Context, fileId string, nu
 		}
 	}()
 
-	return writeTo(f, rd, copyTableFileBufferSize)
+	return writeSealed(f, fsPersister.keys, func(wr io.Writer) error {
+		return writeTo(wr, rd, copyTableFileBufferSize)
+	})
 }
 
 // AddTableFilesToManifest adds table files to the manifest
@@ -1410,7 +1661,8 @@ func (nbs *NomsBlockStore) MarkAndSweepChunks(ctx context.Context, last hash.Has
 		nbs.mu.RLock()
 		defer nbs.mu.RUnlock()
 
-		if nbs.upstream.root != last {
+		// the root may move during a collection started with BeginGC, which keeps the chunks written meanwhile
+		if nbs.keeperFunc == nil && nbs.upstream.root != last {
 			return errLastRootMismatch
 		}
 
@@ -1422,11 +1674,6 @@ func (nbs *NomsBlockStore) MarkAndSweepChunks(ctx context.Context, last hash.Has
 
 		return nil
 	}
-	err := precheck()
-	if err != nil {
-		return err
-	}
-
 	destNBS := nbs
 	if dest != nil {
 		switch typed := dest.(type) {
@@ -1437,6 +1684,20 @@ func (nbs *NomsBlockStore) MarkAndSweepChunks(ctx context.Context, last hash.Has
 		}
 	}
 
+	// table files sealed with a retired key are sealed with the active key whether or not there is garbage to collect
+	err := nbs.rekeyTableFiles(ctx)
+	if err == nil && destNBS != nbs {
+		err = destNBS.rekeyTableFiles(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = precheck()
+	if err != nil {
+		return err
+	}
+
 	specs, err := nbs.copyMarkedChunks(ctx, keepChunks, destNBS)
 	if err != nil {
 		return err
@@ -1446,7 +1707,7 @@ func (nbs *NomsBlockStore) MarkAndSweepChunks(ctx context.Context, last hash.Has
 	}
 
 	if destNBS == nbs {
-		err = nbs.swapTables(ctx, specs)
+		replaced, err := nbs.swapTables(ctx, specs)
 		if err != nil {
 			return err
 		}
@@ -1455,12 +1716,18 @@ func (nbs *NomsBlockStore) MarkAndSweepChunks(ctx context.Context, last hash.Has
 			return ctx.Err()
 		}
 
-		currentContents := func() manifestContents {
+		currentContents, online := func() (manifestContents, bool) {
 			nbs.mu.RLock()
 			defer nbs.mu.RUnlock()
-			return nbs.upstream
+			return nbs.upstream, nbs.keeperFunc != nil
 		}()
 
+		if online {
+			// writers may be persisting new table files meanwhile, so only the table files which were swapped out
+			// are removed
+			return nbs.removeTableFiles(replaced, currentContents)
+		}
+
 		return nbs.p.PruneTableFiles(ctx, currentContents)
 	} else {
 		fileIdToNumChunks := tableSpecsToMap(specs)
@@ -1474,7 +1741,12 @@ func (nbs *NomsBlockStore) MarkAndSweepChunks(ctx context.Context, last hash.Has
 }
 
 func (nbs *NomsBlockStore) copyMarkedChunks(ctx context.Context, keepChunks <-chan []hash.Hash, dest *NomsBlockStore) ([]tableSpec, error) {
-	gcc, err := newGarbageCollectionCopier()
+	fsPersister, ok := dest.localPersister()
+	if !ok {
+		return nil, chunks.ErrUnsupportedOperation
+	}
+
+	gcc, err := newGarbageCollectionCopier(fsPersister.compression)
 	if err != nil {
 		return nil, err
 	}
@@ -1508,9 +1780,7 @@ LOOP:
 		}
 	}
 
-	nomsDir := dest.p.(*fsTablePersister).dir
-
-	return gcc.copyTablesToDir(ctx, nomsDir)
+	return gcc.copyTablesToDir(ctx, fsPersister.dir, fsPersister.keys)
 }
 
 // todo: what's the optimal table size to copy to?
@@ -1530,7 +1800,10 @@ func (nbs *NomsBlockStore) gcTableSize() (uint64, error) {
 	return nbs.mtSize, nil
 }
 
-func (nbs *NomsBlockStore) swapTables(ctx context.Context, specs []tableSpec) (err error) {
+// swapTables replaces the tables of the store with |specs|, and returns the specs of the tables it replaced. When a
+// collection started with BeginGC is in progress, the memTable and novel tables of the store are kept, as they hold
+// chunks written by concurrent writers which are not yet committed.
+func (nbs *NomsBlockStore) swapTables(ctx context.Context, specs []tableSpec) (replaced []tableSpec, err error) {
 	nbs.mm.LockForUpdate()
 	defer func() {
 		unlockErr := nbs.mm.UnlockForUpdate()
@@ -1553,37 +1826,312 @@ func (nbs *NomsBlockStore) swapTables(ctx context.Context, specs []tableSpec) (e
 
 	// nothing has changed.  Bail early
 	if newContents.gcGen == nbs.upstream.gcGen {
-		return nil
+		return nil, nil
 	}
 
+	replaced = append(append([]tableSpec{}, nbs.upstream.specs...), nbs.upstream.appendix...)
+
 	upstream, uerr := nbs.mm.UpdateGCGen(ctx, nbs.upstream.lock, newContents, nbs.stats, nil)
 	if uerr != nil {
-		return uerr
+		return nil, uerr
 	}
 
 	if upstream.lock != newContents.lock {
-		return errors.New("concurrent manifest edit during GC, before swapTables. GC failed.")
+		return nil, errors.New("concurrent manifest edit during GC, before swapTables. GC failed.")
 	}
 
-	// clear memTable
-	nbs.mt = newMemTable(nbs.mtSize)
+	if nbs.keeperFunc == nil {
+		// clear memTable
+		nbs.mt = newMemTable(nbs.mtSize)
 
-	// clear nbs.tables.novel
-	nbs.tables, err = nbs.tables.Flatten()
-	if err != nil {
-		return err
+		// clear nbs.tables.novel
+		nbs.tables, err = nbs.tables.Flatten()
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// replace nbs.tables.upstream with gc compacted tables
 	nbs.upstream = upstream
 	nbs.tables, err = nbs.tables.Rebase(ctx, upstream.specs, nbs.stats)
+	if err != nil {
+		return nil, err
+	}
+
+	return replaced, nil
+}
+
+// rekeyTableFiles seals the table files and manifest of a store encrypted at rest with the active key of its
+// keyring, so that garbage collecting the store rotates its keys. Table files sealed with the active key already are
+// left as they are.
+func (nbs *NomsBlockStore) rekeyTableFiles(ctx context.Context) (err error) {
+	p, ok := nbs.localPersister()
+	if !ok || p.keys == nil {
+		return nil
+	}
+
+	nbs.mm.LockForUpdate()
+	defer func() {
+		unlockErr := nbs.mm.UnlockForUpdate()
+		if err == nil {
+			err = unlockErr
+		}
+	}()
+
+	nbs.mu.Lock()
+	defer nbs.mu.Unlock()
+
+	if nbs.upstream.lock == (addr{}) {
+		// the store has no manifest yet
+		return nil
+	}
+
+	rekeyed := false
+	seen := make(map[addr]struct{})
+	for _, spec := range append(append([]tableSpec{}, nbs.upstream.specs...), nbs.upstream.appendix...) {
+		if _, ok := seen[spec.name]; ok {
+			continue
+		}
+		seen[spec.name] = struct{}{}
+
+		ok, err := p.rekeyTableFile(ctx, spec.name, spec.chunkCount, nbs.stats)
+		if err != nil {
+			return err
+		}
+		rekeyed = rekeyed || ok
+	}
+
+	// writing the manifest seals it with the active key. If it changed meanwhile, it was sealed by its writer.
+	_, err = nbs.mm.Update(ctx, nbs.upstream.lock, nbs.upstream, nbs.stats, nil)
+	if err != nil {
+		return err
+	}
+
+	if !rekeyed {
+		return nil
+	}
+
+	// the open table files still decrypt their chunks with the data keys they were sealed with before
+	reopened := tableSet{novel: nbs.tables.novel, p: nbs.tables.p, rl: nbs.tables.rl}
+	newTables, err := reopened.Rebase(ctx, nbs.upstream.specs, nbs.stats)
+	if err != nil {
+		return err
+	}
+
+	oldTables := nbs.tables
+	nbs.tables = newTables
+	return oldTables.Close()
+}
+
+// ConvertToLegacyTableFiles rewrites the table files of the store which are of the versioned table file format as
+// table files of the original format. Clients of remote stores read the chunk records of table files from byte ranges
+// of the files, and can only parse records of the original format, so stores served as remotes must not hold
+// versioned table files. The rewritten table files are removed.
+func (nbs *NomsBlockStore) ConvertToLegacyTableFiles(ctx context.Context) (err error) {
+	p, ok := nbs.localPersister()
+	if !ok {
+		return chunks.ErrUnsupportedOperation
+	}
+
+	nbs.mm.LockForUpdate()
+	defer func() {
+		unlockErr := nbs.mm.UnlockForUpdate()
+		if err == nil {
+			err = unlockErr
+		}
+	}()
+
+	nbs.mu.Lock()
+	defer nbs.mu.Unlock()
+
+	if nbs.upstream.lock == (addr{}) {
+		// the store has no manifest yet
+		return nil
+	}
+
+	css, err := nbs.chunkSourcesByAddr()
+	if err != nil {
+		return err
+	}
+
+	replaced := make(map[addr]tableSpec)
+	var replacedSpecs []tableSpec
+	for _, spec := range append(append([]tableSpec{}, nbs.upstream.specs...), nbs.upstream.appendix...) {
+		if _, ok := replaced[spec.name]; ok {
+			continue
+		}
+
+		cs, ok := css[spec.name]
+		if !ok {
+			return ErrSpecWithoutChunkSource
+		}
+
+		index, err := cs.index()
+		if err != nil {
+			return err
+		}
+
+		if index.Format() != tableFormatV2 {
+			continue
+		}
+
+		legacy, err := p.rewriteAll(ctx, chunkSources{cs}, SnappyCompression, nbs.stats)
+		if err != nil {
+			return err
+		}
+
+		name, err := legacy.hash()
+		if err != nil {
+			return err
+		}
+
+		count, err := legacy.count()
+		if err != nil {
+			return err
+		}
+
+		err = legacy.Close()
+		if err != nil {
+			return err
+		}
+
+		replaced[spec.name] = tableSpec{name: name, chunkCount: count}
+		replacedSpecs = append(replacedSpecs, spec)
+	}
+
+	if len(replaced) == 0 {
+		return nil
+	}
+
+	replace := func(specs []tableSpec) []tableSpec {
+		var res []tableSpec
+		for _, spec := range specs {
+			if legacy, ok := replaced[spec.name]; ok {
+				spec = legacy
+			}
+			res = append(res, spec)
+		}
+		return res
+	}
+
+	newContents := nbs.upstream
+	newContents.specs = replace(nbs.upstream.specs)
+	newContents.appendix = replace(nbs.upstream.appendix)
+	newContents.lock = generateLockHash(newContents.root, newContents.specs, newContents.appendix)
+
+	upstream, err := nbs.mm.Update(ctx, nbs.upstream.lock, newContents, nbs.stats, nil)
+	if err != nil {
+		return err
+	}
+
+	if upstream.lock != newContents.lock {
+		return errors.New("concurrent manifest edit while converting table files")
+	}
+
+	nbs.upstream = upstream
+	newTables, err := nbs.tables.Rebase(ctx, upstream.specs, nbs.stats)
+	if err != nil {
+		return err
+	}
+
+	oldTables := nbs.tables
+	nbs.tables = newTables
+	err = oldTables.Close()
+	if err != nil {
+		return err
+	}
+
+	return nbs.removeTableFiles(replacedSpecs, upstream)
+}
+
+// removeTableFiles removes the table files of |specs| which are not referenced by |contents|.
+func (nbs *NomsBlockStore) removeTableFiles(specs []tableSpec, contents manifestContents) error {
+	fsPersister, ok := nbs.localPersister()
+	if !ok {
+		return chunks.ErrUnsupportedOperation
+	}
+
+	err := fsPersister.fc.ShrinkCache()
 	if err != nil {
 		return err
 	}
 
+	ss := contents.getSpecSet()
+	ea := make(gcErrAccum)
+	for _, spec := range specs {
+		if _, ok := ss[spec.name]; ok || spec.name == journalAddr {
+			continue
+		}
+
+		filePath := filepath.Join(fsPersister.dir, spec.name.String())
+		if err := file.Remove(filePath); err != nil && !os.IsNotExist(err) {
+			ea.add(filePath, err)
+		}
+	}
+
+	if !ea.isEmpty() {
+		return ea
+	}
+
+	return nil
+}
+
+// BeginGC implements chunks.ChunkStoreGarbageCollector. The chunks which are pending in the store, written but not
+// yet committed, are passed to |keeper| first.
+func (nbs *NomsBlockStore) BeginGC(keeper func(hash.Hash) bool) error {
+	nbs.mu.Lock()
+	defer nbs.mu.Unlock()
+
+	if nbs.keeperFunc != nil {
+		return chunks.ErrGCInProgress
+	}
+
+	if nbs.mt != nil {
+		for _, rec := range nbs.mt.order {
+			keeper(hash.Hash(*rec.a))
+		}
+	}
+
+	for _, src := range nbs.tables.novel {
+		idx, err := src.index()
+		if err != nil {
+			return err
+		}
+
+		for i := uint32(0); i < idx.ChunkCount(); i++ {
+			var a addr
+			idx.IndexEntry(i, &a)
+			if a != tableDictAddr {
+				keeper(hash.Hash(a))
+			}
+		}
+	}
+
+	nbs.gcCond = sync.NewCond(&nbs.mu)
+	nbs.keeperFunc = keeper
 	return nil
 }
 
+// EndGC implements chunks.ChunkStoreGarbageCollector.
+func (nbs *NomsBlockStore) EndGC() {
+	nbs.mu.Lock()
+	defer nbs.mu.Unlock()
+
+	nbs.keeperFunc = nil
+	if nbs.gcCond != nil {
+		nbs.gcCond.Broadcast()
+	}
+}
+
+// waitForGC blocks until the collection in progress ends.
+//
+// callers must acquire lock |nbs.mu|
+func (nbs *NomsBlockStore) waitForGC() {
+	for nbs.keeperFunc != nil {
+		nbs.gcCond.Wait()
+	}
+}
+
 // SetRootChunk changes the root chunk hash from the previous value to the new root.
 func (nbs *NomsBlockStore) SetRootChunk(ctx context.Context, root, previous hash.Hash) error {
 	nbs.mu.Lock()
@@ -44,7 +44,7 @@ func makeTestLocalStore(t *testing.T, maxTableFiles int) (st *NomsBlockStore, no
 	require.NoError(t, err)
 
 	// create a v5 manifest
-	_, err = fileManifest{nomsDir}.Update(ctx, addr{}, manifestContents{}, &Stats{}, nil)
+	_, err = fileManifest{dir: nomsDir}.Update(ctx, addr{}, manifestContents{}, &Stats{}, nil)
 	require.NoError(t, err)
 
 	st, err = newLocalStore(ctx, types.Format_Default.VersionString(), nomsDir, defaultMemTableSize, maxTableFiles)
@@ -285,6 +285,84 @@ func TestNBSCopyGC(t *testing.T) {
 	}
 }
 
+func TestNBSOnlineGC(t *testing.T) {
+	ctx := context.Background()
+	st, _ := makeTestLocalStore(t, 8)
+
+	keepers := makeChunkSet(64, 64)
+	tossers := makeChunkSet(64, 64)
+	for _, c := range keepers {
+		require.NoError(t, st.Put(ctx, c))
+	}
+	for _, c := range tossers {
+		require.NoError(t, st.Put(ctx, c))
+	}
+
+	r, err := st.Root(ctx)
+	require.NoError(t, err)
+	ok, err := st.Commit(ctx, r, r)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	pending := chunks.NewChunk([]byte("pending"))
+	require.NoError(t, st.Put(ctx, pending))
+
+	var mu sync.Mutex
+	written := hash.NewHashSet()
+	keeper := func(h hash.Hash) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		written.Insert(h)
+		return false
+	}
+	require.NoError(t, st.BeginGC(keeper))
+	assert.ErrorIs(t, st.BeginGC(keeper), chunks.ErrGCInProgress)
+	assert.True(t, written.Has(pending.Hash()))
+
+	keepChan := make(chan []hash.Hash, 16)
+	var msErr error
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		msErr = st.MarkAndSweepChunks(ctx, r, keepChan, nil)
+		wg.Done()
+	}()
+
+	// a chunk written while the collection runs is reported to the keeper
+	concurrent := chunks.NewChunk([]byte("concurrent"))
+	require.NoError(t, st.Put(ctx, concurrent))
+
+	for h := range keepers {
+		keepChan <- []hash.Hash{h}
+	}
+	mu.Lock()
+	assert.True(t, written.Has(concurrent.Hash()))
+	for h := range written {
+		keepChan <- []hash.Hash{h}
+	}
+	mu.Unlock()
+	close(keepChan)
+	wg.Wait()
+	require.NoError(t, msErr)
+	st.EndGC()
+
+	for h, c := range keepers {
+		out, err := st.Get(ctx, h)
+		require.NoError(t, err)
+		assert.Equal(t, c, out)
+	}
+	for _, c := range []chunks.Chunk{pending, concurrent} {
+		out, err := st.Get(ctx, c.Hash())
+		require.NoError(t, err)
+		assert.Equal(t, c, out)
+	}
+	for h := range tossers {
+		out, err := st.Get(ctx, h)
+		require.NoError(t, err)
+		assert.Equal(t, chunks.EmptyChunk, out)
+	}
+}
+
 func persistTableFileSources(t *testing.T, p tablePersister, numTableFiles int) (map[hash.Hash]uint32, []hash.Hash) {
 	tableFileMap := make(map[hash.Hash]uint32, numTableFiles)
 	mapIds := make([]hash.Hash, numTableFiles)
@@ -0,0 +1,147 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nbs
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+const (
+	// minTableDictChunks is the number of chunks a table file must hold for a dictionary to be trained for it.
+	minTableDictChunks = 64
+
+	// maxTableDictSize bounds the size of the dictionary of a table file.
+	maxTableDictSize = 32 * 1024
+
+	// minTableDictSize is the size below which a trained dictionary is not worth storing.
+	minTableDictSize = 256
+
+	// maxTableDictSamples and maxTableDictSampleBytes bound the chunks sampled to train a dictionary.
+	maxTableDictSamples     = 1024
+	maxTableDictSampleBytes = 1 << 20
+
+	dictSegmentSize = 64
+	dictKmerSize    = 8
+)
+
+// sampleChunks returns the indexes of the chunks of a table file of |count| chunks to sample for training its
+// dictionary, spread evenly across the file.
+func sampleChunks(count int) []int {
+	n := count
+	if n > maxTableDictSamples {
+		n = maxTableDictSamples
+	}
+
+	idxs := make([]int, n)
+	for i := range idxs {
+		idxs[i] = int(uint64(i) * uint64(count) / uint64(n))
+	}
+	return idxs
+}
+
+// trainTableDict trains a raw content zstd dictionary on |samples|, the data of chunks sampled from a table file. It
+// returns nil when there are too few samples, or they share too little for a dictionary to help.
+//
+// The dictionary is assembled from fixed size segments of the samples. Segments are scored by how many samples share
+// the byte sequences they hold, and chosen greedily, not counting sequences already covered by chosen segments. The
+// best segments come last in the dictionary, where zstd finds matches most cheaply.
+func trainTableDict(samples [][]byte) []byte {
+	if len(samples) < minTableDictChunks {
+		return nil
+	}
+
+	var total int
+	for i, s := range samples {
+		total += len(s)
+		if total > maxTableDictSampleBytes {
+			samples = samples[:i]
+			break
+		}
+	}
+
+	// count the samples holding each k-mer
+	freq := make(map[uint64]uint32)
+	seen := make(map[uint64]struct{})
+	for _, s := range samples {
+		for k := range seen {
+			delete(seen, k)
+		}
+		for i := 0; i+dictKmerSize <= len(s); i++ {
+			k := binary.LittleEndian.Uint64(s[i:])
+			if _, ok := seen[k]; !ok {
+				seen[k] = struct{}{}
+				freq[k]++
+			}
+		}
+	}
+
+	type segment struct {
+		data  []byte
+		score uint64
+	}
+
+	score := func(seg []byte) (score uint64) {
+		for i := 0; i+dictKmerSize <= len(seg); i++ {
+			if f := freq[binary.LittleEndian.Uint64(seg[i:])]; f > 1 {
+				score += uint64(f - 1)
+			}
+		}
+		return score
+	}
+
+	var segs []segment
+	for _, s := range samples {
+		for off := 0; off+dictSegmentSize <= len(s); off += dictSegmentSize {
+			seg := s[off : off+dictSegmentSize]
+			if sc := score(seg); sc > 0 {
+				segs = append(segs, segment{seg, sc})
+			}
+		}
+	}
+
+	sort.SliceStable(segs, func(i, j int) bool {
+		return segs[i].score > segs[j].score
+	})
+
+	var chosen [][]byte
+	var size int
+	for _, seg := range segs {
+		if size+len(seg.data) > maxTableDictSize {
+			break
+		}
+
+		// skip segments made redundant by the segments chosen before them
+		if score(seg.data) == 0 {
+			continue
+		}
+
+		chosen = append(chosen, seg.data)
+		size += len(seg.data)
+		for i := 0; i+dictKmerSize <= len(seg.data); i++ {
+			delete(freq, binary.LittleEndian.Uint64(seg.data[i:]))
+		}
+	}
+
+	if size < minTableDictSize {
+		return nil
+	}
+
+	dict := make([]byte, 0, size)
+	for i := len(chosen) - 1; i >= 0; i-- {
+		dict = append(dict, chosen[i]...)
+	}
+	return dict
+}
@@ -0,0 +1,770 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nbs
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/dolthub/dolt/go/libraries/utils/file"
+	"github.com/dolthub/dolt/go/store/util/tempfiles"
+)
+
+// Table files and manifests may be encrypted at rest with envelope encryption. Each sealed table file and manifest
+// is encrypted with AES-GCM under its own random data key, which is wrapped by a key encryption key of a Keyring.
+// Chunks are still addressed by the hashes of their plaintext, so sealing a table file changes neither its name nor
+// its index, and the chunks of sealed table files are deduplicated and pulled like any others.
+//
+// A sealed table file is the plaintext table file split into segments of |sealedSegmentSize| bytes, each sealed with
+// the data key and its segment number as nonce, followed by a trailer:
+//
+// |-- keyIDSize --|-- wrappedKeySize --|-- uint64 --|-- uint32 --|-- magicNumberSize --|
+// | key id        | wrapped data key   | plain size | seg size   | sealedTableMagic    |
+//
+// The key id names the key encryption key which wrapped the data key, and the rest of the trailer is authenticated
+// as additional data of the wrapped key.
+//
+// A sealed manifest is |sealedManifestMagic|, followed by the key id and wrapped data key, followed by the plaintext
+// manifest sealed with the data key.
+
+const (
+	// sealedTableMagic ends the trailer of sealed table files. It shares its first 7 bytes with |magicNumber|.
+	sealedTableMagic = "\xff\xb5\xd8\xc2\x24\x63\xee\xe1"
+
+	// sealedManifestMagic starts sealed manifests. Plaintext manifests start with their storage version.
+	sealedManifestMagic = "\xffNBSSEAL"
+
+	encryptionKeySize = 32
+	keyIDSize         = 16
+	gcmNonceSize      = 12
+	gcmTagSize        = 16
+	wrappedKeySize    = gcmNonceSize + encryptionKeySize + gcmTagSize
+
+	sealedSegmentSize      = 16 * 1024
+	sealedTableTrailerSize = keyIDSize + wrappedKeySize + uint64Size + uint32Size + magicNumberSize
+)
+
+// ErrEncryptionKeysRequired is returned when reading a sealed table file or manifest without a Keyring.
+var ErrEncryptionKeysRequired = errors.New("the store is encrypted at rest, but no encryption keys were provided")
+
+// ErrUnknownEncryptionKey is returned when reading a table file or manifest sealed with a key missing from the Keyring.
+var ErrUnknownEncryptionKey = errors.New("the store is encrypted with a key which is not in the encryption key file")
+
+// ErrChunkJournalEncryption is returned when opening a store with both a chunk journal and a Keyring.
+var ErrChunkJournalEncryption = errors.New("the chunk journal does not support encryption at rest")
+
+type keyID [keyIDSize]byte
+
+func (id keyID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// Keyring holds the key encryption keys of a store encrypted at rest. Table files and manifests are sealed with the
+// active key, and may be read with any key of the Keyring, so that keys can be rotated by making a new key active
+// and garbage collecting the store, which seals its table files with the new key.
+type Keyring struct {
+	active keyID
+	keks   map[keyID]cipher.AEAD
+}
+
+// keyFile is the format of encryption key files:
+//
+//	{"active": "2022-10", "keys": {"2022-09": "<base64 key>", "2022-10": "<base64 key>"}}
+//
+// Keys are 32 bytes long, and are identified in sealed files by a hash of the key rather than their name.
+type keyFile struct {
+	Active string            `json:"active"`
+	Keys   map[string]string `json:"keys"`
+}
+
+// ReadKeyringFile reads the Keyring in the encryption key file at |path|.
+func ReadKeyringFile(path string) (*Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	kr, err := ParseKeyring(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key file %s: %w", path, err)
+	}
+
+	return kr, nil
+}
+
+// ParseKeyring parses the Keyring of an encryption key file.
+func ParseKeyring(data []byte) (*Keyring, error) {
+	var kf keyFile
+	err := json.Unmarshal(data, &kf)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string][]byte, len(kf.Keys))
+	for name, encoded := range kf.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key '%s' is not base64 encoded: %w", name, err)
+		}
+		keys[name] = key
+	}
+
+	return NewKeyring(kf.Active, keys)
+}
+
+// NewKeyring returns a Keyring of the 32 byte |keys|, which seals table files and manifests with the key named
+// |active|.
+func NewKeyring(active string, keys map[string][]byte) (*Keyring, error) {
+	if _, ok := keys[active]; !ok {
+		return nil, fmt.Errorf("the active key '%s' is not one of the keys", active)
+	}
+
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	kr := &Keyring{keks: make(map[keyID]cipher.AEAD, len(keys))}
+	for _, name := range names {
+		key := keys[name]
+		if len(key) != encryptionKeySize {
+			return nil, fmt.Errorf("key '%s' is %d bytes long, keys must be %d bytes long", name, len(key), encryptionKeySize)
+		}
+
+		id := keyIDOf(key)
+		if _, ok := kr.keks[id]; ok {
+			return nil, fmt.Errorf("key '%s' is a duplicate of another key", name)
+		}
+
+		kek, err := newGCM(key)
+		if err != nil {
+			return nil, err
+		}
+
+		kr.keks[id] = kek
+		if name == active {
+			kr.active = id
+		}
+	}
+
+	return kr, nil
+}
+
+func keyIDOf(key []byte) keyID {
+	sum := sha256.Sum256(key)
+
+	var id keyID
+	copy(id[:], sum[:])
+	return id
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// newDataKey returns a new random data key.
+func newDataKey() ([]byte, error) {
+	key := make([]byte, encryptionKeySize)
+	_, err := io.ReadFull(rand.Reader, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// wrapKey encrypts the data key |key| with the active key of the Keyring, authenticating |aad| with it.
+func (kr *Keyring) wrapKey(key, aad []byte) ([]byte, error) {
+	nonce := make([]byte, gcmNonceSize, wrappedKeySize)
+	_, err := io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return kr.keks[kr.active].Seal(nonce, nonce, key, aad), nil
+}
+
+// unwrapKey decrypts the data key |wrapped|, which was wrapped by the key |id|.
+func (kr *Keyring) unwrapKey(id keyID, wrapped, aad []byte) (cipher.AEAD, error) {
+	if kr == nil {
+		return nil, ErrEncryptionKeysRequired
+	}
+
+	kek, ok := kr.keks[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: key id %s", ErrUnknownEncryptionKey, id)
+	}
+
+	key, err := kek.Open(nil, wrapped[:gcmNonceSize], wrapped[gcmNonceSize:], aad)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data key: %w", err)
+	}
+
+	return newGCM(key)
+}
+
+// isActive returns whether |env| was sealed with the active key of the Keyring.
+func (kr *Keyring) isActive(env *tableEnvelope) bool {
+	return env != nil && env.keyID == kr.active
+}
+
+// tableEnvelope describes how a table file was sealed, and decrypts its segments.
+type tableEnvelope struct {
+	keyID       keyID
+	dataKey     cipher.AEAD
+	size        uint64
+	segmentSize uint64
+}
+
+// sealedTableSize returns the size of the sealed table file of |size| plaintext bytes.
+func sealedTableSize(size uint64) uint64 {
+	segments := (size + sealedSegmentSize - 1) / sealedSegmentSize
+	return size + segments*gcmTagSize + sealedTableTrailerSize
+}
+
+// isSealedTable returns whether |tail|, the end of a table file, is the end of a sealed table file.
+func isSealedTable(tail []byte) bool {
+	return len(tail) >= magicNumberSize && string(tail[len(tail)-magicNumberSize:]) == sealedTableMagic
+}
+
+func trailerAAD(id keyID, size uint64, segmentSize uint32) []byte {
+	aad := make([]byte, keyIDSize+uint64Size+uint32Size+magicNumberSize)
+	copy(aad, id[:])
+	binary.BigEndian.PutUint64(aad[keyIDSize:], size)
+	binary.BigEndian.PutUint32(aad[keyIDSize+uint64Size:], segmentSize)
+	copy(aad[keyIDSize+uint64Size+uint32Size:], sealedTableMagic)
+	return aad
+}
+
+// parseTableEnvelope parses the trailer of a sealed table file, and unwraps its data key with |keys|.
+func parseTableEnvelope(keys *Keyring, trailer []byte) (*tableEnvelope, error) {
+	if len(trailer) != sealedTableTrailerSize || !isSealedTable(trailer) {
+		return nil, ErrInvalidTableFile
+	}
+
+	var id keyID
+	copy(id[:], trailer)
+	wrapped := trailer[keyIDSize : keyIDSize+wrappedKeySize]
+	size := binary.BigEndian.Uint64(trailer[keyIDSize+wrappedKeySize:])
+	segmentSize := binary.BigEndian.Uint32(trailer[keyIDSize+wrappedKeySize+uint64Size:])
+
+	if segmentSize == 0 {
+		return nil, ErrInvalidTableFile
+	}
+
+	dataKey, err := keys.unwrapKey(id, wrapped, trailerAAD(id, size, segmentSize))
+	if err != nil {
+		return nil, err
+	}
+
+	return &tableEnvelope{keyID: id, dataKey: dataKey, size: size, segmentSize: uint64(segmentSize)}, nil
+}
+
+func (env *tableEnvelope) sealedSegmentLen(seg uint64) uint64 {
+	start := seg * env.segmentSize
+	if start+env.segmentSize > env.size {
+		return env.size - start + gcmTagSize
+	}
+	return env.segmentSize + gcmTagSize
+}
+
+func (env *tableEnvelope) sealedOffset(seg uint64) uint64 {
+	return seg * (env.segmentSize + gcmTagSize)
+}
+
+// sealedLen returns the size of the sealed segments of the table file, without its trailer.
+func (env *tableEnvelope) sealedLen() uint64 {
+	if env.size == 0 {
+		return 0
+	}
+	last := (env.size - 1) / env.segmentSize
+	return env.sealedOffset(last) + env.sealedSegmentLen(last)
+}
+
+func segmentNonce(seg uint64) []byte {
+	nonce := make([]byte, gcmNonceSize)
+	binary.BigEndian.PutUint64(nonce[gcmNonceSize-uint64Size:], seg)
+	return nonce
+}
+
+// open decrypts the plaintext at |off| into |p|. |sealed| holds the sealed segments of the table file starting with
+// the segment of |off|, and is overwritten.
+func (env *tableEnvelope) open(p []byte, off uint64, sealed []byte) error {
+	seg := off / env.segmentSize
+	skip := off - seg*env.segmentSize
+
+	for n := 0; n < len(p); seg++ {
+		l := env.sealedSegmentLen(seg)
+		if uint64(len(sealed)) < l {
+			return ErrInvalidTableFile
+		}
+
+		plain, err := env.dataKey.Open(sealed[:0], segmentNonce(seg), sealed[:l], nil)
+		if err != nil {
+			return fmt.Errorf("%w: failed to decrypt segment %d: %s", ErrInvalidTableFile, seg, err.Error())
+		}
+
+		n += copy(p[n:], plain[skip:])
+		skip = 0
+		sealed = sealed[l:]
+	}
+
+	return nil
+}
+
+// readerAt returns a tableReaderAt which reads the plaintext of the sealed table file read by |r|. A nil envelope is
+// the envelope of plaintext table files, which returns |r|.
+func (env *tableEnvelope) readerAt(r tableReaderAt) tableReaderAt {
+	if env == nil {
+		return r
+	}
+	return &sealedReaderAt{r, env}
+}
+
+// sealedReaderAt reads the plaintext of a sealed table file.
+type sealedReaderAt struct {
+	r   tableReaderAt
+	env *tableEnvelope
+}
+
+func (sra *sealedReaderAt) ReadAtWithStats(ctx context.Context, p []byte, off int64, stats *Stats) (int, error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+
+	start := uint64(off)
+	if start >= sra.env.size {
+		return 0, io.EOF
+	}
+
+	end := start + uint64(len(p))
+	if end > sra.env.size {
+		end = sra.env.size
+	}
+
+	if end == start {
+		return 0, nil
+	}
+
+	first, last := start/sra.env.segmentSize, (end-1)/sra.env.segmentSize
+	sealedStart := sra.env.sealedOffset(first)
+	sealed := make([]byte, sra.env.sealedOffset(last)+sra.env.sealedSegmentLen(last)-sealedStart)
+
+	n, err := sra.r.ReadAtWithStats(ctx, sealed, int64(sealedStart), stats)
+	if err != nil && !(err == io.EOF && n == len(sealed)) {
+		return 0, err
+	}
+
+	if n != len(sealed) {
+		return 0, ErrInvalidTableFile
+	}
+
+	err = sra.env.open(p[:end-start], start, sealed)
+	if err != nil {
+		return 0, err
+	}
+
+	if end-start < uint64(len(p)) {
+		return int(end - start), io.EOF
+	}
+
+	return len(p), nil
+}
+
+// readTableTail reads the index and footer of a table file of |chunkCount| chunks, which may be sealed. |readTail|
+// reads the last |n| bytes of the table file. The envelope of sealed table files is returned, to read their chunks.
+func readTableTail(keys *Keyring, chunkCount uint32, readTail func(n uint64) ([]byte, error)) ([]byte, *tableEnvelope, error) {
+	tailSize := indexSize(chunkCount) + footerSize
+	tail, err := readTail(tailSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !isSealedTable(tail) {
+		return tail, nil, nil
+	}
+
+	if keys == nil {
+		return nil, nil, ErrEncryptionKeysRequired
+	}
+
+	trailer := tail
+	if uint64(len(trailer)) < sealedTableTrailerSize {
+		trailer, err = readTail(sealedTableTrailerSize)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	env, err := parseTableEnvelope(keys, trailer[len(trailer)-sealedTableTrailerSize:])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if tailSize > env.size {
+		return nil, nil, ErrInvalidTableFile
+	}
+
+	start := env.size - tailSize
+	sealedStart := env.sealedOffset(start / env.segmentSize)
+	sealed, err := readTail(env.sealedLen() - sealedStart + sealedTableTrailerSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	buff := make([]byte, tailSize)
+	err = env.open(buff, start, sealed[:len(sealed)-sealedTableTrailerSize])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return buff, env, nil
+}
+
+// readFileTail returns a function reading the end of the table file |f| for readTableTail.
+func readFileTail(f io.ReaderAt, size int64) func(n uint64) ([]byte, error) {
+	return func(n uint64) ([]byte, error) {
+		if n > uint64(size) {
+			return nil, ErrInvalidTableFile
+		}
+
+		buff := make([]byte, n)
+		_, err := f.ReadAt(buff, size-int64(n))
+		if err != nil {
+			return nil, err
+		}
+
+		return buff, nil
+	}
+}
+
+// tableSealer seals the table file written to it into |wr|. The trailer of the sealed table file is written by Close.
+type tableSealer struct {
+	wr      io.Writer
+	keys    *Keyring
+	key     []byte
+	dataKey cipher.AEAD
+	buff    []byte
+	seg     uint64
+	size    uint64
+}
+
+func newTableSealer(wr io.Writer, keys *Keyring) (*tableSealer, error) {
+	key, err := newDataKey()
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tableSealer{
+		wr:      wr,
+		keys:    keys,
+		key:     key,
+		dataKey: dataKey,
+		buff:    make([]byte, 0, sealedSegmentSize+gcmTagSize),
+	}, nil
+}
+
+func (ts *tableSealer) Write(p []byte) (int, error) {
+	n := 0
+	for len(p) > 0 {
+		l := sealedSegmentSize - len(ts.buff)
+		if l > len(p) {
+			l = len(p)
+		}
+
+		ts.buff = append(ts.buff, p[:l]...)
+		p = p[l:]
+		n += l
+
+		if len(ts.buff) == sealedSegmentSize {
+			err := ts.sealSegment()
+			if err != nil {
+				return n, err
+			}
+		}
+	}
+
+	return n, nil
+}
+
+func (ts *tableSealer) sealSegment() error {
+	sealed := ts.dataKey.Seal(ts.buff[:0], segmentNonce(ts.seg), ts.buff, nil)
+	_, err := ts.wr.Write(sealed)
+	if err != nil {
+		return err
+	}
+
+	ts.size += uint64(len(ts.buff))
+	ts.seg++
+	ts.buff = ts.buff[:0]
+	return nil
+}
+
+// Close seals the last segment of the table file and writes its trailer. It does not close the underlying writer.
+func (ts *tableSealer) Close() error {
+	if len(ts.buff) > 0 {
+		err := ts.sealSegment()
+		if err != nil {
+			return err
+		}
+	}
+
+	id := ts.keys.active
+	aad := trailerAAD(id, ts.size, sealedSegmentSize)
+	wrapped, err := ts.keys.wrapKey(ts.key, aad)
+	if err != nil {
+		return err
+	}
+
+	trailer := make([]byte, 0, sealedTableTrailerSize)
+	trailer = append(trailer, id[:]...)
+	trailer = append(trailer, wrapped...)
+	trailer = append(trailer, aad[keyIDSize:]...)
+
+	_, err = ts.wr.Write(trailer)
+	return err
+}
+
+// envelope returns the envelope of the table file sealed by a closed tableSealer.
+func (ts *tableSealer) envelope() *tableEnvelope {
+	return &tableEnvelope{keyID: ts.keys.active, dataKey: ts.dataKey, size: ts.size, segmentSize: sealedSegmentSize}
+}
+
+// sealTable seals the table file |data| with |keys|. |data| is returned as it is when |keys| are nil.
+func sealTable(keys *Keyring, data []byte) ([]byte, *tableEnvelope, error) {
+	if keys == nil {
+		return data, nil, nil
+	}
+
+	buff := bytes.NewBuffer(make([]byte, 0, sealedTableSize(uint64(len(data)))))
+	ts, err := newTableSealer(buff, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, err = ts.Write(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = ts.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return buff.Bytes(), ts.envelope(), nil
+}
+
+// writeSealed writes the table file written by |write| to |wr|, sealed with |keys| unless they are nil.
+func writeSealed(wr io.Writer, keys *Keyring, write func(wr io.Writer) error) error {
+	if keys == nil {
+		return write(wr)
+	}
+
+	ts, err := newTableSealer(wr, keys)
+	if err != nil {
+		return err
+	}
+
+	err = write(ts)
+	if err != nil {
+		return err
+	}
+
+	return ts.Close()
+}
+
+// writeSealedTableFile writes the table file written by |write| to |path|, sealed with |keys|. The table file is
+// written to a temporary file in |dir| first, which replaces any file at |path|.
+func writeSealedTableFile(dir, path string, keys *Keyring, write func(wr io.Writer) error) error {
+	tempName, err := func() (tempName string, ferr error) {
+		var temp *os.File
+		temp, ferr = tempfiles.MovableTempFileProvider.NewFile(dir, tempTablePrefix)
+
+		if ferr != nil {
+			return "", ferr
+		}
+
+		defer func() {
+			closeErr := temp.Close()
+
+			if ferr == nil {
+				ferr = closeErr
+			}
+		}()
+
+		return temp.Name(), writeSealed(temp, keys, write)
+	}()
+
+	if err != nil {
+		if tempName != "" {
+			_ = file.Remove(tempName)
+		}
+		return err
+	}
+
+	return file.Rename(tempName, path)
+}
+
+// sealedWithActiveKey returns whether the table file at |path| is sealed with the active key of the Keyring.
+func (kr *Keyring) sealedWithActiveKey(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+
+	if info.Size() < sealedTableTrailerSize {
+		return false, nil
+	}
+
+	trailer, err := readFileTail(f, info.Size())(sealedTableTrailerSize)
+	if err != nil {
+		return false, err
+	}
+
+	if !isSealedTable(trailer) {
+		return false, nil
+	}
+
+	var id keyID
+	copy(id[:], trailer)
+	return id == kr.active, nil
+}
+
+// readerAtAdapter adapts a tableReaderAt to an io.ReaderAt.
+type readerAtAdapter struct {
+	ctx context.Context
+	r   tableReaderAt
+}
+
+func (ra readerAtAdapter) ReadAt(p []byte, off int64) (int, error) {
+	return ra.r.ReadAtWithStats(ra.ctx, p, off, &Stats{})
+}
+
+// sealManifest seals the plaintext manifest |data| with the active key of the Keyring.
+func (kr *Keyring) sealManifest(data []byte) ([]byte, error) {
+	key, err := newDataKey()
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aad := append([]byte(sealedManifestMagic), kr.active[:]...)
+	wrapped, err := kr.wrapKey(key, aad)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed := make([]byte, 0, len(aad)+wrappedKeySize+len(data)+gcmTagSize)
+	sealed = append(sealed, aad...)
+	sealed = append(sealed, wrapped...)
+	return dataKey.Seal(sealed, make([]byte, gcmNonceSize), data, aad), nil
+}
+
+// openManifest decrypts the sealed manifest |data| with |keys|.
+func openManifest(keys *Keyring, data []byte) ([]byte, error) {
+	headerSize := len(sealedManifestMagic) + keyIDSize + wrappedKeySize
+	if len(data) < headerSize+gcmTagSize {
+		return nil, ErrCorruptManifest
+	}
+
+	var id keyID
+	copy(id[:], data[len(sealedManifestMagic):])
+	aad := data[:len(sealedManifestMagic)+keyIDSize]
+
+	dataKey, err := keys.unwrapKey(id, data[len(aad):headerSize], aad)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := dataKey.Open(nil, make([]byte, gcmNonceSize), data[headerSize:], aad)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decrypt manifest: %s", ErrCorruptManifest, err.Error())
+	}
+
+	return plain, nil
+}
+
+// parseManifestWithKeys parses the manifest read from |r|, which is decrypted with |keys| if it is sealed.
+func parseManifestWithKeys(r io.Reader, keys *Keyring) (manifestContents, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return manifestContents{}, err
+	}
+
+	if bytes.HasPrefix(data, []byte(sealedManifestMagic)) {
+		data, err = openManifest(keys, data)
+		if err != nil {
+			return manifestContents{}, err
+		}
+	}
+
+	return parseManifest(bytes.NewReader(data))
+}
+
+// writeManifestWithKeys writes |contents| to |wr|, sealed with |keys| if they are not nil.
+func writeManifestWithKeys(wr io.Writer, contents manifestContents, keys *Keyring) error {
+	if keys == nil {
+		return writeManifest(wr, contents)
+	}
+
+	var buff bytes.Buffer
+	err := writeManifest(&buff, contents)
+	if err != nil {
+		return err
+	}
+
+	sealed, err := keys.sealManifest(buff.Bytes())
+	if err != nil {
+		return err
+	}
+
+	_, err = wr.Write(sealed)
+	return err
+}
@@ -0,0 +1,348 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nbs
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/utils/file"
+	"github.com/dolthub/dolt/go/store/blobstore"
+	"github.com/dolthub/dolt/go/store/chunks"
+	"github.com/dolthub/dolt/go/store/constants"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+func randomKey(t *testing.T) []byte {
+	key := make([]byte, encryptionKeySize)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	return key
+}
+
+func makeTestKeyring(t *testing.T, active string, keys map[string][]byte) *Keyring {
+	kr, err := NewKeyring(active, keys)
+	require.NoError(t, err)
+	return kr
+}
+
+func TestParseKeyring(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString(randomKey(t))
+
+	kr, err := ParseKeyring([]byte(fmt.Sprintf(`{"active": "k1", "keys": {"k1": %q}}`, key)))
+	require.NoError(t, err)
+	assert.NotNil(t, kr)
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"invalid json", `{"active": `},
+		{"no keys", `{"active": "k1", "keys": {}}`},
+		{"unknown active key", fmt.Sprintf(`{"active": "k2", "keys": {"k1": %q}}`, key)},
+		{"invalid base64", `{"active": "k1", "keys": {"k1": "not base64!"}}`},
+		{"short key", fmt.Sprintf(`{"active": "k1", "keys": {"k1": %q}}`, base64.StdEncoding.EncodeToString([]byte("short")))},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseKeyring([]byte(test.data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSealedTableReads(t *testing.T) {
+	ctx := context.Background()
+	keys := makeSingleKeyring(t)
+
+	data := make([]byte, 3*sealedSegmentSize+123)
+	_, err := rand.Read(data)
+	require.NoError(t, err)
+
+	sealed, env, err := sealTable(keys, data)
+	require.NoError(t, err)
+	assert.Equal(t, sealedTableSize(uint64(len(data))), uint64(len(sealed)))
+	assert.False(t, bytes.Contains(sealed, data[:64]))
+
+	tra := env.readerAt(tableReaderAtFromBytes(sealed))
+	reads := []struct {
+		off, len int
+	}{
+		{0, 10},
+		{0, len(data)},
+		{sealedSegmentSize - 5, 10},
+		{sealedSegmentSize, sealedSegmentSize},
+		{len(data) - 200, 200},
+		{100, 2*sealedSegmentSize + 7},
+	}
+	for _, r := range reads {
+		p := make([]byte, r.len)
+		n, err := tra.ReadAtWithStats(ctx, p, int64(r.off), &Stats{})
+		require.NoError(t, err)
+		assert.Equal(t, r.len, n)
+		assert.Equal(t, data[r.off:r.off+r.len], p, "read of %d bytes at %d", r.len, r.off)
+	}
+
+	// sealing without keys leaves the table unchanged
+	plain, env, err := sealTable(nil, data)
+	require.NoError(t, err)
+	assert.Nil(t, env)
+	assert.Equal(t, data, plain)
+}
+
+func makeSingleKeyring(t *testing.T) *Keyring {
+	return makeTestKeyring(t, "k1", map[string][]byte{"k1": randomKey(t)})
+}
+
+func TestReadTableTail(t *testing.T) {
+	chnks := similarChunks(64)
+	data, _, err := buildTable(chnks)
+	require.NoError(t, err)
+	tailSize := indexSize(uint32(len(chnks))) + footerSize
+
+	tail, env, err := readTableTail(nil, uint32(len(chnks)), readFileTail(bytes.NewReader(data), int64(len(data))))
+	require.NoError(t, err)
+	assert.Nil(t, env)
+	assert.Equal(t, data[uint64(len(data))-tailSize:], tail)
+
+	keys := makeSingleKeyring(t)
+	sealed, _, err := sealTable(keys, data)
+	require.NoError(t, err)
+	readTail := readFileTail(bytes.NewReader(sealed), int64(len(sealed)))
+
+	tail, env, err = readTableTail(keys, uint32(len(chnks)), readTail)
+	require.NoError(t, err)
+	require.NotNil(t, env)
+	assert.Equal(t, uint64(len(data)), env.size)
+	assert.Equal(t, data[uint64(len(data))-tailSize:], tail)
+
+	_, _, err = readTableTail(nil, uint32(len(chnks)), readTail)
+	assert.ErrorIs(t, err, ErrEncryptionKeysRequired)
+
+	_, _, err = readTableTail(makeSingleKeyring(t), uint32(len(chnks)), readTail)
+	assert.ErrorIs(t, err, ErrUnknownEncryptionKey)
+}
+
+func TestSealedManifest(t *testing.T) {
+	keys := makeSingleKeyring(t)
+	contents := makeContents("locker", "nbsroot", []tableSpec{{computeAddr([]byte("table")), 7}}, nil)
+
+	buff := &bytes.Buffer{}
+	require.NoError(t, writeManifestWithKeys(buff, contents, keys))
+	assert.True(t, bytes.HasPrefix(buff.Bytes(), []byte(sealedManifestMagic)))
+	assert.False(t, bytes.Contains(buff.Bytes(), []byte(contents.root.String())))
+
+	parsed, err := parseManifestWithKeys(bytes.NewReader(buff.Bytes()), keys)
+	require.NoError(t, err)
+	assert.Equal(t, contents.root, parsed.root)
+	assert.Equal(t, contents.lock, parsed.lock)
+	assert.Equal(t, contents.specs, parsed.specs)
+
+	_, err = parseManifestWithKeys(bytes.NewReader(buff.Bytes()), nil)
+	assert.ErrorIs(t, err, ErrEncryptionKeysRequired)
+
+	_, err = parseManifestWithKeys(bytes.NewReader(buff.Bytes()), makeSingleKeyring(t))
+	assert.ErrorIs(t, err, ErrUnknownEncryptionKey)
+}
+
+// writeEncryptedTestStore writes |chnks| to a new local store in |dir| encrypted with |keys|.
+func writeEncryptedTestStore(t *testing.T, dir string, keys *Keyring, chnks [][]byte) hash.Hash {
+	ctx := context.Background()
+	store, err := NewLocalStoreWithKeyring(ctx, constants.FormatDefaultString, dir, 1<<20, keys)
+	require.NoError(t, err)
+	defer store.Close()
+
+	for _, c := range chnks {
+		require.NoError(t, store.Put(ctx, chunks.NewChunk(c)))
+	}
+	root, err := store.Root(ctx)
+	require.NoError(t, err)
+	ok, err := store.Commit(ctx, chunks.NewChunk(chnks[0]).Hash(), root)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	return chunks.NewChunk(chnks[0]).Hash()
+}
+
+func requireStoreChunks(t *testing.T, store *NomsBlockStore, chnks [][]byte) {
+	ctx := context.Background()
+	for _, c := range chnks {
+		out, err := store.Get(ctx, hash.Of(c))
+		require.NoError(t, err)
+		assert.Equal(t, c, out.Data())
+	}
+}
+
+func TestEncryptedLocalStore(t *testing.T) {
+	ctx := context.Background()
+	dir := makeTempDir(t)
+	defer file.RemoveAll(dir)
+
+	keys := makeSingleKeyring(t)
+	chnks := similarChunks(16)
+	root := writeEncryptedTestStore(t, dir, keys, chnks)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	for _, e := range entries {
+		if e.Name() == lockFileName {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		require.NoError(t, err)
+		assert.False(t, bytes.Contains(data, chnks[0][:32]), "%s holds plaintext chunk data", e.Name())
+		assert.False(t, bytes.Contains(data, []byte(root.String())), "%s holds the plaintext root", e.Name())
+	}
+
+	store, err := NewLocalStoreWithKeyring(ctx, constants.FormatDefaultString, dir, 1<<20, keys)
+	require.NoError(t, err)
+	requireStoreChunks(t, store, chnks)
+	require.NoError(t, store.Close())
+
+	_, err = NewLocalStore(ctx, constants.FormatDefaultString, dir, 1<<20)
+	assert.ErrorIs(t, err, ErrEncryptionKeysRequired)
+
+	_, err = NewLocalStoreWithKeyring(ctx, constants.FormatDefaultString, dir, 1<<20, makeSingleKeyring(t))
+	assert.ErrorIs(t, err, ErrUnknownEncryptionKey)
+
+	check, err := CheckLocalStore(ctx, dir, keys)
+	require.NoError(t, err)
+	assert.False(t, check.Damaged())
+	assert.Len(t, check.Chunks, len(chnks))
+
+	_, err = openLocalStore(ctx, constants.FormatDefaultString, dir, 1<<20, defaultMaxTables, true, keys)
+	assert.ErrorIs(t, err, ErrChunkJournalEncryption)
+}
+
+func TestEncryptedStoreConjoin(t *testing.T) {
+	ctx := context.Background()
+	dir := makeTempDir(t)
+	defer file.RemoveAll(dir)
+
+	keys := makeSingleKeyring(t)
+	store, err := openLocalStore(ctx, constants.FormatDefaultString, dir, 1<<20, 2, false, keys)
+	require.NoError(t, err)
+
+	var all [][]byte
+	for i := 0; i < 6; i++ {
+		chnks := makeChunkSet(8, 64)
+		for _, c := range chnks {
+			require.NoError(t, store.Put(ctx, c))
+			all = append(all, c.Data())
+		}
+		root, err := store.Root(ctx)
+		require.NoError(t, err)
+		ok, err := store.Commit(ctx, root, root)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+	requireStoreChunks(t, store, all)
+	require.NoError(t, store.Close())
+
+	check, err := CheckLocalStore(ctx, dir, keys)
+	require.NoError(t, err)
+	assert.False(t, check.Damaged())
+	assert.LessOrEqual(t, len(check.Tables), 2)
+	for _, tc := range check.Tables {
+		ok, err := keys.sealedWithActiveKey(tc.Path)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	}
+}
+
+func TestEncryptedStoreKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	dir := makeTempDir(t)
+	defer file.RemoveAll(dir)
+
+	oldKey, newKey := randomKey(t), randomKey(t)
+	chnks := similarChunks(16)
+	writeEncryptedTestStore(t, dir, makeTestKeyring(t, "old", map[string][]byte{"old": oldKey}), chnks)
+
+	rotated := makeTestKeyring(t, "new", map[string][]byte{"old": oldKey, "new": newKey})
+	store, err := NewLocalStoreWithKeyring(ctx, constants.FormatDefaultString, dir, 1<<20, rotated)
+	require.NoError(t, err)
+
+	root, err := store.Root(ctx)
+	require.NoError(t, err)
+	keepChan := make(chan []hash.Hash, len(chnks))
+	var msErr error
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		msErr = store.MarkAndSweepChunks(ctx, root, keepChan, nil)
+		wg.Done()
+	}()
+	for _, c := range chnks {
+		keepChan <- []hash.Hash{hash.Of(c)}
+	}
+	close(keepChan)
+	wg.Wait()
+	require.NoError(t, msErr)
+	requireStoreChunks(t, store, chnks)
+	require.NoError(t, store.Close())
+
+	newOnly := makeTestKeyring(t, "new", map[string][]byte{"new": newKey})
+	check, err := CheckLocalStore(ctx, dir, newOnly)
+	require.NoError(t, err)
+	assert.False(t, check.Damaged())
+	for _, tc := range check.Tables {
+		ok, err := newOnly.sealedWithActiveKey(tc.Path)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	}
+
+	store, err = NewLocalStoreWithKeyring(ctx, constants.FormatDefaultString, dir, 1<<20, newOnly)
+	require.NoError(t, err)
+	requireStoreChunks(t, store, chnks)
+	require.NoError(t, store.Close())
+}
+
+func TestEncryptedBSStore(t *testing.T) {
+	ctx := context.Background()
+	bs := blobstore.NewInMemoryBlobstore()
+	keys := makeSingleKeyring(t)
+	chnks := similarChunks(16)
+
+	store, err := NewBSStoreWithKeyring(ctx, constants.FormatDefaultString, bs, 1<<20, keys)
+	require.NoError(t, err)
+	for _, c := range chnks {
+		require.NoError(t, store.Put(ctx, chunks.NewChunk(c)))
+	}
+	root, err := store.Root(ctx)
+	require.NoError(t, err)
+	ok, err := store.Commit(ctx, hash.Of(chnks[0]), root)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NoError(t, store.Close())
+
+	store, err = NewBSStoreWithKeyring(ctx, constants.FormatDefaultString, bs, 1<<20, keys)
+	require.NoError(t, err)
+	requireStoreChunks(t, store, chnks)
+	require.NoError(t, store.Close())
+
+	_, err = NewBSStore(ctx, constants.FormatDefaultString, bs, 1<<20)
+	assert.ErrorIs(t, err, ErrEncryptionKeysRequired)
+}
@@ -0,0 +1,412 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nbs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+// TableFileCompression selects how the chunks of the table files written by a store are compressed.
+type TableFileCompression uint8
+
+const (
+	// SnappyCompression writes table files of the original format, in which every chunk is compressed with snappy.
+	SnappyCompression TableFileCompression = iota
+
+	// ZstdCompression writes versioned table files, in which every chunk is compressed with zstd.
+	ZstdCompression
+
+	// ZstdDictCompression writes versioned table files, in which every chunk is compressed with zstd using a
+	// dictionary trained on a sample of the chunks of the file. The dictionary is stored in the file.
+	ZstdDictCompression
+)
+
+var compressionNames = map[TableFileCompression]string{
+	SnappyCompression:   "snappy",
+	ZstdCompression:     "zstd",
+	ZstdDictCompression: "zstd-dict",
+}
+
+func (c TableFileCompression) String() string {
+	if name, ok := compressionNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown compression %d", c)
+}
+
+// ParseTableFileCompression returns the TableFileCompression named |s|, one of "snappy", "zstd" and "zstd-dict".
+func ParseTableFileCompression(s string) (TableFileCompression, error) {
+	for c, name := range compressionNames {
+		if strings.EqualFold(s, name) {
+			return c, nil
+		}
+	}
+	return SnappyCompression, fmt.Errorf("unknown table file compression '%s', expected one of snappy, zstd or zstd-dict", s)
+}
+
+// format returns the format of the table files written with |c|.
+func (c TableFileCompression) format() tableFormat {
+	if c == SnappyCompression {
+		return tableFormatV1
+	}
+	return tableFormatV2
+}
+
+// tableFormat is the format of a table file, which is identified by the magic number of its footer.
+type tableFormat uint8
+
+const (
+	// tableFormatV1 is the original table file format, in which chunk records hold snappy compressed data.
+	tableFormatV1 tableFormat = 1
+
+	// tableFormatV2 is the versioned table file format, in which chunk records start with the chunkCodec of their data.
+	tableFormatV2 tableFormat = 2
+)
+
+// magicNumberV2 ends the footer of tableFormatV2 table files. It shares its first 7 bytes with |magicNumber|, and ends
+// with the version of the format.
+const magicNumberV2 = "\xff\xb5\xd8\xc2\x24\x63\xee\x02"
+
+// parseTableFormat returns the format of a table file ending with the magic number |magic|.
+func parseTableFormat(magic []byte) (tableFormat, error) {
+	switch string(magic) {
+	case magicNumber:
+		return tableFormatV1, nil
+	case magicNumberV2:
+		return tableFormatV2, nil
+	case sealedTableMagic:
+		return 0, ErrEncryptionKeysRequired
+	default:
+		return 0, ErrInvalidTableFile
+	}
+}
+
+func (f tableFormat) magicNumber() string {
+	if f == tableFormatV2 {
+		return magicNumberV2
+	}
+	return magicNumber
+}
+
+// chunkCodec is the compression of the data of a chunk record. The records of tableFormatV2 files start with their
+// codec, the records of tableFormatV1 files are all |legacySnappyCodec|.
+type chunkCodec uint8
+
+const (
+	legacySnappyCodec chunkCodec = iota
+	snappyCodec
+	zstdCodec
+	zstdDictCodec
+
+	// dictionaryCodec tags the record holding the zstd dictionary of a table file, which is stored uncompressed.
+	dictionaryCodec
+)
+
+const codecSize = 1
+
+// tableDictAddr is the address of the record holding the zstd dictionary of a table file in its index. It is not the
+// address of a chunk, and is never returned as one.
+var tableDictAddr = addr(hash.Parse("dddddddddddddddddddddddddddddddd"))
+
+var errMissingTableDict = errors.New("table file chunk requires a zstd dictionary the table file does not have")
+
+var zstdCodecs = struct {
+	once sync.Once
+	enc  *zstd.Encoder
+	dec  *zstd.Decoder
+	err  error
+}{}
+
+// sharedZstd returns the zstd encoder and decoder of chunks compressed without a dictionary. Both are safe for
+// concurrent use.
+func sharedZstd() (*zstd.Encoder, *zstd.Decoder, error) {
+	zstdCodecs.once.Do(func() {
+		zstdCodecs.enc, zstdCodecs.err = zstd.NewWriter(nil, zstdEncoderOptions()...)
+		if zstdCodecs.err == nil {
+			zstdCodecs.dec, zstdCodecs.err = zstd.NewReader(nil)
+		}
+	})
+	return zstdCodecs.enc, zstdCodecs.dec, zstdCodecs.err
+}
+
+func zstdEncoderOptions() []zstd.EOption {
+	// chunk records are checksummed already, and empty chunks must still produce a frame
+	return []zstd.EOption{zstd.WithEncoderCRC(false), zstd.WithZeroFrames(true)}
+}
+
+// zstdDict is the zstd dictionary of a table file. Its encoder and decoder are created on first use.
+type zstdDict struct {
+	id      uint32
+	content []byte
+
+	once sync.Once
+	enc  *zstd.Encoder
+	dec  *zstd.Decoder
+	err  error
+}
+
+func newZstdDict(content []byte) *zstdDict {
+	// dictionary id 0 means no dictionary
+	id := crc(content)
+	if id == 0 {
+		id = 1
+	}
+	return &zstdDict{id: id, content: content}
+}
+
+func (d *zstdDict) codecs() (*zstd.Encoder, *zstd.Decoder, error) {
+	d.once.Do(func() {
+		opts := append(zstdEncoderOptions(), zstd.WithEncoderDictRaw(d.id, d.content))
+		d.enc, d.err = zstd.NewWriter(nil, opts...)
+		if d.err == nil {
+			d.dec, d.err = zstd.NewReader(nil, zstd.WithDecoderDictRaw(d.id, d.content))
+		}
+	})
+	return d.enc, d.dec, d.err
+}
+
+// compressChunkData appends |data| compressed with |codec| to |dst|. |dict| is required by |zstdDictCodec|.
+func compressChunkData(dst []byte, codec chunkCodec, dict *zstdDict, data []byte) ([]byte, error) {
+	switch codec {
+	case legacySnappyCodec, snappyCodec:
+		encoded := snappy.Encode(nil, data)
+		return append(dst, encoded...), nil
+	case zstdCodec:
+		enc, _, err := sharedZstd()
+		if err != nil {
+			return nil, err
+		}
+		return enc.EncodeAll(data, dst), nil
+	case zstdDictCodec:
+		if dict == nil {
+			return nil, errMissingTableDict
+		}
+		enc, _, err := dict.codecs()
+		if err != nil {
+			return nil, err
+		}
+		return enc.EncodeAll(data, dst), nil
+	default:
+		return nil, fmt.Errorf("%w: cannot compress chunks with codec %d", ErrInvalidTableFile, codec)
+	}
+}
+
+// decompressChunkData returns the chunk data |compressed| with |codec|.
+func decompressChunkData(codec chunkCodec, dict *zstdDict, compressed []byte) ([]byte, error) {
+	switch codec {
+	case legacySnappyCodec, snappyCodec:
+		return snappy.Decode(nil, compressed)
+	case zstdCodec:
+		_, dec, err := sharedZstd()
+		if err != nil {
+			return nil, err
+		}
+		return dec.DecodeAll(compressed, nil)
+	case zstdDictCodec:
+		if dict == nil {
+			return nil, errMissingTableDict
+		}
+		_, dec, err := dict.codecs()
+		if err != nil {
+			return nil, err
+		}
+		return dec.DecodeAll(compressed, nil)
+	default:
+		return nil, fmt.Errorf("%w: unknown chunk codec %d", ErrInvalidTableFile, codec)
+	}
+}
+
+// decompressedLen returns the length of the chunk data |compressed| with |codec|, without decompressing it when the
+// compressed data records it.
+func decompressedLen(codec chunkCodec, dict *zstdDict, compressed []byte) (uint64, error) {
+	switch codec {
+	case legacySnappyCodec, snappyCodec:
+		l, err := snappy.DecodedLen(compressed)
+		return uint64(l), err
+	case zstdCodec, zstdDictCodec:
+		var h zstd.Header
+		if err := h.Decode(compressed); err == nil && h.HasFCS {
+			return h.FrameContentSize, nil
+		}
+	}
+
+	data, err := decompressChunkData(codec, dict, compressed)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(len(data)), nil
+}
+
+// tableEncoder writes the chunk records of the table files written with a TableFileCompression.
+type tableEncoder struct {
+	format tableFormat
+	codec  chunkCodec
+	dict   *zstdDict
+}
+
+// newTableEncoder returns the tableEncoder of |c|. |dict| is the dictionary of the table file being written when |c|
+// is ZstdDictCompression, which may be nil if the file has too few chunks to train one.
+func newTableEncoder(c TableFileCompression, dict *zstdDict) tableEncoder {
+	switch c {
+	case ZstdCompression:
+		return tableEncoder{format: tableFormatV2, codec: zstdCodec}
+	case ZstdDictCompression:
+		if dict == nil {
+			return tableEncoder{format: tableFormatV2, codec: zstdCodec}
+		}
+		return tableEncoder{format: tableFormatV2, codec: zstdDictCodec, dict: dict}
+	default:
+		return tableEncoder{format: tableFormatV1, codec: legacySnappyCodec}
+	}
+}
+
+// appendRecord appends the chunk record of the chunk |data| to |dst|.
+func (e tableEncoder) appendRecord(dst, data []byte) ([]byte, error) {
+	start := len(dst)
+	if e.format == tableFormatV2 {
+		dst = append(dst, byte(e.codec))
+	}
+
+	dst, err := compressChunkData(dst, e.codec, e.dict, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return appendChecksum(dst, start), nil
+}
+
+// appendCompressedRecord appends the chunk record of |cc| to |dst|. The compressed data of |cc| is copied when it is
+// compressed the way this encoder compresses chunks, and is compressed again otherwise. It returns the uncompressed
+// length of the chunk along with the result.
+func (e tableEncoder) appendCompressedRecord(dst []byte, cc CompressedChunk) ([]byte, uint64, error) {
+	if cc.codec == e.codec && sameDict(cc.dict, e.dict) {
+		uncmpLen, err := decompressedLen(cc.codec, cc.dict, cc.CompressedData)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		start := len(dst)
+		if e.format == tableFormatV2 {
+			dst = append(dst, byte(e.codec))
+		}
+		return appendChecksum(append(dst, cc.CompressedData...), start), uncmpLen, nil
+	}
+
+	data, err := decompressChunkData(cc.codec, cc.dict, cc.CompressedData)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	dst, err = e.appendRecord(dst, data)
+	if err != nil {
+		return nil, 0, err
+	}
+	return dst, uint64(len(data)), nil
+}
+
+func sameDict(a, b *zstdDict) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a == b || (a.id == b.id && bytes.Equal(a.content, b.content))
+}
+
+// appendDictionaryRecord appends the record holding the dictionary of the encoder to |dst|.
+func (e tableEncoder) appendDictionaryRecord(dst []byte) []byte {
+	start := len(dst)
+	dst = append(dst, byte(dictionaryCodec))
+	dst = append(dst, e.dict.content...)
+	return appendChecksum(dst, start)
+}
+
+// appendChecksum appends the checksum of the record starting at |dst[start:]| to |dst|.
+func appendChecksum(dst []byte, start int) []byte {
+	var sum [checksumSize]byte
+	binary.BigEndian.PutUint32(sum[:], crc(dst[start:]))
+	return append(dst, sum[:]...)
+}
+
+// parseVersionedChunk parses the tableFormatV2 chunk record |buff| of the chunk |h|, which must be a chunk record of
+// a table file with the dictionary |dict|.
+func parseVersionedChunk(h hash.Hash, buff []byte, dict *zstdDict) (CompressedChunk, error) {
+	if len(buff) < codecSize+checksumSize {
+		return CompressedChunk{}, ErrInvalidTableFile
+	}
+
+	dataLen := len(buff) - checksumSize
+	if binary.BigEndian.Uint32(buff[dataLen:]) != crc(buff[:dataLen]) {
+		return CompressedChunk{}, errors.New("checksum error")
+	}
+
+	cc := CompressedChunk{
+		H:                   h,
+		FullCompressedChunk: buff,
+		CompressedData:      buff[codecSize:dataLen],
+		codec:               chunkCodec(buff[0]),
+	}
+
+	switch cc.codec {
+	case snappyCodec, zstdCodec:
+	case zstdDictCodec:
+		if dict == nil {
+			return CompressedChunk{}, errMissingTableDict
+		}
+		cc.dict = dict
+	default:
+		return CompressedChunk{}, fmt.Errorf("%w: unexpected chunk codec %d", ErrInvalidTableFile, cc.codec)
+	}
+
+	return cc, nil
+}
+
+// parseDictionaryRecord parses the record |buff| holding the dictionary of a table file.
+func parseDictionaryRecord(buff []byte) (*zstdDict, error) {
+	if len(buff) < codecSize+checksumSize {
+		return nil, ErrInvalidTableFile
+	}
+
+	dataLen := len(buff) - checksumSize
+	if binary.BigEndian.Uint32(buff[dataLen:]) != crc(buff[:dataLen]) {
+		return nil, errors.New("checksum error")
+	}
+
+	if chunkCodec(buff[0]) != dictionaryCodec {
+		return nil, fmt.Errorf("%w: dictionary record has codec %d", ErrInvalidTableFile, buff[0])
+	}
+
+	content := make([]byte, dataLen-codecSize)
+	copy(content, buff[codecSize:dataLen])
+	return newZstdDict(content), nil
+}
+
+// hasTableDict returns whether the table file of |index| has a zstd dictionary.
+func hasTableDict(index tableIndex) bool {
+	if index.Format() != tableFormatV2 {
+		return false
+	}
+	a := tableDictAddr
+	_, ok := index.Lookup(&a)
+	return ok
+}
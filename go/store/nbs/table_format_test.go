@@ -0,0 +1,316 @@
+// Copyright 2022 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nbs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/dolthub/dolt/go/libraries/utils/file"
+	"github.com/dolthub/dolt/go/store/chunks"
+	"github.com/dolthub/dolt/go/store/constants"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+// similarChunks returns |n| distinct chunks which share most of their content, as the chunks of a table do.
+func similarChunks(n int) [][]byte {
+	chnks := make([][]byte, n)
+	for i := range chnks {
+		chnks[i] = []byte(fmt.Sprintf(`{"id": %d, "name": "customer %d", "email": "customer.%d@example.com", `+
+			`"address": "%d Main Street, Springfield", "status": "active", "plan": "enterprise", "seats": %d}`,
+			i, i, i, i*7, i%13))
+	}
+	return chnks
+}
+
+func writeCompressedTable(t *testing.T, compression TableFileCompression, chnks [][]byte) tableReader {
+	mt := newMemTable(1 << 20)
+	for _, c := range chnks {
+		require.True(t, mt.addChunk(computeAddr(c), c))
+	}
+
+	_, data, count, err := mt.writeCompressed(nil, compression, &Stats{})
+	require.NoError(t, err)
+
+	ti, err := parseTableIndex(data)
+	require.NoError(t, err)
+	require.Equal(t, compression.format(), ti.Format())
+	require.Equal(t, ti.ChunkCount(), count)
+	return newTableReader(ti, tableReaderAtFromBytes(data), fileBlockSize)
+}
+
+func TestParseTableFileCompression(t *testing.T) {
+	for _, c := range []TableFileCompression{SnappyCompression, ZstdCompression, ZstdDictCompression} {
+		parsed, err := ParseTableFileCompression(c.String())
+		require.NoError(t, err)
+		assert.Equal(t, c, parsed)
+	}
+
+	_, err := ParseTableFileCompression("gzip")
+	assert.Error(t, err)
+}
+
+func TestCompressedTableRoundTrip(t *testing.T) {
+	chnks := similarChunks(256)
+
+	for _, c := range []TableFileCompression{SnappyCompression, ZstdCompression, ZstdDictCompression} {
+		t.Run(c.String(), func(t *testing.T) {
+			tr := writeCompressedTable(t, c, chnks)
+			assert.Equal(t, c == ZstdDictCompression, hasTableDict(tr.tableIndex))
+			if c == ZstdDictCompression {
+				assert.Equal(t, uint32(len(chnks)+1), mustUint32(tr.count()))
+			} else {
+				assert.Equal(t, uint32(len(chnks)), mustUint32(tr.count()))
+			}
+			assertChunksInReader(chnks, tr, assert.New(t))
+
+			hashes := make(hash.HashSet)
+			for _, c := range chnks {
+				hashes.Insert(hash.Hash(computeAddr(c)))
+			}
+			found, err := readAllChunks(context.Background(), hashes, tr)
+			require.NoError(t, err)
+			assert.Len(t, found, len(chnks))
+
+			recs := make(chan extractRecord, len(chnks)+1)
+			require.NoError(t, tr.extract(context.Background(), recs))
+			close(recs)
+			var extracted int
+			for rec := range recs {
+				require.NoError(t, rec.err)
+				extracted++
+			}
+			assert.Equal(t, len(chnks), extracted)
+		})
+	}
+}
+
+func TestZstdDictCompressesSimilarChunks(t *testing.T) {
+	chnks := similarChunks(512)
+
+	sizeOf := func(c TableFileCompression) uint64 {
+		mt := newMemTable(1 << 20)
+		for _, c := range chnks {
+			require.True(t, mt.addChunk(computeAddr(c), c))
+		}
+		_, data, _, err := mt.writeCompressed(nil, c, &Stats{})
+		require.NoError(t, err)
+		return uint64(len(data))
+	}
+
+	assert.Less(t, sizeOf(ZstdDictCompression), sizeOf(ZstdCompression))
+	assert.Less(t, sizeOf(ZstdDictCompression), sizeOf(SnappyCompression))
+}
+
+func TestTrainTableDict(t *testing.T) {
+	assert.Nil(t, trainTableDict(similarChunks(minTableDictChunks-1)))
+
+	dict := trainTableDict(similarChunks(256))
+	require.NotNil(t, dict)
+	assert.True(t, len(dict) >= minTableDictSize)
+	assert.True(t, len(dict) <= maxTableDictSize)
+	assert.True(t, bytes.Contains(dict, []byte(`"status": "active"`)))
+}
+
+func TestCmpChunkTableWriterRecompresses(t *testing.T) {
+	ctx := context.Background()
+	chnks := similarChunks(maxTableDictSamples + 100)
+
+	hashes := make(hash.HashSet)
+	for _, c := range chnks {
+		hashes.Insert(hash.Hash(computeAddr(c)))
+	}
+
+	for _, src := range []TableFileCompression{SnappyCompression, ZstdCompression, ZstdDictCompression} {
+		for _, dest := range []TableFileCompression{SnappyCompression, ZstdCompression, ZstdDictCompression} {
+			t.Run(fmt.Sprintf("%s to %s", src, dest), func(t *testing.T) {
+				tr := writeCompressedTable(t, src, chnks)
+
+				found := make([]CompressedChunk, 0, len(chnks))
+				eg, egCtx := errgroup.WithContext(ctx)
+				_, err := tr.getManyCompressed(egCtx, eg, toGetRecords(hashes), func(ctx context.Context, c CompressedChunk) {
+					found = append(found, c)
+				}, &Stats{})
+				require.NoError(t, err)
+				require.NoError(t, eg.Wait())
+
+				tw, err := NewCmpChunkTableWriterWithCompression("", dest)
+				require.NoError(t, err)
+				for _, cc := range found {
+					require.NoError(t, tw.AddCmpChunk(cc))
+					assert.Equal(t, ErrChunkAlreadyWritten, tw.AddCmpChunk(cc))
+				}
+				_, err = tw.Finish()
+				require.NoError(t, err)
+
+				output := bytes.NewBuffer(nil)
+				require.NoError(t, tw.Flush(output))
+
+				outputTI, err := parseTableIndex(output.Bytes())
+				require.NoError(t, err)
+				assert.Equal(t, dest.format(), outputTI.Format())
+				assert.Equal(t, dest == ZstdDictCompression, hasTableDict(outputTI))
+				outputTR := newTableReader(outputTI, tableReaderAtFromBytes(output.Bytes()), fileBlockSize)
+
+				compareContentsOfTables(t, ctx, hashes, tr, outputTR)
+			})
+		}
+	}
+}
+
+func TestFSTablePersisterConjoinRewritesCompression(t *testing.T) {
+	dir := makeTempDir(t)
+	defer file.RemoveAll(dir)
+	fc := newFDCache(defaultMaxTables)
+	defer fc.Drop()
+	fts := newFSTablePersister(dir, fc, nil)
+
+	chnks := similarChunks(300)
+	var sources chunkSources
+
+	// table files written before and after the compression of the store changes
+	persist := func(chnks [][]byte) (chunkSource, error) {
+		mt := newMemTable(1 << 20)
+		for _, c := range chnks {
+			require.True(t, mt.addChunk(computeAddr(c), c))
+		}
+		return fts.Persist(context.Background(), mt, nil, &Stats{})
+	}
+
+	src, err := persist(chnks[:100])
+	require.NoError(t, err)
+	sources = append(sources, src)
+
+	fts.(*fsTablePersister).compression = ZstdCompression
+	src, err = persist(chnks[100:200])
+	require.NoError(t, err)
+	sources = append(sources, src)
+
+	fts.(*fsTablePersister).compression = ZstdDictCompression
+	src, err = persist(chnks[200:])
+	require.NoError(t, err)
+	sources = append(sources, src)
+
+	conjoined, err := fts.ConjoinAll(context.Background(), sources, &Stats{})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(len(chnks)+1), mustUint32(conjoined.count()))
+
+	buff, err := os.ReadFile(filepath.Join(dir, mustAddr(conjoined.hash()).String()))
+	require.NoError(t, err)
+	ti, err := parseTableIndex(buff)
+	require.NoError(t, err)
+	assert.Equal(t, tableFormatV2, ti.Format())
+	assert.True(t, hasTableDict(ti))
+	tr := newTableReader(ti, tableReaderAtFromBytes(buff), fileBlockSize)
+	assertChunksInReader(chnks, tr, assert.New(t))
+}
+
+func TestIterChunksCompressedTable(t *testing.T) {
+	chnks := similarChunks(128)
+
+	for _, c := range []TableFileCompression{SnappyCompression, ZstdCompression, ZstdDictCompression} {
+		t.Run(c.String(), func(t *testing.T) {
+			mt := newMemTable(1 << 20)
+			for _, c := range chnks {
+				require.True(t, mt.addChunk(computeAddr(c), c))
+			}
+			_, data, _, err := mt.writeCompressed(nil, c, &Stats{})
+			require.NoError(t, err)
+
+			var found [][]byte
+			err = IterChunks(bytes.NewReader(data), func(chunk chunks.Chunk) (bool, error) {
+				found = append(found, chunk.Data())
+				return false, nil
+			})
+			require.NoError(t, err)
+			assert.ElementsMatch(t, chnks, found)
+		})
+	}
+}
+
+func TestConvertToLegacyTableFiles(t *testing.T) {
+	ctx := context.Background()
+	dir := makeTempDir(t)
+	defer file.RemoveAll(dir)
+
+	store, err := NewLocalStore(ctx, constants.FormatDefaultString, dir, 1<<20)
+	require.NoError(t, err)
+	defer store.Close()
+	require.NoError(t, store.SetTableFileCompression(ZstdDictCompression))
+
+	chnks := similarChunks(128)
+	for _, c := range chnks {
+		require.NoError(t, store.Put(ctx, chunks.NewChunk(c)))
+	}
+	root := chunks.NewChunk(chnks[0]).Hash()
+	ok, err := store.Commit(ctx, root, hash.Hash{})
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	tableFormats := func() map[string]tableFormat {
+		_, tfs, _, err := store.Sources(ctx)
+		require.NoError(t, err)
+		formats := make(map[string]tableFormat)
+		for _, tf := range tfs {
+			buff, err := os.ReadFile(filepath.Join(dir, tf.FileID()))
+			require.NoError(t, err)
+			ti, err := parseTableIndex(buff)
+			require.NoError(t, err)
+			formats[tf.FileID()] = ti.Format()
+		}
+		return formats
+	}
+
+	before := tableFormats()
+	require.Len(t, before, 1)
+	for _, f := range before {
+		require.Equal(t, tableFormatV2, f)
+	}
+
+	require.NoError(t, store.ConvertToLegacyTableFiles(ctx))
+
+	after := tableFormats()
+	require.Len(t, after, 1)
+	for id, f := range after {
+		assert.Equal(t, tableFormatV1, f)
+		assert.NotContains(t, before, id)
+	}
+	for id := range before {
+		_, err := os.Stat(filepath.Join(dir, id))
+		assert.True(t, os.IsNotExist(err))
+	}
+
+	for _, c := range chnks {
+		ch, err := store.Get(ctx, chunks.NewChunk(c).Hash())
+		require.NoError(t, err)
+		assert.Equal(t, c, ch.Data())
+	}
+
+	h, err := store.Root(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, root, h)
+
+	// converting a store without versioned table files changes nothing
+	require.NoError(t, store.ConvertToLegacyTableFiles(ctx))
+	assert.Equal(t, after, tableFormats())
+}
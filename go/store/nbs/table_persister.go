@@ -200,7 +200,12 @@ type sourceWithSize struct {
 	dataLen uint64
 }
 
+// errConjoinRequiresRewrite is returned when planning to conjoin table files of different formats, or with
+// dictionaries, whose chunks must be compressed again to be conjoined.
+var errConjoinRequiresRewrite = errors.New("table files of different formats or with dictionaries cannot be conjoined by concatenation")
+
 type compactionPlan struct {
+	format              tableFormat
 	sources             chunkSourcesByDescendingDataSize
 	mergedIndex         []byte
 	chunkCount          uint32
@@ -229,6 +234,14 @@ func planConjoin(sources chunkSources, stats *Stats) (plan compactionPlan, err e
 			return compactionPlan{}, err
 		}
 
+		if plan.format == 0 {
+			plan.format = index.Format()
+		}
+
+		if index.Format() != plan.format || hasTableDict(index) {
+			return compactionPlan{}, errConjoinRequiresRewrite
+		}
+
 		plan.chunkCount += index.ChunkCount()
 
 		// Calculate the amount of chunk data in |src|
@@ -315,7 +328,7 @@ func planConjoin(sources chunkSources, stats *Stats) (plan compactionPlan, err e
 		pfxPos += ordinalSize
 	}
 
-	writeFooter(plan.mergedIndex[uint64(len(plan.mergedIndex))-footerSize:], plan.chunkCount, totalUncompressedData)
+	writeFooter(plan.mergedIndex[uint64(len(plan.mergedIndex))-footerSize:], plan.format, plan.chunkCount, totalUncompressedData)
 
 	stats.BytesPerConjoin.Sample(uint64(plan.totalCompressedData) + uint64(len(plan.mergedIndex)))
 	return plan, nil
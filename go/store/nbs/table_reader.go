@@ -29,6 +29,7 @@ import (
 	"io"
 	"os"
 	"sort"
+	"sync"
 	"sync/atomic"
 
 	"github.com/dolthub/mmap-go"
@@ -43,16 +44,20 @@ import (
 // Do not read more than 128MB at a time.
 const maxReadSize = 128 * 1024 * 1024
 
-// CompressedChunk represents a chunk of data in a table file which is still compressed via snappy.
+// CompressedChunk represents a chunk of data in a table file which is still compressed. Chunks of the original table
+// file format are compressed via snappy, chunks of versioned table files are compressed with the codec of their record.
 type CompressedChunk struct {
 	// H is the hash of the chunk
 	H hash.Hash
 
-	// FullCompressedChunk is the entirety of the compressed chunk data including the crc
+	// FullCompressedChunk is the entirety of the chunk record including the crc
 	FullCompressedChunk []byte
 
-	// CompressedData is just the snappy encoded byte buffer that stores the chunk data
+	// CompressedData is just the compressed byte buffer that stores the chunk data
 	CompressedData []byte
+
+	codec chunkCodec
+	dict  *zstdDict
 }
 
 // NewCompressedChunk creates a CompressedChunk
@@ -69,9 +74,9 @@ func NewCompressedChunk(h hash.Hash, buff []byte) (CompressedChunk, error) {
 	return CompressedChunk{H: h, FullCompressedChunk: buff, CompressedData: compressedData}, nil
 }
 
-// ToChunk snappy decodes the compressed data and returns a chunks.Chunk
+// ToChunk decodes the compressed data and returns a chunks.Chunk
 func (cmp CompressedChunk) ToChunk() (chunks.Chunk, error) {
-	data, err := snappy.Decode(nil, cmp.CompressedData)
+	data, err := decompressChunkData(cmp.codec, cmp.dict, cmp.CompressedData)
 
 	if err != nil {
 		return chunks.Chunk{}, err
@@ -95,7 +100,11 @@ func (cmp CompressedChunk) Hash() hash.Hash {
 
 // IsEmpty returns true if the chunk contains no data.
 func (cmp CompressedChunk) IsEmpty() bool {
-	return len(cmp.CompressedData) == 0 || (len(cmp.CompressedData) == 1 && cmp.CompressedData[0] == 0)
+	if cmp.codec == legacySnappyCodec || cmp.codec == snappyCodec {
+		return len(cmp.CompressedData) == 0 || (len(cmp.CompressedData) == 1 && cmp.CompressedData[0] == 0)
+	}
+	l, err := decompressedLen(cmp.codec, cmp.dict, cmp.CompressedData)
+	return err == nil && l == 0
 }
 
 var EmptyCompressedChunk CompressedChunk
@@ -108,6 +117,7 @@ func init() {
 var ErrInvalidTableFile = errors.New("invalid or corrupt table file")
 
 type onHeapTableIndex struct {
+	format                tableFormat
 	chunkCount            uint32
 	totalUncompressedData uint64
 	prefixes, offsets     []uint64
@@ -159,6 +169,7 @@ func (i mmapTableIndex) Ordinals() []uint32 {
 }
 
 type mmapTableIndex struct {
+	format                tableFormat
 	chunkCount            uint32
 	totalUncompressedData uint64
 	fileSz                uint64
@@ -184,6 +195,10 @@ func (i mmapTableIndex) ChunkCount() uint32 {
 	return i.chunkCount
 }
 
+func (i mmapTableIndex) Format() tableFormat {
+	return i.format
+}
+
 func (i mmapTableIndex) TotalUncompressedData() uint64 {
 	return i.totalUncompressedData
 }
@@ -299,6 +314,7 @@ func newMmapTableIndex(ti onHeapTableIndex, f *os.File) (mmapTableIndex, error)
 	refCnt := new(int32)
 	*refCnt = 1
 	return mmapTableIndex{
+		ti.format,
 		ti.chunkCount,
 		ti.totalUncompressedData,
 		ti.TableFileSize(),
@@ -324,6 +340,7 @@ type tableReader struct {
 	totalUncompressedData uint64
 	r                     tableReaderAt
 	blockSize             uint64
+	dict                  *tableDictLoader
 }
 
 type tableIndex interface {
@@ -353,6 +370,8 @@ type tableIndex interface {
 	// TotalUncompressedData returns the total uncompressed data size of
 	// the table file. Used for informational statistics only.
 	TotalUncompressedData() uint64
+	// Format returns the format of the indexed table file.
+	Format() tableFormat
 
 	// Close releases any resources used by this tableIndex.
 	Close() error
@@ -385,8 +404,10 @@ func ReadTableIndex(rd io.ReadSeeker) (onHeapTableIndex, error) {
 		return onHeapTableIndex{}, err
 	}
 
-	if string(footer[uint32Size+uint64Size:]) != magicNumber {
-		return onHeapTableIndex{}, ErrInvalidTableFile
+	format, err := parseTableFormat(footer[uint32Size+uint64Size:])
+
+	if err != nil {
+		return onHeapTableIndex{}, err
 	}
 
 	chunkCount := binary.BigEndian.Uint32(footer)
@@ -413,6 +434,7 @@ func ReadTableIndex(rd io.ReadSeeker) (onHeapTableIndex, error) {
 	suffixes := indexBytes[tuplesSize+lengthsSize:]
 
 	return onHeapTableIndex{
+		format,
 		chunkCount, totalUncompressedData,
 		prefixes, offsets,
 		lengths, ordinals,
@@ -534,6 +556,10 @@ func (i onHeapTableIndex) TotalUncompressedData() uint64 {
 	return i.totalUncompressedData
 }
 
+func (i onHeapTableIndex) Format() tableFormat {
+	return i.format
+}
+
 func (i onHeapTableIndex) Close() error {
 	return nil
 }
@@ -553,6 +579,7 @@ func newTableReader(index tableIndex, r tableReaderAt, blockSize uint64) tableRe
 		index.TotalUncompressedData(),
 		r,
 		blockSize,
+		&tableDictLoader{},
 	}
 }
 
@@ -598,6 +625,8 @@ func (tr tableReader) hasMany(addrs []hasRecord) (bool, error) {
 	return remaining, nil
 }
 
+// count returns the number of records in the index of the table file, which includes the dictionary record of a table
+// file with a dictionary. Table specs record this count, as it is needed to locate the index.
 func (tr tableReader) count() (uint32, error) {
 	return tr.chunkCount, nil
 }
@@ -638,7 +667,7 @@ func (tr tableReader) get(ctx context.Context, h addr, stats *Stats) ([]byte, er
 		return nil, errors.New("failed to read all data")
 	}
 
-	cmp, err := NewCompressedChunk(hash.Hash(h), buff)
+	cmp, err := tr.parseChunk(ctx, h, buff, stats)
 
 	if err != nil {
 		return nil, err
@@ -721,7 +750,9 @@ func (tr tableReader) readAtOffsetsWithCB(
 	}
 
 	for i := range rb {
-		cmp, err := rb.ExtractChunkFromRead(buff, i)
+		rec := rb[i]
+		chunkStart := rec.offset - rb.Start()
+		cmp, err := tr.parseChunk(ctx, *rec.a, buff[chunkStart:chunkStart+uint64(rec.length)], stats)
 		if err != nil {
 			return err
 		}
@@ -978,7 +1009,7 @@ func (tr tableReader) extract(ctx context.Context, chunks chan<- extractRecord)
 		if uint32(n) != or.length {
 			return errors.New("did not read all data")
 		}
-		cmp, err := NewCompressedChunk(hash.Hash(*or.a), buff)
+		cmp, err := tr.parseChunk(ctx, *or.a, buff, &Stats{})
 
 		if err != nil {
 			return err
@@ -998,6 +1029,9 @@ func (tr tableReader) extract(ctx context.Context, chunks chan<- extractRecord)
 	for i := uint32(0); i < tr.chunkCount; i++ {
 		a := new(addr)
 		e := tr.IndexEntry(i, a)
+		if tr.Format() == tableFormatV2 && *a == tableDictAddr {
+			continue
+		}
 		ors = append(ors, offsetRec{a, e.Offset(), e.Length()})
 	}
 	sort.Sort(ors)
@@ -1021,7 +1055,51 @@ func (tr tableReader) Close() error {
 }
 
 func (tr tableReader) Clone() tableReader {
-	return tableReader{tr.tableIndex.Clone(), tr.prefixes, tr.chunkCount, tr.totalUncompressedData, tr.r, tr.blockSize}
+	return tableReader{tr.tableIndex.Clone(), tr.prefixes, tr.chunkCount, tr.totalUncompressedData, tr.r, tr.blockSize, tr.dict}
+}
+
+// parseChunk parses the chunk record |buff| of the chunk |h|, which was read from this table.
+func (tr tableReader) parseChunk(ctx context.Context, h addr, buff []byte, stats *Stats) (CompressedChunk, error) {
+	if tr.Format() != tableFormatV2 {
+		return NewCompressedChunk(hash.Hash(h), buff)
+	}
+
+	dict, err := tr.dict.load(ctx, tr, stats)
+	if err != nil {
+		return CompressedChunk{}, err
+	}
+	return parseVersionedChunk(hash.Hash(h), buff, dict)
+}
+
+// tableDictLoader loads the zstd dictionary of a table file the first time one of its chunks is read, and is shared by
+// the clones of its tableReader.
+type tableDictLoader struct {
+	once sync.Once
+	dict *zstdDict
+	err  error
+}
+
+func (l *tableDictLoader) load(ctx context.Context, tr tableReader, stats *Stats) (*zstdDict, error) {
+	l.once.Do(func() {
+		a := tableDictAddr
+		e, ok := tr.Lookup(&a)
+		if !ok {
+			return
+		}
+
+		buff := make([]byte, e.Length())
+		n, err := tr.r.ReadAtWithStats(ctx, buff, int64(e.Offset()), stats)
+		if err != nil {
+			l.err = err
+			return
+		} else if n != len(buff) {
+			l.err = errors.New("failed to read all data")
+			return
+		}
+
+		l.dict, l.err = parseDictionaryRecord(buff)
+	})
+	return l.dict, l.err
 }
 
 type readerAdapter struct {
@@ -393,6 +393,8 @@ func (ts tableSet) Flatten() (tableSet, error) {
 		rl:       ts.rl,
 	}
 
+	// the chunk journal is the chunkSource of every memTable persisted to it, so it may be in the set several times
+	seen := make(map[addr]struct{}, ts.Size())
 	for _, src := range ts.novel {
 		cnt, err := src.count()
 
@@ -401,11 +403,32 @@ func (ts tableSet) Flatten() (tableSet, error) {
 		}
 
 		if cnt > 0 {
+			h, err := src.hash()
+
+			if err != nil {
+				return tableSet{}, err
+			}
+
+			if _, ok := seen[h]; !ok {
+				seen[h] = struct{}{}
+				flattened.upstream = append(flattened.upstream, src)
+			}
+		}
+	}
+
+	for _, src := range ts.upstream {
+		h, err := src.hash()
+
+		if err != nil {
+			return tableSet{}, err
+		}
+
+		if _, ok := seen[h]; !ok {
+			seen[h] = struct{}{}
 			flattened.upstream = append(flattened.upstream, src)
 		}
 	}
 
-	flattened.upstream = append(flattened.upstream, ts.upstream...)
 	return flattened, nil
 }
 
@@ -489,6 +512,7 @@ func (ts tableSet) Rebase(ctx context.Context, specs []tableSpec, stats *Stats)
 
 func (ts tableSet) ToSpecs() ([]tableSpec, error) {
 	tableSpecs := make([]tableSpec, 0, ts.Size())
+	seen := make(map[addr]struct{}, ts.Size())
 	for _, src := range ts.novel {
 		cnt, err := src.count()
 
@@ -503,7 +527,10 @@ func (ts tableSet) ToSpecs() ([]tableSpec, error) {
 				return nil, err
 			}
 
-			tableSpecs = append(tableSpecs, tableSpec{h, cnt})
+			if _, ok := seen[h]; !ok {
+				seen[h] = struct{}{}
+				tableSpecs = append(tableSpecs, tableSpec{h, cnt})
+			}
 		}
 	}
 	for _, src := range ts.upstream {
@@ -523,7 +550,10 @@ func (ts tableSet) ToSpecs() ([]tableSpec, error) {
 			return nil, err
 		}
 
-		tableSpecs = append(tableSpecs, tableSpec{h, cnt})
+		if _, ok := seen[h]; !ok {
+			seen[h] = struct{}{}
+			tableSpecs = append(tableSpecs, tableSpec{h, cnt})
+		}
 	}
 	return tableSpecs, nil
 }
@@ -44,6 +44,7 @@ type tableWriter struct {
 	blockHash             hash.Hash
 
 	snapper snappyEncoder
+	enc     tableEncoder
 }
 
 type snappyEncoder interface {
@@ -85,14 +86,47 @@ func newTableWriter(buff []byte, snapper snappyEncoder) *tableWriter {
 		buff:      buff,
 		blockHash: sha512.New(),
 		snapper:   snapper,
+		enc:       newTableEncoder(SnappyCompression, nil),
 	}
 }
 
+// newVersionedTableWriter returns a tableWriter of tableFormatV2 table files, which compresses chunks with |enc|. When
+// |enc| has a dictionary, it is written as the first record of the table. len(buff) must be >= the maxTableSize of
+// the chunks plus versionedTableOverhead.
+func newVersionedTableWriter(buff []byte, enc tableEncoder) *tableWriter {
+	tw := &tableWriter{
+		buff:      buff,
+		blockHash: sha512.New(),
+		enc:       enc,
+	}
+
+	if enc.dict != nil {
+		rec := enc.appendDictionaryRecord(tw.buff[:0])
+		tw.addRecord(tableDictAddr, uint64(len(rec)))
+	}
+
+	return tw
+}
+
+// versionedTableOverhead returns the space needed by a tableFormatV2 table file of |numChunks| chunks and a
+// dictionary of |dictSize| bytes beyond their maxTableSize.
+func versionedTableOverhead(numChunks uint64, dictSize int) uint64 {
+	overhead := numChunks * codecSize
+	if dictSize > 0 {
+		overhead += uint64(dictSize) + codecSize + checksumSize + prefixTupleSize + lengthSize + addrSuffixSize
+	}
+	return overhead
+}
+
 func (tw *tableWriter) addChunk(h addr, data []byte) bool {
 	if len(data) == 0 {
 		panic("NBS blocks cannont be zero length")
 	}
 
+	if tw.enc.format == tableFormatV2 {
+		return tw.addVersionedChunk(h, data)
+	}
+
 	// Compress data straight into tw.buff
 	compressed := tw.snapper.Encode(tw.buff[tw.pos:], data)
 	dataLength := uint64(len(compressed))
@@ -124,6 +158,35 @@ func (tw *tableWriter) addChunk(h addr, data []byte) bool {
 	return true
 }
 
+func (tw *tableWriter) addVersionedChunk(h addr, data []byte) bool {
+	rec, err := tw.enc.appendRecord(nil, data)
+	if err != nil {
+		panic(fmt.Errorf("failed to compress chunk %s: %w", h.String(), err))
+	}
+
+	if copy(tw.buff[tw.pos:], rec) != len(rec) {
+		panic(fmt.Errorf("unbuffered chunk %s: uncompressed %d, record %d, tw.buff %d", h.String(), len(data), len(rec), len(tw.buff[tw.pos:])))
+	}
+
+	tw.totalCompressedData += uint64(len(rec) - codecSize - checksumSize)
+	tw.totalUncompressedData += uint64(len(data))
+	tw.addRecord(h, uint64(len(rec)))
+	return true
+}
+
+// addRecord indexes the record of |size| bytes just written at |tw.pos|.
+func (tw *tableWriter) addRecord(h addr, size uint64) {
+	tw.pos += size
+
+	// Stored in insertion order
+	tw.prefixes = append(tw.prefixes, prefixIndexRec{
+		h.Prefix(),
+		h[addrPrefixSize:],
+		uint32(len(tw.prefixes)),
+		uint32(size),
+	})
+}
+
 func (tw *tableWriter) finish() (uncompressedLength uint64, blockAddr addr, err error) {
 	err = tw.writeIndex()
 
@@ -195,10 +258,10 @@ func (tw *tableWriter) writeIndex() error {
 }
 
 func (tw *tableWriter) writeFooter() {
-	tw.pos += writeFooter(tw.buff[tw.pos:], uint32(len(tw.prefixes)), tw.totalUncompressedData)
+	tw.pos += writeFooter(tw.buff[tw.pos:], tw.enc.format, uint32(len(tw.prefixes)), tw.totalUncompressedData)
 }
 
-func writeFooter(dst []byte, chunkCount uint32, uncData uint64) (consumed uint64) {
+func writeFooter(dst []byte, format tableFormat, chunkCount uint32, uncData uint64) (consumed uint64) {
 	// chunk count
 	binary.BigEndian.PutUint32(dst[consumed:], chunkCount)
 	consumed += uint32Size
@@ -208,7 +271,7 @@ func writeFooter(dst []byte, chunkCount uint32, uncData uint64) (consumed uint64
 	consumed += uint64Size
 
 	// magic number
-	copy(dst[consumed:], magicNumber)
+	copy(dst[consumed:], format.magicNumber())
 	consumed += magicNumberSize
 	return
 }
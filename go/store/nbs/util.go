@@ -15,12 +15,12 @@
 package nbs
 
 import (
+	"context"
 	"io"
 
 	"github.com/dolthub/dolt/go/libraries/utils/iohelp"
 
 	"github.com/dolthub/dolt/go/store/chunks"
-	"github.com/dolthub/dolt/go/store/hash"
 )
 
 func IterChunks(rd io.ReadSeeker, cb func(chunk chunks.Chunk) (stop bool, err error)) error {
@@ -31,10 +31,16 @@ func IterChunks(rd io.ReadSeeker, cb func(chunk chunks.Chunk) (stop bool, err er
 
 	defer idx.Close()
 
+	// chunk records are parsed by a tableReader, which loads the dictionary of a table file which has one
+	tr := newTableReader(idx, readSeekerReaderAt{rd}, fileBlockSize)
+
 	seen := make(map[addr]bool)
 	for i := uint32(0); i < idx.ChunkCount(); i++ {
 		var a addr
 		ie := idx.IndexEntry(i, &a)
+		if idx.Format() == tableFormatV2 && a == tableDictAddr {
+			continue
+		}
 		if _, ok := seen[a]; !ok {
 			seen[a] = true
 			chunkBytes, err := readNFrom(rd, ie.Offset(), ie.Length())
@@ -42,7 +48,7 @@ func IterChunks(rd io.ReadSeeker, cb func(chunk chunks.Chunk) (stop bool, err er
 				return err
 			}
 
-			cmpChnk, err := NewCompressedChunk(hash.Hash(a), chunkBytes)
+			cmpChnk, err := tr.parseChunk(context.Background(), a, chunkBytes, &Stats{})
 			if err != nil {
 				return err
 			}
@@ -73,3 +79,16 @@ func readNFrom(rd io.ReadSeeker, offset uint64, length uint32) ([]byte, error) {
 
 	return iohelp.ReadNBytes(rd, int(length))
 }
+
+// readSeekerReaderAt reads a table file through an io.ReadSeeker, which must not be read concurrently.
+type readSeekerReaderAt struct {
+	rd io.ReadSeeker
+}
+
+func (r readSeekerReaderAt) ReadAtWithStats(ctx context.Context, p []byte, off int64, stats *Stats) (int, error) {
+	buff, err := readNFrom(r.rd, uint64(off), uint32(len(p)))
+	if err != nil {
+		return 0, err
+	}
+	return copy(p, buff), nil
+}
@@ -121,7 +121,7 @@ VALIDRANGES:
 				}
 			} else {
 				isInRange, err := inRange(lastKey.v)
-				if !isInRange {
+				if err != nil {
 					return err
 				} else if !isInRange {
 					break VALIDRANGES
@@ -26,6 +26,7 @@ import (
 	"errors"
 	"runtime"
 	"sync"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 
@@ -80,10 +81,28 @@ type ValueStore struct {
 	withBufferedChildren map[hash.Hash]uint64 // chunk Hash -> ref height
 	unresolvedRefs       hash.HashSet
 	enforceCompleteness  bool
+	absentRefsFilter     HashFilterFunc
 	decodedChunks        *sizecache.SizeCache
 	nbf                  *NomsBinFormat
 
 	versOnce sync.Once
+
+	gcMu          sync.Mutex   // protects the following state, which is only set while a collection is in progress
+	gcKeepers     hash.HashSet // chunks written or referenced by concurrent writers, which the collection must keep
+	gcSafepoint   bool
+	gcSafepointAt time.Time
+}
+
+// GCStats describes the work done by a garbage collection.
+type GCStats struct {
+	// ChunksKept is the number of chunks the collection found reachable, and kept.
+	ChunksKept uint64
+	// ConcurrentChunks is the number of chunks which were written, or referenced, by writers while the collection
+	// ran, and which it kept along with the chunks they reference.
+	ConcurrentChunks uint64
+	// SafepointPause is how long writers were paused at the safepoint of the collection, while it swapped in the
+	// table files it wrote.
+	SafepointPause time.Duration
 }
 
 func PanicIfDangling(ctx context.Context, unresolved hash.HashSet, cs chunks.ChunkStore) {
@@ -153,6 +172,30 @@ func (lvs *ValueStore) SetEnforceCompleteness(enforce bool) {
 	lvs.enforceCompleteness = enforce
 }
 
+// SetAbsentRefsFilter sets a filter which is given chunks referenced by the values of the store which the ChunkStore
+// does not hold, and returns those which are dangling. It lets a store which deliberately holds only part of a graph of
+// values, such as a shallow clone, reference the chunks it knows to be absent: values referencing them may be
+// committed, and garbage collection does not walk them. A nil |filter| treats every absent chunk as dangling.
+func (lvs *ValueStore) SetAbsentRefsFilter(filter HashFilterFunc) {
+	lvs.bufferMu.Lock()
+	defer lvs.bufferMu.Unlock()
+	lvs.absentRefsFilter = filter
+}
+
+// danglingRefs returns the chunks of |unresolved| which are dangling.
+func (lvs *ValueStore) danglingRefs(ctx context.Context, unresolved hash.HashSet) (hash.HashSet, error) {
+	absent, err := lvs.cs.HasMany(ctx, unresolved)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(absent) == 0 || lvs.absentRefsFilter == nil {
+		return absent, nil
+	}
+
+	return lvs.absentRefsFilter(ctx, absent)
+}
+
 func (lvs *ValueStore) ChunkStore() chunks.ChunkStore {
 	return lvs.cs
 }
@@ -397,10 +440,15 @@ func (lvs *ValueStore) bufferChunk(ctx context.Context, v Value, c chunks.Chunk,
 			childHash := childRef.TargetHash()
 			if _, isBuffered := lvs.bufferedChunks[childHash]; isBuffered {
 				lvs.withBufferedChildren[h] = height
-			} else if lvs.enforceCompleteness {
-				// If the childRef isn't presently buffered, we must consider it an
-				// unresolved ref.
-				lvs.unresolvedRefs.Insert(childHash)
+			} else {
+				if lvs.enforceCompleteness {
+					// If the childRef isn't presently buffered, we must consider it an
+					// unresolved ref.
+					lvs.unresolvedRefs.Insert(childHash)
+				}
+
+				// a collection in progress must keep the chunks that buffered chunks reference
+				lvs.gcTrack(childHash)
 			}
 
 			if _, hasBufferedChildren := lvs.withBufferedChildren[childHash]; hasBufferedChildren {
@@ -535,7 +583,14 @@ func (lvs *ValueStore) Commit(ctx context.Context, current, last hash.Hash) (boo
 			}
 		}
 
-		PanicIfDangling(ctx, lvs.unresolvedRefs, lvs.cs)
+		dangling, err := lvs.danglingRefs(ctx, lvs.unresolvedRefs)
+
+		// TODO: fix panics
+		d.PanicIfError(err)
+
+		if len(dangling) != 0 {
+			d.Panic("Found dangling references to %v", dangling)
+		}
 	}
 
 	success, err := lvs.cs.Commit(ctx, current, last)
@@ -588,64 +643,222 @@ func (lvs *ValueStore) numBuffChunks() int {
 	return len(lvs.bufferedChunks)
 }
 
-// GC traverses the ValueStore from the root and removes unreferenced chunks from the ChunkStore
-func (lvs *ValueStore) GC(ctx context.Context, oldGenRefs, newGenRefs hash.HashSet) error {
-	if lvs.numBuffChunks() > 0 {
-		return errors.New("invalid GC state; bufferedChunks must be empty.")
-	}
+// GC traverses the ValueStore from the root and removes unreferenced chunks from the ChunkStore. Nothing may write to
+// the ValueStore while it runs, and the ChunkStore also removes the table files which its manifest does not reference.
+func (lvs *ValueStore) GC(ctx context.Context, oldGenRefs, newGenRefs hash.HashSet) (GCStats, error) {
+	return lvs.collectGarbage(ctx, oldGenRefs, newGenRefs, false)
+}
 
-	err := func() error {
-		lvs.bufferMu.RLock()
-		defer lvs.bufferMu.RUnlock()
-		if len(lvs.bufferedChunks) > 0 {
-			return errors.New("invalid GC state; bufferedChunks must be empty.")
-		}
-		return nil
-	}()
-	if err != nil {
-		return err
-	}
+// OnlineGC is like GC, but may run while the ValueStore is being written to: the chunks written meanwhile, and the
+// chunks referenced by values which are buffered or written meanwhile, are kept, and writers to the ChunkStore are only
+// paused at the safepoint of the collection, while it marks those chunks and swaps in the table files it wrote.
+func (lvs *ValueStore) OnlineGC(ctx context.Context, oldGenRefs, newGenRefs hash.HashSet) (GCStats, error) {
+	return lvs.collectGarbage(ctx, oldGenRefs, newGenRefs, true)
+}
 
+func (lvs *ValueStore) collectGarbage(ctx context.Context, oldGenRefs, newGenRefs hash.HashSet, online bool) (GCStats, error) {
 	lvs.versOnce.Do(lvs.expectVersion)
 
 	root, err := lvs.Root(ctx)
 
 	if err != nil {
-		return err
+		return GCStats{}, err
 	}
 
 	rootVal, err := lvs.ReadValue(ctx, root)
 	if err != nil {
-		return err
+		return GCStats{}, err
 	}
 
 	if rootVal == nil {
 		// empty root
-		return nil
+		return GCStats{}, nil
 	}
 
 	newGenRefs.Insert(root)
+
+	var stats GCStats
 	if gcs, ok := lvs.cs.(chunks.GenerationalCS); ok {
 		oldGen := gcs.OldGen()
 		newGen := gcs.NewGen()
-		err = lvs.gc(ctx, root, oldGenRefs, oldGen.HasMany, newGen, oldGen)
-		if err != nil {
-			return err
+
+		if online {
+			err = lvs.beginGC(newGen)
+			if err != nil {
+				return GCStats{}, err
+			}
+		}
+
+		hashFilter := lvs.gcHashFilter(oldGen.HasMany, oldGen, newGen)
+		err = lvs.gc(ctx, root, oldGenRefs, hashFilter, newGen, oldGen, false, &stats)
+		if err == nil {
+			err = lvs.gc(ctx, root, newGenRefs, hashFilter, newGen, newGen, online, &stats)
 		}
 
-		return lvs.gc(ctx, root, newGenRefs, oldGen.HasMany, newGen, newGen)
+		if online {
+			stats.SafepointPause = lvs.endGC(newGen)
+		}
+		lvs.purgeDecodedChunks()
+		return stats, err
 	} else if collector, ok := lvs.cs.(chunks.ChunkStoreGarbageCollector); ok {
 		if len(oldGenRefs) > 0 {
 			newGenRefs.InsertAll(oldGenRefs)
 		}
 
-		return lvs.gc(ctx, root, newGenRefs, unfilteredHashFunc, collector, collector)
+		if online {
+			err = lvs.beginGC(collector)
+			if err != nil {
+				return GCStats{}, err
+			}
+		}
+
+		err = lvs.gc(ctx, root, newGenRefs, lvs.gcHashFilter(unfilteredHashFunc, collector), collector, collector, online, &stats)
+
+		if online {
+			stats.SafepointPause = lvs.endGC(collector)
+		}
+		lvs.purgeDecodedChunks()
+		return stats, err
 	} else {
-		return chunks.ErrUnsupportedOperation
+		return GCStats{}, chunks.ErrUnsupportedOperation
+	}
+}
+
+// gcHashFilter returns |hashFilter|, preceded by the absent refs filter of the store if it has one, so that a
+// collection does not walk the chunks the store knows to be absent. The chunks absent from all of |stores| are found
+// without tracking them for the collection, as the filter runs past its safepoint.
+func (lvs *ValueStore) gcHashFilter(hashFilter HashFilterFunc, stores ...chunks.ChunkStoreGarbageCollector) HashFilterFunc {
+	lvs.bufferMu.RLock()
+	absentRefsFilter := lvs.absentRefsFilter
+	lvs.bufferMu.RUnlock()
+
+	if absentRefsFilter == nil {
+		return hashFilter
+	}
+
+	return func(ctx context.Context, hs hash.HashSet) (hash.HashSet, error) {
+		absent := hs
+		for _, store := range stores {
+			var err error
+			absent, err = store.HasManyUntracked(ctx, absent)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if len(absent) > 0 {
+			dangling, err := absentRefsFilter(ctx, absent)
+			if err != nil {
+				return nil, err
+			}
+
+			walk := hash.NewHashSet()
+			for h := range hs {
+				if !absent.Has(h) || dangling.Has(h) {
+					walk.Insert(h)
+				}
+			}
+			hs = walk
+		}
+
+		return hashFilter(ctx, hs)
+	}
+}
+
+// beginGC starts tracking the chunks written to |collector|, and the chunks referenced by buffered values, for a
+// collection.
+func (lvs *ValueStore) beginGC(collector chunks.ChunkStoreGarbageCollector) error {
+	func() {
+		lvs.bufferMu.RLock()
+		defer lvs.bufferMu.RUnlock()
+		lvs.gcMu.Lock()
+		defer lvs.gcMu.Unlock()
+
+		lvs.gcKeepers = lvs.unresolvedRefs.Copy()
+		lvs.gcSafepoint = false
+	}()
+
+	err := collector.BeginGC(lvs.gcKeep)
+	if err != nil {
+		lvs.gcMu.Lock()
+		defer lvs.gcMu.Unlock()
+		lvs.gcKeepers = nil
+	}
+
+	return err
+}
+
+// endGC stops tracking chunks for a collection, releasing the writers paused at its safepoint, and returns how long
+// they were paused.
+func (lvs *ValueStore) endGC(collector chunks.ChunkStoreGarbageCollector) time.Duration {
+	collector.EndGC()
+
+	lvs.gcMu.Lock()
+	defer lvs.gcMu.Unlock()
+
+	var pause time.Duration
+	if lvs.gcSafepoint {
+		pause = time.Since(lvs.gcSafepointAt)
+	}
+
+	lvs.gcKeepers = nil
+	lvs.gcSafepoint = false
+	return pause
+}
+
+// purgeDecodedChunks empties the cache of decoded values, which may hold values whose chunks were collected.
+func (lvs *ValueStore) purgeDecodedChunks() {
+	lvs.bufferMu.Lock()
+	defer lvs.bufferMu.Unlock()
+	lvs.decodedChunks = sizecache.New(lvs.decodedChunks.Size())
+}
+
+// gcKeep is the keeper of a collection, see chunks.ChunkStoreGarbageCollector. It returns true once the collection
+// has reached its safepoint.
+func (lvs *ValueStore) gcKeep(h hash.Hash) bool {
+	lvs.gcMu.Lock()
+	defer lvs.gcMu.Unlock()
+
+	if lvs.gcSafepoint {
+		return true
 	}
+
+	if lvs.gcKeepers != nil {
+		lvs.gcKeepers.Insert(h)
+	}
+
+	return false
 }
 
-func (lvs *ValueStore) gc(ctx context.Context, root hash.Hash, toVisit hash.HashSet, hashFilter HashFilterFunc, src, dest chunks.ChunkStoreGarbageCollector) error {
+// gcTrack records |h| as a chunk a collection in progress must keep, if there is one. Unlike gcKeep, it never asks the
+// caller to wait for the collection.
+func (lvs *ValueStore) gcTrack(h hash.Hash) {
+	lvs.gcMu.Lock()
+	defer lvs.gcMu.Unlock()
+
+	if lvs.gcKeepers != nil {
+		lvs.gcKeepers.Insert(h)
+	}
+}
+
+// gcTakeKeepers returns the chunks tracked for a collection so far, and starts tracking anew. With |safepoint|, the
+// collection reaches its safepoint, and writers to the ChunkStore are paused until it ends.
+func (lvs *ValueStore) gcTakeKeepers(safepoint bool) hash.HashSet {
+	lvs.gcMu.Lock()
+	defer lvs.gcMu.Unlock()
+
+	keepers := lvs.gcKeepers
+	lvs.gcKeepers = hash.NewHashSet()
+
+	if safepoint {
+		lvs.gcSafepoint = true
+		lvs.gcSafepointAt = time.Now()
+	}
+
+	return keepers
+}
+
+func (lvs *ValueStore) gc(ctx context.Context, root hash.Hash, toVisit hash.HashSet, hashFilter HashFilterFunc, src, dest chunks.ChunkStoreGarbageCollector, safepoint bool, stats *GCStats) error {
 	keepChunks := make(chan []hash.Hash, gcBuffSize)
 
 	eg, ctx := errgroup.WithContext(ctx)
@@ -656,6 +869,7 @@ func (lvs *ValueStore) gc(ctx context.Context, root hash.Hash, toVisit hash.Hash
 	keepHashes := func(hs []hash.Hash) error {
 		select {
 		case keepChunks <- hs:
+			stats.ChunksKept += uint64(len(hs))
 			return nil
 		case <-ctx.Done():
 			return ctx.Err()
@@ -677,6 +891,13 @@ func (lvs *ValueStore) gc(ctx context.Context, root hash.Hash, toVisit hash.Hash
 			return err
 		}
 
+		if safepoint {
+			err = lvs.gcProcessSafepoint(ctx, visited, keepHashes, walker, hashFilter, stats)
+			if err != nil {
+				return err
+			}
+		}
+
 		// NOTE: We do not defer this close here. When keepChunks
 		// closes, it signals to NBSStore.MarkAndSweepChunks that we
 		// are done walking the references. If gcProcessRefs returns an
@@ -689,12 +910,114 @@ func (lvs *ValueStore) gc(ctx context.Context, root hash.Hash, toVisit hash.Hash
 		return nil
 	})
 
-	err := eg.Wait()
+	return eg.Wait()
+}
+
+// gcSafepointCatchUpRounds is the number of times a collection marks the chunks tracked for it before pausing writers
+// at its safepoint, so that the pause only covers the chunks written during the last round.
+const gcSafepointCatchUpRounds = 3
+
+// gcProcessSafepoint marks the chunks tracked for a collection, and the chunks they reference, and finally pauses
+// writers to the ChunkStore at the safepoint of the collection, so that no chunk they write is missed.
+func (lvs *ValueStore) gcProcessSafepoint(ctx context.Context, visited hash.HashSet, keepHashes func(hs []hash.Hash) error, walker *parallelRefWalker, hashFilter HashFilterFunc, stats *GCStats) error {
+	for i := 0; i < gcSafepointCatchUpRounds; i++ {
+		keepers := lvs.gcTakeKeepers(false)
+		if len(keepers) == 0 {
+			break
+		}
+
+		err := lvs.gcProcessKeepers(ctx, visited, keepers, keepHashes, walker, hashFilter, stats)
+		if err != nil {
+			return err
+		}
+	}
+
+	return lvs.gcProcessKeepers(ctx, visited, lvs.gcTakeKeepers(true), keepHashes, walker, hashFilter, stats)
+}
+
+// gcProcessKeepers marks |keepers| and the chunks they reference. Unlike gcProcessRefs, it reads chunks straight from
+// the ChunkStore, as writers paused at the safepoint may hold |lvs.bufferMu|, and skips chunks which are not there,
+// as writers may track chunks before they write them.
+func (lvs *ValueStore) gcProcessKeepers(ctx context.Context, visited hash.HashSet, keepers hash.HashSet, keepHashes func(hs []hash.Hash) error, walker *parallelRefWalker, hashFilter HashFilterFunc, stats *GCStats) error {
+	toVisit := hash.NewHashSet()
+	for h := range keepers {
+		if !visited.Has(h) {
+			visited.Insert(h)
+			toVisit.Insert(h)
+		}
+	}
+
+	toVisit, err := hashFilter(ctx, toVisit)
 	if err != nil {
 		return err
 	}
 
-	return eg.Wait()
+	stats.ConcurrentChunks += uint64(len(toVisit))
+
+	for len(toVisit) > 0 {
+		next := hash.NewHashSet()
+		for _, batch := range makeBatches([]hash.HashSet{toVisit}, len(toVisit)) {
+			if err := keepHashes(batch); err != nil {
+				return err
+			}
+
+			vals, err := lvs.gcReadManyValues(ctx, batch)
+			if err != nil {
+				return err
+			}
+
+			hashes, err := walker.GetRefSet(visited, vals)
+			if err != nil {
+				return err
+			}
+
+			hashes, err = hashFilter(ctx, hashes)
+			if err != nil {
+				return err
+			}
+
+			next.InsertAll(hashes)
+		}
+		toVisit = next
+	}
+
+	return nil
+}
+
+// gcReadManyValues reads the values of |hashes| which are in the ChunkStore, skipping those which are not.
+func (lvs *ValueStore) gcReadManyValues(ctx context.Context, hashes hash.HashSlice) (ValueSlice, error) {
+	vals := make(ValueSlice, 0, len(hashes))
+
+	remaining := hash.HashSet{}
+	for _, h := range hashes {
+		if v, ok := lvs.decodedChunks.Get(h); ok {
+			vals = append(vals, v.(Value))
+		} else {
+			remaining.Insert(h)
+		}
+	}
+
+	mu := new(sync.Mutex)
+	var decodeErr error
+	err := lvs.cs.GetMany(ctx, remaining, func(ctx context.Context, c *chunks.Chunk) {
+		mu.Lock()
+		defer mu.Unlock()
+		if decodeErr != nil {
+			return
+		}
+
+		var v Value
+		v, decodeErr = DecodeValue(*c, lvs)
+		if decodeErr == nil && v != nil {
+			vals = append(vals, v)
+		}
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return vals, decodeErr
 }
 
 func (lvs *ValueStore) gcProcessRefs(ctx context.Context, visited hash.HashSet, toVisit []hash.HashSet, keepHashes func(hs []hash.Hash) error, walker *parallelRefWalker, hashFilter HashFilterFunc) error {
@@ -736,19 +1059,6 @@ func (lvs *ValueStore) gcProcessRefs(ctx context.Context, visited hash.HashSet,
 		}
 	}
 
-	lvs.bufferMu.Lock()
-	defer lvs.bufferMu.Unlock()
-
-	if len(lvs.bufferedChunks) > 0 {
-		return errors.New("invalid GC state; bufferedChunks started empty and was not empty at end of run.")
-	}
-
-	// purge the cache
-	lvs.decodedChunks = sizecache.New(lvs.decodedChunks.Size())
-	lvs.bufferedChunks = make(map[hash.Hash]chunks.Chunk, lvs.bufferedChunkSize)
-	lvs.bufferedChunkSize = 0
-	lvs.withBufferedChildren = map[hash.Hash]uint64{}
-
 	return nil
 }
 
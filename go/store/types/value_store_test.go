@@ -23,7 +23,9 @@ package types
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -379,7 +381,7 @@ func TestGC(t *testing.T) {
 	require.NoError(t, err)
 	assert.NotNil(v2)
 
-	err = vs.GC(ctx, hash.HashSet{}, hash.HashSet{})
+	_, err = vs.GC(ctx, hash.HashSet{}, hash.HashSet{})
 	require.NoError(t, err)
 
 	v1, err = vs.ReadValue(ctx, h1) // non-nil
@@ -390,6 +392,120 @@ func TestGC(t *testing.T) {
 	assert.Nil(v2)
 }
 
+// writeDuringGCStore is a chunk store which calls |onMarkAndSweep| in its own goroutine when a collection starts
+// sweeping its chunks.
+type writeDuringGCStore struct {
+	*chunks.TestStoreView
+	onMarkAndSweep func()
+	done           chan struct{}
+}
+
+func (s *writeDuringGCStore) MarkAndSweepChunks(ctx context.Context, last hash.Hash, keepChunks <-chan []hash.Hash, dest chunks.ChunkStore) error {
+	go func() {
+		defer close(s.done)
+		s.onMarkAndSweep()
+	}()
+	return s.TestStoreView.MarkAndSweepChunks(ctx, last, keepChunks, s.TestStoreView)
+}
+
+func TestGCKeepsConcurrentWrites(t *testing.T) {
+	ctx := context.Background()
+	ts := &chunks.TestStorage{}
+	cs := &writeDuringGCStore{TestStoreView: ts.NewView(), done: make(chan struct{})}
+	vs := NewValueStore(cs)
+
+	r1 := mustRef(vs.WriteValue(ctx, String("committed")))
+	h1 := mustRef(vs.WriteValue(ctx, mustSet(NewSet(ctx, vs, r1)))).TargetHash()
+	rt, err := vs.Root(ctx)
+	require.NoError(t, err)
+	ok, err := vs.Commit(ctx, h1, rt)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// another writer commits a new root while the collection runs
+	var r2 Ref
+	cs.onMarkAndSweep = func() {
+		r := mustRef(vs.WriteValue(ctx, String("concurrent")))
+		r2 = mustRef(vs.WriteValue(ctx, mustList(NewList(ctx, vs, r))))
+		ok, err := vs.Commit(ctx, r2.TargetHash(), h1)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	}
+
+	stats, err := vs.OnlineGC(ctx, hash.HashSet{}, hash.HashSet{})
+	require.NoError(t, err)
+	<-cs.done
+	assert.True(t, stats.ChunksKept > 0)
+
+	v1, err := vs.ReadValue(ctx, h1)
+	require.NoError(t, err)
+	assert.NotNil(t, v1)
+
+	v2, err := vs.ReadValue(ctx, r2.TargetHash())
+	require.NoError(t, err)
+	require.NotNil(t, v2)
+	s, err := v2.(List).Get(ctx, 0)
+	require.NoError(t, err)
+	v3, err := s.(Ref).TargetValue(ctx, vs)
+	require.NoError(t, err)
+	assert.Equal(t, String("concurrent"), v3)
+}
+
+func TestGCWithAbsentRefsPastSafepoint(t *testing.T) {
+	ctx := context.Background()
+	ts := &chunks.TestStorage{}
+	cs := ts.NewView()
+	vs := NewValueStore(cs)
+
+	// absentRef returns a ref to a value which is never written, which the store knows to be absent
+	absentRef := func(i int) Ref {
+		return mustRef(NewRef(String(fmt.Sprintf("absent %d", i)), vs.Format()))
+	}
+
+	// while the collection walks the graph, every absent ref it finds is followed by a write of a new chunk which
+	// references another absent chunk, until the collection has reached its safepoint
+	collecting, writes := false, 0
+	vs.SetAbsentRefsFilter(func(ctx context.Context, hs hash.HashSet) (hash.HashSet, error) {
+		if collecting && writes <= gcSafepointCatchUpRounds {
+			writes++
+			c, err := EncodeValue(mustList(NewList(ctx, vs, absentRef(writes))), vs.Format())
+			if err != nil {
+				return nil, err
+			}
+			if err = cs.Put(ctx, c); err != nil {
+				return nil, err
+			}
+		}
+		return hash.HashSet{}, nil
+	})
+
+	h := mustRef(vs.WriteValue(ctx, mustList(NewList(ctx, vs, absentRef(0))))).TargetHash()
+	rt, err := vs.Root(ctx)
+	require.NoError(t, err)
+	ok, err := vs.Commit(ctx, h, rt)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	collecting = true
+	done := make(chan error)
+	go func() {
+		_, err := vs.OnlineGC(ctx, hash.HashSet{}, hash.HashSet{})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "garbage collection did not finish")
+	}
+	assert.Equal(t, gcSafepointCatchUpRounds+1, writes)
+
+	v, err := vs.ReadValue(ctx, h)
+	require.NoError(t, err)
+	assert.NotNil(t, v)
+}
+
 type badVersionStore struct {
 	chunks.ChunkStore
 }
@@ -15,129 +15,86 @@
 package main
 
 import (
-	"context"
 	"flag"
 	"fmt"
 	"log"
-	"net"
-	"net/http"
 	"os"
 	"os/signal"
-	"sync"
+	"syscall"
 
-	"google.golang.org/grpc"
-
-	remotesapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/remotesapi/v1alpha1"
-	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+	"github.com/dolthub/dolt/go/libraries/doltcore/remotesrv"
 )
 
 func main() {
+	configParam := flag.String("config", "", "yaml config file of the server. The other parameters override its values.")
 	dirParam := flag.String("dir", "", "root directory that this command will run in.")
-	grpcPortParam := flag.Int("grpc-port", -1, "root directory that this command will run in.")
-	httpPortParam := flag.Int("http-port", -1, "root directory that this command will run in.")
-	httpHostParam := flag.String("http-host", "localhost", "host url that this command will assume.")
+	grpcPortParam := flag.Int("grpc-port", -1, "port the grpc server listens on.")
+	httpPortParam := flag.Int("http-port", -1, "port the http server listens on.")
+	httpHostParam := flag.String("http-host", "", "host url that this command will assume.")
+	authFileParam := flag.String("auth-file", "", "credentials file which requests are authorized against. When not provided, all requests are allowed.")
+	readOnlyParam := flag.Bool("read-only", false, "reject every request which would modify a repository.")
+	autoCreateParam := flag.Bool("auto-create-repos", false, "create repositories which do not exist when they are first accessed by a caller which may write to them, such as by a push.")
+	tlsCertParam := flag.String("tls-cert", "", "certificate file used for TLS by both the grpc and http servers.")
+	tlsKeyParam := flag.String("tls-key", "", "key file of the TLS certificate.")
 	flag.Parse()
 
-	if dirParam != nil && len(*dirParam) > 0 {
-		err := os.Chdir(*dirParam)
+	cfg := remotesrv.DefaultConfig()
+	if *configParam != "" {
+		data, err := os.ReadFile(*configParam)
+		if err != nil {
+			log.Fatalln("failed to read config file:", err.Error())
+		}
 
+		cfg, err = remotesrv.ReadConfig(data)
 		if err != nil {
-			log.Fatalln("failed to chdir to:", *dirParam)
-			log.Fatalln("error:", err.Error())
-			os.Exit(1)
-		} else {
-			log.Println("cwd set to " + *dirParam)
+			log.Fatalln("failed to parse config file:", err.Error())
 		}
-	} else {
-		log.Println("'dir' parameter not provided. Using the current working dir.")
 	}
 
+	if *dirParam != "" {
+		cfg.DataDir = *dirParam
+	}
+	if *grpcPortParam != -1 {
+		cfg.GRPC.Port = *grpcPortParam
+	}
 	if *httpPortParam != -1 {
-		*httpHostParam = fmt.Sprintf("%s:%d", *httpHostParam, *httpPortParam)
-	} else {
-		*httpPortParam = 80
-		log.Println("'http-port' parameter not provided. Using default port 80")
+		cfg.HTTP.Port = *httpPortParam
+	}
+	if *httpHostParam != "" {
+		cfg.HTTP.AdvertiseHost = fmt.Sprintf("%s:%d", *httpHostParam, cfg.HTTP.Port)
+	}
+	if *authFileParam != "" {
+		cfg.AuthFile = *authFileParam
+	}
+	if *readOnlyParam {
+		cfg.ReadOnly = true
+	}
+	if *autoCreateParam {
+		cfg.AutoCreateRepos = true
+	}
+	if *tlsCertParam != "" {
+		cfg.TLS.Cert = *tlsCertParam
+	}
+	if *tlsKeyParam != "" {
+		cfg.TLS.Key = *tlsKeyParam
 	}
 
-	if *grpcPortParam == -1 {
-		*grpcPortParam = 50051
-		log.Println("'grpc-port' parameter not provided. Using default port 50051")
+	server, err := remotesrv.NewServer(cfg)
+	if err != nil {
+		log.Fatalln("failed to start server:", err.Error())
 	}
 
-	stopChan, wg := startServer(*httpHostParam, *httpPortParam, *grpcPortParam)
+	server.Start()
 	waitForSignal()
 
-	close(stopChan)
-	wg.Wait()
+	err = server.Stop()
+	if err != nil {
+		log.Fatalln("error stopping server:", err.Error())
+	}
 }
 
 func waitForSignal() {
 	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, os.Kill)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 	<-c
 }
-
-func startServer(httpHost string, httpPort, grpcPort int) (chan interface{}, *sync.WaitGroup) {
-	wg := sync.WaitGroup{}
-	stopChan := make(chan interface{})
-
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		httpServer(httpPort, stopChan)
-	}()
-
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		grpcServer(httpHost, grpcPort, stopChan)
-	}()
-
-	return stopChan, &wg
-}
-
-func grpcServer(httpHost string, grpcPort int, stopChan chan interface{}) {
-	defer func() {
-		log.Println("exiting grpc Server go routine")
-	}()
-
-	dbCache := NewLocalCSCache(filesys.LocalFS)
-	chnkSt := NewHttpFSBackedChunkStore(httpHost, dbCache)
-
-	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", grpcPort))
-	if err != nil {
-		log.Fatalf("failed to listen: %v", err)
-	}
-
-	grpcServer := grpc.NewServer(grpc.MaxRecvMsgSize(128 * 1024 * 1024))
-	go func() {
-		remotesapi.RegisterChunkStoreServiceServer(grpcServer, chnkSt)
-
-		log.Println("Starting grpc server on port", grpcPort)
-		err := grpcServer.Serve(lis)
-		log.Println("grpc server exited. error:", err)
-	}()
-
-	<-stopChan
-	grpcServer.GracefulStop()
-}
-
-func httpServer(httpPort int, stopChan chan interface{}) {
-	defer func() {
-		log.Println("exiting http Server go routine")
-	}()
-
-	server := http.Server{
-		Addr:    fmt.Sprintf(":%d", httpPort),
-		Handler: http.HandlerFunc(ServeHTTP),
-	}
-
-	go func() {
-		log.Println("Starting http server on port ", httpPort)
-		err := server.ListenAndServe()
-		log.Println("http server exited. exit error:", err)
-	}()
-
-	<-stopChan
-	server.Shutdown(context.Background())
-}
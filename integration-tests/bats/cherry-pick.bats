@@ -0,0 +1,148 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+    dolt sql -q "CREATE TABLE test(pk BIGINT PRIMARY KEY, v1 BIGINT)"
+    dolt add -A
+    dolt commit -m "Created table"
+    dolt checkout -b branch1
+    dolt sql -q "INSERT INTO test VALUES (1, 1)"
+    dolt add -A
+    dolt commit -m "Inserted 1"
+    dolt sql -q "INSERT INTO test VALUES (2, 2)"
+    dolt add -A
+    dolt commit -m "Inserted 2"
+    dolt checkout main
+}
+
+teardown() {
+    assert_feature_version
+    teardown_common
+}
+
+@test "cherry-pick: branch head" {
+    dolt cherry-pick branch1
+    run dolt sql -q "SELECT * FROM test" -r=csv
+    [ "$status" -eq "0" ]
+    [[ "$output" =~ "pk,v1" ]] || false
+    [[ "$output" =~ "2,2" ]] || false
+    [[ "${#lines[@]}" = "2" ]] || false
+
+    run dolt log -n 1
+    [ "$status" -eq "0" ]
+    [[ "$output" =~ "Inserted 2" ]] || false
+}
+
+@test "cherry-pick: ancestor of branch head" {
+    dolt cherry-pick branch1~1
+    run dolt sql -q "SELECT * FROM test" -r=csv
+    [ "$status" -eq "0" ]
+    [[ "$output" =~ "pk,v1" ]] || false
+    [[ "$output" =~ "1,1" ]] || false
+    [[ "${#lines[@]}" = "2" ]] || false
+}
+
+@test "cherry-pick: has changes in the working set" {
+    dolt sql -q "INSERT INTO test VALUES (4, 4)"
+    run dolt cherry-pick branch1
+    [ "$status" -eq "1" ]
+    [[ "$output" =~ "changes" ]] || false
+}
+
+@test "cherry-pick: no changes" {
+    dolt cherry-pick branch1
+    run dolt cherry-pick branch1
+    [ "$status" -eq "0" ]
+    [[ "$output" =~ "No changes were made" ]] || false
+}
+
+@test "cherry-pick: invalid hash" {
+    run dolt cherry-pick aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa
+    [ "$status" -eq "1" ]
+    [[ "$output" =~ "hash" ]] || false
+}
+
+@test "cherry-pick: conflicts are written to the working set" {
+    dolt sql -q "INSERT INTO test VALUES (2, 3)"
+    dolt add -A
+    dolt commit -m "Inserted 2 on main"
+    run dolt cherry-pick branch1
+    [ "$status" -eq "1" ]
+    [[ "$output" =~ "CONFLICT" ]] || false
+
+    run dolt sql -q "SELECT base_v1, our_v1, their_v1 FROM dolt_conflicts_test" -r=csv
+    [ "$status" -eq "0" ]
+    [[ "$output" =~ ",3,2" ]] || false
+
+    dolt conflicts resolve --theirs test
+    dolt add -A
+    dolt commit -m "Resolved cherry-pick"
+    run dolt sql -q "SELECT * FROM test" -r=csv
+    [ "$status" -eq "0" ]
+    [[ "$output" =~ "2,2" ]] || false
+}
+
+@test "cherry-pick: constraint violations are written to the working set" {
+    dolt sql <<"SQL"
+CREATE TABLE parent (pk BIGINT PRIMARY KEY, v1 BIGINT, INDEX(v1));
+CREATE TABLE child (pk BIGINT PRIMARY KEY, v1 BIGINT, CONSTRAINT fk_name FOREIGN KEY (v1) REFERENCES parent (v1));
+INSERT INTO parent VALUES (10, 1), (20, 2);
+SQL
+    dolt add -A
+    dolt commit -m "Created parent and child"
+    dolt branch branch2
+    dolt sql -q "DELETE FROM parent WHERE pk = 20"
+    dolt add -A
+    dolt commit -m "Deleted parent 20"
+    dolt checkout branch2
+    dolt sql -q "INSERT INTO child VALUES (1, 2)"
+    dolt add -A
+    dolt commit -m "Inserted child referencing 20"
+    dolt checkout main
+
+    run dolt cherry-pick branch2
+    [ "$status" -eq "1" ]
+    [[ "$output" =~ "constraint violation" ]] || false
+
+    run dolt sql -q "SELECT * FROM dolt_constraint_violations" -r=csv
+    [ "$status" -eq "0" ]
+    [[ "$output" =~ "child,1" ]] || false
+}
+
+@test "cherry-pick: with --author parameter" {
+    dolt cherry-pick branch1 --author "john <johndoe@gmail.com>"
+    run dolt log -n 1
+    [ "$status" -eq "0" ]
+    [[ "$output" =~ "Author: john <johndoe@gmail.com>" ]] || false
+}
+
+@test "cherry-pick: SQL branch head" {
+    run dolt sql -q "SELECT DOLT_CHERRY_PICK('branch1')"
+    [ "$status" -eq "0" ]
+    run dolt sql -q "SELECT * FROM test" -r=csv
+    [ "$status" -eq "0" ]
+    [[ "$output" =~ "pk,v1" ]] || false
+    [[ "$output" =~ "2,2" ]] || false
+    [[ "${#lines[@]}" = "2" ]] || false
+}
+
+@test "cherry-pick: SQL has changes in the working set" {
+    dolt sql -q "INSERT INTO test VALUES (4, 4)"
+    run dolt sql -q "SELECT DOLT_CHERRY_PICK('branch1')"
+    [ "$status" -eq "1" ]
+    [[ "$output" =~ "changes" ]] || false
+}
+
+@test "cherry-pick: SQL conflicts" {
+    dolt sql -q "INSERT INTO test VALUES (2, 3)"
+    dolt add -A
+    dolt commit -m "Inserted 2 on main"
+    run dolt sql << SQL
+SET autocommit = 0;
+SELECT DOLT_CHERRY_PICK('branch1');
+SELECT base_v1, our_v1, their_v1 FROM dolt_conflicts_test;
+SQL
+    [ "$status" -eq "0" ]
+    [[ "$output" =~ "| NULL    | 3      | 2        |" ]] || false
+}
@@ -649,4 +649,52 @@ SQL
 
     run dolt diff HEAD~1
     [ "${#lines[@]}" -eq 2007 ] # 2000 diffs + 6 for top rows before data + 1 for bottom row of table
-}
\ No newline at end of file
+}
+
+@test "diff: sql schema diff includes column type changes and check constraints" {
+    dolt sql -q "create table t(pk int primary key, val int)"
+    dolt commit -am "creating table"
+
+    dolt sql -q "alter table t modify column val bigint"
+    dolt sql -q "alter table t add constraint val_positive check (val > 0)"
+    run dolt diff -s -r sql
+    [ $status -eq 0 ]
+    [ "${lines[0]}" = 'ALTER TABLE `t` MODIFY COLUMN `val` BIGINT;' ]
+    [ "${lines[1]}" = 'ALTER TABLE `t` ADD CONSTRAINT `val_positive` CHECK ((val > 0));' ]
+    [ "${#lines[@]}" -eq 2 ]
+}
+
+@test "diff: json output" {
+    dolt sql -q "insert into test values (0,0,0,0,0,0), (1,1,1,1,1,1), (2,2,2,2,2,2)"
+    dolt add .
+    dolt commit -m "added rows"
+
+    dolt sql -q "update test set c1 = 10 where pk = 1"
+    dolt sql -q "delete from test where pk = 2"
+    dolt sql -q "insert into test values (3,3,3,3,3,3)"
+    dolt sql -q "alter table test add column c6 int"
+
+    run dolt diff -r json
+    [ $status -eq 0 ]
+    echo "$output" | python3 -c "import json, sys; json.load(sys.stdin)"
+    [[ "$output" =~ '{"tables":[{"name":"test","from_name":"test","to_name":"test","diff_type":"modified","schema_diff":[{"object_type":"column","object_name":"c6","diff_type":"added"' ]] || false
+    [[ "$output" =~ '{"diff_type":"modified","before":{"pk":1,"c1":1,"c2":1,"c3":1,"c4":1,"c5":1},"after":{"pk":1,"c1":10,"c2":1,"c3":1,"c4":1,"c5":1,"c6":null}}' ]] || false
+    [[ "$output" =~ '{"diff_type":"removed","before":{"pk":2,"c1":2,"c2":2,"c3":2,"c4":2,"c5":2},"after":null}' ]] || false
+    [[ "$output" =~ '{"diff_type":"added","before":null,"after":{"pk":3,"c1":3,"c2":3,"c3":3,"c4":3,"c5":3,"c6":null}}' ]] || false
+
+    run dolt diff -r json --data --where "to_pk=3"
+    [ $status -eq 0 ]
+    [ "$output" = '{"tables":[{"name":"test","from_name":"test","to_name":"test","diff_type":"modified","data_diff":[{"diff_type":"added","before":null,"after":{"pk":3,"c1":3,"c2":3,"c3":3,"c4":3,"c5":3,"c6":null}}]}]}' ]
+
+    run dolt diff -r json --data --limit 1
+    [ $status -eq 0 ]
+    [ "$output" = '{"tables":[{"name":"test","from_name":"test","to_name":"test","diff_type":"modified","data_diff":[{"diff_type":"modified","before":{"pk":1,"c1":1,"c2":1,"c3":1,"c4":1,"c5":1},"after":{"pk":1,"c1":10,"c2":1,"c3":1,"c4":1,"c5":1,"c6":null}}]}]}' ]
+
+    run dolt diff -r json --schema
+    [ $status -eq 0 ]
+    [ "$output" = '{"tables":[{"name":"test","from_name":"test","to_name":"test","diff_type":"modified","schema_diff":[{"object_type":"column","object_name":"c6","diff_type":"added","from_definition":null,"to_definition":"`c6` INT","statements":["ALTER TABLE `test` ADD `c6` INT;"]}]}]}' ]
+
+    run dolt diff -r json --summary
+    [ $status -eq 0 ]
+    [ "$output" = '{"tables":[{"name":"test","from_name":"test","to_name":"test","diff_type":"modified","summary":{"cells_modified":1,"new_row_count":3,"old_row_count":3,"rows_added":1,"rows_deleted":1,"rows_modified":1,"rows_unmodified":1}}]}' ]
+}
@@ -0,0 +1,92 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    KEY_DIR="$BATS_TMPDIR/encryption-keys-$$"
+    mkdir -p "$KEY_DIR"
+    OLD_KEY=$(head -c 32 /dev/urandom | base64)
+    NEW_KEY=$(head -c 32 /dev/urandom | base64)
+    write_key_file "$KEY_DIR/keys.json" old "\"old\": \"$OLD_KEY\""
+    export DOLT_ENCRYPTION_KEY_FILE="$KEY_DIR/keys.json"
+
+    setup_common
+    dolt sql -q "CREATE TABLE test(pk BIGINT PRIMARY KEY, v1 VARCHAR(64))"
+    dolt sql -q "INSERT INTO test VALUES (1, 'plaintext-marker-value'), (2, 'another-value')"
+    dolt add -A
+    dolt commit -m "Created table"
+}
+
+teardown() {
+    unset DOLT_ENCRYPTION_KEY_FILE
+    rm -rf "$KEY_DIR"
+    teardown_common
+}
+
+# write_key_file writes an encryption key file to |path| whose active key is |active|, and whose keys are |keys|
+write_key_file() {
+    echo "{\"active\": \"$2\", \"keys\": {$3}}" > "$1"
+}
+
+@test "encryption: table files and manifests are encrypted" {
+    run grep -r "plaintext-marker-value" .dolt/noms
+    [ "$status" -ne 0 ]
+
+    run dolt sql -q "SELECT v1 FROM test WHERE pk = 1" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "plaintext-marker-value" ]] || false
+
+    dolt gc
+    run grep -r "plaintext-marker-value" .dolt/noms
+    [ "$status" -ne 0 ]
+
+    run dolt fsck
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "no damage found" ]] || false
+}
+
+@test "encryption: reading without the key fails" {
+    unset DOLT_ENCRYPTION_KEY_FILE
+    run dolt log
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "encrypted" ]] || false
+
+    write_key_file "$KEY_DIR/other.json" other "\"other\": \"$NEW_KEY\""
+    export DOLT_ENCRYPTION_KEY_FILE="$KEY_DIR/other.json"
+    run dolt log
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "not in the encryption key file" ]] || false
+}
+
+@test "encryption: keys are rotated by gc" {
+    write_key_file "$KEY_DIR/keys.json" new "\"old\": \"$OLD_KEY\", \"new\": \"$NEW_KEY\""
+    dolt sql -q "INSERT INTO test VALUES (3, 'after-rotation')"
+    dolt add -A
+    dolt commit -m "Added a row"
+    dolt gc
+
+    write_key_file "$KEY_DIR/keys.json" new "\"new\": \"$NEW_KEY\""
+    run dolt sql -q "SELECT COUNT(*) FROM test" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "3" ]] || false
+
+    run dolt log
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Created table" ]] || false
+
+    run dolt fsck
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "no damage found" ]] || false
+}
+
+@test "encryption: the chunk journal is not supported" {
+    DOLT_ENABLE_CHUNK_JOURNAL=true run dolt log
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "chunk journal" ]] || false
+}
+
+@test "encryption: invalid key file" {
+    echo "not json" > "$KEY_DIR/keys.json"
+    run dolt log
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "Failed to load database" ]] || false
+}
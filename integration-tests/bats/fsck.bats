@@ -0,0 +1,79 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+    dolt sql -q "CREATE TABLE test(pk BIGINT PRIMARY KEY, v1 BIGINT, INDEX (v1))"
+    dolt sql -q "INSERT INTO test VALUES (1, 1), (2, 2)"
+    dolt add -A
+    dolt commit -m "Created table"
+}
+
+teardown() {
+    teardown_common
+}
+
+# flip_byte inverts the byte at |offset| of the file |path|; negative offsets count from the end of the file
+flip_byte() {
+    python3 -c "
+import sys
+path, offset = sys.argv[1], int(sys.argv[2])
+data = bytearray(open(path, 'rb').read())
+data[offset] ^= 0xff
+open(path, 'wb').write(data)" "$1" "$2"
+}
+
+@test "fsck: intact repository" {
+    dolt branch other
+    dolt sql -q "INSERT INTO test VALUES (3, 3)"
+    dolt gc
+
+    run dolt fsck
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "no damage found" ]] || false
+    ! [[ "$output" =~ "orphaned" ]] || false
+}
+
+@test "fsck: reports orphaned chunks" {
+    dolt sql -q "INSERT INTO test VALUES (3, 3)"
+    dolt sql -q "DELETE FROM test WHERE pk = 3"
+
+    run dolt fsck --verbose
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "orphaned chunks, which can be removed with dolt gc" ]] || false
+    [[ "$output" =~ "orphaned chunk " ]] || false
+    [[ "$output" =~ "no damage found" ]] || false
+}
+
+@test "fsck: corrupt chunk" {
+    dolt gc
+    table_file=$(ls .dolt/noms/oldgen | grep -v -e LOCK -e manifest | head -n 1)
+    flip_byte ".dolt/noms/oldgen/$table_file" 0
+
+    run dolt fsck
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "in table file" ]] || false
+    [[ "$output" =~ "needed by refs/heads/main" ]] || false
+    [[ "$output" =~ "damage found" ]] || false
+}
+
+@test "fsck: invalid table file footer" {
+    dolt gc
+    table_file=$(ls .dolt/noms/oldgen | grep -v -e LOCK -e manifest | head -n 1)
+    flip_byte ".dolt/noms/oldgen/$table_file" -1
+
+    run dolt fsck
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "damaged table file" ]] || false
+    [[ "$output" =~ "failed to load the database" ]] || false
+}
+
+@test "fsck: not a repository" {
+    cd $BATS_TMPDIR
+    mkdir not-a-repo-$$
+    cd not-a-repo-$$
+
+    run dolt fsck
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "not a valid dolt repository" ]] || false
+}
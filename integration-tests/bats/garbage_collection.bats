@@ -8,7 +8,7 @@ setup() {
     mkdir remotes-$$
     mkdir remotes-$$/empty
     echo remotesrv log available here $BATS_TMPDIR/remotes-$$/remotesrv.log
-    remotesrv --http-port 1234 --dir ./remotes-$$ &> ./remotes-$$/remotesrv.log 3>&- &
+    remotesrv --http-port 1234 --dir ./remotes-$$ --auto-create-repos &> ./remotes-$$/remotesrv.log 3>&- &
     remotesrv_pid=$!
     cd dolt-repo-$$
     mkdir "dolt-repo-clones"
@@ -188,3 +188,33 @@ setup_merge() {
     [ "$status" -eq 0 ]
     [[ "${lines[1]}" =~ "9,99" ]] || false
 }
+
+@test "garbage_collection: dolt_gc() sql function" {
+    dolt sql <<SQL
+CREATE TABLE test (pk int PRIMARY KEY, c0 longtext);
+INSERT INTO test VALUES (1, repeat('a', 100000));
+SQL
+    dolt add .
+    dolt commit -m "added row 1"
+
+    # make some garbage
+    dolt sql -q "UPDATE test SET c0 = repeat('b', 100000);"
+    dolt reset --hard
+
+    run dolt sql -r csv <<SQL
+INSERT INTO test VALUES (2, 'uncommitted');
+SELECT json_extract(dolt_gc(), '$.bytes_reclaimed') > 0;
+SELECT pk, length(c0) FROM test;
+SQL
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "true" ]] || false
+    [[ "$output" =~ "1,100000" ]] || false
+    [[ "$output" =~ "2,11" ]] || false
+
+    run dolt sql -q "SELECT pk, c0 FROM test WHERE pk = 2;" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "2,uncommitted" ]] || false
+
+    run dolt fsck
+    [ "$status" -eq 0 ]
+}
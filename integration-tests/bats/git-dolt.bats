@@ -9,7 +9,7 @@ setup() {
     cd $BATS_TMPDIR
     mkdir remotes-$$
     echo remotesrv log available here $BATS_TMPDIR/remotes-$$/remotesrv.log
-    remotesrv --http-port 1234 --dir ./remotes-$$ &> ./remotes-$$/remotesrv.log 3>&- &
+    remotesrv --http-port 1234 --dir ./remotes-$$ --auto-create-repos &> ./remotes-$$/remotesrv.log 3>&- &
     remotesrv_pid=$!
     cd dolt-repo-$$
     dolt remote add test-remote $REMOTE
@@ -31,10 +31,11 @@ else:
 
 from pytest import DoltConnection, csv_to_row_maps
 
+user = os.environ.get('SQL_USER', 'dolt')
 if not database:
-    dc = DoltConnection(port=int(port_str), database=None, user='dolt', auto_commit=auto_commit)
+    dc = DoltConnection(port=int(port_str), database=None, user=user, auto_commit=auto_commit)
 else:
-    dc = DoltConnection(port=int(port_str), database=database, user='dolt', auto_commit=auto_commit)
+    dc = DoltConnection(port=int(port_str), database=database, user=user, auto_commit=auto_commit)
 
 dc.connect()
 
@@ -187,6 +188,7 @@ stop_sql_server() {
 #  * param4 is a csv representing the expected result set.  If a query is not expected to have a result set "" should
 #      be passed.
 #  * param5 is an expected exception string. Mutually exclusive with param4
+# The connection is made as the user dolt, unless another user is given in $SQL_USER.
 server_query() {
     let PORT="$$ % (65536-1024) + 1024"
     PYTEST_DIR="$BATS_TEST_DIRNAME/helper"
@@ -612,3 +612,59 @@ SQL
     [[ "$output" =~ "test1" ]] || false
     [[ ! "$output" =~ "test2" ]] || false
 }
+
+@test "merge: column merge strategies resolve rows changed on both sides" {
+    dolt sql -q "INSERT INTO test1 VALUES (0,10,100), (1,10,100)"
+    dolt sql -q "INSERT INTO dolt_merge_strategies VALUES ('test1','c1','sum-of-deltas',NULL), ('test1','c2','max',NULL)"
+    dolt add .
+    dolt commit -m "added rows and merge strategies"
+
+    dolt checkout -b merge_branch
+    dolt sql -q "UPDATE test1 SET c1 = c1 + 5, c2 = 200 WHERE pk = 0"
+    dolt sql -q "UPDATE test1 SET c2 = NULL WHERE pk = 1"
+    dolt commit -am "changes on merge_branch"
+
+    dolt checkout main
+    dolt sql -q "UPDATE test1 SET c1 = c1 - 3, c2 = 150 WHERE pk = 0"
+    dolt commit -am "changes on main"
+
+    run dolt merge merge_branch
+    [ "$status" -eq 0 ]
+    [[ ! "$output" =~ "CONFLICT" ]] || false
+    run dolt sql -q "SELECT * FROM test1 ORDER BY pk" -r csv
+    [ "$status" -eq 0 ]
+    [ "${lines[1]}" = "0,12,200" ]
+    [ "${lines[2]}" = "1,10," ]
+
+    dolt commit -m "merged"
+    dolt checkout -b other
+    dolt sql -q "UPDATE test1 SET c2 = 300 WHERE pk = 1"
+    dolt commit -am "c2 on other"
+    dolt checkout main
+    dolt sql -q "UPDATE test1 SET c2 = 400 WHERE pk = 1"
+    dolt commit -am "c2 on main"
+
+    run dolt merge other
+    [ "$status" -eq 0 ]
+    run dolt sql -q "SELECT c2 FROM test1 WHERE pk = 1" -r csv
+    [ "${lines[1]}" = "400" ]
+
+    dolt sql -q "DELETE FROM dolt_merge_strategies WHERE column_name = 'c2'"
+    dolt commit -am "removed c2 strategy"
+    dolt checkout -b conflicting
+    dolt sql -q "UPDATE test1 SET c2 = 500 WHERE pk = 1"
+    dolt commit -am "c2 on conflicting"
+    dolt checkout main
+    dolt sql -q "UPDATE test1 SET c2 = 600 WHERE pk = 1"
+    dolt commit -am "c2 on main again"
+
+    run dolt merge conflicting
+    [[ "$output" =~ "CONFLICT" ]] || false
+}
+
+@test "merge: dolt_merge_strategies only accepts known strategies" {
+    run dolt sql -q "INSERT INTO dolt_merge_strategies VALUES ('test1','c1','bogus',NULL)"
+    [ "$status" -ne 0 ]
+    run dolt ls
+    [[ ! "$output" =~ "dolt_merge_strategies" ]] || false
+}
@@ -0,0 +1,217 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+    dolt sql -q "CREATE TABLE test(pk BIGINT PRIMARY KEY, v1 BIGINT)"
+    dolt sql -q "INSERT INTO test VALUES (1, 1)"
+    dolt add -A
+    dolt commit -m "Created table"
+    dolt checkout -b feature
+    dolt sql -q "INSERT INTO test VALUES (2, 2)"
+    dolt add -A
+    dolt commit -m "Inserted 2"
+    dolt sql -q "INSERT INTO test VALUES (3, 3)"
+    dolt add -A
+    dolt commit -m "Inserted 3"
+    dolt checkout main
+    dolt sql -q "INSERT INTO test VALUES (10, 10)"
+    dolt add -A
+    dolt commit -m "Inserted 10"
+    dolt checkout feature
+}
+
+teardown() {
+    assert_feature_version
+    teardown_common
+}
+
+@test "rebase: replays commits onto upstream" {
+    run dolt rebase main
+    [ "$status" -eq "0" ]
+    [[ "$output" =~ "Successfully rebased and updated refs/heads/feature" ]] || false
+
+    run dolt sql -q "SELECT * FROM test" -r=csv
+    [[ "$output" =~ "2,2" ]] || false
+    [[ "$output" =~ "3,3" ]] || false
+    [[ "$output" =~ "10,10" ]] || false
+
+    # history is linear, with the feature commits on top of main
+    run dolt log
+    [[ "$output" =~ "Inserted 3" ]] || false
+    [[ ! "$output" =~ "Merge:" ]] || false
+    run dolt sql -q "SELECT message FROM dolt_log" -r=csv
+    [ "${lines[1]}" = "Inserted 3" ]
+    [ "${lines[2]}" = "Inserted 2" ]
+    [ "${lines[3]}" = "Inserted 10" ]
+    [ "${lines[4]}" = "Created table" ]
+
+    run dolt status
+    [[ "$output" =~ "nothing to commit, working tree clean" ]] || false
+}
+
+@test "rebase: up to date and fast forward" {
+    run dolt rebase main~1
+    [ "$status" -eq "0" ]
+    [[ "$output" =~ "Current branch feature is up to date" ]] || false
+
+    dolt checkout main
+    dolt branch -c main behind
+    dolt checkout behind
+    dolt reset --hard main~1
+    dolt rebase main
+    run dolt sql -q "SELECT * FROM test" -r=csv
+    [[ "$output" =~ "10,10" ]] || false
+    [ "$(dolt sql -q "SELECT hashof('behind')" -r csv | tail -n 1)" = "$(dolt sql -q "SELECT hashof('main')" -r csv | tail -n 1)" ]
+}
+
+@test "rebase: requires a clean working set" {
+    dolt sql -q "INSERT INTO test VALUES (4, 4)"
+    run dolt rebase main
+    [ "$status" -eq "1" ]
+    [[ "$output" =~ "uncommitted changes" ]] || false
+}
+
+@test "rebase: conflicts, continue" {
+    dolt checkout main
+    dolt sql -q "INSERT INTO test VALUES (2, 20)"
+    dolt add -A
+    dolt commit -m "Conflicting insert"
+    dolt checkout feature
+
+    run dolt rebase main
+    [ "$status" -eq "1" ]
+    [[ "$output" =~ "CONFLICT" ]] || false
+    [[ "$output" =~ "could not apply" ]] || false
+    [[ "$output" =~ "Inserted 2" ]] || false
+
+    run dolt status
+    [[ "$output" =~ "You are currently rebasing branch 'feature'" ]] || false
+
+    run dolt commit -am "should fail"
+    [ "$status" -eq "1" ]
+    [[ "$output" =~ "rebase is in progress" ]] || false
+
+    run dolt rebase --continue
+    [ "$status" -eq "1" ]
+    [[ "$output" =~ "unresolved conflicts" ]] || false
+
+    dolt conflicts resolve --theirs test
+    dolt add test
+    run dolt rebase --continue
+    [ "$status" -eq "0" ]
+    [[ "$output" =~ "Successfully rebased" ]] || false
+
+    run dolt sql -q "SELECT * FROM test ORDER BY pk" -r=csv
+    [[ "$output" =~ "2,2" ]] || false
+    [[ "$output" =~ "3,3" ]] || false
+    [[ "$output" =~ "10,10" ]] || false
+
+    run dolt sql -q "SELECT message FROM dolt_log" -r=csv
+    [ "${lines[1]}" = "Inserted 3" ]
+    [ "${lines[2]}" = "Inserted 2" ]
+    [ "${lines[3]}" = "Conflicting insert" ]
+
+    run dolt status
+    [[ "$output" =~ "nothing to commit, working tree clean" ]] || false
+}
+
+@test "rebase: conflicts, abort" {
+    dolt checkout main
+    dolt sql -q "INSERT INTO test VALUES (3, 30)"
+    dolt add -A
+    dolt commit -m "Conflicting insert"
+    dolt checkout feature
+    head=$(dolt sql -q "SELECT hashof('feature')" -r csv | tail -n 1)
+
+    run dolt rebase main
+    [ "$status" -eq "1" ]
+    [[ "$output" =~ "Inserted 3" ]] || false
+
+    run dolt merge --abort
+    [ "$status" -eq "1" ]
+    [[ "$output" =~ "dolt rebase --abort" ]] || false
+
+    run dolt rebase main
+    [ "$status" -eq "1" ]
+    [[ "$output" =~ "already in progress" ]] || false
+
+    dolt rebase --abort
+    [ "$(dolt sql -q "SELECT hashof('feature')" -r csv | tail -n 1)" = "$head" ]
+    run dolt sql -q "SELECT * FROM test" -r=csv
+    [[ ! "$output" =~ "10,10" ]] || false
+    run dolt status
+    [[ "$output" =~ "nothing to commit, working tree clean" ]] || false
+
+    run dolt rebase --abort
+    [ "$status" -eq "1" ]
+    [[ "$output" =~ "no rebase in progress" ]] || false
+}
+
+@test "rebase: plan file with squash and drop" {
+    dolt sql -q "INSERT INTO test VALUES (4, 4)"
+    dolt add -A
+    dolt commit -m "Inserted 4"
+    c2=$(dolt sql -q "SELECT hashof('feature~2')" -r csv | tail -n 1)
+    c3=$(dolt sql -q "SELECT hashof('feature~1')" -r csv | tail -n 1)
+    c4=$(dolt sql -q "SELECT hashof('feature')" -r csv | tail -n 1)
+    cat > plan.txt <<PLAN
+# comments and blank lines are ignored
+
+pick $c2 Inserted 2
+s $c3
+drop $c4
+PLAN
+
+    run dolt rebase --plan plan.txt main
+    [ "$status" -eq "0" ]
+
+    run dolt sql -q "SELECT * FROM test ORDER BY pk" -r=csv
+    [[ "$output" =~ "2,2" ]] || false
+    [[ "$output" =~ "3,3" ]] || false
+    [[ ! "$output" =~ "4,4" ]] || false
+    [[ "$output" =~ "10,10" ]] || false
+
+    run dolt sql -q "SELECT count(*) FROM dolt_log" -r=csv
+    [[ "$output" =~ "4" ]] || false
+    run dolt log -n 1
+    [[ "$output" =~ "Inserted 2" ]] || false
+    [[ "$output" =~ "Inserted 3" ]] || false
+}
+
+@test "rebase: invalid plan files" {
+    c2=$(dolt sql -q "SELECT hashof('feature~1')" -r csv | tail -n 1)
+    echo "squash $c2" > plan.txt
+    run dolt rebase --plan plan.txt main
+    [ "$status" -eq "1" ]
+    [[ "$output" =~ "cannot squash without a previous commit" ]] || false
+
+    echo "edit $c2" > plan.txt
+    run dolt rebase --plan plan.txt main
+    [ "$status" -eq "1" ]
+    [[ "$output" =~ "unknown action" ]] || false
+
+    run dolt rebase --plan missing.txt main
+    [ "$status" -eq "1" ]
+    [[ "$output" =~ "could not read plan file" ]] || false
+}
+
+@test "rebase: rebase state survives gc" {
+    dolt checkout main
+    dolt sql -q "INSERT INTO test VALUES (2, 20)"
+    dolt add -A
+    dolt commit -m "Conflicting insert"
+    dolt checkout feature
+
+    run dolt rebase main
+    [ "$status" -eq "1" ]
+    dolt gc
+    dolt conflicts resolve --ours test
+    dolt add test
+    dolt rebase --continue
+    run dolt sql -q "SELECT * FROM test WHERE pk = 2" -r=csv
+    [[ "$output" =~ "2,20" ]] || false
+    run dolt sql -q "SELECT message FROM dolt_log" -r=csv
+    [ "${lines[1]}" = "Inserted 3" ]
+    [ "${lines[2]}" = "Conflicting insert" ]
+}
@@ -0,0 +1,130 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+    dolt sql -q "CREATE TABLE test(pk BIGINT PRIMARY KEY, v1 BIGINT)"
+    dolt add -A
+    dolt commit -m "Created table"
+}
+
+teardown() {
+    assert_feature_version
+    teardown_common
+}
+
+get_head_commit() {
+    dolt log -n 1 | grep -m 1 commit | cut -c 15-46
+}
+
+@test "reflog: commits and resets are recorded for the current branch" {
+    dolt sql -q "INSERT INTO test VALUES (1, 1)"
+    dolt commit -am "Added a row"
+    lost=$(get_head_commit)
+    dolt reset --hard HEAD~1
+
+    run dolt reflog
+    [ "$status" -eq "0" ]
+    [[ "${lines[0]}" =~ "main@{0}: dolt reset --hard HEAD~1" ]] || false
+    [[ "${lines[1]}" =~ "$lost main@{1}: dolt commit -am \"Added a row\"" ]] || false
+    [[ "${lines[2]}" =~ "main@{2}: dolt commit -m \"Created table\"" ]] || false
+    [[ "${lines[3]}" =~ "main@{3}: dolt init" ]] || false
+    [ "${#lines[@]}" -eq 4 ]
+
+    dolt branch recovered $lost
+    run dolt sql -q "SELECT * FROM test AS OF 'recovered'" -r=csv
+    [[ "$output" =~ "1,1" ]] || false
+}
+
+@test "reflog: branches and tags, including deleted ones" {
+    dolt branch feature
+    dolt tag v1
+    dolt branch -d feature
+
+    run dolt reflog feature
+    [ "$status" -eq "0" ]
+    [[ "${lines[0]}" =~ "feature@{0}: dolt branch -d feature (deleted)" ]] || false
+    [[ "${lines[1]}" =~ "feature@{1}: dolt branch feature" ]] || false
+    [ "${#lines[@]}" -eq 2 ]
+
+    run dolt reflog v1
+    [ "$status" -eq "0" ]
+    [[ "${lines[0]}" =~ "refs/tags/v1@{0}: dolt tag v1" ]] || false
+    [ "${#lines[@]}" -eq 1 ]
+
+    run dolt reflog nonexistent
+    [ "$status" -eq "1" ]
+    [[ "$output" =~ "unknown ref 'nonexistent'" ]] || false
+}
+
+@test "reflog: working set updates are recorded" {
+    dolt sql -q "INSERT INTO test VALUES (1, 1)"
+
+    run dolt reflog workingSets/heads/main
+    [ "$status" -eq "0" ]
+    [[ "${lines[0]}" =~ "workingSets/heads/main@{0}: dolt sql -q \"INSERT INTO test VALUES (1, 1)\"" ]] || false
+
+    run dolt reflog --all
+    [ "$status" -eq "0" ]
+    [[ "$output" =~ "workingSets/heads/main@{0}" ]] || false
+    [[ "$output" =~ "main@{0}" ]] || false
+}
+
+@test "reflog: long commands are truncated" {
+    message=$(printf 'x%.0s' {1..300})
+    dolt commit --allow-empty -m "$message"
+
+    run dolt sql -q "SELECT length(command), command FROM dolt_reflog WHERE ref = 'refs/heads/main' AND ref_index = 0" -r=csv
+    [ "$status" -eq "0" ]
+    [[ "${lines[1]}" =~ "256,dolt commit --allow-empty -m xxx" ]] || false
+    [[ "${lines[1]}" =~ "x..." ]] || false
+}
+
+@test "reflog: dolt_reflog system table" {
+    first=$(get_head_commit)
+    dolt sql -q "INSERT INTO test VALUES (1, 1)"
+    dolt commit -am "Added a row"
+    second=$(get_head_commit)
+
+    run dolt sql -q "SELECT ref_index, old_hash, new_hash, user FROM dolt_reflog WHERE ref = 'refs/heads/main' ORDER BY ref_index" -r=csv
+    [ "$status" -eq "0" ]
+    [[ "${lines[1]}" =~ "0,$first,$second,$(current_dolt_user_name) <$(current_dolt_user_email)>" ]] || false
+    [[ "${lines[3]}" =~ "2,," ]] || false
+    [ "${#lines[@]}" -eq 4 ]
+
+    dolt sql -q "SELECT DOLT_COMMIT('--allow-empty', '-m', 'empty')"
+    run dolt sql -q "SELECT command FROM dolt_reflog WHERE ref = 'refs/heads/main' AND ref_index = 0" -r=csv
+    [ "$status" -eq "0" ]
+    [[ "$output" =~ "SELECT DOLT_COMMIT('--allow-empty', '-m', 'empty')" ]] || false
+}
+
+@test "reflog: gc keeps values referenced by the reflog" {
+    dolt sql -q "INSERT INTO test VALUES (1, 1)"
+    dolt commit -am "Added a row"
+    lost=$(get_head_commit)
+    dolt reset --hard HEAD~1
+
+    dolt gc
+
+    dolt branch recovered $lost
+    run dolt sql -q "SELECT * FROM test AS OF 'recovered'" -r=csv
+    [ "$status" -eq "0" ]
+    [[ "$output" =~ "1,1" ]] || false
+}
+
+@test "reflog: gc expires old entries" {
+    dolt sql -q "INSERT INTO test VALUES (1, 1)"
+    dolt commit -am "Added a row"
+    lost=$(get_head_commit)
+    dolt reset --hard HEAD~1
+
+    dolt config --local --add reflog.expiredays 0
+    dolt gc
+
+    run dolt reflog --all
+    [ "$status" -eq "0" ]
+    [ "$output" = "" ]
+
+    run dolt branch recovered $lost
+    [ "$status" -ne "0" ]
+}
@@ -175,3 +175,242 @@ SQL
     [ ! -d test-repo ]
     cd ..
 }
+
+@test "remotes-file-system: shallow clone with --depth" {
+    dolt sql -q "CREATE TABLE test (pk BIGINT PRIMARY KEY, c1 BIGINT)"
+    dolt add test
+    dolt commit -m "create table"
+    for i in 1 2 3 4; do
+        dolt sql -q "INSERT INTO test VALUES ($i, $i)"
+        dolt commit -am "insert $i"
+    done
+    dolt checkout -b other
+    dolt sql -q "INSERT INTO test VALUES (10, 10)"
+    dolt commit -am "insert 10"
+    dolt checkout main
+
+    mkdir remotedir
+    dolt remote add origin file://remotedir
+    dolt push origin main
+    dolt push origin other
+
+    cd dolt-repo-clones
+    dolt clone --depth 2 file://../remotedir test-repo
+    cd test-repo
+
+    # only the cloned branch is fetched
+    run dolt branch -a
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "* main" ]] || false
+    [[ "$output" =~ "remotes/origin/main" ]] || false
+    [[ ! "$output" =~ "other" ]] || false
+
+    # the history ends at the grafted boundary
+    run dolt log
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "insert 4" ]] || false
+    [[ "$output" =~ "insert 3" ]] || false
+    [[ ! "$output" =~ "insert 2" ]] || false
+    [[ "$output" =~ "(grafted)" ]] || false
+    [ -f .dolt/shallow ]
+
+    run dolt log HEAD~2
+    [ "$status" -ne 0 ]
+
+    # rows which have not changed since the boundary are blamed on it
+    run dolt blame test
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "insert 3" ]] || false
+    [[ "$output" =~ "insert 4" ]] || false
+    [[ ! "$output" =~ "insert 1" ]] || false
+
+    run dolt fsck
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "no damage found" ]] || false
+
+    # commits and merges work on top of the shallow history
+    dolt checkout -b feature
+    dolt sql -q "INSERT INTO test VALUES (5, 5)"
+    dolt commit -am "insert 5"
+    run dolt merge-base main feature
+    [ "$status" -eq 0 ]
+    main_hash=$(dolt log -n 1 main | head -n 1 | awk '{print $2}')
+    [[ "$output" =~ "$main_hash" ]] || false
+
+    dolt gc
+    run dolt fsck
+    [ "$status" -eq 0 ]
+
+    # more history is fetched with --deepen
+    dolt fetch --deepen 2
+    run dolt log main
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "insert 1" ]] || false
+    [[ ! "$output" =~ "create table" ]] || false
+
+    dolt fetch --deepen 5
+    run dolt log main
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "create table" ]] || false
+    [[ ! "$output" =~ "(grafted)" ]] || false
+    [ ! -f .dolt/shallow ]
+
+    run dolt fsck
+    [ "$status" -eq 0 ]
+}
+
+@test "remotes-file-system: fetch into a shallow clone" {
+    dolt sql -q "CREATE TABLE test (pk BIGINT PRIMARY KEY, c1 BIGINT)"
+    dolt add test
+    dolt commit -m "create table"
+    dolt sql -q "INSERT INTO test VALUES (1, 1)"
+    dolt commit -am "insert 1"
+
+    mkdir remotedir
+    dolt remote add origin file://remotedir
+    dolt push origin main
+
+    cd dolt-repo-clones
+    dolt clone --depth 1 file://../remotedir test-repo
+
+    cd ../
+    dolt sql -q "INSERT INTO test VALUES (2, 2)"
+    dolt commit -am "insert 2"
+    dolt push origin main
+
+    cd dolt-repo-clones/test-repo
+    dolt pull
+    run dolt log
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "insert 2" ]] || false
+    [[ "$output" =~ "insert 1" ]] || false
+    [[ ! "$output" =~ "create table" ]] || false
+
+    run dolt sql -q "SELECT count(*) FROM test" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "2" ]] || false
+
+    run dolt fsck
+    [ "$status" -eq 0 ]
+}
+
+@test "remotes-file-system: clone --single-branch" {
+    dolt sql -q "CREATE TABLE test (pk BIGINT PRIMARY KEY)"
+    dolt add test
+    dolt commit -m "create table"
+    dolt branch other
+
+    mkdir remotedir
+    dolt remote add origin file://remotedir
+    dolt push origin main
+    dolt push origin other
+
+    cd dolt-repo-clones
+    dolt clone --single-branch --branch other file://../remotedir test-repo
+    cd test-repo
+
+    run dolt branch -a
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "* other" ]] || false
+    [[ "$output" =~ "remotes/origin/other" ]] || false
+    [[ ! "$output" =~ "main" ]] || false
+    [ ! -f .dolt/shallow ]
+
+    run dolt log
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Initialize data repository" ]] || false
+}
+
+@test "remotes-file-system: partial clone with --tables" {
+    dolt sql -q "CREATE TABLE a (pk BIGINT PRIMARY KEY, c1 BIGINT)"
+    dolt sql -q "CREATE TABLE b (pk BIGINT PRIMARY KEY, c1 VARCHAR(20), INDEX c1_idx (c1))"
+    dolt sql -q "INSERT INTO a VALUES (1, 1), (2, 2)"
+    dolt sql -q "INSERT INTO b VALUES (1, 'x'), (2, 'y')"
+    dolt add .
+    dolt commit -m "create tables"
+    dolt sql -q "INSERT INTO a VALUES (3, 3)"
+    dolt sql -q "INSERT INTO b VALUES (3, 'z')"
+    dolt commit -am "insert 3"
+    dolt branch other
+    dolt tag v1
+
+    mkdir remotedir
+    dolt remote add origin file://remotedir
+    dolt push origin main
+    dolt push origin other
+    dolt push origin v1
+
+    cd dolt-repo-clones
+    run dolt clone --tables nope file://../remotedir test-repo
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "table 'nope' not found" ]] || false
+    [ ! -d test-repo ]
+
+    dolt clone --tables a file://../remotedir test-repo
+    cd test-repo
+    [ -f .dolt/partial ]
+
+    run dolt branch -a
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "remotes/origin/other" ]] || false
+    run dolt tag
+    [[ "$output" =~ "v1" ]] || false
+
+    run dolt status
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "nothing to commit, working tree clean" ]] || false
+
+    # the row data of b is not held, and cannot be read without the remote
+    mv ../../remotedir ../../remotedir.bak
+    run dolt sql -q "SELECT count(*) FROM a" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "3" ]] || false
+    run dolt sql -q "SELECT count(*) FROM b"
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "not held by this partial clone" ]] || false
+    mv ../../remotedir.bak ../../remotedir
+
+    # it is fetched from the remote when it is read
+    run dolt sql -q "SELECT c1 FROM b WHERE pk = 2" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "y" ]] || false
+    run dolt sql -q "SELECT pk FROM b WHERE c1 = 'z'" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "3" ]] || false
+
+    run dolt diff HEAD~1 HEAD
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "diff --dolt a/b b/b" ]] || false
+
+    # commits which only move the row data of b can be made and pushed
+    dolt sql -q "RENAME TABLE b TO b2"
+    dolt sql -q "INSERT INTO a VALUES (4, 4)"
+    dolt commit -am "rename b"
+    dolt gc
+    run dolt fsck
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "no damage found" ]] || false
+    dolt push origin main
+
+    # later pulls only retrieve the row data of a
+    cd ../../
+    dolt pull origin
+    dolt sql -q "INSERT INTO a VALUES (5, 5)"
+    dolt sql -q "INSERT INTO b2 VALUES (5, 'w')"
+    dolt commit -am "insert 5"
+    dolt push origin main
+
+    cd dolt-repo-clones/test-repo
+    dolt pull
+    mv ../../remotedir ../../remotedir.bak
+    run dolt sql -q "SELECT count(*) FROM a" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "5" ]] || false
+    run dolt sql -q "SELECT count(*) FROM b2"
+    [ "$status" -ne 0 ]
+    mv ../../remotedir.bak ../../remotedir
+
+    run dolt sql -q "SELECT c1 FROM b2 WHERE pk = 5" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "w" ]] || false
+}
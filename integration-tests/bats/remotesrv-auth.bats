@@ -0,0 +1,133 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+remotesrv_pid=
+setup() {
+    setup_common
+    cd $BATS_TMPDIR
+    mkdir remotes-auth-$$
+
+    pubkey=`dolt creds new | grep 'pub key:' | awk '{print $3}'`
+    dolt creds use $pubkey
+    cat > remotes-auth-$$/creds.json <<EOF
+{
+  "anonymous": {"public/*": "read"},
+  "users": [
+    {"name": "ci", "token": "s3cr3t", "repos": {"test-org/*": "write", "public/*": "write"}},
+    {"name": "reader", "public_key": "$pubkey", "repos": {"test-org/test-repo": "read"}}
+  ]
+}
+EOF
+
+    echo remotesrv log available here $BATS_TMPDIR/remotes-auth-$$/remotesrv.log
+    remotesrv --http-port 1236 --grpc-port 50053 --dir ./remotes-auth-$$ --auth-file ./remotes-auth-$$/creds.json --auto-create-repos &> ./remotes-auth-$$/remotesrv.log 3>&- &
+    remotesrv_pid=$!
+    export DOLT_REMOTE_TOKEN_HOST=localhost:50053
+    export DOLT_REMOTE_TOKEN_INSECURE=true
+    cd dolt-repo-$$
+    mkdir "dolt-repo-clones"
+
+    dolt sql -q "CREATE TABLE test (pk int PRIMARY KEY)"
+    dolt sql -q "INSERT INTO test VALUES (1), (2)"
+    dolt add test
+    dolt commit -m "test table"
+}
+
+teardown() {
+    teardown_common
+    kill $remotesrv_pid
+    rm -rf $BATS_TMPDIR/remotes-auth-$$
+}
+
+@test "remotesrv-auth: push requires write access" {
+    dolt remote add origin http://localhost:50053/test-org/test-repo
+    # create the repository, as it is not created on first access by a caller without write access
+    curl -s -X PUT -H "Authorization: Bearer s3cr3t" http://localhost:1236/api/v1alpha1/repos/test-org/test-repo
+
+    run dolt push origin main
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "PermissionDenied" ]] || false
+    [[ "$output" =~ "user reader does not have write access to test-org/test-repo" ]] || false
+
+    DOLT_REMOTE_TOKEN=wrong run dolt push origin main
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "Unauthenticated" ]] || false
+
+    DOLT_REMOTE_TOKEN=s3cr3t dolt push origin main
+}
+
+@test "remotesrv-auth: the token is only sent to its host, and only over TLS unless allowed" {
+    dolt remote add origin http://localhost:50053/test-org/test-repo
+    # create the repository, as it is not created on first access by a caller without write access
+    curl -s -X PUT -H "Authorization: Bearer s3cr3t" http://localhost:1236/api/v1alpha1/repos/test-org/test-repo
+
+    # the token is set for another host, so the dolt credentials are sent instead
+    DOLT_REMOTE_TOKEN=s3cr3t DOLT_REMOTE_TOKEN_HOST=remotes.example.com run dolt push origin main
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "user reader does not have write access to test-org/test-repo" ]] || false
+
+    DOLT_REMOTE_TOKEN=s3cr3t DOLT_REMOTE_TOKEN_HOST= run dolt push origin main
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "DOLT_REMOTE_TOKEN_HOST" ]] || false
+
+    DOLT_REMOTE_TOKEN=s3cr3t DOLT_REMOTE_TOKEN_INSECURE= run dolt push origin main
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "only sent over TLS" ]] || false
+
+    DOLT_REMOTE_TOKEN=s3cr3t dolt push origin main
+}
+
+@test "remotesrv-auth: clone with dolt credentials" {
+    dolt remote add origin http://localhost:50053/test-org/test-repo
+    DOLT_REMOTE_TOKEN=s3cr3t dolt push origin main
+
+    cd "dolt-repo-clones"
+    dolt clone http://localhost:50053/test-org/test-repo
+    cd test-repo
+    run dolt sql -q "SELECT count(*) FROM test" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "2" ]] || false
+
+    # the credentials grant read access to test-repo only
+    cd ../..
+    dolt remote add other http://localhost:50053/test-org/other-repo
+    DOLT_REMOTE_TOKEN=s3cr3t dolt push other main
+    cd "dolt-repo-clones"
+    run dolt clone http://localhost:50053/test-org/other-repo
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "PermissionDenied" ]] || false
+}
+
+@test "remotesrv-auth: anonymous access" {
+    dolt remote add origin http://localhost:50053/public/test-repo
+    DOLT_REMOTE_TOKEN=s3cr3t dolt push origin main
+
+    dolt config --global --unset user.creds
+    cd "dolt-repo-clones"
+    dolt clone http://localhost:50053/public/test-repo
+    run dolt clone http://localhost:50053/test-org/test-repo
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "Unauthenticated" ]] || false
+
+    cd test-repo
+    dolt sql -q "INSERT INTO test VALUES (3)"
+    dolt commit -am "anonymous commit"
+    run dolt push origin main
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "Unauthenticated" ]] || false
+}
+
+@test "remotesrv-auth: table files require a signed url or a token" {
+    dolt remote add origin http://localhost:50053/test-org/test-repo
+    DOLT_REMOTE_TOKEN=s3cr3t dolt push origin main
+
+    tablefile=`ls $BATS_TMPDIR/remotes-auth-$$/test-org/test-repo | grep -E "^[0-9a-v]{32}$" | head -n 1`
+    run curl -s -o /dev/null -w "%{http_code}" http://localhost:1236/test-org/test-repo/$tablefile
+    [ "$output" = "401" ]
+    run curl -s -o /dev/null -w "%{http_code}" -H "Authorization: Bearer s3cr3t" http://localhost:1236/test-org/test-repo/$tablefile
+    [ "$output" = "200" ]
+    run curl -s -o /dev/null -w "%{http_code}" -X POST -H "Authorization: Bearer s3cr3t" http://localhost:1236/test-org/test-repo/$tablefile?Expires=1\&Signature=abc
+    [ "$output" = "400" ]
+    run curl -s -o /dev/null -w "%{http_code}" -X POST http://localhost:1236/test-org/test-repo/$tablefile
+    [ "$output" = "401" ]
+}
@@ -0,0 +1,123 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+remotesrv_pid=
+setup() {
+    setup_common
+    mkdir -p $BATS_TMPDIR/remotesrv-$$
+
+    dolt sql -q "CREATE TABLE test (pk int PRIMARY KEY)"
+    dolt sql -q "INSERT INTO test VALUES (1), (2)"
+    dolt add test
+    dolt commit -m "test table"
+    mkdir "dolt-repo-clones"
+}
+
+teardown() {
+    teardown_common
+    if [ -n "$remotesrv_pid" ]; then
+        kill $remotesrv_pid
+        wait $remotesrv_pid || true
+    fi
+    rm -rf $BATS_TMPDIR/remotesrv-$$
+}
+
+start_remotesrv() {
+    dolt remotesrv --http-port 1237 --grpc-port 50054 --dir $BATS_TMPDIR/remotesrv-$$/data "$@" &> $BATS_TMPDIR/remotesrv-$$/remotesrv.log 3>&- &
+    remotesrv_pid=$!
+    for i in `seq 1 50`; do
+        if curl -s -o /dev/null http://localhost:1237/api/v1alpha1/repos; then
+            return 0
+        fi
+        sleep 0.1
+    done
+    cat $BATS_TMPDIR/remotesrv-$$/remotesrv.log
+    return 1
+}
+
+@test "remotesrv: push, clone and list repositories" {
+    start_remotesrv --auto-create-repos
+    dolt remote add origin http://localhost:50054/test-org/test-repo
+    dolt push origin main
+
+    cd "dolt-repo-clones"
+    dolt clone http://localhost:50054/test-org/test-repo
+    cd test-repo
+    run dolt sql -q "SELECT count(*) FROM test" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "2" ]] || false
+
+    run curl -s http://localhost:1237/api/v1alpha1/repos
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ '{"org":"test-org","name":"test-repo"}' ]] || false
+
+    run curl -s -o /dev/null -w "%{http_code}" -X PUT http://localhost:1237/api/v1alpha1/repos/test-org/new-repo
+    [ "$output" = "201" ]
+    run curl -s -o /dev/null -w "%{http_code}" -X PUT http://localhost:1237/api/v1alpha1/repos/test-org/new-repo
+    [ "$output" = "409" ]
+}
+
+@test "remotesrv: read only server rejects pushes" {
+    start_remotesrv --auto-create-repos
+    dolt remote add origin http://localhost:50054/test-org/test-repo
+    dolt push origin main
+    kill $remotesrv_pid
+    wait $remotesrv_pid || true
+
+    start_remotesrv --read-only
+    cd "dolt-repo-clones"
+    dolt clone http://localhost:50054/test-org/test-repo
+
+    cd test-repo
+    dolt sql -q "INSERT INTO test VALUES (3)"
+    dolt commit -am "new row"
+    run dolt push origin main
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "the server is read only" ]] || false
+
+    run curl -s -o /dev/null -w "%{http_code}" -X PUT http://localhost:1237/api/v1alpha1/repos/test-org/new-repo
+    [ "$output" = "403" ]
+}
+
+@test "remotesrv: repositories are not created on first access by default" {
+    start_remotesrv
+
+    dolt remote add origin http://localhost:50054/test-org/test-repo
+    run dolt push origin main
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "NotFound" ]] || false
+
+    run curl -s -o /dev/null -w "%{http_code}" -X PUT http://localhost:1237/api/v1alpha1/repos/test-org/test-repo
+    [ "$output" = "201" ]
+    dolt push origin main
+}
+
+@test "remotesrv: config file" {
+    cat > $BATS_TMPDIR/remotesrv-$$/config.yaml <<EOF
+auto_create_repos: false
+quotas:
+  max_repo_size: 10
+EOF
+    start_remotesrv --config $BATS_TMPDIR/remotesrv-$$/config.yaml
+
+    dolt remote add origin http://localhost:50054/test-org/test-repo
+    run dolt push origin main
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "NotFound" ]] || false
+
+    curl -s -X PUT http://localhost:1237/api/v1alpha1/repos/test-org/test-repo
+    run dolt push origin main
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "repository quota exceeded" ]] || false
+}
+
+@test "remotesrv: invalid config" {
+    echo "unknown_field: true" > $BATS_TMPDIR/remotesrv-$$/config.yaml
+    run dolt remotesrv --config $BATS_TMPDIR/remotesrv-$$/config.yaml
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "failed to parse config file" ]] || false
+
+    run dolt remotesrv --tls-cert cert.pem
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "tls requires both a cert and a key" ]] || false
+}
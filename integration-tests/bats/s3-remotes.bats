@@ -0,0 +1,69 @@
+# Smoke tests verifying remotes on S3 compatible object stores, such as a local MinIO, work as advertised.
+
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+}
+
+teardown() {
+    teardown_common
+}
+
+skip_if_no_s3_tests() {
+    if [ -z "$DOLT_BATS_S3_ENDPOINT" -o -z "$DOLT_BATS_S3_BUCKET" ]; then
+      skip "skipping s3 tests; set DOLT_BATS_S3_ENDPOINT and DOLT_BATS_S3_BUCKET, along with AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, to run"
+    fi
+}
+
+@test "s3-remotes: can add remote with s3 url" {
+    dolt remote add --s3-endpoint http://localhost:9000 --aws-creds-type env origin 's3://s3_bucket/path/repo_name'
+    run dolt remote -v
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "s3://s3_bucket/path/repo_name" ]] || false
+    [[ "$output" =~ "http://localhost:9000" ]] || false
+}
+
+@test "s3-remotes: s3-endpoint is only valid for s3 urls" {
+    run dolt remote add --s3-endpoint http://localhost:9000 origin 'aws://[dynamo_db_table:s3_bucket]/repo_name'
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "only valid for s3 remotes" ]] || false
+
+    run dolt remote add --s3-endpoint http://localhost:9000 origin 'gs://gcs_bucket/repo_name'
+    [ "$status" -ne 0 ]
+}
+
+@test "s3-remotes: can push, clone and pull with an s3 remote" {
+    skip_if_no_s3_tests
+    random_repo=`openssl rand -hex 32`
+    remote_url='s3://'"$DOLT_BATS_S3_BUCKET"'/'"$random_repo"
+
+    dolt sql -q "CREATE TABLE test (pk INT PRIMARY KEY, c1 INT)"
+    dolt sql -q "INSERT INTO test VALUES (1, 1)"
+    dolt add -A
+    dolt commit -m "created table"
+    dolt remote add --s3-endpoint "$DOLT_BATS_S3_ENDPOINT" --aws-creds-type env origin "$remote_url"
+    dolt push origin main
+
+    cd "$BATS_TMPDIR"
+    rm -rf "s3-clone-$$"
+    dolt clone --s3-endpoint "$DOLT_BATS_S3_ENDPOINT" --aws-creds-type env "$remote_url" "s3-clone-$$"
+    cd "s3-clone-$$"
+    run dolt sql -q "SELECT c1 FROM test WHERE pk = 1" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "1" ]] || false
+
+    cd "$BATS_TMPDIR/dolt-repo-$$"
+    dolt sql -q "INSERT INTO test VALUES (2, 2)"
+    dolt commit -am "added a row"
+    dolt push origin main
+
+    cd "$BATS_TMPDIR/s3-clone-$$"
+    dolt pull
+    run dolt sql -q "SELECT COUNT(*) FROM test" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "2" ]] || false
+
+    cd "$BATS_TMPDIR"
+    rm -rf "s3-clone-$$"
+}
@@ -237,3 +237,114 @@ SQL
   [ "$status" -eq 0 ]
   [[ "$output" =~ "$EXPECTED" ]] || false
 }
+
+@test "sql-conflicts: resolve conflicts by updating our columns" {
+  dolt SQL -q "INSERT INTO one_pk (pk1,c1,c2) VALUES (0,0,0),(1,0,0),(2,0,0)"
+  dolt add .
+  dolt commit -m "initial values"
+  dolt branch feature_branch main
+  dolt SQL -q "UPDATE one_pk SET c1=c1+1,c2=1"
+  dolt add .
+  dolt commit -m "changed main"
+  dolt checkout feature_branch
+  dolt SQL -q "UPDATE one_pk SET c1=c1+2,c2=2"
+  dolt SQL -q "DELETE FROM one_pk WHERE pk1=2"
+  dolt add .
+  dolt commit -m "changed feature_branch"
+  dolt checkout main
+  dolt merge feature_branch
+
+  run dolt sql -q "UPDATE dolt_conflicts_one_pk SET their_c1 = 5"
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "column their_c1 of dolt_conflicts_one_pk cannot be updated" ]] || false
+
+  run dolt sql -q "UPDATE dolt_conflicts_one_pk SET our_pk1 = 9"
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "primary key of a row in conflict cannot be changed" ]] || false
+
+  dolt sql  <<SQL
+set autocommit = off;
+set @@dolt_force_transaction_commit = 1;
+UPDATE dolt_conflicts_one_pk SET our_c1 = our_c1 + their_c1 - base_c1, our_c2 = their_c2 WHERE base_pk1 = 0;
+commit;
+SQL
+
+  run dolt sql -r csv -q "SELECT * FROM one_pk ORDER BY pk1"
+  [ "$status" -eq 0 ]
+  [[ "$output" =~ "0,3,2" ]] || false
+  [[ "$output" =~ "1,1,1" ]] || false
+
+  EXPECTED=$( echo -e "table,num_conflicts\none_pk,2")
+  run dolt sql -r csv -q "SELECT * FROM dolt_conflicts"
+  [ "$status" -eq 0 ]
+  [[ "$output" =~ "$EXPECTED" ]] || false
+
+  dolt sql  <<SQL
+set autocommit = off;
+UPDATE dolt_conflicts_one_pk SET our_pk1 = NULL, our_c1 = NULL, our_c2 = NULL WHERE base_pk1 = 2;
+UPDATE dolt_conflicts_one_pk SET our_c1 = 10;
+commit;
+SQL
+
+  run dolt sql -r csv -q "SELECT * FROM one_pk ORDER BY pk1"
+  [ "$status" -eq 0 ]
+  [ "${#lines[@]}" -eq 3 ]
+  [[ "$output" =~ "0,3,2" ]] || false
+  [[ "$output" =~ "1,10,1" ]] || false
+
+  EXPECTED=$( echo -e "table,num_conflicts")
+  run dolt sql -r csv -q "SELECT * FROM dolt_conflicts"
+  [ "$status" -eq 0 ]
+  [[ "$output" =~ "$EXPECTED" ]] || false
+}
+
+@test "sql-conflicts: dolt conflicts resolve --sql" {
+  dolt SQL -q "INSERT INTO one_pk (pk1,c1,c2) VALUES (0,0,0),(1,0,0)"
+  dolt add .
+  dolt commit -m "initial values"
+  dolt branch feature_branch main
+  dolt SQL -q "UPDATE one_pk SET c1=c1+1"
+  dolt add .
+  dolt commit -m "changed main"
+  dolt checkout feature_branch
+  dolt SQL -q "UPDATE one_pk SET c1=c1+2"
+  dolt add .
+  dolt commit -m "changed feature_branch"
+  dolt checkout main
+  dolt merge feature_branch
+
+  run dolt conflicts resolve --sql "their_c1 = 0" one_pk
+  [ "$status" -eq 1 ]
+
+  run dolt conflicts resolve --ours --sql "our_c1 = 0" one_pk
+  [ "$status" -eq 1 ]
+
+  # a table name is quoted, so it cannot end the identifier and add to the query
+  run dolt conflicts resolve --sql "our_c1 = 0" 'one_pk` SET our_c1 = 0; DROP TABLE one_pk; -- '
+  [ "$status" -eq 1 ]
+  run dolt sql -r csv -q "SELECT count(*) FROM one_pk"
+  [ "$status" -eq 0 ]
+  [[ "$output" =~ "2" ]] || false
+
+  dolt conflicts resolve --sql "our_c1 = our_c1 + their_c1 - base_c1" one_pk "base_pk1 = 1"
+
+  run dolt sql -r csv -q "SELECT * FROM one_pk ORDER BY pk1"
+  [ "$status" -eq 0 ]
+  [[ "$output" =~ "0,1,0" ]] || false
+  [[ "$output" =~ "1,3,0" ]] || false
+
+  EXPECTED=$( echo -e "table,num_conflicts\none_pk,1")
+  run dolt sql -r csv -q "SELECT * FROM dolt_conflicts"
+  [ "$status" -eq 0 ]
+  [[ "$output" =~ "$EXPECTED" ]] || false
+
+  dolt conflicts resolve --sql "our_c1 = their_c1" one_pk
+
+  run dolt sql -r csv -q "SELECT * FROM one_pk ORDER BY pk1"
+  [ "$status" -eq 0 ]
+  [[ "$output" =~ "0,2,0" ]] || false
+
+  run dolt status
+  [ "$status" -eq 0 ]
+  [[ "$output" =~ "All conflicts and constraint violations fixed" ]] || false
+}
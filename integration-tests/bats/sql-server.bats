@@ -1469,3 +1469,86 @@ databases:
     run expect $BATS_TEST_DIRNAME/sql-server-mysql.expect $PORT repo1
     [ "$status" -eq 0 ]
 }
+
+@test "sql-server: users and their privileges persist across restarts" {
+    skiponwindows "Has dependencies that are missing on the Jenkins Windows installation."
+
+    start_multi_db_server repo1
+    server_query repo1 1 "CREATE USER 'analyst'@'%'" ""
+    server_query repo1 1 "GRANT SELECT ON *.* TO 'analyst'@'%'" ""
+    stop_sql_server
+
+    [ -f .doltcfg/privileges.json ]
+    run cat .doltcfg/privileges.json
+    [[ "$output" =~ '"user": "analyst"' ]] || false
+    [[ "$output" =~ '"SELECT"' ]] || false
+    # the server's own user is never persisted
+    ! [[ "$output" =~ '"user": "dolt"' ]] || false
+
+    start_multi_db_server repo1
+    SQL_USER=analyst server_query repo1 1 "SELECT 1 AS one" "one\n1"
+    server_query repo1 1 "CREATE TABLE t (pk int PRIMARY KEY)" ""
+    SQL_USER=analyst server_query repo1 1 "INSERT INTO t VALUES (1)" "" "INSERT command denied"
+}
+
+@test "sql-server: branch rules restrict the branches users can write to" {
+    skiponwindows "Has dependencies that are missing on the Jenkins Windows installation."
+
+    cd repo1
+    dolt sql -q "CREATE TABLE t (pk int PRIMARY KEY)"
+    dolt commit -am "add t"
+    dolt branch analysts/q1
+    cd ..
+
+    start_multi_db_server repo1
+    server_query repo1 1 "CREATE USER 'analyst'@'%'" ""
+    server_query repo1 1 "GRANT SELECT, INSERT, UPDATE ON *.* TO 'analyst'@'%'" ""
+    server_query repo1 1 "INSERT INTO dolt_branch_control VALUES ('repo1', 'analysts/%', 'analyst', '%')" ""
+    server_query repo1 1 "SELECT * FROM dolt_branch_control" "database,branch,user,host\nrepo1,analysts/%,analyst,%"
+
+    # analysts can read every branch, but only write to their own
+    SQL_USER=analyst server_query repo1 1 "SELECT COUNT(*) AS c FROM t" "c\n0"
+    SQL_USER=analyst server_query repo1 1 "INSERT INTO t VALUES (1)" "" "not allowed to write to branch 'main'"
+    SQL_USER=analyst server_query "repo1/analysts/q1" 1 "INSERT INTO t VALUES (1)" ""
+    SQL_USER=analyst server_query repo1 1 "SELECT DOLT_CHECKOUT('-b', 'other')" "" "not allowed to write to branch 'other'"
+    SQL_USER=analyst server_query repo1 1 "SELECT DOLT_BRANCH('analysts/q2')" "DOLT_BRANCH('analysts/q2')\n0"
+    SQL_USER=analyst server_query repo1 1 "INSERT INTO dolt_branch_control VALUES ('repo1', '%', 'analyst', '%')" "" "requires the SUPER privilege"
+
+    # the server's user can write everywhere
+    server_query repo1 1 "INSERT INTO t VALUES (2)" ""
+
+    # rules persist across restarts
+    stop_sql_server
+    start_multi_db_server repo1
+    SQL_USER=analyst server_query repo1 1 "INSERT INTO t VALUES (3)" "" "not allowed to write to branch 'main'"
+    server_query repo1 1 "DELETE FROM dolt_branch_control" ""
+    SQL_USER=analyst server_query repo1 1 "INSERT INTO t VALUES (3)" ""
+}
+
+@test "sql-server: branch rules for pushes apply to the local branch" {
+    skiponwindows "Has dependencies that are missing on the Jenkins Windows installation."
+
+    mkdir rem1
+    cd repo1
+    dolt remote add origin file://../rem1
+    dolt sql -q "CREATE TABLE t (pk int PRIMARY KEY)"
+    dolt commit -am "add t"
+    dolt branch analysts/q1
+    cd ..
+
+    start_multi_db_server repo1
+    server_query repo1 1 "CREATE USER 'analyst'@'%'" ""
+    server_query repo1 1 "GRANT SELECT, INSERT, UPDATE ON *.* TO 'analyst'@'%'" ""
+    server_query repo1 1 "INSERT INTO dolt_branch_control VALUES ('repo1', 'analysts/%', 'analyst', '%')" ""
+
+    # the name of the branch on the remote does not matter, only the local branch being pushed
+    SQL_USER=analyst server_query repo1 1 "SELECT DOLT_PUSH('origin', 'main:analysts/q1')" "" "not allowed to write to branch 'main'"
+    SQL_USER=analyst server_query repo1 1 "SELECT DOLT_PUSH('origin', 'analysts/q1:published') AS p" "p\n1"
+
+    stop_sql_server
+    cd repo1
+    run dolt branch -r
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "origin/published" ]] || false
+    [[ ! "$output" =~ "origin/analysts/q1" ]] || false
+}
@@ -234,6 +234,46 @@ SQL
     [[ "$output" =~ "not found" ]] || false
 }
 
+@test "sql: AS OF timestamp queries follow merges" {
+    dolt sql -q "create table t (pk int primary key)"
+    dolt sql -q "insert into t values (1)"
+    dolt add .
+    dolt commit -m "created table" --date "2022-01-01T12:00:00Z"
+    dolt checkout -b feature
+    dolt sql -q "insert into t values (2)"
+    dolt commit -am "inserted 2" --date "2022-01-01T12:30:00Z"
+    dolt checkout main
+    dolt sql -q "insert into t values (3)"
+    dolt commit -am "inserted 3" --date "2022-01-01T12:10:00Z"
+    dolt sql -q "insert into t values (4)"
+    dolt commit -am "inserted 4" --date "2022-01-01T12:20:00Z"
+    dolt merge feature
+    dolt commit -am "merged feature" --date "2022-01-01T13:00:00Z"
+
+    run dolt sql -r csv -q "select group_concat(pk order by pk) as pks from t as of CONVERT('2022-01-01 12:25:00', DATETIME)"
+    [ $status -eq 0 ]
+    [[ "$output" =~ '"1,3,4"' ]] || false
+
+    run dolt sql -r csv -q "select group_concat(pk order by pk) as pks from t as of CONVERT('2022-01-01 12:45:00', DATETIME)"
+    [ $status -eq 0 ]
+    [[ "$output" =~ '"1,2"' ]] || false
+
+    run dolt sql -r csv -q "select count(*) from dolt_history_t as of CONVERT('2022-01-01 12:25:00', DATETIME)"
+    [ $status -eq 0 ]
+    [[ "$output" =~ "6" ]] || false
+
+    run dolt sql -r csv -q "select to_pk, diff_type from dolt_diff_t as of CONVERT('2022-01-01 12:45:00', DATETIME) order by to_pk"
+    [ $status -eq 0 ]
+    [ "${#lines[@]}" -eq 3 ]
+    [[ "$output" =~ "1,added" ]] || false
+    [[ "$output" =~ "2,added" ]] || false
+
+    dolt sql -q "create view history_view as select pk from dolt_history_t"
+    run dolt sql -r csv -q "select count(*) from history_view as of CONVERT('2022-01-01 12:45:00', DATETIME)"
+    [ $status -eq 0 ]
+    [[ "$output" =~ "3" ]] || false
+}
+
 @test "sql: output formats" {
     dolt sql <<SQL
     CREATE TABLE test (
@@ -0,0 +1,157 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+    dolt sql -q "CREATE TABLE test(pk BIGINT PRIMARY KEY, v1 BIGINT)"
+    dolt sql -q "INSERT INTO test VALUES (1, 1)"
+    dolt add -A
+    dolt commit -m "Created table"
+}
+
+teardown() {
+    assert_feature_version
+    teardown_common
+}
+
+@test "stash: stash and pop working changes" {
+    dolt sql -q "INSERT INTO test VALUES (2, 2)"
+    run dolt stash
+    [ "$status" -eq "0" ]
+    [[ "$output" =~ "WIP on main" ]] || false
+
+    run dolt status
+    [[ "$output" =~ "nothing to commit, working tree clean" ]] || false
+    run dolt sql -q "SELECT * FROM test" -r=csv
+    [[ ! "$output" =~ "2,2" ]] || false
+
+    run dolt stash pop
+    [ "$status" -eq "0" ]
+    [[ "$output" =~ "Dropped stash@{0}" ]] || false
+    run dolt sql -q "SELECT * FROM test" -r=csv
+    [[ "$output" =~ "2,2" ]] || false
+
+    run dolt stash list
+    [ "$status" -eq "0" ]
+    [ "$output" = "" ]
+}
+
+@test "stash: staged changes stay staged after pop" {
+    dolt sql -q "INSERT INTO test VALUES (2, 2)"
+    dolt add test
+    dolt sql -q "INSERT INTO test VALUES (3, 3)"
+    dolt stash
+    dolt stash pop
+
+    run dolt diff --cached
+    [[ "$output" =~ "| 2  | 2  |" ]] || false
+    [[ ! "$output" =~ "| 3  | 3  |" ]] || false
+    run dolt diff
+    [[ "$output" =~ "| 3  | 3  |" ]] || false
+}
+
+@test "stash: nothing to stash" {
+    run dolt stash
+    [ "$status" -eq "0" ]
+    [[ "$output" =~ "No local changes to save" ]] || false
+    run dolt stash list
+    [ "$output" = "" ]
+}
+
+@test "stash: untracked tables are left in place unless -u is given" {
+    dolt sql -q "CREATE TABLE untracked(pk BIGINT PRIMARY KEY)"
+    dolt sql -q "INSERT INTO test VALUES (2, 2)"
+    dolt stash
+    run dolt ls
+    [[ "$output" =~ "untracked" ]] || false
+
+    dolt stash pop
+    dolt stash -u
+    run dolt ls
+    [[ ! "$output" =~ "untracked" ]] || false
+    dolt stash pop
+    run dolt ls
+    [[ "$output" =~ "untracked" ]] || false
+}
+
+@test "stash: list, apply and drop" {
+    dolt sql -q "INSERT INTO test VALUES (2, 2)"
+    dolt stash -m "first"
+    dolt sql -q "INSERT INTO test VALUES (3, 3)"
+    dolt stash -m "second"
+
+    run dolt stash list
+    [ "$status" -eq "0" ]
+    [ "${lines[0]}" = "stash@{0}: On main: second" ]
+    [ "${lines[1]}" = "stash@{1}: On main: first" ]
+
+    dolt stash apply stash@{1}
+    run dolt sql -q "SELECT * FROM test" -r=csv
+    [[ "$output" =~ "2,2" ]] || false
+    [[ ! "$output" =~ "3,3" ]] || false
+    run dolt stash list
+    [ "${#lines[@]}" -eq 2 ]
+
+    dolt stash drop stash@{1}
+    run dolt stash list
+    [ "${#lines[@]}" -eq 1 ]
+    [[ "$output" =~ "second" ]] || false
+
+    dolt stash clear
+    run dolt stash list
+    [ "$output" = "" ]
+}
+
+@test "stash: pop with conflicts keeps the stash" {
+    dolt sql -q "UPDATE test SET v1 = 2 WHERE pk = 1"
+    dolt stash
+    dolt sql -q "UPDATE test SET v1 = 3 WHERE pk = 1"
+    dolt add -A
+    dolt commit -m "conflicting change"
+
+    run dolt stash pop
+    [ "$status" -eq "1" ]
+    [[ "$output" =~ "CONFLICT" ]] || false
+    [[ "$output" =~ "stash entry is kept" ]] || false
+
+    run dolt sql -q "SELECT * FROM dolt_conflicts" -r=csv
+    [[ "$output" =~ "test,1" ]] || false
+    run dolt stash list
+    [ "${#lines[@]}" -eq 1 ]
+}
+
+@test "stash: invalid stash names" {
+    run dolt stash pop
+    [ "$status" -eq "1" ]
+    [[ "$output" =~ "no stash entries found" ]] || false
+
+    dolt sql -q "INSERT INTO test VALUES (2, 2)"
+    dolt stash
+    run dolt stash drop stash@{5}
+    [ "$status" -eq "1" ]
+    [[ "$output" =~ "stash not found" ]] || false
+    run dolt stash drop foo
+    [ "$status" -eq "1" ]
+    [[ "$output" =~ "not a valid stash name" ]] || false
+}
+
+@test "stash: stashes survive gc" {
+    dolt sql -q "INSERT INTO test VALUES (2, 2)"
+    dolt stash
+    dolt gc
+    run dolt stash pop
+    [ "$status" -eq "0" ]
+    run dolt sql -q "SELECT * FROM test" -r=csv
+    [[ "$output" =~ "2,2" ]] || false
+}
+
+@test "stash: dolt_stashes system table" {
+    dolt sql -q "INSERT INTO test VALUES (2, 2)"
+    dolt stash -m "my changes"
+    run dolt sql -q "SELECT name, message FROM dolt_stashes" -r=csv
+    [ "$status" -eq "0" ]
+    [[ "$output" =~ "stash@{0},On main: my changes" ]] || false
+
+    run dolt sql -q "SELECT count(*) FROM dolt_stashes s JOIN dolt_log l ON s.head_commit = l.commit_hash" -r=csv
+    [[ "$output" =~ "1" ]] || false
+}
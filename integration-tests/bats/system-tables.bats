@@ -9,7 +9,7 @@ setup() {
     mkdir remotes-$$
     mkdir remotes-$$/empty
     echo remotesrv log available here $BATS_TMPDIR/remotes-$$/remotesrv.log
-    remotesrv --http-port 1234 --dir ./remotes-$$ &> ./remotes-$$/remotesrv.log 3>&- &
+    remotesrv --http-port 1234 --dir ./remotes-$$ --auto-create-repos &> ./remotes-$$/remotesrv.log 3>&- &
     remotesrv_pid=$!
     cd dolt-repo-$$
     mkdir "dolt-repo-clones"
@@ -34,6 +34,7 @@ teardown() {
     [[ "$output" =~ "dolt_remotes" ]] || false
     [[ "$output" =~ "dolt_query_catalog" ]] || false
     [[ "$output" =~ "dolt_status" ]] || false
+    [[ "$output" =~ "dolt_column_diff" ]] || false
     [[ ! "$output" =~ " test" ]] || false  # spaces are impt!
     run dolt ls --all
     [ $status -eq 0 ]
@@ -53,6 +54,7 @@ teardown() {
     [[ "$output" =~ "dolt_history_test" ]] || false
     [[ "$output" =~ "dolt_diff_test" ]] || false
     [[ "$output" =~ "dolt_commit_diff_test" ]] || false
+    [[ "$output" =~ "dolt_blame_test" ]] || false
     run dolt ls --all
     [ $status -eq 0 ]
     [[ "$output" =~ "dolt_history_test" ]] || false
@@ -319,6 +321,87 @@ teardown() {
     [ ! "$status" -eq 0 ]
 }
 
+@test "system-tables: query dolt_commit_diff_ with revision specs and primary key ranges" {
+    dolt sql -q "CREATE TABLE test (pk INT, c1 INT, PRIMARY KEY(pk))"
+    dolt sql -q "INSERT INTO test (pk, c1) VALUES (1,1),(2,2),(3,3),(4,4),(5,5),(6,6)"
+    dolt add test
+    dolt commit -m "added test table"
+    dolt tag v1
+    dolt checkout -b other
+    dolt sql -q "UPDATE test SET c1=c1*10"
+    dolt sql -q "INSERT INTO test (pk, c1) VALUES (7,7)"
+    dolt add test
+    dolt commit -m "modified rows"
+    dolt sql -q "DELETE FROM test WHERE pk=2"
+    dolt add test
+    dolt sql -q "UPDATE test SET c1=0 WHERE pk=3"
+
+    EXPECTED=$(echo -e "to_pk,to_c1,from_pk,from_c1,diff_type\n4,40,4,4,modified\n5,50,5,5,modified")
+    run dolt sql -r csv -q "SELECT to_pk, to_c1, from_pk, from_c1, diff_type FROM dolt_commit_diff_test WHERE from_commit='main' and to_commit='other' and to_pk > 3 and to_pk <= 5 ORDER BY to_pk"
+    [ "$status" -eq 0 ]
+    [ "$output" = "$EXPECTED" ]
+
+    EXPECTED=$(echo -e "to_pk,to_c1,from_pk,from_c1,diff_type\n,,2,2,removed\n7,7,,,added")
+    run dolt sql -r csv -q "SELECT to_pk, to_c1, from_pk, from_c1, diff_type FROM dolt_commit_diff_test WHERE from_commit='v1' and to_commit='STAGED' and (from_pk = 2 or to_pk >= 7) ORDER BY from_pk DESC"
+    [ "$status" -eq 0 ]
+    [ "$output" = "$EXPECTED" ]
+
+    EXPECTED=$(echo -e "to_pk,to_c1,from_pk,from_c1,diff_type\n3,0,3,30,modified")
+    run dolt sql -r csv -q "SELECT to_pk, to_c1, from_pk, from_c1, diff_type FROM dolt_commit_diff_test WHERE from_commit='STAGED' and to_commit='WORKING'"
+    [ "$status" -eq 0 ]
+    [ "$output" = "$EXPECTED" ]
+
+    EXPECTED=$(echo -e "to_pk,to_c1,from_pk,from_c1,diff_type\n1,10,1,1,modified")
+    run dolt sql -r csv -q "SELECT to_pk, to_c1, from_pk, from_c1, diff_type FROM dolt_commit_diff_test WHERE from_commit='HEAD~1' and to_commit='HEAD' and from_pk < 2"
+    [ "$status" -eq 0 ]
+    [ "$output" = "$EXPECTED" ]
+
+    run dolt sql -r csv -q "SELECT COUNT(*) FROM dolt_commit_diff_test WHERE from_commit='HEAD~1' and to_commit='WORKING' and to_pk > 100"
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "0" ]] || false
+}
+
+@test "system-tables: query dolt_schema_diff system table" {
+    dolt sql -q "CREATE TABLE test (pk INT PRIMARY KEY, c1 INT, CONSTRAINT c1_positive CHECK (c1 > 0))"
+    dolt sql -q "CREATE TABLE dropped (pk INT PRIMARY KEY)"
+    dolt add -A
+    dolt commit -m "created tables"
+    dolt branch before_changes
+
+    dolt sql -q "ALTER TABLE test DROP CONSTRAINT c1_positive"
+    dolt sql -q "ALTER TABLE test ADD COLUMN c2 VARCHAR(10)"
+    dolt sql -q "ALTER TABLE test MODIFY COLUMN c1 BIGINT"
+    dolt sql -q "ALTER TABLE test ADD INDEX c1_idx (c1)"
+    dolt sql -q "ALTER TABLE test ADD CONSTRAINT c2_positive CHECK (c2 > 0)"
+    dolt sql -q "DROP TABLE dropped"
+    dolt add -A
+    dolt commit -m "changed schemas"
+    dolt sql -q "CREATE TABLE added (pk INT PRIMARY KEY)"
+
+    run dolt sql -r csv -q "SELECT table_name, object_type, object_name, diff_type, statement FROM dolt_schema_diff WHERE from_commit='before_changes' AND to_commit='HEAD' ORDER BY table_name, object_type, object_name"
+    [ "$status" -eq 0 ]
+    [[ "${lines[1]}" =~ 'dropped,table,dropped,removed,DROP TABLE `dropped`;' ]] || false
+    [[ "${lines[2]}" =~ 'test,check,c1_positive,removed,ALTER TABLE `test` DROP CONSTRAINT `c1_positive`;' ]] || false
+    [[ "${lines[3]}" =~ 'test,check,c2_positive,added,ALTER TABLE `test` ADD CONSTRAINT `c2_positive` CHECK ((c2 > 0));' ]] || false
+    [[ "${lines[4]}" =~ 'test,column,c1,modified,ALTER TABLE `test` MODIFY COLUMN `c1` BIGINT;' ]] || false
+    [[ "${lines[5]}" =~ 'test,column,c2,added,ALTER TABLE `test` ADD `c2` VARCHAR(10);' ]] || false
+    [[ "${lines[6]}" =~ 'test,index,c1_idx,added,ALTER TABLE `test` ADD INDEX `c1_idx`(`c1`);' ]] || false
+    [ "${#lines[@]}" -eq 7 ]
+
+    run dolt sql -r csv -q "SELECT from_definition, to_definition FROM dolt_schema_diff WHERE from_commit='HEAD~1' AND to_commit='HEAD' AND object_name = 'c1'"
+    [ "$status" -eq 0 ]
+    [[ "${lines[1]}" = '`c1` INT,`c1` BIGINT' ]] || false
+
+    run dolt sql -r csv -q "SELECT table_name, object_type, diff_type FROM dolt_schema_diff WHERE from_commit='STAGED' AND to_commit='WORKING'"
+    [ "$status" -eq 0 ]
+    [[ "${lines[1]}" = 'added,table,added' ]] || false
+    [ "${#lines[@]}" -eq 2 ]
+
+    run dolt sql -q "SELECT * FROM dolt_schema_diff WHERE from_commit='HEAD~1'"
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "must be filtered to a single 'to_commit'" ]] || false
+}
+
 @test "system-tables: query dolt_diff_ system table without committing table" {
     dolt sql -q "create table test (pk int not null primary key);"
     dolt sql -q "insert into test values (0), (1);"
@@ -358,6 +441,85 @@ teardown() {
     [ "${#lines[@]}" -eq 6 ]
 }
 
+@test "system-tables: dolt_history_ and dolt_diff_ follow renamed and retyped columns" {
+    dolt sql -q "create table test (pk int primary key, c1 int, c2 int)"
+    dolt sql -q "insert into test values (0, 0, 0)"
+    dolt add test
+    dolt commit -m "Added test table"
+    dolt sql -q "alter table test rename column c1 to c3"
+    dolt sql -q "alter table test modify column c2 varchar(10)"
+    dolt sql -q "insert into test values (1, 1, 'one')"
+    dolt add test
+    dolt commit -m "Renamed c1 and retyped c2"
+
+    run dolt sql -q "select pk, c3, c2 from dolt_history_test order by pk, commit_date" -r csv
+    [ $status -eq 0 ]
+    [ "${lines[0]}" = "pk,c3,c2" ]
+    [ "${lines[1]}" = "0,0,0" ]
+    [ "${lines[2]}" = "0,0,0" ]
+    [ "${lines[3]}" = "1,1,one" ]
+    [ "${#lines[@]}" -eq 4 ]
+
+    run dolt sql -q "select to_pk, to_c3, to_c2, diff_type from dolt_diff_test where to_commit = hashof('HEAD') order by diff_type" -r csv
+    [ $status -eq 0 ]
+    [ "${lines[1]}" = "1,1,one,added" ]
+    [ "${lines[2]}" = ",,,schema_change" ]
+    [ "${#lines[@]}" -eq 3 ]
+}
+
+@test "system-tables: query dolt_column_diff system table" {
+    dolt sql -q "create table test (pk int primary key, c1 int, c2 int)"
+    dolt sql -q "insert into test values (0, 0, 0), (1, 1, 1)"
+    dolt add test
+    dolt commit -m "Added test table"
+    dolt sql -q "update test set c2 = 2 where pk = 1"
+    dolt commit -am "Updated c2"
+
+    run dolt sql -q "select column_name, diff_type from dolt_column_diff where table_name = 'test' and commit_hash = hashof('HEAD')" -r csv
+    [ $status -eq 0 ]
+    [ "${lines[1]}" = "c2,modified" ]
+    [ "${#lines[@]}" -eq 2 ]
+
+    run dolt sql -q "select column_name, diff_type from dolt_column_diff where message = 'Added test table' order by column_name" -r csv
+    [ $status -eq 0 ]
+    [ "${lines[1]}" = "c1,added" ]
+    [ "${lines[2]}" = "c2,added" ]
+    [ "${lines[3]}" = "pk,added" ]
+}
+
+@test "system-tables: query dolt_blame_ system table" {
+    dolt sql -q "create table test (pk int, c1 int, primary key(pk))"
+    dolt sql -q "insert into test values (0,0), (1,1), (2,2)"
+    dolt add test
+    dolt commit -m "Added test table"
+    dolt sql -q "update test set c1 = 10 where pk = 1"
+    dolt commit -am "Updated a row"
+    dolt sql -q "update test set c1 = 20 where pk = 2"
+
+    run dolt sql -q "select pk, message from dolt_blame_test order by pk" -r csv
+    [ $status -eq 0 ]
+    [ "${#lines[@]}" -eq 4 ]
+    [[ "${lines[1]}" = "0,Added test table" ]] || false
+    [[ "${lines[2]}" = "1,Updated a row" ]] || false
+    [[ "${lines[3]}" = "2,Added test table" ]] || false
+
+    run dolt sql -q "select pk, message from dolt_blame_test as of 'HEAD~1' order by pk" -r csv
+    [ $status -eq 0 ]
+    [[ "${lines[2]}" = "1,Added test table" ]] || false
+
+    head=$(dolt sql -q "select commit_hash from dolt_log limit 1" -r csv | tail -n 1)
+    run dolt blame test
+    [ $status -eq 0 ]
+    [[ "$output" =~ "$head" ]] || false
+    run dolt sql -q "select commit_hash from dolt_blame_test where pk = 1" -r csv
+    [ $status -eq 0 ]
+    [[ "${lines[1]}" = "$head" ]] || false
+
+    run dolt sql -q "select * from dolt_blame_missing"
+    [ $status -ne 0 ]
+    [[ "$output" =~ "table not found" ]] || false
+}
+
 @test "system-tables: query dolt_commits" {
     run dolt sql -q "SELECT count(*) FROM dolt_commits;" -r csv
     [ "$status" -eq 0 ]
@@ -495,6 +657,74 @@ SQL
     [[ "$output" =~ "1" ]] || false
 }
 
+@test "system-tables: query and modify dolt_tags system table" {
+    dolt sql -q "create table test (pk int, c1 int, primary key(pk))"
+    dolt add test
+    dolt commit -m "Added test table"
+    dolt tag v1 -m "first release"
+
+    run dolt sql -q "select name, tagger, message from dolt_tags" -r csv
+    [ $status -eq 0 ]
+    [[ "$output" =~ "v1," ]] || false
+    [[ "$output" =~ ",first release" ]] || false
+
+    dolt sql -q "insert into dolt_tags (name, hash, date, message) values ('v0', 'HEAD~1', '2020-01-01 00:00:00', 'initial')"
+    dolt sql -q "select dolt_tag('-m', 'from a function', 'v2')"
+
+    run dolt tag -v
+    [ $status -eq 0 ]
+    [[ "$output" =~ "v0" ]] || false
+    [[ "$output" =~ "initial" ]] || false
+    [[ "$output" =~ "2020" ]] || false
+    [[ "$output" =~ "from a function" ]] || false
+
+    run dolt sql -q "select t.name from dolt_tags t join dolt_log l on t.hash = l.commit_hash where l.message = 'Initialize data repository'" -r csv
+    [ $status -eq 0 ]
+    [[ "$output" =~ "v0" ]] || false
+    [[ ! "$output" =~ "v1" ]] || false
+
+    run dolt sql -q "insert into dolt_tags (name) values ('v1')"
+    [ $status -ne 0 ]
+
+    run dolt sql -q "update dolt_tags set message = 'changed' where name = 'v1'"
+    [ $status -ne 0 ]
+
+    dolt sql -q "delete from dolt_tags where name = 'v0'"
+    dolt sql -q "select dolt_tag('-d', 'v2')"
+    run dolt tag
+    [ $status -eq 0 ]
+    [[ "$output" =~ "v1" ]] || false
+    [[ ! "$output" =~ "v0" ]] || false
+    [[ ! "$output" =~ "v2" ]] || false
+}
+
+@test "system-tables: query dolt_remote_branches system table" {
+    run dolt sql -q "select count(*) from dolt_remote_branches" -r csv
+    [ $status -eq 0 ]
+    [[ "$output" =~ 0 ]] || false
+
+    mkdir remote
+    dolt remote add origin file://remote/
+    dolt checkout -b feature
+    dolt sql -q "create table test (pk int, c1 int, primary key(pk))"
+    dolt add test
+    dolt commit -m "Added test table"
+    dolt push origin main
+    dolt push origin feature
+
+    run dolt sql -q "select name, latest_commit_message from dolt_remote_branches order by name" -r csv
+    [ $status -eq 0 ]
+    [[ "${lines[1]}" = "remotes/origin/feature,Added test table" ]] || false
+    [[ "${lines[2]}" = "remotes/origin/main,Initialize data repository" ]] || false
+
+    run dolt sql -q "select count(*) from dolt_branches b join dolt_remote_branches r on b.hash = r.hash" -r csv
+    [ $status -eq 0 ]
+    [[ "$output" =~ 2 ]] || false
+
+    run dolt sql -q "delete from dolt_remote_branches"
+    [ $status -ne 0 ]
+}
+
 @test "system-tables: cannot delete last branch in dolt_branches" {
     run dolt sql -q "DELETE FROM dolt_branches"
     [ "$status" -ne 0 ]